@@ -0,0 +1,93 @@
+package api
+
+import (
+	"compress/gzip"
+	"crypto/sha1" //nolint:gosec // not for security, cache validator only
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+
+	"github.com/umputun/feed-master/app/youtube"
+)
+
+// gzipMinSize is the smallest body worth compressing
+const gzipMinSize = 1024
+
+// feedValidators makes the ETag and Last-Modified of a /yt/rss feed from the
+// store revision of its channel. The ETag covers the feed settings and the
+// build too, so ?speed, ?limit or a config change never get a stale 304.
+// ok is false without a revision to go by.
+func (s *Server) feedValidators(fi youtube.FeedInfo) (etag string, modified time.Time, ok bool) {
+	if s.YoutubeStore == nil {
+		return "", time.Time{}, false
+	}
+	rev, err := s.YoutubeStore.Revision(fi.ID)
+	if err != nil {
+		log.Printf("[WARN] no revision of %s: %v", fi.ID, err)
+		return "", time.Time{}, false
+	}
+	if rev.Rev == 0 {
+		return "", time.Time{}, false
+	}
+	variant := sha1.Sum([]byte(fmt.Sprintf("%s %+v", s.Version, fi))) //nolint:gosec // cache validator only
+	return fmt.Sprintf(`W/"%d-%x"`, rev.Rev, variant[:6]), rev.Modified, true
+}
+
+// notModified sets the validators of the response and answers 304 when the
+// client's copy is current. If-None-Match wins over If-Modified-Since.
+func notModified(w http.ResponseWriter, r *http.Request, etag string, modified time.Time) bool {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", "no-cache") // may be stored, but revalidated on each poll
+
+	match := false
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				match = true
+				break
+			}
+		}
+	} else if ims, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil {
+		match = !modified.Truncate(time.Second).After(ims)
+	}
+	if match {
+		w.WriteHeader(http.StatusNotModified)
+	}
+	return match
+}
+
+// writeCompressed writes the body, gzipped for clients accepting it
+func writeCompressed(w http.ResponseWriter, r *http.Request, contentType string, body []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Add("Vary", "Accept-Encoding")
+	if len(body) < gzipMinSize || !acceptsGzip(r) {
+		_, _ = w.Write(body)
+		return
+	}
+	w.Header().Set("Content-Encoding", "gzip")
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(body); err != nil {
+		log.Printf("[WARN] failed to write gzipped response: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		log.Printf("[WARN] failed to finish gzipped response: %v", err)
+	}
+}
+
+// acceptsGzip checks Accept-Encoding for gzip, not refused with q=0
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(enc), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			continue
+		}
+		q := strings.ReplaceAll(strings.TrimSpace(params), " ", "")
+		return q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000"
+	}
+	return false
+}
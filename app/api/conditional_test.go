@@ -0,0 +1,102 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/feed-master/app/api/mocks"
+	"github.com/umputun/feed-master/app/config"
+	"github.com/umputun/feed-master/app/youtube"
+	"github.com/umputun/feed-master/app/youtube/store"
+)
+
+func TestServer_getYoutubeFeedCtrlConditional(t *testing.T) {
+	modified := time.Date(2026, time.October, 1, 12, 30, 15, 500, time.UTC)
+	rev := store.Revision{Rev: 7, Modified: modified}
+	yt := &mocks.YoutubeSvcMock{
+		RSSFeedFunc: func(youtube.FeedInfo) (string, error) {
+			return "<rss>" + strings.Repeat("<item>entry</item>", 200) + "</rss>", nil
+		},
+	}
+	ytStore := &mocks.YoutubeStoreMock{
+		RevisionFunc: func(string) (store.Revision, error) { return rev, nil },
+	}
+	s := Server{Version: "1.0", TemplLocation: "../webapp/templates/*", YoutubeSvc: yt, YoutubeStore: ytStore,
+		Conf: config.Conf{}}
+	ts := httptest.NewServer(s.router())
+	defer ts.Close()
+
+	get := func(path string, hdr map[string]string) *http.Response {
+		req, err := http.NewRequest("GET", ts.URL+path, http.NoBody)
+		require.NoError(t, err)
+		for k, v := range hdr {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultTransport.RoundTrip(req) // no transparent gunzip
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = resp.Body.Close() })
+		return resp
+	}
+
+	resp := get("/yt/rss/chan1", map[string]string{"Accept-Encoding": "gzip"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	assert.True(t, strings.HasPrefix(etag, `W/"7-`), etag)
+	assert.Equal(t, "Thu, 01 Oct 2026 12:30:15 GMT", resp.Header.Get("Last-Modified"))
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	gz, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(body), `<?xml version="1.0" encoding="UTF-8"?>`+"\n<rss>"))
+
+	resp = get("/yt/rss/chan1", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	resp = get("/yt/rss/chan1", map[string]string{"If-Modified-Since": "Thu, 01 Oct 2026 12:30:15 GMT"})
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	assert.Equal(t, 1, len(yt.RSSFeedCalls()), "304 answered without building the feed")
+
+	resp = get("/yt/rss/chan1?limit=5", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "other variant of the feed")
+	assert.NotEqual(t, etag, resp.Header.Get("ETag"))
+	assert.Empty(t, resp.Header.Get("Content-Encoding"), "gzip not asked for")
+
+	rev = store.Revision{Rev: 8, Modified: modified.Add(time.Minute)}
+	resp = get("/yt/rss/chan1", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "feed changed")
+	resp = get("/yt/rss/chan1", map[string]string{"If-Modified-Since": "Thu, 01 Oct 2026 12:30:15 GMT"})
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "feed changed")
+
+	rev = store.Revision{}
+	resp = get("/yt/rss/chan1", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("ETag"), "no revision recorded yet")
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tbl := []struct {
+		header string
+		res    bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"deflate, gzip;q=0.8", true},
+		{"br, GZIP", true},
+		{"gzip;q=0", false},
+		{"gzip; q=0.000, deflate", false},
+		{"identity", false},
+	}
+	for _, tt := range tbl {
+		r := httptest.NewRequest("GET", "/", http.NoBody)
+		r.Header.Set("Accept-Encoding", tt.header)
+		assert.Equal(t, tt.res, acceptsGzip(r), tt.header)
+	}
+}
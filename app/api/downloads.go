@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+)
+
+// downloadWindow is how long repeated requests of one listener for a file
+// count as one download: players fetch an episode in many ranges
+const downloadWindow = 24 * time.Hour
+
+// audioExts are the enclosure types counted as downloads, side files aren't
+var audioExts = map[string]bool{".mp3": true, ".m4a": true, ".mp4": true, ".ogg": true, ".opus": true}
+
+// podcastClients maps user-agent markers to the client name, first match wins
+var podcastClients = []struct{ marker, name string }{
+	{"overcast", "Overcast"},
+	{"pocketcasts", "Pocket Casts"},
+	{"pocket casts", "Pocket Casts"},
+	{"antennapod", "AntennaPod"},
+	{"castro", "Castro"},
+	{"podcastaddict", "Podcast Addict"},
+	{"podcast addict", "Podcast Addict"},
+	{"castbox", "Castbox"},
+	{"spotify", "Spotify"},
+	{"podcasts/", "Apple Podcasts"},
+	{"applecoremedia", "Apple Podcasts"},
+	{"itunes", "Apple Podcasts"},
+	{"vlc", "VLC"},
+	{"telegram", "Telegram"},
+	{"mozilla", "Browser"},
+}
+
+// podcastClient names the podcast app of a user-agent, "other" if unknown
+func podcastClient(ua string) string {
+	ua = strings.ToLower(ua)
+	for _, c := range podcastClients {
+		if strings.Contains(ua, c.marker) {
+			return c.name
+		}
+	}
+	return "other"
+}
+
+// anonymizeIP drops the host part of an address: IPv4 to /24, IPv6 to /48
+func anonymizeIP(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return ""
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(48, 128)).String()
+}
+
+// logDownloads writes an access log line for each episode download and counts
+// it for /stats, once per listener (anonymized IP and user-agent) and file in
+// downloadWindow
+func (s *Server) logDownloads(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file := path.Base(r.URL.Path)
+		if r.Method != http.MethodGet || !audioExts[strings.ToLower(path.Ext(file))] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ip, ua := anonymizeIP(r.RemoteAddr), r.UserAgent()
+		if s.firstDownload(ip+"|"+ua+"|"+file, time.Now()) {
+			client := podcastClient(ua)
+			log.Printf("[INFO] download %s, ip %s, client %s, ua %q", file, ip, client, ua)
+			if s.Downloads != nil {
+				if err := s.Downloads.RecordDownload(file, client); err != nil {
+					log.Printf("[WARN] failed to count download of %s: %v", file, err)
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// firstDownload reports whether the listener key wasn't seen in the window,
+// remembering it. Expired keys are dropped along the way.
+func (s *Server) firstDownload(key string, now time.Time) bool {
+	s.dlMu.Lock()
+	defer s.dlMu.Unlock()
+	if s.dlSeen == nil {
+		s.dlSeen = map[string]time.Time{}
+	}
+	if seen, ok := s.dlSeen[key]; ok && now.Sub(seen) < downloadWindow {
+		return false
+	}
+	if len(s.dlSeen) > 1000 {
+		for k, seen := range s.dlSeen {
+			if now.Sub(seen) >= downloadWindow {
+				delete(s.dlSeen, k)
+			}
+		}
+	}
+	s.dlSeen[key] = now
+	return true
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/feed-master/app/api/mocks"
+	"github.com/umputun/feed-master/app/config"
+)
+
+func TestServer_logDownloads(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ep1.mp3"), []byte("MP3DATA"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ep1.vtt"), []byte("WEBVTT"), 0o600))
+
+	dl := &mocks.DownloadLogMock{RecordDownloadFunc: func(string, string) error { return nil }}
+	conf := config.Conf{}
+	conf.YouTube.BaseURL = "http://localhost/yt/media"
+	conf.YouTube.FilesLocation = dir
+	s := Server{Version: "1.0", TemplLocation: "../webapp/templates/*", Conf: conf, Downloads: dl,
+		MediaRedirectBase: "https://r2.example.com"}
+	ts := httptest.NewServer(s.router())
+	defer ts.Close()
+
+	get := func(file, ua, rng string) {
+		req, err := http.NewRequest("GET", ts.URL+"/yt/media/"+file, http.NoBody)
+		require.NoError(t, err)
+		req.Header.Set("User-Agent", ua)
+		if rng != "" {
+			req.Header.Set("Range", rng)
+		}
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+	get("ep1.mp3", "Overcast/3.0 (+http://overcast.fm/)", "bytes=0-1")
+	get("ep1.mp3", "Overcast/3.0 (+http://overcast.fm/)", "bytes=2-")
+	get("ep1.mp3", "AppleCoreMedia/1.0.0.21E236 (iPhone; U; CPU OS 17_4 like Mac OS X)", "")
+	get("ep1.vtt", "Overcast/3.0 (+http://overcast.fm/)", "")
+	get("gone.mp3", "Podcasts/1.0", "") // redirected to R2, counted all the same
+
+	calls := dl.RecordDownloadCalls()
+	require.Len(t, calls, 3, "ranges of one listener counted once, side files not at all")
+	assert.Equal(t, "ep1.mp3", calls[0].File)
+	assert.Equal(t, "Overcast", calls[0].Client)
+	assert.Equal(t, "Apple Podcasts", calls[1].Client)
+	assert.Equal(t, "gone.mp3", calls[2].File)
+}
+
+func TestServer_firstDownload(t *testing.T) {
+	s := Server{}
+	now := time.Now()
+	assert.True(t, s.firstDownload("k1", now))
+	assert.False(t, s.firstDownload("k1", now.Add(time.Hour)))
+	assert.True(t, s.firstDownload("k2", now.Add(time.Hour)))
+	assert.True(t, s.firstDownload("k1", now.Add(downloadWindow)), "window passed")
+}
+
+func TestPodcastClient(t *testing.T) {
+	tbl := []struct{ ua, client string }{
+		{"AppleCoreMedia/1.0.0.21E236 (iPhone; U; CPU OS 17_4 like Mac OS X; en_us)", "Apple Podcasts"},
+		{"Podcasts/1560.2 CFNetwork/1494.0.7 Darwin/23.4.0", "Apple Podcasts"},
+		{"Pocket Casts", "Pocket Casts"},
+		{"AntennaPod/3.4.0", "AntennaPod"},
+		{"Mozilla/5.0 (X11; Linux x86_64) Firefox/125.0", "Browser"},
+		{"Spotify/8.9 Android/34", "Spotify"},
+		{"curl/8.5.0", "other"},
+		{"", "other"},
+	}
+	for _, tt := range tbl {
+		assert.Equal(t, tt.client, podcastClient(tt.ua), tt.ua)
+	}
+}
+
+func TestAnonymizeIP(t *testing.T) {
+	assert.Equal(t, "192.168.1.0", anonymizeIP("192.168.1.77"))
+	assert.Equal(t, "192.168.1.0", anonymizeIP("192.168.1.77:51234"))
+	assert.Equal(t, "2001:db8:85a3::", anonymizeIP("2001:db8:85a3:8d3:1319:8a2e:370:7348"))
+	assert.Equal(t, "2001:db8:85a3::", anonymizeIP("[2001:db8:85a3::1]:443"))
+	assert.Empty(t, anonymizeIP("garbage"))
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+
+	"github.com/umputun/feed-master/app/config"
+)
+
+// mediaLimiter applies config.MediaLimits to each client (IP) of /yt/media.
+// Players fetch an episode in many Range requests, some of them hundreds at
+// once, so requests and open downloads are counted per client, not globally.
+type mediaLimiter struct {
+	conf config.MediaLimits
+	now  func() time.Time
+
+	mu      sync.Mutex
+	clients map[string]*mediaClient
+}
+
+// mediaClient is the usage of one client in the current minute
+type mediaClient struct {
+	window   time.Time // start of the counted minute
+	requests int
+	active   int // downloads in progress
+}
+
+func newMediaLimiter(conf config.MediaLimits) *mediaLimiter {
+	return &mediaLimiter{conf: conf, now: time.Now, clients: map[string]*mediaClient{}}
+}
+
+// enabled reports whether any limit is set
+func (m *mediaLimiter) enabled() bool {
+	return m.conf.RequestsPerMinute > 0 || m.conf.MaxConnections > 0 || m.conf.RateKB > 0
+}
+
+// Handler rejects requests over the limits with 429 and Retry-After,
+// and slows the response down to RateKB
+func (m *mediaLimiter) Handler(next http.Handler) http.Handler {
+	if !m.enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := clientIP(r.RemoteAddr)
+		retry, ok := m.acquire(ip)
+		if !ok {
+			log.Printf("[DEBUG] media request %s from %s rejected, retry in %v", r.URL.Path, ip, retry)
+			w.Header().Set("Retry-After", strconv.Itoa(int(retry.Round(time.Second)/time.Second)))
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+		defer m.release(ip)
+		if m.conf.RateKB > 0 {
+			w = &throttledWriter{ResponseWriter: w, r: r, rate: m.conf.RateKB * 1024, start: time.Now()}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// acquire counts a request of the client, on refusal it returns how long to wait
+func (m *mediaLimiter) acquire(ip string) (retry time.Duration, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	c, found := m.clients[ip]
+	if !found {
+		if len(m.clients) > 1000 {
+			for k, v := range m.clients {
+				if v.active == 0 && now.Sub(v.window) >= time.Minute {
+					delete(m.clients, k)
+				}
+			}
+		}
+		c = &mediaClient{window: now}
+		m.clients[ip] = c
+	}
+	if now.Sub(c.window) >= time.Minute {
+		c.window, c.requests = now, 0
+	}
+	if m.conf.MaxConnections > 0 && c.active >= m.conf.MaxConnections {
+		return 5 * time.Second, false
+	}
+	if m.conf.RequestsPerMinute > 0 && c.requests >= m.conf.RequestsPerMinute {
+		return max(c.window.Add(time.Minute).Sub(now), time.Second), false
+	}
+	c.requests++
+	c.active++
+	return 0, true
+}
+
+// release marks a download of the client as finished
+func (m *mediaLimiter) release(ip string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok := m.clients[ip]; ok && c.active > 0 {
+		c.active--
+	}
+}
+
+// clientIP is the host of the remote address, set from X-Real-IP by rest.RealIP
+func clientIP(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// throttledWriter keeps the response at rate bytes a second, sleeping between
+// chunks. Gives up sleeping as soon as the client goes away.
+type throttledWriter struct {
+	http.ResponseWriter
+	r       *http.Request
+	rate    int // bytes per second
+	start   time.Time
+	written int64
+}
+
+// throttleChunk is the most written between two checks of the pace
+const throttleChunk = 16 * 1024
+
+func (t *throttledWriter) Write(p []byte) (int, error) {
+	total := 0
+	for len(p) > 0 {
+		chunk := p[:min(len(p), throttleChunk)]
+		n, err := t.ResponseWriter.Write(chunk)
+		total += n
+		t.written += int64(n)
+		if err != nil {
+			return total, err
+		}
+		p = p[n:]
+		due := t.start.Add(time.Duration(t.written * int64(time.Second) / int64(t.rate)))
+		if wait := time.Until(due); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-t.r.Context().Done():
+				return total, t.r.Context().Err()
+			}
+		}
+	}
+	return total, nil
+}
+
+// Unwrap gives http.ResponseController access to the original writer
+func (t *throttledWriter) Unwrap() http.ResponseWriter {
+	return t.ResponseWriter
+}
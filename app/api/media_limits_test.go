@@ -0,0 +1,151 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/feed-master/app/config"
+)
+
+func TestMediaLimiter_acquire(t *testing.T) {
+	now := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	m := newMediaLimiter(config.MediaLimits{RequestsPerMinute: 3, MaxConnections: 2})
+	m.now = func() time.Time { return now }
+
+	_, ok := m.acquire("10.0.0.1")
+	require.True(t, ok)
+	_, ok = m.acquire("10.0.0.1")
+	require.True(t, ok)
+	retry, ok := m.acquire("10.0.0.1")
+	assert.False(t, ok, "two downloads in progress")
+	assert.Equal(t, 5*time.Second, retry)
+	_, ok = m.acquire("10.0.0.2")
+	assert.True(t, ok, "other client isn't affected")
+
+	m.release("10.0.0.1")
+	_, ok = m.acquire("10.0.0.1")
+	require.True(t, ok)
+	m.release("10.0.0.1")
+	now = now.Add(20 * time.Second)
+	retry, ok = m.acquire("10.0.0.1")
+	assert.False(t, ok, "3 requests in the minute")
+	assert.Equal(t, 40*time.Second, retry)
+
+	now = now.Add(40 * time.Second)
+	_, ok = m.acquire("10.0.0.1")
+	assert.True(t, ok, "next minute")
+}
+
+func TestServer_mediaLimits(t *testing.T) {
+	dir := t.TempDir()
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ep1.mp3"), data, 0o600))
+
+	conf := config.Conf{}
+	conf.YouTube.BaseURL = "http://localhost/yt/media"
+	conf.YouTube.FilesLocation = dir
+	conf.MediaLimits = config.MediaLimits{RequestsPerMinute: 3, RateKB: 1024}
+	s := Server{Version: "1.0", TemplLocation: "../webapp/templates/*", Conf: conf}
+	ts := httptest.NewServer(s.router())
+	defer ts.Close()
+
+	get := func(rng string) (*http.Response, []byte) {
+		req, err := http.NewRequest("GET", ts.URL+"/yt/media/ep1.mp3", http.NoBody)
+		require.NoError(t, err)
+		if rng != "" {
+			req.Header.Set("Range", rng)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, body
+	}
+
+	resp, body := get("")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, data, body)
+
+	resp, body = get("bytes=9990-")
+	assert.Equal(t, http.StatusPartialContent, resp.StatusCode, "resume works through the throttle")
+	assert.Equal(t, "bytes 9990-9999/10000", resp.Header.Get("Content-Range"))
+	assert.Equal(t, "0123456789", string(body))
+
+	_, _ = get("bytes=0-9")
+	resp, _ = get("bytes=10-19")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+}
+
+func TestThrottledWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", http.NoBody)
+	w := &throttledWriter{ResponseWriter: rec, r: r, rate: 100 * 1024, start: time.Now()}
+	st := time.Now()
+	n, err := w.Write(make([]byte, 20*1024))
+	require.NoError(t, err)
+	assert.Equal(t, 20*1024, n)
+	assert.GreaterOrEqual(t, time.Since(st), 150*time.Millisecond, "20KB at 100KB/s")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w = &throttledWriter{ResponseWriter: httptest.NewRecorder(), r: r.WithContext(ctx), rate: 1024, start: time.Now()}
+	st = time.Now()
+	_, err = w.Write(make([]byte, 64*1024))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(st), time.Second, "client gone, no more waiting")
+}
+
+// BenchmarkMediaRange fetches an episode the way podcast players do, in 64KB
+// ranges, with and without the per-client limits
+func BenchmarkMediaRange(b *testing.B) {
+	dir := b.TempDir()
+	const size = 4 * 1024 * 1024
+	require.NoError(b, os.WriteFile(filepath.Join(dir, "ep1.mp3"), bytes.Repeat([]byte{0xAA}, size), 0o600))
+
+	for _, tt := range []struct {
+		name   string
+		limits config.MediaLimits
+	}{
+		{"no limits", config.MediaLimits{}},
+		{"limited", config.MediaLimits{RequestsPerMinute: 1 << 30, MaxConnections: 4}},
+	} {
+		b.Run(tt.name, func(b *testing.B) {
+			conf := config.Conf{MediaLimits: tt.limits}
+			conf.YouTube.BaseURL = "http://localhost/yt/media"
+			conf.YouTube.FilesLocation = dir
+			s := Server{Version: "1.0", TemplLocation: "../webapp/templates/*", Conf: conf}
+			ts := httptest.NewServer(s.router())
+			defer ts.Close()
+
+			const chunk = 64 * 1024
+			b.SetBytes(chunk)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				offset := (i * chunk) % size
+				req, err := http.NewRequest("GET", ts.URL+"/yt/media/ep1.mp3", http.NoBody)
+				require.NoError(b, err)
+				req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+chunk-1))
+				resp, err := http.DefaultClient.Do(req)
+				require.NoError(b, err)
+				_, _ = io.Copy(io.Discard, resp.Body)
+				_ = resp.Body.Close()
+				if resp.StatusCode != http.StatusPartialContent {
+					b.Fatalf("unexpected status %d", resp.StatusCode)
+				}
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	"github.com/umputun/feed-master/app/youtube/store"
 )
 
 // YoutubeStoreMock is a mock implementation of api.YoutubeStore.
@@ -18,6 +19,9 @@ import (
 //			LoadFunc: func(channelID string, maxItems int) ([]ytfeed.Entry, error) {
 //				panic("mock out the Load method")
 //			},
+//			RevisionFunc: func(channelID string) (store.Revision, error) {
+//				panic("mock out the Revision method")
+//			},
 //		}
 //
 //		// use mockedYoutubeStore in code that requires api.YoutubeStore
@@ -28,6 +32,9 @@ type YoutubeStoreMock struct {
 	// LoadFunc mocks the Load method.
 	LoadFunc func(channelID string, maxItems int) ([]ytfeed.Entry, error)
 
+	// RevisionFunc mocks the Revision method.
+	RevisionFunc func(channelID string) (store.Revision, error)
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// Load holds details about calls to the Load method.
@@ -37,8 +44,14 @@ type YoutubeStoreMock struct {
 			// MaxItems is the maxItems argument value.
 			MaxItems int
 		}
+		// Revision holds details about calls to the Revision method.
+		Revision []struct {
+			// ChannelID is the channelID argument value.
+			ChannelID string
+		}
 	}
-	lockLoad sync.RWMutex
+	lockLoad     sync.RWMutex
+	lockRevision sync.RWMutex
 }
 
 // Load calls LoadFunc.
@@ -76,3 +89,35 @@ func (mock *YoutubeStoreMock) LoadCalls() []struct {
 	mock.lockLoad.RUnlock()
 	return calls
 }
+
+// Revision calls RevisionFunc.
+func (mock *YoutubeStoreMock) Revision(channelID string) (store.Revision, error) {
+	if mock.RevisionFunc == nil {
+		panic("YoutubeStoreMock.RevisionFunc: method is nil but YoutubeStore.Revision was just called")
+	}
+	callInfo := struct {
+		ChannelID string
+	}{
+		ChannelID: channelID,
+	}
+	mock.lockRevision.Lock()
+	mock.calls.Revision = append(mock.calls.Revision, callInfo)
+	mock.lockRevision.Unlock()
+	return mock.RevisionFunc(channelID)
+}
+
+// RevisionCalls gets all the calls that were made to Revision.
+// Check the length with:
+//
+//	len(mockedYoutubeStore.RevisionCalls())
+func (mock *YoutubeStoreMock) RevisionCalls() []struct {
+	ChannelID string
+} {
+	var calls []struct {
+		ChannelID string
+	}
+	mock.lockRevision.RLock()
+	calls = mock.calls.Revision
+	mock.lockRevision.RUnlock()
+	return calls
+}
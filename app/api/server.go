@@ -4,13 +4,16 @@ package api
 import (
 	"context"
 	"crypto/subtle"
+	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"html/template"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -23,13 +26,17 @@ import (
 
 	"github.com/umputun/feed-master/app/config"
 	"github.com/umputun/feed-master/app/feed"
+	"github.com/umputun/feed-master/app/systemd"
 	"github.com/umputun/feed-master/app/youtube"
 	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	"github.com/umputun/feed-master/app/youtube/store"
 )
 
 //go:generate moq -out mocks/yt_service.go -pkg mocks -skip-ensure -fmt goimports . YoutubeSvc
 //go:generate moq -out mocks/store.go -pkg mocks -skip-ensure -fmt goimports . Store
 //go:generate moq -out mocks/youtube_store.go -pkg mocks -skip-ensure -fmt goimports . YoutubeStore
+//go:generate moq -out mocks/download_log.go -pkg mocks -skip-ensure -fmt goimports . DownloadLog
+//go:generate moq -out mocks/link_adder.go -pkg mocks -skip-ensure -fmt goimports . LinkAdder
 
 // Server provides HTTP API
 type Server struct {
@@ -46,10 +53,15 @@ type Server struct {
 	// that are no longer on local disk: episodes are offloaded to R2 and the
 	// VM stops streaming gigabytes through GCP egress
 	MediaRedirectBase string
+	Downloads         DownloadLog // episode downloads counted for /stats, nil = logged only
+	Links             LinkAdder   // links shared from phones, nil = POST /api/add is off
 
 	httpServer *http.Server
 	cache      lcw.LoadingCache[[]byte]
 	templates  *template.Template
+
+	dlMu   sync.Mutex
+	dlSeen map[string]time.Time // listener+file keys of counted downloads
 }
 
 // YoutubeSvc provides access to youtube's audio rss
@@ -67,11 +79,23 @@ type Store interface {
 // YoutubeStore provides access to YouTube channel data
 type YoutubeStore interface {
 	Load(channelID string, maxItems int) ([]ytfeed.Entry, error)
+	Revision(channelID string) (store.Revision, error)
+}
+
+// DownloadLog counts episode downloads
+type DownloadLog interface {
+	RecordDownload(file, client string) error
+}
+
+// LinkAdder takes links shared to the bot from outside of Telegram
+type LinkAdder interface {
+	AddLink(ctx context.Context, link, mode string) error
 }
 
-// Run starts http server for API with all routes
+// Run starts http server for API with all routes. Under systemd it serves the
+// activated socket instead of the port and reports readiness, so restarts queue
+// connections in the socket rather than refusing them.
 func (s *Server) Run(ctx context.Context, port int) {
-	log.Printf("[INFO] starting server on port %d", port)
 	var err error
 	o := lcw.NewOpts[[]byte]()
 	if s.cache, err = lcw.NewExpirableCache(o.TTL(time.Minute*3), o.MaxCacheSize(10*1024*1024)); err != nil {
@@ -79,14 +103,30 @@ func (s *Server) Run(ctx context.Context, port int) {
 		return
 	}
 
+	ln, err := listen(port)
+	if err != nil {
+		log.Printf("[ERROR] can't listen, %v", err)
+		return
+	}
+	log.Printf("[INFO] starting server on %s", ln.Addr())
+
 	serverLock := sync.Mutex{}
 	go func() {
 		<-ctx.Done()
+		if _, nerr := systemd.Notify("STOPPING=1"); nerr != nil {
+			log.Printf("[WARN] failed to notify systemd, %v", nerr)
+		}
 		serverLock.Lock()
 		defer serverLock.Unlock()
 		if s.httpServer != nil {
-			if clsErr := s.httpServer.Close(); clsErr != nil {
-				log.Printf("[ERROR] failed to close proxy http server, %v", clsErr)
+			// let requests in flight finish, a restart shouldn't cut a download
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			if shtErr := s.httpServer.Shutdown(shutdownCtx); shtErr != nil {
+				log.Printf("[WARN] graceful shutdown failed, %v", shtErr)
+				if clsErr := s.httpServer.Close(); clsErr != nil {
+					log.Printf("[ERROR] failed to close proxy http server, %v", clsErr)
+				}
 			}
 		}
 	}()
@@ -99,17 +139,65 @@ func (s *Server) Run(ctx context.Context, port int) {
 
 	serverLock.Lock()
 	s.httpServer = &http.Server{
-		Addr:              fmt.Sprintf(":%d", port),
 		Handler:           s.router(),
 		ReadHeaderTimeout: 5 * time.Second,
 		WriteTimeout:      s.Conf.System.HTTPResponseTimeout,
 		IdleTimeout:       30 * time.Second,
 	}
 	serverLock.Unlock()
-	err = s.httpServer.ListenAndServe()
+	go notifyReady(ctx)
+	err = s.httpServer.Serve(ln)
 	log.Printf("[WARN] http server terminated, %s", err)
 }
 
+// shutdownTimeout is how long Run waits for requests in flight on shutdown
+const shutdownTimeout = 10 * time.Second
+
+// listen returns the socket passed by systemd socket activation, or listens on the port
+func listen(port int) (net.Listener, error) {
+	lns, err := systemd.Listeners()
+	if err != nil {
+		return nil, fmt.Errorf("systemd sockets: %w", err)
+	}
+	if len(lns) > 0 {
+		for _, extra := range lns[1:] {
+			log.Printf("[WARN] systemd socket %s ignored, only one is served", extra.Addr())
+			_ = extra.Close()
+		}
+		return lns[0], nil
+	}
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return nil, fmt.Errorf("port %d: %w", port, err)
+	}
+	return ln, nil
+}
+
+// notifyReady tells systemd the server is up and keeps its watchdog fed until ctx is done
+func notifyReady(ctx context.Context) {
+	sent, err := systemd.Notify("READY=1")
+	if err != nil {
+		log.Printf("[WARN] failed to notify systemd, %v", err)
+		return
+	}
+	interval := systemd.WatchdogInterval()
+	if !sent || interval == 0 {
+		return
+	}
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := systemd.Notify("WATCHDOG=1"); err != nil {
+				log.Printf("[WARN] failed to ping systemd watchdog, %v", err)
+			}
+		}
+	}
+}
+
 // loadTemplates loads templates with custom functions
 func (s *Server) loadTemplates() {
 	funcMap := template.FuncMap{
@@ -164,20 +252,28 @@ func (s *Server) router() http.Handler {
 
 	router.Mount("/yt").Route(func(r *routegroup.Bundle) {
 		r.Use(timeout(60 * time.Second))
-		auth := rest.BasicAuth(func(user, passwd string) bool {
-			return (subtle.ConstantTimeCompare([]byte(s.AdminPasswd), []byte(passwd)) +
-				subtle.ConstantTimeCompare([]byte("admin"), []byte(user))) == 2
-		})
+		auth := s.adminAuth()
 
 		l := logger.New(logger.Log(log.Default()), logger.Prefix("[INFO]"), logger.IPfn(logger.AnonymizeIP))
 		r.Use(l.Handler)
 		r.HandleFunc("GET /rss/{channel}", s.getYoutubeFeedCtrl)
 		r.HandleFunc("GET /image/{channel}", s.getYoutubeImageCtrl)
 		r.HandleFunc("GET /channels", s.getYoutubeChannelsPageCtrl)
+		r.HandleFunc("GET /episodes/{channel}", s.getYoutubeEpisodesPageCtrl)
 		r.With(auth).HandleFunc("POST /rss/generate", s.regenerateRSSCtrl)
 		r.With(auth).HandleFunc("DELETE /entry/{channel}/{video}", s.removeEntryCtrl)
 	})
 
+	// share endpoint for iOS Shortcuts and Android intents, same admin auth as /yt
+	if s.Links != nil {
+		router.Mount("/api").Route(func(r *routegroup.Bundle) {
+			r.Use(timeout(60 * time.Second))
+			l := logger.New(logger.Log(log.Default()), logger.Prefix("[INFO]"), logger.IPfn(logger.AnonymizeIP))
+			r.Use(l.Handler, s.adminAuth())
+			r.HandleFunc("POST /add", s.addLinkCtrl)
+		})
+	}
+
 	if s.Conf.YouTube.BaseURL != "" {
 		baseYtURL, parseErr := url.Parse(s.Conf.YouTube.BaseURL)
 		if parseErr != nil {
@@ -188,14 +284,15 @@ func (s *Server) router() http.Handler {
 			log.Printf("[ERROR] failed to create directory %s, %v", s.Conf.YouTube.FilesLocation, mkdirErr)
 		}
 
+		limits := newMediaLimiter(s.Conf.MediaLimits)
 		if s.MediaRedirectBase != "" {
 			// local file when present (transition period / failed offloads),
 			// otherwise redirect to R2 — players follow 302 with Range fine
-			router.HandleFunc("GET "+baseYtURL.Path+"/{file...}", s.getMediaCtrl)
+			router.Handle("GET "+baseYtURL.Path+"/{file...}", limits.Handler(s.logDownloads(http.HandlerFunc(s.getMediaCtrl))))
 		} else {
 			ytfs, fsErr := rest.NewFileServer(baseYtURL.Path, s.Conf.YouTube.FilesLocation)
 			if fsErr == nil {
-				router.Handle(baseYtURL.Path+"/{file...}", cacheControl(ytfs, "public, max-age=604800"))
+				router.Handle(baseYtURL.Path+"/{file...}", limits.Handler(s.logDownloads(cacheControl(ytfs, "public, max-age=604800"))))
 			} else {
 				log.Printf("[WARN] can't start static file server for yt, %v", fsErr)
 			}
@@ -211,7 +308,15 @@ func (s *Server) router() http.Handler {
 	return router
 }
 
-// GET /yt/media/{file} - episode audio: local disk first, R2 redirect after offload
+// adminAuth is the basic auth of the protected endpoints, user "admin"
+func (s *Server) adminAuth() func(http.Handler) http.Handler {
+	return rest.BasicAuth(func(user, passwd string) bool {
+		return (subtle.ConstantTimeCompare([]byte(s.AdminPasswd), []byte(passwd)) +
+			subtle.ConstantTimeCompare([]byte("admin"), []byte(user))) == 2
+	})
+}
+
+// GET /yt/media/{file} - episode audio and side files: local disk first, R2 redirect after offload
 func (s *Server) getMediaCtrl(w http.ResponseWriter, r *http.Request) {
 	file := r.PathValue("file")
 	if file == "" || strings.ContainsAny(file, "/\\") || strings.Contains(file, "..") {
@@ -221,6 +326,18 @@ func (s *Server) getMediaCtrl(w http.ResponseWriter, r *http.Request) {
 	local := filepath.Join(s.Conf.YouTube.FilesLocation, file)
 	if fi, err := os.Stat(local); err == nil && !fi.IsDir() {
 		w.Header().Set("Cache-Control", "public, max-age=604800")
+		// read-along transcripts, chapters and waveforms, loaded by web players from other origins
+		switch {
+		case strings.HasSuffix(file, ".vtt"):
+			w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		case strings.HasSuffix(file, ".chapters.json"):
+			w.Header().Set("Content-Type", "application/json+chapters")
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		case strings.HasSuffix(file, ".peaks.json"):
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		}
 		http.ServeFile(w, r, local)
 		return
 	}
@@ -282,8 +399,12 @@ func (s *Server) getFeedCtrl(w http.ResponseWriter, r *http.Request) {
 				Name:  "Feed Master",
 				Email: s.Conf.Feeds[feedName].OwnerEmail,
 			},
-			NsItunes: "http://www.itunes.com/dtds/podcast-1.0.dtd",
-			NsMedia:  "http://search.yahoo.com/mrss/",
+			ItunesCategory: feed.NewItunesCategory(s.Conf.Feeds[feedName].Category),
+			NsItunes:       "http://www.itunes.com/dtds/podcast-1.0.dtd",
+			NsMedia:        "http://search.yahoo.com/mrss/",
+		}
+		if s.Conf.Feeds[feedName].Explicit {
+			rss.ItunesExplicit = "yes"
 		}
 
 		// replace link to UI page
@@ -315,8 +436,7 @@ func (s *Server) getFeedCtrl(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/xml; charset=UTF-8")
-	_, _ = fmt.Fprintf(w, "%s", data)
+	writeCompressed(w, r, "application/xml; charset=UTF-8", data)
 }
 
 // GET /image/{name}
@@ -365,6 +485,14 @@ func (s *Server) getYoutubeFeedCtrl(w http.ResponseWriter, r *http.Request) {
 		fi.Name = s.Conf.TelegramBot.FeedTitle
 		fi.Description = s.Conf.TelegramBot.FeedDescription
 		fi.Image = s.Conf.TelegramBot.FeedImage
+		fi.Language = s.Conf.TelegramBot.FeedLanguage
+		fi.Podcast = s.Conf.TelegramBot.FeedPodcast
+		fi.KindEmoji = !s.Conf.TelegramBot.FeedPlainTitles
+		// ?speed picks the sped-up copies, a feed of its own for players without a speed control
+		if r.URL.Query().Get("speed") != "" && s.Conf.TelegramBot.SpeedVariant > 0 {
+			fi.Speed = s.Conf.TelegramBot.SpeedVariant
+			fi.Name += fmt.Sprintf(" (x%g)", fi.Speed)
+		}
 	}
 
 	// convert local image path to URL
@@ -373,15 +501,57 @@ func (s *Server) getYoutubeFeedCtrl(w http.ResponseWriter, r *http.Request) {
 		fi.Image = baseURL + "/yt/image/" + channel
 	}
 
+	// ?sort=published|added and ?limit=N trim what podcast apps re-download on every refresh
+	switch sortBy := r.URL.Query().Get("sort"); sortBy {
+	case "", youtube.SortPublished, youtube.SortAdded:
+		fi.Sort = sortBy
+	default:
+		rest.SendErrorJSON(w, r, log.Default(), http.StatusBadRequest, fmt.Errorf("unknown sort %q", sortBy),
+			"sort must be published or added")
+		return
+	}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			rest.SendErrorJSON(w, r, log.Default(), http.StatusBadRequest, fmt.Errorf("bad limit %q", limit),
+				"limit must be a positive number")
+			return
+		}
+		fi.Limit = n
+	}
+	// ?kind=article,digest makes a feed of some kinds of episodes only
+	if kind := r.URL.Query().Get("kind"); kind != "" {
+		kinds, ok := ytfeed.ParseKinds(kind)
+		if !ok {
+			rest.SendErrorJSON(w, r, log.Default(), http.StatusBadRequest, fmt.Errorf("unknown kind %q", kind),
+				"kind must be a list of "+kindNames())
+			return
+		}
+		fi.Kinds = kinds
+	}
+
+	// clients polling every few minutes mostly get a 304 without the feed even built
+	if etag, modified, ok := s.feedValidators(fi); ok && notModified(w, r, etag, modified) {
+		return
+	}
+
 	res, err := s.YoutubeSvc.RSSFeed(fi)
 	if err != nil {
 		rest.SendErrorJSON(w, r, log.Default(), http.StatusInternalServerError, err, "failed to read yt list")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/xml; charset=UTF-8")
 	res = `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + res
-	_, _ = fmt.Fprintf(w, "%s", res)
+	writeCompressed(w, r, "application/xml; charset=UTF-8", []byte(res))
+}
+
+// kindNames lists the entry kinds for error messages, "video, podcast, ..."
+func kindNames() string {
+	names := make([]string, 0, len(ytfeed.Kinds))
+	for _, k := range ytfeed.Kinds {
+		names = append(names, string(k))
+	}
+	return strings.Join(names, ", ")
 }
 
 // POST /yt/rss/generate - generates rss for all (each) youtube channels
@@ -401,6 +571,30 @@ func (s *Server) regenerateRSSCtrl(w http.ResponseWriter, r *http.Request) {
 	rest.RenderJSON(w, rest.JSON{"status": "ok", "feeds": len(s.Conf.YouTube.Channels)})
 }
 
+// POST /api/add - adds a link shared from a phone, {"url": "...", "mode": "audio"}.
+// An empty mode asks in the chat what to do, as for a link sent to the bot.
+func (s *Server) addLinkCtrl(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		URL  string `json:"url"`
+		Mode string `json:"mode"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req); err != nil {
+		rest.SendErrorJSON(w, r, log.Default(), http.StatusBadRequest, err, "failed to decode request")
+		return
+	}
+	link := strings.TrimSpace(req.URL)
+	if u, err := url.Parse(link); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		rest.SendErrorJSON(w, r, log.Default(), http.StatusBadRequest, fmt.Errorf("bad url %q", req.URL), "bad url")
+		return
+	}
+	mode := strings.ToLower(strings.TrimSpace(req.Mode))
+	if err := s.Links.AddLink(r.Context(), link, mode); err != nil {
+		rest.SendErrorJSON(w, r, log.Default(), http.StatusBadRequest, err, "link rejected")
+		return
+	}
+	rest.RenderJSON(w, rest.JSON{"status": "ok", "url": link, "mode": mode})
+}
+
 // DELETE /yt/entry/{channel}/{video} - deletes entry from youtube channel and videID
 func (s *Server) removeEntryCtrl(w http.ResponseWriter, r *http.Request) {
 	err := s.YoutubeSvc.RemoveEntry(ytfeed.Entry{ChannelID: r.PathValue("channel"), VideoID: r.PathValue("video")})
@@ -3,11 +3,16 @@ package api
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -40,6 +45,38 @@ func TestServer_Run(t *testing.T) {
 	s.Run(ctx, port)
 }
 
+func TestServer_RunNotify(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: sock, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", sock)
+
+	s := Server{Version: "1.0", TemplLocation: "../webapp/templates/*"}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx, 0)
+		close(done)
+	}()
+
+	read := func() string {
+		buf := make([]byte, 64)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, rerr := conn.Read(buf)
+		require.NoError(t, rerr)
+		return string(buf[:n])
+	}
+	assert.Equal(t, "READY=1", read())
+	cancel()
+	assert.Equal(t, "STOPPING=1", read())
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server didn't stop")
+	}
+}
+
 func TestServer_getFeedCtrl(t *testing.T) {
 
 	store := &mocks.StoreMock{
@@ -322,6 +359,105 @@ func TestServer_regenerateRSSCtrl(t *testing.T) {
 	require.Equal(t, "blah", yt.StoreRSSCalls()[1].Rss)
 }
 
+func TestServer_addLinkCtrl(t *testing.T) {
+	links := &mocks.LinkAdderMock{AddLinkFunc: func(_ context.Context, link, _ string) error {
+		if link == "https://example.com/rejected" {
+			return errors.New("mode isn't available")
+		}
+		return nil
+	}}
+	s := Server{Version: "1.0", Conf: config.Conf{}, AdminPasswd: "123456", Links: links}
+	ts := httptest.NewServer(s.router())
+	defer ts.Close()
+
+	post := func(body, passwd string) int {
+		req, err := http.NewRequest("POST", ts.URL+"/api/add", strings.NewReader(body))
+		require.NoError(t, err)
+		req.SetBasicAuth("admin", passwd)
+		resp, err := ts.Client().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close() // nolint
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusForbidden, post(`{"url":"https://youtu.be/dQw4w9WgXcQ"}`, "bad"))
+	assert.Equal(t, http.StatusOK, post(`{"url":" https://youtu.be/dQw4w9WgXcQ ","mode":"Audio"}`, "123456"))
+	assert.Equal(t, http.StatusOK, post(`{"url":"https://example.com/post"}`, "123456"))
+	assert.Equal(t, http.StatusBadRequest, post(`{"url":"ftp://example.com/file"}`, "123456"))
+	assert.Equal(t, http.StatusBadRequest, post(`{"url":"example.com"}`, "123456"))
+	assert.Equal(t, http.StatusBadRequest, post(`not json`, "123456"))
+	assert.Equal(t, http.StatusBadRequest, post(`{"url":"https://example.com/rejected","mode":"tts"}`, "123456"))
+
+	calls := links.AddLinkCalls()
+	require.Len(t, calls, 3)
+	assert.Equal(t, "https://youtu.be/dQw4w9WgXcQ", calls[0].Link)
+	assert.Equal(t, "audio", calls[0].Mode)
+	assert.Equal(t, "https://example.com/post", calls[1].Link)
+	assert.Empty(t, calls[1].Mode)
+
+	// off without a link adder
+	s.Links = nil
+	ts2 := httptest.NewServer(s.router())
+	defer ts2.Close()
+	req, err := http.NewRequest("POST", ts2.URL+"/api/add", strings.NewReader(`{"url":"https://youtu.be/dQw4w9WgXcQ"}`))
+	require.NoError(t, err)
+	req.SetBasicAuth("admin", "123456")
+	resp, err := ts2.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close() // nolint
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestServer_getYoutubeFeedCtrlSortLimit(t *testing.T) {
+	yt := &mocks.YoutubeSvcMock{
+		RSSFeedFunc: func(youtube.FeedInfo) (string, error) {
+			return "<rss></rss>", nil
+		},
+	}
+	s := Server{Version: "1.0", TemplLocation: "../webapp/templates/*", YoutubeSvc: yt, Conf: config.Conf{}}
+	ts := httptest.NewServer(s.router())
+	defer ts.Close()
+
+	for _, q := range []string{"?sort=added&limit=20", "?sort=newest", "?limit=0", "?limit=abc"} {
+		resp, err := ts.Client().Get(ts.URL + "/yt/rss/chan1" + q)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		if q == "?sort=added&limit=20" {
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			continue
+		}
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, q)
+	}
+
+	require.Equal(t, 1, len(yt.RSSFeedCalls()), "bad queries rejected")
+	assert.Equal(t, youtube.SortAdded, yt.RSSFeedCalls()[0].Cinfo.Sort)
+	assert.Equal(t, 20, yt.RSSFeedCalls()[0].Cinfo.Limit)
+}
+
+func TestServer_getYoutubeFeedCtrlKind(t *testing.T) {
+	yt := &mocks.YoutubeSvcMock{
+		RSSFeedFunc: func(youtube.FeedInfo) (string, error) {
+			return "<rss></rss>", nil
+		},
+	}
+	s := Server{Version: "1.0", TemplLocation: "../webapp/templates/*", YoutubeSvc: yt, Conf: config.Conf{}}
+	ts := httptest.NewServer(s.router())
+	defer ts.Close()
+
+	resp, err := ts.Client().Get(ts.URL + "/yt/rss/chan1?kind=article,digest")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = ts.Client().Get(ts.URL + "/yt/rss/chan1?kind=article,blog")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	require.Equal(t, 1, len(yt.RSSFeedCalls()), "bad kind rejected")
+	assert.Equal(t, []ytfeed.Kind{ytfeed.KindArticle, ytfeed.KindDigest}, yt.RSSFeedCalls()[0].Cinfo.Kinds)
+}
+
 func TestServer_removeEntryCtrl(t *testing.T) {
 	yt := &mocks.YoutubeSvcMock{
 		RemoveEntryFunc: func(ytfeed.Entry) error {
@@ -407,3 +543,21 @@ func TestServer_configCtrl(t *testing.T) {
 	assert.Contains(t, body, "this is feed1")
 	assert.Contains(t, body, "http://example.com/feed1")
 }
+
+func TestServer_getMediaCtrlPeaks(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ep1.peaks.json"), []byte(`{"version":2}`), 0o600))
+	conf := config.Conf{}
+	conf.YouTube.BaseURL = "http://localhost/yt/media"
+	conf.YouTube.FilesLocation = dir
+	s := Server{Version: "1.0", TemplLocation: "../webapp/templates/*", Conf: conf, MediaRedirectBase: "https://r2.example.com"}
+	ts := httptest.NewServer(s.router())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/yt/media/ep1.peaks.json")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "*", resp.Header.Get("Access-Control-Allow-Origin"))
+}
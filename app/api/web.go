@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/dustin/go-humanize"
@@ -112,6 +113,7 @@ func (s *Server) getFeedSourceCtrl(w http.ResponseWriter, r *http.Request) {
 		if er != nil {
 			return nil, er
 		}
+		items = ytfeed.Released(items)
 
 		// fill formatted duration and file path
 		for i, item := range items {
@@ -222,6 +224,7 @@ func (s *Server) getYoutubeChannelsPageCtrl(w http.ResponseWriter, r *http.Reque
 			ChannelURL  string
 			LastUpdated time.Time
 			RssURL      string
+			EpisodesURL string
 		}
 		var channelItems []channelItem
 
@@ -234,6 +237,7 @@ func (s *Server) getYoutubeChannelsPageCtrl(w http.ResponseWriter, r *http.Reque
 				FeedInfo:    k,
 				RssURL:      s.Conf.YouTube.BaseChanURL + k.ID,
 				ChannelURL:  "https://youtube.com/channel/" + k.ID,
+				EpisodesURL: "/yt/episodes/" + url.PathEscape(k.ID),
 				LastUpdated: items[0].Published.In(time.UTC),
 			}
 			if k.Type == ytfeed.FTPlaylist {
@@ -266,6 +270,80 @@ func (s *Server) getYoutubeChannelsPageCtrl(w http.ResponseWriter, r *http.Reque
 	_, _ = w.Write(data) // nolint
 }
 
+// episodesPageItems limits the episodes of a channel page
+const episodesPageItems = 100
+
+// GET /yt/episodes/{channel} - renders page with episodes of a YouTube channel or the bot feed,
+// played with their waveforms
+func (s *Server) getYoutubeEpisodesPageCtrl(w http.ResponseWriter, r *http.Request) {
+	channel := r.PathValue("channel")
+	name := ""
+	for _, c := range s.Conf.YouTube.Channels {
+		if c.ID == channel {
+			name = c.Name
+		}
+	}
+	if name == "" && channel == s.Conf.TelegramBot.FeedName {
+		name = s.Conf.TelegramBot.FeedTitle
+	}
+	if name == "" {
+		s.renderErrorPage(w, r, fmt.Errorf("channel %s not found", channel), http.StatusNotFound)
+		return
+	}
+
+	data, err := s.cache.Get("episodes::"+channel, func() ([]byte, error) {
+		entries, err := s.YoutubeStore.Load(channel, episodesPageItems)
+		if err != nil {
+			return nil, err
+		}
+		type episodeItem struct {
+			ytfeed.Entry
+			MediaURL string
+			PeaksURL string // "" = no waveform, a plain player
+		}
+		baseURL := strings.TrimSuffix(s.Conf.YouTube.BaseURL, "/")
+		var episodes []episodeItem
+		for _, e := range ytfeed.Released(entries) {
+			if e.File == "" {
+				continue
+			}
+			item := episodeItem{Entry: e, MediaURL: baseURL + "/" + path.Base(e.File)}
+			if e.Peaks != "" {
+				item.PeaksURL = baseURL + "/" + path.Base(e.Peaks)
+			}
+			if e.Duration > 0 {
+				item.DurationFmt = (time.Duration(e.Duration) * time.Second).String()
+			}
+			episodes = append(episodes, item)
+		}
+
+		tmplData := struct {
+			Name     string
+			Episodes []episodeItem
+			Count    int
+			RSSLink  string
+		}{
+			Name:     name,
+			Episodes: episodes,
+			Count:    len(episodes),
+			RSSLink:  strings.TrimSuffix(s.Conf.System.BaseURL, "/") + "/yt/rss/" + channel,
+		}
+
+		res := bytes.NewBuffer(nil)
+		err = s.templates.ExecuteTemplate(res, "episodes.tmpl", &tmplData)
+		return res.Bytes(), err
+	})
+
+	if err != nil {
+		s.renderErrorPage(w, r, err, 400)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data) // nolint
+}
+
 // GET /feed/{name}/sources - renders page with feed's list of sources
 func (s *Server) getSourcesPageCtrl(w http.ResponseWriter, r *http.Request) {
 	feedName := r.PathValue("name")
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -246,6 +247,8 @@ func TestServer_getFeedSourceCtrl(t *testing.T) {
 				} `xml:"thumbnail"`
 			}{Description: "Video 1 description"},
 		},
+		{Title: "Scheduled video", VideoID: "vid2", ChannelID: "channel1", Duration: 60, File: "/path/to/file2.mp3",
+			Published: time.Date(2025, 8, 4, 12, 0, 0, 0, time.UTC), PublishAt: time.Date(2025, 8, 5, 9, 0, 0, 0, time.UTC)},
 	}
 	ytStoreMock.LoadFunc = func(channelID string, maxItems int) ([]ytfeed.Entry, error) {
 		return entries, nil
@@ -274,6 +277,7 @@ func TestServer_getFeedSourceCtrl(t *testing.T) {
 	assert.Contains(t, body, "Video 1")
 	assert.Contains(t, body, "http://localhost/yt/file1.mp3")
 	assert.Contains(t, body, "1h0m0s") // duration formatted
+	assert.NotContains(t, body, "Scheduled video", "hidden until released")
 
 	// check footer
 	currentYear := time.Now().Year()
@@ -334,6 +338,58 @@ func TestServer_getYoutubeChannelsPageCtrl(t *testing.T) {
 	assert.Contains(t, body, fmt.Sprintf("&copy; %d Umputun", currentYear))
 }
 
+func TestServer_getYoutubeEpisodesPageCtrl(t *testing.T) {
+	conf := config.Conf{}
+	conf.YouTube.Channels = []youtube.FeedInfo{{ID: "channel1", Name: "Channel 1", Type: ytfeed.FTChannel}}
+	conf.YouTube.BaseURL = "http://example.com/yt/media/"
+	conf.System.BaseURL = "http://example.com"
+	conf.TelegramBot.FeedName, conf.TelegramBot.FeedTitle = "manual", "My Podcast"
+
+	ytStoreMock := &mocks.YoutubeStoreMock{}
+	ytStoreMock.LoadFunc = func(channelID string, maxItems int) ([]ytfeed.Entry, error) {
+		return []ytfeed.Entry{
+			{ChannelID: channelID, VideoID: "v1", Title: "With waveform", File: "/srv/yt/ep1.mp3",
+				Peaks: "/srv/yt/ep1.peaks.json", Duration: 90, Published: time.Date(2025, 8, 3, 12, 0, 0, 0, time.UTC)},
+			{ChannelID: channelID, VideoID: "v2", Title: "Plain player", File: "/srv/yt/ep2.mp3"},
+			{ChannelID: channelID, VideoID: "v3", Title: "Scheduled", File: "/srv/yt/ep3.mp3", PublishAt: time.Now().Add(time.Hour)},
+		}, nil
+	}
+
+	srv := setupTestServer(t, conf, nil, ytStoreMock)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /yt/episodes/{channel}", srv.getYoutubeEpisodesPageCtrl)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	get := func(channel string) (int, string) {
+		resp, err := http.Get(ts.URL + "/yt/episodes/" + channel)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	code, body := get("channel1")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "Channel 1")
+	assert.Contains(t, body, "2 episodes")
+	assert.Contains(t, body, `data-peaks="http://example.com/yt/media/ep1.peaks.json"`)
+	assert.Contains(t, body, `src="http://example.com/yt/media/ep1.mp3"`)
+	assert.Contains(t, body, "1m30s")
+	assert.Contains(t, body, `src="http://example.com/yt/media/ep2.mp3"`)
+	assert.Equal(t, 1, strings.Count(body, "<canvas"), "waveform only for the episode with peaks")
+	assert.NotContains(t, body, "Scheduled")
+	assert.Contains(t, body, "http://example.com/yt/rss/channel1")
+
+	code, body = get("manual")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "My Podcast")
+
+	_, body = get("unknown")
+	assert.Contains(t, body, "channel unknown not found")
+}
+
 func TestServer_renderErrorPage(t *testing.T) {
 	srv := setupTestServer(t, config.Conf{}, nil, nil)
 
@@ -4,11 +4,15 @@ package config
 import (
 	"os"
 	"regexp"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/umputun/feed-master/app/feed"
+	"github.com/umputun/feed-master/app/logs"
+	"github.com/umputun/feed-master/app/tools"
+	"github.com/umputun/feed-master/app/tracing"
 	"github.com/umputun/feed-master/app/youtube"
 )
 
@@ -37,6 +41,8 @@ type Conf struct {
 		RSSLocation     string             `yaml:"rss_location"`
 		SkipShorts      time.Duration      `yaml:"skip_shorts"`
 		CookiesFile     string             `yaml:"cookies_file"`
+		DownloadArchive string             `yaml:"download_archive"` // yt-dlp --download-archive file shared with other runs, "" = none
+		Waveform        bool               `yaml:"waveform"`         // new episodes get the waveform (peaks JSON) of the web player
 		DisableUpdates  bool               `yaml:"disable_updates"`
 		YtDlpUpdate     struct {
 			Interval time.Duration `yaml:"interval"`
@@ -47,13 +53,83 @@ type Conf struct {
 	TelegramBot struct {
 		Enabled         bool   `yaml:"enabled"`
 		AllowedUserID   int64  `yaml:"allowed_user_id"`
+		AdminChatID     int64  `yaml:"admin_chat_id"` // background job failures are reported here with a retry button, 0 = none
 		FeedName        string `yaml:"feed_name"`
 		FeedTitle       string `yaml:"feed_title"`
 		FeedDescription string `yaml:"feed_description"`
 		FeedImage       string `yaml:"feed_image"`
+		FeedLanguage    string `yaml:"feed_language"`
 		MaxItems        int    `yaml:"max_items"`
 		TTSEnabled      bool   `yaml:"tts_enabled"`
 		TTSVoice        string `yaml:"tts_voice"`
+		MaxDubSizeMB    int    `yaml:"max_dub_size_mb"` // default cap for downloaded YouTube dubbed tracks, -1 = no limit
+		AudioFormat     string `yaml:"audio_format"`    // format of YouTube dubbed tracks: mp3 (default), m4a or opus
+
+		// Edge TTS voices of untranslated text by its language, over the built-in ones;
+		// an empty voice keeps tts_voice for the language
+		Voices map[string]string `yaml:"voices"`
+
+		RSSPollInterval time.Duration `yaml:"rss_poll_interval"` // how often /rsssub feeds are checked for new posts
+		MaxAge          time.Duration `yaml:"max_age"`           // episodes added earlier are removed (pinned kept), 0 = no limit
+		NitterURL       string        `yaml:"nitter_url"`        // nitter instance tweet threads are unrolled through
+		TranslateTitles bool          `yaml:"translate_titles"`  // translated articles get the title translated too
+		SpeedVariant    float64       `yaml:"speed_variant"`     // tempo of the sped-up copy of each episode (e.g. 1.5), 0 = none
+		AlignCommand    string        `yaml:"align_command"`     // forced aligner (whisperX style JSON) for read-along VTT of articles
+		FeedPlainTitles bool          `yaml:"feed_plain_titles"` // titles without the kind emoji, the kind is in the item category anyway
+		CoverColor      string        `yaml:"cover_color"`       // background (#rrggbb) of covers made for articles without an image, "" = by feed name
+		CoverFont       string        `yaml:"cover_font"`        // font file of the cover titles, "" = default sans font
+
+		// directory metadata (author, category, explicit, owner) of the feed
+		FeedPodcast youtube.PodcastMeta `yaml:"feed_podcast"`
+
+		// once a day short articles voiced during the day are combined into one episode
+		DailyDigest DailyDigest `yaml:"daily_digest"`
+
+		// unread items of a read-later service (WALLABAG_* env) are voiced and archived
+		ReadLater ReadLater `yaml:"read_later"`
+
+		// inbound SMTP gateway, mails (newsletters) sent to it are voiced into the feed
+		Mail Mail `yaml:"mail"`
+
+		// credentials of article page requests: a Netscape cookies file (the
+		// one exported for yt-dlp works) and headers and cookies by domain
+		ArticleCookiesFile string              `yaml:"article_cookies_file"`
+		ArticleSites       map[string]SiteAuth `yaml:"article_sites"`
+
+		// politeness of article page requests, batches of a read-later queue included
+		ArticleFetch ArticleFetch `yaml:"article_fetch"`
+
+		// cleaner versions of article pages (AMP, print) read instead of the page
+		ArticleVariants ArticleVariants `yaml:"article_variants"`
+
+		// what to do with very long articles: ask, reject, voice a summary or split into parts
+		ArticleLimit ArticleLimit `yaml:"article_limit"`
+
+		// jingles and a spoken preamble stitched around each voiced article
+		Intro Intro `yaml:"intro"`
+
+		// keep the original audio and the voice track of /vo episodes to remix them later
+		VoiceoverSources VoiceoverSources `yaml:"vo_sources"`
+
+		// /vo methods in the order they are tried and the video durations each takes
+		Voiceover VoiceoverMethods `yaml:"voiceover"`
+
+		// subtitle dubbing keeping the original audio where nothing is said (music, applause)
+		HybridDub HybridDub `yaml:"hybrid_dub"`
+
+		// pinned message kept up to date with the running jobs and the queue
+		Dashboard Dashboard `yaml:"dashboard"`
+
+		// translation backend and its options for articles and subtitles
+		Translation Translation `yaml:"translation"`
+
+		// named processing presets, picked by prefixing a link ("tech https://...")
+		// or with the preset buttons of the link menu
+		Presets map[string]Preset `yaml:"presets"`
+
+		// default processing of the videos of YouTube channels, by channel id,
+		// however the video reaches the bot
+		ChannelRules map[string]ChannelRule `yaml:"channel_rules"`
 	} `yaml:"telegram_bot"`
 
 	Audio struct {
@@ -70,6 +146,7 @@ type Conf struct {
 		NotionParentPage string `yaml:"notion_parent_page"`
 		Concurrency      int    `yaml:"concurrency"`
 		ChunkSeconds     int    `yaml:"chunk_seconds"`
+		DiarizeCommand   string `yaml:"diarize_command"` // prints RTTM for the audio path appended, e.g. a pyannote script
 	} `yaml:"notes"`
 
 	Read struct {
@@ -77,6 +154,180 @@ type Conf struct {
 		Location string `yaml:"location"` // where read/{id}.md+.html live, default "var/read"
 		Alias    string `yaml:"alias"`    // reserved wayfinding alias for a future reading feed
 	} `yaml:"read"`
+
+	WebSub struct {
+		Hubs []string `yaml:"hubs"` // pinged when a /yt/rss feed gets an entry, advertised in those feeds
+	} `yaml:"websub"`
+
+	// per-client limits of /yt/media, a misbehaving player can't take the whole uplink
+	MediaLimits MediaLimits `yaml:"media_limits"`
+
+	// external binaries by name (yt-dlp, ffmpeg, ffprobe, vot-cli), missing ones are taken from PATH
+	Tools map[string]tools.Tool `yaml:"tools"`
+	// confinement of every external command, see tools.Sandbox
+	Sandbox tools.Sandbox `yaml:"sandbox"`
+	// how many downloads, ffmpeg transcodes and TTS sessions run at once, the rest queue
+	Concurrency tools.Limits `yaml:"concurrency"`
+	// per-job logs of the external commands, for /debug
+	JobLogs tools.JobLogs `yaml:"job_logs"`
+	// process log format and levels, by module too
+	Log logs.Config `yaml:"log"`
+	// OpenTelemetry export of the job traces, off without an endpoint
+	Tracing tracing.Config `yaml:"tracing"`
+}
+
+// MediaLimits caps what one client (IP) may take from the episode files, zero values = no limit
+type MediaLimits struct {
+	RequestsPerMinute int `yaml:"requests_per_minute"` // over that 429 until the minute is up
+	MaxConnections    int `yaml:"max_connections"`     // concurrent downloads
+	RateKB            int `yaml:"rate_kb"`             // KB/s of each download
+}
+
+// DailyDigest configures the daily episode of short articles
+type DailyDigest struct {
+	Time        string `yaml:"time"`         // "HH:MM" local time to build the digest, empty = off
+	MaxItemMin  int    `yaml:"max_item_min"` // articles up to this many minutes of audio go in, default 10
+	MinItems    int    `yaml:"min_items"`    // fewer articles than this make no digest, default 2
+	RemoveItems bool   `yaml:"remove_items"` // drop the combined articles from the feed
+}
+
+// ReadLater configures voicing of a read-later queue
+type ReadLater struct {
+	Interval time.Duration `yaml:"interval"` // queue check period, default 15m
+	Limit    int           `yaml:"limit"`    // items taken per check, default 5
+	Preset   string        `yaml:"preset"`   // processing preset of the items
+}
+
+// Mail configures the inbound mail gateway
+type Mail struct {
+	Listen     string   `yaml:"listen"`     // SMTP listen address, e.g. ":2525" (127.0.0.1), empty = off
+	Recipients []string `yaml:"recipients"` // accepted RCPT TO addresses, required
+	Senders    []string `yaml:"senders"`    // allowed From addresses or "@domain", required
+	Preset     string   `yaml:"preset"`     // processing preset of the mails
+}
+
+// ArticleLimit is the policy for articles over the length limit
+type ArticleLimit struct {
+	MaxChars  int    `yaml:"max_chars"`  // longer articles hit the policy, default 100000, -1 = no limit
+	Policy    string `yaml:"policy"`     // "ask" (buttons), "reject", "summarize" or "split", default "ask"
+	PartChars int    `yaml:"part_chars"` // split: characters per part, default 40000
+	// policy of sources with no one to press the buttons (read-later, RSS, mail) when Policy is "ask":
+	// "reject", "summarize" or "split", default "reject"
+	Background string `yaml:"background"`
+}
+
+// SiteAuth is what article page requests to a site (and its subdomains)
+// carry, for subscription-only sources
+type SiteAuth struct {
+	Headers map[string]string `yaml:"headers"` // e.g. Authorization: Bearer ...
+	Cookies map[string]string `yaml:"cookies"` // name: value, next to the ones of the cookies file
+}
+
+// ArticleFetch is how politely article pages are fetched. Pages of one domain
+// are fetched one at a time in any case.
+type ArticleFetch struct {
+	Delay  time.Duration `yaml:"delay"`  // gap between requests to a domain, default 2s, -1 = none
+	Robots bool          `yaml:"robots"` // skip pages robots.txt disallows, its Crawl-delay stretches the gap
+}
+
+// Translation tunes how text is translated. The source language goes with
+// every chunk of a text, so chunks of one article are read the same way.
+type Translation struct {
+	SourceLang         string `yaml:"source_lang"`         // language of the texts, "" = detected once per text
+	Formality          string `yaml:"formality"`           // "formal" or "informal" address, for providers supporting it
+	PreserveFormatting bool   `yaml:"preserve_formatting"` // send paragraphs and line breaks as HTML markup the translation keeps
+	Speller            bool   `yaml:"speller"`             // fix typos of the source before translating
+	SAKeyFile          string `yaml:"sa_key_file"`         // service account authorized key, IAM tokens instead of the API key
+
+	Provider string             `yaml:"provider"` // "yandex" (default), "offline" or "llm", presets may pick another one
+	Offline  OfflineTranslation `yaml:"offline"`
+	LLM      LLMTranslation     `yaml:"llm"`
+}
+
+// LLMTranslation is an OpenAI-compatible chat model translating with the
+// previous passage as context, keyed by LLM_API_KEY (or GROQ_API_KEY)
+type LLMTranslation struct {
+	Model   string `yaml:"model"`    // chat model, empty = llama-3.3-70b-versatile
+	BaseURL string `yaml:"base_url"` // OpenAI-compatible API base, empty = Groq
+}
+
+// OfflineTranslation is a self-hosted LibreTranslate-compatible server
+// (Argos Translate / CTranslate2 models), e.g. the libretranslate container
+type OfflineTranslation struct {
+	URL    string `yaml:"url"`     // base URL, e.g. http://localhost:5000, empty = no offline backend
+	APIKey string `yaml:"api_key"` // only for servers started with keys required
+}
+
+// ArticleVariants are the cleaner versions of article pages tried first, the
+// page itself is read when none works
+type ArticleVariants struct {
+	AMP   bool                `yaml:"amp"`   // read pages from the AMP version they link to (<link rel="amphtml">) unless it's shorter
+	Sites map[string][]string `yaml:"sites"` // domain → variants in order: amp (/amp path), print (?print=1) or a URL template
+}
+
+// Intro is what the bot feed puts around each voiced article. Jingles are
+// concatenated as is, so they must be MP3s encoded like the TTS output.
+type Intro struct {
+	Jingle   string `yaml:"jingle"`   // MP3 file played before the article, empty = none
+	Outro    string `yaml:"outro"`    // MP3 file played after the article, empty = none
+	Preamble bool   `yaml:"preamble"` // spoken "Статья с сайта X, опубликована 3 марта, время чтения 12 минут"
+}
+
+// VoiceoverSources keeps what a vot-cli voiceover is made of: the translated
+// voice and the original audio, so /remix rebuilds the episode without
+// downloading again
+type VoiceoverSources struct {
+	Keep           bool    `yaml:"keep"`            // keep the voice track and the original audio next to the episode
+	OriginalVolume float64 `yaml:"original_volume"` // level of the original under the voice (0.2 = 20%), 0 = voice only
+	MaxSizeMB      int     `yaml:"max_size_mb"`     // disk quota of the kept files, the oldest are dropped first, 0 = no limit
+}
+
+// Dashboard is a message pinned in the admin chat (or the user's one) the bot
+// edits with the running jobs, their ETAs, the notes queue and the tools load
+type Dashboard struct {
+	Enabled  bool          `yaml:"enabled"`
+	Interval time.Duration `yaml:"interval"` // how often it's refreshed, default 30s
+}
+
+// VoiceoverMethods orders the /vo methods, youtube-dubbed (official dub),
+// vot-cli and subtitles-tts, and limits them by video duration. A method
+// failing or not taking the video passes it on to the next one.
+type VoiceoverMethods struct {
+	Methods     []string                 `yaml:"methods"`      // default youtube-dubbed, vot-cli, subtitles-tts
+	MaxDuration map[string]time.Duration `yaml:"max_duration"` // longer videos skip the method, 0 = any; vot-cli 4h by default
+	MinDuration map[string]time.Duration `yaml:"min_duration"` // shorter videos skip the method; subtitles-tts takes over where vot-cli stops by default
+}
+
+// HybridDub places the voiced subtitles between the stretches of the original
+// audio no subtitle covers, instead of reading all the subtitles back to back
+type HybridDub struct {
+	Enabled bool          `yaml:"enabled"`
+	MinGap  time.Duration `yaml:"min_gap"` // shorter stretches without subtitles are dropped, default 3s
+}
+
+// Preset is a named set of processing options for links sent to the bot
+type Preset struct {
+	Summarize  bool     `yaml:"summarize"`   // articles: voice an LLM summary instead of the full text
+	Translate  *bool    `yaml:"translate"`   // nil = translate non-Russian text, false = keep the original language
+	Voice      string   `yaml:"voice"`       // Edge TTS voice, empty = tts_voice
+	Rate       string   `yaml:"rate"`        // Edge TTS speech rate, e.g. "-15%" or "+20%"
+	SkipCode   bool     `yaml:"skip_code"`   // articles: drop code blocks
+	Images     bool     `yaml:"images"`      // articles: read figure captions and image alt text
+	Citations  string   `yaml:"citations"`   // articles: "strip" drops [12] markers, "inline" reads footnotes after the paragraph
+	Emoji      string   `yaml:"emoji"`       // articles: "say" reads common emoji as words, "keep" leaves them to TTS, dropped by default
+	DubbedOnly bool     `yaml:"dubbed_only"` // voiceover: official YouTube dub only, no vot-cli/subtitles fallback
+	Methods    []string `yaml:"methods"`     // voiceover: methods to try in order, empty = voiceover.methods
+	Lang       string   `yaml:"lang"`        // voiceover: language spoken in the video, empty = detected
+	Translator string   `yaml:"translator"`  // translation backend, "yandex", "offline" or "llm", empty = translation.provider
+}
+
+// ChannelRule is the default processing of the videos of a YouTube channel
+type ChannelRule struct {
+	Action     string `yaml:"action"`      // "vo" or "audio" starts right away instead of the link menu, "" = ask
+	Quality    string `yaml:"quality"`     // yt-dlp --audio-quality of the download, 0 (best) to 10 or a bitrate like 128K, "" = dl_template's
+	StripTitle string `yaml:"strip_title"` // regexp cut from the video titles, e.g. a "[Show #12] " prefix
+	// cap for the dubbed tracks of the channel's videos, 0 = telegram_bot.max_dub_size_mb, -1 = no limit
+	MaxDubSizeMB int `yaml:"max_dub_size_mb"`
 }
 
 // Source defines config section for source
@@ -98,6 +349,8 @@ type Feed struct {
 	ExtendDateTitle string   `yaml:"ext_date"`
 	Author          string   `yaml:"author"`
 	OwnerEmail      string   `yaml:"owner_email"`
+	Category        string   `yaml:"category"` // Apple category, "Technology" or "News > Tech News"
+	Explicit        bool     `yaml:"explicit"`
 }
 
 // Filter defines feed section for a feed filter~
@@ -242,6 +495,53 @@ func (c *Conf) setDefaults() {
 	if c.TelegramBot.TTSVoice == "" {
 		c.TelegramBot.TTSVoice = "ru-RU-DmitryNeural" // Russian male voice for Edge TTS
 	}
+	if c.TelegramBot.AudioFormat == "" {
+		c.TelegramBot.AudioFormat = "mp3"
+	}
+	if c.TelegramBot.MaxDubSizeMB == 0 {
+		c.TelegramBot.MaxDubSizeMB = 500 // 128k mp3 of a 4h video is ~230MB
+	}
+	if c.TelegramBot.NitterURL == "" {
+		c.TelegramBot.NitterURL = "https://nitter.net"
+	}
+	if c.TelegramBot.ArticleLimit.MaxChars == 0 {
+		c.TelegramBot.ArticleLimit.MaxChars = 100000 // ~2 hours of audio
+	}
+	if c.TelegramBot.ArticleLimit.Policy == "" {
+		c.TelegramBot.ArticleLimit.Policy = "ask"
+	}
+	if c.TelegramBot.ArticleLimit.Background == "" {
+		c.TelegramBot.ArticleLimit.Background = "reject"
+	}
+	if c.TelegramBot.ArticleLimit.PartChars == 0 {
+		c.TelegramBot.ArticleLimit.PartChars = 40000
+	}
+	if c.TelegramBot.RSSPollInterval == 0 {
+		c.TelegramBot.RSSPollInterval = 30 * time.Minute
+	}
+	if c.TelegramBot.DailyDigest.MaxItemMin == 0 {
+		c.TelegramBot.DailyDigest.MaxItemMin = 10
+	}
+	if c.TelegramBot.DailyDigest.MinItems == 0 {
+		c.TelegramBot.DailyDigest.MinItems = 2
+	}
+	if c.TelegramBot.ArticleFetch.Delay == 0 {
+		c.TelegramBot.ArticleFetch.Delay = 2 * time.Second
+	}
+	if c.TelegramBot.ReadLater.Interval == 0 {
+		c.TelegramBot.ReadLater.Interval = 15 * time.Minute
+	}
+	if c.TelegramBot.ReadLater.Limit == 0 {
+		c.TelegramBot.ReadLater.Limit = 5
+	}
+	if len(c.TelegramBot.Presets) > 0 {
+		// preset names are matched case-insensitively against the first word of a message
+		presets := make(map[string]Preset, len(c.TelegramBot.Presets))
+		for name, p := range c.TelegramBot.Presets {
+			presets[strings.ToLower(strings.TrimSpace(name))] = p
+		}
+		c.TelegramBot.Presets = presets
+	}
 
 	// set notes defaults
 	if c.Notes.MDLocation == "" {
@@ -271,4 +571,8 @@ func (c *Conf) setDefaults() {
 	if c.Read.Location == "" {
 		c.Read.Location = "var/read"
 	}
+
+	if c.JobLogs.Dir == "" {
+		c.JobLogs.Dir = "var/logs/jobs"
+	}
 }
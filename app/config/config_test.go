@@ -42,6 +42,16 @@ func TestLoad(t *testing.T) {
 
 	assert.Equal(t, "(one|two|three)", r.Feeds["filtered2"].Filter.Title)
 	assert.Equal(t, true, r.Feeds["filtered2"].Filter.Invert)
+
+	require.Len(t, r.TelegramBot.Presets, 3)
+	longread, ok := r.TelegramBot.Presets["longread"]
+	require.True(t, ok, "preset names lowercased")
+	assert.True(t, longread.Summarize)
+	require.NotNil(t, longread.Translate)
+	assert.True(t, *longread.Translate)
+	assert.Equal(t, "-15%", longread.Rate)
+	assert.Equal(t, Preset{SkipCode: true, Rate: "+20%"}, r.TelegramBot.Presets["tech"])
+	assert.True(t, r.TelegramBot.Presets["vo"].DubbedOnly)
 }
 
 func TestLoadConfigNotFoundFile(t *testing.T) {
@@ -0,0 +1,159 @@
+package duration
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// mp3 header tables, indexed by [version][layer] where version is 0 for
+// MPEG-1 and 1 for MPEG-2/2.5, layer is 0..2 for Layer I..III
+var mp3Bitrates = [2][3][15]int{
+	{
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	},
+	{
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	},
+}
+
+// sample rates by the header version bits: 0 = MPEG-2.5, 2 = MPEG-2, 3 = MPEG-1
+var mp3SampleRates = map[byte][3]int{
+	0: {11025, 12000, 8000},
+	2: {22050, 24000, 16000},
+	3: {44100, 48000, 32000},
+}
+
+// mp3Frame is a parsed frame header
+type mp3Frame struct {
+	size    int // bytes, header included
+	samples int
+	rate    int
+	xingOff int // offset of a possible Xing/Info tag
+}
+
+// parseMP3Header decodes a 4-byte frame header, ok is false for anything
+// that is not a valid MPEG audio header (garbage, free-format bitrate)
+func parseMP3Header(h []byte) (f mp3Frame, ok bool) {
+	if len(h) < 4 || h[0] != 0xFF || h[1]&0xE0 != 0xE0 {
+		return f, false
+	}
+	verBits := (h[1] >> 3) & 0x03
+	layerBits := (h[1] >> 1) & 0x03
+	brIdx := int(h[2] >> 4)
+	srIdx := int((h[2] >> 2) & 0x03)
+	padding := int((h[2] >> 1) & 0x01)
+	mono := (h[3] >> 6) == 0x03
+	if verBits == 1 || layerBits == 0 || brIdx == 0 || brIdx == 15 || srIdx == 3 {
+		return f, false
+	}
+
+	ver := 1 // MPEG-2 and 2.5 share tables
+	if verBits == 3 {
+		ver = 0
+	}
+	layer := 3 - int(layerBits) // 0 = Layer I
+	bitrate := mp3Bitrates[ver][layer][brIdx] * 1000
+	f.rate = mp3SampleRates[verBits][srIdx]
+
+	switch {
+	case layer == 0:
+		f.samples = 384
+		f.size = (12*bitrate/f.rate + padding) * 4
+	case layer == 2 && ver == 1:
+		f.samples = 576
+		f.size = 72*bitrate/f.rate + padding
+	default:
+		f.samples = 1152
+		f.size = 144*bitrate/f.rate + padding
+	}
+
+	// Xing/Info tag sits after the side info
+	switch {
+	case ver == 0 && !mono:
+		f.xingOff = 36
+	case ver == 0 && mono, ver == 1 && !mono:
+		f.xingOff = 21
+	default:
+		f.xingOff = 13
+	}
+	return f, true
+}
+
+// MP3Length counts MPEG audio frames in r and returns the exact playing time.
+// Unlike header-based estimates it is right for concatenated files (TTS chunks
+// glued together) and VBR without a Xing tag. ID3v2 tags, junk between frames
+// and the Xing/Info frame are skipped.
+func MP3Length(r io.Reader) (time.Duration, error) {
+	br := bufio.NewReaderSize(r, 64*1024)
+
+	if hdr, err := br.Peek(10); err == nil && bytes.Equal(hdr[:3], []byte("ID3")) {
+		size := int(hdr[6]&0x7F)<<21 | int(hdr[7]&0x7F)<<14 | int(hdr[8]&0x7F)<<7 | int(hdr[9]&0x7F)
+		size += 10
+		if hdr[5]&0x10 != 0 {
+			size += 10 // footer
+		}
+		if _, err := br.Discard(size); err != nil {
+			return 0, fmt.Errorf("skip id3 tag: %w", err)
+		}
+	}
+
+	var seconds float64
+	frames := 0
+	for {
+		h, err := br.Peek(4)
+		if err != nil {
+			break // EOF or a tail shorter than a header
+		}
+		f, ok := parseMP3Header(h)
+		if !ok {
+			if _, err := br.Discard(1); err != nil {
+				break
+			}
+			continue // resync
+		}
+		if frames == 0 {
+			if body, perr := br.Peek(min(f.size, f.xingOff+4)); perr == nil && len(body) >= f.xingOff+4 {
+				tag := string(body[f.xingOff : f.xingOff+4])
+				if tag == "Xing" || tag == "Info" {
+					if _, err := br.Discard(f.size); err != nil {
+						break
+					}
+					frames = -1 // don't count it, don't look again
+					continue
+				}
+			}
+		}
+		n, err := br.Discard(f.size)
+		if n < f.size || err != nil {
+			break // truncated last frame doesn't play
+		}
+		if frames < 0 {
+			frames = 0
+		}
+		frames++
+		seconds += float64(f.samples) / float64(f.rate)
+	}
+	if frames <= 0 {
+		return 0, errors.New("no mp3 frames found")
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// MP3FileLength is MP3Length for a file
+func MP3FileLength(fname string) (time.Duration, error) {
+	fh, err := os.Open(fname) //nolint:gosec // file created by us
+	if err != nil {
+		return 0, fmt.Errorf("open %s: %w", fname, err)
+	}
+	defer fh.Close() // nolint
+	return MP3Length(fh)
+}
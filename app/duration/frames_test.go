@@ -0,0 +1,56 @@
+package duration
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mp3 frames of silence: MPEG-1 Layer III 128kbps 44.1kHz stereo (417 bytes)
+// and Edge TTS's MPEG-2 Layer III 48kbps 24kHz mono (144 bytes)
+var (
+	frameV1 = append([]byte{0xFF, 0xFB, 0x90, 0x64}, make([]byte, 413)...)
+	frameV2 = append([]byte{0xFF, 0xF3, 0x64, 0xC4}, make([]byte, 140)...)
+)
+
+func TestMP3Length(t *testing.T) {
+	xing := append([]byte{0xFF, 0xF3, 0x64, 0xC4}, make([]byte, 140)...)
+	copy(xing[13:], "Info")
+	id3 := append([]byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0, 10}, make([]byte, 10)...)
+
+	tbl := []struct {
+		name string
+		data []byte
+		want time.Duration
+	}{
+		{"mpeg1", bytes.Repeat(frameV1, 1000), 26122448979},               // 1000*1152/44100
+		{"edge tts mpeg2", bytes.Repeat(frameV2, 2500), 60 * time.Second}, // 2500*576/24000
+		{"concatenated chunks with tags", bytes.Join([][]byte{id3, bytes.Repeat(frameV2, 1250),
+			[]byte("garbage"), bytes.Repeat(frameV2, 1250)}, nil), 60 * time.Second},
+		{"xing frame not counted", append(xing, bytes.Repeat(frameV2, 250)...), 6 * time.Second},
+		{"truncated last frame", append(bytes.Repeat(frameV2, 250), frameV2[:100]...), 6 * time.Second},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := MP3Length(bytes.NewReader(tt.data))
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want.Seconds(), d.Seconds(), 0.001)
+		})
+	}
+
+	_, err := MP3Length(bytes.NewReader([]byte("not an mp3 at all")))
+	assert.Error(t, err)
+}
+
+func TestMP3FileLength(t *testing.T) {
+	d, err := MP3FileLength("testdata/audio.mp3")
+	require.NoError(t, err)
+	svc := Service{}
+	assert.Equal(t, svc.File("testdata/audio.mp3"), int(d.Seconds()))
+
+	_, err = MP3FileLength("testdata/no-file.mp3")
+	assert.Error(t, err)
+}
@@ -10,6 +10,7 @@ import (
 	"html/template"
 	"io"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
@@ -22,10 +23,13 @@ type Rss2 struct {
 	Version        string          `xml:"version,attr"`
 	NsItunes       string          `xml:"xmlns:itunes,attr"`
 	NsMedia        string          `xml:"xmlns:media,attr"`
+	NsPodcast      string          `xml:"xmlns:podcast,attr,omitempty"`
+	NsAtom         string          `xml:"xmlns:atom,attr,omitempty"`
 	Title          string          `xml:"channel>title"`
 	Language       string          `xml:"channel>language"`
 	Link           string          `xml:"channel>link"`
 	Description    string          `xml:"channel>description"`
+	AtomLinks      []AtomLink      `xml:"channel>atom:link"`
 	PubDate        string          `xml:"channel>pubDate"`
 	LastBuildDate  string          `xml:"channel>lastBuildDate"`
 	ItunesImage    *ItunesImg      `xml:"channel>itunes:image"`
@@ -34,6 +38,7 @@ type Rss2 struct {
 	ItunesSummary  string          `xml:"channel>itunes:summary,omitempty"`
 	ItunesExplicit string          `xml:"channel>itunes:explicit"`
 	ItunesOwner    *ItunesOwner    `xml:"channel>itunes:owner"`
+	ItunesCategory *ItunesCategory `xml:"channel>itunes:category"`
 	ItemList       []Item          `xml:"channel>item"`
 }
 
@@ -49,12 +54,44 @@ type ItunesOwner struct {
 	Name  string `xml:"itunes:name,omitempty"`
 }
 
+// ItunesCategory category element for iTunes, with an optional subcategory
+type ItunesCategory struct {
+	Text string          `xml:"text,attr"`
+	Sub  *ItunesCategory `xml:"itunes:category"`
+}
+
+// NewItunesCategory makes the category element of "Category" or
+// "Category > Subcategory", nil for an empty string
+func NewItunesCategory(category string) *ItunesCategory {
+	name, sub, _ := strings.Cut(category, ">")
+	if name = strings.TrimSpace(name); name == "" {
+		return nil
+	}
+	res := &ItunesCategory{Text: name}
+	if sub = strings.TrimSpace(sub); sub != "" {
+		res.Sub = &ItunesCategory{Text: sub}
+	}
+	return res
+}
+
 // MediaThumbnail image element for media
 type MediaThumbnail struct {
 	XMLName xml.Name `xml:"media:thumbnail,omitempty"`
 	URL     string   `xml:"url,attr"`
 }
 
+// PodcastTranscript is the podcast:transcript element of an item
+type PodcastTranscript struct {
+	URL  string `xml:"url,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// PodcastChapters is the podcast:chapters element of an item
+type PodcastChapters struct {
+	URL  string `xml:"url,attr"`
+	Type string `xml:"type,attr"`
+}
+
 // Enclosure element from item
 type Enclosure struct {
 	URL    string `xml:"url,attr"`
@@ -127,6 +164,19 @@ func Parse(uri string) (result Rss2, err error) {
 	return result.Normalize()
 }
 
+// ParseFile reads rss feed from the file and returns Rss2 items
+func ParseFile(fname string) (Rss2, error) {
+	body, err := os.ReadFile(fname) // nolint
+	if err != nil {
+		return Rss2{}, fmt.Errorf("failed to read %s: %w", fname, err)
+	}
+	result, err := parseFeedContent(body)
+	if err != nil {
+		return Rss2{}, fmt.Errorf("parsing error: %w", err)
+	}
+	return result.Normalize()
+}
+
 func atom1ToRss2(a Atom1) Rss2 {
 	r := Rss2{
 		Title:       a.Title,
@@ -1,10 +1,13 @@
 package feed
 
 import (
+	"encoding/xml"
 	"fmt"
 	"html/template"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
@@ -83,6 +86,22 @@ func TestFeedParse(t *testing.T) {
 	assert.Equal(t, "podcast@radio-t.com (Umputun, Bobuk, Gray, Ksenks, Alek.sys)", r.ItemList[0].Author)
 }
 
+func TestFeedParseFile(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "feed.xml")
+	require.NoError(t, os.WriteFile(fname, []byte(`<rss version="2.0"><channel><title>t</title>
+<item><title> ep 1
+</title><guid>g1</guid><pubDate>Wed, 01 May 2024 10:00:00 +0000</pubDate></item></channel></rss>`), 0o600))
+	res, err := ParseFile(fname)
+	require.NoError(t, err)
+	require.Len(t, res.ItemList, 1)
+	assert.Equal(t, "ep 1", res.ItemList[0].Title)
+	assert.Equal(t, "g1", res.ItemList[0].GUID)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), res.ItemList[0].DT.UTC())
+
+	_, err = ParseFile(filepath.Join(t.TempDir(), "none.xml"))
+	require.Error(t, err)
+}
+
 func TestFeedParseBadBody(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -271,3 +290,15 @@ func TestParseFeedContentIfRSSVersionEmptyContent(t *testing.T) {
 	assert.Equal(t, got.ItemList[0].Content, template.HTML("Content"))
 	assert.Equal(t, got.ItemList[0].Description, template.HTML("Content"))
 }
+
+func TestNewItunesCategory(t *testing.T) {
+	assert.Nil(t, NewItunesCategory(" "))
+	assert.Equal(t, &ItunesCategory{Text: "Technology"}, NewItunesCategory("Technology"))
+	assert.Equal(t, &ItunesCategory{Text: "Society & Culture", Sub: &ItunesCategory{Text: "Documentary"}},
+		NewItunesCategory("Society & Culture > Documentary"))
+
+	b, err := xml.Marshal(Rss2{ItunesCategory: NewItunesCategory("News > Tech News")})
+	require.NoError(t, err)
+	assert.Contains(t, string(b),
+		`<itunes:category text="News"><itunes:category text="Tech News"></itunes:category></itunes:category>`)
+}
@@ -0,0 +1,83 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// WebSub announces feed updates to WebSub (PubSubHubbub) hubs. Players
+// subscribed through a hub refresh right away instead of on their poll
+// schedule.
+type WebSub struct {
+	Hubs   []string
+	Client *http.Client // nil = client with a 10s timeout
+}
+
+// AtomLink is the atom:link element of a channel, hub and self links of WebSub
+type AtomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+// NsAtom is the atom namespace the channel links are declared in
+const NsAtom = "http://www.w3.org/2005/Atom"
+
+// Links returns the channel links of a feed served at self: its hubs and the
+// topic url subscribers register with them
+func (w *WebSub) Links(self string) []AtomLink {
+	if w == nil || len(w.Hubs) == 0 || self == "" {
+		return nil
+	}
+	res := make([]AtomLink, 0, len(w.Hubs)+1)
+	for _, hub := range w.Hubs {
+		res = append(res, AtomLink{Rel: "hub", Href: hub})
+	}
+	return append(res, AtomLink{Rel: "self", Href: self, Type: "application/rss+xml"})
+}
+
+// Publish pings every hub about the updated topics (feed urls). All hubs are
+// tried, the error covers those that failed.
+func (w *WebSub) Publish(ctx context.Context, topics ...string) error {
+	if w == nil || len(w.Hubs) == 0 || len(topics) == 0 {
+		return nil
+	}
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	var errs []error
+	for _, hub := range w.Hubs {
+		for _, topic := range topics {
+			if err := w.ping(ctx, client, hub, topic); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (w *WebSub) ping(ctx context.Context, client *http.Client, hub, topic string) error {
+	form := url.Values{"hub.mode": {"publish"}, "hub.url": {topic}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hub, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("websub hub %s: %w", hub, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("websub publish %s to %s: %w", topic, hub, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // read-only body
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("websub publish %s to %s: status %d", topic, hub, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebSub_Publish(t *testing.T) {
+	var mu sync.Mutex
+	var topics []string
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "publish", r.PostForm.Get("hub.mode"))
+		mu.Lock()
+		topics = append(topics, r.PostForm.Get("hub.url"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer hub.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer failing.Close()
+
+	var nilSub *WebSub
+	require.NoError(t, nilSub.Publish(context.Background(), "http://example.com/yt/rss/manual"))
+
+	ws := &WebSub{Hubs: []string{failing.URL, hub.URL}}
+	err := ws.Publish(context.Background(), "http://example.com/yt/rss/manual", "http://example.com/yt/rss/manual?speed=1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 400")
+	assert.Equal(t, []string{"http://example.com/yt/rss/manual", "http://example.com/yt/rss/manual?speed=1"}, topics,
+		"the working hub pinged despite the failing one")
+}
+
+func TestWebSub_Links(t *testing.T) {
+	var nilSub *WebSub
+	assert.Nil(t, nilSub.Links("http://example.com/yt/rss/manual"))
+	assert.Nil(t, (&WebSub{}).Links("http://example.com/yt/rss/manual"))
+
+	ws := &WebSub{Hubs: []string{"https://pubsubhubbub.appspot.com/"}}
+	assert.Equal(t, []AtomLink{
+		{Rel: "hub", Href: "https://pubsubhubbub.appspot.com/"},
+		{Rel: "self", Href: "http://example.com/yt/rss/manual", Type: "application/rss+xml"},
+	}, ws.Links("http://example.com/yt/rss/manual"))
+}
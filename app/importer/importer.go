@@ -0,0 +1,305 @@
+// Package importer moves the episodes of an upstream feed-master: entries of
+// its database or its RSS feed go to the store, their audio to the files location
+package importer
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	log "github.com/go-pkgz/lgr"
+	bolt "go.etcd.io/bbolt"
+
+	rssfeed "github.com/umputun/feed-master/app/feed"
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	"github.com/umputun/feed-master/app/youtube/store"
+)
+
+// Importer copies episodes of feed-master into the store
+type Importer struct {
+	Store         *store.BoltDB
+	FilesLocation string       // where the audio of the entries goes, youtube.files_location
+	FilesFrom     string       // directory with the feed-master audio, "" = where the entries point or the enclosure URLs
+	Client        *http.Client // downloads enclosures of an RSS feed without FilesFrom
+}
+
+// Channel maps a channel (bucket) of feed-master to the one of the store
+type Channel struct {
+	From, To string
+}
+
+// Stats counts the episodes of an import
+type Stats struct {
+	Imported int // saved with their audio
+	Skipped  int // already in the store
+	Missing  int // left out, no audio file
+}
+
+// ParseChannel parses "from[:to]", to is from when not set
+func ParseChannel(s string) (Channel, error) {
+	from, to, _ := strings.Cut(s, ":")
+	if from = strings.TrimSpace(from); from == "" {
+		return Channel{}, fmt.Errorf("bad channel %q, want from[:to]", s)
+	}
+	if to = strings.TrimSpace(to); to == "" {
+		to = from
+	}
+	return Channel{From: from, To: to}, nil
+}
+
+// FromBolt imports channels of a feed-master database. Without channels it
+// takes every bucket of YouTube entries under its id, other records
+// (processed, items of the main feeds) are skipped.
+func (im *Importer) FromBolt(ctx context.Context, src *bolt.DB, channels []Channel) (Stats, error) {
+	var stats Stats
+	sources, err := sourceEntries(src)
+	if err != nil {
+		return stats, err
+	}
+	if len(channels) == 0 {
+		for _, name := range slices.Sorted(maps.Keys(sources)) {
+			channels = append(channels, Channel{From: name, To: name})
+		}
+	}
+	for _, ch := range channels {
+		entries, ok := sources[ch.From]
+		if !ok {
+			return stats, fmt.Errorf("no channel %s in the source", ch.From)
+		}
+		for _, e := range entries {
+			if err := ctx.Err(); err != nil {
+				return stats, err
+			}
+			guid := e.ItemGUID()
+			file := e.File
+			if im.FilesFrom != "" {
+				file = filepath.Join(im.FilesFrom, filepath.Base(e.File))
+			}
+			if err := im.save(e, ch.To, guid, func(dst string) error { return copyFile(file, dst) }, &stats); err != nil {
+				return stats, err
+			}
+		}
+		log.Printf("[INFO] imported channel %s to %s", ch.From, ch.To)
+	}
+	return stats, nil
+}
+
+// FromRSS imports the items of a feed-master RSS feed, a file or a URL, into the channel
+func (im *Importer) FromRSS(ctx context.Context, src, channel string) (Stats, error) {
+	var stats Stats
+	var rss rssfeed.Rss2
+	var err error
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		rss, err = rssfeed.Parse(src)
+	} else {
+		rss, err = rssfeed.ParseFile(src)
+	}
+	if err != nil {
+		return stats, fmt.Errorf("can't read feed %s: %w", src, err)
+	}
+	for _, item := range rss.ItemList {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		if item.Enclosure.URL == "" {
+			log.Printf("[WARN] skip %q, no enclosure", item.Title)
+			continue
+		}
+		e := rssEntry(item)
+		fetch := func(dst string) error { return im.download(ctx, item.Enclosure.URL, dst) }
+		if im.FilesFrom != "" {
+			file := filepath.Join(im.FilesFrom, item.GetFilename())
+			fetch = func(dst string) error { return copyFile(file, dst) }
+		}
+		if err := im.save(e, channel, item.GUID, fetch, &stats); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// save puts the entry into the channel with its audio, the guid is kept when
+// the channel changes it. Entries already in the store are skipped, a rerun
+// picks up what the last one didn't finish.
+func (im *Importer) save(e ytfeed.Entry, channel, guid string, fetch func(dst string) error, stats *Stats) error {
+	e.ChannelID, e.GUID = channel, ""
+	if guid != e.UID() {
+		e.GUID = guid
+	}
+	found, err := im.Store.Exist(e)
+	if err != nil {
+		return fmt.Errorf("can't check %s: %w", e.VideoID, err)
+	}
+	if found {
+		stats.Skipped++
+		return nil
+	}
+
+	dst := filepath.Join(im.FilesLocation, path.Base(filepath.ToSlash(e.File)))
+	if _, serr := os.Stat(dst); serr != nil {
+		if ferr := fetch(dst); ferr != nil {
+			log.Printf("[WARN] skip %s %q, no audio: %v", e.VideoID, e.Title, ferr)
+			stats.Missing++
+			return nil
+		}
+	}
+	e.File = dst
+	if e.FileSize == 0 {
+		if fi, serr := os.Stat(dst); serr == nil {
+			e.FileSize = fi.Size()
+		}
+	}
+	if channel == im.Store.BotFeed {
+		// bot feeds of older builds have the kind in the title emoji, YouTube videos keep their titles
+		if e.Kind == "" {
+			e.Kind = ytfeed.LegacyKind(e.VideoID, e.Title)
+		}
+		e.Title = e.PlainTitle()
+	}
+	if _, err := im.Store.Save(e); err != nil {
+		return fmt.Errorf("can't save %s: %w", e.VideoID, err)
+	}
+	// the channel poller must not download it again
+	if err := im.Store.SetProcessed(e); err != nil {
+		return fmt.Errorf("can't set processed %s: %w", e.VideoID, err)
+	}
+	stats.Imported++
+	return nil
+}
+
+// sourceEntries reads the buckets of YouTube entries of a feed-master database
+func sourceEntries(src *bolt.DB) (map[string][]ytfeed.Entry, error) {
+	res := map[string][]ytfeed.Entry{}
+	err := src.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			var entries []ytfeed.Entry
+			err := b.ForEach(func(_, v []byte) error {
+				var e ytfeed.Entry
+				if v == nil || json.Unmarshal(v, &e) != nil || e.VideoID == "" || e.ChannelID == "" {
+					return errNotEntries
+				}
+				entries = append(entries, e)
+				return nil
+			})
+			switch {
+			case errors.Is(err, errNotEntries):
+				log.Printf("[DEBUG] skip bucket %s, not youtube entries", name)
+			case err != nil:
+				return err
+			case len(entries) > 0:
+				res[string(name)] = entries
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't read the source: %w", err)
+	}
+	return res, nil
+}
+
+var errNotEntries = errors.New("not youtube entries")
+
+var ytIDRe = regexp.MustCompile(`(?:youtube\.com/watch\?v=|youtu\.be/)([a-zA-Z0-9_-]{11})`)
+
+// rssEntry makes an entry of a feed item. The video id comes from the
+// feed-master guid (channel::video), a YouTube link or a hash of the guid.
+func rssEntry(item rssfeed.Item) ytfeed.Entry {
+	var e ytfeed.Entry
+	guid := item.GUID
+	if guid == "" {
+		guid = item.Enclosure.URL
+	}
+	if _, id, ok := strings.Cut(guid, "::"); ok && id != "" {
+		e.VideoID = id
+	} else if m := ytIDRe.FindStringSubmatch(item.Link); m != nil {
+		e.VideoID = m[1]
+	} else {
+		e.VideoID = fmt.Sprintf("imp_%x", sha1.Sum([]byte(guid)))[:16]
+	}
+	e.Title = item.Title
+	e.Link.Href = item.Link
+	e.Published, e.Updated = item.DT, item.DT
+	e.Media.Description = template.HTML(item.Description) // nolint
+	e.Author.Name = item.Author
+	e.File = item.GetFilename()
+	e.FileSize = int64(item.Enclosure.Length)
+	e.Duration = parseDuration(item.Duration)
+	return e
+}
+
+// parseDuration parses itunes:duration, seconds or [h:]mm:ss, 0 if bad
+func parseDuration(s string) int {
+	res := 0
+	for _, part := range strings.Split(strings.TrimSpace(s), ":") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0
+		}
+		res = res*60 + n
+	}
+	return res
+}
+
+// copyFile copies src to dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src) // nolint
+	if err != nil {
+		return err
+	}
+	defer in.Close() // nolint
+	return writeFile(dst, in)
+}
+
+// download saves the enclosure to dst
+func (im *Importer) download(ctx context.Context, url, dst string) error {
+	client := im.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %s for %s", resp.Status, url)
+	}
+	return writeFile(dst, resp.Body)
+}
+
+// writeFile writes r to dst through a temp file, a failed copy leaves no dst
+func writeFile(dst string, r io.Reader) error {
+	tmp := dst + ".import"
+	out, err := os.Create(tmp) // nolint
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, r); err != nil {
+		_ = out.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err = out.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
@@ -0,0 +1,166 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	"github.com/umputun/feed-master/app/youtube/store"
+)
+
+func newTestImporter(t *testing.T) *Importer {
+	t.Helper()
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "turnip.bdb"), 0o600, &bolt.Options{Timeout: time.Second})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	files := filepath.Join(t.TempDir(), "yt")
+	require.NoError(t, os.MkdirAll(files, 0o750))
+	return &Importer{Store: &store.BoltDB{DB: db}, FilesLocation: files}
+}
+
+func TestParseChannel(t *testing.T) {
+	ch, err := ParseChannel("UCabc:manual")
+	require.NoError(t, err)
+	assert.Equal(t, Channel{From: "UCabc", To: "manual"}, ch)
+	ch, err = ParseChannel("UCabc")
+	require.NoError(t, err)
+	assert.Equal(t, Channel{From: "UCabc", To: "UCabc"}, ch)
+	_, err = ParseChannel(":manual")
+	require.Error(t, err)
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]int{"": 0, "95": 95, "1:35": 95, "1:02:03": 3723, "bad": 0, "1:-2": 0}
+	for in, want := range tests {
+		assert.Equal(t, want, parseDuration(in), in)
+	}
+}
+
+func TestImporter_FromBolt(t *testing.T) {
+	srcFiles := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(srcFiles, "a1.mp3"), []byte("audio1"), 0o600))
+
+	// feed-master db: a channel, the processed marks and items of a main feed
+	src, err := bolt.Open(filepath.Join(t.TempDir(), "feed-master.bdb"), 0o600, &bolt.Options{Timeout: time.Second})
+	require.NoError(t, err)
+	defer src.Close()
+	published := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, src.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucket([]byte("UCabc"))
+		require.NoError(t, err)
+		for i, file := range []string{"/srv/var/yt/a1.mp3", "/srv/var/yt/a2.mp3"} {
+			data, err := json.Marshal(map[string]any{"ChannelID": "UCabc", "VideoID": fmt.Sprintf("vid%d", i+1),
+				"Title": fmt.Sprintf("📼 title %d", i+1), "Published": published.Add(time.Duration(i) * time.Hour), "File": file, "Duration": 60})
+			require.NoError(t, err)
+			require.NoError(t, b.Put([]byte(fmt.Sprintf("%d", i)), data))
+		}
+		p, err := tx.CreateBucket([]byte("processed"))
+		require.NoError(t, err)
+		require.NoError(t, p.Put([]byte("abc"), []byte(published.Format(time.RFC3339))))
+		f, err := tx.CreateBucket([]byte("radio-t"))
+		require.NoError(t, err)
+		return f.Put([]byte("1"), []byte(`{"title":"ep","guid":"https://radio-t.com/p/1"}`))
+	}))
+
+	im := newTestImporter(t)
+	im.FilesFrom = srcFiles
+	im.Store.BotFeed = "manual"
+	_, err = im.FromBolt(context.Background(), src, []Channel{{From: "nope", To: "manual"}})
+	require.Error(t, err, "not a channel of the source")
+
+	stats, err := im.FromBolt(context.Background(), src, []Channel{{From: "UCabc", To: "manual"}})
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Imported: 1, Missing: 1}, stats)
+
+	entries, err := im.Store.Load("manual", 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	e := entries[0]
+	assert.Equal(t, "vid1", e.VideoID)
+	assert.Equal(t, "UCabc::vid1", e.ItemGUID(), "guid of feed-master kept")
+	assert.Equal(t, filepath.Join(im.FilesLocation, "a1.mp3"), e.File)
+	assert.Equal(t, int64(6), e.FileSize)
+	assert.Equal(t, ytfeed.KindVideo, e.Kind)
+	assert.Equal(t, "title 1", e.Title, "kind emoji of the old bot feed title dropped")
+	data, err := os.ReadFile(e.File)
+	require.NoError(t, err)
+	assert.Equal(t, "audio1", string(data))
+	found, _, err := im.Store.CheckProcessed(e)
+	require.NoError(t, err)
+	assert.True(t, found, "not downloaded again by the poller")
+
+	stats, err = im.FromBolt(context.Background(), src, nil)
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Imported: 1, Missing: 1}, stats, "all channels under their ids, feed items skipped")
+	entries, err = im.Store.Load("UCabc", 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Empty(t, entries[0].GUID, "same channel, same guid")
+	assert.Equal(t, "📼 title 1", entries[0].Title, "title of a channel video kept")
+	assert.Empty(t, entries[0].Kind)
+
+	stats, err = im.FromBolt(context.Background(), src, []Channel{{From: "UCabc", To: "manual"}})
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Skipped: 1, Missing: 1}, stats, "rerun")
+}
+
+func TestImporter_FromRSS(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/yt/b2.mp3" {
+			_, _ = w.Write([]byte("audio2"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	feedFile := filepath.Join(t.TempDir(), "manual.xml")
+	rss := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd">
+<channel><title>manual</title>
+<item><title>first</title><link>https://www.youtube.com/watch?v=dQw4w9WgXcQ</link><guid>manual::dQw4w9WgXcQ</guid>
+<pubDate>Wed, 01 May 2024 10:00:00 +0000</pubDate><enclosure url="%[1]s/yt/b1.mp3" length="100" type="audio/mpeg"/>
+<itunes:duration>1:02:03</itunes:duration></item>
+<item><title>second</title><link>https://example.com/post</link><guid>https://example.com/post</guid>
+<pubDate>Thu, 02 May 2024 10:00:00 +0000</pubDate><enclosure url="%[1]s/yt/b2.mp3" length="6" type="audio/mpeg"/></item>
+<item><title>no audio</title><guid>x</guid></item>
+</channel></rss>`, ts.URL)
+	require.NoError(t, os.WriteFile(feedFile, []byte(rss), 0o600))
+
+	im := newTestImporter(t)
+	stats, err := im.FromRSS(context.Background(), feedFile, "tg")
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Imported: 1, Missing: 1}, stats, "b1 is not served")
+
+	entries, err := im.Store.Load("tg", 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "https://example.com/post", entries[0].ItemGUID())
+	assert.Equal(t, "second", entries[0].Title)
+	assert.Equal(t, filepath.Join(im.FilesLocation, "b2.mp3"), entries[0].File)
+
+	srcFiles := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(srcFiles, "b1.mp3"), []byte("audio1"), 0o600))
+	im.FilesFrom = srcFiles
+	stats, err = im.FromRSS(context.Background(), feedFile, "tg")
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Imported: 1, Skipped: 1}, stats, "b1 from the files directory")
+	entries, err = im.Store.Load("tg", 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "dQw4w9WgXcQ", entries[1].VideoID)
+	assert.Equal(t, "manual::dQw4w9WgXcQ", entries[1].ItemGUID())
+	assert.Equal(t, 3723, entries[1].Duration)
+	assert.Equal(t, int64(100), entries[1].FileSize, "recorded size of the feed")
+}
@@ -0,0 +1,200 @@
+// Package logs sets up the process log: text or JSON output and log levels
+// per module, on top of the lgr calls used across the app
+package logs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+
+	log "github.com/go-pkgz/lgr"
+)
+
+// Config is the log section of the config. A zero value keeps the plain lgr
+// text log, INFO and up.
+type Config struct {
+	Format  string            `yaml:"format"`  // text (default) or json
+	Level   string            `yaml:"level"`   // trace, debug, info (default), warn or error
+	Modules map[string]string `yaml:"modules"` // level by module, e.g. youtube/feed: debug
+}
+
+// appPkg is the package path prefix cut from module names
+const appPkg = "github.com/umputun/feed-master/app/"
+
+// levels are the lgr levels by config name
+var levels = map[string]slog.Level{
+	"trace": slog.LevelDebug - 4,
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+// Validate checks the format and the level names
+func (c Config) Validate() error {
+	if c.Format != "" && c.Format != "text" && c.Format != "json" {
+		return fmt.Errorf("bad log format %q, text or json", c.Format)
+	}
+	if _, err := parseLevel(c.Level); err != nil {
+		return err
+	}
+	for module, level := range c.Modules {
+		if _, err := parseLevel(level); err != nil {
+			return fmt.Errorf("module %s: %w", module, err)
+		}
+	}
+	return nil
+}
+
+// Setup configures the global lgr logger writing to out, stdout (and stderr
+// for errors) when nil. Debug turns DEBUG on for every module, as --dbg does.
+func Setup(c Config, debug bool, out io.Writer) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	var outOpts []log.Option
+	if out != nil {
+		outOpts = []log.Option{log.Out(out), log.Err(out)}
+	}
+	if c.Format != "json" && len(c.Modules) == 0 && c.Level == "" {
+		// plain lgr log, with the caller in debug mode
+		if debug {
+			log.Setup(append([]log.Option{log.Debug, log.CallerFile, log.Msec, log.LevelBraces}, outOpts...)...)
+			return nil
+		}
+		log.Setup(append([]log.Option{log.Msec, log.LevelBraces}, outOpts...)...)
+		return nil
+	}
+
+	h := &handler{level: slog.LevelInfo}
+	if c.Level != "" {
+		h.level, _ = parseLevel(c.Level)
+	}
+	if debug {
+		h.level = min(h.level, slog.LevelDebug)
+	}
+	for module, level := range c.Modules {
+		lv, _ := parseLevel(level)
+		h.modules = append(h.modules, moduleLevel{module: strings.Trim(module, "/"), level: lv})
+	}
+	// longest module first, youtube/feed wins over youtube
+	slices.SortFunc(h.modules, func(a, b moduleLevel) int { return len(b.module) - len(a.module) })
+
+	if c.Format == "json" {
+		if out == nil {
+			out = os.Stdout
+		}
+		h.json = slog.NewJSONHandler(out, &slog.HandlerOptions{Level: slog.LevelDebug - 4})
+	} else {
+		h.text = log.New(append([]log.Option{log.Trace, log.Msec, log.LevelBraces}, outOpts...)...)
+	}
+	log.Setup(log.SlogHandler(h), log.Trace) // lgr drops DEBUG and TRACE itself without these
+	return nil
+}
+
+func parseLevel(name string) (slog.Level, error) {
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+	lv, ok := levels[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("bad log level %q, trace, debug, info, warn or error", name)
+	}
+	return lv, nil
+}
+
+type moduleLevel struct {
+	module string
+	level  slog.Level
+}
+
+// handler filters the lgr records by the level of the module logging them
+// and writes them as text or JSON
+type handler struct {
+	level   slog.Level
+	modules []moduleLevel
+	json    slog.Handler
+	text    *log.Logger
+}
+
+// Enabled lets everything through, the level depends on the caller known in Handle only
+func (h *handler) Enabled(context.Context, slog.Level) bool { return true }
+
+// Handle writes the record if its module logs at this level
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	module := callerModule()
+	if r.Level < h.levelOf(module) {
+		return nil
+	}
+	if h.json != nil {
+		r.AddAttrs(slog.String("module", module))
+		return h.json.Handle(ctx, r)
+	}
+	h.text.Logf("[%s] %s", levelName(r.Level), r.Message)
+	return nil
+}
+
+// WithAttrs is not used by lgr, attributes are ignored
+func (h *handler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+// WithGroup is not used by lgr, groups are ignored
+func (h *handler) WithGroup(string) slog.Handler { return h }
+
+// levelOf returns the level of a module: its own, of the closest parent
+// module configured or the global one
+func (h *handler) levelOf(module string) slog.Level {
+	for _, m := range h.modules {
+		if module == m.module || strings.HasPrefix(module, m.module+"/") {
+			return m.level
+		}
+	}
+	return h.level
+}
+
+// callerModule returns the package of the code calling lgr, relative to the
+// app for its own packages ("youtube/feed", "main")
+func callerModule() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		pkg := funcPackage(frame.Function)
+		if pkg != "" && pkg != "github.com/go-pkgz/lgr" && pkg != appPkg+"logs" && pkg != "log/slog" {
+			return strings.TrimPrefix(pkg, appPkg)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// funcPackage cuts the package path from a function name like
+// github.com/umputun/feed-master/app/youtube/feed.(*Downloader).Get
+func funcPackage(fn string) string {
+	slash := strings.LastIndex(fn, "/")
+	dot := strings.Index(fn[slash+1:], ".")
+	if dot < 0 {
+		return fn
+	}
+	return fn[:slash+1+dot]
+}
+
+func levelName(lv slog.Level) string {
+	switch {
+	case lv < slog.LevelDebug:
+		return "TRACE"
+	case lv < slog.LevelInfo:
+		return "DEBUG"
+	case lv < slog.LevelWarn:
+		return "INFO"
+	case lv < slog.LevelError:
+		return "WARN"
+	default:
+		return "ERROR"
+	}
+}
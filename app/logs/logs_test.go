@@ -0,0 +1,68 @@
+package logs_test // the logs package is skipped looking for the caller module, tests log from outside it
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	log "github.com/go-pkgz/lgr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/feed-master/app/logs"
+)
+
+func TestSetup_text(t *testing.T) {
+	t.Cleanup(func() { log.Setup() })
+	var buf bytes.Buffer
+
+	require.NoError(t, logs.Setup(logs.Config{}, false, &buf))
+	log.Printf("[DEBUG] hidden")
+	log.Printf("[INFO] shown")
+	assert.NotContains(t, buf.String(), "hidden")
+	assert.Contains(t, buf.String(), "[INFO]  shown\n")
+
+	buf.Reset()
+	require.NoError(t, logs.Setup(logs.Config{Level: "warn", Modules: map[string]string{"logs_test": "debug", "youtube": "error"}}, false, &buf))
+	log.Printf("[DEBUG] module debug")
+	log.Printf("[TRACE] module trace")
+	assert.Contains(t, buf.String(), "[DEBUG] module debug\n")
+	assert.NotContains(t, buf.String(), "trace")
+
+	buf.Reset()
+	require.NoError(t, logs.Setup(logs.Config{Level: "warn"}, false, &buf))
+	log.Printf("[INFO] below warn")
+	log.Printf("[WARN] warned")
+	assert.NotContains(t, buf.String(), "below warn")
+	assert.Contains(t, buf.String(), "[WARN]  warned\n")
+
+	buf.Reset()
+	require.NoError(t, logs.Setup(logs.Config{Level: "warn"}, true, &buf))
+	log.Printf("[DEBUG] dbg flag")
+	assert.Contains(t, buf.String(), "dbg flag", "--dbg turns debug on everywhere")
+}
+
+func TestSetup_json(t *testing.T) {
+	t.Cleanup(func() { log.Setup() })
+	var buf bytes.Buffer
+	require.NoError(t, logs.Setup(logs.Config{Format: "json", Modules: map[string]string{"logs_test": "debug"}}, false, &buf))
+	log.Printf("[DEBUG] fetched %d items", 3)
+
+	var rec map[string]any
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(buf.String())), &rec))
+	assert.Equal(t, "DEBUG", rec["level"])
+	assert.Equal(t, "fetched 3 items", rec["msg"])
+	assert.Equal(t, "logs_test", rec["module"])
+	assert.NotEmpty(t, rec["time"])
+}
+
+func TestConfig_Validate(t *testing.T) {
+	require.NoError(t, logs.Config{}.Validate())
+	require.NoError(t, logs.Config{Format: "json", Level: "DEBUG", Modules: map[string]string{"proc": "trace"}}.Validate())
+	require.EqualError(t, logs.Config{Format: "xml"}.Validate(), `bad log format "xml", text or json`)
+	require.Error(t, logs.Config{Level: "loud"}.Validate())
+	require.EqualError(t, logs.Config{Modules: map[string]string{"proc": "loud"}}.Validate(),
+		`module proc: bad log level "loud", trace, debug, info, warn or error`)
+	require.Error(t, logs.Setup(logs.Config{Format: "xml"}, false, nil))
+}
@@ -3,13 +3,18 @@ package main
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"os/exec"
+	"os/signal"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
+	"syscall"
 	"text/template"
 	"time"
 
@@ -24,8 +29,12 @@ import (
 	"github.com/umputun/feed-master/app/config"
 	"github.com/umputun/feed-master/app/duration"
 	rssfeed "github.com/umputun/feed-master/app/feed"
+	"github.com/umputun/feed-master/app/importer"
+	"github.com/umputun/feed-master/app/logs"
 	"github.com/umputun/feed-master/app/proc"
 	"github.com/umputun/feed-master/app/publisher"
+	"github.com/umputun/feed-master/app/tools"
+	"github.com/umputun/feed-master/app/tracing"
 	"github.com/umputun/feed-master/app/youtube"
 	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
 	"github.com/umputun/feed-master/app/youtube/store"
@@ -57,18 +66,65 @@ type options struct {
 	Publish         string `long:"publish" description:"publish an audio file to R2 and exit"`
 	PublishCategory string `long:"publish-category" description:"category for --publish"`
 
+	ExportSite     string `long:"export-site" description:"render a feed into a static HTML archive in this directory and exit"`
+	ExportFeed     string `long:"export-feed" description:"feed for --export-site, default telegram bot feed"`
+	ExportMediaURL string `long:"export-media-url" description:"link --export-site audio from this URL instead of copying files"`
+
 	Dbg bool `long:"dbg" env:"DEBUG" description:"debug mode"`
 }
 
+// serveCommand is the explicit server mode, for service units: same as no
+// command, but refuses the one-shot flags so a unit can't publish or export
+// on every restart. Socket activation and sd_notify work in either mode.
+type serveCommand struct{}
+
+// checkConfigCommand validates the config and dry-runs the download template
+type checkConfigCommand struct {
+	VideoID string `long:"video" default:"dQw4w9WgXcQ" description:"video id to render the download template with"`
+}
+
+// importCommand copies the episodes of an upstream feed-master into the store
+type importCommand struct {
+	From     string   `long:"from" required:"true" description:"feed-master bolt db, or its RSS feed (.xml file or URL)"`
+	Files    string   `long:"files" description:"directory of the feed-master audio files, default where its entries point"`
+	Channels []string `long:"channel" description:"channel to import as from[:to], repeatable; default all channels of the db, the target channel for a feed"`
+}
+
 var revision = "local"
 
 func main() {
 	fmt.Printf("feed-master %s\n", revision)
 	var opts options
-	if _, err := flags.Parse(&opts); err != nil {
+	parser := flags.NewParser(&opts, flags.Default)
+	parser.SubcommandsOptional = true
+	if _, err := parser.AddCommand("serve", "run the server",
+		"run the server, with systemd socket activation and readiness notification when started by systemd",
+		&serveCommand{}); err != nil {
+		log.Fatalf("[ERROR] can't add serve command, %v", err)
+	}
+	checkCmd := &checkConfigCommand{}
+	if _, err := parser.AddCommand("check-config", "validate the config",
+		"load the config and show the download command the template renders, without running it", checkCmd); err != nil {
+		log.Fatalf("[ERROR] can't add check-config command, %v", err)
+	}
+	importCmd := &importCommand{}
+	if _, err := parser.AddCommand("import", "import a feed-master database or feed",
+		"copy the entries and audio files of an upstream feed-master into the store, keeping the item guids; stop the server first",
+		importCmd); err != nil {
+		log.Fatalf("[ERROR] can't add import command, %v", err)
+	}
+	if _, err := parser.Parse(); err != nil {
 		os.Exit(1)
 	}
-	setupLog(opts.Dbg)
+	setupLog(logs.Config{}, opts.Dbg)
+	if parser.Active != nil && parser.Active.Name == "serve" && (opts.Publish != "" || opts.ExportSite != "") {
+		log.Fatalf("[ERROR] --publish and --export-site can't be used with serve")
+	}
+
+	// SIGINT/SIGTERM cancel every service; bot jobs derive their contexts from
+	// this one, so in-flight yt-dlp/ffmpeg children get killed too
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
 
 	var conf = &config.Conf{}
 	if opts.Feed != "" { // single feed (no config) mode
@@ -81,8 +137,29 @@ func main() {
 		if err != nil {
 			log.Fatalf("[ERROR] can't load config %s, %v", opts.Conf, err)
 		}
+		setupLog(conf.Log, opts.Dbg)
 	}
 
+	tools.Configure(conf.Tools)
+	if err = conf.Sandbox.Validate(); err != nil {
+		log.Fatalf("[ERROR] %v", err)
+	}
+	if err = proc.CheckAudioFormat(conf.TelegramBot.AudioFormat); err != nil {
+		log.Fatalf("[ERROR] bad telegram_bot.audio_format, %v", err)
+	}
+	tools.ConfigureSandbox(conf.Sandbox)
+	tools.ConfigureLimits(conf.Concurrency)
+	tools.ConfigureJobLogs(conf.JobLogs)
+	tracing.Configure(conf.Tracing)
+	if parser.Active != nil && parser.Active.Name == "check-config" {
+		if err = checkDownloadTemplate(os.Stdout, conf.YouTube.DlTemplate, checkCmd.VideoID); err != nil {
+			log.Fatalf("[ERROR] %v", err)
+		}
+		return
+	}
+	if _, tmplErr := checkTemplate(conf.YouTube.DlTemplate, "dQw4w9WgXcQ"); tmplErr != nil {
+		log.Fatalf("[ERROR] bad dl_template, %v", tmplErr)
+	}
 	pubSvc := makePublisher(conf)
 
 	// one-shot publish: runs before bolt is opened, so it works next to the
@@ -94,7 +171,7 @@ func main() {
 		if opts.PublishCategory == "" {
 			log.Fatalf("[ERROR] --publish requires --publish-category")
 		}
-		ep, pubErr := pubSvc.PublishFile(context.Background(), opts.Publish, opts.PublishCategory)
+		ep, pubErr := pubSvc.PublishFile(ctx, opts.Publish, opts.PublishCategory)
 		if pubErr != nil {
 			log.Fatalf("[ERROR] publish failed: %v", pubErr)
 		}
@@ -108,6 +185,34 @@ func main() {
 	}
 	procStore := &proc.BoltDB{DB: db}
 
+	// one-shot static archive: bolt is locked by a running instance, stop it first
+	if opts.ExportSite != "" {
+		exporter := &proc.SiteExporter{Store: &store.BoltDB{DB: db}, MDLocation: conf.Notes.MDLocation,
+			MediaURL: opts.ExportMediaURL}
+		feedName := opts.ExportFeed
+		if feedName == "" || feedName == conf.TelegramBot.FeedName {
+			feedName, exporter.Title = conf.TelegramBot.FeedName, conf.TelegramBot.FeedTitle
+		}
+		n, expErr := exporter.Export(ctx, feedName, opts.ExportSite)
+		if expErr != nil {
+			log.Fatalf("[ERROR] export failed: %v", expErr)
+		}
+		fmt.Printf("exported %d episodes of %s to %s\n", n, feedName, filepath.Join(opts.ExportSite, "index.html"))
+		return
+	}
+
+	// one-shot import from feed-master: bolt is locked by a running instance, stop it first
+	if parser.Active != nil && parser.Active.Name == "import" {
+		stats, impErr := runImport(ctx, importCmd, &store.BoltDB{DB: db, BotFeed: botFeedName(conf)}, conf.YouTube.FilesLocation)
+		if impErr != nil {
+			log.Fatalf("[ERROR] import failed: %v", impErr)
+		}
+		fmt.Printf("imported %d episodes, %d already in the store, %d without audio\n", stats.Imported, stats.Skipped, stats.Missing)
+		return
+	}
+
+	toolsStatus := checkTools(ctx, conf.Tools)
+
 	telegramNotif, err := proc.NewTelegramClient(opts.TelegramToken, opts.TelegramServer, opts.TelegramTimeout,
 		&duration.Service{}, &proc.TelegramSenderImpl{})
 	if err != nil {
@@ -116,7 +221,7 @@ func main() {
 
 	p := &proc.Processor{Conf: conf, Store: procStore, TelegramNotif: telegramNotif, TwitterNotif: makeTwitter(opts)}
 	go func() {
-		if err := p.Do(context.Background()); err != nil {
+		if err := p.Do(ctx); err != nil {
 			log.Printf("[ERROR] processor failed: %v", err)
 		}
 	}()
@@ -124,10 +229,23 @@ func main() {
 	var ytSvc youtube.Service
 	var ytStore *store.BoltDB
 
+	var dlArchive *ytfeed.Archive // nil without download_archive
+	if conf.YouTube.DownloadArchive != "" {
+		log.Printf("[INFO] yt-dlp download archive %s", conf.YouTube.DownloadArchive)
+		dlArchive = &ytfeed.Archive{Path: conf.YouTube.DownloadArchive}
+	}
+
+	var webSub *rssfeed.WebSub // nil without hubs
+	if len(conf.WebSub.Hubs) > 0 && conf.System.BaseURL != "" {
+		log.Printf("[INFO] websub hubs %s", strings.Join(conf.WebSub.Hubs, ", "))
+		webSub = &rssfeed.WebSub{Hubs: conf.WebSub.Hubs}
+	}
+
 	// Initialize YouTube service if we have channels OR telegram_bot is enabled
 	needYouTube := len(conf.YouTube.Channels) > 0 || conf.TelegramBot.Enabled
 	if needYouTube {
 		log.Printf("[INFO] initializing youtube service")
+		sweepLeftovers(conf.YouTube.FilesLocation)
 		outWr := log.ToWriter(log.Default(), "DEBUG")
 		errWr := log.ToWriter(log.Default(), "INFO")
 		dwnl := ytfeed.NewDownloader(conf.YouTube.DlTemplate, outWr, errWr, conf.YouTube.FilesLocation, conf.YouTube.CookiesFile)
@@ -144,7 +262,10 @@ func main() {
 		}
 		log.Printf("[DEBUG] buckets for youtube store: %s", strings.Join(channels, ", "))
 
-		ytStore = &store.BoltDB{DB: db, Channels: channels}
+		ytStore = &store.BoltDB{DB: db, Channels: channels, BotFeed: botFeedName(conf)}
+		if _, err = ytStore.Migrate(); err != nil {
+			log.Fatalf("[ERROR] can't migrate youtube store, %v", err)
+		}
 		ytSvc = youtube.Service{
 			Feeds:          conf.YouTube.Channels,
 			Downloader:     dwnl,
@@ -159,6 +280,10 @@ func main() {
 			},
 			DurationService: &duration.Service{},
 			SkipShorts:      conf.YouTube.SkipShorts,
+			WebSub:          webSub,
+			Archive:         dlArchive,
+			Waveform:        conf.YouTube.Waveform,
+			FeedsURL:        strings.TrimSuffix(conf.System.BaseURL, "/") + "/yt/rss",
 		}
 		if conf.YouTube.YtDlpUpdate.Interval > 0 {
 			log.Printf("[INFO] yt-dlp updater enabled, interval %s", conf.YouTube.YtDlpUpdate.Interval)
@@ -175,7 +300,7 @@ func main() {
 					log.Printf("[INFO] youtube updates are disabled")
 					return
 				}
-				if err := ytSvc.Do(context.TODO()); err != nil {
+				if err := ytSvc.Do(ctx); err != nil {
 					log.Printf("[ERROR] youtube processor failed: %v", err)
 				}
 			}()
@@ -184,6 +309,7 @@ func main() {
 
 	// owner notifications for the audio watcher (set when the bot comes up)
 	var ownerNotify func(string)
+	var linkAdder api.LinkAdder // share endpoint, set when the bot runs
 
 	// Initialize Telegram Bot for manual video additions
 	if conf.TelegramBot.Enabled && opts.TelegramToken != "" && conf.TelegramBot.AllowedUserID != 0 {
@@ -207,24 +333,57 @@ func main() {
 		}
 
 		tgBot, err := proc.NewTelegramBot(proc.TelegramBotParams{
-			Token:         opts.TelegramToken,
-			APIURL:        opts.TelegramServer,
-			AllowedUserID: conf.TelegramBot.AllowedUserID,
-			FeedName:      conf.TelegramBot.FeedName,
-			FeedTitle:     conf.TelegramBot.FeedTitle,
-			MaxItems:      conf.TelegramBot.MaxItems,
-			Downloader:    botDownloader,
-			Store:         ytStore,
-			DurationSvc:   &duration.Service{},
-			FilesLocation: conf.YouTube.FilesLocation,
-			BaseURL:       conf.System.BaseURL,
-			TTSEnabled:    conf.TelegramBot.TTSEnabled,
-			TTSVoice:      conf.TelegramBot.TTSVoice,
-			CookiesFile:   conf.YouTube.CookiesFile,
-			NotesSvc:      notesSvc,
-			ReadSvc:       readSvc,
-			Media:         mediaOffloader,
-			Pub:           pubSvc,
+			Token:           opts.TelegramToken,
+			APIURL:          opts.TelegramServer,
+			AllowedUserID:   conf.TelegramBot.AllowedUserID,
+			AdminChatID:     conf.TelegramBot.AdminChatID,
+			FeedName:        conf.TelegramBot.FeedName,
+			FeedTitle:       conf.TelegramBot.FeedTitle,
+			MaxItems:        conf.TelegramBot.MaxItems,
+			MaxAge:          conf.TelegramBot.MaxAge,
+			Downloader:      botDownloader,
+			Store:           ytStore,
+			DurationSvc:     &duration.Service{},
+			FilesLocation:   conf.YouTube.FilesLocation,
+			BaseURL:         conf.System.BaseURL,
+			TTSEnabled:      conf.TelegramBot.TTSEnabled,
+			TTSVoice:        conf.TelegramBot.TTSVoice,
+			Voices:          conf.TelegramBot.Voices,
+			MaxDubSize:      int64(conf.TelegramBot.MaxDubSizeMB) * 1024 * 1024,
+			AudioFormat:     conf.TelegramBot.AudioFormat,
+			CookiesFile:     conf.YouTube.CookiesFile,
+			NotesSvc:        notesSvc,
+			ReadSvc:         readSvc,
+			Media:           mediaOffloader,
+			Pub:             pubSvc,
+			Presets:         conf.TelegramBot.Presets,
+			ChannelRules:    conf.TelegramBot.ChannelRules,
+			RSSPoll:         conf.TelegramBot.RSSPollInterval,
+			WebSub:          webSub,
+			Archive:         dlArchive,
+			Waveform:        conf.YouTube.Waveform,
+			Tools:           toolsStatus,
+			DailyDigest:     conf.TelegramBot.DailyDigest,
+			ReadLater:       makeReadLater(),
+			ReadLaterConf:   conf.TelegramBot.ReadLater,
+			MailPreset:      conf.TelegramBot.Mail.Preset,
+			NitterURL:       conf.TelegramBot.NitterURL,
+			ArticleCookies:  conf.TelegramBot.ArticleCookiesFile,
+			ArticleSites:    conf.TelegramBot.ArticleSites,
+			ArticleFetch:    conf.TelegramBot.ArticleFetch,
+			ArticleVariants: conf.TelegramBot.ArticleVariants,
+			Translation:     conf.TelegramBot.Translation,
+			ArticleLimit:    conf.TelegramBot.ArticleLimit,
+			TranslateTitles: conf.TelegramBot.TranslateTitles,
+			SpeedVariant:    conf.TelegramBot.SpeedVariant,
+			CoverColor:      conf.TelegramBot.CoverColor,
+			CoverFont:       conf.TelegramBot.CoverFont,
+			Intro:           conf.TelegramBot.Intro,
+			VoSources:       conf.TelegramBot.VoiceoverSources,
+			HybridDub:       conf.TelegramBot.HybridDub,
+			VoMethods:       conf.TelegramBot.Voiceover,
+			Dashboard:       conf.TelegramBot.Dashboard,
+			AlignCommand:    conf.TelegramBot.AlignCommand,
 		})
 		if err != nil {
 			log.Printf("[ERROR] failed to create telegram bot: %v", err)
@@ -234,21 +393,30 @@ func main() {
 				notesSvc.External = tgBot.RunQueuedVoiceover // podcast translations ride the same queue
 			}
 			ownerNotify = tgBot.NotifyOwner
+			linkAdder = tgBot
+			if mc := conf.TelegramBot.Mail; mc.Listen != "" && conf.TelegramBot.TTSEnabled {
+				gw := &proc.MailGateway{Addr: mc.Listen, Recipients: mc.Recipients, Senders: mc.Senders, Handle: tgBot.HandleMail}
+				go func() {
+					if err := gw.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+						log.Printf("[ERROR] mail gateway failed: %v", err)
+					}
+				}()
+			}
 			go func() {
-				if err := tgBot.Run(context.Background()); err != nil {
+				if err := tgBot.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
 					log.Printf("[ERROR] telegram bot failed: %v", err)
 				}
 			}()
 		}
 		if notesSvc != nil {
-			go notesSvc.Run(context.Background())
+			go notesSvc.Run(ctx)
 		}
 	}
 
 	// audio watcher: new files in originals/{category}/ get normalized,
 	// uploaded to R2 and added to the category feed automatically
 	if pubSvc != nil {
-		go pubSvc.Watch(context.Background(), time.Minute, ownerNotify)
+		go pubSvc.Watch(ctx, time.Minute, ownerNotify)
 	}
 
 	if opts.AdminPasswd == "" {
@@ -263,6 +431,10 @@ func main() {
 		YoutubeStore: ytStore,
 		YoutubeSvc:   &ytSvc,
 		AdminPasswd:  opts.AdminPasswd,
+		Links:        linkAdder,
+	}
+	if ytStore != nil {
+		server.Downloads = ytStore
 	}
 	if pubSvc != nil {
 		server.PodSecret = pubSvc.Secret
@@ -270,7 +442,99 @@ func main() {
 		fm := publisher.FeedMedia{Store: pubSvc.R2, Secret: pubSvc.Secret}
 		server.MediaRedirectBase = fm.PublicBase()
 	}
-	server.Run(context.Background(), opts.Port)
+	server.Run(ctx, opts.Port)
+	log.Printf("[INFO] shutdown complete")
+}
+
+// botFeedName is the bucket of the telegram bot feed, "" with the bot off
+func botFeedName(conf *config.Conf) string {
+	if !conf.TelegramBot.Enabled {
+		return ""
+	}
+	return conf.TelegramBot.FeedName
+}
+
+// runImport imports a feed-master database, or its RSS feed into one channel
+func runImport(ctx context.Context, cmd *importCommand, ytStore *store.BoltDB, filesLocation string) (importer.Stats, error) {
+	var channels []importer.Channel
+	for _, c := range cmd.Channels {
+		ch, err := importer.ParseChannel(c)
+		if err != nil {
+			return importer.Stats{}, err
+		}
+		channels = append(channels, ch)
+	}
+	if err := os.MkdirAll(filesLocation, 0o750); err != nil {
+		return importer.Stats{}, fmt.Errorf("can't make %s: %w", filesLocation, err)
+	}
+	im := &importer.Importer{Store: ytStore, FilesLocation: filesLocation, FilesFrom: cmd.Files,
+		Client: &http.Client{Timeout: 30 * time.Minute}}
+
+	if strings.HasPrefix(cmd.From, "http://") || strings.HasPrefix(cmd.From, "https://") ||
+		strings.HasSuffix(cmd.From, ".xml") || strings.HasSuffix(cmd.From, ".rss") {
+		if len(channels) != 1 {
+			return importer.Stats{}, errors.New("a feed goes to one channel, set it with --channel")
+		}
+		return im.FromRSS(ctx, cmd.From, channels[0].To)
+	}
+
+	src, err := bolt.Open(cmd.From, 0o600, &bolt.Options{Timeout: time.Second, ReadOnly: true}) // nolint
+	if err != nil {
+		return importer.Stats{}, fmt.Errorf("can't open %s: %w", cmd.From, err)
+	}
+	defer src.Close() // nolint
+	return im.FromBolt(ctx, src, channels)
+}
+
+// checkDownloadTemplate prints the command the download template renders for
+// the video and the binary it resolves to
+func checkDownloadTemplate(w io.Writer, tmpl, videoID string) error {
+	argv, err := checkTemplate(tmpl, videoID)
+	if err != nil {
+		return fmt.Errorf("bad dl_template, %w", err)
+	}
+	bin := argv[0]
+	if bin == "yt-dlp" {
+		bin, argv = tools.Resolve("yt-dlp", argv[1:]...)
+		argv = append([]string{bin}, argv...)
+	}
+	fmt.Fprintf(w, "download template ok, video %s runs:\n", videoID)
+	for i, arg := range argv {
+		fmt.Fprintf(w, "  %2d: %q\n", i, arg)
+	}
+	path, err := exec.LookPath(bin)
+	if err != nil {
+		return fmt.Errorf("download command %s not found, %w", bin, err)
+	}
+	fmt.Fprintf(w, "binary: %s\n", path)
+	return nil
+}
+
+// checkTemplate renders the download template with sample values
+func checkTemplate(tmpl, videoID string) ([]string, error) {
+	return ytfeed.RenderTemplate(tmpl, ytfeed.TemplateParams{ID: videoID, FileName: "0123456789abcdef"})
+}
+
+// checkTools reports the versions of the external binaries. A tool missing
+// from PATH only disables its features, but one set up in the config has to run.
+func checkTools(ctx context.Context, configured map[string]tools.Tool) []tools.Status {
+	for name := range configured {
+		if !slices.Contains(tools.Known, name) {
+			log.Printf("[WARN] tool %s in config isn't known, known are %s", name, strings.Join(tools.Known, ", "))
+		}
+	}
+	res := tools.Check(ctx)
+	for _, st := range res {
+		switch {
+		case st.Err == nil:
+			log.Printf("[INFO] %s %s (%s)", st.Name, st.Version, st.Command)
+		case st.Configured:
+			log.Fatalf("[ERROR] %s configured as %q doesn't run, %v", st.Name, st.Command, st.Err)
+		default:
+			log.Printf("[WARN] %s not available, %v", st.Name, st.Err)
+		}
+	}
+	return res
 }
 
 // makePublisher builds the R2-backed publishing service when R2_* env and
@@ -306,6 +570,23 @@ func makePublisher(conf *config.Conf) *publisher.Service {
 	}
 }
 
+// makeReadLater builds the read-later queue client when WALLABAG_URL and
+// the credentials are set; nil otherwise (feature off)
+func makeReadLater() proc.ReadLaterQueue {
+	wbURL := os.Getenv("WALLABAG_URL")
+	if wbURL == "" {
+		return nil
+	}
+	wb := proc.NewWallabagClient(wbURL, os.Getenv("WALLABAG_CLIENT_ID"), os.Getenv("WALLABAG_CLIENT_SECRET"),
+		os.Getenv("WALLABAG_USERNAME"), os.Getenv("WALLABAG_PASSWORD"))
+	if wb.ClientID == "" || wb.Username == "" {
+		log.Printf("[WARN] WALLABAG_URL set without WALLABAG_CLIENT_ID/WALLABAG_USERNAME, read-later disabled")
+		return nil
+	}
+	log.Printf("[INFO] read-later enabled: wallabag %s", wbURL)
+	return wb
+}
+
 // makeNotesService builds the transcription/notes pipeline when enabled and
 // GROQ_API_KEY is set. Notion publishing additionally needs NOTION_TOKEN and
 // notion_parent_page; without them /notes degrades to /md.
@@ -343,6 +624,7 @@ func makeNotesService(conf *config.Conf, ytStore *store.BoltDB, outWr, errWr io.
 		log.Printf("[INFO] notes.notion_parent_page not set, /notes will be transcript-only")
 	}
 
+	sweepLeftovers(filepath.Join(conf.Notes.MDLocation, "tmp"))
 	notesDownloader := ytfeed.NewDownloader(conf.YouTube.DlTemplate, outWr, errWr,
 		filepath.Join(conf.Notes.MDLocation, "tmp"), conf.YouTube.CookiesFile)
 
@@ -357,6 +639,9 @@ func makeNotesService(conf *config.Conf, ytStore *store.BoltDB, outWr, errWr io.
 	if conf.Notes.WhisperBaseURL != "" {
 		transcriber.BaseURL = conf.Notes.WhisperBaseURL
 	}
+	if conf.Notes.DiarizeCommand != "" {
+		transcriber.Diarizer = &proc.Diarizer{Command: conf.Notes.DiarizeCommand}
+	}
 
 	return proc.NewNotesService(proc.NotesParams{
 		MDLocation:  conf.Notes.MDLocation,
@@ -434,10 +719,21 @@ func makeTwitter(opts options) *proc.TwitterClient {
 	return proc.NewTwitterClient(twiAuth, twitterFmtFn, twitPoster)
 }
 
-func setupLog(dbg bool) {
-	if dbg {
-		log.Setup(log.Debug, log.CallerFile, log.Msec, log.LevelBraces)
+func setupLog(c logs.Config, dbg bool) {
+	if err := logs.Setup(c, dbg, nil); err != nil {
+		log.Fatalf("[ERROR] bad log config, %v", err)
+	}
+}
+
+// sweepLeftovers removes what the jobs killed by the last shutdown or crash
+// left in the directory
+func sweepLeftovers(dir string) {
+	removed, err := ytfeed.SweepLeftovers(dir)
+	if err != nil {
+		log.Printf("[WARN] failed to sweep %s: %v", dir, err)
 		return
 	}
-	log.Setup(log.Msec, log.LevelBraces)
+	if removed > 0 {
+		log.Printf("[INFO] removed %d leftovers of interrupted jobs from %s", removed, dir)
+	}
 }
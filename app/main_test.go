@@ -1,9 +1,11 @@
 package main
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMakeTwitter(t *testing.T) {
@@ -21,3 +23,17 @@ func TestMakeTwitter(t *testing.T) {
 	assert.Equal(t, client.AccessToken, "c")
 	assert.Equal(t, client.AccessSecret, "d")
 }
+
+func TestCheckDownloadTemplate(t *testing.T) {
+	var out bytes.Buffer
+	err := checkDownloadTemplate(&out, `echo -o {{.FileName}} "https://www.youtube.com/watch?v={{.ID}}"`, "abc")
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), `2: "0123456789abcdef"`)
+	assert.Contains(t, out.String(), `3: "https://www.youtube.com/watch?v=abc"`)
+	assert.Contains(t, out.String(), "binary: /")
+
+	err = checkDownloadTemplate(&out, "echo {{.ID}} && reboot", "abc")
+	assert.ErrorContains(t, err, "shell syntax")
+	err = checkDownloadTemplate(&out, "no-such-binary-here {{.ID}}", "abc")
+	assert.ErrorContains(t, err, "not found")
+}
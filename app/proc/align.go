@@ -0,0 +1,206 @@
+package proc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	log "github.com/go-pkgz/lgr"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// Aligner finds when each word of voiced text is spoken by forced alignment
+// with an external command (a whisperX or aeneas wrapper). The command gets
+// the audio and a UTF-8 text file as its last two arguments and prints the
+// words whisperX style:
+// {"segments":[{"words":[{"word":"Привет","start":0.12,"end":0.5}]}]}
+type Aligner struct {
+	Command string               // command line, e.g. "python3 /opt/align.py"
+	Runner  ytfeed.CommandRunner // nil = ytfeed.ExecRunner
+}
+
+// AlignedWord is a word with its time in the audio, in seconds
+type AlignedWord struct {
+	Word  string  `json:"word"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// Align returns the timed words of text spoken in the audio file
+func (a *Aligner) Align(ctx context.Context, audioPath, text string) ([]AlignedWord, error) {
+	args := strings.Fields(a.Command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("align command is empty")
+	}
+	runner := a.Runner
+	if runner == nil {
+		runner = ytfeed.ExecRunner{}
+	}
+
+	textFile, err := os.CreateTemp("", "align-*.txt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create text file: %w", err)
+	}
+	defer os.Remove(textFile.Name()) //nolint:errcheck // temp file
+	if _, err := textFile.WriteString(text); err != nil {
+		_ = textFile.Close()
+		return nil, fmt.Errorf("failed to write text file: %w", err)
+	}
+	if err := textFile.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write text file: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Minute)
+	defer cancel()
+	stdout, stderr, err := runner.Run(ctx, args[0], append(args[1:], audioPath, textFile.Name())...)
+	if err != nil {
+		return nil, fmt.Errorf("alignment failed: %w, stderr: %s", err, lastLines(string(stderr), 5))
+	}
+
+	var resp struct {
+		Segments []struct {
+			Words []AlignedWord `json:"words"`
+		} `json:"segments"`
+	}
+	if err := json.Unmarshal(stdout, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse alignment: %w", err)
+	}
+	var words []AlignedWord
+	for _, seg := range resp.Segments {
+		for _, w := range seg.Words {
+			if w.Word = strings.TrimSpace(w.Word); w.Word == "" {
+				continue
+			}
+			// whisperX leaves numbers and symbols it can't align without a time
+			if w.Start <= 0 && len(words) > 0 {
+				w.Start = words[len(words)-1].End
+			}
+			w.End = max(w.End, w.Start)
+			words = append(words, w)
+		}
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("alignment returned no words")
+	}
+	return words, nil
+}
+
+// transcriptFile is the read-along transcript saved next to the audio
+func transcriptFile(audioFile string) string {
+	return strings.TrimSuffix(audioFile, filepath.Ext(audioFile)) + ".vtt"
+}
+
+// cueMaxWords is the length of a read-along line
+const cueMaxWords = 8
+
+// wordsVTT renders timed words as WebVTT for read-along players. Each cue is
+// a short line, words carry karaoke timestamps (<00:00:01.200>) so players
+// highlight them as they are spoken. A line ends after a sentence, a pause
+// or cueMaxWords words.
+func wordsVTT(words []AlignedWord) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+	for start := 0; start < len(words); {
+		end := start + 1
+		for end < len(words) && end-start < cueMaxWords {
+			prev := words[end-1]
+			last, _ := utf8.DecodeLastRuneInString(prev.Word)
+			if strings.ContainsRune(".!?…", last) || words[end].Start-prev.End > 1.5 {
+				break
+			}
+			end++
+		}
+		cue := words[start:end]
+		fmt.Fprintf(&b, "\n%s --> %s\n", vttTime(cue[0].Start), vttTime(cue[len(cue)-1].End))
+		for i, w := range cue {
+			if i > 0 {
+				b.WriteString(" <" + vttTime(w.Start) + ">")
+			}
+			b.WriteString(escapeXML(w.Word))
+		}
+		b.WriteString("\n")
+		start = end
+	}
+	return b.String()
+}
+
+// vttTime formats seconds as a WebVTT timestamp, 00:01:02.345
+func vttTime(sec float64) string {
+	ms := int64(sec*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// speechCopy is the voiced part of an episode kept aside for the aligner:
+// the jingles around it aren't in the text and would shift every cue. offset
+// is where the speech starts in the episode.
+type speechCopy struct {
+	file   *os.File
+	offset time.Duration
+}
+
+// newSpeechCopy starts a copy of the speech starting at offset in the
+// episode, nil without an aligner or if the temp file fails
+func (t *TelegramBot) newSpeechCopy(offset time.Duration) *speechCopy {
+	if t.Aligner == nil {
+		return nil
+	}
+	f, err := os.CreateTemp("", "speech-*.mp3")
+	if err != nil {
+		log.Printf("[WARN] no read-along transcript: %v", err)
+		return nil
+	}
+	return &speechCopy{file: f, offset: offset}
+}
+
+// tee writes the speech to w and to the copy
+func (s *speechCopy) tee(w io.Writer) io.Writer {
+	if s == nil {
+		return w
+	}
+	return io.MultiWriter(w, s.file)
+}
+
+// remove drops the copy
+func (s *speechCopy) remove() {
+	if s == nil {
+		return
+	}
+	_ = s.file.Close()
+	_ = os.Remove(s.file.Name())
+}
+
+// alignTranscript writes the read-along transcript of voiced text next to
+// the audio, aligned on the speech copy and moved to the episode's timing.
+// Best-effort: returns the file, "" without an aligner or on failure.
+func (t *TelegramBot) alignTranscript(ctx context.Context, audioFile string, speech *speechCopy, text string) string {
+	if speech == nil || strings.TrimSpace(text) == "" {
+		return ""
+	}
+	if err := speech.file.Close(); err != nil {
+		log.Printf("[WARN] no read-along transcript for %s: %v", filepath.Base(audioFile), err)
+		return ""
+	}
+	words, err := t.Aligner.Align(ctx, speech.file.Name(), text)
+	if err != nil {
+		log.Printf("[WARN] no read-along transcript for %s: %v", filepath.Base(audioFile), err)
+		return ""
+	}
+	for i := range words {
+		words[i].Start += speech.offset.Seconds()
+		words[i].End += speech.offset.Seconds()
+	}
+	file := transcriptFile(audioFile)
+	if err := writeAtomic(file, []byte(wordsVTT(words)), 0o644); err != nil {
+		log.Printf("[WARN] failed to save read-along transcript %s: %v", file, err)
+		return ""
+	}
+	log.Printf("[INFO] aligned %d words of %s", len(words), filepath.Base(audioFile))
+	return file
+}
@@ -0,0 +1,101 @@
+package proc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	"github.com/umputun/feed-master/app/config"
+	"github.com/umputun/feed-master/app/youtube/feed/mocks"
+)
+
+func TestWordsVTT(t *testing.T) {
+	words := []AlignedWord{
+		{Word: "Привет,", Start: 0.1, End: 0.5},
+		{Word: "мир.", Start: 0.6, End: 1},
+		{Word: "Это", Start: 1.2, End: 1.4},
+		{Word: "<тест>", Start: 1.5, End: 2},
+		{Word: "после", Start: 4, End: 4.5}, // pause starts a new line
+	}
+	want := "WEBVTT\n" +
+		"\n00:00:00.100 --> 00:00:01.000\nПривет, <00:00:00.600>мир.\n" +
+		"\n00:00:01.200 --> 00:00:02.000\nЭто <00:00:01.500>&lt;тест&gt;\n" +
+		"\n00:00:04.000 --> 00:00:04.500\nпосле\n"
+	assert.Equal(t, want, wordsVTT(words))
+	assert.Equal(t, "01:02:03.450", vttTime(3723.45))
+}
+
+func TestAligner_Align(t *testing.T) {
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			require.Len(t, args, 3)
+			assert.Equal(t, "/tmp/ep.mp3", args[1])
+			text, err := os.ReadFile(args[2])
+			require.NoError(t, err)
+			assert.Equal(t, "В 2026 году.", string(text))
+			return []byte(`{"segments":[{"words":[{"word":"В","start":0.1,"end":0.2},{"word":"2026"},` +
+				`{"word":" году.","start":1.1,"end":1.5}]}]}`), nil, nil
+		},
+	}
+	a := &Aligner{Command: "python3 align.py", Runner: runner}
+	words, err := a.Align(context.Background(), "/tmp/ep.mp3", "В 2026 году.")
+	require.NoError(t, err)
+	assert.Equal(t, []AlignedWord{{Word: "В", Start: 0.1, End: 0.2}, {Word: "2026", Start: 0.2, End: 0.2},
+		{Word: "году.", Start: 1.1, End: 1.5}}, words, "unaligned number placed after the previous word")
+}
+
+func TestTelegramBot_ProcessArticleAlign(t *testing.T) {
+	jingle := filepath.Join(t.TempDir(), "jingle.mp3")
+	require.NoError(t, os.WriteFile(jingle, bytes.Repeat(silentMP3Frame, 40), 0o600))
+	clock := &mp3Clock{w: io.Discard}
+	_, err := clock.Write(bytes.Repeat(silentMP3Frame, 40))
+	require.NoError(t, err)
+	require.Positive(t, clock.elapsed)
+
+	bot := newTestBot(t, newTgStub(t))
+	bot.Store = newTestJobStore(t)
+	bot.MaxItems = 10
+	bot.Intro = config.Intro{Jingle: jingle, Outro: jingle, Preamble: true}
+	var alignedText string
+	var alignedAudio []byte
+	bot.Aligner = &Aligner{Command: "align", Runner: &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			var err error
+			alignedAudio, err = os.ReadFile(args[0])
+			require.NoError(t, err)
+			text, err := os.ReadFile(args[1])
+			require.NoError(t, err)
+			alignedText = string(text)
+			return []byte(`{"segments":[{"words":[{"word":"Статья.","start":0,"end":0.4}]}]}`), nil, nil
+		},
+	}}
+
+	statusMsg := &tb.Message{ID: 5, Chat: &tb.Chat{ID: testBotUserID}}
+	req := articleRequest{URL: "https://example.com/a", Article: &Article{Title: "Статья", TextContent: "Текст статьи."}}
+	require.NoError(t, bot.processArticle(context.Background(), nil, statusMsg, nil, req))
+
+	entries, err := bot.Store.Load(bot.FeedName, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Статья с сайта example.com, время чтения 1 минута.\nТекст статьи.", alignedText, "preamble and voiced text aligned")
+	audio, err := os.ReadFile(entries[0].File)
+	require.NoError(t, err)
+	assert.Len(t, alignedAudio, len(audio)-2*40*len(silentMP3Frame), "aligned without the jingles")
+	assert.Equal(t, transcriptFile(entries[0].File), entries[0].Transcript)
+	data, err := os.ReadFile(entries[0].Transcript)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), vttTime(clock.elapsed.Seconds())+" --> "+vttTime(clock.elapsed.Seconds()+0.4)+"\nСтатья.",
+		"cues moved past the jingle")
+
+	require.NoError(t, bot.deleteEntry(entries[0]))
+	_, err = os.Stat(entries[0].Transcript)
+	assert.True(t, os.IsNotExist(err), "transcript deleted with the episode")
+	assert.Equal(t, bot.FilesLocation, filepath.Dir(entries[0].File))
+}
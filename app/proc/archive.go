@@ -0,0 +1,79 @@
+package proc
+
+import (
+	"bytes"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/go-pkgz/lgr"
+)
+
+// archiveTmpl is the reader-mode copy of an article kept next to its audio.
+// Scripts are off by CSP: the page is served from our own origin and the
+// content, even readability-cleaned, comes from a third-party site.
+var archiveTmpl = template.Must(template.New("archive").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="Content-Security-Policy" content="script-src 'none'; object-src 'none'">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { max-width: 42em; margin: 2em auto; padding: 0 1em; font: 18px/1.6 Georgia, serif; color: #222; }
+img, video, figure { max-width: 100%; height: auto; }
+pre { overflow-x: auto; background: #f5f5f5; padding: .5em; }
+.source { font: 14px sans-serif; color: #666; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="source">{{if .SiteName}}{{.SiteName}} · {{end}}<a href="{{.URL}}">{{.URL}}</a></p>
+{{.Body}}
+</body>
+</html>
+`))
+
+// renderArchive makes a standalone reader-mode page of the article. The
+// extractors that return plain text only get it as escaped paragraphs.
+func renderArchive(article *Article, url string) ([]byte, error) {
+	body := template.HTML(article.Content) //nolint:gosec // readability output, scripts blocked by CSP
+	if strings.TrimSpace(article.Content) == "" {
+		var sb strings.Builder
+		for _, p := range strings.Split(article.TextContent, "\n") {
+			if p = strings.TrimSpace(p); p != "" {
+				sb.WriteString("<p>" + template.HTMLEscapeString(p) + "</p>\n")
+			}
+		}
+		body = template.HTML(sb.String()) //nolint:gosec // escaped above
+	}
+	data := struct {
+		Title, SiteName, URL string
+		Body                 template.HTML
+	}{Title: article.Title, SiteName: article.SiteName, URL: url, Body: body}
+
+	var buf bytes.Buffer
+	if err := archiveTmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// archiveFile is the reader-mode copy path of an episode audio file, same
+// name with .html extension, so it's served from the media location as well
+func archiveFile(audioFile string) string {
+	return strings.TrimSuffix(audioFile, filepath.Ext(audioFile)) + ".html"
+}
+
+// removeArchive deletes the side files of a removed episode, if any: the
+// reader-mode copy, the read-along transcript, the chapters, the covers and
+// the voiced text
+func removeArchive(audioFile string) {
+	for _, f := range []string{archiveFile(audioFile), transcriptFile(audioFile), chaptersFile(audioFile), coverFile(audioFile),
+		imageFile(audioFile), scriptFile(audioFile)} {
+		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+			log.Printf("[WARN] failed to delete %s of %s: %v", filepath.Ext(f), audioFile, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package proc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+)
+
+func TestRenderArchive(t *testing.T) {
+	page, err := renderArchive(&Article{Title: "A & B", SiteName: "Blog", Content: "<div><p>Hello <b>world</b></p></div>"},
+		"https://example.com/post")
+	require.NoError(t, err)
+	s := string(page)
+	assert.Contains(t, s, "<title>A &amp; B</title>")
+	assert.Contains(t, s, "<p>Hello <b>world</b></p>", "readability html kept")
+	assert.Contains(t, s, `<a href="https://example.com/post">`)
+	assert.Contains(t, s, "script-src 'none'")
+
+	page, err = renderArchive(&Article{Title: "T", TextContent: "first <script>x</script>\n\nsecond"}, "https://example.com/p")
+	require.NoError(t, err)
+	s = string(page)
+	assert.Contains(t, s, "<p>first &lt;script&gt;x&lt;/script&gt;</p>\n<p>second</p>", "plain text escaped into paragraphs")
+
+	assert.Equal(t, "/srv/yt/art_1.html", archiveFile("/srv/yt/art_1.mp3"))
+}
+
+func TestTelegramBot_ProcessArticleArchive(t *testing.T) {
+	text := strings.Repeat("Текст статьи, который стоит сохранить. ", 20)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Статья</title></head><body><article><h1>Статья</h1><p>` +
+			text + `</p></article></body></html>`))
+	}))
+	defer ts.Close()
+
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Store = newTestJobStore(t)
+	bot.MaxItems = 10
+	bot.BaseURL = "https://pod.example.com"
+	bot.ArticleExtractor = NewArticleExtractor()
+	statusMsg := &tb.Message{ID: 5, Chat: &tb.Chat{ID: testBotUserID}}
+
+	require.NoError(t, bot.processArticle(context.Background(), nil, statusMsg, nil, articleRequest{URL: ts.URL + "/post"}))
+	entries, err := bot.Store.Load(bot.FeedName, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	archive := archiveFile(entries[0].File)
+	data, err := os.ReadFile(archive) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Текст статьи, который стоит сохранить.")
+	assert.Contains(t, string(entries[0].Media.Description),
+		"Копия статьи: https://pod.example.com/yt/media/"+strings.TrimPrefix(archive, bot.FilesLocation+"/"))
+
+	require.NoError(t, bot.deleteEntry(entries[0]))
+	_, err = os.Stat(archive)
+	assert.True(t, os.IsNotExist(err), "copy removed with the episode")
+}
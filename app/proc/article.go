@@ -1,15 +1,25 @@
 package proc
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 
+	log "github.com/go-pkgz/lgr"
 	"github.com/go-shiori/go-readability"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/umputun/feed-master/app/config"
 )
 
 // Article represents extracted article content
@@ -20,11 +30,29 @@ type Article struct {
 	Image       string
 	SiteName    string
 	URL         string
+	Canonical   string    // <link rel="canonical"> of the page, "" if missing
+	Published   time.Time // publication time from the page metadata, zero if unknown
 }
 
 // ArticleExtractor extracts readable content from URLs
 type ArticleExtractor struct {
-	HTTPClient *http.Client
+	HTTPClient  *http.Client
+	MaxComments int                        // top-level comments read for Reddit and HN threads, 0 = 10
+	NitterURL   string                     // nitter instance tweet threads are unrolled through, "" = nitter.net
+	CookiesFile string                     // Netscape cookies file, its cookies go with page requests to their domains
+	Sites       map[string]config.SiteAuth // headers and cookies of page requests by domain
+	Delay       time.Duration              // gap between page requests to a domain, they go one at a time anyway
+	Robots      bool                       // pages robots.txt disallows aren't fetched
+	AMP         bool                       // pages are read from the AMP version they link to, unless it's shorter
+	Variants    map[string][]string        // domain → cleaner variants of its pages tried first, see variantURL
+
+	politeMu sync.Mutex
+	gates    map[string]*domainGate  // by host
+	robots   map[string]*robotsRules // by scheme://host
+
+	cookiesMu  sync.Mutex
+	cookies    []*http.Cookie // of CookiesFile, read at cookiesMod
+	cookiesMod time.Time
 }
 
 // NewArticleExtractor creates a new article extractor
@@ -38,12 +66,29 @@ func NewArticleExtractor() *ArticleExtractor {
 
 // Extract fetches URL and extracts article content. Sites behind Cloudflare
 // or aggressive bot protection (403 etc.) go through the r.jina.ai reader
-// as a fallback.
+// as a fallback. Reddit posts and HN items are read through their APIs as
+// the post plus top comments, tweets are unrolled into the whole thread,
+// Telegram channel posts are read off their embed widget.
 func (e *ArticleExtractor) Extract(ctx context.Context, rawURL string) (*Article, error) {
-	article, err := e.extractDirect(ctx, rawURL)
+	if site, id := discussionURL(rawURL); site != "" {
+		return e.extractDiscussion(ctx, rawURL, site, id)
+	}
+	if user, id := tweetURL(rawURL); user != "" {
+		return e.extractTweetThread(ctx, rawURL, user, id)
+	}
+	if channel, id := telegramPostURL(rawURL); channel != "" {
+		return e.extractTelegramPost(ctx, rawURL, channel, id)
+	}
+
+	article, authorized, err := e.extractDirect(ctx, rawURL)
 	if err == nil {
 		return article, nil
 	}
+	if authorized || errors.Is(err, errRobotsDisallowed) {
+		// jina reads the page logged out, a paywall teaser is no article;
+		// and it would fetch what the site asked not to
+		return nil, err
+	}
 
 	fallback, ferr := e.extractViaJina(ctx, rawURL)
 	if ferr != nil {
@@ -52,37 +97,100 @@ func (e *ArticleExtractor) Extract(ctx context.Context, rawURL string) (*Article
 	return fallback, nil
 }
 
-// extractDirect fetches the page itself and runs readability over it
-func (e *ArticleExtractor) extractDirect(ctx context.Context, rawURL string) (*Article, error) {
+// extractDirect fetches the page itself, or a cleaner variant of it (see
+// article_variants.go), and runs readability over it. Authorized tells the
+// credentials of the site were sent.
+func (e *ArticleExtractor) extractDirect(ctx context.Context, rawURL string) (article *Article, authorized bool, err error) {
 	parsedURL, err := url.Parse(rawURL)
 	if err != nil {
-		return nil, fmt.Errorf("invalid URL: %w", err)
+		return nil, false, fmt.Errorf("invalid URL: %w", err)
+	}
+	if article, authorized, ok := e.extractSiteVariant(ctx, parsedURL); ok {
+		return article, authorized, nil
+	}
+	article, body, authorized, err := e.extractPage(ctx, rawURL, rawURL)
+	if err != nil {
+		return nil, authorized, err
+	}
+	if amp := e.extractAMP(ctx, article, body, parsedURL); amp != nil {
+		return amp, authorized, nil
+	}
+	return article, authorized, nil
+}
+
+// extractPage reads the article of rawURL from pageURL, the page itself or a variant of it
+func (e *ArticleExtractor) extractPage(ctx context.Context, pageURL, rawURL string) (article *Article, body []byte, authorized bool, err error) {
+	parsedURL, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("invalid URL: %w", err)
+	}
+	body, finalURL, authorized, err := e.fetchPage(ctx, pageURL)
+	if err != nil {
+		return nil, nil, authorized, err
+	}
+	article, err = FromHTML(body, parsedURL)
+	if err != nil {
+		return nil, nil, authorized, err
 	}
+	article.URL = rawURL
+	article.Canonical = canonicalLink(body, finalURL)
+	return article, body, authorized, nil
+}
 
-	// Create request with context
+// fetchPage gets a page the way a browser would, with the credentials of the
+// site, up to 5 MB to prevent OOM on huge pages, transcoded to UTF-8. Requests to a domain go one
+// at a time, Delay apart, robots.txt is checked with Robots on. Returns the
+// URL the page came from after redirects; authorized tells credentials were sent.
+func (e *ArticleExtractor) fetchPage(ctx context.Context, rawURL string) (body []byte, finalURL *url.URL, authorized bool, err error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	crawlDelay, err := e.checkRobots(ctx, req.URL)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	release, err := e.waitDomain(ctx, strings.ToLower(req.URL.Hostname()), crawlDelay)
+	if err != nil {
+		return nil, nil, false, err
 	}
+	defer release()
 
 	// Set user agent to avoid being blocked
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 	req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9,en-US;q=0.8,en;q=0.7")
+	authorized, headers := e.authorize(req)
+	client := e.HTTPClient
+	if len(headers) > 0 {
+		client = withoutHeadersOffHost(client, headers)
+	}
 
-	resp, err := e.HTTPClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch URL: %w", err)
+		return nil, nil, authorized, fmt.Errorf("failed to fetch URL: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP error: %d", resp.StatusCode)
+		return nil, nil, authorized, fmt.Errorf("HTTP error: %d", resp.StatusCode)
+	}
+	body, err = io.ReadAll(io.LimitReader(resp.Body, 5*1024*1024))
+	if err != nil {
+		return nil, nil, authorized, fmt.Errorf("failed to read page: %w", err)
+	}
+	// readability takes UTF-8 only, a windows-1251 page would be voiced as gibberish
+	body, cs := toUTF8(body, resp.Header.Get("Content-Type"))
+	if cs != "utf-8" {
+		log.Printf("[DEBUG] page %s transcoded from %s", rawURL, cs)
 	}
+	return body, resp.Request.URL, authorized, nil
+}
 
-	// Parse with readability, limit response body to 5 MB to prevent OOM on huge pages
-	limitedBody := io.LimitReader(resp.Body, 5*1024*1024)
-	article, err := readability.FromReader(limitedBody, parsedURL)
+// FromHTML runs readability over an HTML document already at hand (a mail
+// body, a saved page), pageURL resolves its relative links
+func FromHTML(page []byte, pageURL *url.URL) (*Article, error) {
+	article, err := readability.FromReader(bytes.NewReader(page), pageURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse article: %w", err)
 	}
@@ -91,14 +199,69 @@ func (e *ArticleExtractor) extractDirect(ctx context.Context, rawURL string) (*A
 		return nil, fmt.Errorf("no content extracted from article")
 	}
 
-	return &Article{
+	res := &Article{
 		Title:       article.Title,
 		Content:     article.Content,
 		TextContent: cleanText(article.TextContent),
 		Image:       article.Image,
 		SiteName:    article.SiteName,
-		URL:         rawURL,
-	}, nil
+		URL:         pageURL.String(),
+	}
+	if article.PublishedTime != nil {
+		res.Published = *article.PublishedTime
+	}
+	return res, nil
+}
+
+// canonicalLink returns the absolute <link rel="canonical"> href from the page
+// head, "" if there is none
+func canonicalLink(page []byte, base *url.URL) string {
+	return headLink(page, base, "canonical")
+}
+
+// headLink returns the absolute href of the <link> with the rel from the
+// page head, "" if there is none
+func headLink(page []byte, base *url.URL, wantRel string) string {
+	z := html.NewTokenizer(bytes.NewReader(page))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "head" {
+				return ""
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) == "body" {
+				return ""
+			}
+			if string(name) != "link" || !hasAttr {
+				continue
+			}
+			var rel, href string
+			for {
+				key, val, more := z.TagAttr()
+				switch string(key) {
+				case "rel":
+					rel = strings.ToLower(string(val))
+				case "href":
+					href = strings.TrimSpace(string(val))
+				}
+				if !more {
+					break
+				}
+			}
+			if rel != wantRel || href == "" {
+				continue
+			}
+			u, err := base.Parse(href)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				return ""
+			}
+			return u.String()
+		}
+	}
 }
 
 // jinaReaderBase is the free r.jina.ai reader endpoint (var for tests)
@@ -153,27 +316,430 @@ func parseJinaReader(raw string) (title, text string) {
 	return title, strings.TrimSpace(body)
 }
 
-// cleanText removes extra whitespace and cleans up text for TTS
-func cleanText(text string) string {
-	// Replace multiple newlines with single newline
-	lines := strings.Split(text, "\n")
-	var cleaned []string
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line != "" {
-			cleaned = append(cleaned, line)
+// TextOptions selects what the spoken text of an article keeps
+type TextOptions struct {
+	SkipCode  bool   // drop code listings
+	Images    bool   // read figure captions and image alt text
+	Citations string // "strip" drops [12] markers, "inline" also reads the note after its paragraph
+	Emoji     string // "say" reads common emoji as words, "keep" leaves them to TTS, dropped otherwise
+	Quotes    bool   // blockquotes start with «Цитата:», verse is read line by line with short pauses
+}
+
+// TextWithoutCode returns the article text with code blocks dropped: listings
+// read aloud are noise. Works off the readability HTML when there is one,
+// otherwise strips markdown fences from the jina reader text.
+func (a *Article) TextWithoutCode() string {
+	return a.Text(TextOptions{SkipCode: true})
+}
+
+// TextWithImages returns the article text with figure captions and image alt
+// text read out as "Изображение: ..." where they stand, for articles whose
+// figures carry meaning. Code listings are dropped with skipCode.
+func (a *Article) TextWithImages(skipCode bool) string {
+	return a.Text(TextOptions{SkipCode: skipCode, Images: true})
+}
+
+// BlockText returns the article text with a line per block element. Unlike
+// readability's TextContent it never glues a heading to the next paragraph.
+func (a *Article) BlockText() string {
+	if a.Content == "" {
+		return a.TextContent
+	}
+	return a.blockText(TextOptions{})
+}
+
+// Text renders the article text for speech with the options. The jina reader
+// text has no HTML, its markdown images and bracketed citations are handled
+// (its emoji are already dropped).
+func (a *Article) Text(opts TextOptions) string {
+	if a.Content != "" {
+		return a.blockText(opts)
+	}
+	text := a.TextContent
+	if opts.SkipCode {
+		text = stripCodeFences(text)
+	}
+	if opts.Images {
+		text = mdImageRe.ReplaceAllStringFunc(text, func(m string) string {
+			if alt := imageDescription(mdImageRe.FindStringSubmatch(m)[1]); alt != "" {
+				return "\n" + alt + "\n"
+			}
+			return ""
+		})
+	}
+	if opts.Citations != "" {
+		text = citationRe.ReplaceAllString(text, "")
+	}
+	if opts.Quotes {
+		text = markdownQuotes(text)
+	}
+	return cleanTextEmoji(text, opts.Emoji)
+}
+
+// blockText renders the readability HTML to text, a line per block element,
+// with the options applied
+func (a *Article) blockText(opts TextOptions) string {
+	root, err := html.Parse(strings.NewReader(a.Content))
+	if err != nil {
+		return a.TextContent
+	}
+	var notes map[string]*html.Node // footnotes by id, read after the paragraph referring to them
+	if opts.Citations == "inline" {
+		notes = footnotes(root)
+	}
+	var pending []string
+	var sb strings.Builder
+	quoted, marker := false, "" // inside a blockquote, its marker not written yet
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case opts.Quotes && isVerse(n):
+			sb.WriteString("\n\n" + marker + verseText(n) + "\n\n")
+			marker = ""
+			return
+		case opts.Quotes && !quoted && n.Type == html.ElementNode && n.DataAtom == atom.Blockquote:
+			quoted, marker = true, quoteMarker
+			sb.WriteString("\n\n")
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				walk(c)
+			}
+			sb.WriteString("\n\n")
+			quoted, marker = false, ""
+			return
+		case n.Type == html.TextNode:
+			if marker != "" && strings.TrimSpace(n.Data) != "" {
+				sb.WriteString(marker) // on the line of the quote's first words
+				marker = ""
+			}
+			sb.WriteString(n.Data)
+			return
+		case n.Type == html.ElementNode && (n.DataAtom == atom.Script || n.DataAtom == atom.Style):
+			return
+		case opts.SkipCode && n.Type == html.ElementNode && n.DataAtom == atom.Pre:
+			sb.WriteString("\n")
+			return
+		case opts.SkipCode && n.Type == html.ElementNode && n.DataAtom == atom.Code && strings.Contains(nodeText(n), "\n"):
+			sb.WriteString("\n") // multi-line <code> without <pre> is a listing too, inline code stays
+			return
+		case opts.Images && n.Type == html.ElementNode && (n.DataAtom == atom.Figure || n.DataAtom == atom.Img):
+			sb.WriteString("\n" + figureDescription(n) + "\n")
+			return
+		case opts.Citations != "" && isCitationRef(n):
+			if note, ok := notes[strings.TrimPrefix(citationTarget(n), "#")]; ok {
+				if text := footnoteText(note); text != "" {
+					pending = append(pending, text)
+				}
+			}
+			return
+		case notes != nil && n.Type == html.ElementNode && notes[attr(n, "id")] != nil:
+			return // read where it's referred to
+		}
+		if n.Type == html.ElementNode && isBlockElement(n.DataAtom) {
+			sb.WriteString("\n")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if n.Type == html.ElementNode && isBlockElement(n.DataAtom) {
+			for _, note := range pending {
+				sb.WriteString("\nСноска: " + note) // a line of the paragraph referring to it
+			}
+			pending = nil
+			sb.WriteString("\n")
+		}
+	}
+	walk(root)
+	text := sb.String()
+	if opts.Citations != "" {
+		text = citationRe.ReplaceAllString(text, "") // markers left without links
+	}
+	return cleanTextEmoji(text, opts.Emoji)
+}
+
+// citationRe matches bracketed citation markers: [12], [3, 4], [5–7]
+var citationRe = regexp.MustCompile(`\s?\[\d{1,3}(?:\s?[,–-]\s?\d{1,3})*\]`)
+
+// citationMarkRe matches the text of a footnote reference link: 12, [12], a
+var citationMarkRe = regexp.MustCompile(`^\s*\[?\s*(\d{1,3}|[a-z])\s*\]?\s*$`)
+
+// isCitationRef reports whether the node is a footnote or citation reference:
+// an in-page link with a number as its text, or a <sup> holding one
+func isCitationRef(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	switch n.DataAtom {
+	case atom.A:
+		return strings.HasPrefix(attr(n, "href"), "#") && citationMarkRe.MatchString(nodeText(n))
+	case atom.Sup:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if isCitationRef(c) {
+				return true
+			}
+		}
+		return citationRe.MatchString(nodeText(n)) && citationMarkRe.MatchString(nodeText(n))
+	}
+	return false
+}
+
+// citationTarget returns the in-page href of a citation reference
+func citationTarget(n *html.Node) string {
+	if n.DataAtom == atom.A {
+		return attr(n, "href")
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			if href := citationTarget(c); href != "" {
+				return href
+			}
+		}
+	}
+	return ""
+}
+
+// maxFootnoteLen is the longest footnote read inline
+const maxFootnoteLen = 1500
+
+// footnotes maps the ids citation references point to onto their elements
+func footnotes(root *html.Node) map[string]*html.Node {
+	targets := map[string]bool{}
+	var refs func(n *html.Node)
+	refs = func(n *html.Node) {
+		if isCitationRef(n) {
+			if href := citationTarget(n); len(href) > 1 {
+				targets[href[1:]] = true
+			}
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			refs(c)
 		}
 	}
-	text = strings.Join(cleaned, "\n")
+	refs(root)
 
-	// Replace multiple spaces with single space
-	for strings.Contains(text, "  ") {
-		text = strings.ReplaceAll(text, "  ", " ")
+	res := map[string]*html.Node{}
+	var find func(n *html.Node)
+	find = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			// a long target is a section, not a note
+			if id := attr(n, "id"); id != "" && targets[id] && len([]rune(nodeText(n))) <= maxFootnoteLen {
+				res[id] = n
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			find(c)
+		}
 	}
+	find(root)
+	return res
+}
 
+// footnoteText is the text of a footnote without its back links ("↩", "^")
+func footnoteText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(c *html.Node)
+	walk = func(c *html.Node) {
+		if c.Type == html.TextNode {
+			sb.WriteString(c.Data)
+			return
+		}
+		if c.Type == html.ElementNode && c.DataAtom == atom.A && strings.HasPrefix(attr(c, "href"), "#") {
+			return
+		}
+		for ch := c.FirstChild; ch != nil; ch = ch.NextSibling {
+			walk(ch)
+		}
+	}
+	walk(n)
+	text := strings.Join(strings.Fields(sb.String()), " ")
+	text = strings.TrimSpace(strings.TrimLeft(text, "^↑↩ "))
+	if text != "" && !strings.ContainsAny(text[len(text)-1:], ".!?") {
+		text += "."
+	}
 	return text
 }
 
+var (
+	mdImageRe   = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`) // markdown images of the jina reader text
+	imageFileRe = regexp.MustCompile(`\.(jpe?g|png|gif|webp|svg)$`)
+)
+
+// figureDescription is the spoken description of a <figure> or <img>: the
+// caption, else the alt text. "" for images without a meaningful one.
+func figureDescription(n *html.Node) string {
+	var caption, alt string
+	var walk func(c *html.Node)
+	walk = func(c *html.Node) {
+		if c.Type != html.ElementNode {
+			return
+		}
+		switch c.DataAtom {
+		case atom.Figcaption:
+			if caption == "" {
+				caption = nodeText(c)
+			}
+			return
+		case atom.Img:
+			for _, at := range c.Attr {
+				if at.Key == "alt" && alt == "" {
+					alt = at.Val
+				}
+			}
+		}
+		for ch := c.FirstChild; ch != nil; ch = ch.NextSibling {
+			walk(ch)
+		}
+	}
+	walk(n)
+	if d := imageDescription(caption); d != "" {
+		return d
+	}
+	return imageDescription(alt)
+}
+
+// imageDescription makes "Изображение: text." of a caption or alt text,
+// placeholders like "image" or a file name give ""
+func imageDescription(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	lower := strings.ToLower(text)
+	switch {
+	case len([]rune(text)) < 3, lower == "image", lower == "img", lower == "photo", lower == "picture",
+		lower == "изображение", lower == "фото", lower == "картинка":
+		return ""
+	case !strings.Contains(text, " ") && (strings.ContainsAny(lower, "/\\") || imageFileRe.MatchString(lower)):
+		return ""
+	}
+	if !strings.ContainsAny(text[len(text)-1:], ".!?") {
+		text += "."
+	}
+	return "Изображение: " + text
+}
+
+// nodeText returns the concatenated text of n's subtree
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(nodeText(c))
+	}
+	return sb.String()
+}
+
+// isBlockElement reports whether the element ends a line of text
+func isBlockElement(a atom.Atom) bool {
+	switch a {
+	case atom.P, atom.Div, atom.Br, atom.Li, atom.Pre, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.Blockquote, atom.Tr, atom.Section, atom.Article, atom.Figure, atom.Figcaption, atom.Header, atom.Footer:
+		return true
+	}
+	return false
+}
+
+// stripCodeFences drops ``` fenced blocks from markdown text
+func stripCodeFences(text string) string {
+	var out []string
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if !inFence {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// cleanText removes extra whitespace and cleans up text for TTS, emoji
+// dropped. Paragraphs are kept apart by a blank line.
+func cleanText(text string) string {
+	return cleanTextEmoji(text, "")
+}
+
+// cleanTextEmoji is cleanText with the emoji handled by mode (see speakEmoji)
+func cleanTextEmoji(text, emoji string) string {
+	return strings.Join(cleanParagraphs(text, emoji), "\n\n")
+}
+
+// blankLineRe matches the blank lines between paragraphs
+var blankLineRe = regexp.MustCompile(`\n\s*\n`)
+
+// cleanParagraphs cleans up text for TTS into its paragraphs, parts between
+// blank lines (a block element of the article HTML), with the emoji handled
+// by mode (see speakEmoji). Lines of a paragraph are trimmed and kept, empty
+// ones dropped. HTML entities left in the text are decoded, typographic
+// quotes made plain, and the rest is normalized by Unicode category: any
+// space (NBSP, thin, ideographic...) is a space, line and paragraph
+// separators are line breaks, invisible format and control characters,
+// which TTS spells or SSML rejects, are dropped.
+func cleanParagraphs(text, emoji string) []string {
+	if strings.Contains(text, "&") {
+		text = html.UnescapeString(text)
+	}
+	text = strings.Map(normalizeRune, text)
+	text = speakEmoji(text, emoji)
+
+	var res []string
+	for _, para := range blankLineRe.Split(text, -1) {
+		var lines []string
+		for _, line := range strings.Split(para, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		para = strings.Join(lines, "\n")
+		// Replace multiple spaces with single space
+		for strings.Contains(para, "  ") {
+			para = strings.ReplaceAll(para, "  ", " ")
+		}
+		res = append(res, para)
+	}
+	return res
+}
+
+// paragraphs splits text into its paragraphs, blank ones dropped
+func paragraphs(text string) []string {
+	var res []string
+	for _, para := range blankLineRe.Split(text, -1) {
+		if strings.TrimSpace(para) != "" {
+			res = append(res, para)
+		}
+	}
+	return res
+}
+
+// normalizeRune maps a rune of the text to what TTS reads well, -1 drops it
+func normalizeRune(r rune) rune {
+	switch r {
+	case '\n':
+		return r
+	case '\t', '\r', '\v', '\f':
+		return ' '
+	case '\u201c', '\u201d', '\u201e', '\u201f', '\u2033': // “ ” „ ‟ ″
+		return '"'
+	case '\u2018', '\u2019', '\u201a', '\u201b', '\u2032': // ‘ ’ ‚ ‛ ′
+		return '\''
+	case '\u200d', '\ufe0f', '\ufe0e': // joiner and variation selectors are parts of emoji, speakEmoji handles them
+		return r
+	}
+	switch {
+	case unicode.In(r, unicode.Zl, unicode.Zp):
+		return '\n'
+	case unicode.IsSpace(r) || unicode.Is(unicode.Zs, r):
+		return ' '
+	case unicode.IsControl(r) || (unicode.Is(unicode.Cf, r) && !isEmojiPart(r)):
+		return -1 // soft hyphen, zero width space, bidi marks, BOM
+	}
+	return r
+}
+
 // IsArticleURL checks if URL looks like an article (not YouTube, not image, etc.)
 func IsArticleURL(rawURL string) bool {
 	// Check if it's a YouTube URL
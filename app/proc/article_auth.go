@@ -0,0 +1,155 @@
+package proc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+)
+
+// authorize adds the configured credentials of the request host: the cookies
+// of the cookies file matching it and the headers and cookies of the closest
+// configured domain. Returns true if anything was added and the names of the
+// headers set, they must not follow a redirect to another host.
+func (e *ArticleExtractor) authorize(req *http.Request) (added bool, headers []string) {
+	host := strings.ToLower(req.URL.Hostname())
+	if e.CookiesFile != "" {
+		now := time.Now()
+		for _, c := range e.fileCookies() {
+			if !cookieDomainMatch(host, c.Domain) || !strings.HasPrefix(req.URL.Path, c.Path) ||
+				(c.Secure && req.URL.Scheme != "https") || (!c.Expires.IsZero() && c.Expires.Before(now)) {
+				continue
+			}
+			req.AddCookie(&http.Cookie{Name: c.Name, Value: c.Value})
+			added = true
+		}
+	}
+
+	best := ""
+	for domain := range e.Sites {
+		if domainMatch(host, domain) && len(domain) > len(best) {
+			best = domain
+		}
+	}
+	if best == "" {
+		return added, nil
+	}
+	site := e.Sites[best]
+	for k, v := range site.Headers {
+		req.Header.Set(k, v)
+		headers = append(headers, k)
+	}
+	for name, value := range site.Cookies {
+		req.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+	return added || len(site.Headers) > 0 || len(site.Cookies) > 0, headers
+}
+
+// fileCookies returns the cookies of CookiesFile, read again only when the
+// file changes, so a refreshed export needs no restart
+func (e *ArticleExtractor) fileCookies() []*http.Cookie {
+	e.cookiesMu.Lock()
+	defer e.cookiesMu.Unlock()
+	st, err := os.Stat(e.CookiesFile)
+	if err != nil {
+		log.Printf("[WARN] can't read article cookies: %v", err)
+		return nil
+	}
+	if st.ModTime().Equal(e.cookiesMod) && e.cookies != nil {
+		return e.cookies
+	}
+	cookies, err := readCookiesFile(e.CookiesFile)
+	if err != nil {
+		log.Printf("[WARN] can't read article cookies: %v", err)
+		return nil
+	}
+	e.cookies, e.cookiesMod = cookies, st.ModTime()
+	return cookies
+}
+
+// withoutHeadersOffHost is the client dropping the site headers from redirects
+// to another host. net/http keeps Authorization and Cookie to the same domain
+// only, but forwards any other header, an API key of the site included.
+func withoutHeadersOffHost(client *http.Client, headers []string) *http.Client {
+	res := *client
+	check := client.CheckRedirect
+	res.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if !strings.EqualFold(req.URL.Hostname(), via[0].URL.Hostname()) {
+			for _, k := range headers {
+				req.Header.Del(k)
+			}
+		}
+		if check != nil {
+			return check(req, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	return &res
+}
+
+// domainMatch tells if host is the domain or its subdomain
+func domainMatch(host, domain string) bool {
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	return domain != "" && (host == domain || strings.HasSuffix(host, "."+domain))
+}
+
+// cookieDomainMatch is domainMatch for a ".example.com" cookie domain, a
+// domain without the dot is of that host only
+func cookieDomainMatch(host, domain string) bool {
+	if strings.HasPrefix(domain, ".") {
+		return domainMatch(host, domain)
+	}
+	return host == strings.ToLower(domain)
+}
+
+// readCookiesFile reads a Netscape cookies file, as exported by browser
+// extensions for yt-dlp and curl
+func readCookiesFile(file string) ([]*http.Cookie, error) {
+	f, err := os.Open(file) //nolint:gosec // file of the config
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck // read-only
+	return parseNetscapeCookies(f)
+}
+
+// parseNetscapeCookies parses the tab separated lines of a cookies file:
+// domain, include subdomains (the domain gets a leading dot), path, secure,
+// expires (unix time, 0 = session), name and value. "#HttpOnly_" prefixed
+// domains are cookies too.
+func parseNetscapeCookies(r io.Reader) ([]*http.Cookie, error) {
+	var res []*http.Cookie
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimPrefix(strings.TrimRight(scanner.Text(), "\r\n"), "#HttpOnly_")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) < 7 {
+			return nil, fmt.Errorf("line %d: %d fields, 7 expected", n, len(fields))
+		}
+		domain := strings.TrimPrefix(fields[0], ".")
+		if strings.EqualFold(fields[1], "TRUE") {
+			domain = "." + domain
+		}
+		c := &http.Cookie{Domain: domain, Path: fields[2], Secure: strings.EqualFold(fields[3], "TRUE"),
+			Name: fields[5], Value: fields[6]}
+		if exp, err := strconv.ParseInt(fields[4], 10, 64); err == nil && exp > 0 {
+			c.Expires = time.Unix(exp, 0)
+		}
+		res = append(res, c)
+	}
+	return res, scanner.Err()
+}
@@ -0,0 +1,161 @@
+package proc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/feed-master/app/config"
+)
+
+const testCookiesFile = `# Netscape HTTP Cookie File
+.paper.com	TRUE	/	FALSE	0	session	abc
+#HttpOnly_.paper.com	TRUE	/	TRUE	0	secure_only	s1
+www.paper.com	FALSE	/	FALSE	0	host_only	h1
+.paper.com	TRUE	/	FALSE	1000	expired	old
+.paper.com	TRUE	/premium	FALSE	0	deep	d1
+.other.com	TRUE	/	FALSE	0	foreign	f1
+` +
+	".paper.com\tTRUE\t/\tFALSE\t0\tempty\t\n" // empty value, a trailing tab
+
+func TestParseNetscapeCookies(t *testing.T) {
+	cookies, err := parseNetscapeCookies(strings.NewReader(testCookiesFile))
+	require.NoError(t, err)
+	require.Len(t, cookies, 7)
+	assert.Equal(t, ".paper.com", cookies[0].Domain)
+	assert.Equal(t, "session", cookies[0].Name)
+	assert.True(t, cookies[1].Secure, "httponly line is a cookie")
+	assert.Equal(t, "www.paper.com", cookies[2].Domain, "no subdomains, no dot")
+	assert.Equal(t, int64(1000), cookies[3].Expires.Unix())
+	assert.Empty(t, cookies[6].Value)
+
+	_, err = parseNetscapeCookies(strings.NewReader("paper.com\tTRUE\t/\n"))
+	assert.ErrorContains(t, err, "line 1")
+}
+
+func TestArticleExtractor_Authorize(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cookies.txt")
+	require.NoError(t, os.WriteFile(file, []byte(testCookiesFile), 0o600))
+	e := &ArticleExtractor{CookiesFile: file, Sites: map[string]config.SiteAuth{
+		"paper.com":      {Headers: map[string]string{"Authorization": "Bearer all"}},
+		"news.paper.com": {Headers: map[string]string{"Authorization": "Bearer news"}, Cookies: map[string]string{"sub": "1"}},
+	}}
+
+	tbl := []struct {
+		url     string
+		cookies []string
+		auth    string
+	}{
+		{"http://paper.com/a", []string{"session=abc", "empty="}, "Bearer all"},
+		{"https://www.paper.com/premium/x", []string{"session=abc", "secure_only=s1", "host_only=h1", "deep=d1", "empty="}, "Bearer all"},
+		{"http://news.paper.com/a", []string{"session=abc", "empty=", "sub=1"}, "Bearer news"},
+		{"http://notpaper.com/a", nil, ""},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.url, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, http.NoBody)
+			req.Header.Del("Cookie")
+			added, headers := e.authorize(req)
+			assert.Equal(t, tt.auth != "", added)
+			assert.Equal(t, tt.auth != "", slices.Contains(headers, "Authorization"))
+			var got []string
+			for _, c := range req.Cookies() {
+				got = append(got, c.Name+"="+c.Value)
+			}
+			assert.Equal(t, tt.cookies, got)
+			assert.Equal(t, tt.auth, req.Header.Get("Authorization"))
+		})
+	}
+}
+
+func TestArticleExtractor_FileCookies(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cookies.txt")
+	require.NoError(t, os.WriteFile(file, []byte(testCookiesFile), 0o600))
+	e := &ArticleExtractor{CookiesFile: file}
+	require.Len(t, e.fileCookies(), 7)
+
+	// same mtime, the cached cookies
+	st, err := os.Stat(file)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(file, []byte(".paper.com\tTRUE\t/\tFALSE\t0\tfresh\t1\n"), 0o600))
+	require.NoError(t, os.Chtimes(file, st.ModTime(), st.ModTime()))
+	assert.Len(t, e.fileCookies(), 7)
+
+	// refreshed export, read again
+	require.NoError(t, os.Chtimes(file, st.ModTime().Add(time.Minute), st.ModTime().Add(time.Minute)))
+	cookies := e.fileCookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "fresh", cookies[0].Name)
+
+	require.NoError(t, os.Remove(file))
+	assert.Empty(t, e.fileCookies())
+}
+
+func TestArticleExtractor_AuthorizedRedirect(t *testing.T) {
+	var gotKey, gotOwnKey string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("X-Api-Key")
+		_, _ = w.Write([]byte(`<html><head><title>Other</title></head><body><article><p>` +
+			strings.Repeat("Text of the page on another host. ", 30) + `</p></article></body></html>`))
+	}))
+	defer other.Close()
+	otherURL := strings.Replace(other.URL, "127.0.0.1", "localhost", 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/moved" {
+			gotOwnKey = r.Header.Get("X-Api-Key")
+			http.Redirect(w, r, otherURL+"/page", http.StatusFound)
+			return
+		}
+		http.Redirect(w, r, "/moved", http.StatusFound)
+	}))
+	defer ts.Close()
+
+	e := NewArticleExtractor()
+	e.Sites = map[string]config.SiteAuth{"127.0.0.1": {Headers: map[string]string{"X-Api-Key": "secret"}}}
+	article, err := e.Extract(context.Background(), ts.URL+"/start")
+	require.NoError(t, err)
+	assert.Contains(t, article.TextContent, "another host")
+	assert.Equal(t, "secret", gotOwnKey, "kept on the same host")
+	assert.Empty(t, gotKey, "dropped on the other host")
+	assert.Nil(t, e.HTTPClient.CheckRedirect, "shared client untouched")
+}
+
+func TestArticleExtractor_ExtractAuthorized(t *testing.T) {
+	var gotCookie string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotCookie = r.Header.Get("Cookie")
+		if gotCookie != "token=secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`<html><head><title>Paid</title></head><body><article><p>` +
+			strings.Repeat("Subscriber only text of the article. ", 30) + `</p></article></body></html>`))
+	}))
+	defer ts.Close()
+
+	e := NewArticleExtractor()
+	e.Sites = map[string]config.SiteAuth{"127.0.0.1": {Cookies: map[string]string{"token": "secret"}}}
+	article, err := e.Extract(context.Background(), ts.URL+"/paid")
+	require.NoError(t, err)
+	assert.Contains(t, article.TextContent, "Subscriber only text")
+
+	e.Sites["127.0.0.1"] = config.SiteAuth{Cookies: map[string]string{"token": "expired"}}
+	_, err = e.Extract(context.Background(), ts.URL+"/paid")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "HTTP error: 403")
+	assert.NotContains(t, err.Error(), "jina", "no logged out fallback for a site with credentials")
+
+	e.Sites["127.0.0.1"] = config.SiteAuth{Cookies: map[string]string{"token": "secret"}}
+	doc, err := e.ExtractStructured(context.Background(), ts.URL+"/paid")
+	require.NoError(t, err, "reading layer sends the credentials too")
+	assert.Contains(t, doc.MD, "Subscriber only text")
+}
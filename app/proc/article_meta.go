@@ -0,0 +1,56 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+
+	log "github.com/go-pkgz/lgr"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// markTranslated tells in the entry that the audio is a translation: the
+// author becomes "Source — перевод" and the description starts with the
+// original title. With TranslateTitles the title itself is translated too,
+// so the feed doesn't show an English title over Russian audio.
+func (t *TelegramBot) markTranslated(ctx context.Context, entry *ytfeed.Entry, article *Article, articleURL string,
+	translator TranslationProvider) {
+	source := articleSource(article, articleURL)
+	if source != "" {
+		entry.Author.Name = source + " — перевод"
+	}
+	original := article.Title
+	note := fmt.Sprintf("Перевод статьи «%s»", original)
+	if source != "" {
+		note += " (" + source + ")"
+	}
+	entry.Media.Description = template.HTML(template.HTMLEscapeString(note)) + "\n" + entry.Media.Description //nolint:gosec // escaped
+
+	if !t.TranslateTitles || original == "" {
+		return
+	}
+	title, err := translator.Translate(ctx, original)
+	if err != nil {
+		log.Printf("[WARN] can't translate title %q, keeping the original: %v", original, err)
+		return
+	}
+	if title = strings.TrimSpace(title); title != "" {
+		entry.Title = title
+	}
+}
+
+// articleSource names where the article comes from: the site name, else the
+// host of its URL
+func articleSource(article *Article, articleURL string) string {
+	if article.SiteName != "" {
+		return article.SiteName
+	}
+	u, err := url.Parse(articleURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
@@ -0,0 +1,49 @@
+package proc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+)
+
+func TestTelegramBot_ProcessArticleTranslatedMeta(t *testing.T) {
+	statusMsg := &tb.Message{ID: 5, Chat: &tb.Chat{ID: testBotUserID}}
+	text := strings.Repeat("This is an English article about economics. ", 5)
+
+	tbl := []struct {
+		name            string
+		translateTitles bool
+		article         *Article
+		wantTitle       string
+		wantAuthor      string
+		wantDesc        string
+	}{
+		{"site name", false, &Article{Title: "Rates & bonds", SiteName: "The Economist", TextContent: text},
+			"Rates & bonds", "The Economist — перевод", "Перевод статьи «Rates &amp; bonds» (The Economist)\nTTS озвучка"},
+		{"host and title", true, &Article{Title: "Rates", TextContent: text},
+			"[ru] Rates", "example.com — перевод", "Перевод статьи «Rates» (example.com)\n"},
+		{"russian text untouched", true, &Article{Title: "Ставки", SiteName: "РБК", TextContent: "Русский текст статьи."},
+			"Ставки", "РБК", "TTS озвучка статьи"},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := newTestBot(t, newTgStub(t))
+			bot.Store = newTestJobStore(t)
+			bot.MaxItems = 10
+			bot.TranslateTitles = tt.translateTitles
+			req := articleRequest{URL: "https://www.example.com/a", Article: tt.article}
+			require.NoError(t, bot.processArticle(context.Background(), nil, statusMsg, nil, req))
+
+			entries, err := bot.Store.Load(bot.FeedName, 10)
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			assert.Equal(t, tt.wantTitle, entries[0].Title)
+			assert.Equal(t, tt.wantAuthor, entries[0].Author.Name)
+			assert.True(t, strings.HasPrefix(string(entries[0].Media.Description), tt.wantDesc), entries[0].Media.Description)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+)
+
+// errRobotsDisallowed is returned for pages robots.txt of the site disallows,
+// with ArticleExtractor.Robots on
+var errRobotsDisallowed = errors.New("disallowed by robots.txt")
+
+const (
+	robotsTTL     = 24 * time.Hour
+	maxRobotsSize = 512 * 1024
+)
+
+// domainGate lets one page request at a time to a domain, Delay apart
+type domainGate struct {
+	sem  chan struct{}
+	last time.Time // end of the previous request, guarded by sem
+}
+
+// robotsRules are the rules of the "*" group of a robots.txt
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+	fetched    time.Time
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string // as written, its length decides between matching rules
+	re      *regexp.Regexp
+}
+
+// waitDomain waits for the turn of a page request to host: after the running
+// one and Delay (or the robots.txt Crawl-delay, if longer) past the previous.
+// Release when the request is done.
+func (e *ArticleExtractor) waitDomain(ctx context.Context, host string, crawlDelay time.Duration) (release func(), err error) {
+	e.politeMu.Lock()
+	if e.gates == nil {
+		e.gates = map[string]*domainGate{}
+	}
+	g, ok := e.gates[host]
+	if !ok {
+		g = &domainGate{sem: make(chan struct{}, 1)}
+		e.gates[host] = g
+	}
+	e.politeMu.Unlock()
+
+	select {
+	case g.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if wait := time.Until(g.last.Add(max(e.Delay, crawlDelay))); wait > 0 {
+		log.Printf("[DEBUG] waiting %v before the next request to %s", wait.Round(time.Millisecond), host)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			<-g.sem
+			return nil, ctx.Err()
+		}
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			g.last = time.Now()
+			<-g.sem
+		})
+	}, nil
+}
+
+// checkRobots returns errRobotsDisallowed if robots.txt of the site forbids
+// the page, and the Crawl-delay of the site. Off without Robots. A robots.txt
+// failing to load allows everything.
+func (e *ArticleExtractor) checkRobots(ctx context.Context, u *url.URL) (time.Duration, error) {
+	if !e.Robots {
+		return 0, nil
+	}
+	rules := e.robotsOf(ctx, u)
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	if !rules.allowed(path) {
+		return rules.crawlDelay, fmt.Errorf("%w: %s", errRobotsDisallowed, u.String())
+	}
+	return rules.crawlDelay, nil
+}
+
+// robotsOf returns the robots.txt rules of the site, cached for robotsTTL
+func (e *ArticleExtractor) robotsOf(ctx context.Context, u *url.URL) *robotsRules {
+	site := u.Scheme + "://" + u.Host
+	e.politeMu.Lock()
+	cached, ok := e.robots[site]
+	e.politeMu.Unlock()
+	if ok && time.Since(cached.fetched) < robotsTTL {
+		return cached
+	}
+
+	rules := &robotsRules{fetched: time.Now()}
+	if data, err := e.fetchRobots(ctx, site+"/robots.txt"); err != nil {
+		log.Printf("[DEBUG] no robots.txt of %s, everything allowed: %v", u.Host, err)
+	} else {
+		rules = parseRobots(bytes.NewReader(data))
+		rules.fetched = time.Now()
+	}
+	e.politeMu.Lock()
+	if e.robots == nil {
+		e.robots = map[string]*robotsRules{}
+	}
+	e.robots[site] = rules
+	e.politeMu.Unlock()
+	return rules
+}
+
+func (e *ArticleExtractor) fetchRobots(ctx context.Context, robotsURL string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := e.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck // read-only body
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxRobotsSize))
+}
+
+// parseRobots reads the Allow, Disallow and Crawl-delay lines of the groups
+// for any user agent ("*"), the page requests look like a browser's
+func parseRobots(r io.Reader) *robotsRules {
+	res := &robotsRules{}
+	scanner := bufio.NewScanner(r)
+	inGroup, groupStarted := false, false // the group is for "*"; its user-agent lines are over
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		switch key {
+		case "user-agent":
+			if groupStarted { // a user-agent after rules opens a new group
+				inGroup, groupStarted = false, false
+			}
+			if value == "*" {
+				inGroup = true
+			}
+		case "allow", "disallow":
+			groupStarted = true
+			if inGroup && value != "" { // an empty Disallow allows everything
+				res.rules = append(res.rules, robotsRule{allow: key == "allow", pattern: value, re: robotsPattern(value)})
+			}
+		case "crawl-delay":
+			groupStarted = true
+			if secs, err := strconv.ParseFloat(value, 64); inGroup && err == nil && secs > 0 {
+				res.crawlDelay = time.Duration(secs * float64(time.Second))
+			}
+		}
+	}
+	return res
+}
+
+// robotsPattern compiles a path prefix with the * (any) and trailing $ (end) wildcards
+func robotsPattern(p string) *regexp.Regexp {
+	anchored := strings.HasSuffix(p, "$")
+	expr := strings.ReplaceAll(regexp.QuoteMeta(strings.TrimSuffix(p, "$")), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile("^" + expr)
+}
+
+// allowed tells if the path is allowed: the longest matching rule decides,
+// Allow wins a tie
+func (r *robotsRules) allowed(path string) bool {
+	allow, best := true, -1
+	for _, rule := range r.rules {
+		if !rule.re.MatchString(path) {
+			continue
+		}
+		if l := len(rule.pattern); l > best || (l == best && rule.allow) {
+			allow, best = rule.allow, l
+		}
+	}
+	return allow
+}
@@ -0,0 +1,138 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRobots(t *testing.T) {
+	rules := parseRobots(strings.NewReader(`
+# comment
+User-agent: Googlebot
+Disallow: /
+
+User-agent: Bingbot
+User-agent: *
+Disallow: /private/
+Allow: /private/open
+Disallow: /*.pdf$
+Disallow: /search?
+Disallow:
+Crawl-delay: 1.5
+
+User-agent: other
+Disallow: /blog/
+`))
+	assert.Equal(t, 1500*time.Millisecond, rules.crawlDelay)
+	tbl := []struct {
+		path    string
+		allowed bool
+	}{
+		{"/", true},
+		{"/blog/post", true},
+		{"/private/", false},
+		{"/private/x", false},
+		{"/private/open-day", true},
+		{"/files/a.pdf", false},
+		{"/files/a.pdf?x=1", true},
+		{"/search?q=go", false},
+		{"/search", true},
+	}
+	for _, tt := range tbl {
+		assert.Equal(t, tt.allowed, rules.allowed(tt.path), tt.path)
+	}
+
+	assert.True(t, parseRobots(strings.NewReader("")).allowed("/any"), "no robots.txt")
+	equal := parseRobots(strings.NewReader("User-agent: *\nDisallow: /a\nAllow: /a\n"))
+	assert.True(t, equal.allowed("/a/b"), "allow wins a tie")
+}
+
+func TestArticleExtractor_WaitDomain(t *testing.T) {
+	e := &ArticleExtractor{Delay: 50 * time.Millisecond}
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+	start := time.Now()
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := e.waitDomain(context.Background(), "a.com", 0)
+			require.NoError(t, err)
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			release()
+			release() // idempotent
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), maxRunning, "one request at a time")
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond, "delay between requests")
+
+	// another domain doesn't wait
+	begin := time.Now()
+	release, err := e.waitDomain(context.Background(), "b.com", 0)
+	require.NoError(t, err)
+	release()
+	assert.Less(t, time.Since(begin), 40*time.Millisecond)
+
+	// crawl delay over the configured one, cancel while waiting
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = e.waitDomain(ctx, "b.com", time.Minute)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	release, err = e.waitDomain(context.Background(), "a.com", 0)
+	require.NoError(t, err, "slot freed after the cancel")
+	release()
+}
+
+func TestArticleExtractor_ExtractRobots(t *testing.T) {
+	var robotsHits, pageHits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			atomic.AddInt32(&robotsHits, 1)
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /members/\n"))
+		default:
+			atomic.AddInt32(&pageHits, 1)
+			_, _ = w.Write([]byte(`<html><head><title>Post</title></head><body><article><p>` +
+				strings.Repeat("Text of the public post goes here. ", 30) + `</p></article></body></html>`))
+		}
+	}))
+	defer ts.Close()
+
+	e := NewArticleExtractor()
+	e.Robots = true
+	_, err := e.Extract(context.Background(), ts.URL+"/members/post")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, errRobotsDisallowed))
+	assert.NotContains(t, err.Error(), "jina")
+	assert.True(t, IsPermanent(err))
+	assert.Contains(t, (&TelegramBot{}).userErrorText(err), "robots.txt")
+
+	article, err := e.Extract(context.Background(), ts.URL+"/blog/post")
+	require.NoError(t, err)
+	assert.Equal(t, "Post", article.Title)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&robotsHits), "robots.txt cached")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&pageHits))
+
+	e.Robots = false
+	_, err = e.Extract(context.Background(), ts.URL+"/members/post")
+	require.NoError(t, err, "check off")
+}
@@ -0,0 +1,173 @@
+package proc
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// quoteMarker starts a blockquote read aloud, so the listener hears where
+// the author stops and the quoted text begins
+const quoteMarker = "Цитата: "
+
+// verseClassRe matches class names of poetry containers on blogs and magazines
+var verseClassRe = regexp.MustCompile(`(?i)\b(poem|poetry|verse|stanza)\b`)
+
+// mdQuoteRe matches a markdown blockquote line of the jina reader text
+var mdQuoteRe = regexp.MustCompile(`^\s*>\s?`)
+
+// hasQuotes reports whether the article has blockquotes or verse, which
+// the spoken text reads differently from prose
+func (a *Article) hasQuotes() bool {
+	if a.Content == "" {
+		for _, line := range strings.Split(a.TextContent, "\n") {
+			if mdQuoteRe.MatchString(line) {
+				return true
+			}
+		}
+		return false
+	}
+	root, err := html.Parse(strings.NewReader(a.Content))
+	if err != nil {
+		return false
+	}
+	var find func(n *html.Node) bool
+	find = func(n *html.Node) bool {
+		if n.Type == html.ElementNode && (n.DataAtom == atom.Blockquote || isVerse(n)) {
+			return true
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if find(c) {
+				return true
+			}
+		}
+		return false
+	}
+	return find(root)
+}
+
+// isVerse reports whether the element holds poetry: marked so by its class,
+// or a paragraph of three and more short lines broken with <br>
+func isVerse(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	if verseClassRe.MatchString(attr(n, "class")) {
+		return true
+	}
+	if n.DataAtom != atom.P && n.DataAtom != atom.Div {
+		return false
+	}
+	var lines []string
+	var line strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.ElementNode && c.DataAtom == atom.Br:
+			lines = append(lines, line.String())
+			line.Reset()
+		case c.Type == html.ElementNode && isBlockElement(c.DataAtom):
+			return false // a container of paragraphs, they're checked on their own
+		default:
+			line.WriteString(nodeText(c))
+		}
+	}
+	lines = append(lines, line.String())
+	short := 0
+	for _, l := range lines {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		if utf8.RuneCountInString(l) > 70 {
+			return false
+		}
+		short++
+	}
+	return short >= 3
+}
+
+// verseText renders poetry a line per line, stanzas (paragraphs or blank
+// lines) apart by a blank line. Lines without punctuation at the end get a
+// comma, a short pause, instead of running into the next one.
+func verseText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			sb.WriteString(strings.ReplaceAll(n.Data, "\n", " "))
+			return
+		case n.Type == html.ElementNode && (n.DataAtom == atom.Script || n.DataAtom == atom.Style):
+			return
+		case n.Type == html.ElementNode && n.DataAtom == atom.Br:
+			sb.WriteString("\n")
+			return
+		}
+		block := n.Type == html.ElementNode && isBlockElement(n.DataAtom)
+		if block {
+			sb.WriteString("\n\n")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if block {
+			sb.WriteString("\n\n")
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c)
+	}
+	return versePauses(sb.String())
+}
+
+// versePauses ends the lines of each stanza but the last with a comma
+// unless punctuated, the stanza end is left to withPauses
+func versePauses(text string) string {
+	var stanzas []string
+	for _, para := range blankLineRe.Split(text, -1) {
+		var lines []string
+		for _, line := range strings.Split(para, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		for i, line := range lines[:max(len(lines)-1, 0)] {
+			if last, _ := utf8.DecodeLastRuneInString(line); !unicode.IsPunct(last) {
+				lines[i] = line + ","
+			}
+		}
+		if len(lines) > 0 {
+			stanzas = append(stanzas, strings.Join(lines, "\n"))
+		}
+	}
+	return strings.Join(stanzas, "\n\n")
+}
+
+// markdownQuotes turns the markdown blockquotes of the jina reader text into
+// paragraphs starting with quoteMarker
+func markdownQuotes(text string) string {
+	lines := strings.Split(text, "\n")
+	var out []string
+	inQuote := false
+	for _, line := range lines {
+		if !mdQuoteRe.MatchString(line) {
+			if inQuote {
+				out = append(out, "")
+			}
+			out, inQuote = append(out, line), false
+			continue
+		}
+		line = mdQuoteRe.ReplaceAllString(line, "")
+		if !inQuote {
+			out = append(out, "", quoteMarker+line)
+			inQuote = true
+			continue
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
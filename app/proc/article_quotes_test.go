@@ -0,0 +1,30 @@
+package proc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArticle_Quotes(t *testing.T) {
+	a := &Article{Content: `<div><p>He wrote:</p><blockquote><p>Simplicity is prerequisite.</p>` +
+		`<blockquote><p>Nested.</p></blockquote></blockquote><p>After the quote.</p>` +
+		`<p>Roses are red<br>Violets are blue<br>Sugar is sweet<br>And so are you</p>` +
+		`<div class="poem"><p>First line<br>second line!</p><p>Stanza two<br>ends here</p></div>` +
+		`<p>A short line<br>and a very long line of prose that goes well over seventy characters in one go</p></div>`}
+	assert.True(t, a.hasQuotes())
+	assert.Equal(t, "He wrote:\n\nЦитата: Simplicity is prerequisite.\n\nNested.\n\nAfter the quote.\n\n"+
+		"Roses are red,\nViolets are blue,\nSugar is sweet,\nAnd so are you\n\n"+
+		"First line,\nsecond line!\n\nStanza two,\nends here\n\n"+
+		"A short line\n\nand a very long line of prose that goes well over seventy characters in one go",
+		a.Text(TextOptions{Quotes: true}))
+	assert.NotContains(t, a.BlockText(), "Цитата", "content hash text unchanged")
+	assert.Contains(t, a.BlockText(), "Roses are red\n\nViolets are blue")
+
+	assert.False(t, (&Article{Content: "<p>Plain prose.</p><p>Two<br>lines</p>"}).hasQuotes())
+
+	jina := &Article{TextContent: "Intro.\n> Quoted line one\n> line two\nBack to text."}
+	assert.True(t, jina.hasQuotes())
+	assert.Equal(t, "Intro.\n\nЦитата: Quoted line one\nline two\n\nBack to text.", jina.Text(TextOptions{Quotes: true}))
+	assert.False(t, (&Article{TextContent: "a > b"}).hasQuotes())
+}
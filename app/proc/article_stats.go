@@ -0,0 +1,77 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	"github.com/umputun/feed-master/app/config"
+)
+
+// articleStats is the reading stats of the article for the link menu: words,
+// estimated audio length, language and whether the preset translates it
+func (t *TelegramBot) articleStats(a *Article, preset config.Preset) string {
+	text := a.TextContent
+	words := len(strings.Fields(text))
+	lang := DetectLanguage(text)
+	var sb strings.Builder
+	if a.Title != "" {
+		fmt.Fprintf(&sb, "📰 %s\n", a.Title)
+	}
+	fmt.Fprintf(&sb, t.tr("📊 %d слов, %d символов, ~%s аудио, язык: %s"), words, len([]rune(text)),
+		t.formatDuration(EstimateDuration(text)), lang)
+	if tr := t.translatorFor(preset); tr != nil && tr.NeedsTranslation(text) {
+		sb.WriteString(t.tr(", будет перевод на русский"))
+	}
+	if limit := t.ArticleLimit.MaxChars; limit > 0 && len([]rune(text)) > limit {
+		fmt.Fprintf(&sb, t.tr("\n📏 Длиннее лимита в %d символов"), limit)
+	}
+	return sb.String()
+}
+
+// articleMenuText is the prompt of the article link menu, with the reading
+// stats once the article is extracted
+func (t *TelegramBot) articleMenuText(pa *pendingAction) string {
+	prompt := t.tr("🤔 Что сделать со ссылкой?") + t.presetNote(pa.preset)
+	if pa.article == nil {
+		return prompt
+	}
+	return t.articleStats(pa.article, t.preset(pa.preset)) + "\n\n" + prompt
+}
+
+// previewArticle extracts the linked article while the menu is shown and puts
+// its reading stats into the menu, so the choice is made knowing the length.
+// Voicing then reuses the extracted text. Nothing changes when the menu has
+// been used meanwhile.
+func (t *TelegramBot) previewArticle(ctx context.Context, menuMsg *tb.Message, token string) {
+	t.pendingMu.Lock()
+	pa, ok := t.pendingActions[token]
+	var rawURL string
+	if ok {
+		rawURL = pa.url
+	}
+	t.pendingMu.Unlock()
+	if !ok {
+		return
+	}
+
+	articleURL := t.resolveURL(ctx, rawURL)
+	article, err := t.ArticleExtractor.Extract(ctx, articleURL)
+	if err != nil {
+		log.Printf("[DEBUG] no reading stats for %s: %v", articleURL, err)
+		return
+	}
+
+	t.pendingMu.Lock()
+	if cur, ok := t.pendingActions[token]; !ok || cur != pa {
+		t.pendingMu.Unlock()
+		return
+	}
+	pa.url, pa.article = articleURL, article
+	text := t.articleMenuText(pa)
+	t.pendingMu.Unlock()
+	t.edit(menuMsg, text, t.buildActionMenu(token, "article"))
+}
@@ -0,0 +1,63 @@
+package proc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	"github.com/umputun/feed-master/app/config"
+)
+
+func TestTelegramBot_ArticleStats(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	bot.ArticleLimit = config.ArticleLimit{MaxChars: 100}
+	a := &Article{Title: "Long read", TextContent: strings.Repeat("word ", 40)}
+
+	stats := bot.articleStats(a, config.Preset{})
+	assert.Contains(t, stats, "📰 Long read")
+	assert.Contains(t, stats, "40 слов, 200 символов")
+	assert.Contains(t, stats, "язык: en, будет перевод на русский")
+	assert.Contains(t, stats, "Длиннее лимита в 100 символов")
+
+	noTranslate := false
+	stats = bot.articleStats(&Article{TextContent: "short text"}, config.Preset{Translate: &noTranslate})
+	assert.NotContains(t, stats, "перевод")
+	assert.NotContains(t, stats, "лимита")
+}
+
+func TestTelegramBot_PreviewArticle(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Preview me</title></head><body><article><h1>Preview me</h1><p>` +
+			strings.Repeat("This article is in English and long enough for readability. ", 10) + `</p></article></body></html>`))
+	}))
+	defer ts.Close()
+
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.ArticleExtractor = NewArticleExtractor()
+	menuMsg := &tb.Message{ID: 7, Chat: &tb.Chat{ID: testBotUserID}}
+
+	token := bot.storePendingAction(&pendingAction{kind: "article", url: ts.URL + "/post?utm_source=x"})
+	bot.previewArticle(context.Background(), menuMsg, token)
+
+	pa := bot.pendingActions[token]
+	require.NotNil(t, pa.article, "extracted text kept for voicing")
+	assert.Equal(t, ts.URL+"/post", pa.url, "resolved url kept, the article ID comes from it")
+	edits := stub.texts("editMessageText")
+	require.Len(t, edits, 1)
+	assert.Contains(t, edits[0], "📰 Preview me")
+	assert.Contains(t, edits[0], "язык: en, будет перевод")
+	assert.Contains(t, edits[0], "🤔 Что сделать со ссылкой?")
+
+	// menu used before the extraction finished: nothing is touched
+	used := bot.storePendingAction(&pendingAction{kind: "article", url: ts.URL + "/post"})
+	bot.takePendingAction(used)
+	bot.previewArticle(context.Background(), menuMsg, used)
+	assert.Len(t, stub.texts("editMessageText"), 1)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -43,3 +44,101 @@ func TestExtractFallsBackToJina(t *testing.T) {
 	assert.Equal(t, "Спасённая статья", article.Title)
 	assert.Contains(t, article.TextContent, "добыт через ридер")
 }
+
+func TestArticleTextWithoutCode(t *testing.T) {
+	a := &Article{
+		Content: `<div><h2>Intro</h2><p>Run the <code>go test</code> command:</p>
+<pre><code>go test ./...
+go vet ./...</code></pre><p>Then check the output.</p><code>line one
+line two</code></div>`,
+		TextContent: "original",
+	}
+	assert.Equal(t, "Intro\n\nRun the go test command:\n\nThen check the output.", a.TextWithoutCode())
+	assert.Equal(t, "Intro\n\nRun the go test command:\n\ngo test ./...\ngo vet ./...\n\nThen check the output.\nline one\nline two",
+		a.BlockText())
+
+	jina := &Article{TextContent: "Before\n```go\nfmt.Println(1)\n```\nAfter"}
+	assert.Equal(t, "Before\nAfter", jina.TextWithoutCode())
+}
+
+func TestArticleTextWithImages(t *testing.T) {
+	a := &Article{
+		Content: `<div><p>Intro.</p><figure><img src="a.png" alt="chart"><figcaption>Latency before and after the fix</figcaption></figure>
+<p>Middle.</p><img src="b.png" alt="A cat asleep on the keyboard"><img src="c.png" alt="IMG_0042.jpg"><img src="d.png">
+<pre><code>make build</code></pre><p>End.</p></div>`,
+	}
+	assert.Equal(t, "Intro.\n\nИзображение: Latency before and after the fix.\n\nMiddle.\n\n"+
+		"Изображение: A cat asleep on the keyboard.\n\nmake build\n\nEnd.", a.TextWithImages(false))
+	assert.Equal(t, "Intro.\n\nИзображение: Latency before and after the fix.\n\nMiddle.\n\n"+
+		"Изображение: A cat asleep on the keyboard.\n\nEnd.", a.TextWithImages(true))
+	assert.Equal(t, "Intro.\n\nLatency before and after the fix\n\nMiddle.\n\nmake build\n\nEnd.", a.BlockText(), "no images by default")
+
+	jina := &Article{TextContent: "Before ![Architecture diagram](https://x.example/d.png) after ![](https://x.example/e.png)"}
+	assert.Equal(t, "Before\nИзображение: Architecture diagram.\nafter", jina.TextWithImages(false))
+}
+
+func TestArticleTextCitations(t *testing.T) {
+	a := &Article{
+		Content: `<div><p>Go was announced in 2009.<sup id="r1"><a href="#fn1">[1]</a></sup> It compiles fast<a href="#fn2">2</a>.</p>
+<p>The area is x<sup>2</sup> and see [3] too.</p>
+<ol><li id="fn1"><a href="#r1">↩</a> Pike, R. Go at Google</li><li id="fn2">^ Build times of a large codebase</li></ol></div>`,
+	}
+	assert.Equal(t, "Go was announced in 2009. It compiles fast.\n\nThe area is x2 and see too.\n\n"+
+		"↩ Pike, R. Go at Google\n\n^ Build times of a large codebase", a.Text(TextOptions{Citations: "strip"}))
+	assert.Equal(t, "Go was announced in 2009. It compiles fast.\nСноска: Pike, R. Go at Google.\n"+
+		"Сноска: Build times of a large codebase.\n\nThe area is x2 and see too.", a.Text(TextOptions{Citations: "inline"}))
+	assert.Contains(t, a.BlockText(), "2009.[1]", "markers kept by default")
+
+	jina := &Article{TextContent: "Claim [12] and another [3, 4]. Array a[i] stays."}
+	assert.Equal(t, "Claim and another. Array a[i] stays.", jina.Text(TextOptions{Citations: "strip"}))
+}
+
+func TestCleanTextSpeech(t *testing.T) {
+	tbl := []struct{ name, in, want string }{
+		{"entities", "Tom &amp; Jerry&nbsp;&mdash; &laquo;cartoon&raquo; &#8470;&nbsp;5", "Tom & Jerry — «cartoon» № 5"},
+		{"not an entity", "AT&T & co", "AT&T & co"},
+		{"quotes", "“Hello,” she said. „Gut“ and ‘it’s’", `"Hello," she said. "Gut" and 'it's'`},
+		{"spaces", "a\u00a0b\u2009c\u3000d\te\r\n f", "a b c d e\nf"},
+		{"paragraphs", "one\r\n  \n\n two\nlines\n\n\n", "one\n\ntwo\nlines"},
+		{"separators", "one\u2028two\u2029three", "one\ntwo\nthree"},
+		{"invisible", "soft\u00adhyphen zero\u200bwidth \u200fbidi \ufeffbom bell\a", "softhyphen zerowidth bidi bom bell"},
+		{"emoji dropped", "Запуск 🚀 сегодня!🔥🔥 Ура 👍🏽, 👨‍👩‍👧 семья 🇷🇺\n🎉🎉", "Запуск сегодня! Ура, семья"},
+		{"emoji between words", "Запуск🚀сегодня", "Запуск сегодня"},
+		{"keycap", "Шаг 1️⃣ готов", "Шаг 1 готов"},
+		{"symbols kept", "© 2024, 20°C, → далее, ™", "© 2024, 20°C, → далее, ™"},
+	}
+	for _, tt := range tbl {
+		assert.Equal(t, tt.want, cleanText(tt.in), tt.name)
+	}
+}
+
+func TestSpeakEmoji(t *testing.T) {
+	text := "Горячо 🔥🔥🔥! Запуск🚀сегодня ⚠️ 👍🏽 и 🦩 🇫🇷"
+	assert.Equal(t, "Горячо (огонь)! Запуск (ракета) сегодня (внимание) (класс) и  (флаг)", speakEmoji(text, "say"))
+	assert.Equal(t, text, speakEmoji(text, "keep"))
+	assert.Equal(t, "Горячо! Запуск сегодня   и  ", speakEmoji(text, "strip"))
+	assert.Equal(t, "Горячо (огонь)! Запуск (ракета) сегодня (внимание) (класс) и (флаг)", cleanTextEmoji(text, "say"))
+
+	a := &Article{Content: `<p>New release 🎉 &mdash; try it 👀</p>`}
+	assert.Equal(t, "New release — try it", a.Text(TextOptions{}))
+	assert.Equal(t, "New release (праздник) — try it (смотрите)", a.Text(TextOptions{Emoji: "say"}))
+}
+
+func TestCanonicalLink(t *testing.T) {
+	base, err := url.Parse("https://mirror.example.com/p/1")
+	require.NoError(t, err)
+	tbl := []struct {
+		name, page, want string
+	}{
+		{"absolute", `<html><head><link rel="canonical" href="https://blog.example.com/post"></head></html>`,
+			"https://blog.example.com/post"},
+		{"relative", `<head><LINK REL="Canonical" href="/post/2"/></head>`, "https://mirror.example.com/post/2"},
+		{"missing", `<head><link rel="icon" href="/f.ico"></head><body><link rel="canonical" href="/x"></body>`, ""},
+		{"not http", `<head><link rel="canonical" href="javascript:alert(1)"></head>`, ""},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, canonicalLink([]byte(tt.page), base))
+		})
+	}
+}
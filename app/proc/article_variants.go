@@ -0,0 +1,114 @@
+package proc
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	log "github.com/go-pkgz/lgr"
+)
+
+const (
+	minVariantRunes = 200 // a variant reading to less is a stub ("no print version"), not the article
+	minAMPShare     = 0.8 // an AMP version with less of the page text is a teaser
+)
+
+// extractSiteVariant reads the article from the first variant of the page the
+// rules of its domain name (ArticleExtractor.Variants) that works, ok = false
+// when there are none or none works
+func (e *ArticleExtractor) extractSiteVariant(ctx context.Context, u *url.URL) (article *Article, authorized, ok bool) {
+	for _, variant := range e.siteVariants(u) {
+		a, _, auth, err := e.extractPage(ctx, variant, u.String())
+		if err != nil {
+			log.Printf("[DEBUG] variant %s of %s failed: %v", variant, u, err)
+			continue
+		}
+		if len([]rune(a.TextContent)) < minVariantRunes {
+			log.Printf("[DEBUG] variant %s of %s is too short, skipped", variant, u)
+			continue
+		}
+		log.Printf("[INFO] read %s from its variant %s", u, variant)
+		return a, auth, true
+	}
+	return nil, false, false
+}
+
+// extractAMP reads the article from the AMP version the page links to, with
+// ArticleExtractor.AMP on. Nil when there is none, it fails or it has notably
+// less text than the page (a "read more" teaser).
+func (e *ArticleExtractor) extractAMP(ctx context.Context, page *Article, body []byte, u *url.URL) *Article {
+	if !e.AMP {
+		return nil
+	}
+	ampURL := headLink(body, u, "amphtml")
+	if ampURL == "" || ampURL == u.String() {
+		return nil
+	}
+	amp, _, _, err := e.extractPage(ctx, ampURL, u.String())
+	if err != nil {
+		log.Printf("[DEBUG] AMP version %s of %s failed: %v", ampURL, u, err)
+		return nil
+	}
+	if float64(len([]rune(amp.TextContent))) < minAMPShare*float64(len([]rune(page.TextContent))) {
+		log.Printf("[DEBUG] AMP version %s of %s is shorter than the page, skipped", ampURL, u)
+		return nil
+	}
+	if amp.Canonical == "" {
+		amp.Canonical = page.Canonical
+	}
+	if amp.Published.IsZero() {
+		amp.Published = page.Published
+	}
+	log.Printf("[INFO] read %s from its AMP version %s", u, ampURL)
+	return amp
+}
+
+// siteVariants returns the variant URLs of the page named by the rules of the
+// closest configured domain, in order
+func (e *ArticleExtractor) siteVariants(u *url.URL) []string {
+	host := strings.ToLower(u.Hostname())
+	best := ""
+	for domain := range e.Variants {
+		if domainMatch(host, domain) && len(domain) > len(best) {
+			best = domain
+		}
+	}
+	if best == "" {
+		return nil
+	}
+	var res []string
+	for _, v := range e.Variants[best] {
+		vu := variantURL(u, v)
+		if vu == "" {
+			log.Printf("[WARN] unknown article variant %q of %s, amp, print or a URL template expected", v, best)
+			continue
+		}
+		if vu != u.String() {
+			res = append(res, vu)
+		}
+	}
+	return res
+}
+
+// variantURL makes a variant of the page URL: "amp" adds /amp to the path,
+// "print" adds print=1 to the query, a template gets {url}, {scheme}, {host},
+// {path} and {query} (without "?") filled. "" for anything else.
+func variantURL(u *url.URL, variant string) string {
+	v := *u
+	v.Fragment = ""
+	switch variant {
+	case "amp":
+		v.Path, v.RawPath = strings.TrimSuffix(u.Path, "/")+"/amp", ""
+		return v.String()
+	case "print":
+		q := v.Query()
+		q.Set("print", "1")
+		v.RawQuery = q.Encode()
+		return v.String()
+	}
+	if !strings.Contains(variant, "{") {
+		return ""
+	}
+	return strings.NewReplacer("{url}", v.String(), "{scheme}", u.Scheme, "{host}", u.Host,
+		"{path}", u.EscapedPath(), "{query}", u.RawQuery).Replace(variant)
+}
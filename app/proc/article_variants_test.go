@@ -0,0 +1,124 @@
+package proc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariantURL(t *testing.T) {
+	u, err := url.Parse("https://news.example.com/2024/post/?id=5#top")
+	require.NoError(t, err)
+	tbl := []struct{ variant, want string }{
+		{"amp", "https://news.example.com/2024/post/amp?id=5"},
+		{"print", "https://news.example.com/2024/post/?id=5&print=1"},
+		{"{scheme}://{host}/print{path}?{query}", "https://news.example.com/print/2024/post/?id=5"},
+		{"https://reader.example.org/?u={url}", "https://reader.example.org/?u=https://news.example.com/2024/post/?id=5"},
+		{"mobile", ""},
+	}
+	for _, tt := range tbl {
+		assert.Equal(t, tt.want, variantURL(u, tt.variant), tt.variant)
+	}
+}
+
+func TestArticleExtractor_SiteVariants(t *testing.T) {
+	e := &ArticleExtractor{Variants: map[string][]string{
+		"example.com":      {"print"},
+		"news.example.com": {"amp", "bogus", "{url}"},
+	}}
+	u, _ := url.Parse("https://news.example.com/a")
+	assert.Equal(t, []string{"https://news.example.com/a/amp"}, e.siteVariants(u), "closest domain, the page itself and unknown ones dropped")
+	u, _ = url.Parse("https://www.example.com/a")
+	assert.Equal(t, []string{"https://www.example.com/a?print=1"}, e.siteVariants(u))
+	u, _ = url.Parse("https://other.com/a")
+	assert.Empty(t, e.siteVariants(u))
+}
+
+func TestArticleExtractor_ExtractVariants(t *testing.T) {
+	page := func(title, text string, head string) string {
+		return `<html><head><title>` + title + `</title>` + head + `</head><body><article><p>` + text + `</p></article></body></html>`
+	}
+	full := strings.Repeat("The whole text of the article is here. ", 30)
+	var mu sync.Mutex
+	var hits []string
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits = append(hits, r.URL.RequestURI())
+		mu.Unlock()
+		switch {
+		case r.URL.Query().Get("print") == "1" && r.URL.Path == "/stub":
+			_, _ = w.Write([]byte(page("Print", "No print version.", "")))
+		case r.URL.Query().Get("print") == "1":
+			_, _ = w.Write([]byte(page("Print", "Clean "+full, "")))
+		case strings.HasSuffix(r.URL.Path, "/amp") && strings.HasPrefix(r.URL.Path, "/teaser"):
+			_, _ = w.Write([]byte(page("AMP", "Read more in the app.", "")))
+		case strings.HasSuffix(r.URL.Path, "/amp"):
+			_, _ = w.Write([]byte(page("AMP", "AMP "+full, "")))
+		default:
+			_, _ = w.Write([]byte(page("Page", "Cluttered "+full,
+				`<link rel="amphtml" href="`+r.URL.Path+`/amp"><link rel="canonical" href="`+ts.URL+`/canonical">`)))
+		}
+	}))
+	defer ts.Close()
+	reset := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		res := hits
+		hits = nil
+		return res
+	}
+
+	t.Run("site variant first", func(t *testing.T) {
+		e := NewArticleExtractor()
+		e.Variants = map[string][]string{"127.0.0.1": {"print"}}
+		article, err := e.Extract(context.Background(), ts.URL+"/post")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(article.TextContent, "Clean"))
+		assert.Equal(t, ts.URL+"/post", article.URL, "the page URL, not the variant's")
+		assert.Equal(t, []string{"/post?print=1"}, reset())
+	})
+
+	t.Run("stub variant, page itself", func(t *testing.T) {
+		e := NewArticleExtractor()
+		e.Variants = map[string][]string{"127.0.0.1": {"print"}}
+		article, err := e.Extract(context.Background(), ts.URL+"/stub")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(article.TextContent, "Cluttered"))
+		assert.Equal(t, []string{"/stub?print=1", "/stub"}, reset())
+	})
+
+	t.Run("amp version", func(t *testing.T) {
+		e := NewArticleExtractor()
+		e.AMP = true
+		article, err := e.Extract(context.Background(), ts.URL+"/post")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(article.TextContent, "AMP"))
+		assert.Equal(t, ts.URL+"/canonical", article.Canonical, "canonical of the page kept")
+		assert.Equal(t, []string{"/post", "/post/amp"}, reset())
+	})
+
+	t.Run("amp teaser skipped", func(t *testing.T) {
+		e := NewArticleExtractor()
+		e.AMP = true
+		article, err := e.Extract(context.Background(), ts.URL+"/teaser")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(article.TextContent, "Cluttered"))
+		reset()
+	})
+
+	t.Run("amp off", func(t *testing.T) {
+		e := NewArticleExtractor()
+		article, err := e.Extract(context.Background(), ts.URL+"/post")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(article.TextContent, "Cluttered"))
+		assert.Equal(t, []string{"/post"}, reset())
+	})
+}
@@ -0,0 +1,76 @@
+package proc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// writeAtomic writes data to path so readers (the feed HTTP server, a crash
+// restart) see either the old file or the complete new one, never a partial
+// write. The data goes to a uniquely named temp file in the same directory,
+// which is synced and renamed over path. Creates the directory if missing.
+func writeAtomic(path string, data []byte, perm os.FileMode) error {
+	f, err := createAtomic(path)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		f.Abort()
+		return fmt.Errorf("failed to write tmp file: %w", err)
+	}
+	return f.Commit(perm)
+}
+
+// atomicFile is a file written incrementally and published with the same
+// guarantees as writeAtomic: nothing shows up at path until Commit.
+type atomicFile struct {
+	*os.File
+	path string
+}
+
+// createAtomic starts an atomic write to path, the caller must call either
+// Commit or Abort
+func createAtomic(path string) (*atomicFile, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create dir: %w", err)
+	}
+	// same dir keeps rename atomic (same fs), ".tmp" suffix keeps it out of *.mp3 globs
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tmp file: %w", err)
+	}
+	return &atomicFile{File: f, path: path}, nil
+}
+
+// Commit syncs the written data and renames the temp file over the target path
+func (f *atomicFile) Commit(perm os.FileMode) (err error) {
+	tmp := f.Name()
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to sync tmp file: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("failed to close tmp file: %w", err)
+	}
+	if err = os.Chmod(tmp, perm); err != nil {
+		return fmt.Errorf("failed to chmod tmp file: %w", err)
+	}
+	if err = os.Rename(tmp, f.path); err != nil {
+		return fmt.Errorf("failed to rename tmp file: %w", err)
+	}
+	return nil
+}
+
+// Abort closes and removes the temp file, the target path is left untouched
+func (f *atomicFile) Abort() {
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+}
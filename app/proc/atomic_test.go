@@ -0,0 +1,69 @@
+package proc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sub", "vo_abc.mp3")
+
+	require.NoError(t, writeAtomic(path, []byte("first"), 0o640))
+	data, err := os.ReadFile(path) //nolint:gosec // test temp file
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(data))
+	st, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), st.Mode().Perm())
+
+	require.NoError(t, writeAtomic(path, []byte("second"), 0o644))
+	data, err = os.ReadFile(path) //nolint:gosec // test temp file
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+
+	files, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, files, 1, "no temp files left behind")
+	assert.Equal(t, "vo_abc.mp3", files[0].Name())
+}
+
+func TestWriteAtomicFailureKeepsOld(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "vo_abc.mp3")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0o600))
+
+	// a directory in place of the target makes the final rename fail
+	target := filepath.Join(dir, "taken")
+	require.NoError(t, os.Mkdir(target, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(target, "x"), []byte("x"), 0o600))
+	require.Error(t, writeAtomic(target, []byte("new"), 0o640))
+
+	data, err := os.ReadFile(path) //nolint:gosec // test temp file
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(data))
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 2, "temp file removed on failure")
+}
+
+func TestAtomicFileAbort(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "article.mp3")
+
+	f, err := createAtomic(path)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("partial"))
+	require.NoError(t, err)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "nothing at path before commit")
+	f.Abort()
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
@@ -0,0 +1,209 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	"github.com/umputun/feed-master/app/config"
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// channelRule is the rule of a YouTube channel with its title regexp compiled
+type channelRule struct {
+	config.ChannelRule
+	strip *regexp.Regexp // nil = titles kept
+}
+
+// audioQualityRe is what yt-dlp takes as --audio-quality
+var audioQualityRe = regexp.MustCompile(`^(?:10|[0-9]|[1-9][0-9]{1,3}[kK])$`)
+
+// compileChannelRules checks the channel rules of the config and compiles their regexps
+func compileChannelRules(rules map[string]config.ChannelRule) (map[string]channelRule, error) {
+	res := make(map[string]channelRule, len(rules))
+	for id, r := range rules {
+		switch r.Action {
+		case "", "audio", "vo":
+		default:
+			return nil, fmt.Errorf("channel rule %s: unknown action %q, want audio or vo", id, r.Action)
+		}
+		if r.Quality != "" && !audioQualityRe.MatchString(r.Quality) {
+			return nil, fmt.Errorf("channel rule %s: bad quality %q, want 0-10 or a bitrate like 128K", id, r.Quality)
+		}
+		if r.MaxDubSizeMB < -1 {
+			return nil, fmt.Errorf("channel rule %s: bad max_dub_size_mb %d, want MB or -1 for no limit", id, r.MaxDubSizeMB)
+		}
+		rule := channelRule{ChannelRule: r}
+		if r.StripTitle != "" {
+			re, err := regexp.Compile(r.StripTitle)
+			if err != nil {
+				return nil, fmt.Errorf("channel rule %s: bad strip_title: %w", id, err)
+			}
+			rule.strip = re
+		}
+		res[id] = rule
+	}
+	return res, nil
+}
+
+// channelRule returns the rule of the channel, the zero one without a rule
+func (t *TelegramBot) channelRule(channelID string) channelRule {
+	return t.channelRules[channelID]
+}
+
+// dubSizeLimit is the limit of a dubbed track of the channel's video in bytes,
+// the one of its rule or the default, <= 0 = no limit
+func (t *TelegramBot) dubSizeLimit(channelID string) int64 {
+	switch mb := t.channelRule(channelID).MaxDubSizeMB; {
+	case mb > 0:
+		return int64(mb) * 1024 * 1024
+	case mb < 0:
+		return 0
+	}
+	return t.maxDubSize
+}
+
+// title strips the title by the rule, a title stripped to nothing is kept
+func (r channelRule) title(s string) string {
+	if r.strip == nil {
+		return s
+	}
+	if res := strings.TrimSpace(r.strip.ReplaceAllString(s, "")); res != "" {
+		return res
+	}
+	return s
+}
+
+// videoInfoTTL is how long fetched metadata is reused: the channel rule lookup
+// and the job it starts take one yt-dlp call
+const videoInfoTTL = 10 * time.Minute
+
+// cachedVideoInfo is the metadata of a video fetched at the time
+type cachedVideoInfo struct {
+	info ytfeed.VideoInfo
+	at   time.Time
+}
+
+// videoInfo fetches the metadata of the video, its title stripped by the rule
+// of its channel. Metadata fetched in the last videoInfoTTL is reused.
+func (t *TelegramBot) videoInfo(ctx context.Context, videoURL string) (*ytfeed.VideoInfo, error) {
+	now := time.Now()
+	t.infoMu.Lock()
+	cached, ok := t.infoCache[videoURL]
+	t.infoMu.Unlock()
+	if ok && now.Sub(cached.at) < videoInfoTTL {
+		info := cached.info
+		return &info, nil
+	}
+
+	info, err := t.Downloader.GetInfo(ctx, videoURL)
+	if err != nil {
+		return nil, err
+	}
+	info.Title = t.channelRule(info.ChannelID).title(info.Title)
+
+	t.infoMu.Lock()
+	defer t.infoMu.Unlock()
+	if t.infoCache == nil {
+		t.infoCache = map[string]cachedVideoInfo{}
+	}
+	for k, c := range t.infoCache {
+		if now.Sub(c.at) >= videoInfoTTL {
+			delete(t.infoCache, k)
+		}
+	}
+	t.infoCache[videoURL] = cachedVideoInfo{info: *info, at: now}
+	return info, nil
+}
+
+// applyChannelRules starts the rule actions of the videos of a link menu whose
+// channels have one. The menu is sent first, the lookups don't hold it; the
+// videos going by a rule leave it, a menu left with none is removed. A menu
+// answered meanwhile is left alone, the choice was made.
+func (t *TelegramBot) applyChannelRules(ctx context.Context, chat *tb.Chat, menuMsg *tb.Message, token string) {
+	t.pendingMu.Lock()
+	pa := t.pendingActions[token]
+	var videoIDs []string
+	if pa != nil {
+		videoIDs = slices.Clone(pa.videoIDs)
+	}
+	t.pendingMu.Unlock()
+
+	actions, titles := map[string]string{}, map[string]string{}
+	for _, id := range videoIDs {
+		info, err := t.videoInfo(ctx, "https://www.youtube.com/watch?v="+id)
+		if err != nil {
+			log.Printf("[WARN] no channel of %s for its rule: %v", id, err)
+			continue
+		}
+		if action := t.channelRule(info.ChannelID).Action; action != "" {
+			actions[id], titles[id] = action, info.Title
+		}
+	}
+	if len(actions) == 0 {
+		return
+	}
+
+	t.pendingMu.Lock()
+	if t.pendingActions[token] != pa {
+		t.pendingMu.Unlock()
+		return
+	}
+	rest := slices.DeleteFunc(slices.Clone(pa.videoIDs), func(id string) bool { return actions[id] != "" })
+	originalMsg := pa.originalMsg
+	if len(rest) == 0 {
+		delete(t.pendingActions, token)
+	} else {
+		pa.videoIDs = rest
+		originalMsg = nil // the menu keeps it
+	}
+	t.pendingMu.Unlock()
+
+	if len(rest) == 0 {
+		_ = t.Bot.Delete(menuMsg)
+	} else {
+		t.edit(menuMsg, t.linksPrompt(len(rest))+t.presetNote(pa.preset), t.buildActionMenu(token, "yt"))
+	}
+	for _, id := range videoIDs {
+		action := actions[id]
+		if action == "" {
+			continue
+		}
+		note := t.tr("📺 %s: аудио по правилу канала")
+		if action == "vo" {
+			note = t.tr("📺 %s: перевод по правилу канала")
+		}
+		statusMsg, err := t.Bot.Send(chat, fmt.Sprintf(note, titles[id]))
+		if err != nil {
+			log.Printf("[WARN] rule of %s not applied, can't send status: %v", id, err)
+			continue
+		}
+		t.runAction(chat, statusMsg, &pendingAction{kind: "yt", videoIDs: []string{id}, preset: pa.preset,
+			forceInflight: pa.forceInflight, originalMsg: originalMsg}, action)
+		originalMsg = nil
+	}
+}
+
+// processBackgroundVideo handles a video of a subscription or a queue, with no
+// one to ask: the action of the rule of its channel, audio without one
+func (t *TelegramBot) processBackgroundVideo(ctx context.Context, chat *tb.Chat, statusMsg *tb.Message, videoID, preset string) error {
+	videoURL := "https://www.youtube.com/watch?v=" + videoID
+	info, err := t.videoInfo(ctx, videoURL)
+	if err != nil {
+		return fmt.Errorf("failed to get video info: %w", err)
+	}
+	if t.channelRule(info.ChannelID).Action != "vo" {
+		return t.processVideo(ctx, chat, statusMsg, nil, videoID)
+	}
+	if !IsVotCliAvailable() {
+		return errors.New("vot-cli not installed")
+	}
+	return t.processVoiceover(ctx, chat, statusMsg, nil, videoURL, videoID, t.preset(preset))
+}
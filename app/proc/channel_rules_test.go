@@ -0,0 +1,142 @@
+package proc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	"github.com/umputun/feed-master/app/config"
+	"github.com/umputun/feed-master/app/tools"
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	"github.com/umputun/feed-master/app/youtube/feed/mocks"
+)
+
+func TestCompileChannelRules(t *testing.T) {
+	rules, err := compileChannelRules(map[string]config.ChannelRule{
+		"chA": {Action: "vo"},
+		"chB": {Action: "audio", Quality: "128K", StripTitle: `^\[[^]]*\]\s*`},
+		"chC": {Quality: "5", MaxDubSizeMB: -1},
+	})
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+	assert.Nil(t, rules["chA"].strip)
+	assert.NotNil(t, rules["chB"].strip)
+
+	for _, bad := range []config.ChannelRule{{Action: "notes"}, {Quality: "11"}, {Quality: "best"}, {StripTitle: "(["},
+		{MaxDubSizeMB: -2}} {
+		_, err := compileChannelRules(map[string]config.ChannelRule{"ch": bad})
+		assert.Error(t, err, "%+v", bad)
+	}
+}
+
+func TestChannelRule_title(t *testing.T) {
+	rules, err := compileChannelRules(map[string]config.ChannelRule{"ch": {StripTitle: `^\[[^]]*\]\s*|\s*\|\s*Podcast$`}})
+	require.NoError(t, err)
+	r := rules["ch"]
+	assert.Equal(t, "Talk", r.title("[Show #12] Talk | Podcast"))
+	assert.Equal(t, "[Show #12]", r.title("[Show #12]"), "stripped to nothing, kept")
+	assert.Equal(t, "Talk", channelRule{}.title("Talk"))
+}
+
+func TestTelegramBot_dubSizeLimit(t *testing.T) {
+	rules, err := compileChannelRules(map[string]config.ChannelRule{"big": {MaxDubSizeMB: 2000}, "any": {MaxDubSizeMB: -1}})
+	require.NoError(t, err)
+	bot := &TelegramBot{channelRules: rules, maxDubSize: 500 * 1024 * 1024}
+	assert.Equal(t, int64(2000*1024*1024), bot.dubSizeLimit("big"))
+	assert.Equal(t, int64(0), bot.dubSizeLimit("any"), "no limit")
+	assert.Equal(t, int64(500*1024*1024), bot.dubSizeLimit("other"), "default")
+}
+
+func TestTelegramBot_applyChannelRules(t *testing.T) {
+	t.Cleanup(func() { tools.Configure(nil) })
+	tools.Configure(map[string]tools.Tool{"vot-cli": {Path: "/nonexistent/vot-cli"}})
+
+	newBot := func() (*TelegramBot, *tgStub, *mocks.CommandRunnerMock) {
+		stub := newTgStub(t)
+		bot := newTestBot(t, stub)
+		runner := &mocks.CommandRunnerMock{
+			RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+				channelID := "chB" // no rule
+				if strings.Contains(strings.Join(args, " "), "ruled000000") {
+					channelID = "chA"
+				}
+				return []byte(`{"id":"x","title":"[Show #12] Talk","channel_id":"` + channelID + `"}`), nil, nil
+			},
+		}
+		bot.Downloader = &ytfeed.Downloader{Runner: runner}
+		rules, err := compileChannelRules(map[string]config.ChannelRule{"chA": {Action: "vo", StripTitle: `^\[[^]]*\]\s*`}})
+		require.NoError(t, err)
+		bot.channelRules = rules
+		return bot, stub, runner
+	}
+	msg := testMessage(testBotUserID, "https://youtu.be/ruled000000")
+	menuMsg := &tb.Message{ID: 7, Chat: msg.Chat}
+
+	t.Run("action of the rule", func(t *testing.T) {
+		bot, stub, _ := newBot()
+		token := bot.storePendingAction(&pendingAction{kind: "yt", videoIDs: []string{"ruled000000"}, originalMsg: msg})
+		bot.applyChannelRules(context.Background(), msg.Chat, menuMsg, token)
+		assert.Equal(t, []string{"📺 Talk: перевод по правилу канала"}, stub.texts("sendMessage"))
+		assert.Equal(t, []string{"❌ vot-cli not installed"}, stub.texts("editMessageText"), "vo started")
+		assert.Equal(t, 1, stub.count("deleteMessage"), "menu removed")
+		assert.Nil(t, bot.takePendingAction(token))
+	})
+
+	t.Run("batch, the rest stays in the menu", func(t *testing.T) {
+		bot, stub, _ := newBot()
+		pa := &pendingAction{kind: "yt", videoIDs: []string{"free0000000", "ruled000000", "free1111111"}, originalMsg: msg}
+		token := bot.storePendingAction(pa)
+		bot.applyChannelRules(context.Background(), msg.Chat, menuMsg, token)
+		assert.Equal(t, []string{"📺 Talk: перевод по правилу канала"}, stub.texts("sendMessage"))
+		assert.Equal(t, []string{"🤔 Что сделать с 2 ссылками?", "❌ vot-cli not installed"}, stub.texts("editMessageText"))
+		assert.Zero(t, stub.count("deleteMessage"))
+		assert.Equal(t, []string{"free0000000", "free1111111"}, pa.videoIDs)
+		assert.Equal(t, msg, pa.originalMsg, "kept by the menu")
+	})
+
+	t.Run("no rule, the menu", func(t *testing.T) {
+		bot, stub, _ := newBot()
+		pa := &pendingAction{kind: "yt", videoIDs: []string{"free0000000"}, originalMsg: msg}
+		token := bot.storePendingAction(pa)
+		bot.applyChannelRules(context.Background(), msg.Chat, menuMsg, token)
+		assert.Empty(t, stub.texts("sendMessage"))
+		assert.Empty(t, stub.texts("editMessageText"))
+		assert.Equal(t, pa, bot.takePendingAction(token))
+	})
+
+	t.Run("menu answered meanwhile", func(t *testing.T) {
+		bot, stub, _ := newBot()
+		token := bot.storePendingAction(&pendingAction{kind: "yt", videoIDs: []string{"ruled000000"}, originalMsg: msg})
+		require.NotNil(t, bot.takePendingAction(token))
+		bot.applyChannelRules(context.Background(), msg.Chat, menuMsg, token)
+		assert.Empty(t, stub.texts("sendMessage"))
+	})
+
+	t.Run("titles stripped, info fetched once", func(t *testing.T) {
+		bot, _, runner := newBot()
+		for range 2 {
+			info, err := bot.videoInfo(context.Background(), "https://youtu.be/ruled000000")
+			require.NoError(t, err)
+			assert.Equal(t, "Talk", info.Title)
+			info.Title = "changed by the caller"
+		}
+		assert.Len(t, runner.RunCalls(), 1)
+	})
+
+	t.Run("background video", func(t *testing.T) {
+		bot, _, _ := newBot()
+		err := bot.processBackgroundVideo(context.Background(), msg.Chat, menuMsg, "ruled000000", "")
+		assert.EqualError(t, err, "vot-cli not installed", "the rule of the channel, no menu to ask")
+	})
+}
+
+func TestTelegramBot_handleTextChannelRule(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.handleText(&tb.Message{Sender: &tb.User{ID: testBotUserID}, Chat: &tb.Chat{ID: testBotUserID}, Text: "https://youtu.be/abc12345678"})
+	assert.Equal(t, []string{"🤔 Что сделать со ссылкой?"}, stub.texts("sendMessage"), "no rules, the menu right away")
+}
@@ -0,0 +1,209 @@
+package proc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bogem/id3v2/v2"
+	log "github.com/go-pkgz/lgr"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/umputun/feed-master/app/duration"
+)
+
+// Chapter is a titled part of an episode
+type Chapter struct {
+	Title string
+	Start time.Duration
+}
+
+// minChapterGap drops the leading chapter when the first heading follows it
+// closer than that, e.g. right after the jingle
+const minChapterGap = 2 * time.Second
+
+// Headings returns the h2 and h3 headings of the readability HTML in order,
+// nil for articles without HTML
+func (a *Article) Headings() []string {
+	if a.Content == "" {
+		return nil
+	}
+	root, err := html.Parse(strings.NewReader(a.Content))
+	if err != nil {
+		return nil
+	}
+	var res []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && (n.DataAtom == atom.H2 || n.DataAtom == atom.H3) {
+			if text := strings.Join(strings.Fields(nodeText(n)), " "); text != "" {
+				res = append(res, text)
+			}
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	return res
+}
+
+// mp3Clock passes MP3 data through, adding up its playing time. Every write
+// must be whole frames, as the TTS chunks and jingles are.
+type mp3Clock struct {
+	w       io.Writer
+	elapsed time.Duration
+}
+
+func (c *mp3Clock) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	if d, derr := duration.MP3Length(bytes.NewReader(p)); derr == nil {
+		c.elapsed += d
+	}
+	return n, nil
+}
+
+// chaptersFile is the JSON chapters saved next to the audio
+func chaptersFile(audioFile string) string {
+	return strings.TrimSuffix(audioFile, filepath.Ext(audioFile)) + ".chapters.json"
+}
+
+// chaptersJSON renders chapters in the podcast namespace JSON chapters format
+func chaptersJSON(chapters []Chapter) ([]byte, error) {
+	type jsonChapter struct {
+		StartTime float64 `json:"startTime"`
+		Title     string  `json:"title"`
+	}
+	doc := struct {
+		Version  string        `json:"version"`
+		Chapters []jsonChapter `json:"chapters"`
+	}{Version: "1.2.0"}
+	for _, c := range chapters {
+		doc.Chapters = append(doc.Chapters, jsonChapter{StartTime: c.Start.Seconds(), Title: c.Title})
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// readChapters loads the chapters saved by chaptersJSON
+func readChapters(file string) ([]Chapter, error) {
+	data, err := os.ReadFile(file) //nolint:gosec // chapters file of an entry
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Chapters []struct {
+			StartTime float64 `json:"startTime"`
+			Title     string  `json:"title"`
+		} `json:"chapters"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse chapters %s: %w", file, err)
+	}
+	res := make([]Chapter, 0, len(doc.Chapters))
+	for _, c := range doc.Chapters {
+		res = append(res, Chapter{Title: c.Title, Start: time.Duration(c.StartTime * float64(time.Second))})
+	}
+	return res, nil
+}
+
+// writeID3Chapters adds CHAP frames to the MP3 tag, each chapter ending
+// where the next starts and the last at total
+func writeID3Chapters(file string, chapters []Chapter, total time.Duration) error {
+	tag, err := id3v2.Open(file, id3v2.Options{Parse: true})
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", file, err)
+	}
+	defer tag.Close()
+	tag.DeleteFrames("CHAP")
+	for i, c := range chapters {
+		end := total
+		if i+1 < len(chapters) {
+			end = chapters[i+1].Start
+		}
+		tag.AddChapterFrame(id3v2.ChapterFrame{
+			ElementID:   fmt.Sprintf("chp%d", i),
+			StartTime:   c.Start,
+			EndTime:     end,
+			StartOffset: id3v2.IgnoredOffset,
+			EndOffset:   id3v2.IgnoredOffset,
+			Title:       &id3v2.TextFrame{Encoding: id3v2.EncodingUTF8, Text: c.Title},
+		})
+	}
+	if err := tag.Save(); err != nil {
+		return fmt.Errorf("failed to save chapters of %s: %w", file, err)
+	}
+	return nil
+}
+
+// articleChapters makes the chapter list of a voiced article: the opening
+// (intro and text before the first heading) under the article title, then a
+// chapter per heading reached. nil for fewer than two chapters.
+func articleChapters(title string, headings []string, starts map[int]time.Duration) []Chapter {
+	chapters := []Chapter{{Title: title, Start: 0}}
+	for i, h := range headings {
+		start, ok := starts[i]
+		if !ok {
+			continue
+		}
+		if len(chapters) == 1 && start < minChapterGap {
+			chapters = chapters[:0]
+		}
+		chapters = append(chapters, Chapter{Title: h, Start: start})
+	}
+	if len(chapters) < 2 {
+		return nil
+	}
+	return chapters
+}
+
+// saveChapters embeds the chapters into the MP3 and writes the JSON copy for
+// the feed. Titles of a translated article are translated too. Best-effort:
+// returns the JSON file, "" on failure.
+func (t *TelegramBot) saveChapters(ctx context.Context, audioFile string, chapters []Chapter, total time.Duration,
+	translator TranslationProvider) string {
+	if len(chapters) == 0 {
+		return ""
+	}
+	if translator != nil {
+		titles := make([]string, len(chapters))
+		for i, c := range chapters {
+			titles[i] = c.Title
+		}
+		if translated, err := translator.Translate(ctx, strings.Join(titles, "\n")); err == nil {
+			if lines := strings.Split(strings.TrimSpace(translated), "\n"); len(lines) == len(chapters) {
+				for i := range chapters {
+					chapters[i].Title = strings.TrimSpace(lines[i])
+				}
+			}
+		} else {
+			log.Printf("[WARN] chapter titles of %s left untranslated: %v", filepath.Base(audioFile), err)
+		}
+	}
+
+	if err := writeID3Chapters(audioFile, chapters, total); err != nil {
+		log.Printf("[WARN] no ID3 chapters in %s: %v", filepath.Base(audioFile), err)
+	}
+	data, err := chaptersJSON(chapters)
+	if err != nil {
+		log.Printf("[WARN] failed to render chapters of %s: %v", filepath.Base(audioFile), err)
+		return ""
+	}
+	file := chaptersFile(audioFile)
+	if err := writeAtomic(file, data, 0o644); err != nil {
+		log.Printf("[WARN] failed to save chapters %s: %v", file, err)
+		return ""
+	}
+	log.Printf("[INFO] %d chapters in %s", len(chapters), filepath.Base(audioFile))
+	return file
+}
@@ -0,0 +1,109 @@
+package proc
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bogem/id3v2/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+)
+
+func TestArticle_Headings(t *testing.T) {
+	a := &Article{Content: `<div><h1>Заголовок</h1><p>текст</p><h2>Первая <em>часть</em></h2><p>а</p>` +
+		`<h3> Детали </h3><h2></h2><h4>мелочь</h4></div>`}
+	assert.Equal(t, []string{"Первая часть", "Детали"}, a.Headings())
+	assert.Nil(t, (&Article{TextContent: "без html"}).Headings())
+}
+
+func TestSplitAtMarks(t *testing.T) {
+	sections, marks := splitAtMarks("Вступление.\nЧасть 1\nтекст\nЧасть 2\nещё", []string{"Часть 1", "нет такой", "Часть 2"})
+	assert.Equal(t, []string{"Вступление.\n", "Часть 1\nтекст\n", "Часть 2\nещё"}, sections)
+	assert.Equal(t, []int{-1, 0, 2}, marks)
+
+	sections, marks = splitAtMarks("Часть 1\nтекст", []string{"Часть 1"})
+	assert.Equal(t, []string{"Часть 1\nтекст"}, sections, "no empty leading section")
+	assert.Equal(t, []int{0}, marks)
+
+	sections, marks = splitAtMarks("текст", nil)
+	assert.Equal(t, []string{"текст"}, sections)
+	assert.Equal(t, []int{-1}, marks)
+}
+
+func TestArticleChapters(t *testing.T) {
+	chapters := articleChapters("Статья", []string{"А", "Б", "В"}, map[int]time.Duration{0: time.Second, 2: time.Minute})
+	assert.Equal(t, []Chapter{{Title: "А", Start: time.Second}, {Title: "В", Start: time.Minute}}, chapters,
+		"opening right before the first heading dropped, unreached heading skipped")
+
+	chapters = articleChapters("Статья", []string{"А"}, map[int]time.Duration{0: 30 * time.Second})
+	assert.Equal(t, []Chapter{{Title: "Статья"}, {Title: "А", Start: 30 * time.Second}}, chapters)
+
+	assert.Nil(t, articleChapters("Статья", []string{"А"}, map[int]time.Duration{}))
+}
+
+func TestWriteID3Chapters(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ep.mp3")
+	require.NoError(t, os.WriteFile(file, silentMP3Frame, 0o600))
+	chapters := []Chapter{{Title: "Начало"}, {Title: "Вторая часть", Start: 90 * time.Second}}
+	require.NoError(t, writeID3Chapters(file, chapters, 3*time.Minute))
+
+	tag, err := id3v2.Open(file, id3v2.Options{Parse: true})
+	require.NoError(t, err)
+	defer tag.Close()
+	frames := tag.GetFrames("CHAP")
+	require.Len(t, frames, 2)
+	second, ok := frames[1].(id3v2.ChapterFrame)
+	require.True(t, ok)
+	assert.Equal(t, "Вторая часть", second.Title.Text)
+	assert.Equal(t, 90*time.Second, second.StartTime)
+	assert.Equal(t, 3*time.Minute, second.EndTime)
+}
+
+func TestTelegramBot_ProcessArticleChapters(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	bot.Store = newTestJobStore(t)
+	bot.MaxItems = 10
+	bot.TTS = &FakeTTS{FramePerChars: 1}
+
+	intro := strings.Repeat("Вступление статьи. ", 20)
+	req := articleRequest{URL: "https://example.com/a", Article: &Article{Title: "Статья",
+		Content:     "<p>" + intro + "</p><h2>Первая часть</h2><p>Текст первой части.</p><h2>Вторая часть</h2><p>Конец.</p>",
+		TextContent: intro + "\nПервая часть\nТекст первой части.\nВторая часть\nКонец."}}
+	statusMsg := &tb.Message{ID: 5, Chat: &tb.Chat{ID: testBotUserID}}
+	require.NoError(t, bot.processArticle(context.Background(), nil, statusMsg, nil, req))
+
+	entries, err := bot.Store.Load(bot.FeedName, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, chaptersFile(entries[0].File), entries[0].Chapters)
+	data, err := os.ReadFile(entries[0].Chapters)
+	require.NoError(t, err)
+	var doc struct {
+		Chapters []struct {
+			StartTime float64 `json:"startTime"`
+			Title     string  `json:"title"`
+		} `json:"chapters"`
+	}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	require.Len(t, doc.Chapters, 3)
+	assert.Equal(t, "Статья", doc.Chapters[0].Title)
+	assert.Equal(t, "Первая часть", doc.Chapters[1].Title)
+	assert.Equal(t, "Вторая часть", doc.Chapters[2].Title)
+	assert.Greater(t, doc.Chapters[1].StartTime, 2.0, "after the intro text")
+	assert.Greater(t, doc.Chapters[2].StartTime, doc.Chapters[1].StartTime)
+
+	tag, err := id3v2.Open(entries[0].File, id3v2.Options{Parse: true})
+	require.NoError(t, err)
+	assert.Len(t, tag.GetFrames("CHAP"), 3)
+	require.NoError(t, tag.Close())
+
+	require.NoError(t, bot.deleteEntry(entries[0]))
+	_, err = os.Stat(entries[0].Chapters)
+	assert.True(t, os.IsNotExist(err), "chapters deleted with the episode")
+}
@@ -0,0 +1,72 @@
+package proc
+
+import (
+	"regexp"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// metaCharsetRe finds the charset of <meta charset> and <meta http-equiv> tags
+var metaCharsetRe = regexp.MustCompile(`(?i)(<meta[^>]*?charset\s*=\s*["']?)([\w.:-]+)`)
+
+// toUTF8 transcodes a page to UTF-8 by its charset: the BOM, the Content-Type
+// header or the <meta> of the page (unless the page is valid UTF-8 anyway).
+// Undeclared (or falsely declared UTF-8) pages are guessed, Cyrillic ones as windows-1251 or KOI8-R. The <meta>
+// charset is rewritten to utf-8, so a saved copy displays right. Returns the
+// charset the page was read in.
+func toUTF8(body []byte, contentType string) ([]byte, string) {
+	enc, name, certain := charset.DetermineEncoding(body, contentType)
+	declared := certain || metaCharsetRe.Match(body[:min(len(body), 1024)])
+	switch {
+	case name == "utf-8" && utf8.Valid(body):
+		return body, name
+	case !certain && utf8.Valid(body):
+		return body, "utf-8" // a stale <meta> of a page converted to UTF-8, a real single byte page is hardly valid UTF-8
+	case name == "utf-8" || !declared:
+		enc, name = guessCharset(body)
+	}
+	if name == "utf-8" {
+		return body, name
+	}
+	res, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return body, "utf-8" // single byte decoders don't fail, keep the page as is anyway
+	}
+	return metaCharsetRe.ReplaceAll(res, []byte("${1}utf-8")), name
+}
+
+// guessCharset picks the single byte charset of an undeclared page. Cyrillic
+// words are runs of high bytes, unlike the odd accented letter of Western
+// text; windows-1251 has the lower case letters (the most of text) in
+// 0xE0-0xFF, KOI8-R in 0xC0-0xDF. Windows-1252 when not Cyrillic.
+func guessCharset(body []byte) (encoding.Encoding, string) {
+	if utf8.Valid(body) {
+		return encoding.Nop, "utf-8"
+	}
+	var high, paired, upperHalf, lowerHalf int
+	for i, b := range body {
+		if b < 0x80 {
+			continue
+		}
+		high++
+		if i+1 < len(body) && body[i+1] >= 0x80 {
+			paired++
+		}
+		switch {
+		case b >= 0xE0:
+			upperHalf++
+		case b >= 0xC0:
+			lowerHalf++
+		}
+	}
+	if high == 0 || float64(paired)/float64(high) < 0.5 {
+		return charmap.Windows1252, "windows-1252"
+	}
+	if lowerHalf > upperHalf {
+		return charmap.KOI8R, "koi8-r"
+	}
+	return charmap.Windows1251, "windows-1251"
+}
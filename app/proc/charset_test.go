@@ -0,0 +1,73 @@
+package proc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestToUTF8(t *testing.T) {
+	const text = "Съешь же ещё этих мягких французских булок, да выпей чаю."
+	encode := func(enc *charmap.Charmap, s string) []byte {
+		b, err := enc.NewEncoder().Bytes([]byte(s))
+		require.NoError(t, err)
+		return b
+	}
+	page := func(head string) string {
+		return "<html><head>" + head + "<title>t</title></head><body><p>" + text + "</p></body></html>"
+	}
+
+	tbl := []struct {
+		name        string
+		body        []byte
+		contentType string
+		charset     string
+	}{
+		{"utf-8", []byte(page("")), "text/html", "utf-8"},
+		{"header", encode(charmap.Windows1251, page("")), "text/html; charset=windows-1251", "windows-1251"},
+		{"meta", encode(charmap.Windows1251, page(`<meta charset="windows-1251">`)), "text/html", "windows-1251"},
+		{"http-equiv koi8-r", encode(charmap.KOI8R, page(`<meta http-equiv="Content-Type" content="text/html; charset=koi8-r">`)),
+			"", "koi8-r"},
+		{"undeclared windows-1251", encode(charmap.Windows1251, page("")), "text/html", "windows-1251"},
+		{"undeclared koi8-r", encode(charmap.KOI8R, page("")), "", "koi8-r"},
+		{"false utf-8 header", encode(charmap.Windows1251, page("")), "text/html; charset=utf-8", "windows-1251"},
+		{"stale meta of a utf-8 page", []byte(page(`<meta charset="windows-1251">`)), "text/html", "utf-8"},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			got, cs := toUTF8(tt.body, tt.contentType)
+			assert.Equal(t, tt.charset, cs)
+			assert.Contains(t, string(got), text)
+			if cs != "utf-8" {
+				assert.NotContains(t, strings.ToLower(string(got)), "charset="+cs, "meta rewritten")
+			}
+		})
+	}
+
+	western, cs := toUTF8(encode(charmap.Windows1252, "<html><body><p>Un café crème à la française</p></body></html>"), "")
+	assert.Equal(t, "windows-1252", cs)
+	assert.Contains(t, string(western), "Un café crème à la française")
+}
+
+func TestArticleExtractor_ExtractWindows1251(t *testing.T) {
+	text := strings.Repeat("Это текст статьи в старой кодировке сайта. ", 20)
+	body, err := charmap.Windows1251.NewEncoder().String(`<html><head><meta charset="windows-1251"><title>Заголовок</title></head>` +
+		`<body><article><p>` + text + `</p></article></body></html>`)
+	require.NoError(t, err)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	article, err := NewArticleExtractor().Extract(context.Background(), ts.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "Заголовок", article.Title)
+	assert.Contains(t, article.TextContent, "Это текст статьи")
+}
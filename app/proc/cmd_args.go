@@ -0,0 +1,81 @@
+package proc
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	tb "gopkg.in/tucnak/telebot.v2"
+)
+
+// cmdArgs are the arguments of a bot command: positional words and flags
+// (/vo <url> --lang=en --method=subs)
+type cmdArgs struct {
+	pos   []string
+	flags map[string]string
+}
+
+// commandArgs parses the words after the command of the message, "/vo@bot"
+// included. "--name=value" and a bare "--name" (value "") are flags, the
+// other words are positional; "--" ends the flags.
+func commandArgs(m *tb.Message) cmdArgs {
+	words := strings.Fields(m.Text)
+	if len(words) > 0 && strings.HasPrefix(words[0], "/") {
+		words = words[1:]
+	}
+	return parseArgs(words)
+}
+
+// parseArgs splits the words into positional arguments and flags
+func parseArgs(words []string) cmdArgs {
+	res := cmdArgs{flags: map[string]string{}}
+	for i, w := range words {
+		if w == "--" {
+			res.pos = append(res.pos, words[i+1:]...)
+			break
+		}
+		name, ok := strings.CutPrefix(w, "--")
+		if !ok || name == "" {
+			res.pos = append(res.pos, w)
+			continue
+		}
+		name, value, _ := strings.Cut(name, "=")
+		res.flags[strings.ToLower(name)] = value
+	}
+	return res
+}
+
+// len returns the number of positional arguments
+func (a cmdArgs) len() int { return len(a.pos) }
+
+// arg returns the i-th positional argument, "" when there are fewer
+func (a cmdArgs) arg(i int) string {
+	if i < 0 || i >= len(a.pos) {
+		return ""
+	}
+	return a.pos[i]
+}
+
+// from returns the positional arguments starting with the i-th, joined by spaces
+func (a cmdArgs) from(i int) string {
+	if i >= len(a.pos) {
+		return ""
+	}
+	return strings.Join(a.pos[i:], " ")
+}
+
+// flag returns the value of the flag and whether it is set
+func (a cmdArgs) flag(name string) (string, bool) {
+	v, ok := a.flags[name]
+	return v, ok
+}
+
+// unknownFlag returns the first flag not in known as "--name", "" when all are known
+func (a cmdArgs) unknownFlag(known ...string) string {
+	for _, name := range slices.Sorted(maps.Keys(a.flags)) {
+		if !slices.Contains(known, name) {
+			return "--" + name
+		}
+	}
+	return ""
+}
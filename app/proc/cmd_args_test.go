@@ -0,0 +1,34 @@
+package proc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandArgs(t *testing.T) {
+	args := commandArgs(testMessage(testBotUserID, "/vo@turnip_bot tech  https://youtu.be/abc --lang=EN --Method=vot,subs --force"))
+	assert.Equal(t, 2, args.len())
+	assert.Equal(t, "tech", args.arg(0))
+	assert.Equal(t, "", args.arg(5))
+	assert.Equal(t, "tech https://youtu.be/abc", args.from(0))
+	assert.Equal(t, "", args.from(2))
+	lang, ok := args.flag("lang")
+	assert.True(t, ok)
+	assert.Equal(t, "EN", lang)
+	methods, _ := args.flag("method")
+	assert.Equal(t, "vot,subs", methods)
+	force, ok := args.flag("force")
+	assert.True(t, ok)
+	assert.Equal(t, "", force)
+	_, ok = args.flag("preset")
+	assert.False(t, ok)
+	assert.Equal(t, "", args.unknownFlag("lang", "method", "force"))
+	assert.Equal(t, "--lang", args.unknownFlag("method", "force"))
+
+	args = commandArgs(testMessage(testBotUserID, "/revoice 1 ru -15% -- --not-a-flag"))
+	assert.Equal(t, []string{"1", "ru", "-15%", "--not-a-flag"}, args.pos)
+	assert.Empty(t, args.flags)
+
+	assert.Equal(t, 0, commandArgs(testMessage(testBotUserID, "/list")).len())
+}
@@ -0,0 +1,70 @@
+package proc
+
+import (
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+)
+
+// botCommand is a command of the bot with its handler and its line in the
+// Telegram command menu
+type botCommand struct {
+	name   string
+	desc   string // written in Russian, the catalog has the English one
+	handle func(m *tb.Message)
+	hidden bool // handled but left out of the menu
+}
+
+// botCommands returns the commands of the bot in the menu order. The menu
+// leaves out the commands of services not configured.
+func (t *TelegramBot) botCommands() []botCommand {
+	noNotes, noPub := t.NotesSvc == nil, t.Pub == nil
+	return []botCommand{
+		{name: "list", desc: "Что сейчас в ленте", handle: t.handleList},
+		{name: "vo", desc: "Озвучка видео на русском", handle: t.handleVoiceover},
+		{name: "info", desc: "Как сделан эпизод N", handle: t.handleInfo},
+		{name: "del", desc: "Удалить из ленты", handle: t.handleDelete},
+		{name: "delall", desc: "Очистить ленту", handle: t.handleDeleteAll},
+		{name: "undo", desc: "Вернуть последнее удалённое", handle: t.handleUndo},
+		{name: "schedule", desc: "Показать эпизоды в ленте позже", handle: t.handleSchedule},
+		{name: "remix", desc: "Пересвести озвучку с другой громкостью оригинала", handle: t.handleRemix},
+		{name: "revoice", desc: "Переозвучить статью другим голосом", handle: t.handleRevoice},
+		{name: "compare", desc: "Озвучить видео двумя способами", handle: t.handleCompare},
+		{name: "md", desc: "Транскрипт в MD-файл", handle: t.handleMD, hidden: noNotes},
+		{name: "notes", desc: "Конспект в Notion", handle: t.handleNotes, hidden: noNotes},
+		{name: "digest", desc: "Сводный конспект по тегу", handle: t.handleDigest, hidden: noNotes},
+		{name: "read", desc: "Статья в читалку", handle: t.handleRead},
+		{name: "rsssub", desc: "Подписки на RSS", handle: t.handleRSSSub},
+		{name: "rssunsub", desc: "Отписаться от RSS", handle: t.handleRSSUnsub},
+		{name: "glossary", desc: "Глоссарий перевода", handle: t.handleGlossary},
+		{name: "feeds", desc: "Ленты платформы", handle: t.handleFeeds, hidden: noPub},
+		{name: "archive", desc: "Эпизоды категории платформы", handle: t.handleArchive, hidden: noPub},
+		{name: "status", desc: "Очередь задач, R2, лимиты LLM", handle: t.handleStatus},
+		{name: "history", desc: "Лог всех отправлений", handle: t.handleHistory},
+		{name: "stats", desc: "Статистика по неделям", handle: t.handleStats},
+		{name: "verify", desc: "Проверить файлы ленты", handle: t.handleVerify},
+		{name: "debug", desc: "Лог задачи", handle: t.handleDebug},
+		{name: "lang", desc: "Язык сообщений бота", handle: t.handleLang},
+		{name: "help", desc: "Справка", handle: t.handleHelp},
+		{name: "start", handle: t.handleStart, hidden: true},
+	}
+}
+
+// setCommandMenu registers the menu of the commands with Telegram: the
+// Russian one by default, the English one for English Telegram apps
+func (t *TelegramBot) setCommandMenu() {
+	for _, lang := range []string{"", langEN} {
+		var cmds []tb.Command
+		for _, c := range t.botCommands() {
+			if !c.hidden {
+				cmds = append(cmds, tb.Command{Text: c.name, Description: translateMsg(lang, c.desc)})
+			}
+		}
+		params := map[string]any{"commands": cmds}
+		if lang != "" {
+			params["language_code"] = lang
+		}
+		if _, err := t.Bot.Raw("setMyCommands", params); err != nil {
+			log.Printf("[WARN] failed to set the %q command menu: %v", lang, err)
+		}
+	}
+}
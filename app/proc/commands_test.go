@@ -0,0 +1,43 @@
+package proc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelegramBot_SetCommandMenu(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.setCommandMenu()
+
+	var menus []tgCall
+	stub.mu.Lock()
+	for _, c := range stub.calls {
+		if c.Method == "setMyCommands" {
+			menus = append(menus, c)
+		}
+	}
+	stub.mu.Unlock()
+	require.Len(t, menus, 2)
+	assert.NotContains(t, menus[0].Params, "language_code")
+	assert.Equal(t, "en", menus[1].Params["language_code"])
+
+	descs := func(c tgCall) map[string]string {
+		res := map[string]string{}
+		for _, cmd := range c.Params["commands"].([]any) {
+			cmd := cmd.(map[string]any)
+			res[cmd["command"].(string)] = cmd["description"].(string)
+		}
+		return res
+	}
+	ru, en := descs(menus[0]), descs(menus[1])
+	assert.Equal(t, "Озвучка видео на русском", ru["vo"])
+	assert.Equal(t, "Russian voiceover of a video", en["vo"])
+	assert.Equal(t, "Help", en["help"])
+	assert.NotContains(t, ru, "start", "handled, not in the menu")
+	assert.NotContains(t, ru, "md", "notes are not configured")
+	assert.NotContains(t, ru, "feeds", "no publishing platform")
+	assert.Len(t, en, len(ru))
+}
@@ -0,0 +1,313 @@
+package proc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"image"
+	_ "image/gif"  // decoders of checkCoverImage
+	_ "image/jpeg" // decoders of checkCoverImage
+	_ "image/png"  // decoders of checkCoverImage
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+)
+
+const (
+	coverSize      = 1400 // px, square, the minimum podcast directories accept
+	coverLineRunes = 22   // title runes per line at coverFontSize
+	coverMaxLines  = 7
+	coverFontSize  = 88
+	coverIconSize  = 180
+	maxFaviconSize = 512 * 1024
+	maxImageSize   = 10 * 1024 * 1024
+	minImageSide   = 300 // px, smaller images are icons, not covers
+)
+
+// coverPalette are the backgrounds picked by the feed name without cover_color
+var coverPalette = []string{"#2b5876", "#4e4376", "#1f6f50", "#8e3b46", "#355c7d", "#6c4f3d", "#2d4059", "#5f4b8b"}
+
+var coverColorRe = regexp.MustCompile(`^#?[0-9a-fA-F]{6}$`)
+
+// imageFile is the local copy path of the article image of an episode audio file: ep.mp3 → ep.cover.jpg
+func imageFile(audioFile string) string {
+	return strings.TrimSuffix(audioFile, filepath.Ext(audioFile)) + ".cover.jpg"
+}
+
+// coverFile is the generated cover path of an episode audio file: ep.mp3 → ep.cover.png
+func coverFile(audioFile string) string {
+	return strings.TrimSuffix(audioFile, filepath.Ext(audioFile)) + ".cover.png"
+}
+
+// Cover draws a square PNG cover at dst: the title in white on the bg color
+// (#rrggbb) with the icon image above it, if any. Font is a font file, "" =
+// the default sans font of fontconfig.
+func (f *AudioFinalizer) Cover(ctx context.Context, dst, title, icon, bg, font string) error {
+	if !coverColorRe.MatchString(bg) {
+		return fmt.Errorf("bad cover color %q, #rrggbb expected", bg)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	// the title goes through a file, no escaping of quotes and colons of titles in the filter
+	textFile := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".title.tmp")
+	if err := os.WriteFile(textFile, []byte(wrapCoverTitle(title)), 0o600); err != nil {
+		return fmt.Errorf("write cover title: %w", err)
+	}
+	defer os.Remove(textFile) //nolint:errcheck // temp file
+	tmp := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".tmp.png")
+	defer os.Remove(tmp) //nolint:errcheck // gone after a successful rename
+
+	text := fmt.Sprintf("drawtext=textfile=%s:fontcolor=white:fontsize=%d:line_spacing=%d:x=(w-text_w)/2",
+		filterValue(textFile), coverFontSize, coverFontSize/4)
+	if font != "" {
+		text += ":fontfile=" + filterValue(font)
+	} else {
+		text += ":font=Sans"
+	}
+	args := []string{"-nostdin", "-y", "-v", "error",
+		"-f", "lavfi", "-i", fmt.Sprintf("color=c=0x%s:s=%dx%d:d=1", strings.TrimPrefix(bg, "#"), coverSize, coverSize)}
+	var graph string
+	if icon != "" {
+		args = append(args, "-i", icon)
+		graph = fmt.Sprintf("[1:v]scale=%d:%d[icon];[0:v][icon]overlay=(W-w)/2:%d,%s:y=(h-text_h)/2+%d[out]",
+			coverIconSize, coverIconSize, coverSize/8, text, coverIconSize/2)
+	} else {
+		graph = fmt.Sprintf("[0:v]%s:y=(h-text_h)/2[out]", text)
+	}
+	args = append(args, "-filter_complex", graph, "-map", "[out]", "-frames:v", "1", "-f", "image2", "-c:v", "png", tmp)
+
+	_, stderr, err := f.runner().Run(ctx, "ffmpeg", args...)
+	if err != nil {
+		return fmt.Errorf("ffmpeg cover failed: %w, stderr: %s", err, lastLines(string(stderr), 5))
+	}
+	if err := os.Chmod(tmp, 0o644); err != nil {
+		return fmt.Errorf("chmod cover: %w", err)
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return fmt.Errorf("rename cover: %w", err)
+	}
+	return nil
+}
+
+// SquareJPEG converts the image at src to a square coverSize JPEG at dst,
+// cropping the center of a non-square one
+func (f *AudioFinalizer) SquareJPEG(ctx context.Context, src, dst string) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	tmp := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".tmp.jpg")
+	defer os.Remove(tmp) //nolint:errcheck // gone after a successful rename
+
+	_, stderr, err := f.runner().Run(ctx, "ffmpeg", "-nostdin", "-y", "-v", "error", "-i", src,
+		"-vf", fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d", coverSize, coverSize, coverSize, coverSize),
+		"-frames:v", "1", "-q:v", "3", "-f", "image2", "-c:v", "mjpeg", tmp)
+	if err != nil {
+		return fmt.Errorf("ffmpeg image convert failed: %w, stderr: %s", err, lastLines(string(stderr), 5))
+	}
+	if err := os.Chmod(tmp, 0o644); err != nil {
+		return fmt.Errorf("chmod image: %w", err)
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return fmt.Errorf("rename image: %w", err)
+	}
+	return nil
+}
+
+// filterValue quotes a path for an ffmpeg filter option, a quote inside is
+// closed, escaped and reopened
+func filterValue(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// wrapCoverTitle breaks the title into lines fitting the cover by words,
+// the rest of a too long title is cut with an ellipsis
+func wrapCoverTitle(title string) string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(title) {
+		if r := []rune(word); len(r) > coverLineRunes {
+			word = string(r[:coverLineRunes-1]) + "…"
+		}
+		switch {
+		case line == "":
+			line = word
+		case len([]rune(line))+1+len([]rune(word)) <= coverLineRunes:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	if len(lines) > coverMaxLines {
+		lines = lines[:coverMaxLines]
+		last := []rune(lines[coverMaxLines-1])
+		lines[coverMaxLines-1] = string(last[:min(len(last), coverLineRunes-1)]) + "…"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// coverColor is the configured cover background or one of the palette picked
+// by the feed name, so covers of a feed look alike
+func (t *TelegramBot) coverColor() string {
+	if t.CoverColor != "" {
+		return t.CoverColor
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(t.FeedName))
+	return coverPalette[h.Sum32()%uint32(len(coverPalette))] //nolint:gosec // palette index
+}
+
+// articleCover returns the thumbnail URL of a voiced article: its own image
+// hosted locally when it passes the checks, a generated cover otherwise.
+// Without ffmpeg the article image is used as is.
+func (t *TelegramBot) articleCover(ctx context.Context, article *Article, articleURL, audioFile string) string {
+	if t.Finalizer == nil {
+		return article.Image
+	}
+	if article.Image != "" {
+		local, err := t.localizeImage(ctx, article.Image, audioFile)
+		if err == nil {
+			return local
+		}
+		log.Printf("[INFO] image of %s not used, %s: %v", articleURL, article.Image, err)
+	}
+	return t.makeCover(ctx, article, articleURL, audioFile)
+}
+
+// localizeImage downloads the image, checks it's a raster picture big enough
+// for a cover (podcast apps reject SVG and show icons blurred) and saves it as
+// a square JPEG next to the audio file. Returns the URL of the copy.
+func (t *TelegramBot) localizeImage(ctx context.Context, imageURL, audioFile string) (string, error) {
+	data, err := t.fetchImage(ctx, imageURL, maxImageSize)
+	if err != nil {
+		return "", err
+	}
+	if err := checkCoverImage(data); err != nil {
+		return "", err
+	}
+	dst := imageFile(audioFile)
+	src := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".src.tmp")
+	if err := os.WriteFile(src, data, 0o600); err != nil {
+		return "", fmt.Errorf("save image: %w", err)
+	}
+	defer os.Remove(src) //nolint:errcheck // temp file
+	if err := t.Finalizer.SquareJPEG(ctx, src, dst); err != nil {
+		return "", err
+	}
+	return t.BaseURL + "/yt/media/" + filepath.Base(dst), nil
+}
+
+// checkCoverImage rejects what isn't a raster image and images smaller than
+// minImageSide. Formats the standard library can't measure (webp) pass to
+// ffmpeg unmeasured.
+func checkCoverImage(data []byte) error {
+	ctype := http.DetectContentType(data)
+	if !strings.HasPrefix(ctype, "image/") {
+		return fmt.Errorf("not a raster image: %s", ctype)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		if ctype == "image/webp" {
+			return nil
+		}
+		return fmt.Errorf("undecodable %s: %w", ctype, err)
+	}
+	if cfg.Width < minImageSide || cfg.Height < minImageSide {
+		return fmt.Errorf("%s too small, %dx%d", format, cfg.Width, cfg.Height)
+	}
+	return nil
+}
+
+// makeCover generates the cover of an article without an image next to its
+// audio file and returns its URL. Best-effort: without ffmpeg or on failure
+// the episode goes without a cover, "" returned.
+func (t *TelegramBot) makeCover(ctx context.Context, article *Article, articleURL, audioFile string) string {
+	if t.Finalizer == nil || audioFile == "" {
+		return ""
+	}
+	title := article.Title
+	if title == "" {
+		title = article.SiteName
+	}
+	dst := coverFile(audioFile)
+	icon := t.fetchFavicon(ctx, articleURL, dst)
+	if icon != "" {
+		defer os.Remove(icon) //nolint:errcheck // temp file
+	}
+	err := t.Finalizer.Cover(ctx, dst, title, icon, t.coverColor(), t.CoverFont)
+	if err != nil && icon != "" {
+		// a favicon ffmpeg can't read (svg, broken ico) shouldn't cost the cover
+		log.Printf("[DEBUG] cover of %s with favicon failed, trying without: %v", articleURL, err)
+		err = t.Finalizer.Cover(ctx, dst, title, "", t.coverColor(), t.CoverFont)
+	}
+	if err != nil {
+		log.Printf("[WARN] no cover for %s: %v", articleURL, err)
+		return ""
+	}
+	return t.BaseURL + "/yt/media/" + filepath.Base(dst)
+}
+
+// fetchFavicon downloads /favicon.ico of the article site next to dst,
+// returns the temp file path or "" when the site has none
+func (t *TelegramBot) fetchFavicon(ctx context.Context, articleURL, dst string) string {
+	u, err := url.Parse(articleURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	data, err := t.fetchImage(ctx, u.Scheme+"://"+u.Host+"/favicon.ico", maxFaviconSize)
+	if err != nil {
+		log.Printf("[DEBUG] no favicon of %s: %v", u.Host, err)
+		return ""
+	}
+	icon := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".favicon.tmp")
+	if err := os.WriteFile(icon, data, 0o600); err != nil {
+		log.Printf("[WARN] can't save favicon of %s: %v", u.Host, err)
+		return ""
+	}
+	return icon
+}
+
+// fetchImage downloads an image up to maxSize bytes. Text responses fail, a
+// missing image is often an html 404 page served with 200.
+func (t *TelegramBot) fetchImage(ctx context.Context, imageURL string, maxSize int64) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	client := http.DefaultClient
+	if t.ArticleExtractor != nil && t.ArticleExtractor.HTTPClient != nil {
+		client = t.ArticleExtractor.HTTPClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck // read-only body
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %s", resp.Status)
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/") {
+		return nil, fmt.Errorf("not an image: %s", resp.Header.Get("Content-Type"))
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("size %d out of the 1-%d range", len(data), maxSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,254 @@
+package proc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/feed-master/app/youtube/feed/mocks"
+)
+
+func TestCoverFile(t *testing.T) {
+	assert.Equal(t, "/srv/yt/ep.cover.png", coverFile("/srv/yt/ep.mp3"))
+}
+
+func TestWrapCoverTitle(t *testing.T) {
+	assert.Equal(t, "Короткий заголовок", wrapCoverTitle("Короткий  заголовок"))
+	assert.Equal(t, "Как устроены очереди\nзадач в распределённых\nсистемах",
+		wrapCoverTitle("Как устроены очереди задач в распределённых системах"))
+	assert.Equal(t, "Supercalifragilistice…", wrapCoverTitle("Supercalifragilisticexpialidocious"))
+
+	long := wrapCoverTitle(strings.Repeat("слово ", 60))
+	lines := strings.Split(long, "\n")
+	assert.Len(t, lines, coverMaxLines)
+	assert.True(t, strings.HasSuffix(lines[coverMaxLines-1], "…"))
+	assert.Empty(t, wrapCoverTitle(""))
+}
+
+func TestTelegramBot_CoverColor(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	bot.FeedName = "news"
+	color := bot.coverColor()
+	assert.Contains(t, coverPalette, color)
+	assert.Equal(t, color, bot.coverColor(), "same feed, same color")
+
+	bot.CoverColor = "#112233"
+	assert.Equal(t, "#112233", bot.coverColor())
+}
+
+func TestAudioFinalizer_Cover(t *testing.T) {
+	var gotArgs []string
+	var gotTitle string
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			assert.Equal(t, "ffmpeg", name)
+			gotArgs = args
+			for _, a := range args {
+				if _, rest, ok := strings.Cut(a, "textfile='"); ok {
+					data, err := os.ReadFile(rest[:strings.Index(rest, "'")])
+					require.NoError(t, err)
+					gotTitle = string(data)
+				}
+			}
+			return nil, nil, os.WriteFile(args[len(args)-1], []byte("png"), 0o600)
+		},
+	}
+	f := &AudioFinalizer{Runner: runner}
+	dst := filepath.Join(t.TempDir(), "ep.cover.png")
+
+	require.NoError(t, f.Cover(context.Background(), dst, "Заголовок: статьи 'в кавычках'", "", "#2b5876", ""))
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "png", string(data))
+	assert.Equal(t, "Заголовок: статьи 'в\nкавычках'", gotTitle, "title passed as is through the file")
+	assert.Contains(t, gotArgs, "color=c=0x2b5876:s=1400x1400:d=1")
+	graph := gotArgs[slices.Index(gotArgs, "-filter_complex")+1]
+	assert.Contains(t, graph, "font=Sans")
+	assert.NotContains(t, graph, "overlay")
+	files, err := os.ReadDir(filepath.Dir(dst))
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "temp files removed")
+
+	require.NoError(t, f.Cover(context.Background(), dst, "t", "/tmp/icon.ico", "2b5876", "/fonts/a b.ttf"))
+	assert.Contains(t, gotArgs, "/tmp/icon.ico")
+	graph = gotArgs[slices.Index(gotArgs, "-filter_complex")+1]
+	assert.Contains(t, graph, "overlay=")
+	assert.Contains(t, graph, "fontfile='/fonts/a b.ttf'")
+
+	assert.Error(t, f.Cover(context.Background(), dst, "t", "", "blue", ""), "bad color")
+}
+
+func TestTelegramBot_MakeCover(t *testing.T) {
+	favicon := []byte("\x00\x00\x01\x00icon")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/favicon.ico" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/x-icon")
+		_, _ = w.Write(favicon)
+	}))
+	defer ts.Close()
+
+	var icons []string
+	var ffmpegErr error
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			icon := ""
+			for _, a := range args {
+				if strings.HasSuffix(a, ".favicon.tmp") {
+					icon = a
+					data, err := os.ReadFile(icon)
+					require.NoError(t, err)
+					assert.Equal(t, favicon, data)
+				}
+			}
+			icons = append(icons, icon)
+			if ffmpegErr != nil && icon != "" {
+				return nil, []byte("bad icon"), ffmpegErr
+			}
+			return nil, nil, os.WriteFile(args[len(args)-1], []byte("png"), 0o600)
+		},
+	}
+	newBot := func() (*TelegramBot, string) {
+		bot := newTestBot(t, newTgStub(t))
+		bot.Finalizer = &AudioFinalizer{Runner: runner}
+		bot.BaseURL = "https://example.com"
+		icons = nil
+		return bot, filepath.Join(t.TempDir(), "art.mp3")
+	}
+
+	t.Run("with favicon", func(t *testing.T) {
+		bot, file := newBot()
+		got := bot.makeCover(context.Background(), &Article{Title: "Title"}, ts.URL+"/post/1", file)
+		assert.Equal(t, "https://example.com/yt/media/art.cover.png", got)
+		require.Len(t, icons, 1)
+		assert.NotEmpty(t, icons[0])
+		_, err := os.Stat(icons[0])
+		assert.True(t, os.IsNotExist(err), "favicon temp file removed")
+		_, err = os.Stat(coverFile(file))
+		assert.NoError(t, err)
+	})
+
+	t.Run("favicon ffmpeg can't read", func(t *testing.T) {
+		bot, file := newBot()
+		ffmpegErr = errors.New("exit status 1")
+		defer func() { ffmpegErr = nil }()
+		got := bot.makeCover(context.Background(), &Article{Title: "Title"}, ts.URL+"/post/1", file)
+		assert.Equal(t, "https://example.com/yt/media/art.cover.png", got)
+		require.Len(t, icons, 2)
+		assert.Empty(t, icons[1], "retried without the favicon")
+	})
+
+	t.Run("no favicon", func(t *testing.T) {
+		bot, file := newBot()
+		got := bot.makeCover(context.Background(), &Article{Title: "Title"}, "http://127.0.0.1:1/post", file)
+		assert.NotEmpty(t, got)
+		require.Len(t, icons, 1)
+		assert.Empty(t, icons[0])
+	})
+
+	t.Run("no ffmpeg", func(t *testing.T) {
+		bot, file := newBot()
+		bot.Finalizer = nil
+		assert.Empty(t, bot.makeCover(context.Background(), &Article{Title: "Title"}, ts.URL, file))
+	})
+
+	t.Run("removed with the episode", func(t *testing.T) {
+		_, file := newBot()
+		require.NoError(t, os.WriteFile(coverFile(file), []byte("png"), 0o600))
+		removeArchive(file)
+		_, err := os.Stat(coverFile(file))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestCheckCoverImage(t *testing.T) {
+	assert.NoError(t, checkCoverImage(testPNG(t, 600, 400)))
+	assert.ErrorContains(t, checkCoverImage(testPNG(t, 32, 32)), "too small, 32x32")
+	assert.ErrorContains(t, checkCoverImage([]byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`)), "not a raster image")
+	assert.NoError(t, checkCoverImage([]byte("RIFF\x00\x00\x00\x00WEBPVP8 ")), "webp left to ffmpeg")
+	assert.Error(t, checkCoverImage([]byte("\x89PNG\r\n\x1a\nbroken")))
+}
+
+func TestTelegramBot_ArticleCover(t *testing.T) {
+	big, tiny := testPNG(t, 800, 600), testPNG(t, 16, 16)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/big.png":
+			_, _ = w.Write(big)
+		case "/tiny.png":
+			_, _ = w.Write(tiny)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	var calls [][]string
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			calls = append(calls, args)
+			return nil, nil, os.WriteFile(args[len(args)-1], []byte("img"), 0o600)
+		},
+	}
+	newBot := func() (*TelegramBot, string) {
+		bot := newTestBot(t, newTgStub(t))
+		bot.Finalizer = &AudioFinalizer{Runner: runner}
+		bot.BaseURL = "https://example.com"
+		calls = nil
+		return bot, filepath.Join(t.TempDir(), "art.mp3")
+	}
+
+	t.Run("image hosted as square jpeg", func(t *testing.T) {
+		bot, file := newBot()
+		got := bot.articleCover(context.Background(), &Article{Title: "T", Image: ts.URL + "/big.png"}, ts.URL+"/post", file)
+		assert.Equal(t, "https://example.com/yt/media/art.cover.jpg", got)
+		require.Len(t, calls, 1)
+		assert.Contains(t, calls[0], "scale=1400:1400:force_original_aspect_ratio=increase,crop=1400:1400")
+		_, err := os.Stat(imageFile(file))
+		require.NoError(t, err)
+		files, err := os.ReadDir(filepath.Dir(file))
+		require.NoError(t, err)
+		assert.Len(t, files, 1, "temp files removed")
+	})
+
+	t.Run("tiny image replaced by a generated cover", func(t *testing.T) {
+		bot, file := newBot()
+		got := bot.articleCover(context.Background(), &Article{Title: "T", Image: ts.URL + "/tiny.png"}, ts.URL+"/post", file)
+		assert.Equal(t, "https://example.com/yt/media/art.cover.png", got)
+	})
+
+	t.Run("missing image replaced by a generated cover", func(t *testing.T) {
+		bot, file := newBot()
+		got := bot.articleCover(context.Background(), &Article{Title: "T", Image: ts.URL + "/gone.jpg"}, ts.URL+"/post", file)
+		assert.Equal(t, "https://example.com/yt/media/art.cover.png", got)
+	})
+
+	t.Run("no ffmpeg, image as is", func(t *testing.T) {
+		bot, file := newBot()
+		bot.Finalizer = nil
+		got := bot.articleCover(context.Background(), &Article{Title: "T", Image: ts.URL + "/tiny.png"}, ts.URL+"/post", file)
+		assert.Equal(t, ts.URL+"/tiny.png", got)
+		assert.Empty(t, calls)
+	})
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))))
+	return buf.Bytes()
+}
@@ -0,0 +1,228 @@
+package proc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+
+	"github.com/umputun/feed-master/app/duration"
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	ytstore "github.com/umputun/feed-master/app/youtube/store"
+)
+
+// errNoDigest is returned when the day has too few short articles for a digest
+var errNoDigest = errors.New("not enough short articles for a digest")
+
+// digestJobTimeout limits building the digest, headlines are a few TTS calls
+const digestJobTimeout = 30 * time.Minute
+
+// nextDigestTime returns the first moment after now at the "HH:MM" local time
+func nextDigestTime(now time.Time, hhmm string) (time.Time, error) {
+	at, err := time.ParseInLocation("15:04", hhmm, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("bad daily digest time %q: %w", hhmm, err)
+	}
+	next := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next, nil
+}
+
+// runDailyDigest builds the digest at the configured time every day until ctx is done
+func (t *TelegramBot) runDailyDigest(ctx context.Context) {
+	if t.DailyDigest.Time == "" || t.Store == nil || t.TTS == nil {
+		return
+	}
+	if t.Finalizer == nil {
+		log.Printf("[WARN] daily digest disabled: needs ffmpeg")
+		return
+	}
+	for {
+		next, err := nextDigestTime(time.Now(), t.DailyDigest.Time)
+		if err != nil {
+			log.Printf("[WARN] daily digest disabled: %v", err)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+		}
+		jobCtx, cancel := context.WithTimeout(ctx, digestJobTimeout)
+		entry, err := t.buildDailyDigest(jobCtx, next)
+		cancel()
+		switch {
+		case errors.Is(err, errNoDigest):
+			log.Printf("[INFO] no daily digest: %v", err)
+		case err != nil:
+			log.Printf("[WARN] failed to build daily digest: %v", err)
+			t.NotifyOwner(t.tr("❌ Дайджест дня не собрался: ") + err.Error())
+		default:
+			t.NotifyOwner(fmt.Sprintf("🗞 %s (%s)", entry.Title, t.formatDuration(time.Duration(entry.Duration)*time.Second)))
+		}
+	}
+}
+
+// digestItems returns the short articles voiced in the day before now,
+// oldest first. Articles offloaded to R2 have no local audio to join, skipped.
+func (t *TelegramBot) digestItems(now time.Time) ([]ytfeed.Entry, error) {
+	all, err := t.Store.Load(t.FeedName, 0)
+	if err != nil {
+		return nil, err
+	}
+	maxDur := t.DailyDigest.MaxItemMin * 60
+	var res []ytfeed.Entry
+	for _, e := range all {
+		if !strings.HasPrefix(e.VideoID, "art_") || e.Published.Before(now.Add(-24*time.Hour)) || e.Published.After(now) {
+			continue
+		}
+		if e.Duration <= 0 || (maxDur > 0 && e.Duration > maxDur) {
+			continue
+		}
+		if _, err := os.Stat(e.File); err != nil {
+			log.Printf("[DEBUG] %s not in the digest, no local audio: %v", e.VideoID, err)
+			continue
+		}
+		res = append(res, e)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Published.Before(res[j].Published) })
+	return res, nil
+}
+
+// buildDailyDigest joins the day's short articles into one episode with
+// ffmpeg: a spoken intro, then a spoken headline before each article. Chapters per article
+// go to the description, as the feed shows them for podcasts.
+func (t *TelegramBot) buildDailyDigest(ctx context.Context, now time.Time) (ytfeed.Entry, error) {
+	items, err := t.digestItems(now)
+	if err != nil {
+		return ytfeed.Entry{}, err
+	}
+	if minItems := max(t.DailyDigest.MinItems, 1); len(items) < minItems {
+		return ytfeed.Entry{}, fmt.Errorf("%w: %d of %d", errNoDigest, len(items), minItems)
+	}
+
+	digestID := "dig_" + now.Format("20060102")
+	if found, _, _ := t.Store.CheckProcessed(ytfeed.Entry{ChannelID: t.FeedName, VideoID: digestID}); found {
+		return ytfeed.Entry{}, fmt.Errorf("%w: already built for %s", errNoDigest, now.Format("02.01.2006"))
+	}
+
+	if t.Finalizer == nil {
+		return ytfeed.Entry{}, errors.New("the digest is joined with ffmpeg, not available")
+	}
+	// headlines are voiced to files of their own, ffmpeg joins everything
+	tmpDir, err := os.MkdirTemp("", "digest")
+	if err != nil {
+		return ytfeed.Entry{}, fmt.Errorf("failed to make temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir) //nolint:errcheck // temp files
+
+	var clips []spliceClip
+	var offset time.Duration
+	var chapters strings.Builder
+	speak := func(text string) error {
+		audio, err := t.TTS.Synthesize(ctx, text)
+		if err != nil {
+			return fmt.Errorf("failed to synthesize %q: %w", text, err)
+		}
+		d, err := duration.MP3Length(bytes.NewReader(audio))
+		if err != nil {
+			return fmt.Errorf("can't measure %q: %w", text, err)
+		}
+		file := filepath.Join(tmpDir, fmt.Sprintf("%03d.mp3", len(clips)))
+		if err := os.WriteFile(file, audio, 0o600); err != nil {
+			return fmt.Errorf("failed to save %q: %w", text, err)
+		}
+		clips = append(clips, spliceClip{file: file})
+		offset += d
+		return nil
+	}
+
+	err = speak(fmt.Sprintf("Дайджест статей за %s. Статей: %d.", now.Format("02.01.2006"), len(items)))
+	for i, e := range items {
+		if err != nil {
+			break
+		}
+		title := e.PlainTitle()
+		fmt.Fprintf(&chapters, "%s %s\n", strings.Trim(formatTimecode(offset.Seconds()), "[]"), title)
+		if err = speak(fmt.Sprintf("Статья %d. %s.", i+1, title)); err != nil {
+			break
+		}
+		clips = append(clips, spliceClip{file: e.File})
+		offset += digestItemLength(e)
+	}
+	if err != nil {
+		return ytfeed.Entry{}, err
+	}
+
+	// re-encoded as one stream: the parts have ID3/Xing headers of their own
+	// and the voices may differ in rate and bitrate
+	filePath := t.FilesLocation + "/" + t.makeFileName(digestID) + ".mp3"
+	if err := t.Finalizer.Splice(ctx, "", clips, filePath); err != nil {
+		return ytfeed.Entry{}, fmt.Errorf("failed to join digest: %w", err)
+	}
+	if err := t.finalizeAudio(ctx, filePath); err != nil {
+		return ytfeed.Entry{}, err
+	}
+
+	entry := ytfeed.Entry{
+		ChannelID: t.FeedName,
+		VideoID:   digestID,
+		Title:     "Дайджест статей за " + now.Format("02.01.2006"),
+		Kind:      ytfeed.KindDigest,
+		Published: now,
+		Updated:   now,
+		File:      filePath,
+		Duration:  t.ttsDuration(filePath, 0),
+	}
+	entry.Media.Description = template.HTML(fmt.Sprintf("Статей: %d\n\nГлавы:\n%s", //nolint:gosec // our own text
+		len(items), strings.TrimRight(chapters.String(), "\n")))
+
+	created, err := t.saveEntry(ctx, entry)
+	if err != nil {
+		return ytfeed.Entry{}, fmt.Errorf("failed to save: %w", err)
+	}
+	if !created {
+		return ytfeed.Entry{}, fmt.Errorf("%w: already in the feed", errNoDigest)
+	}
+	if err := t.Store.SetProcessed(entry); err != nil {
+		log.Printf("[WARN] failed to mark digest as processed: %v", err)
+	}
+	t.offloadMedia(entry)
+	t.logHistory(ytstore.HistoryEntry{
+		Title:    entry.Title,
+		Action:   "digest",
+		VideoID:  digestID,
+		Duration: t.formatDuration(time.Duration(entry.Duration) * time.Second),
+	})
+
+	if t.DailyDigest.RemoveItems {
+		for _, e := range items {
+			if err := t.deleteEntry(e); err != nil {
+				log.Printf("[WARN] failed to remove %s after the digest: %v", e.VideoID, err)
+			}
+		}
+	}
+	t.removeOldEntries()
+	log.Printf("[INFO] added daily digest %s: %d articles, %ds", digestID, len(items), entry.Duration)
+	return entry, nil
+}
+
+// digestItemLength is the length of an article's audio, the stored
+// duration when the file can't be measured
+func digestItemLength(e ytfeed.Entry) time.Duration {
+	d, err := duration.MP3FileLength(e.File)
+	if err != nil {
+		return time.Duration(e.Duration) * time.Second
+	}
+	return d
+}
@@ -0,0 +1,114 @@
+package proc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/feed-master/app/config"
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	"github.com/umputun/feed-master/app/youtube/feed/mocks"
+)
+
+func TestNextDigestTime(t *testing.T) {
+	loc := time.FixedZone("test", 3*3600)
+	now := time.Date(2026, 10, 16, 12, 30, 0, 0, loc)
+	tbl := []struct {
+		at   string
+		want time.Time
+	}{
+		{"21:00", time.Date(2026, 10, 16, 21, 0, 0, 0, loc)},
+		{"12:30", time.Date(2026, 10, 17, 12, 30, 0, 0, loc)},
+		{"08:15", time.Date(2026, 10, 17, 8, 15, 0, 0, loc)},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.at, func(t *testing.T) {
+			next, err := nextDigestTime(now, tt.at)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, next)
+		})
+	}
+	_, err := nextDigestTime(now, "9pm")
+	require.Error(t, err)
+}
+
+func TestTelegramBot_BuildDailyDigest(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Store = newTestJobStore(t)
+	bot.MaxItems = 10
+	bot.DailyDigest = config.DailyDigest{MaxItemMin: 10, MinItems: 2, RemoveItems: true}
+	var joined [][]string
+	bot.Finalizer = &AudioFinalizer{Runner: &mocks.CommandRunnerMock{
+		RunFunc: func(_ context.Context, _ string, args ...string) ([]byte, []byte, error) {
+			if slices.Contains(args, "null") {
+				return nil, nil, nil // decode check
+			}
+			// inputs joined as they are, enough for the durations
+			var out []byte
+			for i, a := range args {
+				if a == "-i" {
+					data, err := os.ReadFile(args[i+1])
+					if err != nil {
+						return nil, nil, err
+					}
+					out = append(out, data...)
+				}
+			}
+			if slices.Contains(args, "-filter_complex") {
+				joined = append(joined, args)
+			}
+			return nil, nil, os.WriteFile(args[len(args)-1], out, 0o600)
+		}}}
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 21, 0, 0, 0, time.UTC)
+
+	addArticle := func(id, title string, published time.Time, dur int) {
+		audio, err := (&FakeTTS{}).Synthesize(ctx, strings.Repeat("текст ", 600))
+		require.NoError(t, err)
+		file := filepath.Join(bot.FilesLocation, id+".mp3")
+		require.NoError(t, os.WriteFile(file, audio, 0o600))
+		_, err = bot.Store.Save(ytfeed.Entry{ChannelID: bot.FeedName, VideoID: id, Title: "📖 " + title,
+			Published: published, File: file, Duration: dur})
+		require.NoError(t, err)
+	}
+	addArticle("art_1", "Первая", now.Add(-5*time.Hour), 60)
+	addArticle("art_2", "Вторая", now.Add(-time.Hour), 90)
+	addArticle("art_long", "Длинная", now.Add(-2*time.Hour), 3600)
+	addArticle("art_old", "Вчерашняя", now.Add(-30*time.Hour), 60)
+
+	entry, err := bot.buildDailyDigest(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, "dig_20261016", entry.VideoID)
+	assert.Equal(t, "Дайджест статей за 16.10.2026", entry.Title)
+	desc := string(entry.Media.Description)
+	assert.Contains(t, desc, "Статей: 2")
+	lines := strings.Split(desc[strings.Index(desc, "Главы:\n")+len("Главы:\n"):], "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasSuffix(lines[0], " Первая"), lines[0])
+	assert.True(t, strings.HasSuffix(lines[1], " Вторая"), lines[1])
+	assert.Greater(t, strings.Fields(lines[1])[0], strings.Fields(lines[0])[0], "second chapter after the first article")
+	assert.Greater(t, entry.Duration, 0)
+	require.Len(t, joined, 1)
+	assert.Contains(t, strings.Join(joined[0], " "), "concat=n=5:v=0:a=1", "intro, two headlines, two articles")
+	assert.Contains(t, joined[0], "libmp3lame", "re-encoded")
+	assert.Contains(t, joined[0], filepath.Join(bot.FilesLocation, "art_1.mp3"))
+
+	entries, err := bot.Store.Load(bot.FeedName, 0)
+	require.NoError(t, err)
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.VideoID)
+	}
+	assert.ElementsMatch(t, []string{"dig_20261016", "art_long", "art_old"}, ids, "combined articles removed")
+
+	_, err = bot.buildDailyDigest(ctx, now)
+	require.ErrorIs(t, err, errNoDigest)
+}
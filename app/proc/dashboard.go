@@ -0,0 +1,167 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	"github.com/umputun/feed-master/app/tools"
+)
+
+// defaultDashboardInterval is how often the dashboard is refreshed unless configured
+const defaultDashboardInterval = 30 * time.Second
+
+// runningJob is a background job shown on the dashboard
+type runningJob struct {
+	name    string
+	started time.Time
+	eta     time.Time // expected end from the learned speeds, zero = unknown
+}
+
+type runningJobKey struct{}
+
+// trackJob adds the job to the running ones till finished is called
+func (t *TelegramBot) trackJob(ctx context.Context, id, name string) (jobCtx context.Context, finished func()) {
+	if name == "" {
+		name = t.tr("фоновая задача")
+	}
+	job := &runningJob{name: name, started: time.Now()}
+	t.runningMu.Lock()
+	if t.running == nil {
+		t.running = map[string]*runningJob{}
+	}
+	t.running[id] = job
+	t.runningMu.Unlock()
+	return context.WithValue(ctx, runningJobKey{}, job), func() {
+		t.runningMu.Lock()
+		delete(t.running, id)
+		t.runningMu.Unlock()
+	}
+}
+
+// setJobETA records the expected end of the job of ctx, nothing outside of a job
+func (t *TelegramBot) setJobETA(ctx context.Context, left time.Duration) {
+	job, ok := ctx.Value(runningJobKey{}).(*runningJob)
+	if !ok {
+		return
+	}
+	t.runningMu.Lock()
+	job.eta = time.Now().Add(left)
+	t.runningMu.Unlock()
+}
+
+// runningJobs returns copies of the running jobs, the oldest first
+func (t *TelegramBot) runningJobs() []runningJob {
+	t.runningMu.Lock()
+	defer t.runningMu.Unlock()
+	res := make([]runningJob, 0, len(t.running))
+	for _, job := range t.running {
+		res = append(res, *job)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].started.Before(res[j].started) })
+	return res
+}
+
+// actionJobName names a link menu job on the dashboard by its source
+func (t *TelegramBot) actionJobName(pa *pendingAction) string {
+	switch {
+	case len(pa.videoIDs) == 1:
+		return "youtu.be/" + pa.videoIDs[0]
+	case len(pa.videoIDs) > 1:
+		return fmt.Sprintf(t.tr("%d видео"), len(pa.videoIDs))
+	case pa.url != "":
+		return notesLabel(pa.url)
+	case pa.article != nil:
+		return pa.article.Title
+	}
+	return ""
+}
+
+// dashboardChat is where the dashboard is pinned: the admin chat, else the user's one
+func (t *TelegramBot) dashboardChat() *tb.Chat {
+	if t.AdminChatID != 0 {
+		return &tb.Chat{ID: t.AdminChatID}
+	}
+	if t.AllowedUserID != 0 {
+		return &tb.Chat{ID: t.AllowedUserID}
+	}
+	return nil
+}
+
+// runDashboard sends and pins the dashboard, then edits it on every change
+// till ctx is done. Per-job status messages are still sent.
+func (t *TelegramBot) runDashboard(ctx context.Context) {
+	chat := t.dashboardChat()
+	if chat == nil {
+		log.Printf("[WARN] dashboard enabled without a chat to pin it to")
+		return
+	}
+	text := t.dashboardText(time.Now())
+	msg, err := t.Bot.Send(chat, text, tb.Silent)
+	if err != nil {
+		log.Printf("[WARN] failed to send dashboard: %v", err)
+		return
+	}
+	if err := t.Bot.Pin(msg, tb.Silent); err != nil {
+		log.Printf("[WARN] failed to pin dashboard: %v", err)
+	}
+
+	interval := t.Dashboard.Interval
+	if interval <= 0 {
+		interval = defaultDashboardInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			t.edit(msg, t.tr("📌 Бот остановлен"))
+			if err := t.Bot.Unpin(chat, msg.ID); err != nil {
+				log.Printf("[WARN] failed to unpin dashboard: %v", err)
+			}
+			return
+		case now := <-ticker.C:
+			next := t.dashboardText(now)
+			if next == text {
+				continue // Telegram rejects edits not changing the message
+			}
+			t.edit(msg, next)
+			text = next
+		}
+	}
+}
+
+// dashboardText renders the running jobs with their ETAs, the notes queue
+// and the tools load
+func (t *TelegramBot) dashboardText(now time.Time) string {
+	var b strings.Builder
+	jobs := t.runningJobs()
+	if len(jobs) == 0 {
+		b.WriteString(t.tr("📌 Задач нет\n"))
+	} else {
+		fmt.Fprintf(&b, t.tr("📌 В работе: %d\n"), len(jobs))
+	}
+	for _, job := range jobs {
+		fmt.Fprintf(&b, t.tr("• %s, идёт %s"), job.name, t.formatETA(now.Sub(job.started)))
+		switch {
+		case job.eta.IsZero():
+		case job.eta.After(now):
+			fmt.Fprintf(&b, t.tr(", ⏱ %s осталось"), t.formatETA(job.eta.Sub(now)))
+		default:
+			b.WriteString(t.tr(", ⏱ вот-вот"))
+		}
+		b.WriteString("\n")
+	}
+	if t.NotesSvc != nil {
+		if queued, processing, _, err := t.NotesSvc.QueueStatus(); err == nil {
+			fmt.Fprintf(&b, t.tr("\n📋 Конспекты: в очереди %d, в работе %d\n"), queued, processing)
+		}
+	}
+	t.writeLoad(&b, tools.Load())
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,63 @@
+package proc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelegramBot_DashboardText(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	assert.Equal(t, "📌 Задач нет", bot.dashboardText(time.Now()))
+
+	ctx, finishedVo := bot.trackJob(context.Background(), "j1", "youtu.be/abc")
+	bot.setJobETA(ctx, 10*time.Minute)
+	_, finishedBg := bot.trackJob(context.Background(), "j2", "")
+	bot.setJobETA(context.Background(), time.Hour) // outside of a job, ignored
+
+	text := bot.dashboardText(time.Now().Add(3 * time.Minute))
+	assert.Equal(t, "📌 В работе: 2\n"+
+		"• youtu.be/abc, идёт ≈3 мин, ⏱ ≈7 мин осталось\n"+
+		"• фоновая задача, идёт ≈3 мин", text)
+	assert.Contains(t, bot.dashboardText(time.Now().Add(time.Hour)), "⏱ вот-вот")
+
+	finishedVo()
+	finishedBg()
+	assert.Empty(t, bot.runningJobs())
+}
+
+func TestActionJobName(t *testing.T) {
+	bot := &TelegramBot{}
+	assert.Equal(t, "youtu.be/abc", bot.actionJobName(&pendingAction{kind: "yt", videoIDs: []string{"abc"}}))
+	assert.Equal(t, "3 видео", bot.actionJobName(&pendingAction{kind: "yt", videoIDs: []string{"a", "b", "c"}}))
+	assert.Equal(t, "example.com/post", bot.actionJobName(&pendingAction{kind: "article", url: "https://www.example.com/post"}))
+	assert.Equal(t, "Letter", bot.actionJobName(&pendingAction{kind: "article", article: &Article{Title: "Letter"}}))
+}
+
+func TestTelegramBot_RunDashboard(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Dashboard.Interval = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		bot.runDashboard(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return stub.count("pinChatMessage") == 1 }, time.Second, 5*time.Millisecond)
+	_, finished := bot.trackJob(context.Background(), "j1", "youtu.be/abc")
+	require.Eventually(t, func() bool { return len(stub.texts("editMessageText")) > 0 }, time.Second, 5*time.Millisecond)
+	finished()
+	cancel()
+	<-done
+
+	assert.Equal(t, []string{"📌 Задач нет"}, stub.texts("sendMessage"))
+	edits := stub.texts("editMessageText")
+	assert.Contains(t, edits[0], "youtu.be/abc")
+	assert.Equal(t, "📌 Бот остановлен", edits[len(edits)-1])
+	assert.Equal(t, 1, stub.count("unpinChatMessage"))
+}
@@ -0,0 +1,116 @@
+package proc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// articleRequest is an article link to voice and how to do it
+type articleRequest struct {
+	URL      string
+	Preset   string   // processing preset name, "" = default
+	Force    bool     // add even when identical content is already in the feed
+	MinChars int      // shorter texts are rejected with errTooShort, 0 = no limit
+	Article  *Article // already extracted (mail bodies), URL is then only its ID source
+	LongText string   // over the length limit: "full", "summarize" or "split" as chosen, "" = ArticleLimit policy
+
+	// no one to answer buttons (read-later, RSS, mail), "ask" turns into the background policy
+	Background bool
+}
+
+// errInFeedAlready is returned by processArticle for background requests
+// whose article (or the same text) is in the feed already, nothing was voiced
+var errInFeedAlready = errors.New("article is in the feed already")
+
+// inFeed is the result of a request for an article in the feed already as
+// entry id: nil for a user, who sees the status, errInFeedAlready for a
+// background source to tell it from a voiced one
+func (r articleRequest) inFeed(id string) error {
+	if !r.Background {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", errInFeedAlready, id)
+}
+
+// contentHash hashes text reduced to lowercase words of letters and digits,
+// so whitespace, punctuation and markup differences between syndicated copies
+// of the same article don't change it. Short lines (headings, bylines, image
+// captions, "share" widgets) are what syndication rewrites, they are skipped
+// unless the text has nothing else.
+func contentHash(text string) string {
+	const minLineWords = 5
+	var all, body []string
+	for _, line := range strings.Split(text, "\n") {
+		words := strings.FieldsFunc(strings.ToLower(line), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		all = append(all, words...)
+		if len(words) >= minLineWords {
+			body = append(body, words...)
+		}
+	}
+	if len(body) == 0 {
+		body = all
+	}
+	sum := sha256.Sum256([]byte(strings.Join(body, " ")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// findDuplicate looks for a feed entry with the given content hash. pos is
+// the entry number as /list shows it and /del takes it.
+func (t *TelegramBot) findDuplicate(hash string) (pos int, entry ytfeed.Entry, found bool) {
+	entries, err := t.Store.Load(t.FeedName, t.MaxItems)
+	if err != nil {
+		log.Printf("[WARN] can't check duplicates: %v", err)
+		return 0, ytfeed.Entry{}, false
+	}
+	for i, e := range entries {
+		if e.ContentHash != "" && e.ContentHash == hash {
+			return i + 1, e, true
+		}
+	}
+	return 0, ytfeed.Entry{}, false
+}
+
+// canonicalArticleURL returns the page's canonical URL cleaned of tracking
+// params when it can stand for the link, the link itself otherwise. A
+// canonical pointing to the site root of a deeper page is a CMS misconfig,
+// taking it would collapse every article of the site into one ID.
+func canonicalArticleURL(link, canonical string) string {
+	if canonical == "" {
+		return link
+	}
+	cu, err := url.Parse(canonical)
+	if err != nil {
+		return link
+	}
+	lu, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	if strings.Trim(cu.Path, "/") == "" && strings.Trim(lu.Path, "/") != "" {
+		return link
+	}
+	return StripTracking(canonical)
+}
+
+// offerDuplicateOverride replaces the status with a duplicate warning and a
+// button adding the article anyway
+func (t *TelegramBot) offerDuplicateOverride(statusMsg, originalMsg *tb.Message, req articleRequest, pos int, dup ytfeed.Entry) {
+	token := t.storePendingAction(&pendingAction{kind: "article", url: req.URL, preset: req.Preset, force: true, originalMsg: originalMsg})
+	markup := &tb.ReplyMarkup{}
+	btnAdd := markup.Data(t.tr("➕ Всё равно добавить"), "act", token+"|tts")
+	btnCancel := markup.Data(t.tr("🚫 Отмена"), "act", token+"|cancel")
+	markup.InlineKeyboard = [][]tb.InlineButton{{*btnAdd.Inline(), *btnCancel.Inline()}}
+	t.edit(statusMsg, fmt.Sprintf(t.tr("⚠️ Такой же текст уже в ленте: запись %d «%s»"), pos, dup.Title), markup)
+}
@@ -0,0 +1,89 @@
+package proc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+)
+
+func TestContentHash(t *testing.T) {
+	a := contentHash("Hello, World!\nThis is   the text.")
+	assert.Equal(t, a, contentHash("hello world — this is the TEXT"), "punctuation, case and spacing ignored")
+	assert.NotEqual(t, a, contentHash("hello world this is another text"))
+	assert.Len(t, a, 32)
+
+	body := "The first paragraph of the article is long enough.\nAnd the second one is long as well."
+	assert.Equal(t, contentHash("Title\nBy Author\n"+body), contentHash(body+"\nShare on X"), "short lines skipped")
+}
+
+func TestCanonicalArticleURL(t *testing.T) {
+	tbl := []struct {
+		name, link, canonical, want string
+	}{
+		{"no canonical", "https://mirror.com/p/1", "", "https://mirror.com/p/1"},
+		{"syndicated copy", "https://medium.com/@a/post-123", "https://blog.example.com/post?utm_source=medium",
+			"https://blog.example.com/post"},
+		{"root canonical ignored", "https://example.com/2024/post", "https://example.com/", "https://example.com/2024/post"},
+		{"root link and root canonical", "https://example.com/", "https://www.example.com/", "https://www.example.com/"},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, canonicalArticleURL(tt.link, tt.canonical))
+		})
+	}
+}
+
+func TestTelegramBot_ProcessArticleDuplicate(t *testing.T) {
+	text := strings.Repeat("Это длинный текст статьи про одно и то же. ", 20)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// same text, different markup on the two "sites"
+		if r.URL.Path == "/original" {
+			_, _ = w.Write([]byte(`<html><head><title>Статья</title></head><body><article><h1>Статья</h1><p>` +
+				text + `</p></article></body></html>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<html><head><title>Копия</title></head><body><div class="post"><p>` +
+			strings.ReplaceAll(text, ". ", ".\n") + `</p><p></p></div></body></html>`))
+	}))
+	defer ts.Close()
+
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Store = newTestJobStore(t)
+	bot.MaxItems = 10
+	bot.ArticleExtractor = NewArticleExtractor()
+	ctx := context.Background()
+	statusMsg := &tb.Message{ID: 5, Chat: &tb.Chat{ID: testBotUserID}}
+
+	require.NoError(t, bot.processArticle(ctx, nil, statusMsg, nil, articleRequest{URL: ts.URL + "/original"}))
+	entries, err := bot.Store.Load(bot.FeedName, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.NotEmpty(t, entries[0].ContentHash)
+
+	require.NoError(t, bot.processArticle(ctx, nil, statusMsg, nil, articleRequest{URL: ts.URL + "/copy?utm_source=x"}))
+	edits := stub.texts("editMessageText")
+	assert.Contains(t, edits[len(edits)-1], "Такой же текст уже в ленте: запись 1")
+	entries, err = bot.Store.Load(bot.FeedName, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "duplicate not added")
+
+	var pa *pendingAction
+	for _, p := range bot.pendingActions {
+		pa = p
+	}
+	require.NotNil(t, pa, "override offered")
+	assert.True(t, pa.force)
+	assert.Equal(t, ts.URL+"/copy?utm_source=x", pa.url)
+
+	require.NoError(t, bot.processArticle(ctx, nil, statusMsg, nil, articleRequest{URL: pa.url, Force: true}))
+	entries, err = bot.Store.Load(bot.FeedName, 10)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "forced")
+}
@@ -0,0 +1,47 @@
+package proc
+
+import (
+	"encoding/base64"
+	"strings"
+	"unicode/utf8"
+
+	tb "gopkg.in/tucnak/telebot.v2"
+)
+
+// handleStart opens the bot, a t.me/<bot>?start=<payload> deep link carries
+// a link in the payload and is handled as if the link was sent
+func (t *TelegramBot) handleStart(m *tb.Message) {
+	text, ok := startPayloadText(commandArgs(m).arg(0))
+	if !ok || t.extractURL(text) == "" || !t.isAuthorized(m.Sender) {
+		t.handleHelp(m)
+		return
+	}
+	linkMsg := *m
+	linkMsg.Text, linkMsg.Payload = text, ""
+	t.handleText(&linkMsg)
+}
+
+// startPayloadText decodes the payload of a deep link: the base64 of the
+// text, URL-safe as Telegram allows only A-Z, a-z, 0-9, _ and - there, the
+// standard alphabet and padding are taken too. The https:// of a link may be
+// left out to fit the 64 characters of the payload.
+func startPayloadText(payload string) (string, bool) {
+	payload = strings.TrimRight(payload, "=")
+	if payload == "" {
+		return "", false
+	}
+	payload = strings.NewReplacer("+", "-", "/", "_").Replace(payload)
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", false
+	}
+	text := strings.TrimSpace(string(data))
+	if text == "" || !utf8.ValidString(text) || strings.ContainsFunc(text, func(r rune) bool { return r < ' ' && r != '\n' && r != '\t' }) {
+		return "", false
+	}
+	words := strings.Fields(text) // "[preset] <link>"
+	if last := words[len(words)-1]; !strings.Contains(last, "://") && strings.Contains(last, ".") {
+		words[len(words)-1] = "https://" + last // "example.com/post"
+	}
+	return strings.Join(words, " "), true
+}
@@ -0,0 +1,57 @@
+package proc
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartPayloadText(t *testing.T) {
+	enc := base64.RawURLEncoding.EncodeToString
+	tbl := []struct {
+		payload, text string
+		ok            bool
+	}{
+		{enc([]byte("https://example.com/post?id=1")), "https://example.com/post?id=1", true},
+		{enc([]byte("example.com/post")), "https://example.com/post", true},
+		{enc([]byte("tech  example.com/post")), "tech https://example.com/post", true},
+		{base64.StdEncoding.EncodeToString([]byte("https://example.com/?a=b>")), "https://example.com/?a=b>", true},
+		{"", "", false},
+		{"not base64!", "", false},
+		{enc([]byte("\x00\x01binary")), "", false},
+		{enc([]byte{0xff, 0xfe}), "", false},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.payload, func(t *testing.T) {
+			text, ok := startPayloadText(tt.payload)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.text, text)
+		})
+	}
+}
+
+func TestTelegramBot_HandleStart(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+
+	payload := base64.RawURLEncoding.EncodeToString([]byte("example.com/post"))
+	bot.handleStart(testMessage(testBotUserID, "/start "+payload))
+	bot.handleStart(testMessage(testBotUserID, "/start"))
+	bot.handleStart(testMessage(testBotUserID, "/start "+base64.RawURLEncoding.EncodeToString([]byte("hello"))))
+	bot.handleStart(testMessage(1, "/start "+payload))
+
+	sent := stub.texts("sendMessage")
+	require.Len(t, sent, 4)
+	assert.Equal(t, "🤔 Что сделать со ссылкой?", sent[0], "the link goes to the menu as if sent")
+	assert.Contains(t, sent[1], "🎧 Turnip Bot")
+	assert.Contains(t, sent[2], "🎧 Turnip Bot", "no link in the payload")
+	assert.Equal(t, "Unauthorized. This bot is private.", sent[3])
+	bot.pendingMu.Lock()
+	defer bot.pendingMu.Unlock()
+	require.Len(t, bot.pendingActions, 1)
+	for _, pa := range bot.pendingActions {
+		assert.Equal(t, "https://example.com/post", pa.url)
+	}
+}
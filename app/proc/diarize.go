@@ -0,0 +1,105 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// Diarizer tells who speaks when by running an external diarization command
+// (e.g. a pyannote.audio wrapper script) over the audio file. The command gets
+// the file path as its last argument and prints RTTM to stdout.
+type Diarizer struct {
+	Command string               // command line, e.g. "python3 /opt/diarize.py"
+	Runner  ytfeed.CommandRunner // nil = ytfeed.ExecRunner
+}
+
+// SpeakerTurn is one stretch of speech by one speaker, in seconds
+type SpeakerTurn struct {
+	Start, End float64
+	Speaker    string // diarizer's own label, e.g. SPEAKER_00
+}
+
+// Diarize runs the command over the audio and returns the speaker turns in
+// the order printed
+func (d *Diarizer) Diarize(ctx context.Context, audioPath string) ([]SpeakerTurn, error) {
+	args := strings.Fields(d.Command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("diarize command is empty")
+	}
+	runner := d.Runner
+	if runner == nil {
+		runner = ytfeed.ExecRunner{}
+	}
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Minute)
+	defer cancel()
+
+	stdout, stderr, err := runner.Run(ctx, args[0], append(args[1:], audioPath)...)
+	if err != nil {
+		return nil, fmt.Errorf("diarization failed: %w, stderr: %s", err, lastLines(string(stderr), 5))
+	}
+	turns := parseRTTM(stdout)
+	if len(turns) == 0 {
+		return nil, fmt.Errorf("diarization found no speech")
+	}
+	return turns, nil
+}
+
+// parseRTTM reads the SPEAKER lines of RTTM output:
+// SPEAKER <file> <chan> <start> <duration> <NA> <NA> <speaker> <NA> <NA>
+func parseRTTM(data []byte) []SpeakerTurn {
+	var res []SpeakerTurn
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		f := strings.Fields(sc.Text())
+		if len(f) < 8 || f[0] != "SPEAKER" {
+			continue
+		}
+		start, err1 := strconv.ParseFloat(f[3], 64)
+		dur, err2 := strconv.ParseFloat(f[4], 64)
+		if err1 != nil || err2 != nil || dur <= 0 {
+			continue
+		}
+		res = append(res, SpeakerTurn{Start: start, End: start + dur, Speaker: f[7]})
+	}
+	return res
+}
+
+// labelSpeakers sets each segment's Speaker to the one talking most during
+// it, named "Speaker 1", "Speaker 2"... in order of appearance. A single
+// voice isn't worth labelling, the segments are left as they are then.
+func labelSpeakers(segs []TranscriptSegment, turns []SpeakerTurn) {
+	labels := map[string]string{}
+	picked := make([]string, len(segs))
+	for i, seg := range segs {
+		overlap := map[string]float64{}
+		best := ""
+		for _, turn := range turns {
+			if o := min(seg.End, turn.End) - max(seg.Start, turn.Start); o > 0 {
+				overlap[turn.Speaker] += o
+				if best == "" || overlap[turn.Speaker] > overlap[best] {
+					best = turn.Speaker
+				}
+			}
+		}
+		if best == "" {
+			continue
+		}
+		if _, ok := labels[best]; !ok {
+			labels[best] = fmt.Sprintf("Speaker %d", len(labels)+1)
+		}
+		picked[i] = labels[best]
+	}
+	if len(labels) < 2 {
+		return
+	}
+	for i := range segs {
+		segs[i].Speaker = picked[i]
+	}
+}
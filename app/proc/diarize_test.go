@@ -0,0 +1,65 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/feed-master/app/youtube/feed/mocks"
+)
+
+const testRTTM = `SPEAKER ep 1 0.000 4.200 <NA> <NA> SPEAKER_01 <NA> <NA>
+SPEAKER ep 1 4.200 5.800 <NA> <NA> SPEAKER_00 <NA> <NA>
+garbage line
+SPEAKER ep 1 10.000 3.000 <NA> <NA> SPEAKER_01 <NA> <NA>
+`
+
+func TestParseRTTM(t *testing.T) {
+	turns := parseRTTM([]byte(testRTTM))
+	require.Len(t, turns, 3)
+	assert.Equal(t, SpeakerTurn{Start: 4.2, End: 10, Speaker: "SPEAKER_00"}, turns[1])
+}
+
+func TestLabelSpeakers(t *testing.T) {
+	segs := []TranscriptSegment{
+		{Start: 0, End: 4, Text: "вопрос"},
+		{Start: 4, End: 9.5, Text: "ответ"},
+		{Start: 9.5, End: 13, Text: "ещё вопрос"},
+		{Start: 20, End: 25, Text: "тишина в разметке"},
+	}
+	labelSpeakers(segs, parseRTTM([]byte(testRTTM)))
+	assert.Equal(t, "Speaker 1", segs[0].Speaker, "numbered in order of appearance")
+	assert.Equal(t, "Speaker 2", segs[1].Speaker)
+	assert.Equal(t, "Speaker 1", segs[2].Speaker, "most overlap wins")
+	assert.Empty(t, segs[3].Speaker)
+
+	single := []TranscriptSegment{{Start: 0, End: 3}, {Start: 3, End: 6}}
+	labelSpeakers(single, []SpeakerTurn{{Start: 0, End: 6, Speaker: "SPEAKER_00"}})
+	assert.Empty(t, single[0].Speaker, "one voice isn't labelled")
+}
+
+func TestDiarizer_Diarize(t *testing.T) {
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			assert.Equal(t, "python3", name)
+			assert.Equal(t, []string{"/opt/diarize.py", "/tmp/ep.mp3"}, args)
+			return []byte(testRTTM), nil, nil
+		},
+	}
+	d := &Diarizer{Command: "python3 /opt/diarize.py", Runner: runner}
+	turns, err := d.Diarize(context.Background(), "/tmp/ep.mp3")
+	require.NoError(t, err)
+	assert.Len(t, turns, 3)
+
+	runner.RunFunc = func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+		return nil, []byte("CUDA out of memory"), errors.New("exit status 1")
+	}
+	_, err = d.Diarize(context.Background(), "/tmp/ep.mp3")
+	assert.ErrorContains(t, err, "CUDA out of memory")
+
+	_, err = (&Diarizer{}).Diarize(context.Background(), "/tmp/ep.mp3")
+	assert.Error(t, err)
+}
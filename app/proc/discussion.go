@@ -0,0 +1,233 @@
+package proc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// defaultMaxComments is how many top-level comments of a discussion are read
+const defaultMaxComments = 10
+
+// redditBase and hnAPIBase are the JSON endpoints of the discussion extractors (vars for tests)
+var (
+	redditBase = "https://www.reddit.com"
+	hnAPIBase  = "https://hacker-news.firebaseio.com/v0"
+)
+
+var redditPostRe = regexp.MustCompile(`^/r/([^/]+)/comments/([a-z0-9]+)`)
+
+// discussionURL tells whether the URL is a Reddit post or a Hacker News item,
+// readability makes a mess of both. Returns the site and the post id.
+func discussionURL(rawURL string) (site, id string) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", ""
+	}
+	switch strings.ToLower(u.Hostname()) {
+	case "reddit.com", "www.reddit.com", "old.reddit.com", "np.reddit.com", "new.reddit.com":
+		if m := redditPostRe.FindStringSubmatch(u.Path); m != nil {
+			return "reddit", m[2]
+		}
+	case "news.ycombinator.com":
+		if u.Path == "/item" {
+			if id := u.Query().Get("id"); id != "" {
+				if _, err := strconv.Atoi(id); err == nil {
+					return "hn", id
+				}
+			}
+		}
+	}
+	return "", ""
+}
+
+// extractDiscussion reads the post and its top comments through the site's
+// JSON API and makes a script of them, every part introduced by its author
+func (e *ArticleExtractor) extractDiscussion(ctx context.Context, rawURL, site, id string) (*Article, error) {
+	limit := e.MaxComments
+	if limit <= 0 {
+		limit = defaultMaxComments
+	}
+	var article *Article
+	var err error
+	switch site {
+	case "reddit":
+		article, err = e.extractReddit(ctx, id, limit)
+	case "hn":
+		article, err = e.extractHN(ctx, id, limit)
+	default:
+		return nil, fmt.Errorf("unknown discussion site %q", site)
+	}
+	if err != nil {
+		return nil, err
+	}
+	article.URL = rawURL
+	article.TextContent = cleanText(article.TextContent)
+	if article.TextContent == "" {
+		return nil, fmt.Errorf("no content extracted from %s", article.SiteName)
+	}
+	return article, nil
+}
+
+type redditThing struct {
+	Kind string `json:"kind"`
+	Data struct {
+		Title        string `json:"title"`
+		Author       string `json:"author"`
+		Subreddit    string `json:"subreddit"`
+		IsSelf       bool   `json:"is_self"`
+		Domain       string `json:"domain"`
+		SelftextHTML string `json:"selftext_html"`
+		BodyHTML     string `json:"body_html"`
+		Stickied     bool   `json:"stickied"`
+	} `json:"data"`
+}
+
+type redditListing struct {
+	Data struct {
+		Children []redditThing `json:"children"`
+	} `json:"data"`
+}
+
+// extractReddit reads a post and its best top-level comments, moderator
+// stickies, AutoModerator and deleted comments are skipped
+func (e *ArticleExtractor) extractReddit(ctx context.Context, id string, limit int) (*Article, error) {
+	q := url.Values{"raw_json": {"1"}, "depth": {"1"}, "sort": {"confidence"}, "limit": {strconv.Itoa(limit * 2)}}
+	var listings []redditListing
+	if err := e.getJSON(ctx, redditBase+"/comments/"+id+".json?"+q.Encode(), &listings); err != nil {
+		return nil, fmt.Errorf("reddit: %w", err)
+	}
+	if len(listings) < 1 || len(listings[0].Data.Children) == 0 {
+		return nil, fmt.Errorf("reddit: post %s not found", id)
+	}
+	post := listings[0].Data.Children[0].Data
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s\n\nPost by %s in r/%s:\n", spokenTitle(post.Title), post.Author, post.Subreddit)
+	if !post.IsSelf && post.Domain != "" {
+		fmt.Fprintf(&sb, "Link to %s.\n", post.Domain)
+	}
+	sb.WriteString(htmlFragmentText(post.SelftextHTML))
+
+	if len(listings) > 1 {
+		n := 0
+		for _, c := range listings[1].Data.Children {
+			if n >= limit {
+				break
+			}
+			d := c.Data
+			if c.Kind != "t1" || d.Stickied || d.Author == "AutoModerator" || d.Author == "[deleted]" {
+				continue
+			}
+			text := htmlFragmentText(d.BodyHTML)
+			if text == "" || text == "[removed]" || text == "[deleted]" {
+				continue
+			}
+			fmt.Fprintf(&sb, "\n\nComment by %s:\n%s", d.Author, text)
+			n++
+		}
+	}
+	return &Article{Title: post.Title, TextContent: sb.String(), SiteName: "Reddit"}, nil
+}
+
+type hnItem struct {
+	ID      int    `json:"id"`
+	By      string `json:"by"`
+	Title   string `json:"title"`
+	Text    string `json:"text"`
+	URL     string `json:"url"`
+	Kids    []int  `json:"kids"`
+	Deleted bool   `json:"deleted"`
+	Dead    bool   `json:"dead"`
+}
+
+// extractHN reads an item and its top-level comments, the API lists kids in
+// the ranked order the site shows them
+func (e *ArticleExtractor) extractHN(ctx context.Context, id string, limit int) (*Article, error) {
+	var item hnItem
+	if err := e.getJSON(ctx, hnAPIBase+"/item/"+id+".json", &item); err != nil {
+		return nil, fmt.Errorf("hacker news: %w", err)
+	}
+	if item.ID == 0 {
+		return nil, fmt.Errorf("hacker news: item %s not found", id)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s\n\nPost by %s:\n", spokenTitle(item.Title), item.By)
+	if u, err := url.Parse(item.URL); err == nil && u.Host != "" {
+		fmt.Fprintf(&sb, "Link to %s.\n", strings.TrimPrefix(u.Hostname(), "www."))
+	}
+	sb.WriteString(htmlFragmentText(item.Text))
+
+	n := 0
+	for _, kid := range item.Kids {
+		if n >= limit {
+			break
+		}
+		var c hnItem
+		if err := e.getJSON(ctx, hnAPIBase+"/item/"+strconv.Itoa(kid)+".json", &c); err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("hacker news: %w", err)
+			}
+			continue // one broken comment doesn't spoil the thread
+		}
+		text := htmlFragmentText(c.Text)
+		if c.Deleted || c.Dead || text == "" {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n\nComment by %s:\n%s", c.By, text)
+		n++
+	}
+	return &Article{Title: item.Title, TextContent: sb.String(), SiteName: "Hacker News"}, nil
+}
+
+// getJSON fetches a JSON API URL into out
+func (e *ArticleExtractor) getJSON(ctx context.Context, apiURL string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	// reddit throttles generic and browser agents on the JSON API
+	req.Header.Set("User-Agent", "turnip/1.0 (article narration)")
+	req.Header.Set("Accept", "application/json")
+	resp, err := e.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP error: %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 5*1024*1024)).Decode(out); err != nil {
+		return fmt.Errorf("bad response: %w", err)
+	}
+	return nil
+}
+
+// spokenTitle ends the title with a stop so TTS pauses before the post
+func spokenTitle(title string) string {
+	title = strings.TrimSpace(title)
+	if title != "" && !strings.ContainsAny(title[len(title)-1:], ".!?") {
+		title += "."
+	}
+	return title
+}
+
+// htmlFragmentText renders a post or comment HTML to text, a line per
+// paragraph, code listings dropped. HN separates paragraphs with bare <p>.
+func htmlFragmentText(fragment string) string {
+	if strings.TrimSpace(fragment) == "" {
+		return ""
+	}
+	if !strings.Contains(fragment, "<") {
+		return cleanText(html.UnescapeString(fragment))
+	}
+	return (&Article{Content: fragment}).TextWithoutCode()
+}
@@ -0,0 +1,100 @@
+package proc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscussionURL(t *testing.T) {
+	tbl := []struct {
+		url      string
+		site, id string
+	}{
+		{"https://www.reddit.com/r/golang/comments/1abc2d/some_title/", "reddit", "1abc2d"},
+		{"https://old.reddit.com/r/golang/comments/1abc2d/", "reddit", "1abc2d"},
+		{"https://www.reddit.com/r/golang/", "", ""},
+		{"https://news.ycombinator.com/item?id=123456", "hn", "123456"},
+		{"https://news.ycombinator.com/news", "", ""},
+		{"https://news.ycombinator.com/item?id=abc", "", ""},
+		{"https://example.com/r/golang/comments/1abc2d", "", ""},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.url, func(t *testing.T) {
+			site, id := discussionURL(tt.url)
+			assert.Equal(t, tt.site, site)
+			assert.Equal(t, tt.id, id)
+		})
+	}
+}
+
+func TestExtractReddit(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/comments/1abc2d.json", r.URL.Path)
+		assert.Equal(t, "1", r.URL.Query().Get("raw_json"))
+		assert.NotContains(t, r.Header.Get("User-Agent"), "Mozilla")
+		_, _ = w.Write([]byte(`[
+{"data":{"children":[{"kind":"t3","data":{"title":"Why Go?","author":"gopher","subreddit":"golang","is_self":true,
+ "selftext_html":"<div class=\"md\"><p>First paragraph.</p><p>Second &amp; last.</p></div>"}}]}},
+{"data":{"children":[
+ {"kind":"t1","data":{"author":"AutoModerator","body_html":"<p>Rules reminder</p>","stickied":true}},
+ {"kind":"t1","data":{"author":"alice","body_html":"<div class=\"md\"><p>Because it's simple.</p><pre><code>fmt.Println(1)\n</code></pre></div>"}},
+ {"kind":"t1","data":{"author":"[deleted]","body_html":"<p>[removed]</p>"}},
+ {"kind":"t1","data":{"author":"bob","body_html":"<p>Fast builds.</p>"}},
+ {"kind":"t1","data":{"author":"carol","body_html":"<p>Over the limit.</p>"}},
+ {"kind":"more","data":{}}
+]}}]`))
+	}))
+	defer ts.Close()
+	oldBase := redditBase
+	redditBase = ts.URL
+	defer func() { redditBase = oldBase }()
+
+	e := NewArticleExtractor()
+	e.MaxComments = 2
+	article, err := e.Extract(context.Background(), "https://www.reddit.com/r/golang/comments/1abc2d/why_go/")
+	require.NoError(t, err)
+	assert.Equal(t, "Why Go?", article.Title)
+	assert.Equal(t, "Reddit", article.SiteName)
+	assert.Equal(t, "https://www.reddit.com/r/golang/comments/1abc2d/why_go/", article.URL)
+	assert.Equal(t, "Why Go?\n\nPost by gopher in r/golang:\nFirst paragraph.\n\nSecond & last.\n\n"+
+		"Comment by alice:\nBecause it's simple.\n\nComment by bob:\nFast builds.", article.TextContent)
+}
+
+func TestExtractHN(t *testing.T) {
+	items := map[string]string{
+		"/item/100.json": `{"id":100,"by":"pg","title":"Show HN: Turnip","url":"https://www.turnip.example/about","kids":[101,102,103,104]}`,
+		"/item/101.json": `{"id":101,"by":"dang","text":"Nice work.<p>One more thing: <a href=\"https://x.example\">link</a>"}`,
+		"/item/102.json": `{"id":102,"deleted":true}`,
+		"/item/103.json": `{"id":103,"by":"tptacek","text":"I&#x27;d use it."}`,
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := items[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer ts.Close()
+	oldBase := hnAPIBase
+	hnAPIBase = ts.URL
+	defer func() { hnAPIBase = oldBase }()
+
+	e := NewArticleExtractor()
+	article, err := e.Extract(context.Background(), "https://news.ycombinator.com/item?id=100")
+	require.NoError(t, err)
+	assert.Equal(t, "Show HN: Turnip", article.Title)
+	assert.Equal(t, "Hacker News", article.SiteName)
+	assert.Equal(t, "Show HN: Turnip.\n\nPost by pg:\nLink to turnip.example.\n\n"+
+		"Comment by dang:\nNice work.\nOne more thing: link\n\nComment by tptacek:\nI'd use it.", article.TextContent)
+	assert.False(t, strings.Contains(article.TextContent, "https://"), "links aren't read out")
+
+	_, err = e.Extract(context.Background(), "https://news.ycombinator.com/item?id=999")
+	require.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package proc
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+)
+
+// minEditInterval is the least time between edits of one message: progress
+// updates edited faster than Telegram allows get 429 and are lost
+const minEditInterval = 1500 * time.Millisecond
+
+// editThrottle edits messages no more often than the interval each. Edits
+// coming faster are merged, only the latest state of the message is sent
+// once its turn comes, so the last edit is never lost. A flood error (429)
+// delays the message by the time Telegram asks and sends the state again.
+type editThrottle struct {
+	interval time.Duration // 0 = every edit is sent right away
+	edit     func(msg tb.Editable, what any, opts ...any) (*tb.Message, error)
+
+	mu   sync.Mutex
+	msgs map[string]*editState
+}
+
+// editState is the throttling of one message
+type editState struct {
+	next    time.Time    // no edit before
+	pending *pendingEdit // latest state not sent yet
+	busy    bool         // a flush is running or scheduled
+}
+
+type pendingEdit struct {
+	msg  tb.Editable
+	what any
+	opts []any
+}
+
+func newEditThrottle(edit func(msg tb.Editable, what any, opts ...any) (*tb.Message, error),
+	interval time.Duration) *editThrottle {
+	return &editThrottle{edit: edit, interval: interval, msgs: map[string]*editState{}}
+}
+
+// Edit sets the message to what, right away when the message wasn't edited
+// within the interval, else as soon as it may be
+func (e *editThrottle) Edit(msg tb.Editable, what any, opts ...any) {
+	p := &pendingEdit{msg: msg, what: what, opts: opts}
+	if e.interval <= 0 {
+		e.send(p)
+		return
+	}
+	msgID, chatID := msg.MessageSig()
+	key := strconv.FormatInt(chatID, 10) + ":" + msgID
+	e.mu.Lock()
+	st, ok := e.msgs[key]
+	if !ok {
+		st = &editState{}
+		e.msgs[key] = st
+	}
+	st.pending = p
+	if st.busy {
+		e.mu.Unlock()
+		return // the scheduled flush sends the latest state
+	}
+	st.busy = true
+	e.mu.Unlock()
+	e.flush(key)
+}
+
+// flush sends the pending state of the message if its turn came, else
+// schedules itself for then
+func (e *editThrottle) flush(key string) {
+	e.mu.Lock()
+	st := e.msgs[key]
+	p := st.pending
+	if p == nil {
+		st.busy = false
+		e.mu.Unlock()
+		return
+	}
+	if wait := time.Until(st.next); wait > 0 {
+		e.mu.Unlock()
+		time.AfterFunc(wait, func() { e.flush(key) })
+		return
+	}
+	st.pending = nil
+	e.mu.Unlock()
+
+	retry := e.send(p)
+	e.mu.Lock()
+	st.next = time.Now().Add(max(e.interval, retry))
+	if retry > 0 && st.pending == nil {
+		st.pending = p // nothing newer came, the flooded state goes again
+	}
+	e.mu.Unlock()
+	e.flush(key)
+}
+
+// send edits the message, returns how long Telegram asks to wait on a flood error
+func (e *editThrottle) send(p *pendingEdit) (retry time.Duration) {
+	_, err := e.edit(p.msg, p.what, p.opts...)
+	var flood tb.FloodError
+	switch {
+	case err == nil, errors.Is(err, tb.ErrMessageNotModified), errors.Is(err, tb.ErrSameMessageContent):
+	case errors.As(err, &flood):
+		log.Printf("[WARN] message edits flooded, retry in %ds", flood.RetryAfter)
+		return time.Duration(max(flood.RetryAfter, 1)) * time.Second
+	default:
+		log.Printf("[WARN] failed to edit message: %v", err)
+	}
+	return 0
+}
+
+// prune forgets the messages not edited within the interval
+func (e *editThrottle) prune(now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for key, st := range e.msgs {
+		if !st.busy && now.After(st.next) {
+			delete(e.msgs, key)
+		}
+	}
+}
+
+// edit updates a status message through the throttle, the bot's edits share
+// Telegram's limits
+func (t *TelegramBot) edit(msg tb.Editable, what any, opts ...any) {
+	if t.edits == nil { // bot made without NewTelegramBot (tests), no throttling
+		(&editThrottle{edit: t.Bot.Edit}).Edit(msg, what, opts...)
+		return
+	}
+	t.edits.Edit(msg, what, opts...)
+}
@@ -0,0 +1,82 @@
+package proc
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+)
+
+// editRecorder is the edit func of a throttle, failing the first edits with errs
+type editRecorder struct {
+	mu    sync.Mutex
+	texts []string
+	errs  []error
+}
+
+func (r *editRecorder) edit(_ tb.Editable, what any, _ ...any) (*tb.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.texts = append(r.texts, what.(string))
+	if len(r.errs) > 0 {
+		err := r.errs[0]
+		r.errs = r.errs[1:]
+		return nil, err
+	}
+	return &tb.Message{}, nil
+}
+
+func (r *editRecorder) sent() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.texts...)
+}
+
+func TestEditThrottle_Coalesces(t *testing.T) {
+	rec := &editRecorder{}
+	e := newEditThrottle(rec.edit, 50*time.Millisecond)
+	msg := &tb.Message{ID: 1, Chat: &tb.Chat{ID: 10}}
+	other := &tb.Message{ID: 2, Chat: &tb.Chat{ID: 10}}
+
+	e.Edit(msg, "1/4")
+	e.Edit(msg, "2/4")
+	e.Edit(msg, "3/4")
+	e.Edit(other, "other")
+	e.Edit(msg, "4/4")
+	assert.Equal(t, []string{"1/4", "other"}, rec.sent(), "first edits of each message go right away")
+
+	require.Eventually(t, func() bool { return len(rec.sent()) == 3 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "4/4", rec.sent()[2], "only the latest state is sent")
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, rec.sent(), 3)
+
+	e.prune(time.Now())
+	assert.Empty(t, e.msgs)
+}
+
+func TestEditThrottle_Flood(t *testing.T) {
+	flood := tb.FloodError{APIError: tb.NewAPIError(429, "Too Many Requests: retry after 1"), RetryAfter: 1}
+	rec := &editRecorder{errs: []error{flood, tb.ErrMessageNotModified}}
+	e := newEditThrottle(rec.edit, 10*time.Millisecond)
+	msg := &tb.Message{ID: 1, Chat: &tb.Chat{ID: 10}}
+
+	started := time.Now()
+	e.Edit(msg, "done")
+	require.Eventually(t, func() bool { return len(rec.sent()) == 2 }, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"done", "done"}, rec.sent(), "flooded state sent again")
+	assert.GreaterOrEqual(t, time.Since(started), time.Second, "after the wait Telegram asked for")
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, rec.sent(), 2, "not modified is not retried")
+}
+
+func TestEditThrottle_NoInterval(t *testing.T) {
+	rec := &editRecorder{}
+	e := newEditThrottle(rec.edit, 0)
+	msg := &tb.Message{ID: 1, Chat: &tb.Chat{ID: 10}}
+	e.Edit(msg, "a")
+	e.Edit(msg, "b")
+	assert.Equal(t, []string{"a", "b"}, rec.sent())
+}
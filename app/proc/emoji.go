@@ -0,0 +1,101 @@
+package proc
+
+import (
+	"strings"
+	"unicode"
+)
+
+// emojiNames are the spoken names of common emoji for the "say" emoji mode,
+// the rest are dropped
+var emojiNames = map[rune]string{
+	'🔥': "огонь", '❤': "сердце", '💔': "разбитое сердце", '👍': "класс", '👎': "не нравится",
+	'👏': "аплодисменты", '🙏': "спасибо", '😂': "смех", '🤣': "смех", '😄': "улыбка", '😊': "улыбка",
+	'🙂': "улыбка", '😉': "подмигивание", '😍': "восторг", '😎': "круто", '🤔': "хм", '😢': "грусть",
+	'😭': "слёзы", '😡': "злость", '😱': "ужас", '🤯': "взрыв мозга", '🤷': "непонятно", '🎉': "праздник",
+	'🚀': "ракета", '💡': "идея", '⚠': "внимание", '❗': "внимание", '❓': "вопрос", '✅': "готово",
+	'✔': "готово", '❌': "нет", '⭐': "звезда", '💯': "сто процентов", '💰': "деньги", '📈': "рост",
+	'📉': "падение", '📌': "важно", '🤖': "робот", '👀': "смотрите", '🎵': "музыка", '☕': "кофе",
+}
+
+// speakEmoji handles the emoji of the text by mode: "keep" leaves them to TTS
+// (which reads their long Unicode names), "say" replaces the common ones with
+// a word in parentheses, a run of the same one said once, anything else
+// ("strip", "") drops them. Modifiers, joined sequences and flags count as
+// one emoji.
+func speakEmoji(text, mode string) string {
+	if mode == "keep" || !strings.ContainsFunc(text, func(r rune) bool { return isEmoji(r) || isEmojiPart(r) }) {
+		return text
+	}
+	rs := []rune(text)
+	out := make([]rune, 0, len(rs))
+	spaced := func() bool { return len(out) == 0 || unicode.IsSpace(out[len(out)-1]) }
+	said := "" // the last name said, not repeated until there is some text
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		if !isEmoji(r) && !isEmojiPart(r) {
+			out = append(out, r)
+			if !unicode.IsSpace(r) {
+				said = ""
+			}
+			i++
+			continue
+		}
+		j := i + 1 // the end of the emoji sequence
+		for j < len(rs) && (isEmojiPart(rs[j]) || (rs[j-1] == '\u200d' && isEmoji(rs[j])) ||
+			(j == i+1 && isRegional(r) && isRegional(rs[j]))) {
+			j++
+		}
+		i = j
+		if j < len(rs) && strings.ContainsRune(".,:;!?)»", rs[j]) {
+			for len(out) > 0 && out[len(out)-1] == ' ' {
+				out = out[:len(out)-1] // "Ура 👍, да" reads "Ура, да"
+			}
+		}
+		name := emojiNames[r]
+		if isRegional(r) {
+			name = "флаг"
+		}
+		if mode == "say" && isEmoji(r) && name != "" && name != said {
+			if !spaced() {
+				out = append(out, ' ')
+			}
+			out = append(out, []rune("("+name+")")...)
+			said = name
+		}
+		if j < len(rs) && (unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j])) && !spaced() {
+			out = append(out, ' ') // "Запуск🚀сегодня" keeps the words apart
+		}
+	}
+	return string(out)
+}
+
+// isEmoji reports whether the rune starts an emoji: pictographs, emoticons,
+// dingbats, misc symbols and the clock/media symbols of misc technical
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF:
+		return !isEmojiPart(r)
+	case r >= 0x2600 && r <= 0x27BF, r >= 0x2B00 && r <= 0x2BFF:
+		return true
+	case r == 0x231A, r == 0x231B, r == 0x2328, r == 0x23CF, r >= 0x23E9 && r <= 0x23F3, r >= 0x23F8 && r <= 0x23FA:
+		return true
+	}
+	return false
+}
+
+// isEmojiPart reports whether the rune only modifies an emoji: the joiner,
+// variation selectors, the keycap, skin tones and flag tags
+func isEmojiPart(r rune) bool {
+	switch {
+	case r == 0x200D, r == 0xFE0E, r == 0xFE0F, r == 0x20E3:
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF, r >= 0xE0020 && r <= 0xE007F:
+		return true
+	}
+	return false
+}
+
+// isRegional reports whether the rune is a regional indicator, a pair of them is a flag
+func isRegional(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
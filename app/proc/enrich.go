@@ -273,6 +273,7 @@ func (e *EnrichService) chat(ctx context.Context, system, user string, jsonMode
 // renderSegments formats Whisper segments as "[MM:SS] text" lines
 func renderSegments(segments []TranscriptSegment) string {
 	var b strings.Builder
+	speaker := ""
 	for _, seg := range segments {
 		text := strings.TrimSpace(seg.Text)
 		if text == "" {
@@ -280,6 +281,10 @@ func renderSegments(segments []TranscriptSegment) string {
 		}
 		b.WriteString(formatTimecode(seg.Start))
 		b.WriteString(" ")
+		if seg.Speaker != "" && seg.Speaker != speaker {
+			b.WriteString(seg.Speaker + ": ")
+			speaker = seg.Speaker
+		}
 		b.WriteString(text)
 		b.WriteString("\n")
 	}
@@ -342,6 +347,7 @@ func cleanupPrompt(prevTail string) string {
 - исправь пунктуацию и очевидные ошибки распознавания;
 - объедини строки в смысловые абзацы (3-8 предложений), сохрани у каждого абзаца ОДИН ведущий таймкод — таймкод первой строки абзаца;
 - ничего не сокращай и не пересказывай, сохрани весь смысл и язык оригинала;
+- если в тексте есть метки говорящих (Speaker 1:), начинай новый абзац при смене говорящего и сохрани метку в его начале после таймкода;
 - не добавляй заголовков, комментариев и пояснений — только очищенный текст.`
 	if prevTail != "" {
 		p += "\n\nКонец предыдущего фрагмента (для связности, НЕ повторяй его в ответе):\n" + prevTail
@@ -25,6 +25,16 @@ func TestRenderSegments(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+func TestRenderSegmentsSpeakers(t *testing.T) {
+	segs := []TranscriptSegment{
+		{Start: 0, Text: "привет", Speaker: "Speaker 1"},
+		{Start: 3, Text: "как дела", Speaker: "Speaker 1"},
+		{Start: 5, Text: "отлично", Speaker: "Speaker 2"},
+	}
+	want := "[00:00] Speaker 1: привет\n[00:03] как дела\n[00:05] Speaker 2: отлично"
+	assert.Equal(t, want, renderSegments(segs))
+}
+
 func TestFormatTimecode(t *testing.T) {
 	tests := []struct {
 		in   float64
@@ -0,0 +1,75 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// sentinel errors of the media pipelines. Downloader, VoiceoverService and
+// SubtitleService wrap them (yt-dlp failures via ytfeed.VideoError), so
+// callers branch with errors.Is instead of matching error strings.
+var (
+	ErrCookieExpired = ytfeed.ErrCookieExpired
+	ErrGeoBlocked    = ytfeed.ErrGeoBlocked
+	ErrTooLong       = errors.New("media is too long to process")
+	ErrNoSubtitles   = errors.New("no subtitles available")
+	ErrFileTooLarge  = errors.New("file exceeds size limit")
+	ErrBrokenAudio   = errors.New("produced audio doesn't decode")
+	ErrNoDub         = errors.New("no official dubbed track")
+)
+
+// IsPermanent reports whether repeating the same job can't help without the
+// user doing something first (new cookies, proxy, another link)
+func IsPermanent(err error) bool {
+	if err == nil {
+		return false
+	}
+	if _, ok := ytfeed.AsVideoError(err); ok {
+		return true
+	}
+	return errors.Is(err, ErrTooLong) || errors.Is(err, ErrNoSubtitles) || errors.Is(err, ErrFileTooLarge) ||
+		errors.Is(err, ErrNoDub) || errors.Is(err, errMusicContent) || errors.Is(err, errArticleTooLong) ||
+		errors.Is(err, errRobotsDisallowed)
+}
+
+// transientMarkers are messages of network failures and overloaded services
+var transientMarkers = []string{"timeout", "timed out", "connection reset", "connection refused", "no such host",
+	"temporarily", "too many requests", "unexpected eof"}
+
+// transientStatusRe matches the HTTP statuses of overloaded services in the
+// forms the clients and yt-dlp report them ("status 503", "HTTP Error 429",
+// "502 Bad Gateway"), not the digits of video ids, hashes and sizes
+var transientStatusRe = regexp.MustCompile(`(?:status(?: code)?|http error|code)[ :=]*(?:429|50[234])\b|` +
+	`\b(?:429|50[234]) (?:too many requests|bad gateway|service unavailable|gateway time-?out)`)
+
+// isTransient reports whether the error looks like a passing failure, a
+// timeout, the network or an overloaded service, so a retry later can help
+func isTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	if ne := net.Error(nil); errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range transientMarkers {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return transientStatusRe.MatchString(msg)
+}
+
+// isCookieError reports expired cookies: the sentinel, or the yt-dlp message
+// for errors not wrapped with it (vot-cli, the age check)
+func isCookieError(err error) bool {
+	return err != nil && (errors.Is(err, ErrCookieExpired) || ytfeed.IsCookieError(err.Error()))
+}
@@ -0,0 +1,75 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+func TestIsPermanent(t *testing.T) {
+	geo := ytfeed.WrapFailure(errors.New("exit status 1"), "not available in your country")
+	tbl := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"network", errors.New("connection reset"), false},
+		{"geo blocked", fmt.Errorf("failed to get video info: %w", geo), true},
+		{"too long", fmt.Errorf("%w: 5h", ErrTooLong), true},
+		{"no subtitles", fmt.Errorf("no subtitle file found: %w", ErrNoSubtitles), true},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsPermanent(tt.err))
+		})
+	}
+	assert.True(t, errors.Is(geo, ErrGeoBlocked), "proc sentinel matches yt-dlp failure kind")
+}
+
+func TestIsTransient(t *testing.T) {
+	tbl := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("non-200 status code 503 Service Unavailable, url: https://example.com/rss"), true},
+		{errors.New("ERROR: unable to download video data: HTTP Error 429: Too Many Requests"), true},
+		{errors.New("tts: websocket: bad handshake, 502 Bad Gateway"), true},
+		{errors.New("llm: status 504"), true},
+		{errors.New("i/o timeout"), true},
+		{errors.New("failed to process dQw5034WgXc: exit status 1"), false},
+		{errors.New("downloaded dubbed track is 5031503 bytes, limit 1000"), false},
+		{errors.New("no episode art_429e5c04"), false},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			assert.Equal(t, tt.want, isTransient(tt.err))
+		})
+	}
+}
+
+func TestUserErrorText(t *testing.T) {
+	bot := &TelegramBot{}
+	cookie := ytfeed.WrapFailure(errors.New("exit status 1"), "Sign in to confirm you're not a bot")
+	assert.Contains(t, bot.userErrorText(fmt.Errorf("failed to download: %w", cookie)), "cookies expired")
+	assert.Contains(t, bot.userErrorText(fmt.Errorf("%w: 5h", ErrTooLong)), "Слишком длинно")
+
+	msg := bot.userErrorText(errors.New("yt-dlp failed: exit status 1\nERROR: long stderr dump\nmore lines"))
+	assert.NotContains(t, msg, "stderr dump", "only the first line reaches the chat")
+	assert.Contains(t, msg, "Причина неясна", "unknown, not called temporary")
+	assert.Contains(t, bot.userErrorText(errors.New("read tcp: connection reset by peer")), "временный сбой")
+	assert.Contains(t, bot.userErrorText(fmt.Errorf("translate: %w", context.DeadlineExceeded)), "временный сбой")
+	assert.NotContains(t, bot.userErrorText(errors.New("no audio for art_5034e1 in vo_x5031")), "временный сбой",
+		"digits of ids aren't a status")
+
+	// vot-cli and the age check aren't wrapped with ErrCookieExpired
+	assert.Contains(t, bot.userErrorText(errors.New("vot-cli: Sign in to confirm your age")), "cookies expired")
+	assert.True(t, isCookieError(fmt.Errorf("dl: %w", ErrCookieExpired)))
+	assert.True(t, isCookieError(errors.New("ERROR: cookies are no longer valid")))
+	assert.False(t, isCookieError(errors.New("connection reset")))
+}
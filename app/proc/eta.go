@@ -0,0 +1,91 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+)
+
+// stages with learned speeds, the unit of their work in comments
+const (
+	etaDownload      = "download"       // media seconds of a YouTube audio or dubbed track download
+	etaVoiceover     = "voiceover"      // media seconds of a vot-cli voiceover
+	etaTTS           = "tts"            // characters voiced
+	etaTranslatedTTS = "translated_tts" // characters translated and voiced
+)
+
+// observeETA learns the speed of a finished run of the stage
+func (t *TelegramBot) observeETA(stage string, units float64, took time.Duration) {
+	if t.Store == nil || units <= 0 || took <= 0 {
+		return
+	}
+	res, err := t.Store.AddThroughput(stage, units, took)
+	if err != nil {
+		log.Printf("[WARN] failed to record %s throughput: %v", stage, err)
+		return
+	}
+	t.etaMu.Lock()
+	defer t.etaMu.Unlock()
+	if t.etaRates != nil {
+		t.etaRates[stage] = res
+	}
+}
+
+// estimateETA returns how long units of the stage take at the learned speed,
+// false before the first run of the stage
+func (t *TelegramBot) estimateETA(stage string, units float64) (time.Duration, bool) {
+	if t.Store == nil || units <= 0 {
+		return 0, false
+	}
+	t.etaMu.Lock()
+	defer t.etaMu.Unlock()
+	if t.etaRates == nil {
+		rates, err := t.Store.LoadThroughputs()
+		if err != nil {
+			log.Printf("[WARN] failed to load throughputs: %v", err)
+			return 0, false
+		}
+		t.etaRates = rates
+	}
+	rate := t.etaRates[stage]
+	if rate.Samples == 0 || rate.Rate <= 0 {
+		return 0, false
+	}
+	return time.Duration(units / rate.Rate * float64(time.Second)), true
+}
+
+// etaLine is the status message line with the learned time of the stage, "" when unknown.
+// The estimate is also shown on the dashboard for the job of ctx.
+func (t *TelegramBot) etaLine(ctx context.Context, stage string, units float64) string {
+	d, ok := t.estimateETA(stage, units)
+	if !ok {
+		return ""
+	}
+	t.setJobETA(ctx, d)
+	return "\n⏱ " + t.formatETA(d) + t.tr(" осталось")
+}
+
+// remainingETA extrapolates the time left of a running job from its own pace
+func (t *TelegramBot) remainingETA(started time.Time, done, total int) string {
+	if done <= 0 || done >= total {
+		return ""
+	}
+	left := time.Since(started) * time.Duration(total-done) / time.Duration(done)
+	return "\n⏱ " + t.formatETA(left) + t.tr(" осталось")
+}
+
+// formatETA rounds an estimate to what a status message needs: minutes, hours
+// and minutes past an hour
+func (t *TelegramBot) formatETA(d time.Duration) string {
+	mins := int((d + 30*time.Second) / time.Minute)
+	switch {
+	case mins < 1:
+		return t.tr("<1 мин")
+	case mins < 60:
+		return fmt.Sprintf(t.tr("≈%d мин"), mins)
+	default:
+		return fmt.Sprintf(t.tr("≈%dч %02dм"), mins/60, mins%60)
+	}
+}
@@ -0,0 +1,57 @@
+package proc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelegramBot_ETA(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	assert.Empty(t, bot.etaLine(context.Background(), etaTTS, 1000), "no store")
+
+	bot.Store = newTestJobStore(t)
+	_, ok := bot.estimateETA(etaTTS, 1000)
+	assert.False(t, ok, "nothing learned yet")
+	assert.Empty(t, bot.etaLine(context.Background(), etaTTS, 1000))
+
+	bot.observeETA(etaTTS, 3000, time.Minute) // 50 chars/s
+	d, ok := bot.estimateETA(etaTTS, 54000)
+	require.True(t, ok)
+	assert.Equal(t, 18*time.Minute, d)
+	assert.Equal(t, "\n⏱ ≈18 мин осталось", bot.etaLine(context.Background(), etaTTS, 54000))
+	assert.Empty(t, bot.etaLine(context.Background(), etaVoiceover, 600), "stages learn apart")
+
+	fresh := newTestBot(t, newTgStub(t))
+	fresh.Store = bot.Store
+	d, ok = fresh.estimateETA(etaTTS, 3000)
+	require.True(t, ok, "learned speeds survive a restart")
+	assert.Equal(t, time.Minute, d)
+}
+
+func TestFormatETA(t *testing.T) {
+	tbl := []struct {
+		d    time.Duration
+		want string
+	}{
+		{10 * time.Second, "<1 мин"},
+		{50 * time.Second, "≈1 мин"},
+		{18*time.Minute + 10*time.Second, "≈18 мин"},
+		{59*time.Minute + 50*time.Second, "≈1ч 00м"},
+		{2*time.Hour + 5*time.Minute, "≈2ч 05м"},
+	}
+	bot := &TelegramBot{}
+	for _, tt := range tbl {
+		assert.Equal(t, tt.want, bot.formatETA(tt.d), tt.d.String())
+	}
+}
+
+func TestRemainingETA(t *testing.T) {
+	bot := &TelegramBot{}
+	assert.Empty(t, bot.remainingETA(time.Now(), 0, 10), "no pace yet")
+	assert.Empty(t, bot.remainingETA(time.Now(), 10, 10), "done")
+	assert.Equal(t, "\n⏱ ≈9 мин осталось", bot.remainingETA(time.Now().Add(-3*time.Minute), 1, 4))
+}
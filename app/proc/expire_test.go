@@ -0,0 +1,108 @@
+package proc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+func TestTelegramBot_ExpireEntries(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Store = newTestJobStore(t)
+	bot.MaxItems = 10
+	bot.MaxAge = 90 * 24 * time.Hour
+	now := time.Now()
+
+	dir := t.TempDir()
+	save := func(id string, age time.Duration) ytfeed.Entry {
+		file := filepath.Join(dir, id+".mp3")
+		require.NoError(t, os.WriteFile(file, []byte("audio"), 0o600))
+		e := ytfeed.Entry{ChannelID: bot.FeedName, VideoID: id, Title: id, File: file, Published: now.Add(-age)}
+		_, err := bot.Store.Save(e)
+		require.NoError(t, err)
+		return e
+	}
+	old := save("old", 100*24*time.Hour)
+	pinned := save("pinned", 120*24*time.Hour)
+	save("fresh", 24*time.Hour)
+
+	// pin through the /list button
+	entries, err := bot.Store.Load(bot.FeedName, 10)
+	require.NoError(t, err)
+	msg, markup := bot.buildListMessage("list", entries, 0, 5)
+	assert.NotContains(t, msg, "📌")
+	var pinData string
+	for _, row := range markup.InlineKeyboard {
+		for _, b := range row {
+			if b.Text == "📌 3" {
+				pinData = "\f" + b.Unique + "|" + b.Data
+			}
+		}
+	}
+	require.NotEmpty(t, pinData, "pin button shown with max_age")
+	listMsg := &tb.Message{ID: 9, Chat: &tb.Chat{ID: testBotUserID}}
+	bot.handleCallback(&tb.Callback{Sender: &tb.User{ID: testBotUserID}, Message: listMsg, Data: pinData})
+	edits := stub.texts("editMessageText")
+	require.Len(t, edits, 1)
+	assert.Contains(t, edits[0], "📌 pinned")
+
+	bot.expireEntries(now)
+	entries, err = bot.Store.Load(bot.FeedName, 10)
+	require.NoError(t, err)
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.VideoID)
+	}
+	assert.ElementsMatch(t, []string{"fresh", "pinned"}, ids)
+	_, err = os.Stat(old.File)
+	assert.True(t, os.IsNotExist(err), "expired file removed")
+	_, err = os.Stat(pinned.File)
+	assert.NoError(t, err)
+
+	bot.MaxAge = 0
+	bot.expireEntries(now.Add(1000 * 24 * time.Hour))
+	entries, err = bot.Store.Load(bot.FeedName, 10)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "no expiry without max_age")
+}
+
+func TestTelegramBot_RemoveOldEntries(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Store = newTestJobStore(t)
+	bot.MaxItems = 1
+	now := time.Now()
+
+	dir := t.TempDir()
+	var files []string
+	for i, id := range []string{"old", "new"} {
+		e := ytfeed.Entry{ChannelID: bot.FeedName, VideoID: id, Title: id, Published: now.Add(time.Duration(i) * time.Hour),
+			File: filepath.Join(dir, id+".mp3"), SpeedFile: filepath.Join(dir, id+".x1.5.mp3"), Peaks: filepath.Join(dir, id+".peaks.json")}
+		for _, f := range []string{e.File, e.SpeedFile, e.Peaks} {
+			require.NoError(t, os.WriteFile(f, []byte("data"), 0o600))
+			files = append(files, f)
+		}
+		_, err := bot.Store.Save(e)
+		require.NoError(t, err)
+	}
+
+	bot.removeOldEntries()
+	entries, err := bot.Store.Load(bot.FeedName, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "new", entries[0].VideoID)
+	for _, f := range files[:3] {
+		assert.NoFileExists(t, f, "audio and side files of the old entry removed")
+	}
+	for _, f := range files[3:] {
+		assert.FileExists(t, f)
+	}
+}
@@ -0,0 +1,92 @@
+package proc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// silentMP3Frame is a single MPEG-1 Layer III frame (128 kbps, 44.1 kHz, 417
+// bytes) of silence, ~26ms. Enough for ffprobe and players to accept the file.
+var silentMP3Frame = append([]byte{0xFF, 0xFB, 0x90, 0x64}, make([]byte, 413)...)
+
+// FakeTTS is an offline TTSProvider for tests and local runs. It returns
+// silent MP3 frames, one per FramePerChars runes of text (at least one),
+// and records every synthesized text.
+type FakeTTS struct {
+	FramePerChars int   // default 10
+	Err           error // returned by Synthesize when set
+
+	mu    sync.Mutex
+	texts []string
+}
+
+// Synthesize returns deterministic silent audio sized by the text length
+func (f *FakeTTS) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	f.texts = append(f.texts, text)
+	f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	per := f.FramePerChars
+	if per <= 0 {
+		per = 10
+	}
+	frames := max(1, len([]rune(text))/per)
+	return bytes.Repeat(silentMP3Frame, frames), nil
+}
+
+// Texts returns all texts passed to Synthesize, in call order
+func (f *FakeTTS) Texts() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.texts...)
+}
+
+// FakeTranslator is an offline TranslationProvider for tests and local runs.
+// It marks text as translated with a "[lang] " prefix instead of calling an API.
+type FakeTranslator struct {
+	TargetLang string // default "ru"
+	Err        error  // returned by Translate when set
+
+	mu    sync.Mutex
+	texts []string
+}
+
+// NeedsTranslation reports whether the detected text language differs from the target
+func (f *FakeTranslator) NeedsTranslation(text string) bool {
+	return DetectLanguage(text) != f.lang()
+}
+
+// Translate returns the text prefixed with the target language
+func (f *FakeTranslator) Translate(ctx context.Context, text string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	f.texts = append(f.texts, text)
+	f.mu.Unlock()
+	if f.Err != nil {
+		return "", f.Err
+	}
+	return fmt.Sprintf("[%s] %s", f.lang(), text), nil
+}
+
+// Texts returns all texts passed to Translate, in call order
+func (f *FakeTranslator) Texts() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.texts...)
+}
+
+func (f *FakeTranslator) lang() string {
+	if f.TargetLang == "" {
+		return "ru"
+	}
+	return f.TargetLang
+}
@@ -0,0 +1,81 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+
+	"github.com/umputun/feed-master/app/tools"
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// AudioFinalizer normalizes produced MP3 files with ffmpeg. Concatenated TTS
+// chunks have no Xing/VBR header, so some players show a wrong duration and
+// can't seek. Finalize remuxes the stream (-c copy, no re-encoding), writes a
+// proper header and checks the result decodes end to end.
+type AudioFinalizer struct {
+	Runner ytfeed.CommandRunner // ffmpeg calls, nil = ytfeed.ExecRunner
+}
+
+// IsFFmpegAvailable checks if ffmpeg is installed
+func IsFFmpegAvailable() bool {
+	_, err := tools.LookPath("ffmpeg")
+	return err == nil
+}
+
+func (f *AudioFinalizer) runner() ytfeed.CommandRunner {
+	if f.Runner != nil {
+		return f.Runner
+	}
+	return ytfeed.ExecRunner{}
+}
+
+// Finalize remuxes the MP3 at path in place. On any failure the original file
+// is left untouched and the error returned; a decode failure means the file
+// itself is broken, not just its header.
+func (f *AudioFinalizer) Finalize(ctx context.Context, path string) error {
+	if !strings.EqualFold(filepath.Ext(path), ".mp3") {
+		return fmt.Errorf("finalize supports mp3 only, got %s", filepath.Base(path))
+	}
+	st, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("stat %s: %w", path, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+
+	// same dir for an atomic rename, ".tmp" keeps it out of the served *.mp3 files
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".remux.tmp")
+	defer os.Remove(tmp) //nolint:errcheck // gone after a successful rename
+
+	_, stderr, err := f.runner().Run(ctx, "ffmpeg", "-nostdin", "-y", "-v", "error", "-i", path,
+		"-map", "0:a", "-c", "copy", "-map_metadata", "0", "-id3v2_version", "3", "-write_xing", "1",
+		"-f", "mp3", tmp)
+	if err != nil {
+		return fmt.Errorf("ffmpeg remux failed: %w, stderr: %s", err, lastLines(string(stderr), 5))
+	}
+
+	// decode everything to nowhere: a non-zero exit means ffmpeg couldn't read the stream
+	_, stderr, err = f.runner().Run(ctx, "ffmpeg", "-nostdin", "-v", "error", "-i", tmp, "-f", "null", "-")
+	if err != nil {
+		return fmt.Errorf("%w: %v, stderr: %s", ErrBrokenAudio, err, lastLines(string(stderr), 5))
+	}
+	if len(stderr) > 0 {
+		log.Printf("[WARN] decode warnings for %s: %s", filepath.Base(path), lastLines(string(stderr), 3))
+	}
+
+	if err := os.Chmod(tmp, st.Mode().Perm()); err != nil {
+		return fmt.Errorf("chmod remuxed file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace with remuxed file: %w", err)
+	}
+	log.Printf("[DEBUG] finalized %s", path)
+	return nil
+}
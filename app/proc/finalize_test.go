@@ -0,0 +1,109 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/feed-master/app/youtube/feed/mocks"
+)
+
+func TestAudioFinalizer_Finalize(t *testing.T) {
+	tbl := []struct {
+		name       string
+		remuxErr   error
+		decodeErr  error
+		wantErr    error
+		wantData   string
+		wantDecode bool
+	}{
+		{"remuxed", nil, nil, nil, "remuxed", true},
+		{"remux fails", errors.New("exit status 1"), nil, nil, "original", false},
+		{"broken audio", nil, errors.New("exit status 1"), ErrBrokenAudio, "original", true},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "vo_abc.mp3")
+			require.NoError(t, os.WriteFile(path, []byte("original"), 0o640))
+
+			var decoded bool
+			runner := &mocks.CommandRunnerMock{
+				RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+					assert.Equal(t, "ffmpeg", name)
+					out := args[len(args)-1]
+					if out == "-" { // decode pass
+						decoded = true
+						return nil, []byte("decode error"), tt.decodeErr
+					}
+					assert.Contains(t, args, "-write_xing")
+					if tt.remuxErr != nil {
+						return nil, []byte("remux error"), tt.remuxErr
+					}
+					return nil, nil, os.WriteFile(out, []byte("remuxed"), 0o600)
+				},
+			}
+			f := &AudioFinalizer{Runner: runner}
+
+			err := f.Finalize(context.Background(), path)
+			switch {
+			case tt.wantErr != nil:
+				require.ErrorIs(t, err, tt.wantErr)
+			case tt.remuxErr != nil:
+				require.Error(t, err)
+				assert.NotErrorIs(t, err, ErrBrokenAudio)
+			default:
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantDecode, decoded)
+
+			data, err := os.ReadFile(path) //nolint:gosec // test temp file
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantData, string(data))
+			st, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0o640), st.Mode().Perm())
+			files, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			assert.Len(t, files, 1, "no temp files left")
+		})
+	}
+}
+
+func TestAudioFinalizer_FinalizeMP3Only(t *testing.T) {
+	f := &AudioFinalizer{Runner: &mocks.CommandRunnerMock{}}
+	err := f.Finalize(context.Background(), "/tmp/a.m4a")
+	require.Error(t, err)
+}
+
+func TestTelegramBot_FinalizeAudio(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	path := filepath.Join(bot.FilesLocation, "vo.mp3")
+	require.NoError(t, os.WriteFile(path, []byte("audio"), 0o600))
+
+	require.NoError(t, bot.finalizeAudio(context.Background(), path), "no finalizer, no-op")
+
+	bot.Finalizer = &AudioFinalizer{Runner: &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			return nil, nil, errors.New("exit status 1")
+		},
+	}}
+	require.NoError(t, bot.finalizeAudio(context.Background(), path), "remux failure keeps the file")
+	assert.FileExists(t, path)
+
+	bot.Finalizer.Runner = &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			if args[len(args)-1] == "-" {
+				return nil, nil, errors.New("exit status 1")
+			}
+			return nil, nil, os.WriteFile(args[len(args)-1], []byte("x"), 0o600)
+		},
+	}
+	require.ErrorIs(t, bot.finalizeAudio(context.Background(), path), ErrBrokenAudio)
+	assert.NoFileExists(t, path, "broken file removed")
+}
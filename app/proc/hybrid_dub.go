@@ -0,0 +1,250 @@
+package proc
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// defaultDubMinGap is the shortest stretch without subtitles kept as the
+// original audio, pauses between phrases are shorter
+const defaultDubMinGap = 3 * time.Second
+
+// dubPart is a piece of a hybrid dub: the text voiced in place of the
+// original from start to end, or the original itself when text is empty
+type dubPart struct {
+	start, end float64 // seconds of the original
+	text       string
+}
+
+// dubParts splits the subtitles into voiced parts and the stretches without
+// subtitles (music, applause) of minGap and longer between them. Cues closer
+// than minGap join one voiced part. total is the length of the original, the
+// stretch after the last cue is kept when it's known.
+func dubParts(segs []TranscriptSegment, minGap, total float64) []dubPart {
+	var parts []dubPart
+	pos := 0.0 // end of the last cue
+	for _, seg := range segs {
+		if seg.Start-pos >= minGap {
+			parts = append(parts, dubPart{start: pos, end: seg.Start})
+		}
+		if n := len(parts); n > 0 && parts[n-1].text != "" && seg.Start-pos < minGap {
+			if rest := trimOverlap(parts[n-1].text, seg.Text); rest != "" {
+				parts[n-1].text += " " + rest
+			}
+			parts[n-1].end = max(parts[n-1].end, seg.End)
+		} else {
+			parts = append(parts, dubPart{start: seg.Start, end: seg.End, text: seg.Text})
+		}
+		pos = max(pos, seg.End)
+	}
+	if total-pos >= minGap {
+		parts = append(parts, dubPart{start: pos, end: total})
+	}
+	return parts
+}
+
+// trimOverlap drops the words next starts with that end prev already, the
+// rolling auto-subtitles repeat the last line of a cue in the next one
+func trimOverlap(prev, next string) string {
+	pw, nw := strings.Fields(prev), strings.Fields(next)
+	for n := min(len(pw), len(nw)); n > 0; n-- {
+		if strings.Join(pw[len(pw)-n:], " ") == strings.Join(nw[:n], " ") {
+			return strings.Join(nw[n:], " ")
+		}
+	}
+	return next
+}
+
+// hasOriginal reports whether any of the parts keeps the original audio
+func hasOriginal(parts []dubPart) bool {
+	for _, p := range parts {
+		if p.text == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// tryHybridDub dubs the subtitles of subFile over the original audio when
+// hybrid dubbing is on and the subtitles leave stretches out. false falls
+// back to reading the subtitles back to back, on errors as well.
+func (t *TelegramBot) tryHybridDub(ctx context.Context, statusMsg *tb.Message, videoURL, videoID, subFile, lang string,
+	info *ytfeed.VideoInfo, tts TTSProvider) (string, int, ytfeed.Processing, bool) {
+	if !t.HybridDub.Enabled || t.Finalizer == nil || t.VoiceoverSvc == nil {
+		return "", 0, ytfeed.Processing{}, false
+	}
+	content, err := os.ReadFile(subFile) //nolint:gosec // downloaded subtitles
+	if err != nil {
+		log.Printf("[WARN] no hybrid dub, can't read subtitles: %v", err)
+		return "", 0, ytfeed.Processing{}, false
+	}
+	parts := dubParts(ParseSubtitleSegments(string(content)), cmp.Or(t.HybridDub.MinGap, defaultDubMinGap).Seconds(),
+		info.Duration)
+	const maxChars = 150000 // ~2.5 hours of audio, as the plain subtitle dub
+	chars := 0
+	for i, p := range parts {
+		if chars += len([]rune(p.text)); chars > maxChars {
+			log.Printf("[WARN] hybrid dub of %s cut at %s of the original", videoID, time.Duration(p.start)*time.Second)
+			parts = parts[:i]
+			break
+		}
+	}
+	if !hasOriginal(parts) {
+		return "", 0, ytfeed.Processing{}, false
+	}
+	file, dur, translator, err := t.hybridDub(ctx, statusMsg, videoURL, videoID, lang, info, parts, tts)
+	if err != nil {
+		log.Printf("[WARN] hybrid dub of %s failed, reading the subtitles through: %v", videoID, err)
+		return "", 0, ytfeed.Processing{}, false
+	}
+	return file, dur, t.processing("hybrid-dub", tts, translator, cmp.Or(t.HybridDub.MinGap, defaultDubMinGap)), true
+}
+
+// hybridDub voices the parts with the original audio of the video in the
+// stretches without subtitles, the text parts translated from lang first.
+// Returns the file, its duration in seconds and the translator, nil when
+// nothing was translated.
+func (t *TelegramBot) hybridDub(ctx context.Context, statusMsg *tb.Message, videoURL, videoID, lang string,
+	info *ytfeed.VideoInfo, parts []dubPart, tts TTSProvider) (string, int, TranslationProvider, error) {
+	tmpDir, err := os.MkdirTemp("", "dub-")
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir) //nolint:errcheck // temp files
+
+	t.edit(statusMsg, fmt.Sprintf(t.tr("🎵 Скачиваю оригинальный звук: %s..."), info.Title))
+	original := filepath.Join(tmpDir, "original.mp3")
+	if err := t.VoiceoverSvc.DownloadOriginalAudio(ctx, videoURL, original); err != nil {
+		return "", 0, nil, fmt.Errorf("не удалось скачать оригинальный звук: %w", err)
+	}
+
+	var translator TranslationProvider
+	if lang != "ru" && t.Translator != nil {
+		t.edit(statusMsg, fmt.Sprintf(t.tr("🌐 Перевожу с %s на русский..."), lang))
+		translated, err := t.translateDubParts(ctx, parts)
+		if err != nil {
+			return "", 0, nil, fmt.Errorf("не удалось перевести: %w", err)
+		}
+		if translated {
+			translator = t.Translator
+		}
+	}
+
+	clips := make([]spliceClip, len(parts))
+	voiced, chars := 0, 0
+	for i, p := range parts {
+		if p.text == "" {
+			clips[i] = spliceClip{start: p.start, end: p.end}
+			continue
+		}
+		voiced++
+		t.edit(statusMsg, fmt.Sprintf(t.tr("🔊 Озвучиваю фрагмент %d..."), voiced))
+		audio, err := synthesizeLongText(ctx, tts, p.text, 3000)
+		if err != nil {
+			return "", 0, nil, fmt.Errorf("не удалось озвучить: %w", err)
+		}
+		clips[i].file = filepath.Join(tmpDir, fmt.Sprintf("part%d.mp3", i))
+		if err := os.WriteFile(clips[i].file, audio, 0o600); err != nil {
+			return "", 0, nil, fmt.Errorf("failed to save voiced part: %w", err)
+		}
+		chars += len([]rune(p.text))
+	}
+
+	t.edit(statusMsg, fmt.Sprintf(t.tr("🎚 Свожу %d фрагментов с оригиналом..."), len(clips)))
+	filePath := fmt.Sprintf("%s/vo_%s_%d.mp3", t.FilesLocation, videoID, time.Now().Unix())
+	if err := t.Finalizer.Splice(ctx, original, clips, filePath); err != nil {
+		return "", 0, nil, err
+	}
+	duration := t.ttsDuration(filePath, chars)
+	log.Printf("[INFO] hybrid dub created: %s (%d voiced parts of %d, duration: %ds)", filePath, voiced, len(parts), duration)
+	return filePath, duration, translator, nil
+}
+
+// translateDubParts translates the texts of the parts in place, in one go
+// with a paragraph per part, or part by part when the translator doesn't
+// keep the paragraphs. false when the texts needed no translation.
+func (t *TelegramBot) translateDubParts(ctx context.Context, parts []dubPart) (bool, error) {
+	var idx []int
+	var texts []string
+	for i, p := range parts {
+		if p.text != "" {
+			idx, texts = append(idx, i), append(texts, p.text)
+		}
+	}
+	joined := strings.Join(texts, "\n\n")
+	if !t.Translator.NeedsTranslation(joined) {
+		return false, nil
+	}
+	translated, err := t.Translator.Translate(ctx, joined)
+	if err != nil {
+		return false, err
+	}
+	if res := blankLineRe.Split(strings.TrimSpace(translated), -1); len(res) == len(idx) {
+		for j, i := range idx {
+			parts[i].text = strings.TrimSpace(res[j])
+		}
+		return true, nil
+	}
+	log.Printf("[DEBUG] %d dub parts came back merged, translating one by one", len(idx))
+	for _, i := range idx {
+		if parts[i].text, err = t.Translator.Translate(ctx, parts[i].text); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// spliceClip is an input of Splice: an audio file, or the original from
+// start to end when file is empty
+type spliceClip struct {
+	file       string
+	start, end float64
+}
+
+// Splice joins the clips in order into the MP3 at dst, re-encoded to one
+// format since the TTS voice and the original differ in rate and channels
+func (f *AudioFinalizer) Splice(ctx context.Context, original string, clips []spliceClip, dst string) error {
+	if len(clips) == 0 {
+		return fmt.Errorf("nothing to splice")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+
+	tmp := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".splice.tmp")
+	defer os.Remove(tmp) //nolint:errcheck // gone after a successful rename
+
+	args := []string{"-nostdin", "-y", "-v", "error"}
+	var filter, inputs strings.Builder
+	for i, c := range clips {
+		if c.file != "" {
+			args = append(args, "-i", c.file)
+		} else {
+			args = append(args, "-ss", strconv.FormatFloat(c.start, 'f', 3, 64), "-to", strconv.FormatFloat(c.end, 'f', 3, 64),
+				"-i", original)
+		}
+		fmt.Fprintf(&filter, "[%d:a]aresample=44100,aformat=sample_fmts=fltp:channel_layouts=mono[a%d];", i, i)
+		fmt.Fprintf(&inputs, "[a%d]", i)
+	}
+	filter.WriteString(inputs.String())
+	fmt.Fprintf(&filter, "concat=n=%d:v=0:a=1[out]", len(clips))
+	args = append(args, "-filter_complex", filter.String(), "-map", "[out]",
+		"-c:a", "libmp3lame", "-q:a", "5", "-id3v2_version", "3", "-write_xing", "1", "-f", "mp3", tmp)
+	if _, stderr, err := f.runner().Run(ctx, "ffmpeg", args...); err != nil {
+		return fmt.Errorf("ffmpeg splice failed: %w, stderr: %s", err, lastLines(string(stderr), 5))
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return fmt.Errorf("rename spliced file: %w", err)
+	}
+	return nil
+}
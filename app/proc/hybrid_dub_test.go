@@ -0,0 +1,87 @@
+package proc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/feed-master/app/config"
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+func TestDubParts(t *testing.T) {
+	tests := []struct {
+		name  string
+		segs  []TranscriptSegment
+		total float64
+		want  []dubPart
+	}{
+		{name: "speech only, close cues joined",
+			segs: []TranscriptSegment{{Start: 0.5, End: 2, Text: "Hello."}, {Start: 3, End: 5, Text: "Bye."}},
+			want: []dubPart{{start: 0.5, end: 5, text: "Hello. Bye."}}},
+		{name: "music intro, break and outro kept",
+			segs:  []TranscriptSegment{{Start: 10, End: 12, Text: "Hello."}, {Start: 20, End: 22, Text: "Bye."}},
+			total: 30,
+			want: []dubPart{{start: 0, end: 10}, {start: 10, end: 12, text: "Hello."}, {start: 12, end: 20},
+				{start: 20, end: 22, text: "Bye."}, {start: 22, end: 30}}},
+		{name: "short tail dropped, unknown total",
+			segs: []TranscriptSegment{{Start: 0, End: 2, Text: "Hello."}},
+			want: []dubPart{{start: 0, end: 2, text: "Hello."}}},
+		{name: "rolling auto-subtitles",
+			segs: []TranscriptSegment{{Start: 0, End: 2, Text: "so we went"}, {Start: 2, End: 4, Text: "so we went to the park"},
+				{Start: 4, End: 6, Text: "to the park and back"}},
+			want: []dubPart{{start: 0, end: 6, text: "so we went to the park and back"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, dubParts(tt.segs, 3, tt.total))
+		})
+	}
+}
+
+func TestTelegramBot_TryHybridDub(t *testing.T) {
+	var ffmpegArgs []string
+	bot := newTestBot(t, newTgStub(t))
+	bot.FilesLocation = t.TempDir()
+	bot.Finalizer = &AudioFinalizer{Runner: sourcesRunner(&ffmpegArgs)}
+	bot.VoiceoverSvc.Runner = sourcesRunner(&ffmpegArgs)
+	tts := &FakeTTS{}
+	subFile := filepath.Join(t.TempDir(), "sub.en.vtt")
+	vtt := "WEBVTT\n\n00:00:05.000 --> 00:00:07.000\nHello there.\n\n00:00:07.500 --> 00:00:09.000\nHow are you?\n\n" +
+		"00:00:20.000 --> 00:00:22.000\nGeneral Kenobi.\n"
+	require.NoError(t, os.WriteFile(subFile, []byte(vtt), 0o600))
+	info := &ytfeed.VideoInfo{ID: "abc", Title: "Talk", Duration: 30}
+	msg := testMessage(testBotUserID, "⏳")
+
+	_, _, _, ok := bot.tryHybridDub(context.Background(), msg, "https://youtu.be/abc", "abc", subFile, "en", info, tts)
+	assert.False(t, ok, "off by default")
+
+	bot.HybridDub = config.HybridDub{Enabled: true, MinGap: 2 * time.Second}
+	file, _, p, ok := bot.tryHybridDub(context.Background(), msg, "https://youtu.be/abc", "abc", subFile, "en", info, tts)
+	require.True(t, ok)
+	assert.Equal(t, bot.FilesLocation, filepath.Dir(file))
+	assert.Equal(t, "hybrid-dub", p.Method)
+	assert.Equal(t, "*proc.FakeTranslator", p.Translator)
+	data, err := os.ReadFile(file) //nolint:gosec // test temp file
+	require.NoError(t, err)
+	assert.Equal(t, "MIX", string(data))
+
+	assert.Equal(t, []string{"Hello there. How are you?\n\nGeneral Kenobi."}, bot.Translator.(*FakeTranslator).Texts(),
+		"voiced parts translated in one go")
+	assert.Equal(t, []string{"[ru] Hello there. How are you?", "General Kenobi."}, tts.Texts())
+	args := strings.Join(ffmpegArgs, " ")
+	assert.Contains(t, args, "-ss 0.000 -to 5.000 -i", "music before the speech")
+	assert.Contains(t, args, "-ss 9.000 -to 20.000 -i", "break between the parts")
+	assert.Contains(t, args, "-ss 22.000 -to 30.000 -i", "outro")
+	assert.Contains(t, args, "concat=n=5:v=0:a=1[out]")
+
+	require.NoError(t, os.WriteFile(subFile, []byte("WEBVTT\n\n00:00:00.000 --> 00:00:29.000\nAll talk.\n"), 0o600))
+	_, _, _, ok = bot.tryHybridDub(context.Background(), msg, "https://youtu.be/abc", "abc", subFile, "en", info, tts)
+	assert.False(t, ok, "nothing to keep of the original")
+}
@@ -0,0 +1,138 @@
+package proc
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+)
+
+// bot languages, the messages are written in a mix of both and translated
+// by the catalog
+const (
+	langRU = "ru"
+	langEN = "en"
+)
+
+// russianSpeaking are Telegram language codes of the users shown Russian
+var russianSpeaking = map[string]bool{"ru": true, "uk": true, "be": true, "kk": true}
+
+// normLang maps a Telegram language code ("en-US") to a bot language,
+// "" when the code is empty
+func normLang(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" {
+		return ""
+	}
+	if i := strings.IndexAny(code, "-_"); i > 0 {
+		code = code[:i]
+	}
+	if russianSpeaking[code] {
+		return langRU
+	}
+	return langEN
+}
+
+// translateMsg returns the text in the language, as written when the catalog
+// has no translation or the language is ""
+func translateMsg(lang, text string) string {
+	if tr, ok := catalog[lang][text]; ok {
+		return tr
+	}
+	return text
+}
+
+// tr translates a message of the bot to the language of its user. Replies,
+// statuses and background job reports all go to the one allowed user.
+func (t *TelegramBot) tr(text string) string {
+	return translateMsg(t.userLang(t.AllowedUserID), text)
+}
+
+// userLang is the language the user picked with /lang, else the one of
+// their Telegram app, "" before the first message
+func (t *TelegramBot) userLang(userID int64) string {
+	t.langMu.Lock()
+	defer t.langMu.Unlock()
+	if lang := t.langPicked[userID]; lang != "" {
+		return lang
+	}
+	return t.langSeen[userID]
+}
+
+// noteUserLang remembers the language of the user's Telegram app
+func (t *TelegramBot) noteUserLang(user *tb.User) {
+	lang := normLang(user.LanguageCode)
+	if lang == "" {
+		return
+	}
+	t.langMu.Lock()
+	defer t.langMu.Unlock()
+	if t.langSeen == nil {
+		t.langSeen = map[int64]string{}
+	}
+	t.langSeen[int64(user.ID)] = lang
+}
+
+// loadUserLangs restores the /lang choices
+func (t *TelegramBot) loadUserLangs() {
+	if t.Store == nil {
+		return
+	}
+	langs, err := t.Store.LoadUserLangs()
+	if err != nil {
+		log.Printf("[WARN] failed to load user languages: %v", err)
+		return
+	}
+	t.langMu.Lock()
+	t.langPicked = langs
+	t.langMu.Unlock()
+}
+
+// handleLang sets the language of the bot messages: /lang ru|en, /lang auto
+// follows the Telegram app again
+func (t *TelegramBot) handleLang(m *tb.Message) {
+	if !t.isAuthorized(m.Sender) {
+		return
+	}
+	userID := int64(m.Sender.ID)
+	arg := strings.ToLower(commandArgs(m).arg(0))
+	if arg == "" {
+		lang := t.userLang(userID)
+		if lang == "" {
+			lang = "-"
+		}
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("🌐 Язык: %s\nUsage: /lang ru|en|auto"), lang))
+		return
+	}
+	lang := arg
+	switch arg {
+	case langRU, langEN:
+	case "auto":
+		lang = ""
+	default:
+		_, _ = t.Bot.Send(m.Chat, t.tr("Usage: /lang ru|en|auto"))
+		return
+	}
+	if t.Store != nil {
+		if err := t.Store.SaveUserLang(userID, lang); err != nil {
+			_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("❌ Error: %v"), err))
+			return
+		}
+	}
+	t.langMu.Lock()
+	if t.langPicked == nil {
+		t.langPicked = map[int64]string{}
+	}
+	if lang == "" {
+		delete(t.langPicked, userID)
+	} else {
+		t.langPicked[userID] = lang
+	}
+	t.langMu.Unlock()
+	if lang == "" {
+		_, _ = t.Bot.Send(m.Chat, t.tr("🌐 Язык как в Telegram"))
+		return
+	}
+	_, _ = t.Bot.Send(m.Chat, t.tr("🌐 Язык сообщений изменён"))
+}
@@ -0,0 +1,419 @@
+package proc
+
+// catalog holds the translations of the bot messages by language, keyed by
+// the message as written in the code. Messages written in Russian need an
+// English translation and the other way round, see TestCatalogCoversMessages.
+var catalog = map[string]map[string]string{
+	langEN: {
+		// link menu and actions
+		"🤔 Что сделать со ссылкой?":                                          "🤔 What to do with the link?",
+		"🤔 Что сделать с %d ссылками?":                                       "🤔 What to do with %d links?",
+		"📺 %s: аудио по правилу канала":                                      "📺 %s: audio by the channel rule",
+		"📺 %s: перевод по правилу канала":                                    "📺 %s: dub by the channel rule",
+		"🤔 Что сделать с эпизодом?":                                          "🤔 What to do with the episode?",
+		"🎙 «%s» — %d эпизодов в каталоге. Добавить все в ленту?":             "🎙 «%s» has %d episodes in the catalog. Add all of them to the feed?",
+		"🎙 «%s» — %d эпизодов в каталоге. Добавлю последние %d. Продолжить?": "🎙 «%s» has %d episodes in the catalog. I'll add the latest %d. Continue?",
+		"🎵 Аудио":            "🎵 Audio",
+		"🎙 Перевод RU":       "🎙 Russian dub",
+		"📄 MD-файл":          "📄 MD file",
+		"🎵+📓 Аудио и Notion": "🎵+📓 Audio and Notion",
+		"🎧 В ленту":          "🎧 To the feed",
+		"🎧 Добавить все":     "🎧 Add all",
+		"🎙 Перевод всех RU":  "🎙 Russian dub of all",
+		"📝 Озвучить":         "📝 Voice",
+		"📖 В читалку":        "📖 To the reader",
+		"🚫 Отмена":           "🚫 Cancel",
+		"🚫 Отменено":         "🚫 Cancelled",
+		"Просрочено":         "Expired",
+		"⏱ Меню просрочено или уже использовано": "⏱ The menu expired or was used already",
+		"Без пресета":   "No preset",
+		"⚙️ Пресет: ":   "⚙️ Preset: ",
+		"\n⚙️ Пресет: ": "\n⚙️ Preset: ",
+		"\n\nПресеты (слово перед ссылкой или кнопка в меню): ":           "\n\nPresets (a word before the link or a menu button): ",
+		"📊 %d слов, %d символов, ~%s аудио, язык: %s":                     "📊 %d words, %d characters, ~%s of audio, language: %s",
+		", будет перевод на русский":                                      ", will be translated to Russian",
+		"\n📏 Длиннее лимита в %d символов":                                "\n📏 Over the limit of %d characters",
+		"⏳ Читаю плейлист...":                                             "⏳ Reading the playlist...",
+		"❌ Не смог прочитать плейлист (пустой, приватный или недоступен)": "❌ Couldn't read the playlist (empty, private or unavailable)",
+		"🎬 Плейлист: %d видео. Что сделать?":                              "🎬 Playlist: %d videos. What to do?",
+		"🎬 Плейлист: %d видео, обработаю первые %d. Что сделать?":         "🎬 Playlist: %d videos, I'll process the first %d. What to do?",
+		"⏳ Конспект в очереди...":                                         "⏳ Notes queued...",
+		"⏳ Получаю озвучку...":                                            "⏳ Getting the voiceover...",
+		"⏳ Озвучиваю %d видео...":                                         "⏳ Voicing %d videos...",
+		"⏳ Скачиваю эпизод...":                                            "⏳ Downloading the episode...",
+		"⏳ Добавляю эпизоды...":                                           "⏳ Adding the episodes...",
+		"⏳ Перевожу эпизоды...":                                           "⏳ Translating the episodes...",
+		"⏳ Ставлю переводы в очередь...":                                  "⏳ Queueing the translations...",
+		"⏳ Озвучиваю статью...":                                           "⏳ Voicing the article...",
+		"⏳ Добавляю в читалку...":                                         "⏳ Adding to the reader...",
+		"⏳ В очереди...":                                                  "⏳ Queued...",
+		"🔁 Всё равно запустить":                                           "🔁 Run anyway",
+		"⏳ Эта ссылка уже в работе с %s, результат придёт в её сообщение": "⏳ This link is in work since %s, the result comes to its message",
+		"➕ Всё равно добавить":                                            "➕ Add anyway",
+		"⚠️ Такой же текст уже в ленте: запись %d «%s»":                   "⚠️ The same text is in the feed already: entry %d «%s»",
+
+		// progress of the jobs
+		"⬇️ Скачиваю: %s...%s":      "⬇️ Downloading: %s...%s",
+		"⬇️ Скачиваю: %s...":        "⬇️ Downloading: %s...",
+		"⬇️ Скачиваю аудио: %s...":  "⬇️ Downloading the audio: %s...",
+		"🎙 %s: запускаю озвучку...": "🎙 %s: starting the voiceover...",
+		"✅ Озвучено %d/%d":          "✅ Voiced %d/%d",
+		"\n🎵 Музыка, без перевода (%d) — добавь как 🎵 Аудио:\n%s": "\n🎵 Music, not translated (%d), add it as 🎵 Audio:\n%s",
+		"⏳ Извлекаю текст статьи...":                              "⏳ Extracting the article text...",
+		"🧠 Делаю выжимку: %s...":                                  "🧠 Summarizing: %s...",
+		"🔊 Озвучиваю: %s (%d символов)":                           "🔊 Voicing: %s (%d characters)",
+		"🌐 Перевожу с %s и озвучиваю: %s":                         "🌐 Translating from %s and voicing: %s",
+		"🎙 Пробую Яндекс-перевод: %s...":                          "🎙 Trying the Yandex translation: %s...",
+		"📜 Официальный транскрипт: %s...":                         "📜 Official transcript: %s...",
+		"🎧 Транскрибирую %d/%d: %s...":                            "🎧 Transcribing %d/%d: %s...",
+		"🌐 Перевожу: %s...":                                       "🌐 Translating: %s...",
+		"🗣 Озвучиваю: %s...":                                      "🗣 Voicing: %s...",
+		"⏳ Получаю информацию о видео...":                         "⏳ Getting the video info...",
+		"🔍 Ищу, как озвучить: %s...":                              "🔍 Looking for a way to voice: %s...",
+		"📝 Скачиваю субтитры: %s...":                              "📝 Downloading the subtitles: %s...",
+		"📄 Извлекаю текст из субтитров...":                        "📄 Extracting the text of the subtitles...",
+		"🌐 Перевожу с %s на русский (%d символов)...":             "🌐 Translating from %s to Russian (%d characters)...",
+		"🔊 Озвучиваю (%d символов, это займёт время)...":          "🔊 Voicing (%d characters, this takes a while)...",
+		"🔊 Продолжаю озвучку с фрагмента %d/%d...":                "🔊 Resuming the voiceover from chunk %d/%d...",
+		"🔊 Озвучиваю: %d/%d фрагментов...":                        "🔊 Voicing: %d/%d chunks...",
+		"🎬 Скачиваю дубляж YouTube: %s...%s":                      "🎬 Downloading the YouTube dub: %s...%s",
+		"⚠️ Дубляж больше лимита (%d МБ), перевожу сам: %s...":    "⚠️ The dub is over the limit (%d MB), translating myself: %s...",
+		"🎙 Скачиваю озвучку (vot-cli): %s...%s":                   "🎙 Downloading the voiceover (vot-cli): %s...%s",
+		"🎚 Сохраняю оригинальную дорожку: %s...":                  "🎚 Saving the original track: %s...",
+		"🎵 Субтитров нет, скачиваю звук для расшифровки: %s...":   "🎵 No subtitles, downloading the audio to transcribe: %s...",
+		"🎧 Расшифровываю %d/%d: %s...":                            "🎧 Transcribing %d/%d: %s...",
+		"🎵 Скачиваю оригинальный звук: %s...":                     "🎵 Downloading the original audio: %s...",
+		"🌐 Перевожу с %s на русский...":                           "🌐 Translating from %s to Russian...",
+		"🔊 Озвучиваю фрагмент %d...":                              "🔊 Voicing chunk %d...",
+		"🎚 Свожу %d фрагментов с оригиналом...":                   "🎚 Mixing %d chunks with the original...",
+		"⏳ Сравниваю %s и %s...":                                  "⏳ Comparing %s and %s...",
+		"готово за %s":                                            "done in %s",
+		" осталось":                                               " left",
+		"<1 мин":                                                  "<1 min",
+		"≈%d мин":                                                 "≈%d min",
+		"≈%dч %02dм":                                              "≈%dh %02dm",
+
+		// results
+		"✅ 📖 %s: %d частей (%s)":        "✅ 📖 %s: %d parts (%s)",
+		"✅ «%s»: добавлено %d/%d":       "✅ «%s»: added %d/%d",
+		"✅ «%s»: переведено %d/%d":      "✅ «%s»: translated %d/%d",
+		" (%d уже в ленте)":             " (%d in the feed already)",
+		" (%d с ошибками)":              " (%d failed)",
+		"⚠️ Уже есть в ленте":           "⚠️ In the feed already",
+		"🔞 с возрастным ограничением":   "🔞 age-restricted",
+		"💎 только для спонсоров":        "💎 members only",
+		"🌍 недоступны в стране сервера": "🌍 unavailable in the server's country",
+		"🔒 приватные":                   "🔒 private",
+		"🗑 удалены":                     "🗑 removed",
+		"🎵 Добавить оригинал":           "🎵 Add the original",
+		"🎵 «%s» похоже на музыку — переводить нечего.\nДобавить оригинальное аудио?": "🎵 «%s» looks like music, there is nothing to translate.\nAdd the original audio?",
+		"🔊 Целиком":       "🔊 In full",
+		"🧠 Выжимка":       "🧠 Summary",
+		"✂️ Частями (%d)": "✂️ In parts (%d)",
+		"📏 Длинная статья: «%s», %d символов (~%s). Как озвучить?": "📏 Long article: «%s», %d characters (~%s). How to voice it?",
+
+		// errors
+		"⏳ Слишком длинно для перевода: vot-cli такие видео не берёт, а субтитров нет. Добавь его как 🎵 Аудио.":                "⏳ Too long to translate: vot-cli doesn't take such videos and there are no subtitles. Add it as 🎵 Audio.",
+		"📝 У видео нет субтитров, перевести через них не получится.":                                                           "📝 The video has no subtitles to translate.",
+		"📦 Файл больше лимита размера (max_dub_size_mb), скачивание отменено.":                                                 "📦 The file is over the size limit (max_dub_size_mb), download cancelled.",
+		"🎬 Официального русского дубляжа нет, а пресет запрещает машинный перевод.":                                            "🎬 There is no official Russian dub and the preset forbids machine translation.",
+		"📏 Статья длиннее лимита (telegram_bot.article_limit.max_chars), не озвучиваю.":                                        "📏 The article is over the limit (telegram_bot.article_limit.max_chars), not voicing it.",
+		"🤖 robots.txt сайта запрещает загрузку этой страницы (telegram_bot.article_fetch.robots).":                             "🤖 The site's robots.txt forbids fetching this page (telegram_bot.article_fetch.robots).",
+		"\nПохоже на временный сбой, попробуй ещё раз позже.":                                                                  "\nLooks like a temporary failure, try again later.",
+		"\nПричина неясна, подробности в логе.":                                                                                "\nThe cause is unclear, the details are in the log.",
+		"🔞 Видео с возрастным ограничением. Нужны cookies аккаунта, подтвердившего возраст: пришли свежий cookies.txt файлом.": "🔞 Age-restricted video. It needs cookies of an account with a confirmed age: send a fresh cookies.txt as a file.",
+		"💎 Видео только для спонсоров канала. Нужны cookies аккаунта с подпиской: пришли cookies.txt файлом.":                  "💎 Members-only video. It needs cookies of a subscribed account: send cookies.txt as a file.",
+		"🌍 Видео недоступно в стране сервера. Помогут прокси (--proxy в dl_template) или cookies из другой страны.":            "🌍 The video is unavailable in the server's country. A proxy (--proxy in dl_template) or cookies from another country help.",
+		"🔒 Приватное видео. Скачать можно только с cookies аккаунта, у которого есть доступ.":                                  "🔒 Private video. It can only be downloaded with cookies of an account that has access.",
+		"🗑 Видео удалено или недоступно (удалено автором, заблокировано, канал закрыт).":                                       "🗑 The video is removed or unavailable (removed by the author, blocked, the channel is closed).",
+		"❌ Конспекты не настроены (notes.enabled в конфиге + GROQ_API_KEY)":                                                    "❌ Notes are not configured (notes.enabled in the config + GROQ_API_KEY)",
+		"❌ Конспекты не настроены (notes.enabled + GROQ_API_KEY)":                                                              "❌ Notes are not configured (notes.enabled + GROQ_API_KEY)",
+		"❌ Не нашёл ссылку в сообщении":                                                                                        "❌ No link found in the message",
+		"❌ Не понял ссылку на эпизод":                                                                                          "❌ Couldn't make out the episode link",
+		"❌ Дайджест дня не собрался: ":                                                                                         "❌ The daily digest failed: ",
+		"❌ Для этого эпизода озвученный текст не сохранён":                                                                     "❌ The voiced text of this episode is not kept",
+		"❌ Нет ffmpeg, пересвести нечем":                                                                                       "❌ No ffmpeg to remix with",
+		"❌ Для этого эпизода исходники не сохранены (vo_sources.keep)":                                                         "❌ The sources of this episode are not kept (vo_sources.keep)",
+		"❌ Глоссарий не настроен (нужно хранилище).":                                                                           "❌ The glossary is not configured (needs the store).",
+		"❌ Читалка не настроена (read.enabled).":                                                                               "❌ The reader is not configured (read.enabled).",
+		"❌ Читалка не настроена.":                                                                                              "❌ The reader is not configured.",
+		"❌ Это не похоже на ссылку на статью.":                                                                                 "❌ This doesn't look like an article link.",
+		"❌ Озвучка статей не настроена (tts_enabled).":                                                                         "❌ Article voicing is not configured (tts_enabled).",
+		"❌ min= ждёт число символов, например min=2000":                                                                        "❌ min= expects a number of characters, like min=2000",
+		"❌ Не читается как RSS/Atom: %v":                                                                                       "❌ Doesn't read as RSS/Atom: %v",
+		"⚠️ Не смог отправить файл: %v":                                                                                        "⚠️ Couldn't send the file: %v",
+		"Ошибка":     "Error",
+		"Ошибка: %v": "Error: %v",
+
+		// notes and digests
+		"⏳ Перевод в очереди...\n":                                                      "⏳ Translation queued...\n",
+		"⏳ Перевод в очереди: ":                                                         "⏳ Translation queued: ",
+		"🎙 «%s»: поставил в очередь %d переводов":                                       "🎙 «%s»: queued %d translations",
+		"⚠️ %s\n📄 транскрипт готов (файл ниже), но дальше не получилось:\n%v":           "⚠️ %s\n📄 the transcript is ready (the file below), but the rest failed:\n%v",
+		"⏳ Снова в очереди...\n":                                                        "⏳ Queued again...\n",
+		"Пока нет транскриптов с тегами. Сначала /md или /notes.":                       "No tagged transcripts yet. Try /md or /notes first.",
+		"🏷 Теги в транскриптах:\n\n":                                                    "🏷 Tags of the transcripts:\n\n",
+		"\nСобрать конспект по теме: /digest <тег>":                                     "\nNotes on a topic: /digest <tag>",
+		"Дайджест «%s» актуален: новых материалов нет (%d в составе).":                  "The «%s» digest is up to date: nothing new (%d in it).",
+		"⏳ В очереди: дайджест «%s» (%d новых из %d)...":                                "⏳ Queued: the «%s» digest (%d new of %d)...",
+		"⏳ В очереди: дайджест «%s» — точного тега нет, подберу источники по смыслу...": "⏳ Queued: the «%s» digest, no such tag, picking the sources by meaning...",
+		"⏱ %dч %02dм": "⏱ %dh %02dm",
+		"⏱ %dм":       "⏱ %dm",
+		"%d слов":     "%d words",
+		"Пока нет ни одного транскрипта. Пришли ссылку и выбери 📄 MD-файл.": "No transcripts yet. Send a link and pick 📄 MD file.",
+		"📄 Транскрипты (%d) — стр %d/%d:\n\n":                               "📄 Transcripts (%d), page %d/%d:\n\n",
+		"%dм": "%dm",
+		"\n⬇️ скачать · 📓 в Notion · 🗑 удалить": "\n⬇️ download · 📓 to Notion · 🗑 delete",
+		"Файл не найден":                        "File not found",
+		"Отправил файл":                         "File sent",
+		"У файла нет URL источника":             "The file has no source URL",
+		"📄 Транскриптов больше нет":             "📄 No more transcripts",
+		"Удалён": "Deleted",
+		"Конспекты не настроены": "Notes are not configured",
+
+		// /status and the dashboard
+		"Конспекты выключены\n":                                    "Notes are off\n",
+		"📋 Очередь конспектов\n⏳ в очереди: %d\n⚙️ в работе: %d\n": "📋 Notes queue\n⏳ queued: %d\n⚙️ in work: %d\n",
+		"\n🔧 Утилиты:\n":                                           "\n🔧 Tools:\n",
+		"\n🚦 Нагрузка:\n":                                          "\n🚦 Load:\n",
+		", в очереди %d":                                           ", %d waiting",
+		"\nПоследние задачи:\n":                                    "\nRecent jobs:\n",
+		"☁️ R2: недоступно":                                        "☁️ R2: unavailable",
+		"⚠️ R2 занято %.1f GB из 10 бесплатных.\nПрослушанное можно удалить: /list → 🗑 — файл в R2 удалится вместе с записью, ссылка останется в /history.": "⚠️ R2 has %.1f GB of the 10 free ones used.\nDelete what you listened to: /list → 🗑, the R2 file goes with the entry, the link stays in /history.",
+		"только что":   "just now",
+		"%d мин назад": "%d min ago",
+		"🧠 LLM: осталось %s из %s токенов (%s)": "🧠 LLM: %s of %s tokens left (%s)",
+		"фоновая задача":                        "background job",
+		"%d видео":                              "%d videos",
+		"📌 Бот остановлен":                      "📌 The bot is stopped",
+		"📌 Задач нет\n":                         "📌 No jobs\n",
+		"📌 В работе: %d\n":                      "📌 In work: %d\n",
+		"• %s, идёт %s":                         "• %s, running %s",
+		", ⏱ %s осталось":                       ", ⏱ %s left",
+		", ⏱ вот-вот":                           ", ⏱ any moment",
+		"\n📋 Конспекты: в очереди %d, в работе %d\n": "\n📋 Notes: %d queued, %d in work\n",
+
+		// failures and retries
+		"🚨 Сбой задачи %s\n🆔 %s\n":            "🚨 Job %s failed\n🆔 %s\n",
+		"🔁 Повторить":                         "🔁 Retry",
+		"Уже перезапущено или слишком старое": "Restarted already or too old",
+		"Перезапускаю":                        "Restarting",
+		"\n\n🔁 перезапущено":                  "\n\n🔁 restarted",
+		"⏳ Повтор %s %s...":                   "⏳ Retrying %s %s...",
+		"Нет лога задачи %s":                  "No log of job %s",
+		"Логов задач нет.":                    "No job logs.",
+		"📜 Последние логи задач:\n":           "📜 Recent job logs:\n",
+		"⚠️ %s: не получилось озвучить «%s» (попыток: %d), убрал в архив\n%s\n%v": "⚠️ %s: couldn't voice «%s» (attempts: %d), archived it\n%s\n%v",
+
+		// feed entries
+		"Эпизод не найден":          "Episode not found",
+		"Файл не найден на диске":   "File not found on disk",
+		"Не удалось отправить файл": "Couldn't send the file",
+		"Отправил аудио":            "Audio sent",
+		"⬇️ %s\n%s\n(файл %d МБ — больше лимита Telegram, качай по ссылке)": "⬇️ %s\n%s\n(the file is %d MB, over the Telegram limit, download it by the link)",
+		"Прислал ссылку":                     "Link sent",
+		"У эпизода нет ссылки на источник":   "The episode has no source link",
+		"Поставил в очередь":                 "Queued",
+		"Не удалось закрепить":               "Couldn't pin",
+		"Закреплён, не удалится по возрасту": "Pinned, won't expire",
+		"Откреплён":                          "Unpinned",
+		"Вид: %s\n":                          "Kind: %s\n",
+		"Добавлено: %s\n":                    "Added: %s\n",
+		"Длительность: %s\n":                 "Duration: %s\n",
+		"Способ: не записан\n":               "Method: not recorded\n",
+		"Способ: %s\n":                       "Method: %s\n",
+		"Голос: %s\n":                        "Voice: %s\n",
+		"Перевод: %s\n":                      "Translation: %s\n",
+		"Настройки: %s\n":                    "Settings: %s\n",
+		"Usage: /revoice N <голос|язык|пресет> [скорость]\nExample: /revoice 1 en-US-AriaNeural +10%": "Usage: /revoice N <voice|language|preset> [rate]\nExample: /revoice 1 en-US-AriaNeural +10%",
+		"🔊 Переозвучиваю %s (%s)...":                                                                       "🔊 Revoicing %s (%s)...",
+		"✅ Переозвучено: ":                                                                                 "✅ Revoiced: ",
+		"Usage: /remix N [громкость оригинала 0-1]\nExample: /remix 1 0.3":                                 "Usage: /remix N [original volume 0-1]\nExample: /remix 1 0.3",
+		"🎚 Пересвожу %s...":                                                                                "🎚 Remixing %s...",
+		"✅ Пересведено: %s (оригинал %.0f%%)":                                                              "✅ Remixed: %s (original %.0f%%)",
+		"Usage: /compare <video_url> [способ способ]\nСпособы: %s\nExample: /compare https://youtu.be/xxx": "Usage: /compare <video_url> [method method]\nMethods: %s\nExample: /compare https://youtu.be/xxx",
+		"🗑 Удалить все (%d)":                                                                               "🗑 Delete all (%d)",
+		"Удалить все эпизоды ленты (%d)? Файлы хранятся сутки, /undo вернёт их.":                           "Delete all the feed episodes (%d)? The files are kept for a day, /undo brings them back.",
+		"Отменено, лента не тронута.":                                                                      "Cancelled, the feed is untouched.",
+		"Лента изменилась с момента вопроса, повтори /delall.":                                             "The feed changed since the question, repeat /delall.",
+		"🗑 Удалено эпизодов: %d. Лента пуста, /undo вернёт их в течение суток.":                            "🗑 Episodes deleted: %d. The feed is empty, /undo brings them back within a day.",
+
+		// glossary
+		"Usage: /glossary add <термин> [= <перевод>] | /glossary list | /glossary del <термин или N>": "Usage: /glossary add <term> [= <translation>] | /glossary list | /glossary del <term or N>",
+		"📖 В глоссарии: ":              "📖 In the glossary: ",
+		"❌ Нет термина %d, всего %d":   "❌ No term %d, there are %d",
+		"❌ Нет термина %q в глоссарии": "❌ No term %q in the glossary",
+		"🗑 Удалено из глоссария: ":     "🗑 Removed from the glossary: ",
+		"Глоссарий пуст. /glossary add <термин> [= <перевод>] — как переводить термин.": "The glossary is empty. /glossary add <term> [= <translation>] sets how a term is translated.",
+		"📖 Глоссарий (%d):\n\n":      "📖 Glossary (%d):\n\n",
+		"\nУдалить: /glossary del N": "\nDelete: /glossary del N",
+		" (без перевода)":            " (kept as is)",
+
+		// publishing platform
+		"Издательская платформа не настроена (R2_* + FEED_SECRET)": "The publishing platform is not configured (R2_* + FEED_SECRET)",
+		"Пока нет ни одной ленты. Закинь файл в Inbox — появится.": "No feeds yet. Drop a file into the Inbox to make one.",
+		"📻 Ленты платформы:\n\n":                                   "📻 Platform feeds:\n\n",
+		"• %s — %d эп., %s\n%s\n\n":                                "• %s: %d ep., %s\n%s\n\n",
+		"Управление эпизодами: /archive <категория>":               "Manage the episodes: /archive <category>",
+		"Usage: /archive <категория>\nЕсть: ":                      "Usage: /archive <category>\nThere are: ",
+		"В «%s» нет опубликованных эпизодов":                       "No published episodes in «%s»",
+		"🗄 %s (%d) — стр %d/%d:\n\n":                               "🗄 %s (%d), page %d/%d:\n\n",
+		"%d. %s (%s, %d МБ)\n":                                     "%d. %s (%s, %d MB)\n",
+		"\n🗄 в архив (из ленты и R2) · 🔁 переобработать":           "\n🗄 archive (off the feed and R2) · 🔁 reprocess",
+		"Список устарел, открой /archive заново":                   "The list is stale, open /archive again",
+		"В архиве": "Archived",
+		"Забыт — watcher переобработает": "Forgotten, the watcher reprocesses it",
+
+		// reader
+		"📖 В читалке":      "📖 In the reader",
+		"♻️ Уже в читалке": "♻️ In the reader already",
+		"📖 %d мин":         "📖 %d min",
+		"В читалке пусто. Пришли ссылку и выбери 📖 В читалку.": "The reader is empty. Send a link and pick 📖 To the reader.",
+		"📖 Читалка (%d) — стр %d/%d:\n\n":                      "📖 Reader (%d), page %d/%d:\n\n",
+		"%d мин": "%d min",
+		"\n⬇️ скачать · 🔗 источник · 🗑 удалить": "\n⬇️ download · 🔗 source · 🗑 delete",
+		"У статьи нет URL источника":            "The article has no source URL",
+		"📖 В читалке больше ничего нет":         "📖 Nothing else in the reader",
+
+		// RSS subscriptions
+		"Usage: /rsssub <url> [min=N] [пресет]": "Usage: /rsssub <url> [min=N] [preset]",
+		"📰 Читаю ленту...":                      "📰 Reading the feed...",
+		"📰 Подписка: %s\nНовые посты будут озвучиваться в ленту, %d текущих пропущено.": "📰 Subscribed: %s\nNew posts will be voiced into the feed, %d current ones skipped.",
+		"Usage: /rssunsub N (номер из /rsssub)": "Usage: /rssunsub N (the number from /rsssub)",
+		"❌ Нет подписки %s, всего %d":           "❌ No subscription %s, there are %d",
+		"🗑 Отписался: ":                         "🗑 Unsubscribed: ",
+		"Подписок нет. /rsssub <url> [min=N] [пресет] — озвучивать новые посты ленты.": "No subscriptions. /rsssub <url> [min=N] [preset] voices new posts of a feed.",
+		"📰 RSS-подписки:\n\n":     "📰 RSS subscriptions:\n\n",
+		"от %d символов":          "from %d characters",
+		"пресет ":                 "preset ",
+		"Отписаться: /rssunsub N": "Unsubscribe: /rssunsub N",
+
+		// stats and checks
+		"История пуста, считать нечего":               "The history is empty, nothing to count",
+		"Часы аудио по неделям с %s":                  "Hours of audio by week since %s",
+		"📊 Всего: %d, аудио %.1f ч\n\n":               "📊 Total: %d, %.1f h of audio\n\n",
+		"Неделя\tШтук\tЧасов":                         "Week\tItems\tHours",
+		"\nСпособ:\n":                                 "\nMethod:\n",
+		"\nИсточники:\n":                              "\nSources:\n",
+		"\n🎧 Скачиваний: %d\n":                        "\n🎧 Downloads: %d\n",
+		"По способу":                                  "By method",
+		"Эпизоды":                                     "Episodes",
+		"Приложения":                                  "Apps",
+		"🔍 Проверяю файлы ленты...":                   "🔍 Checking the feed files...",
+		"%s — ошибка проверки: %v":                    "%s: check failed: %v",
+		"%s — не удалось посчитать сумму: %v":         "%s: couldn't compute the checksum: %v",
+		" — файл отсутствует":                         ": the file is missing",
+		" — размер не совпадает (файл обрезан?)":      ": the size differs (a truncated file?)",
+		" — контрольная сумма не совпадает":           ": the checksum differs",
+		"🔍 Проверено эпизодов: %d\n✅ в порядке: %d\n": "🔍 Episodes checked: %d\n✅ fine: %d\n",
+		"🆕 записаны контрольные суммы: %d\n":          "🆕 checksums recorded: %d\n",
+		"☁️ не на диске (в R2): %d\n":                 "☁️ not on disk (in R2): %d\n",
+		"\n❌ Проблемы (%d):\n":                        "\n❌ Problems (%d):\n",
+		"…и ещё %d\n":                                 "…and %d more\n",
+		"Удалить битые: /list → 🗑, затем отправить ссылки заново.": "Delete the broken ones: /list → 🗑, then send the links again.",
+
+		// command menu
+		"Что сейчас в ленте":                               "What's in the feed",
+		"Озвучка видео на русском":                         "Russian voiceover of a video",
+		"Как сделан эпизод N":                              "How episode N was made",
+		"Удалить из ленты":                                 "Delete from the feed",
+		"Очистить ленту":                                   "Clear the feed",
+		"Вернуть последнее удалённое":                      "Bring back the last deleted",
+		"Показать эпизоды в ленте позже":                   "Show episodes in the feed later",
+		"Пересвести озвучку с другой громкостью оригинала": "Remix a voiceover with another original volume",
+		"Переозвучить статью другим голосом":               "Revoice an article with another voice",
+		"Озвучить видео двумя способами":                   "Voice a video by two methods",
+		"Транскрипт в MD-файл":                             "Transcript to an MD file",
+		"Конспект в Notion":                                "Notes to Notion",
+		"Сводный конспект по тегу":                         "Notes on a tag",
+		"Статья в читалку":                                 "Article to the reader",
+		"Подписки на RSS":                                  "RSS subscriptions",
+		"Отписаться от RSS":                                "Unsubscribe from RSS",
+		"Глоссарий перевода":                               "Translation glossary",
+		"Ленты платформы":                                  "Platform feeds",
+		"Эпизоды категории платформы":                      "Episodes of a platform category",
+		"Очередь задач, R2, лимиты LLM":                    "Job queue, R2, LLM limits",
+		"Лог всех отправлений":                             "Log of everything sent",
+		"Статистика по неделям":                            "Weekly stats",
+		"Проверить файлы ленты":                            "Check the feed files",
+		"Лог задачи":                                       "Job log",
+		"Язык сообщений бота":                              "Language of the bot messages",
+		"Справка":                                          "Help",
+
+		// /lang
+		"🌐 Язык: %s\nUsage: /lang ru|en|auto": "🌐 Language: %s\nUsage: /lang ru|en|auto",
+		"🌐 Язык как в Telegram":               "🌐 Language as in Telegram",
+		"🌐 Язык сообщений изменён":            "🌐 Message language changed",
+
+		"🎧 Turnip Bot\n\nПришли ссылку (YouTube, эпизод или подкаст Apple Podcasts, статья) —\nпоявится меню: слушать, перевод RU, MD-файл, Notion.\n\nСлушать:\n/list — что сейчас в ленте\n/info N — как сделан эпизод N: способ, голос, перевод, настройки\n/del [N] — удалить из ленты (последнее или N-е); /del 3-7 — диапазон; /del tag:<тег> — по типу, каналу или сайту\n/delall — очистить ленту (с подтверждением)\n/undo — вернуть последнее удалённое (файлы хранятся сутки)\n/schedule N|3-7 <когда> [daily] — показать в ленте позже: tomorrow 7am, 19:00, +3h; daily — по одному в день\n/remix N [0.3] — пересвести озвучку N с другой громкостью оригинала (vo_sources)\n/revoice N <голос|язык|пресет> [+20%%] — переозвучить статью N другим голосом, без перевода заново\n/vo <url> — озвучка видео на русском (YouTube, Vimeo и другие сайты yt-dlp); --lang=en — язык видео, --method=vot|subs|dub — способ\n/compare <url> [способ способ] — озвучить двумя способами (vot-cli и субтитры) и прислать оба файла, в ленту не добавляет\n\nКонспекты:\n/md <url> — транскрипт в MD-файл\n/md — список транскриптов (скачать / в Notion / удалить)\n/notes <url> [short|long] — транскрипт + саммари + отсылки в Notion\n/digest — теги; /digest <тег> — сводный конспект по теме\n/status — очередь задач, R2, лимиты LLM\n\nЧитать:\n/read <url> — статья в структурный MD (читалка)\n/read — список статей (скачать / открыть / удалить)\n/rsssub <url> [min=N] [пресет] — озвучивать новые посты RSS/Atom\n/rsssub — подписки; /rssunsub N — отписаться\n/glossary add <термин> [= <перевод>] — как переводить термин; /glossary list|del N\n\nПлатформа (книги/курсы):\n/feeds — ленты с URL подписки\n/archive <категория> — 🗄 в архив / 🔁 переобработать\n\nПрочее:\n/history — вечный лог всех отправлений\n/stats [chart] — сколько добавлено по неделям, способам и источникам\n/verify — проверить файлы ленты (пропавшие, битые)\n/debug [id] — лог задачи файлом; без id — последние логи\n/lang ru|en|auto — язык сообщений бота\n/help — эта справка\nФайл cookies.txt вложением — обновить YouTube-куки\n\nRSS: %s/yt/rss/%s": "🎧 Turnip Bot\n\nSend a link (YouTube, an Apple Podcasts episode or show, an article)\nto get a menu: listen, Russian dub, MD file, Notion.\n\nListen:\n/list — what's in the feed now\n/info N — how episode N was made: method, voice, translation, settings\n/del [N] — delete from the feed (the last or the N-th); /del 3-7 — a range; /del tag:<tag> — by kind, channel or site\n/delall — clear the feed (asks first)\n/undo — bring back the last deleted (the files are kept for a day)\n/schedule N|3-7 <when> [daily] — show in the feed later: tomorrow 7am, 19:00, +3h; daily — one a day\n/remix N [0.3] — remix voiceover N with another original volume (vo_sources)\n/revoice N <voice|language|preset> [+20%%] — revoice article N with another voice, no new translation\n/vo <url> — Russian voiceover of a video (YouTube, Vimeo and other yt-dlp sites); --lang=en — the video language, --method=vot|subs|dub — the method\n/compare <url> [method method] — voice by two methods (vot-cli and subtitles) and send both files, not added to the feed\n\nNotes:\n/md <url> — transcript to an MD file\n/md — transcripts (download / to Notion / delete)\n/notes <url> [short|long] — transcript + summary + references to Notion\n/digest — tags; /digest <tag> — notes on a topic\n/status — job queue, R2, LLM limits\n\nRead:\n/read <url> — article to a structured MD (reader)\n/read — articles (download / open / delete)\n/rsssub <url> [min=N] [preset] — voice new posts of an RSS/Atom feed\n/rsssub — subscriptions; /rssunsub N — unsubscribe\n/glossary add <term> [= <translation>] — how to translate a term; /glossary list|del N\n\nPlatform (books/courses):\n/feeds — feeds with subscription URLs\n/archive <category> — 🗄 archive / 🔁 reprocess\n\nOther:\n/history — the log of everything sent\n/stats [chart] — what was added by week, method and source\n/verify — check the feed files (missing, broken)\n/debug [id] — job log as a file; no id — recent logs\n/lang ru|en|auto — language of the bot messages\n/help — this help\nA cookies.txt file attached — update the YouTube cookies\n\nRSS: %s/yt/rss/%s",
+	},
+	langRU: {
+		"Usage: /lang ru|en|auto":            "Как: /lang ru|en|auto",
+		"Unauthorized. This bot is private.": "Нет доступа, это личный бот.",
+		"No valid URL found. Send a link:\n• YouTube: https://youtube.com/watch?v=VIDEO_ID": "Не нашёл ссылку. Пришли ссылку:\n• YouTube: https://youtube.com/watch?v=VIDEO_ID",
+		"\n• Article: any web page URL":    "\n• Статья: адрес любой веб-страницы",
+		"Usage: /info N\nExample: /info 1": "Как: /info N\nНапример: /info 1",
+		"Usage: /vo <video_url> [--lang=en] [--method=dub|vot|subs]\nExample: /vo https://youtube.com/watch?v=xxx": "Как: /vo <ссылка на видео> [--lang=en] [--method=dub|vot|subs]\nНапример: /vo https://youtube.com/watch?v=xxx",
+		"Usage: /%s <url> [url2 ...] [short|long]": "Как: /%s <ссылка> [ссылка2 ...] [short|long]",
+		"Error: %v":                            "Ошибка: %v",
+		"❌ Error: %v":                          "❌ Ошибка: %v",
+		"❌ Error: ":                            "❌ Ошибка: ",
+		"Error loading entries: %v":            "Ошибка загрузки записей: %v",
+		"Error loading entries":                "Ошибка загрузки записей",
+		"Error loading history":                "Ошибка загрузки истории",
+		"Error loading feed":                   "Ошибка загрузки ленты",
+		"❌ Error loading history: %v":          "❌ Ошибка загрузки истории: %v",
+		"Error removing: %v":                   "Ошибка удаления: %v",
+		"❌ Error removing: %v":                 "❌ Ошибка удаления: %v",
+		"%s\n(Error loading updated list: %v)": "%s\n(Ошибка загрузки нового списка: %v)",
+		"Error scheduling %s: %v":              "Ошибка планирования %s: %v",
+		"Only %d entries in feed.":             "В ленте всего %d записей.",
+		"No videos in feed yet.":               "В ленте пока пусто.",
+		"No history yet.":                      "История пока пуста.",
+		"Feed is empty.":                       "Лента пуста.",
+		"Feed is now empty.":                   "Лента теперь пуста.",
+		"Remaining (%d):\n":                    "Осталось (%d):\n",
+		"Nothing to undo.":                     "Нечего возвращать.",
+		"↩️ Restored %d:\n":                    "↩️ Возвращено %d:\n",
+		"📢 %s — now\n":                         "📢 %s — сейчас\n",
+		"Deleted":                              "Удалено",
+		"Deleted, /undo to restore":            "Удалено, /undo вернёт",
+		"Delete failed":                        "Не удалось удалить",
+		"Not found":                            "Не найдено",
+		"Missing video id":                     "Нет id видео",
+		"Bad data":                             "Неверные данные",
+		"Bad action":                           "Неизвестное действие",
+		"❌ Unknown action: %s":                 "❌ Неизвестное действие: %s",
+		"❌ Invalid video URL":                  "❌ Неверная ссылка на видео",
+		"❌ vot-cli not installed":              "❌ vot-cli не установлен",
+		"❌ Cookies file path is not configured on server.":            "❌ На сервере не задан путь к файлу cookies.",
+		"❌ File too large (%d bytes). Expected a cookies.txt export.": "❌ Файл слишком большой (%d байт), ждал экспорт cookies.txt.",
+		"🍪 Receiving %s...":     "🍪 Получаю %s...",
+		"❌ Download failed: %v": "❌ Не скачался: %v",
+		"❌ Read failed: %v":     "❌ Не читается: %v",
+		"❌ Not a valid YouTube auth cookies file (no SAPISID/SID/LOGIN_INFO found). ": "❌ Это не cookies авторизации YouTube (нет SAPISID/SID/LOGIN_INFO). ",
+		"Make sure you're logged into YouTube in your browser before exporting.":      "Перед экспортом войди в YouTube в браузере.",
+		"❌ Install failed: %v":                                  "❌ Не установился: %v",
+		"✅ Cookies updated (%d bytes). Try a YouTube link now.": "✅ Cookies обновлены (%d байт). Попробуй ссылку YouTube.",
+		"❌ YouTube cookies expired. This video requires authentication.\nRun update-cookies.sh to fix.": "❌ Cookies YouTube устарели, а видео требует входа.\nОбнови их: update-cookies.sh.",
+		"\n⚠️ YouTube cookies expired. Run update-cookies.sh to fix.":                                   "\n⚠️ Cookies YouTube устарели. Обнови их: update-cookies.sh.",
+		"⚠️ %s: cookies expired, continuing...":                                                         "⚠️ %s: cookies устарели, продолжаю...",
+		"⬇️ %s: Processing...":                                                                          "⬇️ %s: обрабатываю...",
+		"⏳ Processing...":                                                                               "⏳ Обрабатываю...",
+		"⏳ Processing %d videos...":                                                                     "⏳ Обрабатываю %d видео...",
+		"✅ Added %d/%d":                                                                                 "✅ Добавлено %d/%d",
+		"%d already in feed":                                                                            "%d уже в ленте",
+		"%d failed":                                                                                     "%d с ошибками",
+		" (%d failed)":                                                                                  " (%d с ошибками)",
+		"⚠️ Already in feed: %s":                                                                        "⚠️ Уже в ленте: %s",
+		"⚠️ Already exists: %s":                                                                         "⚠️ Уже есть: %s",
+		"⚠️ %s: %s (already in feed)":                                                                   "⚠️ %s: %s (уже в ленте)",
+		"⚠️ %s (already in feed)":                                                                       "⚠️ %s (уже в ленте)",
+		"⚠️ 🎙 %s (already in feed)":                                                                     "⚠️ 🎙 %s (уже в ленте)",
+		"📜 History (%d) — page %d/%d:\n\n":                                                              "📜 История (%d) — стр %d/%d:\n\n",
+		"Recent videos (%d) — page %d/%d:\n\n":                                                          "Последние записи (%d) — стр %d/%d:\n\n",
+		"  [deleted %s]":                                                                                "  [удалено %s]",
+		"  [deleted]":                                                                                   "  [удалено]",
+		"☁️ R2: %.1f GB / 10 GB":                                                                        "☁️ R2: %.1f ГБ / 10 ГБ",
+	},
+}
@@ -0,0 +1,160 @@
+package proc
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+func TestNormLang(t *testing.T) {
+	tests := map[string]string{"": "", "ru": langRU, "uk": langRU, "ru-RU": langRU, "EN": langEN, "en-US": langEN, "de": langEN, "pt_BR": langEN}
+	for code, want := range tests {
+		assert.Equal(t, want, normLang(code), code)
+	}
+}
+
+// trMessages returns the messages passed to tr in the package sources,
+// literals and string consts
+func trMessages(t *testing.T) []string {
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+	fset := token.NewFileSet()
+	consts := map[string]string{}
+	var calls []*ast.CallExpr
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		require.NoError(t, err)
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch x := n.(type) {
+			case *ast.ValueSpec:
+				for i, id := range x.Names {
+					if lit, ok := valueLit(x, i); ok {
+						consts[id.Name] = lit
+					}
+				}
+			case *ast.CallExpr:
+				calls = append(calls, x)
+			}
+			return true
+		})
+	}
+
+	var res []string
+	for _, c := range calls {
+		fn := ""
+		switch f := c.Fun.(type) {
+		case *ast.Ident:
+			fn = f.Name
+		case *ast.SelectorExpr:
+			fn = f.Sel.Name
+		}
+		if fn != "tr" || len(c.Args) != 1 {
+			continue
+		}
+		switch a := c.Args[0].(type) {
+		case *ast.BasicLit:
+			s, err := strconv.Unquote(a.Value)
+			require.NoError(t, err)
+			res = append(res, s)
+		case *ast.Ident:
+			if s, ok := consts[a.Name]; ok {
+				res = append(res, s)
+			}
+		}
+	}
+	return res
+}
+
+func valueLit(spec *ast.ValueSpec, i int) (string, bool) {
+	if i >= len(spec.Values) {
+		return "", false
+	}
+	lit, ok := spec.Values[i].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+func TestCatalogCoversMessages(t *testing.T) {
+	msgs := trMessages(t)
+	require.Greater(t, len(msgs), 100)
+	for _, kind := range []ytfeed.FailureKind{ytfeed.FailureAgeRestricted, ytfeed.FailureMembersOnly,
+		ytfeed.FailureGeoBlocked, ytfeed.FailurePrivate, ytfeed.FailureRemoved, ytfeed.FailureCookies} {
+		msgs = append(msgs, (&ytfeed.VideoError{Kind: kind, Err: errors.New("failed")}).Hint())
+	}
+	for _, c := range (&TelegramBot{}).botCommands() {
+		if c.desc != "" {
+			msgs = append(msgs, c.desc)
+		}
+	}
+
+	verbs := regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+	used := map[string]bool{}
+	for _, msg := range msgs {
+		used[msg] = true
+		lang := langRU // written in English, needs Russian
+		if strings.IndexFunc(msg, func(r rune) bool { return unicode.Is(unicode.Cyrillic, r) }) >= 0 {
+			lang = langEN
+		}
+		tr, ok := catalog[lang][msg]
+		if !assert.True(t, ok, "no %s translation of %q", lang, msg) {
+			continue
+		}
+		assert.Equal(t, verbs.FindAllString(msg, -1), verbs.FindAllString(tr, -1), "format verbs of %q", msg)
+	}
+	for lang, msgs := range catalog {
+		for msg := range msgs {
+			assert.True(t, used[msg], "stale %s translation of %q", lang, msg)
+		}
+	}
+}
+
+func TestTelegramBot_Lang(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Store = newTestJobStore(t)
+	user := &tb.User{ID: testBotUserID}
+	chat := &tb.Chat{ID: testBotUserID}
+
+	assert.Equal(t, "🎵 Аудио", bot.tr("🎵 Аудио"), "as written before the first message")
+	user.LanguageCode = "en-US"
+	require.True(t, bot.isAuthorized(user))
+	assert.Equal(t, "🎵 Audio", bot.tr("🎵 Аудио"))
+	assert.Equal(t, "Feed is empty.", bot.tr("Feed is empty."))
+	user.LanguageCode = "uk"
+	require.True(t, bot.isAuthorized(user))
+	assert.Equal(t, "Лента пуста.", bot.tr("Feed is empty."))
+
+	bot.handleLang(&tb.Message{Sender: user, Chat: chat, Text: "/lang en"})
+	assert.Equal(t, "🎵 Audio", bot.tr("🎵 Аудио"), "/lang wins over Telegram")
+	restarted := newTestBot(t, stub)
+	restarted.Store = bot.Store
+	restarted.loadUserLangs()
+	assert.Equal(t, "🎵 Audio", restarted.tr("🎵 Аудио"), "kept over restarts")
+
+	bot.handleLang(&tb.Message{Sender: user, Chat: chat, Text: "/lang auto"})
+	assert.Equal(t, "🎵 Аудио", bot.tr("🎵 Аудио"), "back to Telegram's language")
+	bot.handleLang(&tb.Message{Sender: user, Chat: chat, Text: "/lang de"})
+	bot.handleLang(&tb.Message{Sender: user, Chat: chat, Text: "/lang"})
+
+	assert.Equal(t, []string{"🌐 Message language changed", "🌐 Язык как в Telegram",
+		"Как: /lang ru|en|auto", "🌐 Язык: ru\nUsage: /lang ru|en|auto"}, stub.texts("sendMessage"))
+}
@@ -0,0 +1,130 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	tb "gopkg.in/tucnak/telebot.v2"
+)
+
+// forceWords in a message with a link start its job even when the same
+// source is being processed already
+var forceWords = map[string]bool{"again": true, "--force": true, "заново": true}
+
+// inflightJob counts the running jobs of one source and action
+type inflightJob struct {
+	count int
+	since time.Time // start of the oldest one
+}
+
+// splitForceFlag cuts the force words out of a message
+func splitForceFlag(text string) (force bool, rest string) {
+	words := strings.Fields(text)
+	kept := words[:0]
+	for _, w := range words {
+		if forceWords[strings.ToLower(w)] {
+			force = true
+			continue
+		}
+		kept = append(kept, w)
+	}
+	if !force {
+		return false, text
+	}
+	return true, strings.Join(kept, " ")
+}
+
+// inflightKeys returns the sources an action works on, keyed by what is done
+// with them, so audio and a voiceover of one video still run side by side.
+// Nil for actions deduplicated elsewhere (the notes queue) or cheap ones.
+func inflightKeys(pa *pendingAction, action string) []string {
+	var group string
+	switch {
+	case action == "audio" || action == "audio_notes":
+		group = "audio"
+	case action == "vo" && pa.kind != "podcast": // queued podcast voiceovers dedup in the notes queue
+		group = "vo"
+	case pa.kind == "article" && (action == "tts" || strings.HasPrefix(action, "long:")):
+		group = "tts"
+	default:
+		return nil
+	}
+	if pa.kind == "yt" {
+		keys := make([]string, 0, len(pa.videoIDs))
+		for _, id := range pa.videoIDs {
+			keys = append(keys, group+":yt:"+id)
+		}
+		return keys
+	}
+	return []string{group + ":" + pa.url}
+}
+
+// claimInflight marks the keys running. Unless forced, a key running already
+// fails the claim, since is then the start of its job. Release is safe to
+// call more than once.
+func (t *TelegramBot) claimInflight(keys []string, force bool) (release func(), since time.Time, ok bool) {
+	t.inflightMu.Lock()
+	defer t.inflightMu.Unlock()
+	if !force {
+		for _, k := range keys {
+			if job, running := t.inflight[k]; running {
+				return func() {}, job.since, false
+			}
+		}
+	}
+	if t.inflight == nil {
+		t.inflight = map[string]*inflightJob{}
+	}
+	now := time.Now()
+	for _, k := range keys {
+		if job, running := t.inflight[k]; running {
+			job.count++
+			continue
+		}
+		t.inflight[k] = &inflightJob{count: 1, since: now}
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.inflightMu.Lock()
+			defer t.inflightMu.Unlock()
+			for _, k := range keys {
+				if job, running := t.inflight[k]; running {
+					if job.count--; job.count <= 0 {
+						delete(t.inflight, k)
+					}
+				}
+			}
+		})
+	}, now, true
+}
+
+// goActionJob runs a job of a link menu action, holding the in-flight claim
+// of the action till the job is over
+func (t *TelegramBot) goActionJob(pa *pendingAction, timeout time.Duration, fn func(ctx context.Context)) {
+	release := pa.release
+	pa.release = nil // the job owns the claim now
+	t.goNamedJob(t.actionJobName(pa), timeout, func(ctx context.Context) {
+		if release != nil {
+			defer release()
+		}
+		fn(ctx)
+	})
+}
+
+// offerInflightOverride tells the same source is being processed and offers
+// a button starting the action anyway
+func (t *TelegramBot) offerInflightOverride(statusMsg *tb.Message, pa *pendingAction, action string, since time.Time) {
+	again := *pa
+	again.forceInflight, again.release = true, nil
+	token := t.storePendingAction(&again)
+	markup := &tb.ReplyMarkup{}
+	btnRun := markup.Data(t.tr("🔁 Всё равно запустить"), "act", token+"|"+action)
+	btnCancel := markup.Data(t.tr("🚫 Отмена"), "act", token+"|cancel")
+	markup.InlineKeyboard = [][]tb.InlineButton{{*btnRun.Inline(), *btnCancel.Inline()}}
+	t.edit(statusMsg, fmt.Sprintf(t.tr("⏳ Эта ссылка уже в работе с %s, результат придёт в её сообщение"),
+		since.Format("15:04")), markup)
+}
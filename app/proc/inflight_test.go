@@ -0,0 +1,96 @@
+package proc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitForceFlag(t *testing.T) {
+	force, rest := splitForceFlag("https://youtu.be/abc again")
+	assert.True(t, force)
+	assert.Equal(t, "https://youtu.be/abc", rest)
+	force, rest = splitForceFlag("--force tech https://example.com/a")
+	assert.True(t, force)
+	assert.Equal(t, "tech https://example.com/a", rest)
+	force, rest = splitForceFlag("  https://example.com/again ")
+	assert.False(t, force)
+	assert.Equal(t, "  https://example.com/again ", rest, "untouched without the flag")
+}
+
+func TestInflightKeys(t *testing.T) {
+	yt := &pendingAction{kind: "yt", videoIDs: []string{"a", "b"}}
+	assert.Equal(t, []string{"audio:yt:a", "audio:yt:b"}, inflightKeys(yt, "audio"))
+	assert.Equal(t, []string{"audio:yt:a", "audio:yt:b"}, inflightKeys(yt, "audio_notes"))
+	assert.Equal(t, []string{"vo:yt:a", "vo:yt:b"}, inflightKeys(yt, "vo"))
+	assert.Nil(t, inflightKeys(yt, "notes"), "the notes queue dedups itself")
+
+	article := &pendingAction{kind: "article", url: "https://example.com/a"}
+	assert.Equal(t, []string{"tts:https://example.com/a"}, inflightKeys(article, "tts"))
+	assert.Equal(t, []string{"tts:https://example.com/a"}, inflightKeys(article, "long:split"))
+	assert.Nil(t, inflightKeys(article, "read"))
+	assert.Nil(t, inflightKeys(&pendingAction{kind: "podcast", url: "u"}, "vo"))
+	assert.Equal(t, []string{"audio:u"}, inflightKeys(&pendingAction{kind: "podcast", url: "u"}, "audio"))
+}
+
+func TestTelegramBot_ClaimInflight(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	release, _, ok := bot.claimInflight([]string{"audio:yt:a"}, false)
+	require.True(t, ok)
+	_, _, ok = bot.claimInflight([]string{"audio:yt:b", "audio:yt:a"}, false)
+	assert.False(t, ok, "a running key fails the claim")
+	_, _, ok = bot.claimInflight([]string{"vo:yt:a"}, false)
+	assert.True(t, ok, "another action of the same video")
+
+	forced, _, ok := bot.claimInflight([]string{"audio:yt:a"}, true)
+	require.True(t, ok)
+	release()
+	release()
+	_, _, ok = bot.claimInflight([]string{"audio:yt:a"}, false)
+	assert.False(t, ok, "the forced job still runs")
+	forced()
+	_, _, ok = bot.claimInflight([]string{"audio:yt:a"}, false)
+	assert.True(t, ok)
+}
+
+func TestTelegramBot_RunActionInflight(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	release, _, ok := bot.claimInflight([]string{"tts:https://example.com/a"}, false)
+	require.True(t, ok)
+	defer release()
+
+	statusMsg := testMessage(testBotUserID, "🤔 Что сделать со ссылкой?")
+	bot.runAction(statusMsg.Chat, statusMsg, &pendingAction{kind: "article", url: "https://example.com/a"}, "tts")
+	edits := stub.texts("editMessageText")
+	require.Len(t, edits, 1)
+	assert.Contains(t, edits[0], "⏳ Эта ссылка уже в работе с ")
+	stub.mu.Lock()
+	markup := stub.calls[len(stub.calls)-1].Params["reply_markup"]
+	stub.mu.Unlock()
+	assert.Contains(t, markup, "Всё равно запустить")
+
+	bot.pendingMu.Lock()
+	defer bot.pendingMu.Unlock()
+	require.Len(t, bot.pendingActions, 1)
+	for _, pa := range bot.pendingActions {
+		assert.True(t, pa.forceInflight, "the button runs it forced")
+		assert.False(t, pa.force, "the duplicate check is still on")
+		assert.Equal(t, "https://example.com/a", pa.url)
+	}
+}
+
+func TestTelegramBot_HandleTextAgain(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	bot.TTSEnabled = true
+	bot.handleText(testMessage(testBotUserID, "https://example.com/post again"))
+
+	bot.pendingMu.Lock()
+	defer bot.pendingMu.Unlock()
+	require.Len(t, bot.pendingActions, 1)
+	for _, pa := range bot.pendingActions {
+		assert.True(t, pa.forceInflight)
+		assert.False(t, pa.force, "again doesn't skip the duplicate check")
+	}
+}
@@ -0,0 +1,90 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+)
+
+// ruMonths are genitive month names for spoken dates ("3 марта")
+var ruMonths = [...]string{"января", "февраля", "марта", "апреля", "мая", "июня", "июля", "августа",
+	"сентября", "октября", "ноября", "декабря"}
+
+// writePreamble puts the spoken preamble in front of an article and returns
+// its text, "" if there is none. A failed preamble is logged and skipped, the
+// article itself matters more; only a failed write to the episode file is an error.
+func (t *TelegramBot) writePreamble(ctx context.Context, w io.Writer, tts TTSProvider, article *Article, articleURL string) (string, error) {
+	if !t.Intro.Preamble || tts == nil {
+		return "", nil
+	}
+	text := articlePreamble(article, articleURL, time.Now())
+	audio, err := tts.Synthesize(ctx, text)
+	if err != nil {
+		log.Printf("[WARN] no preamble for %s: %v", articleURL, err)
+		return "", nil
+	}
+	if _, err := w.Write(audio); err != nil {
+		return "", fmt.Errorf("failed to write preamble: %w", err)
+	}
+	return text, nil
+}
+
+// writeJingle copies the jingle MP3 into the episode, "" = no jingle
+func (t *TelegramBot) writeJingle(w io.Writer, file string) error {
+	if file == "" {
+		return nil
+	}
+	audio, err := os.ReadFile(file) //nolint:gosec // path from the config
+	if err != nil {
+		log.Printf("[WARN] jingle skipped: %v", err)
+		return nil
+	}
+	if _, err := w.Write(audio); err != nil {
+		return fmt.Errorf("failed to write jingle: %w", err)
+	}
+	return nil
+}
+
+// articlePreamble is the spoken line before an article: where it comes from,
+// when it was published and how long it reads
+func articlePreamble(article *Article, articleURL string, now time.Time) string {
+	parts := []string{"Статья"}
+	if source := articleSource(article, articleURL); source != "" {
+		parts[0] = "Статья с сайта " + source
+	}
+	if !article.Published.IsZero() {
+		parts = append(parts, "опубликована "+spokenDate(article.Published, now))
+	}
+	if m := readingMinutes(article.TextContent); m > 0 {
+		parts = append(parts, fmt.Sprintf("время чтения %d %s", m, ruPlural(m, "минута", "минуты", "минут")))
+	}
+	return strings.Join(parts, ", ") + "."
+}
+
+// spokenDate is "3 марта", with the year for dates not from the current one
+func spokenDate(d, now time.Time) string {
+	res := fmt.Sprintf("%d %s", d.Day(), ruMonths[d.Month()-1])
+	if d.Year() != now.Year() {
+		res += fmt.Sprintf(" %d года", d.Year())
+	}
+	return res
+}
+
+// ruPlural picks the Russian plural form for n: one (1, 21), few (2-4, 22-24), many
+func ruPlural(n int, one, few, many string) string {
+	n %= 100
+	switch {
+	case n >= 11 && n <= 14:
+		return many
+	case n%10 == 1:
+		return one
+	case n%10 >= 2 && n%10 <= 4:
+		return few
+	}
+	return many
+}
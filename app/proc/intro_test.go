@@ -0,0 +1,88 @@
+package proc
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	"github.com/umputun/feed-master/app/config"
+)
+
+func TestArticlePreamble(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	text := strings.Repeat("слово ", 2300) // 12 minutes at 200 wpm
+
+	a := &Article{SiteName: "Хабр", TextContent: text, Published: time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC)}
+	assert.Equal(t, "Статья с сайта Хабр, опубликована 3 марта, время чтения 12 минут.",
+		articlePreamble(a, "https://habr.com/p/1", now))
+
+	a = &Article{TextContent: "одно слово", Published: time.Date(2024, 12, 21, 9, 0, 0, 0, time.UTC)}
+	assert.Equal(t, "Статья с сайта example.com, опубликована 21 декабря 2024 года, время чтения 1 минута.",
+		articlePreamble(a, "https://www.example.com/a", now))
+
+	assert.Equal(t, "Статья.", articlePreamble(&Article{}, "", now))
+}
+
+func TestRuPlural(t *testing.T) {
+	for n, want := range map[int]string{1: "минута", 2: "минуты", 5: "минут", 11: "минут", 12: "минут",
+		21: "минута", 22: "минуты", 111: "минут"} {
+		assert.Equal(t, want, ruPlural(n, "минута", "минуты", "минут"), n)
+	}
+}
+
+// recordingTTS remembers the texts it was asked to voice
+type recordingTTS struct {
+	FakeTTS
+	mu    sync.Mutex
+	texts []string
+}
+
+func (r *recordingTTS) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	r.mu.Lock()
+	r.texts = append(r.texts, text)
+	r.mu.Unlock()
+	return r.FakeTTS.Synthesize(ctx, text)
+}
+
+func TestTelegramBot_ProcessArticleIntro(t *testing.T) {
+	dir := t.TempDir()
+	jingle, outro := filepath.Join(dir, "jingle.mp3"), filepath.Join(dir, "outro.mp3")
+	require.NoError(t, os.WriteFile(jingle, []byte("JINGLE"), 0o600))
+	require.NoError(t, os.WriteFile(outro, []byte("OUTRO"), 0o600))
+
+	bot := newTestBot(t, newTgStub(t))
+	bot.Store = newTestJobStore(t)
+	bot.MaxItems = 10
+	tts := &recordingTTS{}
+	bot.TTS = tts
+	bot.Intro = config.Intro{Jingle: jingle, Outro: outro, Preamble: true}
+
+	statusMsg := &tb.Message{ID: 5, Chat: &tb.Chat{ID: testBotUserID}}
+	req := articleRequest{URL: "https://example.com/a", Article: &Article{Title: "Статья", SiteName: "Пример",
+		TextContent: "Короткий текст статьи."}}
+	require.NoError(t, bot.processArticle(context.Background(), nil, statusMsg, nil, req))
+
+	entries, err := bot.Store.Load(bot.FeedName, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	audio, err := os.ReadFile(entries[0].File)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(audio, []byte("JINGLE")), "jingle first")
+	assert.True(t, bytes.HasSuffix(audio, []byte("OUTRO")), "outro last")
+	require.NotEmpty(t, tts.texts)
+	assert.Equal(t, "Статья с сайта Пример, время чтения 1 минута.", tts.texts[0], "preamble voiced before the text")
+
+	// a missing jingle doesn't fail the article
+	bot.Intro = config.Intro{Jingle: filepath.Join(dir, "nope.mp3")}
+	req.URL = "https://example.com/b"
+	require.NoError(t, bot.processArticle(context.Background(), nil, statusMsg, nil, req))
+}
@@ -0,0 +1,131 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	"github.com/umputun/feed-master/app/config"
+)
+
+// errArticleTooLong is returned by processArticle for a text over the length
+// limit when the policy is "reject"
+var errArticleTooLong = errors.New("article is over the length limit")
+
+// checkArticleLimit rejects unknown policies of the length limit
+func checkArticleLimit(l config.ArticleLimit) error {
+	switch l.Policy {
+	case "", "ask", "reject", "summarize", "split":
+	default:
+		return fmt.Errorf("article_limit: unknown policy %q, want ask, reject, summarize or split", l.Policy)
+	}
+	switch l.Background {
+	case "", "reject", "summarize", "split":
+	default:
+		return fmt.Errorf("article_limit: unknown background policy %q, want reject, summarize or split", l.Background)
+	}
+	return nil
+}
+
+// backgroundLimitPolicy is what an over-long article of a background source
+// gets instead of the buttons, "reject" by default
+func (t *TelegramBot) backgroundLimitPolicy() string {
+	if t.ArticleLimit.Background == "" {
+		return "reject"
+	}
+	return t.ArticleLimit.Background
+}
+
+// offerLongArticleChoice replaces the status with the article length and
+// buttons to voice it in full, as a summary or in parts
+func (t *TelegramBot) offerLongArticleChoice(statusMsg, originalMsg *tb.Message, req articleRequest, article *Article) {
+	n := len([]rune(article.TextContent))
+	token := t.storePendingAction(&pendingAction{kind: "article", url: req.URL, preset: req.Preset, force: req.Force,
+		article: req.Article, originalMsg: originalMsg})
+	markup := &tb.ReplyMarkup{}
+	row := []tb.InlineButton{*markup.Data(t.tr("🔊 Целиком"), "act", token+"|long:full").Inline()}
+	if t.NotesSvc != nil && t.NotesSvc.Enricher != nil {
+		row = append(row, *markup.Data(t.tr("🧠 Выжимка"), "act", token+"|long:summarize").Inline())
+	}
+	parts := len(splitArticleParts(article.TextContent, t.partChars()))
+	row = append(row, *markup.Data(fmt.Sprintf(t.tr("✂️ Частями (%d)"), parts), "act", token+"|long:split").Inline())
+	markup.InlineKeyboard = [][]tb.InlineButton{row, {*markup.Data(t.tr("🚫 Отмена"), "act", token+"|cancel").Inline()}}
+	t.edit(statusMsg, fmt.Sprintf(t.tr("📏 Длинная статья: «%s», %d символов (~%s). Как озвучить?"),
+		article.Title, n, t.formatDuration(EstimateDuration(article.TextContent))), markup)
+}
+
+// voiceArticleParts voices an over-long article as separate feed entries of
+// PartChars each, "Title (часть 2/3)". The reader-mode copy goes with the
+// first part.
+func (t *TelegramBot) voiceArticleParts(ctx context.Context, statusMsg, originalMsg *tb.Message, article *Article,
+	articleURL, textHash string, archivePage []byte, preset config.Preset, warm <-chan struct{}) error {
+	parts := splitArticleParts(article.TextContent, t.partChars())
+	var total time.Duration
+	added := 0
+	for i, text := range parts {
+		part := *article
+		part.Title = fmt.Sprintf("%s (часть %d/%d)", article.Title, i+1, len(parts))
+		part.TextContent = text
+		partURL := fmt.Sprintf("%s#part%d", articleURL, i+1)
+		entry, created, err := t.voiceArticle(ctx, statusMsg, &part, partURL, textHash, archivePage, preset, warm)
+		if err != nil {
+			return fmt.Errorf("part %d/%d: %w", i+1, len(parts), err)
+		}
+		archivePage = nil
+		if !created {
+			log.Printf("[INFO] part %d of %s is in the feed already", i+1, articleURL)
+			continue
+		}
+		added++
+		total += time.Duration(entry.Duration) * time.Second
+	}
+	if added == 0 {
+		t.edit(statusMsg, fmt.Sprintf(t.tr("⚠️ Already exists: %s"), article.Title))
+	} else {
+		t.edit(statusMsg, fmt.Sprintf(t.tr("✅ 📖 %s: %d частей (%s)"), article.Title, added, t.formatDuration(total)))
+	}
+	t.deleteMessageAfterDelay(originalMsg, 5*time.Second)
+	return nil
+}
+
+// partChars is the size of a part for split articles
+func (t *TelegramBot) partChars() int {
+	if t.ArticleLimit.PartChars > 0 {
+		return t.ArticleLimit.PartChars
+	}
+	return 40000
+}
+
+// splitArticleParts cuts text into parts of about equal length, at most
+// size runes each unless a single paragraph is longer. Cuts go between
+// paragraphs so no part starts mid-sentence.
+func splitArticleParts(text string, size int) []string {
+	n := len([]rune(text))
+	if size <= 0 || n <= size {
+		return []string{text}
+	}
+	count := (n + size - 1) / size
+	target := n / count
+
+	var parts []string
+	var cur []string
+	curLen := 0
+	for _, line := range strings.Split(text, "\n") {
+		l := len([]rune(line))
+		if curLen > 0 && (curLen+l > size || curLen >= target) && len(parts) < count-1 {
+			parts = append(parts, strings.Join(cur, "\n"))
+			cur, curLen = nil, 0
+		}
+		cur = append(cur, line)
+		curLen += l + 1
+	}
+	if len(cur) > 0 {
+		parts = append(parts, strings.Join(cur, "\n"))
+	}
+	return parts
+}
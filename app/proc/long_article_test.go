@@ -0,0 +1,120 @@
+package proc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	"github.com/umputun/feed-master/app/config"
+)
+
+func TestSplitArticleParts(t *testing.T) {
+	para := strings.Repeat("слово ", 20) // 120 runes
+	text := strings.TrimSuffix(strings.Repeat(para+"\n", 10), "\n")
+
+	parts := splitArticleParts(text, 500)
+	require.Len(t, parts, 3)
+	assert.Equal(t, text, strings.Join(parts, "\n"), "nothing lost")
+	for _, p := range parts {
+		assert.LessOrEqual(t, len([]rune(p)), 500)
+		assert.True(t, strings.HasPrefix(p, "слово"), "cut between paragraphs")
+	}
+
+	assert.Equal(t, []string{"short"}, splitArticleParts("short", 500))
+	assert.Len(t, splitArticleParts(strings.Repeat("x", 1000), 500), 1, "a paragraph isn't cut")
+}
+
+func TestCheckArticleLimit(t *testing.T) {
+	require.NoError(t, checkArticleLimit(config.ArticleLimit{Policy: "ask", Background: "summarize"}))
+	require.NoError(t, checkArticleLimit(config.ArticleLimit{}))
+	require.Error(t, checkArticleLimit(config.ArticleLimit{Policy: "sumarize"}))
+	require.Error(t, checkArticleLimit(config.ArticleLimit{Policy: "ask", Background: "ask"}), "no one to ask")
+}
+
+func TestTelegramBot_ProcessArticleLong(t *testing.T) {
+	text := strings.TrimSuffix(strings.Repeat(strings.Repeat("Длинный абзац статьи. ", 10)+"\n", 12), "\n")
+	newArticle := func() *Article { return &Article{Title: "Большая статья", TextContent: text} }
+	statusMsg := &tb.Message{ID: 5, Chat: &tb.Chat{ID: testBotUserID}}
+	ctx := context.Background()
+
+	setup := func(policy string) (*TelegramBot, *tgStub) {
+		stub := newTgStub(t)
+		bot := newTestBot(t, stub)
+		bot.Store = newTestJobStore(t)
+		bot.MaxItems = 10
+		bot.ArticleLimit = config.ArticleLimit{MaxChars: 1000, Policy: policy, PartChars: 1200}
+		return bot, stub
+	}
+
+	t.Run("ask", func(t *testing.T) {
+		bot, stub := setup("ask")
+		req := articleRequest{URL: "https://example.com/long", Article: newArticle()}
+		require.NoError(t, bot.processArticle(ctx, nil, statusMsg, nil, req))
+		edits := stub.texts("editMessageText")
+		assert.Contains(t, edits[len(edits)-1], "Длинная статья: «Большая статья»")
+		require.Len(t, bot.pendingActions, 1)
+		for _, pa := range bot.pendingActions {
+			assert.Equal(t, req.Article, pa.article, "pre-extracted text kept for the choice")
+		}
+		entries, _ := bot.Store.Load(bot.FeedName, 10)
+		assert.Empty(t, entries, "nothing voiced before the choice")
+
+		req.LongText = "full"
+		require.NoError(t, bot.processArticle(ctx, nil, statusMsg, nil, req))
+		entries, err := bot.Store.Load(bot.FeedName, 10)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		bot, _ := setup("reject")
+		err := bot.processArticle(ctx, nil, statusMsg, nil, articleRequest{URL: "https://example.com/long", Article: newArticle()})
+		require.ErrorIs(t, err, errArticleTooLong)
+		assert.Contains(t, bot.userErrorText(err), "длиннее лимита")
+	})
+
+	t.Run("ask in background", func(t *testing.T) {
+		bot, _ := setup("ask")
+		req := articleRequest{URL: "https://example.com/long", Article: newArticle(), Background: true}
+		err := bot.processArticle(ctx, nil, statusMsg, nil, req)
+		require.ErrorIs(t, err, errArticleTooLong, "no buttons without a user, rejected by default")
+		assert.Empty(t, bot.pendingActions)
+
+		bot.ArticleLimit.Background = "split"
+		require.NoError(t, bot.processArticle(ctx, nil, statusMsg, nil, req))
+		entries, err := bot.Store.Load(bot.FeedName, 10)
+		require.NoError(t, err)
+		assert.Len(t, entries, 3)
+	})
+
+	t.Run("split", func(t *testing.T) {
+		bot, stub := setup("split")
+		require.NoError(t, bot.processArticle(ctx, nil, statusMsg, nil,
+			articleRequest{URL: "https://example.com/long", Article: newArticle()}))
+		entries, err := bot.Store.Load(bot.FeedName, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		var titles []string
+		for _, e := range entries {
+			titles = append(titles, e.Title)
+			assert.Contains(t, e.Link.Href, "https://example.com/long#part")
+		}
+		assert.ElementsMatch(t, []string{"Большая статья (часть 1/3)", "Большая статья (часть 2/3)",
+			"Большая статья (часть 3/3)"}, titles)
+		edits := stub.texts("editMessageText")
+		assert.Contains(t, edits[len(edits)-1], "3 частей")
+	})
+
+	t.Run("under the limit", func(t *testing.T) {
+		bot, _ := setup("reject")
+		short := &Article{Title: "Короткая", TextContent: "Короткий текст статьи."}
+		require.NoError(t, bot.processArticle(ctx, nil, statusMsg, nil, articleRequest{URL: "https://example.com/short", Article: short}))
+		entries, err := bot.Store.Load(bot.FeedName, 10)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+}
@@ -0,0 +1,305 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	"golang.org/x/net/html/charset"
+)
+
+// MailMessage is the readable part of an inbound mail
+type MailMessage struct {
+	MessageID string
+	From      string // sender address, lowercase
+	FromName  string
+	Subject   string
+	HTML      string // text/html body, "" if the mail has none
+	Text      string // text/plain body
+}
+
+// ErrMailRejected is returned by a MailGateway handler for mails it doesn't
+// take, the sender gets a permanent SMTP failure
+var ErrMailRejected = errors.New("mail rejected")
+
+// MailGateway is a minimal inbound SMTP server: mails sent to the configured
+// addresses are parsed and handed to Handle. It has no TLS and no auth and the
+// From header it checks is easy to forge, so it must sit behind an MTA that
+// authenticates the senders and relays to it on a private address.
+type MailGateway struct {
+	Addr       string   // listen address, e.g. ":2525", a port alone binds to 127.0.0.1
+	Hostname   string   // name in the greeting, default "turnip"
+	Recipients []string // accepted RCPT TO addresses, required
+	Senders    []string // allowed From addresses or "@domain" suffixes, required
+	MaxSize    int64    // message size limit, default 10MB
+	Handle     func(ctx context.Context, msg *MailMessage) error
+}
+
+// Run accepts SMTP connections until ctx is done. It refuses to start without
+// the recipient and sender lists, an open gateway would voice anyone's mail.
+func (g *MailGateway) Run(ctx context.Context) error {
+	if len(g.Recipients) == 0 || len(g.Senders) == 0 {
+		return errors.New("mail gateway needs recipients and senders")
+	}
+	addr := listenAddr(g.Addr)
+	var lc net.ListenConfig
+	ln, err := lc.Listen(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("mail gateway listen %s: %w", addr, err)
+	}
+	log.Printf("[INFO] mail gateway listening on %s", ln.Addr())
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+	}()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Printf("[WARN] mail gateway accept: %v", err)
+			continue
+		}
+		go g.serve(ctx, conn)
+	}
+}
+
+// serve talks SMTP on one connection: HELO/EHLO, MAIL, RCPT, DATA, RSET, NOOP, QUIT
+func (g *MailGateway) serve(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	host := g.Hostname
+	if host == "" {
+		host = "turnip"
+	}
+	maxSize := g.MaxSize
+	if maxSize <= 0 {
+		maxSize = 10 * 1024 * 1024
+	}
+	tp := textproto.NewConn(conn)
+	reply := func(format string, args ...any) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(time.Minute))
+		return tp.PrintfLine(format, args...) == nil
+	}
+	if !reply("220 %s ESMTP", host) {
+		return
+	}
+
+	var from string
+	var rcpts int
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Minute))
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			reply("250 %s", host)
+		case "EHLO":
+			reply("250-%s\r\n250-SIZE %d\r\n250 8BITMIME", host, maxSize)
+		case "MAIL":
+			from, rcpts = smtpPath(arg, "FROM:"), 0
+			reply("250 OK")
+		case "RCPT":
+			to := smtpPath(arg, "TO:")
+			if !g.acceptsRecipient(to) {
+				reply("550 no such mailbox")
+				continue
+			}
+			rcpts++
+			reply("250 OK")
+		case "DATA":
+			if rcpts == 0 {
+				reply("503 need RCPT first")
+				continue
+			}
+			reply("354 end with <CRLF>.<CRLF>")
+			data, err := io.ReadAll(io.LimitReader(tp.DotReader(), maxSize+1))
+			if err != nil {
+				return
+			}
+			rcpts = 0
+			if int64(len(data)) > maxSize {
+				reply("552 message too big")
+				continue
+			}
+			switch err := g.deliver(ctx, data); {
+			case errors.Is(err, ErrMailRejected):
+				log.Printf("[INFO] mail from %s rejected: %v", from, err)
+				reply("550 %s", err.Error())
+			case err != nil:
+				log.Printf("[WARN] mail from %s not taken: %v", from, err)
+				reply("451 try again later")
+			default:
+				reply("250 queued")
+			}
+		case "RSET":
+			from, rcpts = "", 0
+			reply("250 OK")
+		case "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+// deliver parses a received message, checks the sender and hands it over
+func (g *MailGateway) deliver(ctx context.Context, data []byte) error {
+	msg, err := ParseMail(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMailRejected, err)
+	}
+	if !g.acceptsSender(msg.From) {
+		return fmt.Errorf("%w: sender %s not allowed", ErrMailRejected, msg.From)
+	}
+	if g.Handle == nil {
+		return nil
+	}
+	return g.Handle(ctx, msg)
+}
+
+func (g *MailGateway) acceptsRecipient(addr string) bool {
+	for _, r := range g.Recipients {
+		if strings.EqualFold(r, addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsSender matches the From address against the list, an entry starting
+// with "@" allows the whole domain
+func (g *MailGateway) acceptsSender(addr string) bool {
+	addr = strings.ToLower(addr)
+	for _, s := range g.Senders {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s == addr || (strings.HasPrefix(s, "@") && strings.HasSuffix(addr, s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// listenAddr binds a port without a host (":2525") to the loopback, all
+// interfaces take an explicit "0.0.0.0:2525"
+func listenAddr(addr string) string {
+	if host, port, err := net.SplitHostPort(addr); err == nil && host == "" {
+		return net.JoinHostPort("127.0.0.1", port)
+	}
+	return addr
+}
+
+// smtpPath extracts the address of "FROM:<a@b> SIZE=1" style arguments
+func smtpPath(arg, prefix string) string {
+	if len(arg) >= len(prefix) && strings.EqualFold(arg[:len(prefix)], prefix) {
+		arg = arg[len(prefix):]
+	}
+	arg = strings.TrimSpace(arg)
+	if i := strings.IndexByte(arg, ' '); i >= 0 {
+		arg = arg[:i]
+	}
+	return strings.Trim(arg, "<>")
+}
+
+// ParseMail reads a MIME message and returns its headers of interest and the
+// first text/html and text/plain bodies, decoded to UTF-8
+func ParseMail(r io.Reader) (*MailMessage, error) {
+	m, err := mail.ReadMessage(bufio.NewReader(r))
+	if err != nil {
+		return nil, fmt.Errorf("bad message: %w", err)
+	}
+	dec := &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+	res := &MailMessage{MessageID: strings.Trim(m.Header.Get("Message-Id"), "<> ")}
+	if res.Subject, err = dec.DecodeHeader(m.Header.Get("Subject")); err != nil {
+		res.Subject = m.Header.Get("Subject")
+	}
+	addrParser := &mail.AddressParser{WordDecoder: dec}
+	if from, err := addrParser.Parse(m.Header.Get("From")); err == nil {
+		res.From, res.FromName = strings.ToLower(from.Address), from.Name
+	}
+	if res.From == "" {
+		return nil, errors.New("no sender address")
+	}
+	if err := res.readPart(textproto.MIMEHeader(m.Header), m.Body, 0); err != nil {
+		return nil, err
+	}
+	if res.HTML == "" && res.Text == "" {
+		return nil, errors.New("no text body")
+	}
+	return res, nil
+}
+
+// readPart collects text bodies of a part, recursing into multiparts
+func (m *MailMessage) readPart(h textproto.MIMEHeader, body io.Reader, depth int) error {
+	const maxDepth = 10
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxDepth {
+			return nil
+		}
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			p, err := mr.NextPart()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("bad multipart: %w", err)
+			}
+			if err := m.readPart(p.Header, p, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	if mediaType != "text/html" && mediaType != "text/plain" {
+		return nil
+	}
+	if strings.HasPrefix(strings.ToLower(h.Get("Content-Disposition")), "attachment") {
+		return nil
+	}
+
+	switch strings.ToLower(h.Get("Content-Transfer-Encoding")) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	if cs := params["charset"]; cs != "" {
+		if body, err = charset.NewReaderLabel(cs, body); err != nil {
+			return fmt.Errorf("unsupported charset %s: %w", cs, err)
+		}
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("bad %s body: %w", mediaType, err)
+	}
+	switch {
+	case mediaType == "text/html" && m.HTML == "":
+		m.HTML = string(data)
+	case mediaType == "text/plain" && m.Text == "":
+		m.Text = string(data)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package proc
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testNewsletter = "From: =?utf-8?B?0KDQsNGB0YHRi9C70LrQsA==?= <news@letters.example.com>\r\n" +
+	"To: feed@turnip.local\r\n" +
+	"Subject: =?windows-1251?Q?=C2=FB=EF=F3=F1=EA_1?=\r\n" +
+	"Message-ID: <abc123@letters.example.com>\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/alternative; boundary=\"b1\"\r\n" +
+	"\r\n" +
+	"--b1\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"0J/RgNC40LLQtdGCLCDRjdGC0L4g0YLQtdC60YHRgi4=\r\n" +
+	"--b1\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"<html><body><article><p>=D0=9F=D1=80=D0=B8=D0=B2=D0=B5=D1=82 html</p></article></body></html>\r\n" +
+	"--b1--\r\n"
+
+func TestParseMail(t *testing.T) {
+	msg, err := ParseMail(strings.NewReader(testNewsletter))
+	require.NoError(t, err)
+	assert.Equal(t, "abc123@letters.example.com", msg.MessageID)
+	assert.Equal(t, "news@letters.example.com", msg.From)
+	assert.Equal(t, "Рассылка", msg.FromName)
+	assert.Equal(t, "Выпуск 1", msg.Subject)
+	assert.Equal(t, "Привет, это текст.", msg.Text)
+	assert.Contains(t, msg.HTML, "<p>Привет html</p>")
+
+	_, err = ParseMail(strings.NewReader("Subject: x\r\n\r\nbody"))
+	require.Error(t, err, "no sender")
+}
+
+func TestMailGateway_Filters(t *testing.T) {
+	g := &MailGateway{Recipients: []string{"feed@turnip.local"}, Senders: []string{"@letters.example.com", "me@mail.com"}}
+	assert.True(t, g.acceptsRecipient("FEED@turnip.local"))
+	assert.False(t, g.acceptsRecipient("other@turnip.local"))
+	assert.True(t, g.acceptsSender("news@letters.example.com"))
+	assert.True(t, g.acceptsSender("Me@Mail.com"))
+	assert.False(t, g.acceptsSender("spam@example.com"))
+	assert.False(t, (&MailGateway{}).acceptsSender("anyone@x.com"), "no list, no sender")
+	assert.False(t, (&MailGateway{}).acceptsRecipient("feed@turnip.local"), "no list, no recipient")
+	assert.Equal(t, "127.0.0.1:2525", listenAddr(":2525"))
+	assert.Equal(t, "0.0.0.0:2525", listenAddr("0.0.0.0:2525"))
+	assert.Equal(t, "a@b.com", smtpPath("FROM:<a@b.com> SIZE=100", "FROM:"))
+	assert.Equal(t, "a@b.com", smtpPath("to: <a@b.com>", "TO:"))
+}
+
+func TestMailGateway_RunOpen(t *testing.T) {
+	err := (&MailGateway{Addr: ":0", Senders: []string{"@letters.example.com"}}).Run(context.Background())
+	require.Error(t, err, "no recipients")
+	err = (&MailGateway{Addr: ":0", Recipients: []string{"feed@turnip.local"}}).Run(context.Background())
+	require.Error(t, err, "no senders")
+}
+
+func TestMailGateway_Session(t *testing.T) {
+	var got []*MailMessage
+	g := &MailGateway{Recipients: []string{"feed@turnip.local"}, Senders: []string{"@letters.example.com"},
+		Handle: func(_ context.Context, msg *MailMessage) error {
+			got = append(got, msg)
+			return nil
+		}}
+	server, client := net.Pipe()
+	go g.serve(context.Background(), server)
+	c := textproto.NewConn(client)
+	defer c.Close()
+
+	expect := func(code int) {
+		t.Helper()
+		_, _, err := c.ReadResponse(code)
+		require.NoError(t, err)
+	}
+	send := func(line string, code int) {
+		t.Helper()
+		require.NoError(t, c.PrintfLine("%s", line))
+		expect(code)
+	}
+	expect(220)
+	send("EHLO client", 250)
+	send("MAIL FROM:<bounce@letters.example.com>", 250)
+	send("RCPT TO:<nobody@turnip.local>", 550)
+	send("DATA", 503)
+	send("RCPT TO:<feed@turnip.local>", 250)
+	send("DATA", 354)
+	w := c.DotWriter()
+	_, err := w.Write([]byte(strings.ReplaceAll(testNewsletter, "\r\n", "\n")))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	expect(250)
+
+	send("MAIL FROM:<spam@example.com>", 250)
+	send("RCPT TO:<feed@turnip.local>", 250)
+	send("DATA", 354)
+	w = c.DotWriter()
+	_, err = w.Write([]byte(strings.ReplaceAll(testNewsletter, "news@letters.example.com", "spam@example.com")))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	expect(550)
+	send("QUIT", 221)
+
+	require.Len(t, got, 1)
+	assert.Equal(t, "Выпуск 1", got[0].Subject)
+}
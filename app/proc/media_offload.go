@@ -29,16 +29,32 @@ const r2WarnEvery = 24 * time.Hour
 
 // offloadMedia uploads a saved episode to R2 in the background and removes
 // the local copy on success. On failure the file stays and /yt/media serves
-// it from disk — nothing breaks, only egress money leaks.
+// it from disk — nothing breaks, only egress money leaks. The sped-up copy
+// (SpeedVariant) and the waveform are made first, they need the local file;
+// the copy goes to R2 too, the waveform stays local.
 func (t *TelegramBot) offloadMedia(entry ytfeed.Entry) {
-	if t.Media == nil || entry.File == "" {
+	if entry.File == "" || (t.Media == nil && t.SpeedVariant <= 0 && !t.Waveform) {
 		return
 	}
-	file := entry.File
 	go func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Minute)
 		defer cancel()
-		if t.offloadMediaSync(ctx, file) {
+		t.makePeaks(ctx, entry)
+		files := []string{entry.File}
+		if variant := t.makeSpeedVariant(ctx, entry); variant != "" {
+			files = append(files, variant)
+			t.announceFeed(true) // the sped-up feed has the episode at its speed now
+		}
+		if t.Media == nil {
+			return
+		}
+		offloaded := false
+		for _, f := range files {
+			if t.offloadMediaSync(ctx, f) {
+				offloaded = true
+			}
+		}
+		if offloaded {
 			t.checkR2Usage(ctx)
 		}
 	}()
@@ -98,7 +114,7 @@ func (t *TelegramBot) checkR2Usage(ctx context.Context) {
 	}
 
 	t.NotifyOwner(fmt.Sprintf(
-		"⚠️ R2 занято %.1f GB из 10 бесплатных.\nПрослушанное можно удалить: /list → 🗑 — файл в R2 удалится вместе с записью, ссылка останется в /history.",
+		t.tr("⚠️ R2 занято %.1f GB из 10 бесплатных.\nПрослушанное можно удалить: /list → 🗑 — файл в R2 удалится вместе с записью, ссылка останется в /history."),
 		float64(total)/(1<<30)))
 }
 
@@ -111,7 +127,7 @@ func (t *TelegramBot) r2UsageLine() string {
 	defer cancel()
 	total, err := t.Media.TotalSize(ctx)
 	if err != nil {
-		return "☁️ R2: недоступно"
+		return t.tr("☁️ R2: недоступно")
 	}
-	return fmt.Sprintf("☁️ R2: %.1f GB / 10 GB", float64(total)/(1<<30))
+	return fmt.Sprintf(t.tr("☁️ R2: %.1f GB / 10 GB"), float64(total)/(1<<30))
 }
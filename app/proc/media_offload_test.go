@@ -73,17 +73,18 @@ func TestLLMRateLine(t *testing.T) {
 	llmRate.remaining, llmRate.limit = "", ""
 	llmRate.mu.Unlock()
 
-	assert.Equal(t, "", llmRateLine(), "empty before the first LLM call")
+	bot := &TelegramBot{}
+	assert.Equal(t, "", bot.llmRateLine(), "empty before the first LLM call")
 
 	h := make(map[string][]string)
 	h["X-Ratelimit-Remaining-Tokens"] = []string{"34000"}
 	h["X-Ratelimit-Limit-Tokens"] = []string{"100000"}
 	captureRateHeaders(h)
-	line := llmRateLine()
+	line := bot.llmRateLine()
 	assert.Contains(t, line, "34000")
 	assert.Contains(t, line, "100000")
 	assert.Contains(t, line, "только что")
 
 	captureRateHeaders(map[string][]string{"Content-Type": {"application/json"}})
-	assert.Contains(t, llmRateLine(), "34000", "headers without rate info don't wipe the snapshot")
+	assert.Contains(t, bot.llmRateLine(), "34000", "headers without rate info don't wipe the snapshot")
 }
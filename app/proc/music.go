@@ -0,0 +1,72 @@
+package proc
+
+import (
+	"strings"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// musicTitleMarkers are title fragments labels put on music uploads
+var musicTitleMarkers = []string{
+	"official music video", "official video", "official audio", "lyric video",
+	"lyrics video", "(lyrics)", "[lyrics]", "official visualizer", "(audio)", "[audio]",
+	"клип", "премьера клипа", "official mv", "(mv)", "[mv]",
+}
+
+// musicDescMarkers are description fragments of auto-generated and label uploads
+var musicDescMarkers = []string{
+	"provided to youtube by", "auto-generated by youtube", "℗", "stream/download",
+	"listen on spotify", "spotify.link", "music.apple.com",
+}
+
+// maxMusicDuration caps what counts as a song: long mixes and live
+// concerts in the Music category still may carry talk worth translating
+const maxMusicDuration = 12 * 60
+
+// IsMusicContent guesses whether a video is a song or a music clip.
+// Translating such videos (vot-cli or subtitles → TTS) produces a robot
+// reading lyrics over nothing, so /vo suggests the original audio instead.
+func IsMusicContent(info *ytfeed.VideoInfo) bool {
+	if info == nil {
+		return false
+	}
+	if info.Duration > maxMusicDuration {
+		return false
+	}
+	if strings.HasSuffix(info.Uploader, " - Topic") {
+		return true // auto-generated artist channel
+	}
+
+	score := 0
+	// yt-dlp fills track/artist from YouTube Music metadata, which podcasts
+	// and talks with a soundtrack get too, one signal of several
+	if info.Track != "" && info.Artist != "" {
+		score += 2
+	}
+	for _, c := range info.Categories {
+		if strings.EqualFold(c, "Music") {
+			score += 2
+		}
+	}
+	title := strings.ToLower(info.Title)
+	for _, m := range musicTitleMarkers {
+		if strings.Contains(title, m) {
+			score += 2
+			break
+		}
+	}
+	desc := strings.ToLower(info.Description)
+	for _, m := range musicDescMarkers {
+		if strings.Contains(desc, m) {
+			score++
+			break
+		}
+	}
+	if strings.HasSuffix(strings.ToLower(info.Uploader), "vevo") {
+		score += 2
+	}
+	// no signal alone is enough: interviews with musicians and music-theory
+	// lectures land in the Music category, podcasts get track metadata; a
+	// second signal is required
+	return score >= 3
+}
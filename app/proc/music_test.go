@@ -0,0 +1,97 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	"github.com/umputun/feed-master/app/config"
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	"github.com/umputun/feed-master/app/youtube/feed/mocks"
+)
+
+func TestIsMusicContent(t *testing.T) {
+	tbl := []struct {
+		name string
+		info *ytfeed.VideoInfo
+		want bool
+	}{
+		{"nil", nil, false},
+		{"track metadata and music category",
+			&ytfeed.VideoInfo{Track: "Song", Artist: "Band", Categories: []string{"Music"}, Duration: 200}, true},
+		{"track metadata alone", &ytfeed.VideoInfo{Title: "Episode 12: on habits", Track: "Episode 12", Artist: "The Show",
+			Categories: []string{"Education"}, Duration: 600}, false},
+		{"topic channel", &ytfeed.VideoInfo{Uploader: "Band - Topic", Duration: 200}, true},
+		{"music category and title marker",
+			&ytfeed.VideoInfo{Title: "Band - Song (Official Music Video)", Categories: []string{"Music"}, Duration: 240}, true},
+		{"music category and label description",
+			&ytfeed.VideoInfo{Title: "Song", Description: "Provided to YouTube by Label", Categories: []string{"Music"}, Duration: 180}, true},
+		{"vevo and marker", &ytfeed.VideoInfo{Title: "Song (Official Video)", Uploader: "BandVEVO", Duration: 200}, true},
+		{"music category alone", &ytfeed.VideoInfo{Title: "Interview with the band", Categories: []string{"Music"}, Duration: 300}, false},
+		{"long concert", &ytfeed.VideoInfo{Track: "Live", Artist: "Band", Duration: 2 * 60 * 60}, false},
+		{"lecture", &ytfeed.VideoInfo{Title: "Go concurrency patterns", Categories: []string{"Education"}, Duration: 600}, false},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsMusicContent(tt.info))
+		})
+	}
+}
+
+func TestTelegramBot_VoiceoverBatchMusic(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Store = newTestJobStore(t)
+	bot.Downloader = &ytfeed.Downloader{Runner: &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			return []byte(`{"id":"song1","title":"Band - Song (Official Video)","uploader":"BandVEVO","duration":200}`), nil, nil
+		},
+	}}
+	chat := &tb.Chat{ID: testBotUserID}
+	statusMsg, err := bot.Bot.Send(chat, "⏳")
+	require.NoError(t, err)
+
+	bot.processVoiceoverBatch(context.Background(), chat, statusMsg, nil, []string{"song1", "song2"}, config.Preset{})
+	var offers int
+	for _, s := range stub.texts("sendMessage") {
+		if strings.Contains(s, "похоже на музыку") {
+			offers++
+		}
+	}
+	assert.Equal(t, 2, offers, "an offer of its own per song")
+	edits := stub.texts("editMessageText")
+	require.NotEmpty(t, edits)
+	assert.Contains(t, edits[len(edits)-1], "Музыка, без перевода (2)", "the shared status keeps the summary")
+	for _, e := range edits {
+		assert.NotContains(t, e, "похоже на музыку", "the shared status isn't taken by an offer")
+	}
+}
+
+func TestTelegramBot_VoiceoverWebMusic(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Store = newTestJobStore(t)
+	bot.Downloader = &ytfeed.Downloader{Runner: &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			return []byte(`{"id":"1","title":"Band - Song (Official Video)","uploader":"Band - Topic","duration":200}`), nil, nil
+		},
+	}}
+	chat := &tb.Chat{ID: testBotUserID}
+	statusMsg, err := bot.Bot.Send(chat, "⏳")
+	require.NoError(t, err)
+
+	videoURL := "https://vimeo.com/76979871"
+	err = bot.processVoiceover(context.Background(), chat, statusMsg, nil, videoURL, sourceID(videoURL),
+		config.Preset{Methods: []string{"nope"}})
+	require.Error(t, err)
+	var music *musicContentError
+	assert.False(t, errors.As(err, &music), "no original audio offer, it downloads YouTube ids only")
+	for _, e := range stub.texts("editMessageText") {
+		assert.NotContains(t, e, "похоже на музыку")
+	}
+}
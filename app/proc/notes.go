@@ -13,6 +13,8 @@ import (
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/umputun/feed-master/app/tools"
+	"github.com/umputun/feed-master/app/tracing"
 	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
 	ytstore "github.com/umputun/feed-master/app/youtube/store"
 )
@@ -337,6 +339,10 @@ func (n *NotesService) drainQueue(ctx context.Context) {
 
 // runJob processes one claimed job and stores the final status
 func (n *NotesService) runJob(ctx context.Context, job ytstore.NotesJobRecord) {
+	ctx, done := tools.StartJob(ctx, job.ID)
+	defer done()
+	ctx, span := tracing.Start(ctx, "job", "job.id", job.ID, "job.kind", "notes "+job.Level)
+	defer span.End()
 	res, err := n.process(ctx, job)
 	job.UpdatedAt = time.Now().UTC()
 	if err != nil {
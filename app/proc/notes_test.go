@@ -169,16 +169,17 @@ func repeatRunes(r rune, n int) string {
 }
 
 func TestNoteCaption(t *testing.T) {
+	bot := &TelegramBot{}
 	res := NotesResult{
 		Meta:      NoteMeta{DurationMin: 94, Tags: []string{"design", "history"}},
 		WordCount: 12340,
 	}
-	assert.Equal(t, "⏱ 1ч 34м · 12340 слов · 🏷 design, history", noteCaption(res))
+	assert.Equal(t, "⏱ 1ч 34м · 12340 слов · 🏷 design, history", bot.noteCaption(res))
 
 	short := NotesResult{Meta: NoteMeta{DurationMin: 42}}
-	assert.Equal(t, "⏱ 42м", noteCaption(short))
+	assert.Equal(t, "⏱ 42м", bot.noteCaption(short))
 
-	assert.Equal(t, "", noteCaption(NotesResult{}))
+	assert.Equal(t, "", bot.noteCaption(NotesResult{}))
 }
 
 func TestNotesEnqueueDedupAndOverflow(t *testing.T) {
@@ -0,0 +1,171 @@
+package proc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+)
+
+// partialVoiceKeep is how long the progress of a failed voiceover waits for
+// a retry before it's dropped
+const partialVoiceKeep = 7 * 24 * time.Hour
+
+// partialVoicePrefix starts the names of the progress files in the files location
+const partialVoicePrefix = ".partial_"
+
+// partialVoice is the progress of a long voiceover kept on disk: the text
+// voiced and the audio of the chunks done so far. A retry of a failed job
+// goes on from the chunk it stopped at instead of starting over.
+type partialVoice struct {
+	Key        string `json:"key"`        // source text, voice and translator the progress is of
+	Translated bool   `json:"translated"` // the text is a translation
+	Chunks     int    `json:"chunks"`     // TTS requests of the text
+	Done       int    `json:"done"`       // chunks in the audio
+	Size       int64  `json:"size"`       // audio bytes of the done chunks
+
+	Text string `json:"-"` // text voiced, kept in its own file
+	base string // files path without the extension
+}
+
+// loadPartialVoice returns the progress of the voiceover id made of the key,
+// an empty one when there is none or it was of another text or voice
+func loadPartialVoice(dir, id, key string) *partialVoice {
+	p := &partialVoice{base: filepath.Join(dir, partialVoicePrefix+id)}
+	data, err := os.ReadFile(p.base + ".json")
+	if err == nil {
+		err = json.Unmarshal(data, p)
+	}
+	if err == nil && p.Key == key {
+		text, terr := os.ReadFile(p.base + ".txt")
+		if terr == nil && len(text) > 0 {
+			p.Text = string(text)
+			return p
+		}
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		p.remove() // of another text, or broken
+	}
+	return &partialVoice{Key: key, base: p.base}
+}
+
+// audioFile is the audio of the done chunks
+func (p *partialVoice) audioFile() string {
+	return p.base + ".mp3"
+}
+
+// start keeps the text to voice, dropping the audio of another one
+func (p *partialVoice) start(text string, translated bool) error {
+	p.Text, p.Translated, p.Chunks, p.Done, p.Size = text, translated, 0, 0, 0
+	if err := writeAtomic(p.base+".txt", []byte(text), 0o644); err != nil {
+		return fmt.Errorf("failed to save voiced text: %w", err)
+	}
+	return p.save()
+}
+
+// save writes the progress
+func (p *partialVoice) save() error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if err := writeAtomic(p.base+".json", data, 0o644); err != nil {
+		return fmt.Errorf("failed to save voiceover progress: %w", err)
+	}
+	return nil
+}
+
+// remove drops the progress and the audio
+func (p *partialVoice) remove() {
+	for _, ext := range []string{".json", ".txt", ".mp3"} {
+		if err := os.Remove(p.base + ext); err != nil && !os.IsNotExist(err) {
+			log.Printf("[WARN] failed to remove %s: %v", p.base+ext, err)
+		}
+	}
+}
+
+// synthesize voices the chunks of the text not done yet with tts, appending
+// them to the audio and saving the progress after each. progress (if set) is
+// called after each chunk.
+func (p *partialVoice) synthesize(ctx context.Context, tts TTSProvider, maxChunkSize int,
+	progress func(done, total int)) error {
+	if tts == nil {
+		return fmt.Errorf("TTS provider is not configured")
+	}
+	chunks := splitTextIntoChunks(withPauses(p.Text), maxChunkSize)
+	if p.Chunks != len(chunks) {
+		p.Chunks, p.Done, p.Size = len(chunks), 0, 0 // chunked differently, the audio doesn't match
+	}
+	f, err := os.OpenFile(p.audioFile(), os.O_CREATE|os.O_WRONLY, 0o644) //nolint:gosec // files location
+	if err != nil {
+		return fmt.Errorf("failed to open partial audio: %w", err)
+	}
+	defer f.Close() //nolint:errcheck // synced before the progress is saved
+	// a chunk written after the last save is dropped, it's voiced again
+	if err := f.Truncate(p.Size); err != nil {
+		return fmt.Errorf("failed to trim partial audio: %w", err)
+	}
+	if _, err := f.Seek(p.Size, 0); err != nil {
+		return fmt.Errorf("failed to seek partial audio: %w", err)
+	}
+
+	edge, isEdge := tts.(*EdgeTTS)
+	for i := p.Done; i < len(chunks); i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if isEdge && i > p.Done {
+			time.Sleep(edgeChunkPause)
+		}
+		var audio []byte
+		if isEdge {
+			audio, err = edge.synthesizeWithRetry(ctx, chunks[i])
+		} else {
+			audio, err = tts.Synthesize(ctx, chunks[i])
+		}
+		if err != nil {
+			return fmt.Errorf("failed to synthesize chunk %d: %w", i, err)
+		}
+		if _, err := f.Write(audio); err != nil {
+			return fmt.Errorf("failed to write partial audio: %w", err)
+		}
+		if err := f.Sync(); err != nil {
+			return fmt.Errorf("failed to write partial audio: %w", err)
+		}
+		p.Done, p.Size = i+1, p.Size+int64(len(audio))
+		if err := p.save(); err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(p.Done, p.Chunks)
+		}
+	}
+	return nil
+}
+
+// dropStalePartials removes the progress of voiceovers failed over
+// partialVoiceKeep ago, nobody is retrying them
+func (t *TelegramBot) dropStalePartials(now time.Time) {
+	if t.FilesLocation == "" {
+		return
+	}
+	files, err := filepath.Glob(filepath.Join(t.FilesLocation, partialVoicePrefix+"*.json"))
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		st, err := os.Stat(file)
+		if err != nil || now.Sub(st.ModTime()) < partialVoiceKeep {
+			continue
+		}
+		log.Printf("[INFO] dropping stale voiceover progress %s", filepath.Base(file))
+		(&partialVoice{base: strings.TrimSuffix(file, ".json")}).remove()
+	}
+}
@@ -0,0 +1,113 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	"github.com/umputun/feed-master/app/youtube/feed/mocks"
+)
+
+// flakyTTS fails the request failAt and the ones after it
+type flakyTTS struct {
+	FakeTTS
+	failAt, calls int
+}
+
+func (f *flakyTTS) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	if f.calls++; f.calls > f.failAt {
+		return nil, errors.New("tts is down")
+	}
+	return f.FakeTTS.Synthesize(ctx, text)
+}
+
+func TestPartialVoice_Resume(t *testing.T) {
+	dir := t.TempDir()
+	text := "First paragraph here.\n\nSecond paragraph here.\n\nThird paragraph here."
+
+	p := loadPartialVoice(dir, "abc", "k1")
+	assert.Empty(t, p.Text)
+	require.NoError(t, p.start(text, true))
+	err := p.synthesize(context.Background(), &flakyTTS{failAt: 2}, 25, nil)
+	require.ErrorContains(t, err, "failed to synthesize chunk 2: tts is down")
+	assert.Equal(t, 3, p.Chunks)
+	assert.Equal(t, 2, p.Done)
+
+	// a chunk half-written when the job was killed
+	f, err := os.OpenFile(p.audioFile(), os.O_APPEND|os.O_WRONLY, 0o644) //nolint:gosec // test temp file
+	require.NoError(t, err)
+	_, err = f.WriteString("garbage")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	resumed := loadPartialVoice(dir, "abc", "k1")
+	assert.Equal(t, text, resumed.Text)
+	assert.True(t, resumed.Translated)
+	assert.Equal(t, 2, resumed.Done)
+	tts := &FakeTTS{}
+	var progress []int
+	require.NoError(t, resumed.synthesize(context.Background(), tts, 25, func(done, total int) {
+		progress = append(progress, done, total)
+	}))
+	assert.Equal(t, []string{"Third paragraph here."}, tts.Texts(), "done chunks not voiced again")
+	assert.Equal(t, []int{3, 3}, progress)
+	audio, err := os.ReadFile(resumed.audioFile())
+	require.NoError(t, err)
+	assert.Len(t, audio, int(resumed.Size))
+	assert.NotContains(t, string(audio), "garbage")
+
+	other := loadPartialVoice(dir, "abc", "k2")
+	assert.Empty(t, other.Text, "progress of another text")
+	assert.NoFileExists(t, resumed.audioFile())
+}
+
+func TestTelegramBot_VoiceSubtitlesResumes(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	bot.FilesLocation = t.TempDir()
+	bot.SubtitleSvc.Runner = &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			vtt := "WEBVTT\n\n00:00:00.000 --> 00:00:02.000\nHello there.\n"
+			return nil, nil, os.WriteFile(outputArg(args)+".en.vtt", []byte(vtt), 0o600)
+		},
+	}
+	statusMsg := &tb.Message{ID: 1, Chat: &tb.Chat{ID: testBotUserID}}
+	info := &ytfeed.VideoInfo{ID: "abc123", Title: "Long talk"}
+
+	_, _, _, err := bot.processVoiceoverViaSubtitles(context.Background(), statusMsg, "https://www.youtube.com/watch?v=abc123",
+		"abc123", info, &FakeTTS{Err: errors.New("tts is down")})
+	require.ErrorContains(t, err, "tts is down")
+	assert.FileExists(t, filepath.Join(bot.FilesLocation, ".partial_abc123.json"))
+
+	tts := &FakeTTS{}
+	file, _, p, err := bot.processVoiceoverViaSubtitles(context.Background(), statusMsg, "https://www.youtube.com/watch?v=abc123",
+		"abc123", info, tts)
+	require.NoError(t, err)
+	assert.FileExists(t, file)
+	assert.Equal(t, "*proc.FakeTranslator", p.Translator)
+	assert.Equal(t, []string{"Hello there."}, bot.Translator.(*FakeTranslator).Texts(), "translated once")
+	assert.Equal(t, []string{"[ru] Hello there."}, tts.Texts())
+	assert.NoFileExists(t, filepath.Join(bot.FilesLocation, ".partial_abc123.json"), "progress dropped when done")
+}
+
+func TestTelegramBot_DropStalePartials(t *testing.T) {
+	bot := &TelegramBot{FilesLocation: t.TempDir()}
+	stale := loadPartialVoice(bot.FilesLocation, "old", "k")
+	require.NoError(t, stale.start("old text", false))
+	fresh := loadPartialVoice(bot.FilesLocation, "new", "k")
+	require.NoError(t, fresh.start("new text", false))
+	old := time.Now().Add(-partialVoiceKeep - time.Hour)
+	require.NoError(t, os.Chtimes(stale.base+".json", old, old))
+
+	bot.dropStalePartials(time.Now())
+	assert.NoFileExists(t, stale.base+".json")
+	assert.NoFileExists(t, stale.base+".txt")
+	assert.FileExists(t, fresh.base+".json")
+}
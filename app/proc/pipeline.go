@@ -0,0 +1,207 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+)
+
+// speechPipeline voices long text while it's still being translated. A
+// producer translates the text chunk by chunk and hands the results over a
+// bounded queue, the consumer synthesizes them in order and writes audio out
+// as soon as each chunk is ready, so TTS of the first chunk overlaps with
+// translation of the rest instead of waiting for the whole article.
+type speechPipeline struct {
+	TTS        TTSProvider
+	Translator TranslationProvider // nil or no translation needed voices the text as is
+	ChunkSize  int                 // TTS request size, default 3000
+	Lookahead  int                 // translated chunks queued ahead of TTS, default 2
+	Spoken     *strings.Builder    // gets the voiced text (the translation, if translated), nil = not kept
+	Marks      []string            // texts (headings) starting a new chunk each, in order of appearance
+	OnMark     func(i int)         // called before the audio of Marks[i] is written, marks not found are skipped
+}
+
+// speechChunk is a translated piece of the source text split for TTS requests
+type speechChunk struct {
+	parts []string
+	err   error
+}
+
+// translateChunkSize keeps a single Translate call to one API request
+const translateChunkSize = 2000
+
+// Run voices text into w, calling progress (if set) after each source chunk
+// is written. Returns the number of voiced characters, which differs from the
+// source length when the text was translated.
+func (p speechPipeline) Run(ctx context.Context, text string, w io.Writer, progress func(done, total int)) (int, error) {
+	if p.TTS == nil {
+		return 0, fmt.Errorf("TTS provider is not configured")
+	}
+	chunkSize := p.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = 3000
+	}
+	lookahead := p.Lookahead
+	if lookahead <= 0 {
+		lookahead = 2
+	}
+
+	text = withPauses(text)
+	translate := p.Translator != nil && p.Translator.NeedsTranslation(text)
+	srcSize := chunkSize
+	if translate {
+		srcSize = translateChunkSize
+		if d, ok := p.Translator.(sourceDetector); ok {
+			if lang := d.DetectSource(ctx, text); lang != "" {
+				ctx = withSourceLang(ctx, lang) // chunks are translated from one language
+			}
+		}
+	}
+	var srcChunks []string
+	var chunkMarks []int // mark starting the source chunk, -1 = none
+	sections, sectionMarks := splitAtMarks(text, p.Marks)
+	for i, section := range sections {
+		for j, chunk := range splitTextIntoChunks(section, srcSize) {
+			mark := -1
+			if j == 0 {
+				mark = sectionMarks[i]
+			}
+			srcChunks = append(srcChunks, chunk)
+			chunkMarks = append(chunkMarks, mark)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	queue := make(chan speechChunk, lookahead)
+	go func() {
+		defer close(queue)
+		trCtx := ctx
+		for i, src := range srcChunks {
+			chunk := speechChunk{parts: []string{src}}
+			if translate {
+				translated, err := p.Translator.Translate(trCtx, src)
+				if err != nil {
+					chunk = speechChunk{err: fmt.Errorf("failed to translate chunk %d: %w", i, err)}
+				} else {
+					chunk.parts = splitTextIntoChunks(translated, chunkSize)
+					trCtx = withTranslateContext(ctx, src, translated) // the next chunk continues this one
+				}
+			}
+			select {
+			case queue <- chunk:
+			case <-ctx.Done():
+				return
+			}
+			if chunk.err != nil {
+				return
+			}
+		}
+	}()
+
+	edge, isEdge := p.TTS.(*EdgeTTS)
+	chars, requests, done := 0, 0, 0
+	for chunk := range queue {
+		if chunk.err != nil {
+			return chars, chunk.err
+		}
+		if mark := chunkMarks[done]; mark >= 0 && p.OnMark != nil {
+			p.OnMark(mark)
+		}
+		for _, part := range chunk.parts {
+			if err := ctx.Err(); err != nil {
+				return chars, err
+			}
+			if isEdge && requests > 0 {
+				time.Sleep(edgeChunkPause)
+			}
+			var audio []byte
+			var err error
+			if isEdge {
+				audio, err = edge.synthesizeWithRetry(ctx, part)
+			} else {
+				audio, err = p.TTS.Synthesize(ctx, part)
+			}
+			requests++
+			if err != nil {
+				return chars, fmt.Errorf("failed to synthesize chunk %d: %w", requests-1, err)
+			}
+			if _, err = w.Write(audio); err != nil {
+				return chars, fmt.Errorf("failed to write audio: %w", err)
+			}
+			chars += len([]rune(part))
+			if p.Spoken != nil {
+				if p.Spoken.Len() > 0 {
+					p.Spoken.WriteString("\n") // chunks split at whitespace, don't glue the words
+				}
+				p.Spoken.WriteString(part)
+			}
+		}
+		done++
+		if progress != nil {
+			progress(done, len(srcChunks))
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return chars, err // the producer stopped early on cancellation
+	}
+	return chars, nil
+}
+
+// ttsWarmer is a TTS provider which gains from an early request made while
+// the input text is still being prepared
+type ttsWarmer interface {
+	Warmup(ctx context.Context) error
+}
+
+// warmupTTS starts the provider's warmup in background, if it has one. The
+// returned channel is closed when the warmup is over, failures are only
+// logged since the real requests retry anyway.
+func warmupTTS(ctx context.Context, p TTSProvider) <-chan struct{} {
+	done := make(chan struct{})
+	w, ok := p.(ttsWarmer)
+	if !ok {
+		close(done)
+		return done
+	}
+	go func() {
+		defer close(done)
+		st := time.Now()
+		if err := w.Warmup(ctx); err != nil {
+			log.Printf("[WARN] TTS warmup failed: %v", err)
+			return
+		}
+		log.Printf("[DEBUG] TTS warmup done in %v", time.Since(st))
+	}()
+	return done
+}
+
+// splitAtMarks cuts text before each mark found, searching in order from the
+// previous one. Returns the sections and the mark starting each, -1 for the
+// text before the first mark.
+func splitAtMarks(text string, marks []string) (sections []string, sectionMarks []int) {
+	cur, curMark, pos := 0, -1, 0
+	for i, mark := range marks {
+		if mark == "" {
+			continue
+		}
+		idx := strings.Index(text[pos:], mark)
+		if idx < 0 {
+			continue
+		}
+		idx += pos
+		if idx > cur {
+			sections = append(sections, text[cur:idx])
+			sectionMarks = append(sectionMarks, curMark)
+		}
+		cur, curMark, pos = idx, i, idx+len(mark)
+	}
+	sections = append(sections, text[cur:])
+	sectionMarks = append(sectionMarks, curMark)
+	return sections, sectionMarks
+}
@@ -0,0 +1,173 @@
+package proc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpeechPipeline_NoTranslation(t *testing.T) {
+	tts := &FakeTTS{FramePerChars: 100}
+	text := strings.Repeat("Это предложение на русском языке. ", 200)
+	var buf bytes.Buffer
+	var progress [][2]int
+	var spoken strings.Builder
+	pipe := speechPipeline{TTS: tts, Translator: &FakeTranslator{}, ChunkSize: 1000, Spoken: &spoken}
+	chars, err := pipe.Run(context.Background(), text, &buf, func(done, total int) {
+		progress = append(progress, [2]int{done, total})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, len([]rune(text)), chars)
+
+	texts := tts.Texts()
+	require.Greater(t, len(texts), 1)
+	assert.Equal(t, text, strings.Join(texts, ""), "chunks voiced in order")
+	assert.Equal(t, strings.Join(texts, "\n"), spoken.String(), "chunks kept apart")
+	assert.Len(t, progress, len(texts))
+	assert.Equal(t, [2]int{len(texts), len(texts)}, progress[len(progress)-1])
+	assert.Zero(t, buf.Len()%len(silentMP3Frame))
+	assert.Positive(t, buf.Len())
+}
+
+func TestSpeechPipeline_Translation(t *testing.T) {
+	tts := &FakeTTS{}
+	tr := &FakeTranslator{}
+	text := strings.Repeat("This is a sentence in English. ", 200)
+	var buf bytes.Buffer
+	pipe := speechPipeline{TTS: tts, Translator: tr}
+	chars, err := pipe.Run(context.Background(), text, &buf, nil)
+	require.NoError(t, err)
+
+	srcChunks := tr.Texts()
+	require.Greater(t, len(srcChunks), 1, "translated in several requests")
+	assert.Equal(t, text, strings.Join(srcChunks, ""))
+	voiced := tts.Texts()
+	require.Len(t, voiced, len(srcChunks))
+	for i, v := range voiced {
+		assert.Equal(t, "[ru] "+srcChunks[i], v)
+	}
+	assert.Equal(t, len([]rune(strings.Join(voiced, ""))), chars)
+}
+
+// gatedTranslator blocks every chunk after the first until released
+type gatedTranslator struct {
+	FakeTranslator
+	release chan struct{}
+	calls   int
+	mu      sync.Mutex
+}
+
+func (g *gatedTranslator) Translate(ctx context.Context, text string) (string, error) {
+	g.mu.Lock()
+	g.calls++
+	n := g.calls
+	g.mu.Unlock()
+	if n > 1 {
+		select {
+		case <-g.release:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	return g.FakeTranslator.Translate(ctx, text)
+}
+
+func TestSpeechPipeline_TTSStartsBeforeTranslationEnds(t *testing.T) {
+	tr := &gatedTranslator{release: make(chan struct{})}
+	tts := &FakeTTS{}
+	w := &notifyWriter{written: make(chan struct{}, 1)}
+	text := strings.Repeat("This is a sentence in English. ", 200)
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := speechPipeline{TTS: tts, Translator: tr}.Run(context.Background(), text, w, nil)
+		errCh <- err
+	}()
+
+	select {
+	case <-w.written: // first chunk voiced while the second is still being translated
+	case <-time.After(5 * time.Second):
+		t.Fatal("no audio before translation finished")
+	}
+	assert.Len(t, tts.Texts(), 1)
+	close(tr.release)
+	require.NoError(t, <-errCh)
+	assert.Len(t, tts.Texts(), len(tr.Texts()))
+}
+
+type notifyWriter struct {
+	bytes.Buffer
+	written chan struct{}
+}
+
+func (w *notifyWriter) Write(p []byte) (int, error) {
+	select {
+	case w.written <- struct{}{}:
+	default:
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestSpeechPipeline_Errors(t *testing.T) {
+	text := strings.Repeat("This is a sentence in English. ", 200)
+
+	t.Run("translation", func(t *testing.T) {
+		tts := &FakeTTS{}
+		_, err := speechPipeline{TTS: tts, Translator: &FakeTranslator{Err: errors.New("quota")}}.
+			Run(context.Background(), text, &bytes.Buffer{}, nil)
+		require.ErrorContains(t, err, "failed to translate chunk 0: quota")
+		assert.Empty(t, tts.Texts())
+	})
+
+	t.Run("tts", func(t *testing.T) {
+		tr := &FakeTranslator{}
+		_, err := speechPipeline{TTS: &FakeTTS{Err: errors.New("ws closed")}, Translator: tr}.
+			Run(context.Background(), text, &bytes.Buffer{}, nil)
+		require.ErrorContains(t, err, "failed to synthesize chunk 0: ws closed")
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := speechPipeline{TTS: &FakeTTS{}}.Run(ctx, text, &bytes.Buffer{}, nil)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("no provider", func(t *testing.T) {
+		_, err := speechPipeline{}.Run(context.Background(), text, &bytes.Buffer{}, nil)
+		require.Error(t, err)
+	})
+}
+
+type fakeWarmer struct {
+	FakeTTS
+	warmed chan struct{}
+}
+
+func (f *fakeWarmer) Warmup(context.Context) error {
+	close(f.warmed)
+	return nil
+}
+
+func TestWarmupTTS(t *testing.T) {
+	select {
+	case <-warmupTTS(context.Background(), &FakeTTS{}):
+	case <-time.After(time.Second):
+		t.Fatal("warmup of a provider without Warmup should be done immediately")
+	}
+
+	w := &fakeWarmer{warmed: make(chan struct{})}
+	<-warmupTTS(context.Background(), w)
+	select {
+	case <-w.warmed:
+	default:
+		t.Fatal("Warmup was not called")
+	}
+}
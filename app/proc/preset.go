@@ -0,0 +1,236 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	"github.com/umputun/feed-master/app/config"
+)
+
+// maxCallbackData is Telegram's limit for inline button callback data
+const maxCallbackData = 64
+
+// splitPresetPrefix cuts a leading preset name off a message ("tech https://..."),
+// returns "" and the text unchanged when the first word is not a known preset
+func (t *TelegramBot) splitPresetPrefix(text string) (name, rest string) {
+	text = strings.TrimSpace(text)
+	first, rest, found := strings.Cut(text, " ")
+	if !found {
+		return "", text
+	}
+	first = strings.ToLower(first)
+	if _, ok := t.Presets[first]; !ok {
+		return "", text
+	}
+	return first, strings.TrimSpace(rest)
+}
+
+// preset returns the named preset, the zero preset (default processing) for
+// an empty or unknown name
+func (t *TelegramBot) preset(name string) config.Preset {
+	return t.Presets[name]
+}
+
+// actionPreset returns the preset of the action with the options asked for
+// in the command applied
+func (t *TelegramBot) actionPreset(pa *pendingAction) config.Preset {
+	p := t.preset(pa.preset)
+	if len(pa.voMethods) > 0 {
+		p.Methods = pa.voMethods
+	}
+	if pa.voLang != "" {
+		p.Lang = pa.voLang
+	}
+	return p
+}
+
+// presetNames returns configured preset names in stable order
+func (t *TelegramBot) presetNames() []string {
+	names := make([]string, 0, len(t.Presets))
+	for name := range t.Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ttsFor returns the TTS provider with the preset's voice and rate applied.
+// Only Edge TTS has these knobs, other providers are returned as is.
+func (t *TelegramBot) ttsFor(p config.Preset) TTSProvider {
+	edge, ok := t.TTS.(*EdgeTTS)
+	if !ok || (p.Voice == "" && p.Rate == "") {
+		return t.TTS
+	}
+	res := *edge
+	if p.Voice != "" {
+		res.Voice = p.Voice
+	}
+	if p.Rate != "" {
+		res.Rate = p.Rate
+	}
+	return &res
+}
+
+// defaultVoices are the Edge TTS voices of untranslated text by its language
+var defaultVoices = map[string]string{
+	"ru": "ru-RU-DmitryNeural", "en": "en-US-GuyNeural", "de": "de-DE-ConradNeural", "fr": "fr-FR-HenriNeural",
+	"es": "es-ES-AlvaroNeural", "it": "it-IT-DiegoNeural", "uk": "uk-UA-OstapNeural", "pl": "pl-PL-MarekNeural",
+}
+
+// voiceFor returns the TTS provider reading untranslated text in its own
+// language: a voice of the language when the configured one is of another,
+// so English isn't read with a Russian accent. A voice set by the preset and
+// providers other than Edge TTS are kept.
+func (t *TelegramBot) voiceFor(ctx context.Context, tts TTSProvider, p config.Preset, text string) TTSProvider {
+	edge, ok := tts.(*EdgeTTS)
+	if !ok || p.Voice != "" {
+		return tts
+	}
+	lang := DetectLanguage(text)
+	if d, ok := t.Translator.(sourceDetector); ok && lang != "ru" {
+		if detected := d.DetectSource(ctx, text); detected != "" {
+			lang = detected // DetectLanguage tells Cyrillic from Latin only
+		}
+	}
+	if lang == voiceLang(edge.Voice) {
+		return tts
+	}
+	voice, ok := t.Voices[lang]
+	if !ok {
+		voice = defaultVoices[lang]
+	}
+	if voice == "" {
+		return tts
+	}
+	log.Printf("[INFO] text in %s, voiced with %s instead of %s", lang, voice, edge.Voice)
+	res := *edge
+	res.Voice = voice
+	return &res
+}
+
+// translatorFor returns the translator, the preset's backend if it names a
+// configured one, nil when the preset keeps the original language
+func (t *TelegramBot) translatorFor(p config.Preset) TranslationProvider {
+	if p.Translate != nil && !*p.Translate {
+		return nil
+	}
+	if p.Translator != "" {
+		if tr, ok := t.Translators[p.Translator]; ok {
+			return tr
+		}
+		log.Printf("[WARN] translator %q of the preset is not configured, using the default", p.Translator)
+	}
+	return t.Translator
+}
+
+// summarizeForSpeech voices an LLM summary instead of the full text. The
+// summary is markdown (headings, bullets), the markup is dropped so TTS
+// doesn't read out the symbols. Needs the notes LLM.
+func (t *TelegramBot) summarizeForSpeech(ctx context.Context, text string) (string, error) {
+	if t.NotesSvc == nil || t.NotesSvc.Enricher == nil {
+		return "", fmt.Errorf("summarize needs the notes LLM (notes.enabled)")
+	}
+	summary, err := t.NotesSvc.Enricher.Summarize(ctx, text, SummaryLong)
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize: %w", err)
+	}
+	lines := strings.Split(summary, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		line = strings.TrimLeft(line, "#*->• ")
+		line = strings.ReplaceAll(line, "**", "")
+		if line != "" && !strings.ContainsAny(line[len(line)-1:], ".!?:;") {
+			line += "." // headings and bullets get a pause
+		}
+		lines[i] = line
+	}
+	return cleanText(strings.Join(lines, "\n")), nil
+}
+
+// presetRows returns inline buttons selecting a preset for the pending action,
+// the selected one is checked
+func (t *TelegramBot) presetRows(markup *tb.ReplyMarkup, token string) [][]tb.InlineButton {
+	if len(t.Presets) == 0 {
+		return nil
+	}
+	t.pendingMu.Lock()
+	selected := ""
+	if pa, ok := t.pendingActions[token]; ok {
+		selected = pa.preset
+	}
+	t.pendingMu.Unlock()
+
+	var rows [][]tb.InlineButton
+	var row []tb.InlineButton
+	for _, name := range t.presetNames() {
+		data := token + "|preset:" + name
+		if len("\fact|"+data) > maxCallbackData {
+			log.Printf("[WARN] preset name %q is too long for a button", name)
+			continue
+		}
+		label := "⚙️ " + name
+		if name == selected {
+			label = "✅ " + name
+		}
+		btn := markup.Data(label, "act", data)
+		row = append(row, *btn.Inline())
+		if len(row) == 3 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// selectPreset toggles the preset of a pending action and redraws its menu.
+// The action stays pending, the next button press runs it with the preset.
+func (t *TelegramBot) selectPreset(c *tb.Callback, token, name string) {
+	t.pendingMu.Lock()
+	pa, ok := t.pendingActions[token]
+	kind, statsText := "", ""
+	if ok {
+		kind = pa.kind
+		if pa.preset == name {
+			pa.preset = "" // second tap unselects
+		} else {
+			pa.preset = name
+		}
+		name = pa.preset
+		if pa.article != nil {
+			statsText = t.articleMenuText(pa) // translation depends on the preset
+		}
+	}
+	t.pendingMu.Unlock()
+
+	if !ok {
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Просрочено")})
+		t.edit(c.Message, t.tr("⏱ Меню просрочено или уже использовано"))
+		return
+	}
+	msg := t.tr("Без пресета")
+	if name != "" {
+		msg = t.tr("⚙️ Пресет: ") + name
+	}
+	_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: msg})
+	if statsText != "" {
+		t.edit(c.Message, statsText, t.buildActionMenu(token, kind))
+		return
+	}
+	_, _ = t.Bot.EditReplyMarkup(c.Message, t.buildActionMenu(token, kind))
+}
+
+// presetNote is the prompt suffix naming the selected preset
+func (t *TelegramBot) presetNote(name string) string {
+	if name == "" {
+		return ""
+	}
+	return t.tr("\n⚙️ Пресет: ") + name
+}
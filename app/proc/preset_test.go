@@ -0,0 +1,149 @@
+package proc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	"github.com/umputun/feed-master/app/config"
+)
+
+func TestTelegramBot_SplitPresetPrefix(t *testing.T) {
+	bot := &TelegramBot{Presets: map[string]config.Preset{"tech": {SkipCode: true}}}
+	tbl := []struct {
+		text, name, rest string
+	}{
+		{"tech https://example.com/a", "tech", "https://example.com/a"},
+		{"Tech  https://example.com/a ", "tech", "https://example.com/a"},
+		{"https://example.com/a", "", "https://example.com/a"},
+		{"look https://example.com/a", "", "look https://example.com/a"},
+		{"tech", "", "tech"},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.text, func(t *testing.T) {
+			name, rest := bot.splitPresetPrefix(tt.text)
+			assert.Equal(t, tt.name, name)
+			assert.Equal(t, tt.rest, rest)
+		})
+	}
+}
+
+func TestTelegramBot_ActionPreset(t *testing.T) {
+	bot := &TelegramBot{Presets: map[string]config.Preset{"vo": {Methods: []string{voMethodVot}, Lang: "de"}}}
+	assert.Equal(t, config.Preset{Methods: []string{voMethodVot}, Lang: "de"}, bot.actionPreset(&pendingAction{preset: "vo"}))
+	assert.Equal(t, config.Preset{Methods: []string{voMethodSubtitles}, Lang: "en"},
+		bot.actionPreset(&pendingAction{preset: "vo", voMethods: []string{voMethodSubtitles}, voLang: "en"}))
+	assert.Equal(t, config.Preset{Lang: "en"}, bot.actionPreset(&pendingAction{voLang: "en"}))
+	assert.Equal(t, []string{voMethodVot}, bot.Presets["vo"].Methods, "the preset itself is kept")
+}
+
+func TestTelegramBot_PresetProviders(t *testing.T) {
+	keep := false
+	edge := NewEdgeTTS("ru-RU-DmitryNeural")
+	bot := &TelegramBot{TTS: edge, Translator: &FakeTranslator{}}
+
+	assert.Same(t, edge, bot.ttsFor(config.Preset{}), "no voice knobs, same provider")
+	tts, ok := bot.ttsFor(config.Preset{Voice: "ru-RU-SvetlanaNeural", Rate: "+20%"}).(*EdgeTTS)
+	require.True(t, ok)
+	assert.Equal(t, &EdgeTTS{Voice: "ru-RU-SvetlanaNeural", Rate: "+20%"}, tts)
+	assert.Equal(t, "ru-RU-DmitryNeural", edge.Voice, "configured provider untouched")
+
+	fake := &FakeTTS{}
+	bot.TTS = fake
+	assert.Same(t, fake, bot.ttsFor(config.Preset{Rate: "+20%"}), "non-edge provider has no rate")
+
+	assert.NotNil(t, bot.translatorFor(config.Preset{}))
+	assert.Nil(t, bot.translatorFor(config.Preset{Translate: &keep}))
+
+	_, err := bot.summarizeForSpeech(context.Background(), "text")
+	require.Error(t, err, "summary needs the notes LLM")
+}
+
+func TestTelegramBot_PresetSelection(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Presets = map[string]config.Preset{"tech": {SkipCode: true}, "longread": {Summarize: true}}
+
+	bot.handleText(testMessage(testBotUserID, "tech https://example.com/post"))
+	sent := stub.texts("sendMessage")
+	require.Len(t, sent, 1)
+	assert.Contains(t, sent[0], "⚙️ Пресет: tech")
+
+	var token string
+	for k, pa := range bot.pendingActions {
+		token = k
+		assert.Equal(t, "article", pa.kind)
+		assert.Equal(t, "tech", pa.preset)
+		assert.Equal(t, "https://example.com/post", pa.url)
+	}
+	require.NotEmpty(t, token)
+
+	menu := bot.buildActionMenu(token, "article")
+	var labels []string
+	for _, row := range menu.InlineKeyboard {
+		for _, btn := range row {
+			labels = append(labels, btn.Text)
+		}
+	}
+	assert.Contains(t, labels, "✅ tech")
+	assert.Contains(t, labels, "⚙️ longread")
+
+	msg := &tb.Message{ID: 7, Chat: &tb.Chat{ID: testBotUserID}}
+	cb := &tb.Callback{Sender: &tb.User{ID: testBotUserID}, Message: msg, Data: token + "|preset:longread"}
+	bot.handleActionCallback(cb)
+	assert.Equal(t, "longread", bot.pendingActions[token].preset, "switched, action still pending")
+
+	cb.Data = token + "|preset:longread"
+	bot.handleActionCallback(cb)
+	assert.Empty(t, bot.pendingActions[token].preset, "second tap unselects")
+}
+
+func TestTelegramBot_PresetTranslator(t *testing.T) {
+	yandex, offline := &FakeTranslator{}, &FakeTranslator{TargetLang: "ru"}
+	bot := &TelegramBot{Translator: yandex, Translators: map[string]TranslationProvider{"yandex": yandex, "offline": offline}}
+	assert.Same(t, yandex, bot.translatorFor(config.Preset{}))
+	assert.Same(t, offline, bot.translatorFor(config.Preset{Translator: "offline"}))
+	assert.Same(t, yandex, bot.translatorFor(config.Preset{Translator: "deepl"}), "unknown backend falls back")
+	keep := false
+	assert.Nil(t, bot.translatorFor(config.Preset{Translator: "offline", Translate: &keep}))
+}
+
+func TestTelegramBot_VoiceFor(t *testing.T) {
+	edge := &EdgeTTS{Voice: "ru-RU-DmitryNeural", Rate: "+10%"}
+	bot := &TelegramBot{}
+	english := "This article is written in English and nobody translated it."
+
+	tts, ok := bot.voiceFor(context.Background(), edge, config.Preset{}, english).(*EdgeTTS)
+	require.True(t, ok)
+	assert.Equal(t, &EdgeTTS{Voice: "en-US-GuyNeural", Rate: "+10%"}, tts, "voice of the language, rate kept")
+	assert.Same(t, edge, bot.voiceFor(context.Background(), edge, config.Preset{}, "Статья на русском языке."))
+	assert.Same(t, edge, bot.voiceFor(context.Background(), edge, config.Preset{Voice: "ru-RU-SvetlanaNeural"}, english),
+		"preset voice kept")
+
+	bot.Voices = map[string]string{"en": "en-GB-RyanNeural"}
+	tts, ok = bot.voiceFor(context.Background(), edge, config.Preset{}, english).(*EdgeTTS)
+	require.True(t, ok)
+	assert.Equal(t, "en-GB-RyanNeural", tts.Voice)
+	bot.Voices = map[string]string{"en": ""}
+	assert.Same(t, edge, bot.voiceFor(context.Background(), edge, config.Preset{}, english), "disabled for the language")
+
+	bot.Voices = nil
+	bot.Translator = &detectingTranslator{lang: "de"}
+	tts, ok = bot.voiceFor(context.Background(), edge, config.Preset{}, "Ein Artikel auf Deutsch.").(*EdgeTTS)
+	require.True(t, ok)
+	assert.Equal(t, "de-DE-ConradNeural", tts.Voice, "language detected by the translator")
+
+	fake := &FakeTTS{}
+	assert.Same(t, fake, bot.voiceFor(context.Background(), fake, config.Preset{}, english))
+}
+
+// detectingTranslator is a FakeTranslator detecting a fixed source language
+type detectingTranslator struct {
+	FakeTranslator
+	lang string
+}
+
+func (d *detectingTranslator) DetectSource(context.Context, string) string { return d.lang }
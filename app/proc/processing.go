@@ -0,0 +1,116 @@
+package proc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// processing records how an entry is made by the method with tts (nil = no
+// TTS) and translator (nil = not translated). The settings hash covers them
+// and the extra settings the result depends on.
+func (t *TelegramBot) processing(method string, tts TTSProvider, translator TranslationProvider,
+	settings ...any) ytfeed.Processing {
+	p := ytfeed.Processing{Method: method, Voice: ttsName(tts), Translator: t.translatorName(translator)}
+	p.Settings = settingsHash(append([]any{p}, settings...)...)
+	return p
+}
+
+// settingsHash is the short hash of the settings, the same for equal values
+func settingsHash(settings ...any) string {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:4])
+}
+
+// ttsName names the voice of the provider, "ru-RU-DmitryNeural +20%" of Edge TTS
+func ttsName(tts TTSProvider) string {
+	switch v := tts.(type) {
+	case nil:
+		return ""
+	case *EdgeTTS:
+		return strings.TrimSpace(v.Voice + " " + v.Rate)
+	default:
+		return fmt.Sprintf("%T", tts)
+	}
+}
+
+// translatorName is the configured name of the translation backend, "" for nil
+func (t *TelegramBot) translatorName(tr TranslationProvider) string {
+	if tr == nil {
+		return ""
+	}
+	for name, p := range t.Translators {
+		if p == tr {
+			return name
+		}
+	}
+	return fmt.Sprintf("%T", tr)
+}
+
+// handleInfo shows what entry N is and how it was made (/info N)
+func (t *TelegramBot) handleInfo(m *tb.Message) {
+	if !t.isAuthorized(m.Sender) {
+		return
+	}
+	idx := 1
+	if args := commandArgs(m); args.len() > 0 {
+		n, err := strconv.Atoi(args.arg(0))
+		if err != nil || n < 1 {
+			_, _ = t.Bot.Send(m.Chat, t.tr("Usage: /info N\nExample: /info 1"))
+			return
+		}
+		idx = n
+	}
+	entries, err := t.Store.Load(t.FeedName, t.MaxItems)
+	if err != nil {
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Error: %v"), err))
+		return
+	}
+	if idx > len(entries) {
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Only %d entries in feed."), len(entries)))
+		return
+	}
+	_, _ = t.Bot.Send(m.Chat, t.entryInfo(entries[idx-1]), tb.NoPreview)
+}
+
+// entryInfo describes the entry for /info
+func (t *TelegramBot) entryInfo(e ytfeed.Entry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ℹ️ %s\n", e.Title)
+	fmt.Fprintf(&b, t.tr("Вид: %s\n"), e.EntryKind())
+	fmt.Fprintf(&b, t.tr("Добавлено: %s\n"), e.AddedAt().Format("2006-01-02 15:04"))
+	if e.Duration > 0 {
+		fmt.Fprintf(&b, t.tr("Длительность: %s\n"), t.formatDuration(time.Duration(e.Duration)*time.Second))
+	}
+	p := e.Processing
+	if p.Method == "" {
+		b.WriteString(t.tr("Способ: не записан\n"))
+	} else {
+		fmt.Fprintf(&b, t.tr("Способ: %s\n"), p.Method)
+	}
+	if p.Voice != "" {
+		fmt.Fprintf(&b, t.tr("Голос: %s\n"), p.Voice)
+	}
+	if p.Translator != "" {
+		fmt.Fprintf(&b, t.tr("Перевод: %s\n"), p.Translator)
+	}
+	if p.Settings != "" {
+		fmt.Fprintf(&b, t.tr("Настройки: %s\n"), p.Settings)
+	}
+	if e.Link.Href != "" {
+		b.WriteString(e.Link.Href + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
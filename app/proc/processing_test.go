@@ -0,0 +1,52 @@
+package proc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/feed-master/app/config"
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+func TestTelegramBot_Processing(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	llm := &FakeTranslator{}
+	bot.Translators = map[string]TranslationProvider{"yandex": bot.Translator, "llm": llm}
+
+	p := bot.processing("tts", &EdgeTTS{Voice: "ru-RU-DmitryNeural", Rate: "+20%"}, llm, config.Preset{SkipCode: true})
+	assert.Equal(t, "tts", p.Method)
+	assert.Equal(t, "ru-RU-DmitryNeural +20%", p.Voice)
+	assert.Equal(t, "llm", p.Translator)
+	assert.Len(t, p.Settings, 8)
+
+	same := bot.processing("tts", &EdgeTTS{Voice: "ru-RU-DmitryNeural", Rate: "+20%"}, llm, config.Preset{SkipCode: true})
+	assert.Equal(t, p, same, "same settings, same hash")
+	other := bot.processing("tts", &EdgeTTS{Voice: "ru-RU-DmitryNeural", Rate: "+20%"}, llm, config.Preset{})
+	assert.NotEqual(t, p.Settings, other.Settings, "preset changed")
+
+	assert.Equal(t, ytfeed.Processing{Method: "vot-cli", Settings: bot.processing("vot-cli", nil, nil).Settings},
+		bot.processing("vot-cli", nil, nil), "no voice and translation of the bot")
+}
+
+func TestTelegramBot_HandleInfo(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Store = newTestJobStore(t)
+	bot.MaxItems = 10
+	_, err := bot.Store.Save(ytfeed.Entry{ChannelID: bot.FeedName, VideoID: "vo_1", Title: "Talk", Kind: ytfeed.KindVoiceover,
+		Duration: 90, Processing: ytfeed.Processing{Method: "vot-cli", Settings: "1a2b3c4d"}})
+	require.NoError(t, err)
+
+	bot.handleInfo(testMessage(testBotUserID, "/info 1"))
+	bot.handleInfo(testMessage(testBotUserID, "/info 2"))
+	bot.handleInfo(testMessage(testBotUserID, "/info x"))
+	sent := stub.texts("sendMessage")
+	require.Len(t, sent, 3)
+	assert.Contains(t, sent[0], "ℹ️ Talk\nВид: voiceover\n")
+	assert.Contains(t, sent[0], "Способ: vot-cli\nНастройки: 1a2b3c4d")
+	assert.NotContains(t, sent[0], "Голос:", "no TTS")
+	assert.Equal(t, "Only 1 entries in feed.", sent[1])
+	assert.Contains(t, sent[2], "Usage: /info")
+}
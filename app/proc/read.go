@@ -110,7 +110,7 @@ func (s *ReadService) Save(ctx context.Context, rawURL string) (ReadResult, erro
 	}
 
 	if doc.RawHTML != "" {
-		if werr := writeAtomic(s.htmlPath(id), []byte(doc.RawHTML)); werr != nil {
+		if werr := writeAtomic(s.htmlPath(id), []byte(doc.RawHTML), 0o640); werr != nil {
 			log.Printf("[WARN] read: failed to archive raw html for %s: %v", rawURL, werr)
 		}
 	}
@@ -198,7 +198,7 @@ func writeReadFile(path string, meta ReadMeta, body string) error {
 		return fmt.Errorf("failed to marshal frontmatter: %w", err)
 	}
 	content := "---\n" + string(fm) + "---\n\n" + strings.TrimSpace(body) + "\n"
-	return writeAtomic(path, []byte(content))
+	return writeAtomic(path, []byte(content), 0o640)
 }
 
 // readReadFile parses a reading artifact into frontmatter and body
@@ -223,18 +223,3 @@ func readReadFile(path string) (ReadMeta, string, error) {
 	body := strings.TrimPrefix(rest[idx+len("\n---"):], "\n")
 	return meta, strings.TrimSpace(body), nil
 }
-
-// writeAtomic writes bytes to path via a tmp file + rename, creating the dir
-func writeAtomic(path string, data []byte) error {
-	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
-		return fmt.Errorf("failed to create dir: %w", err)
-	}
-	tmp := path + ".tmp"
-	if err := os.WriteFile(tmp, data, 0o640); err != nil { //nolint:gosec
-		return fmt.Errorf("failed to write tmp file: %w", err)
-	}
-	if err := os.Rename(tmp, path); err != nil {
-		return fmt.Errorf("failed to rename tmp file: %w", err)
-	}
-	return nil
-}
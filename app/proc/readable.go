@@ -3,6 +3,7 @@ package proc
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -37,10 +38,13 @@ var readSanitizer = bluemonday.UGCPolicy()
 // html→markdown, raw HTML archived); on failure it falls back to r.jina.ai,
 // which already returns Markdown (no raw HTML to archive then).
 func (e *ArticleExtractor) ExtractStructured(ctx context.Context, rawURL string) (*ReadableDoc, error) {
-	doc, err := e.extractStructuredDirect(ctx, rawURL)
+	doc, authorized, err := e.extractStructuredDirect(ctx, rawURL)
 	if err == nil {
 		return doc, nil
 	}
+	if authorized || errors.Is(err, errRobotsDisallowed) {
+		return nil, err // jina reads the page logged out, and ignores robots.txt
+	}
 
 	fallback, ferr := e.extractStructuredViaJina(ctx, rawURL)
 	if ferr != nil {
@@ -50,50 +54,34 @@ func (e *ArticleExtractor) ExtractStructured(ctx context.Context, rawURL string)
 }
 
 // extractStructuredDirect fetches the page, keeps the raw HTML, runs
-// readability, sanitizes and converts the cleaned HTML to Markdown
-func (e *ArticleExtractor) extractStructuredDirect(ctx context.Context, rawURL string) (*ReadableDoc, error) {
+// readability, sanitizes and converts the cleaned HTML to Markdown.
+// Authorized tells the credentials of the site were sent.
+func (e *ArticleExtractor) extractStructuredDirect(ctx context.Context, rawURL string) (doc *ReadableDoc, authorized bool, err error) {
 	parsedURL, err := url.Parse(rawURL)
 	if err != nil {
-		return nil, fmt.Errorf("invalid URL: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-	req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9,en-US;q=0.8,en;q=0.7")
-
-	resp, err := e.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch URL: %w", err)
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP error: %d", resp.StatusCode)
+		return nil, false, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// keep the raw bytes: they feed both readability and the HTML archive
-	raw, err := io.ReadAll(io.LimitReader(resp.Body, 5*1024*1024))
+	raw, _, authorized, err := e.fetchPage(ctx, rawURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read body: %w", err)
+		return nil, authorized, err
 	}
 
 	article, err := readability.FromReader(bytes.NewReader(raw), parsedURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse article: %w", err)
+		return nil, authorized, fmt.Errorf("failed to parse article: %w", err)
 	}
 	if strings.TrimSpace(article.Content) == "" {
-		return nil, fmt.Errorf("no content extracted from article")
+		return nil, authorized, fmt.Errorf("no content extracted from article")
 	}
 
 	markdown, err := htmlToMarkdown(article.Content, parsedURL.Host)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert to markdown: %w", err)
+		return nil, authorized, fmt.Errorf("failed to convert to markdown: %w", err)
 	}
 	if strings.TrimSpace(markdown) == "" {
-		return nil, fmt.Errorf("markdown conversion produced empty output")
+		return nil, authorized, fmt.Errorf("markdown conversion produced empty output")
 	}
 
 	return &ReadableDoc{
@@ -105,7 +93,7 @@ func (e *ArticleExtractor) extractStructuredDirect(ctx context.Context, rawURL s
 		Image:      article.Image,
 		ReadingMin: readingMinutes(article.TextContent),
 		URL:        rawURL,
-	}, nil
+	}, authorized, nil
 }
 
 // extractStructuredViaJina uses r.jina.ai, which renders the page server-side
@@ -0,0 +1,203 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// speechRateRe matches an Edge TTS speech rate, "+20%" or "-15%"
+var speechRateRe = regexp.MustCompile(`^[+-]\d{1,3}%$`)
+
+// scriptFile is the voiced text path of an episode audio file: ep.mp3 → ep.txt
+func scriptFile(audioFile string) string {
+	return strings.TrimSuffix(audioFile, filepath.Ext(audioFile)) + ".txt"
+}
+
+// saveScript keeps the voiced text next to the audio, so the episode can be
+// voiced again without extraction and translation. Best-effort: returns the
+// file, "" on failure or for an empty text.
+func saveScript(audioFile, text string) string {
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	file := scriptFile(audioFile)
+	if err := writeAtomic(file, []byte(text), 0o644); err != nil {
+		log.Printf("[WARN] failed to save voiced text %s: %v", file, err)
+		return ""
+	}
+	return file
+}
+
+// handleRevoice voices article N again from its saved text with another
+// voice or speech rate (/revoice N <voice|language|preset> [rate])
+func (t *TelegramBot) handleRevoice(m *tb.Message) {
+	if !t.isAuthorized(m.Sender) {
+		return
+	}
+	usage := t.tr("Usage: /revoice N <голос|язык|пресет> [скорость]\nExample: /revoice 1 en-US-AriaNeural +10%")
+	args := commandArgs(m)
+	if args.len() < 2 || args.len() > 3 {
+		_, _ = t.Bot.Send(m.Chat, usage)
+		return
+	}
+	idx, err := strconv.Atoi(args.arg(0))
+	if err != nil || idx < 1 {
+		_, _ = t.Bot.Send(m.Chat, usage)
+		return
+	}
+	rate := args.arg(2)
+	if rate != "" && !speechRateRe.MatchString(rate) {
+		_, _ = t.Bot.Send(m.Chat, usage)
+		return
+	}
+	tts, err := t.revoiceTTS(args.arg(1), rate)
+	if err != nil {
+		_, _ = t.Bot.Send(m.Chat, "❌ "+err.Error())
+		return
+	}
+
+	entries, err := t.Store.Load(t.FeedName, t.MaxItems)
+	if err != nil {
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Error: %v"), err))
+		return
+	}
+	if idx > len(entries) {
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Only %d entries in feed."), len(entries)))
+		return
+	}
+	entry := entries[idx-1]
+	if entry.Script == "" {
+		_, _ = t.Bot.Send(m.Chat, t.tr("❌ Для этого эпизода озвученный текст не сохранён"))
+		return
+	}
+
+	statusMsg, _ := t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("🔊 Переозвучиваю %s (%s)..."), entry.Title, args.arg(1)))
+	t.goJob(2*time.Hour, func(ctx context.Context) {
+		text := t.tr("✅ Переозвучено: ") + entry.Title
+		updated, err := t.revoiceEntry(ctx, entry, tts)
+		if err != nil {
+			log.Printf("[WARN] revoice of %s failed: %v", entry.VideoID, err)
+			text = "❌ " + t.userErrorText(err)
+		} else {
+			text += fmt.Sprintf(" (%s)", t.formatDuration(time.Duration(updated.Duration)*time.Second))
+		}
+		if statusMsg != nil {
+			t.edit(statusMsg, text)
+		}
+	})
+}
+
+// revoiceTTS returns the Edge TTS voicing with the named preset, the voice of
+// a language ("en") or the voice itself ("en-US-AriaNeural"). A rate, if set,
+// overrides the one of the preset.
+func (t *TelegramBot) revoiceTTS(name, rate string) (TTSProvider, error) {
+	edge, ok := t.TTS.(*EdgeTTS)
+	if !ok {
+		return nil, fmt.Errorf("сменить голос можно только с Edge TTS")
+	}
+	res := *edge
+	if p, ok := t.Presets[name]; ok {
+		if tts, ok := t.ttsFor(p).(*EdgeTTS); ok {
+			res = *tts
+		}
+	} else {
+		voice := name
+		if v, ok := t.Voices[strings.ToLower(name)]; ok && v != "" {
+			voice = v
+		} else if v, ok := defaultVoices[strings.ToLower(name)]; ok {
+			voice = v
+		}
+		if !strings.Contains(voice, "-") {
+			return nil, fmt.Errorf("неизвестный голос, язык или пресет %q", name)
+		}
+		res.Voice = voice
+	}
+	if rate != "" {
+		res.Rate = rate
+	}
+	return &res, nil
+}
+
+// revoiceEntry synthesizes the saved text of the entry with tts into its
+// file again and re-publishes it. The jingles are kept, the preamble isn't
+// (it's made of the article, which isn't kept); chapters are moved to the
+// new timing in proportion and the read-along transcript is aligned again.
+func (t *TelegramBot) revoiceEntry(ctx context.Context, entry ytfeed.Entry, tts TTSProvider) (ytfeed.Entry, error) {
+	script, err := os.ReadFile(entry.Script)
+	if err != nil {
+		return ytfeed.Entry{}, fmt.Errorf("voiced text %s is gone: %w", filepath.Base(entry.Script), err)
+	}
+	text := string(script)
+
+	out, err := createAtomic(entry.File)
+	if err != nil {
+		return ytfeed.Entry{}, fmt.Errorf("failed to save audio file: %w", err)
+	}
+	clock := &mp3Clock{w: out}
+	if err := t.writeJingle(clock, t.Intro.Jingle); err != nil {
+		out.Abort()
+		return ytfeed.Entry{}, err
+	}
+	var speech *speechCopy
+	if entry.Transcript != "" {
+		speech = t.newSpeechCopy(clock.elapsed)
+		defer speech.remove()
+	}
+	// the text is the translation already, no translator
+	charCount, err := speechPipeline{TTS: tts, ChunkSize: 3000}.Run(ctx, text, speech.tee(clock), nil)
+	if err != nil {
+		out.Abort()
+		return ytfeed.Entry{}, fmt.Errorf("failed to synthesize speech: %w", err)
+	}
+	if err := t.writeJingle(clock, t.Intro.Outro); err != nil {
+		out.Abort()
+		return ytfeed.Entry{}, err
+	}
+	if err := out.Commit(0o644); err != nil {
+		return ytfeed.Entry{}, fmt.Errorf("failed to save audio file: %w", err)
+	}
+	if err := t.finalizeAudio(ctx, entry.File); err != nil {
+		return ytfeed.Entry{}, err
+	}
+
+	oldDuration := entry.Duration
+	entry.Duration = t.ttsDuration(entry.File, charCount)
+	if entry.Chapters != "" && oldDuration > 0 {
+		chapters, err := readChapters(entry.Chapters)
+		if err != nil {
+			log.Printf("[WARN] chapters of %s left as were: %v", entry.VideoID, err)
+		} else {
+			ratio := float64(entry.Duration) / float64(oldDuration)
+			for i := range chapters {
+				chapters[i].Start = time.Duration(float64(chapters[i].Start) * ratio)
+			}
+			entry.Chapters = t.saveChapters(ctx, entry.File, chapters, time.Duration(entry.Duration)*time.Second, nil)
+		}
+	}
+	if entry.Transcript != "" {
+		entry.Transcript = t.alignTranscript(ctx, entry.File, speech, text)
+	}
+	// the rest of the settings stays as it was, the hash covers them with the new voice
+	entry.Processing.Voice = ttsName(tts)
+	entry.Processing.Settings = settingsHash(entry.Processing)
+	if err := entry.SetIntegrity(); err != nil {
+		log.Printf("[WARN] failed to checksum %s: %v", entry.File, err)
+	}
+	if err := t.Store.UpdateEntry(entry); err != nil {
+		return ytfeed.Entry{}, fmt.Errorf("failed to update %s: %w", entry.VideoID, err)
+	}
+	log.Printf("[INFO] revoiced %s, %d chars", entry.VideoID, charCount)
+	t.offloadMedia(entry)
+	return entry, nil
+}
@@ -0,0 +1,88 @@
+package proc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/feed-master/app/config"
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+func TestTelegramBot_RevoiceEntry(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	bot.Store = newTestJobStore(t)
+	dir := t.TempDir()
+
+	file := filepath.Join(dir, "article_1.mp3")
+	require.NoError(t, os.WriteFile(file, []byte("OLD"), 0o600))
+	script := saveScript(file, "Первый абзац.\n\nВторой абзац.")
+	require.Equal(t, filepath.Join(dir, "article_1.txt"), script)
+	chapters := bot.saveChapters(context.Background(), file,
+		[]Chapter{{Title: "Начало"}, {Title: "Дальше", Start: 10 * time.Second}}, 20*time.Second, nil)
+	require.NotEmpty(t, chapters)
+	entry := ytfeed.Entry{ChannelID: bot.FeedName, VideoID: "article_1", File: file, Duration: 20, Script: script,
+		Chapters: chapters}
+	_, err := bot.Store.Save(entry)
+	require.NoError(t, err)
+
+	tts := &FakeTTS{FramePerChars: 1}
+	updated, err := bot.revoiceEntry(context.Background(), entry, tts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Первый абзац.\n\nВторой абзац."}, tts.Texts(), "saved text voiced as is")
+
+	audio, err := os.ReadFile(file) //nolint:gosec // test temp file
+	require.NoError(t, err)
+	assert.Contains(t, string(audio), string(silentMP3Frame), "file replaced")
+	entries, err := bot.Store.Load(bot.FeedName, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, updated.Duration, entries[0].Duration)
+	assert.Equal(t, int64(len(audio)), entries[0].FileSize)
+
+	moved, err := readChapters(chapters)
+	require.NoError(t, err)
+	require.Len(t, moved, 2)
+	want := time.Duration(float64(10*time.Second) * float64(updated.Duration) / 20)
+	assert.InDelta(t, want.Seconds(), moved[1].Start.Seconds(), 0.01, "chapter moved in proportion")
+
+	require.NoError(t, bot.deleteEntry(entries[0]))
+	_, err = os.Stat(script)
+	assert.True(t, os.IsNotExist(err), "voiced text deleted with the episode")
+	_, err = bot.revoiceEntry(context.Background(), entry, tts)
+	assert.ErrorContains(t, err, "is gone")
+}
+
+func TestTelegramBot_RevoiceTTS(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	_, err := bot.revoiceTTS("en", "")
+	require.Error(t, err, "not Edge TTS")
+
+	bot.TTS = &EdgeTTS{Voice: "ru-RU-DmitryNeural"}
+	bot.Presets = map[string]config.Preset{"calm": {Voice: "ru-RU-SvetlanaNeural", Rate: "-10%"}}
+	bot.Voices = map[string]string{"de": "de-DE-KatjaNeural"}
+	tests := []struct {
+		name, rate string
+		want       EdgeTTS
+	}{
+		{name: "en-US-AriaNeural", want: EdgeTTS{Voice: "en-US-AriaNeural"}},
+		{name: "EN", rate: "+20%", want: EdgeTTS{Voice: "en-US-GuyNeural", Rate: "+20%"}},
+		{name: "de", want: EdgeTTS{Voice: "de-DE-KatjaNeural"}},
+		{name: "calm", want: EdgeTTS{Voice: "ru-RU-SvetlanaNeural", Rate: "-10%"}},
+		{name: "calm", rate: "+5%", want: EdgeTTS{Voice: "ru-RU-SvetlanaNeural", Rate: "+5%"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+tt.rate, func(t *testing.T) {
+			tts, err := bot.revoiceTTS(tt.name, tt.rate)
+			require.NoError(t, err)
+			assert.Equal(t, &tt.want, tts)
+		})
+	}
+	_, err = bot.revoiceTTS("nobody", "")
+	assert.ErrorContains(t, err, "неизвестный голос")
+}
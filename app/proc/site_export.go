@@ -0,0 +1,192 @@
+package proc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	ytstore "github.com/umputun/feed-master/app/youtube/store"
+)
+
+// siteTmpl is the static archive page: every episode with a player and,
+// when there is one, its transcript or reader-mode article copy
+var siteTmpl = template.Must(template.New("site").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { max-width: 48em; margin: 2em auto; padding: 0 1em; font: 17px/1.5 -apple-system, sans-serif; color: #222; }
+article { border-bottom: 1px solid #ddd; padding: 1em 0; }
+h2 { font-size: 1.2em; margin: 0 0 .3em; }
+audio { width: 100%; }
+.meta { font-size: 14px; color: #666; }
+.desc { white-space: pre-line; }
+details p { margin: .5em 0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">Эпизодов: {{len .Episodes}}, обновлено {{.Updated}}</p>
+{{range .Episodes}}<article>
+<h2>{{.Title}}</h2>
+<p class="meta">{{.Date}}{{if .Duration}} · {{.Duration}}{{end}}{{if .Author}} · {{.Author}}{{end}}{{if .Link}} · <a href="{{.Link}}">источник</a>{{end}}{{if .Archive}} · <a href="{{.Archive}}">текст статьи</a>{{end}}</p>
+{{if .Audio}}<audio controls preload="none" src="{{.Audio}}"></audio>
+{{end}}{{if .Description}}<p class="desc">{{.Description}}</p>
+{{end}}{{if .Transcript}}<details><summary>Транскрипт</summary>
+{{.Transcript}}</details>
+{{end}}</article>
+{{end}}</body>
+</html>
+`))
+
+// SiteExporter renders a feed into a static HTML archive: index.html with a
+// player per episode and its transcript, publishable by any static host.
+// Audio is copied into media/ next to the page, or linked from MediaURL.
+type SiteExporter struct {
+	Store      *ytstore.BoltDB
+	Title      string // page title, "" = the feed name
+	MDLocation string // notes transcripts (<video id>.md), "" = none
+	MediaURL   string // base URL of the episode files, "" = copy them into the site
+}
+
+// siteEpisode is one episode on the archive page
+type siteEpisode struct {
+	Title, Date, Duration, Author, Link, Audio, Archive, Description string
+	Transcript                                                       template.HTML
+}
+
+// Export writes the archive of the feed into dir and returns the number of
+// episodes. Episodes whose audio is neither on disk nor under MediaURL are
+// listed without a player.
+func (e *SiteExporter) Export(ctx context.Context, feedName, dir string) (int, error) {
+	entries, err := e.Store.Load(feedName, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load feed %s: %w", feedName, err)
+	}
+	entries = ytfeed.Released(entries) // scheduled episodes are published once released
+	if e.MediaURL == "" {
+		if err := os.MkdirAll(filepath.Join(dir, "media"), 0o750); err != nil {
+			return 0, fmt.Errorf("failed to create media dir: %w", err)
+		}
+	}
+
+	episodes := make([]siteEpisode, 0, len(entries))
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		episodes = append(episodes, e.episode(entry, dir))
+	}
+
+	title := e.Title
+	if title == "" {
+		title = feedName
+	}
+	data := struct {
+		Title, Updated string
+		Episodes       []siteEpisode
+	}{Title: title, Updated: time.Now().Format("02.01.2006 15:04"), Episodes: episodes}
+
+	var buf bytes.Buffer
+	if err := siteTmpl.Execute(&buf, data); err != nil {
+		return 0, fmt.Errorf("failed to render archive page: %w", err)
+	}
+	if err := writeAtomic(filepath.Join(dir, "index.html"), buf.Bytes(), 0o644); err != nil {
+		return 0, fmt.Errorf("failed to write archive page: %w", err)
+	}
+	return len(episodes), nil
+}
+
+// episode collects what the page shows for an entry, copying its files
+// into the site directory
+func (e *SiteExporter) episode(entry ytfeed.Entry, dir string) siteEpisode {
+	ep := siteEpisode{
+		Title:       entry.Title,
+		Date:        entry.Published.Format("02.01.2006"),
+		Author:      entry.Author.Name,
+		Link:        entry.Link.Href,
+		Description: html.UnescapeString(string(entry.Media.Description)), // re-escaped by the template
+	}
+	if entry.Duration > 0 {
+		ep.Duration = (time.Duration(entry.Duration) * time.Second).String()
+	}
+	if entry.File == "" {
+		return ep
+	}
+
+	base := filepath.Base(entry.File)
+	switch {
+	case e.MediaURL != "":
+		ep.Audio = strings.TrimSuffix(e.MediaURL, "/") + "/" + base
+	case copyFile(entry.File, filepath.Join(dir, "media", base)) == nil:
+		ep.Audio = "media/" + base
+	default:
+		log.Printf("[WARN] no local audio for %s, listed without a player", entry.VideoID)
+	}
+
+	archive := archiveFile(entry.File)
+	if err := copyFile(archive, filepath.Join(dir, "media", filepath.Base(archive))); err == nil {
+		ep.Archive = "media/" + filepath.Base(archive)
+	}
+	ep.Transcript = e.transcript(entry.VideoID)
+	return ep
+}
+
+// transcript renders the notes transcript of the episode as paragraphs, ""
+// if there is none
+func (e *SiteExporter) transcript(videoID string) template.HTML {
+	if e.MDLocation == "" || videoID == "" {
+		return ""
+	}
+	_, body, err := readNoteFile(filepath.Join(e.MDLocation, videoID+".md"))
+	if err != nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, p := range strings.Split(body, "\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			sb.WriteString("<p>" + template.HTMLEscapeString(p) + "</p>\n")
+		}
+	}
+	return template.HTML(sb.String()) //nolint:gosec // escaped above
+}
+
+// copyFile copies src to dst, skipping the copy when dst is already the same size
+func copyFile(src, dst string) error {
+	in, err := os.Open(src) //nolint:gosec // feed files
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	st, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if cur, err := os.Stat(dst); err == nil && cur.Size() == st.Size() {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return err
+	}
+	out, err := os.Create(dst) //nolint:gosec // export dir from the command line
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
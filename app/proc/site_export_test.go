@@ -0,0 +1,70 @@
+package proc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+func TestSiteExporter_Export(t *testing.T) {
+	st := newTestJobStore(t)
+	filesDir, mdDir, siteDir := t.TempDir(), t.TempDir(), t.TempDir()
+
+	video := ytfeed.Entry{ChannelID: "bot", VideoID: "vid1", Title: "Видео <1>", File: filepath.Join(filesDir, "vid1.mp3"),
+		Published: time.Date(2026, 3, 3, 10, 0, 0, 0, time.UTC), Duration: 754}
+	video.Link.Href = "https://youtube.com/watch?v=vid1"
+	video.Media.Description = "Описание &amp; ссылки"
+	require.NoError(t, os.WriteFile(video.File, []byte("audio1"), 0o600))
+	require.NoError(t, writeNoteFile(filepath.Join(mdDir, "vid1.md"), NoteMeta{Title: "Видео"}, "Первый абзац.\n\nВторой <абзац>."))
+
+	article := ytfeed.Entry{ChannelID: "bot", VideoID: "art1", Title: "Статья", File: filepath.Join(filesDir, "art1.mp3"),
+		Published: time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)}
+	require.NoError(t, os.WriteFile(article.File, []byte("audio2"), 0o600))
+	require.NoError(t, os.WriteFile(archiveFile(article.File), []byte("<html>copy</html>"), 0o600))
+
+	offloaded := ytfeed.Entry{ChannelID: "bot", VideoID: "gone", Title: "В R2", File: filepath.Join(filesDir, "gone.mp3"),
+		Published: time.Date(2026, 3, 5, 10, 0, 0, 0, time.UTC)}
+	scheduled := ytfeed.Entry{ChannelID: "bot", VideoID: "later", Title: "Завтрашний", File: filepath.Join(filesDir, "later.mp3"),
+		Published: time.Date(2026, 3, 6, 10, 0, 0, 0, time.UTC), PublishAt: time.Date(2026, 3, 7, 9, 0, 0, 0, time.UTC)}
+	for _, e := range []ytfeed.Entry{video, article, offloaded, scheduled} {
+		_, err := st.Save(e)
+		require.NoError(t, err)
+	}
+
+	exp := &SiteExporter{Store: st, Title: "Моя лента", MDLocation: mdDir}
+	n, err := exp.Export(context.Background(), "bot", siteDir)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	page, err := os.ReadFile(filepath.Join(siteDir, "index.html"))
+	require.NoError(t, err)
+	html := string(page)
+	assert.Contains(t, html, "<title>Моя лента</title>")
+	assert.Contains(t, html, "<h2>Видео &lt;1&gt;</h2>")
+	assert.Contains(t, html, "03.03.2026 · 12m34s")
+	assert.Contains(t, html, `<audio controls preload="none" src="media/vid1.mp3">`)
+	assert.Contains(t, html, "Описание &amp; ссылки", "description not escaped twice")
+	assert.Contains(t, html, "<p>Второй &lt;абзац&gt;.</p>", "transcript paragraphs")
+	assert.Contains(t, html, `<a href="media/art1.html">текст статьи</a>`)
+	assert.NotContains(t, html, "media/gone.mp3", "no player without the file")
+	assert.NotContains(t, html, "Завтрашний", "scheduled, not released yet")
+
+	data, err := os.ReadFile(filepath.Join(siteDir, "media", "vid1.mp3"))
+	require.NoError(t, err)
+	assert.Equal(t, "audio1", string(data))
+
+	exp = &SiteExporter{Store: st, MediaURL: "https://example.com/yt/media/"}
+	_, err = exp.Export(context.Background(), "bot", siteDir)
+	require.NoError(t, err)
+	page, err = os.ReadFile(filepath.Join(siteDir, "index.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), `src="https://example.com/yt/media/gone.mp3"`)
+	assert.Contains(t, string(page), "<title>bot</title>")
+}
@@ -0,0 +1,93 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// speedVariantFile names the sped-up copy of an episode file: ep.mp3 → ep.x1.5.mp3
+func speedVariantFile(file string, tempo float64) string {
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + ".x" + strconv.FormatFloat(tempo, 'f', -1, 64) + ext
+}
+
+// Stretch writes a copy of the MP3 at src played tempo times faster to dst.
+// atempo keeps the pitch, so voices don't turn into chipmunks. For listeners
+// with players lacking a speed control. Chapters are dropped, their times
+// don't fit the copy.
+func (f *AudioFinalizer) Stretch(ctx context.Context, src, dst string, tempo float64) error {
+	if tempo < 0.5 || tempo > 2 {
+		return fmt.Errorf("tempo %g out of the 0.5-2 range", tempo)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+
+	tmp := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".stretch.tmp")
+	defer os.Remove(tmp) //nolint:errcheck // gone after a successful rename
+
+	_, stderr, err := f.runner().Run(ctx, "ffmpeg", "-nostdin", "-y", "-v", "error", "-i", src,
+		"-map", "0:a", "-filter:a", "atempo="+strconv.FormatFloat(tempo, 'f', -1, 64),
+		"-c:a", "libmp3lame", "-q:a", "5", "-map_metadata", "0", "-map_chapters", "-1", "-id3v2_version", "3", "-write_xing", "1",
+		"-f", "mp3", tmp)
+	if err != nil {
+		return fmt.Errorf("ffmpeg atempo failed: %w, stderr: %s", err, lastLines(string(stderr), 5))
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return fmt.Errorf("rename stretched file: %w", err)
+	}
+	return nil
+}
+
+// makeSpeedVariant writes the sped-up copy of a new episode and records it in
+// the entry, so the ?speed feed points to it. Best-effort: without ffmpeg or
+// on failure the speed feed serves the original file. Returns the copy path,
+// "" if none was made.
+func (t *TelegramBot) makeSpeedVariant(ctx context.Context, entry ytfeed.Entry) string {
+	if t.SpeedVariant <= 0 || t.Finalizer == nil || entry.File == "" {
+		return ""
+	}
+	dst := speedVariantFile(entry.File, t.SpeedVariant)
+	if err := t.Finalizer.Stretch(ctx, entry.File, dst, t.SpeedVariant); err != nil {
+		log.Printf("[WARN] no x%g copy of %s: %v", t.SpeedVariant, filepath.Base(entry.File), err)
+		return ""
+	}
+	st, err := os.Stat(dst)
+	if err != nil {
+		log.Printf("[WARN] can't stat x%g copy %s: %v", t.SpeedVariant, dst, err)
+		return ""
+	}
+	if err := t.updateStoredEntry(entry, func(e *ytfeed.Entry) { e.SpeedFile, e.SpeedFileSize = dst, st.Size() }); err != nil {
+		// the episode is gone already (deleted while stretching), so is its copy
+		log.Printf("[WARN] can't record x%g copy of %s: %v", t.SpeedVariant, entry.VideoID, err)
+		_ = os.Remove(dst)
+		return ""
+	}
+	log.Printf("[INFO] made x%g copy %s", t.SpeedVariant, filepath.Base(dst))
+	return dst
+}
+
+// updateStoredEntry changes the stored entry with fn, the stored one carries
+// fields (size, checksum, files made in the background) the caller's copy may lack
+func (t *TelegramBot) updateStoredEntry(entry ytfeed.Entry, fn func(e *ytfeed.Entry)) error {
+	entries, err := t.Store.Load(entry.ChannelID, 0)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.VideoID != entry.VideoID {
+			continue
+		}
+		fn(&e)
+		return t.Store.UpdateEntry(e)
+	}
+	return fmt.Errorf("entry %s not found", entry.VideoID)
+}
@@ -0,0 +1,90 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	"github.com/umputun/feed-master/app/youtube/feed/mocks"
+)
+
+func TestSpeedVariantFile(t *testing.T) {
+	assert.Equal(t, "/srv/yt/ep.x1.5.mp3", speedVariantFile("/srv/yt/ep.mp3", 1.5))
+	assert.Equal(t, "/srv/yt/ep.x2.m4a", speedVariantFile("/srv/yt/ep.m4a", 2))
+}
+
+func TestTelegramBot_MakeSpeedVariant(t *testing.T) {
+	var ffmpegErr error
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			assert.Contains(t, args, "atempo=1.5")
+			if ffmpegErr != nil {
+				return nil, []byte("boom"), ffmpegErr
+			}
+			return nil, nil, os.WriteFile(args[len(args)-1], []byte("fast"), 0o600)
+		},
+	}
+	newBot := func() (*TelegramBot, ytfeed.Entry) {
+		bot := newTestBot(t, newTgStub(t))
+		bot.Store = newTestJobStore(t)
+		bot.Finalizer = &AudioFinalizer{Runner: runner}
+		bot.SpeedVariant = 1.5
+		file := filepath.Join(t.TempDir(), "ep.mp3")
+		require.NoError(t, os.WriteFile(file, []byte("normal"), 0o600))
+		entry := ytfeed.Entry{ChannelID: bot.FeedName, VideoID: "v1", File: file, FileSize: 6}
+		_, err := bot.Store.Save(entry)
+		require.NoError(t, err)
+		return bot, entry
+	}
+
+	t.Run("made and recorded", func(t *testing.T) {
+		bot, entry := newBot()
+		entry.FileSize = 0 // caller's copy may lack stored fields
+		variant := bot.makeSpeedVariant(context.Background(), entry)
+		require.Equal(t, speedVariantFile(entry.File, 1.5), variant)
+		entries, err := bot.Store.Load(bot.FeedName, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, variant, entries[0].SpeedFile)
+		assert.Equal(t, int64(4), entries[0].SpeedFileSize)
+		assert.Equal(t, int64(6), entries[0].FileSize, "stored fields kept")
+
+		require.NoError(t, bot.deleteEntry(entries[0]))
+		_, err = os.Stat(variant)
+		assert.True(t, os.IsNotExist(err), "copy deleted with the episode")
+	})
+
+	t.Run("ffmpeg fails", func(t *testing.T) {
+		bot, entry := newBot()
+		ffmpegErr = errors.New("exit status 1")
+		defer func() { ffmpegErr = nil }()
+		assert.Empty(t, bot.makeSpeedVariant(context.Background(), entry))
+		_, err := os.Stat(speedVariantFile(entry.File, 1.5))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("entry deleted meanwhile", func(t *testing.T) {
+		bot, entry := newBot()
+		require.NoError(t, bot.Store.Remove(entry))
+		assert.Empty(t, bot.makeSpeedVariant(context.Background(), entry))
+		_, err := os.Stat(speedVariantFile(entry.File, 1.5))
+		assert.True(t, os.IsNotExist(err), "orphan copy removed")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		bot, entry := newBot()
+		bot.SpeedVariant = 0
+		assert.Empty(t, bot.makeSpeedVariant(context.Background(), entry))
+	})
+}
+
+func TestAudioFinalizer_StretchTempoRange(t *testing.T) {
+	f := &AudioFinalizer{Runner: &mocks.CommandRunnerMock{}}
+	require.Error(t, f.Stretch(context.Background(), "a.mp3", "b.mp3", 3))
+}
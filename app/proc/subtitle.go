@@ -2,11 +2,9 @@ package proc
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -21,6 +19,7 @@ import (
 type SubtitleService struct {
 	OutputDir   string
 	CookiesFile string
+	Runner      ytfeed.CommandRunner // yt-dlp calls, nil = ytfeed.ExecRunner
 }
 
 // NewSubtitleService creates a new subtitle service
@@ -28,16 +27,30 @@ func NewSubtitleService(outputDir, cookiesFile string) *SubtitleService {
 	return &SubtitleService{OutputDir: outputDir, CookiesFile: cookiesFile}
 }
 
+// runner returns the injected CommandRunner or the exec-backed default
+func (s *SubtitleService) runner() ytfeed.CommandRunner {
+	if s.Runner != nil {
+		return s.Runner
+	}
+	return ytfeed.ExecRunner{}
+}
+
 // DownloadSubtitles downloads subtitles for a YouTube video using yt-dlp.
 // On cookie errors, retries without cookies as a fallback.
 // Returns path to the subtitle file and detected language.
 func (s *SubtitleService) DownloadSubtitles(ctx context.Context, videoURL string) (string, string, error) {
-	file, lang, err := s.downloadSubtitles(ctx, videoURL, true)
+	return s.DownloadSubtitlesLang(ctx, videoURL, "")
+}
+
+// DownloadSubtitlesLang downloads the subtitles in the language, English or
+// Russian ones when the language is ""
+func (s *SubtitleService) DownloadSubtitlesLang(ctx context.Context, videoURL, lang string) (string, string, error) {
+	file, subLang, err := s.downloadSubtitles(ctx, videoURL, lang, true)
 	if err != nil && s.CookiesFile != "" && ytfeed.IsCookieError(err.Error()) {
 		log.Printf("[WARN] cookies expired, retrying DownloadSubtitles without cookies")
-		return s.downloadSubtitles(ctx, videoURL, false)
+		return s.downloadSubtitles(ctx, videoURL, lang, false)
 	}
-	return file, lang, err
+	return file, subLang, err
 }
 
 // DownloadManualSubtitles fetches only author-uploaded subtitles (no
@@ -52,13 +65,19 @@ func (s *SubtitleService) DownloadManualSubtitles(ctx context.Context, videoURL
 	return file, lang, err
 }
 
-func (s *SubtitleService) downloadManualSubtitles(ctx context.Context, videoURL string, useCookies bool) (string, string, error) {
-	videoID := extractVideoID(normalizeYouTubeURL(videoURL))
-	if videoID == "" {
-		return "", "", fmt.Errorf("could not extract video ID")
-	}
+func (s *SubtitleService) downloadManualSubtitles(ctx context.Context, videoURL string, useCookies bool) (file, lang string, err error) {
+	videoID := sourceID(videoURL)
 
-	outputTemplate := filepath.Join(s.OutputDir, fmt.Sprintf("msub_%s_%d", videoID, time.Now().Unix()))
+	jobDir, err := ytfeed.MakeJobDir(s.OutputDir)
+	if err != nil {
+		return "", "", err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(jobDir) // Cleanup removes it on success
+		}
+	}()
+	outputTemplate := filepath.Join(jobDir, fmt.Sprintf("msub_%s_%d", videoID, time.Now().Unix()))
 	args := []string{
 		"--write-sub", // no --write-auto-sub: manual subtitles only
 		"--sub-lang", "ru,en,en-US,en-GB",
@@ -75,11 +94,8 @@ func (s *SubtitleService) downloadManualSubtitles(ctx context.Context, videoURL
 	cmdCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
-	cmd := exec.CommandContext(cmdCtx, "yt-dlp", args...)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return "", "", fmt.Errorf("yt-dlp manual subtitles failed: %w\nstderr: %s", err, stderr.String())
+	if _, stderr, runErr := s.runner().Run(cmdCtx, "yt-dlp", args...); runErr != nil {
+		return "", "", fmt.Errorf("yt-dlp manual subtitles failed: %w\nstderr: %s", runErr, stderr)
 	}
 
 	matches, _ := filepath.Glob(outputTemplate + "*.vtt")
@@ -87,9 +103,9 @@ func (s *SubtitleService) downloadManualSubtitles(ctx context.Context, videoURL
 		matches, _ = filepath.Glob(outputTemplate + "*.srt")
 	}
 	if len(matches) == 0 {
-		return "", "", fmt.Errorf("no manual subtitles")
+		return "", "", fmt.Errorf("no manual subtitles: %w", ErrNoSubtitles)
 	}
-	lang := "en"
+	lang = "en"
 	if strings.Contains(matches[0], ".ru.") {
 		lang = "ru"
 	}
@@ -97,21 +113,31 @@ func (s *SubtitleService) downloadManualSubtitles(ctx context.Context, videoURL
 	return matches[0], lang, nil
 }
 
-func (s *SubtitleService) downloadSubtitles(ctx context.Context, videoURL string, useCookies bool) (string, string, error) {
-	// Create temp filename based on video URL hash
-	videoID := extractVideoID(normalizeYouTubeURL(videoURL))
-	if videoID == "" {
-		return "", "", fmt.Errorf("could not extract video ID")
-	}
+func (s *SubtitleService) downloadSubtitles(ctx context.Context, videoURL, wantLang string,
+	useCookies bool) (file, lang string, err error) {
+	videoID := sourceID(videoURL)
 
-	outputTemplate := filepath.Join(s.OutputDir, fmt.Sprintf("sub_%s_%d", videoID, time.Now().Unix()))
+	jobDir, err := ytfeed.MakeJobDir(s.OutputDir)
+	if err != nil {
+		return "", "", err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(jobDir) // Cleanup removes it on success
+		}
+	}()
+	outputTemplate := filepath.Join(jobDir, fmt.Sprintf("sub_%s_%d", videoID, time.Now().Unix()))
 
 	// Try to download subtitles with yt-dlp
 	// Priority: manual English subs > auto-generated English > manual Russian > auto Russian
+	subLangs := "en,ru"
+	if wantLang != "" {
+		subLangs = wantLang
+	}
 	args := []string{
 		"--write-sub",
 		"--write-auto-sub",
-		"--sub-lang", "en,ru",
+		"--sub-lang", subLangs,
 		"--sub-format", "vtt/srt/best",
 		"--skip-download",
 		"--no-playlist",
@@ -127,16 +153,12 @@ func (s *SubtitleService) downloadSubtitles(ctx context.Context, videoURL string
 	cmdCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
-	cmd := exec.CommandContext(cmdCtx, "yt-dlp", args...)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return "", "", fmt.Errorf("yt-dlp subtitles failed: %w\nstderr: %s", err, stderr.String())
+	stdout, stderr, runErr := s.runner().Run(cmdCtx, "yt-dlp", args...)
+	if runErr != nil {
+		return "", "", ytfeed.WrapFailure(fmt.Errorf("yt-dlp subtitles failed: %w\nstderr: %s", runErr, stderr), string(stderr))
 	}
 
-	log.Printf("[DEBUG] yt-dlp subtitle stdout: %s", stdout.String())
+	log.Printf("[DEBUG] yt-dlp subtitle stdout: %s", stdout)
 
 	// Find the downloaded subtitle file
 	pattern := outputTemplate + "*.vtt"
@@ -146,15 +168,18 @@ func (s *SubtitleService) downloadSubtitles(ctx context.Context, videoURL string
 		pattern = outputTemplate + "*.srt"
 		matches, err = filepath.Glob(pattern)
 		if err != nil || len(matches) == 0 {
-			return "", "", fmt.Errorf("no subtitle file found")
+			return "", "", fmt.Errorf("no subtitle file found: %w", ErrNoSubtitles)
 		}
 	}
 
 	subFile := matches[0]
 
 	// Detect language from filename (e.g., sub_xxx.en.vtt or sub_xxx.ru.vtt)
-	lang := "en"
-	if strings.Contains(subFile, ".ru.") {
+	lang = "en"
+	switch {
+	case wantLang != "":
+		lang = wantLang
+	case strings.Contains(subFile, ".ru."):
 		lang = "ru"
 	}
 
@@ -272,10 +297,15 @@ func parseSRT(content string) string {
 	return strings.Join(lines, " ")
 }
 
-// Cleanup removes the subtitle file
+// Cleanup removes the subtitle file along with the other languages and
+// intermediates of its download
 func (s *SubtitleService) Cleanup(filePath string) {
 	if filePath != "" {
-		if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		target, remove := filePath, os.Remove
+		if ytfeed.InJobDir(filePath) {
+			target, remove = filepath.Dir(filePath), os.RemoveAll
+		}
+		if err := remove(target); err != nil && !os.IsNotExist(err) {
 			log.Printf("[WARN] failed to cleanup subtitle file %s: %v", filePath, err)
 		}
 	}
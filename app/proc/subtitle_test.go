@@ -0,0 +1,164 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/feed-master/app/youtube/feed/mocks"
+)
+
+func TestParseVTT(t *testing.T) {
+	vtt := `WEBVTT
+Kind: captions
+Language: en
+
+00:00:00.000 --> 00:00:02.000
+Hello <c>world</c>
+
+00:00:02.000 --> 00:00:04.000
+Hello world
+
+00:00:04.000 --> 00:00:06.000
+second line`
+
+	assert.Equal(t, "Hello world second line", parseVTT(vtt))
+}
+
+func TestParseSRT(t *testing.T) {
+	srt := `1
+00:00:00,000 --> 00:00:02,000
+<i>Привет</i>
+
+2
+00:00:02,000 --> 00:00:04,000
+Привет
+
+3
+00:00:04,000 --> 00:00:06,000
+мир`
+
+	assert.Equal(t, "Привет мир", parseSRT(srt))
+}
+
+// outputArg returns the value of yt-dlp --output argument
+func outputArg(args []string) string {
+	for i, a := range args {
+		if a == "--output" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func TestSubtitleService_DownloadSubtitles(t *testing.T) {
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			// pretend yt-dlp wrote the russian subtitles next to the output template
+			err := os.WriteFile(outputArg(args)+".ru.vtt", []byte("WEBVTT\n\n00:00:00.000 --> 00:00:01.000\nтекст\n"), 0o600)
+			return nil, nil, err
+		},
+	}
+	svc := NewSubtitleService(t.TempDir(), "")
+	svc.Runner = runner
+
+	file, lang, err := svc.DownloadSubtitles(context.Background(), "https://www.youtube.com/watch?v=abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "ru", lang)
+	text, err := svc.ParseSubtitles(file)
+	require.NoError(t, err)
+	assert.Equal(t, "текст", text)
+	require.Len(t, runner.RunCalls(), 1)
+	assert.Contains(t, runner.RunCalls()[0].Args, "--write-auto-sub")
+}
+
+func TestSubtitleService_DownloadSubtitlesLang(t *testing.T) {
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			err := os.WriteFile(outputArg(args)+".de.vtt", []byte("WEBVTT\n\n00:00:00.000 --> 00:00:01.000\nText\n"), 0o600)
+			return nil, nil, err
+		},
+	}
+	svc := NewSubtitleService(t.TempDir(), "")
+	svc.Runner = runner
+
+	file, lang, err := svc.DownloadSubtitlesLang(context.Background(), "https://www.youtube.com/watch?v=abc123", "de")
+	require.NoError(t, err)
+	defer svc.Cleanup(file)
+	assert.Equal(t, "de", lang)
+	args := runner.RunCalls()[0].Args
+	i := slices.Index(args, "--sub-lang")
+	require.GreaterOrEqual(t, i, 0)
+	assert.Equal(t, "de", args[i+1])
+}
+
+func TestSubtitleService_DownloadSubtitlesNone(t *testing.T) {
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			return []byte("[info] There are no subtitles for the requested languages"), nil, nil
+		},
+	}
+	svc := NewSubtitleService(t.TempDir(), "")
+	svc.Runner = runner
+
+	_, _, err := svc.DownloadSubtitles(context.Background(), "https://www.youtube.com/watch?v=abc123")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrNoSubtitles)
+}
+
+func TestSubtitleService_DownloadSubtitlesCookieRetry(t *testing.T) {
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			if args[0] == "--cookies" {
+				return nil, []byte("ERROR: cookies are no longer valid"), errors.New("exit status 1")
+			}
+			return nil, nil, os.WriteFile(outputArg(args)+".en.srt", []byte("1\n00:00:00,000 --> 00:00:01,000\ntext\n"), 0o600)
+		},
+	}
+	svc := NewSubtitleService(t.TempDir(), "/tmp/cookies.txt")
+	svc.Runner = runner
+
+	_, lang, err := svc.DownloadSubtitles(context.Background(), "https://www.youtube.com/watch?v=abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "en", lang)
+	assert.Len(t, runner.RunCalls(), 2)
+}
+
+func TestSubtitleService_Cleanup(t *testing.T) {
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			for _, lang := range []string{"en", "ru"} {
+				if err := os.WriteFile(outputArg(args)+"."+lang+".vtt", []byte("WEBVTT\n"), 0o600); err != nil {
+					return nil, nil, err
+				}
+			}
+			return nil, nil, nil
+		},
+	}
+	dir := t.TempDir()
+	svc := NewSubtitleService(dir, "")
+	svc.Runner = runner
+
+	file, _, err := svc.DownloadSubtitles(context.Background(), "https://www.youtube.com/watch?v=abc123")
+	require.NoError(t, err)
+	assert.FileExists(t, file)
+	svc.Cleanup(file)
+	left, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, left, "both languages removed with the job dir")
+
+	runner.RunFunc = func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+		_ = os.WriteFile(outputArg(args)+".en.vtt.part", []byte("WEBV"), 0o600)
+		return nil, nil, errors.New("exit status 1")
+	}
+	_, _, err = svc.DownloadManualSubtitles(context.Background(), "https://www.youtube.com/watch?v=abc123")
+	require.Error(t, err)
+	left, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, left, "partial download removed on failure")
+}
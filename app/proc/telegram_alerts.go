@@ -0,0 +1,210 @@
+package proc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	"github.com/umputun/feed-master/app/config"
+	"github.com/umputun/feed-master/app/tools"
+)
+
+const (
+	maxFailures       = 50 // reports kept for the retry button, the oldest go first
+	stderrExcerptSize = 1500
+	debugListSize     = 10 // logs listed by /debug without an id
+)
+
+// failure is a background job failure reported to the admin chat
+type failure struct {
+	ID    string // id of the report for the retry button, Log or a random one when empty
+	Log   string // id of the job log, see tools.StartJob
+	Job   string // what failed: audio, vo, tts, notes...
+	URL   string
+	Err   error
+	retry func(chat *tb.Chat, statusMsg *tb.Message) // nil = no retry button
+	at    time.Time
+}
+
+// reportFailure sends a failure report with a retry button to the admin chat.
+// The status message in the user's chat can be gone by then (cleared chat),
+// the report stays. No-op without admin_chat_id.
+func (t *TelegramBot) reportFailure(f failure) {
+	if t.AdminChatID == 0 || f.Err == nil {
+		return
+	}
+	if f.ID == "" {
+		f.ID = f.Log
+	}
+	if f.ID == "" {
+		f.ID = newJobID()
+	}
+	f.at = time.Now()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, t.tr("🚨 Сбой задачи %s\n🆔 %s\n"), f.Job, f.ID)
+	if f.URL != "" {
+		fmt.Fprintf(&b, "🔗 %s\n", f.URL)
+	}
+	msg, stderr, _ := strings.Cut(f.Err.Error(), "\n")
+	fmt.Fprintf(&b, "❌ %s\n", msg)
+	if excerpt := stderrExcerpt(stderr); excerpt != "" {
+		fmt.Fprintf(&b, "\nstderr:\n%s\n", excerpt)
+	}
+	if _, err := tools.JobLog(f.Log); f.Log != "" && err == nil {
+		fmt.Fprintf(&b, "\n📜 /debug %s\n", f.Log)
+	}
+
+	var opts []any
+	if f.retry != nil {
+		t.failuresMu.Lock()
+		t.failures = append(t.failures, f)
+		if len(t.failures) > maxFailures {
+			t.failures = t.failures[len(t.failures)-maxFailures:]
+		}
+		t.failuresMu.Unlock()
+		markup := &tb.ReplyMarkup{}
+		btnRetry := markup.Data(t.tr("🔁 Повторить"), "retry", f.ID)
+		markup.InlineKeyboard = [][]tb.InlineButton{{*btnRetry.Inline()}}
+		opts = append(opts, markup)
+	}
+	if _, err := t.Bot.Send(&tb.Chat{ID: t.AdminChatID}, b.String(), opts...); err != nil {
+		log.Printf("[WARN] failed to report %s failure %s to admin chat: %v", f.Job, f.ID, err)
+	}
+}
+
+// newJobID makes a short random id of a background job
+func newJobID() string {
+	var buf [4]byte
+	_, _ = rand.Read(buf[:])
+	return hex.EncodeToString(buf[:])
+}
+
+// stderrExcerpt is the tail of the stderr part of an error, where the reason usually is
+func stderrExcerpt(s string) string {
+	s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "stderr:"))
+	if runes := []rune(s); len(runes) > stderrExcerptSize {
+		s = "…" + string(runes[len(runes)-stderrExcerptSize:])
+	}
+	return s
+}
+
+// retryAction makes the retry of a link menu job. The original message is
+// left alone, it was handled by the failed run.
+func (t *TelegramBot) retryAction(pa *pendingAction, action string) func(chat *tb.Chat, statusMsg *tb.Message) {
+	again := *pa
+	again.originalMsg = nil
+	return func(chat *tb.Chat, statusMsg *tb.Message) { t.runAction(chat, statusMsg, &again, action) }
+}
+
+// retryVideo makes the retry of a YouTube audio download, failing again reports again
+func (t *TelegramBot) retryVideo(videoID string) func(chat *tb.Chat, statusMsg *tb.Message) {
+	return func(chat *tb.Chat, statusMsg *tb.Message) {
+		t.goJob(audioJobTimeout, func(ctx context.Context) {
+			if err := t.processVideo(ctx, chat, statusMsg, nil, videoID); err != nil {
+				log.Printf("[ERROR] failed to process video %s: %v", videoID, err)
+				t.edit(statusMsg, t.userErrorText(err))
+				t.reportFailure(failure{Log: tools.JobID(ctx), Job: "audio", URL: "https://www.youtube.com/watch?v=" + videoID, Err: err,
+					retry: t.retryVideo(videoID)})
+			}
+		})
+	}
+}
+
+// retryVoiceover makes the retry of a video voiceover, failing again reports again
+func (t *TelegramBot) retryVoiceover(videoURL, videoID string, preset config.Preset) func(chat *tb.Chat, statusMsg *tb.Message) {
+	return func(chat *tb.Chat, statusMsg *tb.Message) {
+		t.goJob(voiceoverJobTimeout, func(ctx context.Context) {
+			if err := t.processVoiceover(ctx, chat, statusMsg, nil, videoURL, videoID, preset); err != nil {
+				t.reportVoiceoverError(ctx, statusMsg, nil, videoURL, videoID, preset, err)
+			}
+		})
+	}
+}
+
+// handleRetryCallback reruns a failed job from its report in the admin chat,
+// the new run reports in a fresh status message there. Data is the job id.
+func (t *TelegramBot) handleRetryCallback(c *tb.Callback) {
+	if c == nil || c.Message == nil || !t.isAuthorized(c.Sender) {
+		return
+	}
+	t.failuresMu.Lock()
+	var found *failure
+	for i, f := range t.failures {
+		if f.ID == c.Data {
+			found = &f
+			t.failures = append(t.failures[:i], t.failures[i+1:]...)
+			break
+		}
+	}
+	t.failuresMu.Unlock()
+	if found == nil {
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Уже перезапущено или слишком старое")})
+		return
+	}
+	_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Перезапускаю")})
+	t.edit(c.Message, c.Message.Text+t.tr("\n\n🔁 перезапущено"))
+	statusMsg, err := t.Bot.Send(c.Message.Chat, fmt.Sprintf(t.tr("⏳ Повтор %s %s..."), found.Job, found.ID))
+	if err != nil {
+		log.Printf("[WARN] failed to start retry of %s: %v", found.ID, err)
+		return
+	}
+	log.Printf("[INFO] retrying failed %s job %s", found.Job, found.ID)
+	found.retry(c.Message.Chat, statusMsg)
+}
+
+// handleDebug sends the log of a job as a document to the admin chat (/debug
+// <id>, the id of a failure report), without an id it lists the recent logs
+func (t *TelegramBot) handleDebug(m *tb.Message) {
+	if !t.isAuthorized(m.Sender) {
+		return
+	}
+	id := commandArgs(m).arg(0)
+	if id == "" {
+		t.listJobLogs(m.Chat)
+		return
+	}
+	info, err := tools.JobLog(id)
+	if err != nil {
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Нет лога задачи %s"), id))
+		return
+	}
+	to := m.Chat
+	if t.AdminChatID != 0 {
+		to = &tb.Chat{ID: t.AdminChatID}
+	}
+	doc := &tb.Document{
+		File:     tb.FromDisk(info.Path),
+		FileName: info.ID + ".log",
+		Caption:  fmt.Sprintf("📜 %s, %s", info.ID, info.ModTime.Format("02.01 15:04:05")),
+	}
+	if _, err := t.Bot.Send(to, doc); err != nil {
+		log.Printf("[WARN] failed to send log of job %s: %v", info.ID, err)
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Error: %v"), err))
+	}
+}
+
+// listJobLogs shows the newest job logs for /debug without an id
+func (t *TelegramBot) listJobLogs(chat *tb.Chat) {
+	logs, err := tools.RecentJobLogs(debugListSize)
+	if err != nil {
+		_, _ = t.Bot.Send(chat, fmt.Sprintf(t.tr("Error: %v"), err))
+		return
+	}
+	if len(logs) == 0 {
+		_, _ = t.Bot.Send(chat, t.tr("Логов задач нет."))
+		return
+	}
+	var b strings.Builder
+	b.WriteString(t.tr("📜 Последние логи задач:\n"))
+	for _, l := range logs {
+		fmt.Fprintf(&b, "/debug %s — %s, %.1f KB\n", l.ID, l.ModTime.Format("02.01 15:04"), float64(l.Size)/1024)
+	}
+	_, _ = t.Bot.Send(chat, b.String())
+}
@@ -0,0 +1,96 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	"github.com/umputun/feed-master/app/tools"
+)
+
+func TestTelegramBot_ReportFailure(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	var retried []*tb.Message
+	retry := func(_ *tb.Chat, statusMsg *tb.Message) { retried = append(retried, statusMsg) }
+
+	bot.reportFailure(failure{Job: "vo", Err: errors.New("boom"), retry: retry})
+	assert.Empty(t, stub.texts("sendMessage"), "no admin chat configured")
+
+	bot.AdminChatID = 42
+	bot.reportFailure(failure{ID: "job1", Job: "vo", URL: "https://www.youtube.com/watch?v=abc",
+		Err: errors.New("yt-dlp download failed: exit status 1\nstderr: ERROR: [youtube] abc: HTTP Error 403"), retry: retry})
+	sent := stub.texts("sendMessage")
+	require.Len(t, sent, 1)
+	assert.Equal(t, "🚨 Сбой задачи vo\n🆔 job1\n🔗 https://www.youtube.com/watch?v=abc\n"+
+		"❌ yt-dlp download failed: exit status 1\n\nstderr:\nERROR: [youtube] abc: HTTP Error 403\n", sent[0])
+	stub.mu.Lock()
+	call := stub.calls[len(stub.calls)-1]
+	stub.mu.Unlock()
+	assert.Equal(t, "42", call.Params["chat_id"])
+	assert.Contains(t, call.Params["reply_markup"], "retry|job1")
+
+	callback := &tb.Callback{Sender: &tb.User{ID: testBotUserID}, Data: "job1",
+		Message: &tb.Message{ID: 7, Text: sent[0], Chat: &tb.Chat{ID: 42}}}
+	bot.handleRetryCallback(callback)
+	require.Len(t, retried, 1)
+	assert.Equal(t, "⏳ Повтор vo job1...", retried[0].Text)
+	assert.Contains(t, stub.texts("editMessageText")[0], "🔁 перезапущено")
+
+	bot.handleRetryCallback(callback)
+	assert.Len(t, retried, 1, "a report retries once")
+}
+
+func TestStderrExcerpt(t *testing.T) {
+	assert.Empty(t, stderrExcerpt(""))
+	assert.Equal(t, "ERROR: boom", stderrExcerpt("stderr: ERROR: boom\n"))
+	long := stderrExcerpt(strings.Repeat("x", 2000) + "tail")
+	assert.Len(t, []rune(long), stderrExcerptSize+1)
+	assert.Contains(t, long, "tail")
+}
+
+func TestTelegramBot_HandleDebug(t *testing.T) {
+	t.Cleanup(func() { tools.ConfigureJobLogs(tools.JobLogs{}) })
+	tools.ConfigureJobLogs(tools.JobLogs{Dir: t.TempDir()})
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.AdminChatID = 42
+
+	bot.handleDebug(testMessage(testBotUserID, "/debug"))
+	assert.Equal(t, []string{"Логов задач нет."}, stub.texts("sendMessage"))
+
+	ctx, done := tools.StartJob(context.Background(), "job1")
+	cmd := exec.CommandContext(ctx, "sh", "-c", "echo oops >&2; exit 1")
+	cleanup, err := tools.Prepare(ctx, cmd)
+	require.NoError(t, err)
+	require.Error(t, cmd.Run())
+	cleanup()
+	done()
+
+	bot.reportFailure(failure{Log: "job1", Job: "audio", Err: errors.New("boom")})
+	sent := stub.texts("sendMessage")
+	require.Len(t, sent, 2)
+	assert.Contains(t, sent[1], "🆔 job1\n")
+	assert.Contains(t, sent[1], "📜 /debug job1")
+
+	bot.handleDebug(testMessage(testBotUserID, "/debug"))
+	sent = stub.texts("sendMessage")
+	require.Len(t, sent, 3)
+	assert.Contains(t, sent[2], "/debug job1 — ")
+
+	bot.handleDebug(testMessage(testBotUserID, "/debug nope"))
+	sent = stub.texts("sendMessage")
+	require.Len(t, sent, 4)
+	assert.Equal(t, "Нет лога задачи nope", sent[3])
+
+	bot.handleDebug(testMessage(testBotUserID, "/debug job1"))
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	assert.Equal(t, "sendDocument", stub.calls[len(stub.calls)-1].Method)
+}
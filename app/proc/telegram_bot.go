@@ -1,16 +1,20 @@
 package proc
 
 import (
+	"cmp"
 	"context"
 	"crypto/rand"
 	"crypto/sha1"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"html/template"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -18,7 +22,11 @@ import (
 	log "github.com/go-pkgz/lgr"
 	tb "gopkg.in/tucnak/telebot.v2"
 
+	"github.com/umputun/feed-master/app/config"
+	"github.com/umputun/feed-master/app/feed"
 	"github.com/umputun/feed-master/app/publisher"
+	"github.com/umputun/feed-master/app/tools"
+	"github.com/umputun/feed-master/app/tracing"
 	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
 	ytstore "github.com/umputun/feed-master/app/youtube/store"
 )
@@ -27,9 +35,11 @@ import (
 type TelegramBot struct {
 	Bot              *tb.Bot
 	AllowedUserID    int64
+	AdminChatID      int64 // failure reports of background jobs go here, 0 = none
 	FeedName         string
 	FeedTitle        string
 	MaxItems         int
+	MaxAge           time.Duration // episodes added earlier are removed, pinned ones kept; 0 = no limit
 	Downloader       *ytfeed.Downloader
 	Store            *ytstore.BoltDB
 	DurationSvc      DurationService
@@ -39,31 +49,89 @@ type TelegramBot struct {
 	TTSEnabled       bool
 	TTS              TTSProvider
 	ArticleExtractor *ArticleExtractor
+	URLResolver      *URLResolver // nil = links only cleaned of tracking params, not unshortened
 	VoiceoverSvc     *VoiceoverService
 	SubtitleSvc      *SubtitleService
-	Translator       *Translator
-	NotesSvc         *NotesService      // nil when notes feature is disabled
-	ReadSvc          *ReadService       // nil when the reading layer is disabled
-	Apple            *AppleResolver     // apple podcasts links resolution
-	Media            MediaOffloader     // nil = episodes stay on local disk
-	Pub              *publisher.Service // nil = publishing platform off
+	Finalizer        *AudioFinalizer // nil = produced audio kept as written (no ffmpeg)
+	Aligner          *Aligner        // read-along transcripts of voiced articles, nil = none
+	Translator       TranslationProvider
+	Translators      map[string]TranslationProvider // configured backends by name, picked by presets
+	NotesSvc         *NotesService                  // nil when notes feature is disabled
+	ReadSvc          *ReadService                   // nil when the reading layer is disabled
+	Apple            *AppleResolver                 // apple podcasts links resolution
+	Media            MediaOffloader                 // nil = episodes stay on local disk
+	Pub              *publisher.Service             // nil = publishing platform off
+	WebSub           *feed.WebSub                   // hubs pinged when an episode is added, nil = none
+	Archive          *ytfeed.Archive                // yt-dlp download archive the added videos go to, nil = none
+	Waveform         bool                           // new episodes get the waveform of the web player
+	Tools            []tools.Status                 // external binaries checked on startup, shown in /status
+	Presets          map[string]config.Preset
+	Voices           map[string]string // Edge TTS voice by language of untranslated text, over defaultVoices
+	RSSPollInterval  time.Duration     // period of /rsssub feed checks, 0 = defaultRSSPollInterval
+	DailyDigest      config.DailyDigest
+	ReadLater        ReadLaterQueue // nil = no read-later integration
+	ReadLaterConf    config.ReadLater
+	MailPreset       string // processing preset of mails from the gateway
+	ArticleLimit     config.ArticleLimit
+	TranslateTitles  bool    // translated articles get the title translated too
+	SpeedVariant     float64 // tempo of the sped-up copy of each episode, 0 = none
+	CoverColor       string  // background of generated article covers, "" = picked by the feed name
+	CoverFont        string  // font file of generated article covers, "" = default sans
+	Intro            config.Intro
+	VoSources        config.VoiceoverSources // vot-cli voiceover inputs kept for /remix
+	HybridDub        config.HybridDub        // subtitle dubs keep the original audio between the subtitles
+	VoMethods        config.VoiceoverMethods // order and duration limits of the /vo methods
+	Dashboard        config.Dashboard        // pinned message with the running jobs, off by default
+
+	channelRules map[string]channelRule // by YouTube channel id
+	maxDubSize   int64                  // bytes, default limit of downloaded dubbed tracks, see dubSizeLimit
+
+	infoMu    sync.Mutex
+	infoCache map[string]cachedVideoInfo // fetched video metadata by URL, see videoInfo
 
 	r2WarnMu   sync.Mutex
 	lastR2Warn time.Time
 
 	pendingMu      sync.Mutex
 	pendingActions map[string]*pendingAction
+
+	failuresMu sync.Mutex
+	failures   []failure // reported to the admin chat, waiting for the retry button
+
+	inflightMu sync.Mutex
+	inflight   map[string]*inflightJob // sources being processed, by action
+
+	etaMu    sync.Mutex
+	etaRates map[string]ytstore.Throughput // learned speeds by stage, loaded on first use
+
+	edits *editThrottle // status message edits within Telegram's limits
+
+	langMu     sync.Mutex
+	langSeen   map[int64]string // languages of the users' Telegram apps
+	langPicked map[int64]string // languages picked with /lang, win over the seen ones
+
+	runningMu sync.Mutex
+	running   map[string]*runningJob // background jobs by id, shown on the dashboard
+
+	runCtx context.Context // set by Run, parent of every job context
 }
 
 // pendingAction stores the URL(s) extracted from a user message while the user
 // picks an action from the inline menu. Callback data is limited to 64 bytes,
 // so the menu carries only a short token referencing this entry.
 type pendingAction struct {
-	kind        string // "yt" or "article"
-	videoIDs    []string
-	url         string
-	originalMsg *tb.Message
-	created     time.Time
+	kind          string // "yt" or "article"
+	videoIDs      []string
+	url           string
+	preset        string   // selected processing preset, "" = default
+	voMethods     []string // voiceover: methods asked for with --method, over the preset's
+	voLang        string   // voiceover: language of the video asked for with --lang
+	force         bool     // article: add even if identical content is in the feed
+	forceInflight bool     // run even if the source is being processed ("again", the 🔁 button)
+	article       *Article // article: already extracted text (mail), nil = extract url
+	originalMsg   *tb.Message
+	created       time.Time
+	release       func() // in-flight claim of the running action, see goActionJob
 }
 
 const (
@@ -73,28 +141,68 @@ const (
 	pendingActionTTL       = 10 * time.Minute
 	maxShowEpisodes        = 50 // cap for "add the whole show" batches
 	maxPlaylistItems       = 50 // cap for "expand a youtube playlist" batches
+
+	// per-job timeouts, batches get one per item
+	lookupJobTimeout    = 2 * time.Minute
+	audioJobTimeout     = time.Hour
+	articleJobTimeout   = time.Hour
+	voiceoverJobTimeout = 6 * time.Hour // subtitles → translate → TTS of a 4h+ video
+	verifyJobTimeout    = 30 * time.Minute
 )
 
 // TelegramBotParams contains all parameters for creating a new TelegramBot
 type TelegramBotParams struct {
-	Token         string
-	APIURL        string
-	AllowedUserID int64
-	FeedName      string
-	FeedTitle     string
-	MaxItems      int
-	Downloader    *ytfeed.Downloader
-	Store         *ytstore.BoltDB
-	DurationSvc   DurationService
-	FilesLocation string
-	BaseURL       string
-	TTSEnabled    bool
-	TTSVoice      string
-	CookiesFile   string
-	NotesSvc      *NotesService
-	ReadSvc       *ReadService
-	Media         MediaOffloader
-	Pub           *publisher.Service
+	Token           string
+	APIURL          string
+	AllowedUserID   int64
+	AdminChatID     int64
+	FeedName        string
+	FeedTitle       string
+	MaxItems        int
+	MaxAge          time.Duration
+	Downloader      *ytfeed.Downloader
+	Store           *ytstore.BoltDB
+	DurationSvc     DurationService
+	FilesLocation   string
+	BaseURL         string
+	TTSEnabled      bool
+	TTSVoice        string
+	Voices          map[string]string
+	MaxDubSize      int64  // bytes, <= 0 = no limit on downloaded dubbed tracks, channel rules may override
+	AudioFormat     string // format of dubbed tracks (mp3, m4a, opus), "" = mp3
+	CookiesFile     string
+	NotesSvc        *NotesService
+	ReadSvc         *ReadService
+	Media           MediaOffloader
+	Pub             *publisher.Service
+	Presets         map[string]config.Preset
+	ChannelRules    map[string]config.ChannelRule
+	RSSPoll         time.Duration
+	DailyDigest     config.DailyDigest
+	ReadLater       ReadLaterQueue
+	ReadLaterConf   config.ReadLater
+	MailPreset      string
+	NitterURL       string
+	ArticleCookies  string // Netscape cookies file of article page requests
+	ArticleSites    map[string]config.SiteAuth
+	ArticleFetch    config.ArticleFetch
+	ArticleVariants config.ArticleVariants
+	Translation     config.Translation
+	TranslateTitles bool
+	ArticleLimit    config.ArticleLimit
+	SpeedVariant    float64
+	CoverColor      string
+	CoverFont       string
+	Intro           config.Intro
+	VoSources       config.VoiceoverSources
+	HybridDub       config.HybridDub
+	VoMethods       config.VoiceoverMethods
+	Dashboard       config.Dashboard
+	AlignCommand    string // forced alignment of voiced articles, "" = no read-along transcripts
+	WebSub          *feed.WebSub
+	Archive         *ytfeed.Archive
+	Waveform        bool
+	Tools           []tools.Status
 }
 
 // NewTelegramBot creates a new bot for receiving YouTube URLs
@@ -103,6 +211,22 @@ func NewTelegramBot(params TelegramBotParams) (*TelegramBot, error) {
 		return nil, fmt.Errorf("telegram token required")
 	}
 
+	if err := checkVoMethods(params.VoMethods); err != nil {
+		return nil, err
+	}
+	for name, p := range params.Presets {
+		if err := checkVoMethods(config.VoiceoverMethods{Methods: p.Methods}); err != nil {
+			return nil, fmt.Errorf("preset %s: %w", name, err)
+		}
+	}
+	if err := checkArticleLimit(params.ArticleLimit); err != nil {
+		return nil, err
+	}
+	channelRules, err := compileChannelRules(params.ChannelRules)
+	if err != nil {
+		return nil, err
+	}
+
 	apiURL := params.APIURL
 	if apiURL == "" {
 		apiURL = "https://api.telegram.org"
@@ -118,40 +242,139 @@ func NewTelegramBot(params TelegramBotParams) (*TelegramBot, error) {
 	}
 
 	tb := &TelegramBot{
-		Bot:            bot,
-		AllowedUserID:  params.AllowedUserID,
-		FeedName:       params.FeedName,
-		FeedTitle:      params.FeedTitle,
-		MaxItems:       params.MaxItems,
-		Downloader:     params.Downloader,
-		Store:          params.Store,
-		DurationSvc:    params.DurationSvc,
-		FilesLocation:  params.FilesLocation,
-		BaseURL:        params.BaseURL,
-		CookiesFile:    params.CookiesFile,
-		TTSEnabled:     params.TTSEnabled,
-		NotesSvc:       params.NotesSvc,
-		ReadSvc:        params.ReadSvc,
-		Media:          params.Media,
-		Pub:            params.Pub,
-		pendingActions: make(map[string]*pendingAction),
+		Bot:             bot,
+		AllowedUserID:   params.AllowedUserID,
+		AdminChatID:     params.AdminChatID,
+		FeedName:        params.FeedName,
+		FeedTitle:       params.FeedTitle,
+		MaxItems:        params.MaxItems,
+		MaxAge:          params.MaxAge,
+		Downloader:      params.Downloader,
+		Store:           params.Store,
+		DurationSvc:     params.DurationSvc,
+		FilesLocation:   params.FilesLocation,
+		BaseURL:         params.BaseURL,
+		CookiesFile:     params.CookiesFile,
+		TTSEnabled:      params.TTSEnabled,
+		NotesSvc:        params.NotesSvc,
+		ReadSvc:         params.ReadSvc,
+		Media:           params.Media,
+		Pub:             params.Pub,
+		Presets:         params.Presets,
+		channelRules:    channelRules,
+		maxDubSize:      params.MaxDubSize,
+		Voices:          params.Voices,
+		RSSPollInterval: params.RSSPoll,
+		DailyDigest:     params.DailyDigest,
+		ReadLater:       params.ReadLater,
+		ReadLaterConf:   params.ReadLaterConf,
+		MailPreset:      params.MailPreset,
+		ArticleLimit:    params.ArticleLimit,
+		TranslateTitles: params.TranslateTitles,
+		SpeedVariant:    params.SpeedVariant,
+		CoverColor:      params.CoverColor,
+		CoverFont:       params.CoverFont,
+		Intro:           params.Intro,
+		VoSources:       params.VoSources,
+		HybridDub:       params.HybridDub,
+		VoMethods:       params.VoMethods,
+		Dashboard:       params.Dashboard,
+		WebSub:          params.WebSub,
+		Archive:         params.Archive,
+		Waveform:        params.Waveform,
+		Tools:           params.Tools,
+		pendingActions:  make(map[string]*pendingAction),
+		edits:           newEditThrottle(bot.Edit, minEditInterval),
 	}
 
 	// Initialize TTS if enabled
 	if params.TTSEnabled {
 		tb.TTS = NewEdgeTTS(params.TTSVoice)
 		tb.ArticleExtractor = NewArticleExtractor()
+		tb.ArticleExtractor.NitterURL = params.NitterURL
+		tb.ArticleExtractor.CookiesFile = params.ArticleCookies
+		tb.ArticleExtractor.Sites = params.ArticleSites
+		tb.ArticleExtractor.Delay = max(params.ArticleFetch.Delay, 0)
+		tb.ArticleExtractor.Robots = params.ArticleFetch.Robots
+		tb.ArticleExtractor.AMP = params.ArticleVariants.AMP
+		tb.ArticleExtractor.Variants = params.ArticleVariants.Sites
+		if params.ArticleCookies != "" {
+			if _, err := readCookiesFile(params.ArticleCookies); err != nil {
+				log.Printf("[WARN] article cookies file %s unusable: %v", params.ArticleCookies, err)
+			}
+		}
 	}
 
 	// Initialize voiceover service (for YouTube voice-over translation)
 	tb.VoiceoverSvc = NewVoiceoverService(params.FilesLocation, "ru", params.CookiesFile)
+	tb.VoiceoverSvc.AudioFormat = params.AudioFormat
 
 	// Initialize subtitle service and translator (for long video fallback)
 	tb.SubtitleSvc = NewSubtitleService(params.FilesLocation, params.CookiesFile)
-	tb.Translator = NewTranslatorWithKey(os.Getenv("YANDEX_TRANSLATE_KEY"), os.Getenv("YANDEX_FOLDER_ID"), "ru")
+	translator := NewTranslatorWithKey(os.Getenv("YANDEX_TRANSLATE_KEY"), os.Getenv("YANDEX_FOLDER_ID"), "ru")
+	if tb.Store != nil {
+		translator.Glossary = tb.Store.LoadGlossary
+	}
+	translator.SourceLang = params.Translation.SourceLang
+	translator.PreserveFormatting = params.Translation.PreserveFormatting
+	translator.Speller = params.Translation.Speller
+	if params.Translation.SAKeyFile != "" {
+		if err := translator.SetServiceAccountKey(params.Translation.SAKeyFile); err != nil {
+			return nil, fmt.Errorf("failed to set up translation service account: %w", err)
+		}
+	}
+	if params.Translation.Formality != "" {
+		log.Printf("[WARN] Yandex Translate has no formality option, translation.formality %q ignored", params.Translation.Formality)
+	}
+	tb.Translators = map[string]TranslationProvider{"yandex": translator}
+	if params.Translation.Offline.URL != "" {
+		offline := NewLibreTranslator(params.Translation.Offline.URL, params.Translation.Offline.APIKey, "ru")
+		offline.SourceLang = params.Translation.SourceLang
+		tb.Translators["offline"] = offline
+	}
+	if llmKey := cmp.Or(os.Getenv("LLM_API_KEY"), os.Getenv("GROQ_API_KEY")); llmKey != "" {
+		llm := NewEnrichService(llmKey, params.Translation.LLM.Model)
+		if params.Translation.LLM.BaseURL != "" {
+			llm.BaseURL = params.Translation.LLM.BaseURL
+		}
+		llmTranslator := NewLLMTranslator(llm)
+		llmTranslator.Glossary = translator.Glossary
+		llmTranslator.SourceLang = params.Translation.SourceLang
+		tb.Translators["llm"] = llmTranslator
+	}
+	provider := params.Translation.Provider
+	if provider == "" {
+		provider = "yandex"
+	}
+	if tb.Translator = tb.Translators[provider]; tb.Translator == nil {
+		return nil, fmt.Errorf("translation provider %q is not configured", provider)
+	}
+
+	if IsFFmpegAvailable() {
+		tb.Finalizer = &AudioFinalizer{}
+	} else {
+		log.Printf("[WARN] ffmpeg not found, TTS audio won't be remuxed (duration headers may be off)")
+		if params.SpeedVariant > 0 {
+			log.Printf("[WARN] ffmpeg not found, no sped-up episode copies")
+		}
+		if params.Waveform {
+			log.Printf("[WARN] ffmpeg not found, no episode waveforms")
+		}
+		if params.VoSources.Keep {
+			log.Printf("[WARN] ffmpeg not found, voiceover sources won't be kept")
+		}
+		if params.HybridDub.Enabled {
+			log.Printf("[WARN] ffmpeg not found, subtitle dubs won't keep the original audio")
+		}
+	}
+
+	if params.AlignCommand != "" {
+		tb.Aligner = &Aligner{Command: params.AlignCommand}
+	}
 
 	// Apple Podcasts links resolution (no auth, public iTunes lookup)
 	tb.Apple = NewAppleResolver()
+	tb.URLResolver = NewURLResolver()
 
 	return tb, nil
 }
@@ -159,22 +382,15 @@ func NewTelegramBot(params TelegramBotParams) (*TelegramBot, error) {
 // Run starts the bot and listens for messages
 func (t *TelegramBot) Run(ctx context.Context) error {
 	log.Printf("[INFO] starting telegram bot for user %d, feed: %s", t.AllowedUserID, t.FeedName)
+	t.runCtx = ctx // before Start: handlers read it without locking
+	t.loadUserLangs()
 
 	// Register handlers
 	t.Bot.Handle(tb.OnText, t.handleText)
-	t.Bot.Handle("/list", t.handleList)
-	t.Bot.Handle("/history", t.handleHistory)
-	t.Bot.Handle("/del", t.handleDelete)
-	t.Bot.Handle("/vo", t.handleVoiceover)
-	t.Bot.Handle("/md", t.handleMD)
-	t.Bot.Handle("/notes", t.handleNotes)
-	t.Bot.Handle("/status", t.handleStatus)
-	t.Bot.Handle("/read", t.handleRead)
-	t.Bot.Handle("/digest", t.handleDigest)
-	t.Bot.Handle("/feeds", t.handleFeeds)
-	t.Bot.Handle("/archive", t.handleArchive)
-	t.Bot.Handle("/help", t.handleHelp)
-	t.Bot.Handle("/start", t.handleHelp)
+	for _, c := range t.botCommands() {
+		t.Bot.Handle("/"+c.name, c.handle)
+	}
+	t.setCommandMenu()
 
 	// Document uploads: currently only cookies.txt refresh
 	t.Bot.Handle(tb.OnDocument, t.handleDocument)
@@ -185,8 +401,22 @@ func (t *TelegramBot) Run(ctx context.Context) error {
 	// Start polling in goroutine
 	go t.Bot.Start()
 
-	// Periodically drop stale pending menu entries
-	go t.gcPendingActions(ctx)
+	// Periodically drop stale pending menu entries and expired episodes
+	go t.gc(ctx)
+
+	// Voice new posts of /rsssub feeds
+	go t.pollRSSSubscriptions(ctx)
+
+	// Combine the day's short articles into one episode
+	go t.runDailyDigest(ctx)
+
+	// Voice the read-later queue
+	go t.pollReadLater(ctx)
+
+	// Keep the pinned dashboard up to date
+	if t.Dashboard.Enabled {
+		go t.runDashboard(ctx)
+	}
 
 	// Wait for context cancellation
 	<-ctx.Done()
@@ -195,7 +425,93 @@ func (t *TelegramBot) Run(ctx context.Context) error {
 	return ctx.Err()
 }
 
-func (t *TelegramBot) gcPendingActions(ctx context.Context) {
+// jobContext derives a job context from the bot's run context: stopping the
+// bot cancels in-flight work and kills its yt-dlp/ffmpeg children, the
+// timeout keeps a stuck child from hanging the job forever
+func (t *TelegramBot) jobContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	parent := t.runCtx
+	if parent == nil {
+		parent = context.Background() // handlers invoked without Run (tests)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// saveEntry records the file size and checksum of a new episode and stores it.
+// A checksum failure is logged, not fatal: the episode is still playable.
+func (t *TelegramBot) saveEntry(ctx context.Context, entry ytfeed.Entry) (created bool, err error) {
+	_, span := tracing.Start(ctx, "store", "video.id", entry.VideoID)
+	defer func() { span.Finish(err) }()
+	if err := entry.SetIntegrity(); err != nil {
+		log.Printf("[WARN] failed to checksum %s: %v", entry.File, err)
+	}
+	created, err = t.Store.Save(entry)
+	if created {
+		t.announceFeed(false)
+	}
+	return created, err
+}
+
+// announceFeed pings the WebSub hubs about the bot feed, or its sped-up copy,
+// in the background: a slow hub doesn't hold the job
+func (t *TelegramBot) announceFeed(speed bool) {
+	if t.WebSub == nil || t.BaseURL == "" {
+		return
+	}
+	topic := strings.TrimSuffix(t.BaseURL, "/") + "/yt/rss/" + t.FeedName
+	if speed {
+		topic += "?speed=1"
+	}
+	go func() {
+		ctx, cancel := t.jobContext(time.Minute)
+		defer cancel()
+		if err := t.WebSub.Publish(ctx, topic); err != nil {
+			log.Printf("[WARN] %v", err)
+			return
+		}
+		log.Printf("[DEBUG] websub hubs notified about %s", topic)
+	}()
+}
+
+// finalizeAudio fixes headers of a produced MP3 before its duration is read.
+// Only a file that doesn't decode fails the job, remux trouble keeps the
+// file as written.
+func (t *TelegramBot) finalizeAudio(ctx context.Context, path string) error {
+	if t.Finalizer == nil {
+		return nil
+	}
+	err := t.Finalizer.Finalize(ctx, path)
+	if errors.Is(err, ErrBrokenAudio) {
+		_ = os.Remove(path)
+		return err
+	}
+	if err != nil {
+		log.Printf("[WARN] failed to finalize %s, keeping as is: %v", path, err)
+	}
+	return nil
+}
+
+// goJob runs fn in its own goroutine under a job context
+func (t *TelegramBot) goJob(timeout time.Duration, fn func(ctx context.Context)) {
+	t.goNamedJob("", timeout, fn)
+}
+
+// goNamedJob is goJob showing the job on the dashboard under the name
+func (t *TelegramBot) goNamedJob(name string, timeout time.Duration, fn func(ctx context.Context)) {
+	go func() {
+		ctx, cancel := t.jobContext(timeout)
+		defer cancel()
+		jobID := newJobID()
+		ctx, done := tools.StartJob(ctx, jobID)
+		defer done()
+		ctx, finished := t.trackJob(ctx, jobID, name)
+		defer finished()
+		ctx, span := tracing.Start(ctx, "job", "job.id", jobID)
+		defer span.End()
+		fn(ctx)
+	}()
+}
+
+func (t *TelegramBot) gc(ctx context.Context) {
 	ticker := time.NewTicker(5 * time.Minute)
 	defer ticker.Stop()
 	for {
@@ -210,6 +526,13 @@ func (t *TelegramBot) gcPendingActions(ctx context.Context) {
 				}
 			}
 			t.pendingMu.Unlock()
+			t.expireEntries(now)
+			t.purgeTrash(now)
+			t.dropStalePartials(now)
+			t.releaseScheduled(now)
+			if t.edits != nil {
+				t.edits.prune(now)
+			}
 		}
 	}
 }
@@ -242,62 +565,72 @@ func (t *TelegramBot) takePendingAction(token string) *pendingAction {
 func (t *TelegramBot) handleText(m *tb.Message) {
 	if !t.isAuthorized(m.Sender) {
 		log.Printf("[WARN] unauthorized user %d tried to send message", m.Sender.ID)
-		_, _ = t.Bot.Send(m.Chat, "Unauthorized. This bot is private.")
+		_, _ = t.Bot.Send(m.Chat, t.tr("Unauthorized. This bot is private."))
 		return
 	}
 
-	videoIDs := t.extractAllYouTubeVideoIDs(m.Text)
+	// "tech https://..." preselects a processing preset, "again" reprocesses a link in work
+	force, text := splitForceFlag(m.Text)
+	preset, text := t.splitPresetPrefix(text)
+
+	videoIDs := t.extractAllYouTubeVideoIDs(text)
 	if len(videoIDs) > 0 {
-		token := t.storePendingAction(&pendingAction{kind: "yt", videoIDs: videoIDs, originalMsg: m})
-		var prompt string
-		if len(videoIDs) == 1 {
-			prompt = "🤔 Что сделать со ссылкой?"
-		} else {
-			prompt = fmt.Sprintf("🤔 Что сделать с %d ссылками?", len(videoIDs))
+		pa := &pendingAction{kind: "yt", videoIDs: videoIDs, preset: preset, forceInflight: force, originalMsg: m}
+		token := t.storePendingAction(pa)
+		menuMsg, err := t.Bot.Send(m.Chat, t.linksPrompt(len(videoIDs))+t.presetNote(preset), t.buildActionMenu(token, "yt"))
+		if err == nil && len(t.channelRules) > 0 {
+			// the channels of the videos may pick the action, it takes a lookup per video
+			t.goJob(time.Duration(len(videoIDs))*lookupJobTimeout, func(ctx context.Context) {
+				t.applyChannelRules(ctx, m.Chat, menuMsg, token)
+			})
 		}
-		_, _ = t.Bot.Send(m.Chat, prompt, t.buildActionMenu(token, "yt"))
 		return
 	}
 
 	// bare playlist link (no specific video). A watch?v=..&list=.. link already
 	// matched above as a single video and is intentionally left alone
 	// (--no-playlist), so we only reach here for pure playlist URLs.
-	if plURL := extractPlaylistURL(m.Text); plURL != "" {
-		go t.handlePlaylistLink(context.Background(), m, plURL)
+	if plURL := extractPlaylistURL(text); plURL != "" {
+		t.goJob(lookupJobTimeout, func(ctx context.Context) { t.handlePlaylistLink(ctx, m, plURL) })
 		return
 	}
 
-	if podcastURL := t.extractURL(m.Text); podcastURL != "" && IsApplePodcastURL(podcastURL) {
+	if podcastURL := t.extractURL(text); podcastURL != "" && IsApplePodcastURL(podcastURL) {
 		if _, episodeID, err := parseAppleURL(podcastURL); err == nil && episodeID == "" {
 			// link to a whole show: offer adding all catalog episodes
-			show, eps, rerr := t.Apple.ResolveShow(context.Background(), podcastURL)
+			ctx, cancel := t.jobContext(lookupJobTimeout)
+			show, eps, rerr := t.Apple.ResolveShow(ctx, podcastURL)
+			cancel()
 			if rerr != nil {
 				_, _ = t.Bot.Send(m.Chat, fmt.Sprintf("❌ %v", rerr))
 				return
 			}
-			token := t.storePendingAction(&pendingAction{kind: "podcast_show", url: podcastURL, originalMsg: m})
-			prompt := fmt.Sprintf("🎙 «%s» — %d эпизодов в каталоге. Добавить все в ленту?", show, len(eps))
+			token := t.storePendingAction(&pendingAction{kind: "podcast_show", url: podcastURL, forceInflight: force, originalMsg: m})
+			prompt := fmt.Sprintf(t.tr("🎙 «%s» — %d эпизодов в каталоге. Добавить все в ленту?"), show, len(eps))
 			if len(eps) > maxShowEpisodes {
-				prompt = fmt.Sprintf("🎙 «%s» — %d эпизодов в каталоге. Добавлю последние %d. Продолжить?", show, len(eps), maxShowEpisodes)
+				prompt = fmt.Sprintf(t.tr("🎙 «%s» — %d эпизодов в каталоге. Добавлю последние %d. Продолжить?"), show, len(eps), maxShowEpisodes)
 			}
 			_, _ = t.Bot.Send(m.Chat, prompt, t.buildActionMenu(token, "podcast_show"))
 			return
 		}
-		token := t.storePendingAction(&pendingAction{kind: "podcast", url: podcastURL, originalMsg: m})
-		_, _ = t.Bot.Send(m.Chat, "🤔 Что сделать с эпизодом?", t.buildActionMenu(token, "podcast"))
+		token := t.storePendingAction(&pendingAction{kind: "podcast", url: podcastURL, forceInflight: force, originalMsg: m})
+		_, _ = t.Bot.Send(m.Chat, t.tr("🤔 Что сделать с эпизодом?"), t.buildActionMenu(token, "podcast"))
 		return
 	}
 
-	articleURL := t.extractURL(m.Text)
+	articleURL := t.extractURL(text)
 	if articleURL != "" && (t.TTSEnabled || t.ReadSvc != nil) && IsArticleURL(articleURL) {
-		token := t.storePendingAction(&pendingAction{kind: "article", url: articleURL, originalMsg: m})
-		_, _ = t.Bot.Send(m.Chat, "🤔 Что сделать со ссылкой?", t.buildActionMenu(token, "article"))
+		token := t.storePendingAction(&pendingAction{kind: "article", url: articleURL, preset: preset, forceInflight: force, originalMsg: m})
+		menuMsg, err := t.Bot.Send(m.Chat, t.tr("🤔 Что сделать со ссылкой?")+t.presetNote(preset), t.buildActionMenu(token, "article"))
+		if err == nil && t.TTSEnabled && t.ArticleExtractor != nil {
+			t.goJob(lookupJobTimeout, func(ctx context.Context) { t.previewArticle(ctx, menuMsg, token) })
+		}
 		return
 	}
 
-	helpMsg := "No valid URL found. Send a link:\n• YouTube: https://youtube.com/watch?v=VIDEO_ID"
+	helpMsg := t.tr("No valid URL found. Send a link:\n• YouTube: https://youtube.com/watch?v=VIDEO_ID")
 	if t.TTSEnabled || t.ReadSvc != nil {
-		helpMsg += "\n• Article: any web page URL"
+		helpMsg += t.tr("\n• Article: any web page URL")
 	}
 	_, _ = t.Bot.Send(m.Chat, helpMsg)
 }
@@ -308,50 +641,53 @@ func (t *TelegramBot) buildActionMenu(token, kind string) *tb.ReplyMarkup {
 	var rows [][]tb.InlineButton
 	switch kind {
 	case "yt":
-		btnAudio := markup.Data("🎵 Аудио", "act", token+"|audio")
-		btnVO := markup.Data("🎙 Перевод RU", "act", token+"|vo")
+		btnAudio := markup.Data(t.tr("🎵 Аудио"), "act", token+"|audio")
+		btnVO := markup.Data(t.tr("🎙 Перевод RU"), "act", token+"|vo")
 		rows = append(rows, []tb.InlineButton{*btnAudio.Inline(), *btnVO.Inline()})
 		if t.NotesSvc != nil {
-			btnMD := markup.Data("📄 MD-файл", "act", token+"|md")
+			btnMD := markup.Data(t.tr("📄 MD-файл"), "act", token+"|md")
 			btnNotes := markup.Data("📓 Notion", "act", token+"|notes")
-			btnBoth := markup.Data("🎵+📓 Аудио и Notion", "act", token+"|audio_notes")
+			btnBoth := markup.Data(t.tr("🎵+📓 Аудио и Notion"), "act", token+"|audio_notes")
 			rows = append(rows,
 				[]tb.InlineButton{*btnMD.Inline(), *btnNotes.Inline()},
 				[]tb.InlineButton{*btnBoth.Inline()})
 		}
 	case "podcast":
-		btnAudio := markup.Data("🎧 В ленту", "act", token+"|audio")
-		btnVO := markup.Data("🎙 Перевод RU", "act", token+"|vo")
+		btnAudio := markup.Data(t.tr("🎧 В ленту"), "act", token+"|audio")
+		btnVO := markup.Data(t.tr("🎙 Перевод RU"), "act", token+"|vo")
 		rows = append(rows, []tb.InlineButton{*btnAudio.Inline(), *btnVO.Inline()})
 		if t.NotesSvc != nil {
-			btnMD := markup.Data("📄 MD-файл", "act", token+"|md")
+			btnMD := markup.Data(t.tr("📄 MD-файл"), "act", token+"|md")
 			btnNotes := markup.Data("📓 Notion", "act", token+"|notes")
 			rows = append(rows, []tb.InlineButton{*btnMD.Inline(), *btnNotes.Inline()})
 		}
 	case "podcast_show":
-		btnAll := markup.Data("🎧 Добавить все", "act", token+"|audio")
-		btnVOAll := markup.Data("🎙 Перевод всех RU", "act", token+"|vo")
+		btnAll := markup.Data(t.tr("🎧 Добавить все"), "act", token+"|audio")
+		btnVOAll := markup.Data(t.tr("🎙 Перевод всех RU"), "act", token+"|vo")
 		rows = append(rows, []tb.InlineButton{*btnAll.Inline(), *btnVOAll.Inline()})
 	case "article":
 		var top []tb.InlineButton
 		if t.TTSEnabled {
-			btnTTS := markup.Data("📝 Озвучить", "act", token+"|tts")
+			btnTTS := markup.Data(t.tr("📝 Озвучить"), "act", token+"|tts")
 			top = append(top, *btnTTS.Inline())
 		}
 		if t.ReadSvc != nil {
-			btnRead := markup.Data("📖 В читалку", "act", token+"|read")
+			btnRead := markup.Data(t.tr("📖 В читалку"), "act", token+"|read")
 			top = append(top, *btnRead.Inline())
 		}
 		if len(top) > 0 {
 			rows = append(rows, top)
 		}
 		if t.NotesSvc != nil {
-			btnMD := markup.Data("📄 MD-файл", "act", token+"|md")
+			btnMD := markup.Data(t.tr("📄 MD-файл"), "act", token+"|md")
 			btnNotes := markup.Data("📓 Notion", "act", token+"|notes")
 			rows = append(rows, []tb.InlineButton{*btnMD.Inline(), *btnNotes.Inline()})
 		}
 	}
-	btnCancel := markup.Data("🚫 Отмена", "act", token+"|cancel")
+	if kind == "yt" || kind == "article" {
+		rows = append(rows, t.presetRows(markup, token)...)
+	}
+	btnCancel := markup.Data(t.tr("🚫 Отмена"), "act", token+"|cancel")
 	rows = append(rows, []tb.InlineButton{*btnCancel.Inline()})
 	markup.InlineKeyboard = rows
 	return markup
@@ -366,12 +702,12 @@ func (t *TelegramBot) handleList(m *tb.Message) {
 	pageSize := t.parsePageSize(m.Text, defaultListPageSize)
 	entries, err := t.Store.Load(t.FeedName, t.MaxItems)
 	if err != nil {
-		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf("Error loading entries: %v", err))
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Error loading entries: %v"), err))
 		return
 	}
 
 	if len(entries) == 0 {
-		_, _ = t.Bot.Send(m.Chat, "No videos in feed yet.")
+		_, _ = t.Bot.Send(m.Chat, t.tr("No videos in feed yet."))
 		return
 	}
 
@@ -391,11 +727,11 @@ func (t *TelegramBot) handleHistory(m *tb.Message) {
 	pageSize := t.parsePageSize(m.Text, defaultHistoryPageSize)
 	entries, total, err := t.Store.LoadHistory(t.FeedName, 0, pageSize)
 	if err != nil {
-		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf("Error: %v", err))
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Error: %v"), err))
 		return
 	}
 	if total == 0 {
-		_, _ = t.Bot.Send(m.Chat, "No history yet.")
+		_, _ = t.Bot.Send(m.Chat, t.tr("No history yet."))
 		return
 	}
 
@@ -403,57 +739,53 @@ func (t *TelegramBot) handleHistory(m *tb.Message) {
 	_, _ = t.Bot.Send(m.Chat, msg, markup, tb.NoPreview)
 }
 
-// handleDelete removes entry from feed and deletes file from disk
+// handleDelete removes entries from feed and deletes their files from disk:
+// /del N, /del 3-7 or /del tag:news, by /list numbers
 func (t *TelegramBot) handleDelete(m *tb.Message) {
 	if !t.isAuthorized(m.Sender) {
 		return
 	}
 
-	// parse argument: /del 1 or /del (without arg = delete last)
-	args := regexp.MustCompile(`\s+`).Split(m.Text, -1)
-	idx := 1 // default: first (most recent)
-	if len(args) > 1 && args[1] != "" {
-		if _, err := fmt.Sscanf(args[1], "%d", &idx); err != nil || idx < 1 {
-			_, _ = t.Bot.Send(m.Chat, "Usage: /del [number]\nExample: /del 1 (delete most recent)")
-			return
-		}
+	// parse argument: /del 1, /del 3-7, /del tag:name or /del (without arg = delete last)
+	sel := "1" // default: first (most recent)
+	if args := commandArgs(m); args.len() > 0 {
+		sel = args.from(0)
 	}
 
 	entries, err := t.Store.Load(t.FeedName, t.MaxItems)
 	if err != nil {
-		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf("Error: %v", err))
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Error: %v"), err))
 		return
 	}
 
 	if len(entries) == 0 {
-		_, _ = t.Bot.Send(m.Chat, "Feed is empty.")
+		_, _ = t.Bot.Send(m.Chat, t.tr("Feed is empty."))
 		return
 	}
 
-	if idx > len(entries) {
-		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf("Only %d entries in feed.", len(entries)))
+	selected, problem := selectEntries(entries, sel)
+	if problem != "" {
+		_, _ = t.Bot.Send(m.Chat, problem)
 		return
 	}
 
-	entry := entries[idx-1]
-
-	if err := t.deleteEntry(entry); err != nil {
-		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf("Error removing: %v", err))
+	if err := t.deleteEntries(selected); err != nil {
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Error removing: %v"), err))
 		return
 	}
 
 	// Show updated list after deletion
+	msg := deletedSummary(selected)
 	updatedEntries, err := t.Store.Load(t.FeedName, 10)
 	if err != nil {
-		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf("🗑 Deleted: %s\n\n(Error loading updated list: %v)", entry.Title, err))
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("%s\n(Error loading updated list: %v)"), msg, err))
 		return
 	}
 
-	msg := fmt.Sprintf("🗑 Deleted: %s\n\n", entry.Title)
 	if len(updatedEntries) == 0 {
-		msg += "Feed is now empty."
+		msg += t.tr("Feed is now empty.")
 	} else {
-		msg += fmt.Sprintf("Remaining (%d):\n", len(updatedEntries))
+		msg += fmt.Sprintf(t.tr("Remaining (%d):\n"), len(updatedEntries))
 		for i, e := range updatedEntries {
 			dur := time.Duration(e.Duration) * time.Second
 			msg += fmt.Sprintf("%d. %s (%s)\n", i+1, e.Title, t.formatDuration(dur))
@@ -465,19 +797,26 @@ func (t *TelegramBot) handleDelete(m *tb.Message) {
 // handleHelp sends help message
 func (t *TelegramBot) handleHelp(m *tb.Message) {
 	if !t.isAuthorized(m.Sender) {
-		_, _ = t.Bot.Send(m.Chat, "Unauthorized. This bot is private.")
+		_, _ = t.Bot.Send(m.Chat, t.tr("Unauthorized. This bot is private."))
 		return
 	}
 
-	help := fmt.Sprintf(`🎧 Turnip Bot
+	help := fmt.Sprintf(t.tr(`🎧 Turnip Bot
 
 Пришли ссылку (YouTube, эпизод или подкаст Apple Podcasts, статья) —
 появится меню: слушать, перевод RU, MD-файл, Notion.
 
 Слушать:
 /list — что сейчас в ленте
-/del [N] — удалить из ленты (последнее или N-е)
-/vo <url> — озвучка YouTube на русском
+/info N — как сделан эпизод N: способ, голос, перевод, настройки
+/del [N] — удалить из ленты (последнее или N-е); /del 3-7 — диапазон; /del tag:<тег> — по типу, каналу или сайту
+/delall — очистить ленту (с подтверждением)
+/undo — вернуть последнее удалённое (файлы хранятся сутки)
+/schedule N|3-7 <когда> [daily] — показать в ленте позже: tomorrow 7am, 19:00, +3h; daily — по одному в день
+/remix N [0.3] — пересвести озвучку N с другой громкостью оригинала (vo_sources)
+/revoice N <голос|язык|пресет> [+20%%] — переозвучить статью N другим голосом, без перевода заново
+/vo <url> — озвучка видео на русском (YouTube, Vimeo и другие сайты yt-dlp); --lang=en — язык видео, --method=vot|subs|dub — способ
+/compare <url> [способ способ] — озвучить двумя способами (vot-cli и субтитры) и прислать оба файла, в ленту не добавляет
 
 Конспекты:
 /md <url> — транскрипт в MD-файл
@@ -489,6 +828,9 @@ func (t *TelegramBot) handleHelp(m *tb.Message) {
 Читать:
 /read <url> — статья в структурный MD (читалка)
 /read — список статей (скачать / открыть / удалить)
+/rsssub <url> [min=N] [пресет] — озвучивать новые посты RSS/Atom
+/rsssub — подписки; /rssunsub N — отписаться
+/glossary add <термин> [= <перевод>] — как переводить термин; /glossary list|del N
 
 Платформа (книги/курсы):
 /feeds — ленты с URL подписки
@@ -496,10 +838,17 @@ func (t *TelegramBot) handleHelp(m *tb.Message) {
 
 Прочее:
 /history — вечный лог всех отправлений
+/stats [chart] — сколько добавлено по неделям, способам и источникам
+/verify — проверить файлы ленты (пропавшие, битые)
+/debug [id] — лог задачи файлом; без id — последние логи
+/lang ru|en|auto — язык сообщений бота
 /help — эта справка
 Файл cookies.txt вложением — обновить YouTube-куки
 
-RSS: %s/yt/rss/%s`, t.BaseURL, t.FeedName)
+RSS: %s/yt/rss/%s`), t.BaseURL, t.FeedName)
+	if len(t.Presets) > 0 {
+		help += t.tr("\n\nПресеты (слово перед ссылкой или кнопка в меню): ") + strings.Join(t.presetNames(), ", ")
+	}
 
 	_, _ = t.Bot.Send(m.Chat, help)
 }
@@ -519,7 +868,7 @@ func (t *TelegramBot) handleDocument(m *tb.Message) {
 		return
 	}
 	if t.CookiesFile == "" {
-		_, _ = t.Bot.Send(m.Chat, "❌ Cookies file path is not configured on server.")
+		_, _ = t.Bot.Send(m.Chat, t.tr("❌ Cookies file path is not configured on server."))
 		return
 	}
 
@@ -528,17 +877,17 @@ func (t *TelegramBot) handleDocument(m *tb.Message) {
 	// (someone attaching an mp3 by mistake) without cutting off big exports.
 	const maxCookiesSize = 2 * 1024 * 1024
 	if doc.FileSize > maxCookiesSize {
-		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf("❌ File too large (%d bytes). Expected a cookies.txt export.", doc.FileSize))
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("❌ File too large (%d bytes). Expected a cookies.txt export."), doc.FileSize))
 		return
 	}
 
-	status, _ := t.Bot.Send(m.Chat, fmt.Sprintf("🍪 Receiving %s...", doc.FileName))
+	status, _ := t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("🍪 Receiving %s..."), doc.FileName))
 
 	// Download to a temp path next to the target so the final rename is atomic
 	// (same filesystem). Any partial download stays in /tmp-style location.
 	tmpPath := t.CookiesFile + ".incoming"
 	if err := t.Bot.Download(&doc.File, tmpPath); err != nil {
-		_, _ = t.Bot.Edit(status, fmt.Sprintf("❌ Download failed: %v", err))
+		t.edit(status, fmt.Sprintf(t.tr("❌ Download failed: %v"), err))
 		return
 	}
 	// Best-effort cleanup on any early return.
@@ -546,14 +895,14 @@ func (t *TelegramBot) handleDocument(m *tb.Message) {
 
 	raw, err := os.ReadFile(tmpPath)
 	if err != nil {
-		_, _ = t.Bot.Edit(status, fmt.Sprintf("❌ Read failed: %v", err))
+		t.edit(status, fmt.Sprintf(t.tr("❌ Read failed: %v"), err))
 		return
 	}
 
 	if !isValidYouTubeCookies(raw) {
-		_, _ = t.Bot.Edit(status,
-			"❌ Not a valid YouTube auth cookies file (no SAPISID/SID/LOGIN_INFO found). "+
-				"Make sure you're logged into YouTube in your browser before exporting.")
+		t.edit(status,
+			t.tr("❌ Not a valid YouTube auth cookies file (no SAPISID/SID/LOGIN_INFO found). ")+
+				t.tr("Make sure you're logged into YouTube in your browser before exporting."))
 		return
 	}
 
@@ -567,7 +916,7 @@ func (t *TelegramBot) handleDocument(m *tb.Message) {
 
 	// Atomic replace: rename over the target (same fs).
 	if err := os.Rename(tmpPath, t.CookiesFile); err != nil {
-		_, _ = t.Bot.Edit(status, fmt.Sprintf("❌ Install failed: %v", err))
+		t.edit(status, fmt.Sprintf(t.tr("❌ Install failed: %v"), err))
 		return
 	}
 	// Tighten perms; cookies file is sensitive.
@@ -580,7 +929,7 @@ func (t *TelegramBot) handleDocument(m *tb.Message) {
 		log.Printf("[WARN] failed to delete cookies message: %v", delErr)
 	}
 
-	_, _ = t.Bot.Edit(status, fmt.Sprintf("✅ Cookies updated (%d bytes). Try a YouTube link now.", len(raw)))
+	t.edit(status, fmt.Sprintf(t.tr("✅ Cookies updated (%d bytes). Try a YouTube link now."), len(raw)))
 	t.deleteMessageAfterDelay(status, 15*time.Second)
 }
 
@@ -600,10 +949,11 @@ func isValidYouTubeCookies(data []byte) bool {
 
 // isAuthorized checks if user is allowed
 func (t *TelegramBot) isAuthorized(user *tb.User) bool {
-	if user == nil {
+	if user == nil || int64(user.ID) != t.AllowedUserID {
 		return false
 	}
-	return int64(user.ID) == t.AllowedUserID
+	t.noteUserLang(user)
+	return true
 }
 
 // videoResult holds the outcome of processing a single video (without Telegram UI).
@@ -614,6 +964,14 @@ type videoResult struct {
 	Skipped  bool // true when video was already in feed
 }
 
+// linksPrompt is the question of the link menu of n YouTube links
+func (t *TelegramBot) linksPrompt(n int) string {
+	if n == 1 {
+		return t.tr("🤔 Что сделать со ссылкой?")
+	}
+	return fmt.Sprintf(t.tr("🤔 Что сделать с %d ссылками?"), n)
+}
+
 // extractYouTubeVideoID extracts video ID from YouTube URL
 func (t *TelegramBot) extractYouTubeVideoID(text string) string {
 	patterns := []*regexp.Regexp{
@@ -675,15 +1033,15 @@ func extractPlaylistURL(text string) string {
 // action menu as a batch of links. Runs off the main handler (yt-dlp can take
 // a couple of seconds), so it owns its own status message.
 func (t *TelegramBot) handlePlaylistLink(ctx context.Context, m *tb.Message, plURL string) {
-	status, _ := t.Bot.Send(m.Chat, "⏳ Читаю плейлист...")
+	status, _ := t.Bot.Send(m.Chat, t.tr("⏳ Читаю плейлист..."))
 	ids, err := t.Downloader.ExpandPlaylist(ctx, plURL)
 	if err != nil {
-		if ytfeed.IsCookieError(err.Error()) {
-			_, _ = t.Bot.Edit(status, "❌ YouTube cookies expired. Run update-cookies.sh to fix.")
+		if _, ok := ytfeed.AsVideoError(err); ok || isCookieError(err) {
+			t.edit(status, t.userErrorText(err))
 			return
 		}
 		log.Printf("[ERROR] failed to expand playlist %s: %v", plURL, err)
-		_, _ = t.Bot.Edit(status, "❌ Не смог прочитать плейлист (пустой, приватный или недоступен)")
+		t.edit(status, t.tr("❌ Не смог прочитать плейлист (пустой, приватный или недоступен)"))
 		return
 	}
 
@@ -695,20 +1053,21 @@ func (t *TelegramBot) handlePlaylistLink(ctx context.Context, m *tb.Message, plU
 	}
 
 	token := t.storePendingAction(&pendingAction{kind: "yt", videoIDs: ids, originalMsg: m})
-	prompt := fmt.Sprintf("🎬 Плейлист: %d видео. Что сделать?", total)
+	prompt := fmt.Sprintf(t.tr("🎬 Плейлист: %d видео. Что сделать?"), total)
 	if capped {
-		prompt = fmt.Sprintf("🎬 Плейлист: %d видео, обработаю первые %d. Что сделать?", total, maxPlaylistItems)
+		prompt = fmt.Sprintf(t.tr("🎬 Плейлист: %d видео, обработаю первые %d. Что сделать?"), total, maxPlaylistItems)
 	}
-	_, _ = t.Bot.Edit(status, prompt, t.buildActionMenu(token, "yt"))
+	t.edit(status, prompt, t.buildActionMenu(token, "yt"))
 }
 
 // processVideoItem contains the core video processing logic without any Telegram UI calls.
-// It downloads the video, saves it to the store, and returns the result.
-func (t *TelegramBot) processVideoItem(ctx context.Context, videoID string) (*videoResult, error) {
+// It downloads the video, saves it to the store, and returns the result. Progress,
+// if set, gets the status text of the download with its learned time.
+func (t *TelegramBot) processVideoItem(ctx context.Context, videoID string, progress func(status string)) (*videoResult, error) {
 	videoURL := "https://www.youtube.com/watch?v=" + videoID
 
 	// 1. Fetch metadata
-	info, err := t.Downloader.GetInfo(ctx, videoURL)
+	info, err := t.videoInfo(ctx, videoURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get video info: %w", err)
 	}
@@ -720,11 +1079,20 @@ func (t *TelegramBot) processVideoItem(ctx context.Context, videoID string) (*vi
 	}
 
 	// 3. Download audio
+	if progress != nil {
+		progress(fmt.Sprintf(t.tr("⬇️ Скачиваю: %s...%s"), info.Title, t.etaLine(ctx, etaDownload, info.Duration)))
+	}
 	fname := t.makeFileName(videoID)
-	file, err := t.Downloader.Get(ctx, videoID, fname)
+	started := time.Now()
+	var opts []ytfeed.GetOption
+	if q := t.channelRule(info.ChannelID).Quality; q != "" {
+		opts = append(opts, ytfeed.AudioQuality(q))
+	}
+	file, err := t.Downloader.Get(ctx, videoID, fname, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download: %w", err)
 	}
+	t.observeETA(etaDownload, info.Duration, time.Since(started))
 
 	// 4. Get duration from file
 	duration := int(info.Duration)
@@ -738,7 +1106,7 @@ func (t *TelegramBot) processVideoItem(ctx context.Context, videoID string) (*vi
 	entry := t.createEntry(info, file, duration)
 
 	// 6. Store in BoltDB
-	created, err := t.Store.Save(entry)
+	created, err := t.saveEntry(ctx, entry)
 	if err != nil {
 		return nil, fmt.Errorf("failed to save: %w", err)
 	}
@@ -750,6 +1118,9 @@ func (t *TelegramBot) processVideoItem(ctx context.Context, videoID string) (*vi
 	if err := t.Store.SetProcessed(entry); err != nil {
 		log.Printf("[WARN] failed to mark as processed: %v", err)
 	}
+	if err := t.Archive.Add(videoID); err != nil {
+		log.Printf("[WARN] failed to add %s to download archive: %v", videoID, err)
+	}
 	t.offloadMedia(entry)
 
 	// 8. Append to permanent history log (survives /del and auto-cleanup)
@@ -772,15 +1143,15 @@ func (t *TelegramBot) processVideoItem(ctx context.Context, videoID string) (*vi
 
 // processVideo downloads and stores a YouTube video (single-video path with Telegram status messages).
 func (t *TelegramBot) processVideo(ctx context.Context, chat *tb.Chat, statusMsg, originalMsg *tb.Message, videoID string) error {
-	res, err := t.processVideoItem(ctx, videoID)
+	res, err := t.processVideoItem(ctx, videoID, func(status string) { t.edit(statusMsg, status) })
 	if err != nil {
 		return err
 	}
 
 	if res.Skipped {
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("⚠️ Already in feed: %s", res.Title))
+		t.edit(statusMsg, fmt.Sprintf(t.tr("⚠️ Already in feed: %s"), res.Title))
 	} else {
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("✅ %s (%s)", res.Title, t.formatDuration(res.Duration)))
+		t.edit(statusMsg, fmt.Sprintf("✅ %s (%s)", res.Title, t.formatDuration(res.Duration)))
 	}
 
 	t.deleteMessageAfterDelay(originalMsg, 5*time.Second)
@@ -792,51 +1163,83 @@ func (t *TelegramBot) processVideoBatch(ctx context.Context, chat *tb.Chat, stat
 	total := len(videoIDs)
 	var added, skipped, failed int
 	cookieErrShown := false
+	unavailable := map[ytfeed.FailureKind]int{}
 
 	for i, id := range videoIDs {
 		pos := fmt.Sprintf("%d/%d", i+1, total)
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("⬇️ %s: Processing...", pos))
+		t.edit(statusMsg, fmt.Sprintf(t.tr("⬇️ %s: Processing..."), pos))
 
-		res, err := t.processVideoItem(ctx, id)
+		res, err := t.processVideoItem(ctx, id, func(status string) {
+			t.edit(statusMsg, fmt.Sprintf("⬇️ %s: %s", pos, strings.TrimPrefix(status, "⬇️ ")))
+		})
 		if err != nil {
 			failed++
 			log.Printf("[ERROR] batch %s: failed to process video %s: %v", pos, id, err)
-			if ytfeed.IsCookieError(err.Error()) && !cookieErrShown {
+			if ve, ok := ytfeed.AsVideoError(err); ok && ve.Kind != ytfeed.FailureCookies {
+				unavailable[ve.Kind]++
+			} else {
+				t.reportFailure(failure{ID: fmt.Sprintf("%s-%d", tools.JobID(ctx), i+1), Log: tools.JobID(ctx), Job: "audio",
+					URL: "https://www.youtube.com/watch?v=" + id, Err: err, retry: t.retryVideo(id)})
+			}
+			if isCookieError(err) && !cookieErrShown {
 				cookieErrShown = true
-				_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("⚠️ %s: cookies expired, continuing...", pos))
+				t.edit(statusMsg, fmt.Sprintf(t.tr("⚠️ %s: cookies expired, continuing..."), pos))
 			}
 			continue
 		}
 
 		if res.Skipped {
 			skipped++
-			_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("⚠️ %s: %s (already in feed)", pos, res.Title))
+			t.edit(statusMsg, fmt.Sprintf(t.tr("⚠️ %s: %s (already in feed)"), pos, res.Title))
 		} else {
 			added++
-			_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("✅ %s: %s (%s)", pos, res.Title, t.formatDuration(res.Duration)))
+			t.edit(statusMsg, fmt.Sprintf("✅ %s: %s (%s)", pos, res.Title, t.formatDuration(res.Duration)))
 		}
 	}
 
 	// Build final summary
-	summary := fmt.Sprintf("✅ Added %d/%d", added, total)
+	summary := fmt.Sprintf(t.tr("✅ Added %d/%d"), added, total)
 	var details []string
 	if skipped > 0 {
-		details = append(details, fmt.Sprintf("%d already in feed", skipped))
+		details = append(details, fmt.Sprintf(t.tr("%d already in feed"), skipped))
 	}
 	if failed > 0 {
-		details = append(details, fmt.Sprintf("%d failed", failed))
+		details = append(details, fmt.Sprintf(t.tr("%d failed"), failed))
 	}
 	if len(details) > 0 {
 		summary += " (" + strings.Join(details, ", ") + ")"
 	}
+	summary += t.failureKindsSummary(unavailable)
 	if cookieErrShown {
-		summary += "\n⚠️ YouTube cookies expired. Run update-cookies.sh to fix."
+		summary += t.tr("\n⚠️ YouTube cookies expired. Run update-cookies.sh to fix.")
 	}
 
-	_, _ = t.Bot.Edit(statusMsg, summary)
+	t.edit(statusMsg, summary)
 	t.deleteMessageAfterDelay(originalMsg, 5*time.Second)
 }
 
+// failureKindsSummary renders per-kind counts of unavailable videos for a
+// batch summary, one line per kind, "" when there are none
+func (t *TelegramBot) failureKindsSummary(kinds map[ytfeed.FailureKind]int) string {
+	labels := []struct {
+		kind  ytfeed.FailureKind
+		label string
+	}{
+		{ytfeed.FailureAgeRestricted, t.tr("🔞 с возрастным ограничением")},
+		{ytfeed.FailureMembersOnly, t.tr("💎 только для спонсоров")},
+		{ytfeed.FailureGeoBlocked, t.tr("🌍 недоступны в стране сервера")},
+		{ytfeed.FailurePrivate, t.tr("🔒 приватные")},
+		{ytfeed.FailureRemoved, t.tr("🗑 удалены")},
+	}
+	var b strings.Builder
+	for _, l := range labels {
+		if n := kinds[l.kind]; n > 0 {
+			fmt.Fprintf(&b, "\n%s: %d", l.label, n)
+		}
+	}
+	return b.String()
+}
+
 // deleteMessageAfterDelay deletes a message after specified delay
 func (t *TelegramBot) deleteMessageAfterDelay(msg *tb.Message, delay time.Duration) {
 	if msg == nil {
@@ -849,46 +1252,50 @@ func (t *TelegramBot) deleteMessageAfterDelay(msg *tb.Message, delay time.Durati
 }
 
 // removeOldEntries removes entries exceeding MaxItems and deletes their files.
-// Capture overflow video IDs *before* RemoveOld so we can mark the matching
-// history entries as deleted afterwards (history records survive cleanup,
-// they're just flagged).
+// Capture overflow entries *before* RemoveOld so we can delete their files and
+// mark the matching history entries as deleted afterwards (history records
+// survive cleanup, they're just flagged).
 func (t *TelegramBot) removeOldEntries() {
 	if t.MaxItems <= 0 {
 		return
 	}
 
-	type overflowID struct {
-		videoID string
-		url     string
-	}
-	var overflow []overflowID
-	if all, err := t.Store.Load(t.FeedName, 0); err == nil {
-		// Load returns newest-first; anything past MaxItems will be removed.
-		if len(all) > t.MaxItems {
-			for _, e := range all[t.MaxItems:] {
-				overflow = append(overflow, overflowID{videoID: e.VideoID, url: e.Link.Href})
-			}
-		}
+	var overflow []ytfeed.Entry
+	if all, err := t.Store.Load(t.FeedName, 0); err == nil && len(all) > t.MaxItems {
+		overflow = all[t.MaxItems:] // Load returns newest-first, anything past MaxItems will be removed
 	}
 
-	files, err := t.Store.RemoveOld(t.FeedName, t.MaxItems)
-	if err != nil {
+	if _, err := t.Store.RemoveOld(t.FeedName, t.MaxItems); err != nil {
 		log.Printf("[WARN] failed to remove old entries: %v", err)
+		return // removed in one transaction, nothing is gone
 	}
 
-	for _, f := range files {
-		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
-			log.Printf("[WARN] failed to delete old file %s: %v", f, err)
-		} else {
-			log.Printf("[INFO] auto-removed old file %s", f)
+	for _, e := range overflow {
+		t.removeEntryFiles(e)
+		if err := t.Store.MarkHistoryDeleted(t.FeedName, e.VideoID, e.Link.Href); err != nil {
+			log.Printf("[WARN] failed to mark history deleted for %s: %v", e.VideoID, err)
 		}
-		t.deleteMediaObject(f)
+		log.Printf("[INFO] auto-removed old entry %s: %s", e.VideoID, e.Title)
 	}
+}
 
-	for _, o := range overflow {
-		if err := t.Store.MarkHistoryDeleted(t.FeedName, o.videoID, o.url); err != nil {
-			log.Printf("[WARN] failed to mark history deleted for %s: %v", o.videoID, err)
+// expireEntries removes episodes older than MaxAge, except pinned ones, the
+// same way removeOldEntries drops the ones over MaxItems
+func (t *TelegramBot) expireEntries(now time.Time) {
+	if t.MaxAge <= 0 {
+		return
+	}
+	expired, err := t.Store.RemoveExpired(t.FeedName, now.Add(-t.MaxAge))
+	if err != nil {
+		log.Printf("[WARN] failed to remove expired entries: %v", err)
+		return
+	}
+	for _, e := range expired {
+		t.removeEntryFiles(e)
+		if err := t.Store.MarkHistoryDeleted(t.FeedName, e.VideoID, e.Link.Href); err != nil {
+			log.Printf("[WARN] failed to mark history deleted for %s: %v", e.VideoID, err)
 		}
+		log.Printf("[INFO] expired %s: %s, added %s", e.VideoID, e.Title, e.Published.Format(time.DateOnly))
 	}
 }
 
@@ -928,7 +1335,8 @@ func (t *TelegramBot) createEntry(info *ytfeed.VideoInfo, file string, duration
 	return ytfeed.Entry{
 		ChannelID: t.FeedName,
 		VideoID:   info.ID,
-		Title:     "📼 " + info.Title,
+		Title:     info.Title,
+		Kind:      ytfeed.KindVideo,
 		Link: struct {
 			Href string `xml:"href,attr"`
 		}{Href: info.WebpageURL},
@@ -1004,7 +1412,7 @@ func (t *TelegramBot) buildHistoryMessage(entries []ytstore.HistoryEntry, total,
 		page = pages - 1
 	}
 
-	msg := fmt.Sprintf("📜 History (%d) — page %d/%d:\n\n", total, page+1, pages)
+	msg := fmt.Sprintf(t.tr("📜 History (%d) — page %d/%d:\n\n"), total, page+1, pages)
 	for i, e := range entries {
 		num := total - (page*pageSize + i)
 		mark := "✓"
@@ -1012,9 +1420,9 @@ func (t *TelegramBot) buildHistoryMessage(entries []ytstore.HistoryEntry, total,
 		if e.Deleted {
 			mark = "✗"
 			if !e.DeletedAt.IsZero() {
-				suffix = fmt.Sprintf("  [deleted %s]", e.DeletedAt.Format("2006-01-02"))
+				suffix = fmt.Sprintf(t.tr("  [deleted %s]"), e.DeletedAt.Format("2006-01-02"))
 			} else {
-				suffix = "  [deleted]"
+				suffix = t.tr("  [deleted]")
 			}
 		}
 		dur := ""
@@ -1068,9 +1476,9 @@ func (t *TelegramBot) buildListMessage(kind string, entries []ytfeed.Entry, page
 
 	var msg string
 	if kind == "history" {
-		msg = fmt.Sprintf("📜 History (%d) — page %d/%d:\n\n", total, page+1, pages)
+		msg = fmt.Sprintf(t.tr("📜 History (%d) — page %d/%d:\n\n"), total, page+1, pages)
 	} else {
-		msg = fmt.Sprintf("Recent videos (%d) — page %d/%d:\n\n", total, page+1, pages)
+		msg = fmt.Sprintf(t.tr("Recent videos (%d) — page %d/%d:\n\n"), total, page+1, pages)
 	}
 
 	for i := start; i < end; i++ {
@@ -1080,7 +1488,11 @@ func (t *TelegramBot) buildListMessage(kind string, entries []ytfeed.Entry, page
 			msg += fmt.Sprintf("%d. %s\n%s\n\n", num, e.Title, e.Link.Href)
 		} else {
 			dur := time.Duration(e.Duration) * time.Second
-			msg += fmt.Sprintf("%d. %s (%s)\n", num, e.Title, t.formatDuration(dur))
+			pin := ""
+			if e.Pinned {
+				pin = "📌 "
+			}
+			msg += fmt.Sprintf("%d. %s%s%s (%s)\n", num, pin, scheduledMark(e), e.Title, t.formatDuration(dur))
 		}
 	}
 
@@ -1102,8 +1514,9 @@ func (t *TelegramBot) buildListMessage(kind string, entries []ytfeed.Entry, page
 		markup.InlineKeyboard = append(markup.InlineKeyboard, []tb.InlineButton{*btnPrev.Inline(), *btnNext.Inline()})
 	}
 
-	// per-item actions for the list view: download / notes / delete,
-	// one row per item (same language as the /md list)
+	// per-item actions for the list view: download / notes / delete, plus
+	// pin when episodes expire by age, one row per item (same language as
+	// the /md list)
 	if kind == "list" {
 		for i := start; i < end; i++ {
 			num := i + 1
@@ -1112,8 +1525,12 @@ func (t *TelegramBot) buildListMessage(kind string, entries []ytfeed.Entry, page
 			btnDL := markup.Data(fmt.Sprintf("⬇️ %d", num), "list_act", "a=dl|"+data)
 			btnNotes := markup.Data(fmt.Sprintf("📓 %d", num), "list_act", "a=nt|"+data)
 			btnDel := markup.Data(fmt.Sprintf("🗑 %d", num), "list_del", data)
-			markup.InlineKeyboard = append(markup.InlineKeyboard,
-				[]tb.InlineButton{*btnDL.Inline(), *btnNotes.Inline(), *btnDel.Inline()})
+			row := []tb.InlineButton{*btnDL.Inline(), *btnNotes.Inline()}
+			if t.MaxAge > 0 {
+				btnPin := markup.Data(fmt.Sprintf("📌 %d", num), "list_act", "a=pin|"+data)
+				row = append(row, *btnPin.Inline())
+			}
+			markup.InlineKeyboard = append(markup.InlineKeyboard, append(row, *btnDel.Inline()))
 		}
 	}
 
@@ -1172,6 +1589,12 @@ func (t *TelegramBot) handleCallback(c *tb.Callback) {
 	case strings.HasPrefix(c.Data, "\flist_act|"):
 		c.Data = strings.TrimPrefix(c.Data, "\flist_act|")
 		t.handleListEntryActionCallback(c)
+	case strings.HasPrefix(c.Data, "\fretry|"):
+		c.Data = strings.TrimPrefix(c.Data, "\fretry|")
+		t.handleRetryCallback(c)
+	case strings.HasPrefix(c.Data, "\fdelall|"):
+		c.Data = strings.TrimPrefix(c.Data, "\fdelall|")
+		t.handleDeleteAllCallback(c)
 	case strings.HasPrefix(c.Data, "\fact|"):
 		c.Data = strings.TrimPrefix(c.Data, "\fact|")
 		t.handleActionCallback(c)
@@ -1208,27 +1631,48 @@ func (t *TelegramBot) handleActionCallback(c *tb.Callback) {
 
 	parts := strings.SplitN(c.Data, "|", 2)
 	if len(parts) != 2 {
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Bad data"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Bad data")})
 		return
 	}
 	token, action := parts[0], parts[1]
 
+	if name, ok := strings.CutPrefix(action, "preset:"); ok {
+		t.selectPreset(c, token, name)
+		return
+	}
+
 	pa := t.takePendingAction(token)
 	if pa == nil {
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Просрочено"})
-		_, _ = t.Bot.Edit(c.Message, "⏱ Меню просрочено или уже использовано")
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Просрочено")})
+		t.edit(c.Message, t.tr("⏱ Меню просрочено или уже использовано"))
 		return
 	}
 
 	_ = t.Bot.Respond(c)
 
 	if action == "cancel" {
-		_, _ = t.Bot.Edit(c.Message, "🚫 Отменено")
+		t.edit(c.Message, t.tr("🚫 Отменено"))
 		return
 	}
 
-	statusMsg := c.Message
-	chat := c.Message.Chat
+	t.runAction(c.Message.Chat, c.Message, pa, action)
+}
+
+// runAction starts the job of a link menu button in the background, the job
+// reports its progress and result in statusMsg
+func (t *TelegramBot) runAction(chat *tb.Chat, statusMsg *tb.Message, pa *pendingAction, action string) {
+	release, since, ok := t.claimInflight(inflightKeys(pa, action), pa.forceInflight)
+	if !ok {
+		t.offerInflightOverride(statusMsg, pa, action, since)
+		return
+	}
+	pa.release = release
+	defer func() { // no job took the claim: the action failed to start or queued elsewhere
+		if pa.release != nil {
+			pa.release()
+			pa.release = nil
+		}
+	}()
 
 	switch pa.kind {
 	case "yt":
@@ -1239,7 +1683,7 @@ func (t *TelegramBot) handleActionCallback(c *tb.Callback) {
 			for i, videoID := range pa.videoIDs {
 				st := statusMsg
 				if i > 0 {
-					st, _ = t.Bot.Send(chat, "⏳ Конспект в очереди...")
+					st, _ = t.Bot.Send(chat, t.tr("⏳ Конспект в очереди..."))
 				}
 				var origMsg *tb.Message
 				if i == 0 {
@@ -1252,86 +1696,113 @@ func (t *TelegramBot) handleActionCallback(c *tb.Callback) {
 			// notes jobs get their own status messages; the audio flow owns
 			// statusMsg and the original message deletion
 			for _, videoID := range pa.videoIDs {
-				st, _ := t.Bot.Send(chat, "⏳ Конспект в очереди...")
+				st, _ := t.Bot.Send(chat, t.tr("⏳ Конспект в очереди..."))
 				t.enqueueNotesJob(st, nil, "https://www.youtube.com/watch?v="+videoID, "notes", "")
 			}
 		case "vo":
 			if !IsVotCliAvailable() {
-				_, _ = t.Bot.Edit(statusMsg, "❌ vot-cli not installed")
+				t.edit(statusMsg, t.tr("❌ vot-cli not installed"))
 				return
 			}
 			if len(pa.videoIDs) == 1 {
-				_, _ = t.Bot.Edit(statusMsg, "⏳ Получаю озвучку...")
+				t.edit(statusMsg, t.tr("⏳ Получаю озвучку..."))
 				videoID := pa.videoIDs[0]
 				videoURL := "https://www.youtube.com/watch?v=" + videoID
-				go func() {
-					if err := t.processVoiceover(context.Background(), chat, statusMsg, pa.originalMsg, videoURL, videoID); err != nil {
-						log.Printf("[ERROR] failed to process voiceover %s: %v", videoID, err)
-						if ytfeed.IsCookieError(err.Error()) {
-							_, _ = t.Bot.Edit(statusMsg,
-								"❌ YouTube cookies expired. This video requires authentication.\nRun update-cookies.sh to fix.")
-						} else {
-							_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("❌ Error: %v", err))
-						}
+				t.goActionJob(pa, voiceoverJobTimeout, func(ctx context.Context) {
+					if err := t.processVoiceover(ctx, chat, statusMsg, pa.originalMsg, videoURL, videoID, t.actionPreset(pa)); err != nil {
+						t.reportVoiceoverError(ctx, statusMsg, pa.originalMsg, videoURL, videoID, t.actionPreset(pa), err)
 					}
-				}()
+				})
 			} else {
-				_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("⏳ Озвучиваю %d видео...", len(pa.videoIDs)))
-				go t.processVoiceoverBatch(context.Background(), chat, statusMsg, pa.originalMsg, pa.videoIDs)
+				t.edit(statusMsg, fmt.Sprintf(t.tr("⏳ Озвучиваю %d видео..."), len(pa.videoIDs)))
+				t.goActionJob(pa, time.Duration(len(pa.videoIDs))*voiceoverJobTimeout, func(ctx context.Context) {
+					t.processVoiceoverBatch(ctx, chat, statusMsg, pa.originalMsg, pa.videoIDs, t.actionPreset(pa))
+				})
 			}
 		default:
-			_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("❌ Unknown action: %s", action))
+			t.edit(statusMsg, fmt.Sprintf(t.tr("❌ Unknown action: %s"), action))
 		}
+	case "video":
+		if action != "vo" {
+			t.edit(statusMsg, fmt.Sprintf(t.tr("❌ Unknown action: %s"), action))
+			return
+		}
+		videoID := sourceID(pa.url)
+		t.goActionJob(pa, voiceoverJobTimeout, func(ctx context.Context) {
+			if err := t.processVoiceover(ctx, chat, statusMsg, pa.originalMsg, pa.url, videoID, t.actionPreset(pa)); err != nil {
+				t.reportVoiceoverError(ctx, statusMsg, pa.originalMsg, pa.url, videoID, t.actionPreset(pa), err)
+			}
+		})
 	case "podcast":
 		switch action {
 		case "audio":
-			_, _ = t.Bot.Edit(statusMsg, "⏳ Скачиваю эпизод...")
-			go func() {
-				if err := t.processPodcastAudio(context.Background(), chat, statusMsg, pa.originalMsg, pa.url); err != nil {
+			t.edit(statusMsg, t.tr("⏳ Скачиваю эпизод..."))
+			t.goActionJob(pa, audioJobTimeout, func(ctx context.Context) {
+				if err := t.processPodcastAudio(ctx, chat, statusMsg, pa.originalMsg, pa.url); err != nil {
 					log.Printf("[ERROR] failed to process podcast %s: %v", pa.url, err)
-					_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("❌ Error: %v", err))
+					t.edit(statusMsg, fmt.Sprintf(t.tr("❌ Error: %v"), err))
+					t.reportFailure(failure{Log: tools.JobID(ctx), Job: "podcast audio", URL: pa.url, Err: err, retry: t.retryAction(pa, action)})
 				}
-			}()
+			})
 		case "vo":
 			t.enqueueVoiceoverJob(chat, statusMsg, pa.originalMsg, pa.url)
 		case "md", "notes":
 			t.enqueueNotesJob(statusMsg, pa.originalMsg, pa.url, action, "")
 		default:
-			_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("❌ Unknown action: %s", action))
+			t.edit(statusMsg, fmt.Sprintf(t.tr("❌ Unknown action: %s"), action))
 		}
 	case "podcast_show":
 		switch action {
 		case "audio":
-			_, _ = t.Bot.Edit(statusMsg, "⏳ Добавляю эпизоды...")
-			go t.processPodcastShowBatch(context.Background(), chat, statusMsg, pa.originalMsg, pa.url)
+			t.edit(statusMsg, t.tr("⏳ Добавляю эпизоды..."))
+			t.goActionJob(pa, maxShowEpisodes*audioJobTimeout, func(ctx context.Context) {
+				t.processPodcastShowBatch(ctx, chat, statusMsg, pa.originalMsg, pa.url)
+			})
 		case "vo":
 			if t.NotesSvc == nil {
-				_, _ = t.Bot.Edit(statusMsg, "⏳ Перевожу эпизоды...")
-				go t.processPodcastShowVoiceoverBatch(context.Background(), chat, statusMsg, pa.originalMsg, pa.url)
+				t.edit(statusMsg, t.tr("⏳ Перевожу эпизоды..."))
+				t.goActionJob(pa, maxShowEpisodes*voiceoverJobTimeout, func(ctx context.Context) {
+					t.processPodcastShowVoiceoverBatch(ctx, chat, statusMsg, pa.originalMsg, pa.url)
+				})
 				return
 			}
-			_, _ = t.Bot.Edit(statusMsg, "⏳ Ставлю переводы в очередь...")
-			go t.enqueueShowVoiceovers(context.Background(), chat, statusMsg, pa.originalMsg, pa.url)
+			t.edit(statusMsg, t.tr("⏳ Ставлю переводы в очередь..."))
+			t.goActionJob(pa, lookupJobTimeout, func(ctx context.Context) {
+				t.enqueueShowVoiceovers(ctx, chat, statusMsg, pa.originalMsg, pa.url)
+			})
 		default:
-			_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("❌ Unknown action: %s", action))
+			t.edit(statusMsg, fmt.Sprintf(t.tr("❌ Unknown action: %s"), action))
 		}
 	case "article":
 		switch action {
 		case "tts":
-			_, _ = t.Bot.Edit(statusMsg, "⏳ Озвучиваю статью...")
-			go func() {
-				if err := t.processArticle(context.Background(), chat, statusMsg, pa.originalMsg, pa.url); err != nil {
+			t.edit(statusMsg, t.tr("⏳ Озвучиваю статью..."))
+			t.goActionJob(pa, articleJobTimeout, func(ctx context.Context) {
+				req := articleRequest{URL: pa.url, Preset: pa.preset, Force: pa.force, Article: pa.article}
+				if err := t.processArticle(ctx, chat, statusMsg, pa.originalMsg, req); err != nil {
+					log.Printf("[ERROR] failed to process article %s: %v", pa.url, err)
+					t.edit(statusMsg, fmt.Sprintf(t.tr("❌ Error: %v"), err))
+					t.reportFailure(failure{Log: tools.JobID(ctx), Job: "article", URL: pa.url, Err: err, retry: t.retryAction(pa, action)})
+				}
+			})
+		case "long:full", "long:summarize", "long:split":
+			t.edit(statusMsg, t.tr("⏳ Озвучиваю статью..."))
+			t.goActionJob(pa, articleJobTimeout, func(ctx context.Context) {
+				req := articleRequest{URL: pa.url, Preset: pa.preset, Force: pa.force, Article: pa.article,
+					LongText: strings.TrimPrefix(action, "long:")}
+				if err := t.processArticle(ctx, chat, statusMsg, pa.originalMsg, req); err != nil {
 					log.Printf("[ERROR] failed to process article %s: %v", pa.url, err)
-					_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("❌ Error: %v", err))
+					t.edit(statusMsg, fmt.Sprintf(t.tr("❌ Error: %v"), err))
+					t.reportFailure(failure{Log: tools.JobID(ctx), Job: "article", URL: pa.url, Err: err, retry: t.retryAction(pa, action)})
 				}
-			}()
+			})
 		case "read":
-			_, _ = t.Bot.Edit(statusMsg, "⏳ Добавляю в читалку...")
-			go t.processRead(context.Background(), chat, statusMsg, pa.originalMsg, pa.url)
+			t.edit(statusMsg, t.tr("⏳ Добавляю в читалку..."))
+			t.goActionJob(pa, articleJobTimeout, func(ctx context.Context) { t.processRead(ctx, chat, statusMsg, pa.originalMsg, pa.url) })
 		case "md", "notes":
 			t.enqueueNotesJob(statusMsg, pa.originalMsg, pa.url, action, "")
 		default:
-			_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("❌ Unknown action: %s", action))
+			t.edit(statusMsg, fmt.Sprintf(t.tr("❌ Unknown action: %s"), action))
 		}
 	}
 }
@@ -1339,32 +1810,44 @@ func (t *TelegramBot) handleActionCallback(c *tb.Callback) {
 // processVoiceoverBatch sequentially voices over multiple videos. Each call to
 // processVoiceover rewrites statusMsg with its own progress; a final summary
 // replaces it when the loop is done.
-func (t *TelegramBot) processVoiceoverBatch(ctx context.Context, chat *tb.Chat, statusMsg, originalMsg *tb.Message, videoIDs []string) {
+func (t *TelegramBot) processVoiceoverBatch(ctx context.Context, chat *tb.Chat, statusMsg, originalMsg *tb.Message, videoIDs []string,
+	preset config.Preset) {
 	total := len(videoIDs)
 	var added, failed int
+	var music []string
 	cookieErrShown := false
 
 	for i, id := range videoIDs {
 		pos := fmt.Sprintf("%d/%d", i+1, total)
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("🎙 %s: запускаю озвучку...", pos))
+		t.edit(statusMsg, fmt.Sprintf(t.tr("🎙 %s: запускаю озвучку..."), pos))
 		videoURL := "https://www.youtube.com/watch?v=" + id
-		if err := t.processVoiceover(ctx, chat, statusMsg, originalMsg, videoURL, id); err != nil {
+		if err := t.processVoiceover(ctx, chat, statusMsg, originalMsg, videoURL, id, preset); err != nil {
+			if me := (*musicContentError)(nil); errors.As(err, &me) {
+				music = append(music, videoURL)
+				t.offerOriginalAudio(chat, nil, originalMsg, me.videoID, me.title)
+				continue
+			}
 			failed++
 			log.Printf("[ERROR] batch voiceover %s: %v", pos, err)
-			if ytfeed.IsCookieError(err.Error()) && !cookieErrShown {
+			t.reportFailure(failure{ID: fmt.Sprintf("%s-%d", tools.JobID(ctx), i+1), Log: tools.JobID(ctx), Job: "vo",
+				URL: videoURL, Err: err, retry: t.retryVoiceover(videoURL, id, preset)})
+			if isCookieError(err) && !cookieErrShown {
 				cookieErrShown = true
-				_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("⚠️ %s: cookies expired, continuing...", pos))
+				t.edit(statusMsg, fmt.Sprintf(t.tr("⚠️ %s: cookies expired, continuing..."), pos))
 			}
 			continue
 		}
 		added++
 	}
 
-	summary := fmt.Sprintf("✅ Озвучено %d/%d", added, total)
+	summary := fmt.Sprintf(t.tr("✅ Озвучено %d/%d"), added, total)
 	if failed > 0 {
-		summary += fmt.Sprintf(" (%d failed)", failed)
+		summary += fmt.Sprintf(t.tr(" (%d failed)"), failed)
+	}
+	if len(music) > 0 {
+		summary += fmt.Sprintf(t.tr("\n🎵 Музыка, без перевода (%d) — добавь как 🎵 Аудио:\n%s"), len(music), strings.Join(music, "\n"))
 	}
-	_, _ = t.Bot.Edit(statusMsg, summary)
+	t.edit(statusMsg, summary, tb.NoPreview)
 }
 
 // telegramBotFileLimit is the Bot API upload cap; larger episodes are sent
@@ -1384,16 +1867,19 @@ func (t *TelegramBot) handleListEntryActionCallback(c *tb.Callback) {
 	} else if strings.HasPrefix(c.Data, "a=nt|") {
 		action = "nt"
 		c.Data = strings.TrimPrefix(c.Data, "a=nt|")
+	} else if strings.HasPrefix(c.Data, "a=pin|") {
+		action = "pin"
+		c.Data = strings.TrimPrefix(c.Data, "a=pin|")
 	}
-	_, _, _, videoID := t.unpackCallbackData(c.Data)
+	kind, page, pageSize, videoID := t.unpackCallbackData(c.Data)
 	if action == "" || videoID == "" {
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Bad data"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Bad data")})
 		return
 	}
 
 	entries, err := t.Store.Load(t.FeedName, t.MaxItems)
 	if err != nil {
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Error loading entries"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Error loading entries")})
 		return
 	}
 	var entry *ytfeed.Entry
@@ -1404,7 +1890,7 @@ func (t *TelegramBot) handleListEntryActionCallback(c *tb.Callback) {
 		}
 	}
 	if entry == nil {
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Эпизод не найден"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Эпизод не найден")})
 		return
 	}
 
@@ -1412,7 +1898,7 @@ func (t *TelegramBot) handleListEntryActionCallback(c *tb.Callback) {
 	case "dl":
 		fi, statErr := os.Stat(entry.File)
 		if statErr != nil {
-			_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Файл не найден на диске"})
+			_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Файл не найден на диске")})
 			return
 		}
 		if fi.Size() <= telegramBotFileLimit {
@@ -1423,29 +1909,43 @@ func (t *TelegramBot) handleListEntryActionCallback(c *tb.Callback) {
 			}
 			if _, serr := t.Bot.Send(c.Message.Chat, audio); serr != nil {
 				log.Printf("[WARN] failed to send audio %s: %v", entry.File, serr)
-				_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Не удалось отправить файл"})
+				_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Не удалось отправить файл")})
 				return
 			}
-			_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Отправил аудио"})
+			_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Отправил аудио")})
 			return
 		}
 		// over the Bot API cap: hand out the direct stream link
 		link := t.BaseURL + "/yt/media/" + filepath.Base(entry.File)
-		_, _ = t.Bot.Send(c.Message.Chat, fmt.Sprintf("⬇️ %s\n%s\n(файл %d МБ — больше лимита Telegram, качай по ссылке)",
+		_, _ = t.Bot.Send(c.Message.Chat, fmt.Sprintf(t.tr("⬇️ %s\n%s\n(файл %d МБ — больше лимита Telegram, качай по ссылке)"),
 			entry.Title, link, fi.Size()/1024/1024))
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Прислал ссылку"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Прислал ссылку")})
 	case "nt":
 		if t.NotesSvc == nil {
-			_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Конспекты не настроены"})
+			_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Конспекты не настроены")})
 			return
 		}
 		if entry.Link.Href == "" {
-			_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "У эпизода нет ссылки на источник"})
+			_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("У эпизода нет ссылки на источник")})
 			return
 		}
-		statusMsg, _ := t.Bot.Send(c.Message.Chat, "⏳ В очереди...")
+		statusMsg, _ := t.Bot.Send(c.Message.Chat, t.tr("⏳ В очереди..."))
 		t.enqueueNotesJob(statusMsg, nil, entry.Link.Href, "notes", "")
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Поставил в очередь"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Поставил в очередь")})
+	case "pin":
+		entry.Pinned = !entry.Pinned
+		if err := t.Store.UpdateEntry(*entry); err != nil {
+			log.Printf("[WARN] failed to pin %s: %v", entry.VideoID, err)
+			_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Не удалось закрепить")})
+			return
+		}
+		text := t.tr("Закреплён, не удалится по возрасту")
+		if !entry.Pinned {
+			text = t.tr("Откреплён")
+		}
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: text})
+		msg, markup := t.buildListMessage(kind, entries, page, pageSize)
+		t.edit(c.Message, msg, markup, tb.NoPreview)
 	}
 }
 
@@ -1462,23 +1962,23 @@ func (t *TelegramBot) handleListPageCallback(c *tb.Callback) {
 	if kind == "history" {
 		entries, total, err := t.Store.LoadHistory(t.FeedName, page*pageSize, pageSize)
 		if err != nil {
-			_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Error loading history"})
+			_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Error loading history")})
 			return
 		}
 		msg, markup := t.buildHistoryMessage(entries, total, page, pageSize)
-		_, _ = t.Bot.Edit(c.Message, msg, markup, tb.NoPreview)
+		t.edit(c.Message, msg, markup, tb.NoPreview)
 		_ = t.Bot.Respond(c)
 		return
 	}
 
 	entries, err := t.Store.Load(t.FeedName, t.MaxItems)
 	if err != nil {
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Error loading entries"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Error loading entries")})
 		return
 	}
 
 	msg, markup := t.buildListMessage(kind, entries, page, pageSize)
-	_, _ = t.Bot.Edit(c.Message, msg, markup, tb.NoPreview)
+	t.edit(c.Message, msg, markup, tb.NoPreview)
 	_ = t.Bot.Respond(c)
 }
 
@@ -1489,13 +1989,13 @@ func (t *TelegramBot) handleListDeleteCallback(c *tb.Callback) {
 
 	kind, page, pageSize, videoID := t.unpackCallbackData(c.Data)
 	if videoID == "" {
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Missing video id"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Missing video id")})
 		return
 	}
 
 	entries, err := t.Store.Load(t.FeedName, t.MaxItems)
 	if err != nil {
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Error loading entries"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Error loading entries")})
 		return
 	}
 
@@ -1507,22 +2007,37 @@ func (t *TelegramBot) handleListDeleteCallback(c *tb.Callback) {
 		}
 	}
 	if entry == nil {
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Not found"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Not found")})
 		return
 	}
 
-	if err := t.deleteEntry(*entry); err != nil {
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Delete failed"})
+	if err := t.deleteEntries([]ytfeed.Entry{*entry}); err != nil {
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Delete failed")})
 		return
 	}
 
 	entries, _ = t.Store.Load(t.FeedName, t.MaxItems)
 	msg, markup := t.buildListMessage(kind, entries, page, pageSize)
-	_, _ = t.Bot.Edit(c.Message, msg, markup, tb.NoPreview)
-	_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Deleted"})
+	t.edit(c.Message, msg, markup, tb.NoPreview)
+	_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Deleted, /undo to restore")})
 }
 
+// deleteEntry removes the entry and its files right away, bypassing the trash
 func (t *TelegramBot) deleteEntry(entry ytfeed.Entry) error {
+	t.removeEntryFiles(entry)
+
+	// remove from database
+	if err := t.Store.Remove(entry); err != nil {
+		return err
+	}
+	t.forgetEntry(entry)
+	return nil
+}
+
+// removeEntryFiles deletes the audio of the entry with its side files (the
+// sped-up copy, waveform, archive copy and voiceover sources) from disk and R2.
+// Every removal of entries goes through it, a new side file is added here.
+func (t *TelegramBot) removeEntryFiles(entry ytfeed.Entry) {
 	// delete audio file from disk and its offloaded R2 object
 	if entry.File != "" {
 		if err := os.Remove(entry.File); err != nil && !os.IsNotExist(err) {
@@ -1531,13 +2046,24 @@ func (t *TelegramBot) deleteEntry(entry ytfeed.Entry) error {
 			log.Printf("[INFO] deleted file %s", entry.File)
 		}
 		t.deleteMediaObject(entry.File)
+		removeArchive(entry.File)
 	}
-
-	// remove from database
-	if err := t.Store.Remove(entry); err != nil {
-		return err
+	if entry.SpeedFile != "" {
+		if err := os.Remove(entry.SpeedFile); err != nil && !os.IsNotExist(err) {
+			log.Printf("[WARN] failed to delete sped-up copy %s: %v", entry.SpeedFile, err)
+		}
+		t.deleteMediaObject(entry.SpeedFile)
+	}
+	if entry.Peaks != "" {
+		if err := os.Remove(entry.Peaks); err != nil && !os.IsNotExist(err) {
+			log.Printf("[WARN] failed to delete waveform %s: %v", entry.Peaks, err)
+		}
 	}
+	removeVoiceoverSources(entry)
+}
 
+// forgetEntry updates the processed and history records of a removed entry
+func (t *TelegramBot) forgetEntry(entry ytfeed.Entry) {
 	// reset processed status so it can be re-added if needed
 	_ = t.Store.ResetProcessed(entry)
 
@@ -1547,93 +2073,236 @@ func (t *TelegramBot) deleteEntry(entry ytfeed.Entry) error {
 	}
 
 	log.Printf("[INFO] deleted entry %s: %s", entry.VideoID, entry.Title)
-	return nil
 }
 
 // processArticle extracts article text, converts to speech, and adds to feed
-func (t *TelegramBot) processArticle(ctx context.Context, chat *tb.Chat, statusMsg, originalMsg *tb.Message, articleURL string) error {
-	// 1. Extract article content
-	_, _ = t.Bot.Edit(statusMsg, "⏳ Извлекаю текст статьи...")
-	article, err := t.ArticleExtractor.Extract(ctx, articleURL)
-	if err != nil {
-		return fmt.Errorf("failed to extract article: %w", err)
+func (t *TelegramBot) processArticle(ctx context.Context, chat *tb.Chat, statusMsg, originalMsg *tb.Message, req articleRequest) error {
+	preset := t.preset(req.Preset)
+
+	// 1. Extract article content, TTS warms up meanwhile. Short and tracking
+	// links are resolved first, the article ID is made from the final URL
+	warm := warmupTTS(ctx, t.ttsFor(preset))
+	t.edit(statusMsg, t.tr("⏳ Извлекаю текст статьи..."))
+	articleURL, article := req.URL, req.Article
+	if article == nil {
+		var err error
+		articleURL = t.resolveURL(ctx, req.URL)
+		if article, err = t.ArticleExtractor.Extract(ctx, articleURL); err != nil {
+			return fmt.Errorf("failed to extract article: %w", err)
+		}
+	}
+	textHash := contentHash(article.BlockText()) // of the source text, before preset transforms
+	archivePage, archiveErr := renderArchive(article, articleURL)
+	if archiveErr != nil {
+		log.Printf("[WARN] can't render reader-mode copy of %s: %v", articleURL, archiveErr)
 	}
 
+	if preset.SkipCode || preset.Images || preset.Citations != "" || preset.Emoji != "" || article.hasQuotes() {
+		article.TextContent = article.Text(TextOptions{SkipCode: preset.SkipCode, Images: preset.Images,
+			Citations: preset.Citations, Emoji: preset.Emoji, Quotes: true})
+	}
 	if article.TextContent == "" {
 		return fmt.Errorf("no text content found in article")
 	}
-
-	// 1.5. Translate if needed (for non-Russian articles)
-	translator := NewTranslatorWithKey(os.Getenv("YANDEX_TRANSLATE_KEY"), os.Getenv("YANDEX_FOLDER_ID"), "ru")
-	if translator.NeedsTranslation(article.TextContent) {
-		detectedLang := DetectLanguage(article.TextContent)
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("🌐 Перевожу с %s на русский...", detectedLang))
-
-		translatedText, err := translator.Translate(ctx, article.TextContent)
-		if err != nil {
-			return fmt.Errorf("failed to translate article: %w", err)
-		}
-		article.TextContent = translatedText
+	if n := len([]rune(article.TextContent)); n < req.MinChars {
+		return fmt.Errorf("%w: %d < %d", errTooShort, n, req.MinChars)
 	}
 
-	// 2. Generate unique ID for this article
+	// 2. Generate unique ID for this article, syndicated copies declaring
+	// the original as canonical get the original's ID
+	articleURL = canonicalArticleURL(articleURL, article.Canonical)
 	articleID := t.makeArticleID(articleURL)
 
-	// 3. Check if already processed
+	// 3. Check if already processed, by ID and by the text itself
 	tempEntry := ytfeed.Entry{ChannelID: t.FeedName, VideoID: articleID}
 	if found, _, _ := t.Store.CheckProcessed(tempEntry); found {
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("⚠️ Already in feed: %s", article.Title))
+		t.edit(statusMsg, fmt.Sprintf(t.tr("⚠️ Already in feed: %s"), article.Title))
 		t.deleteMessageAfterDelay(originalMsg, 5*time.Second)
+		return req.inFeed(articleID)
+	}
+	if !req.Force {
+		if pos, dup, found := t.findDuplicate(textHash); found {
+			if req.Background {
+				t.edit(statusMsg, fmt.Sprintf(t.tr("⚠️ Такой же текст уже в ленте: запись %d «%s»"), pos, dup.Title))
+				return req.inFeed(dup.VideoID)
+			}
+			t.offerDuplicateOverride(statusMsg, originalMsg, req, pos, dup)
+			return nil
+		}
+	}
+
+	// 3.2. Over the length limit: ask, reject, summarize or split
+	mode := req.LongText
+	if n, limit := len([]rune(article.TextContent)), t.ArticleLimit.MaxChars; mode == "" && limit > 0 && n > limit {
+		mode = t.ArticleLimit.Policy
+		if mode == "ask" && req.Background {
+			mode = t.backgroundLimitPolicy()
+		}
+		log.Printf("[INFO] article %s is %d chars, over the %d limit: %s", articleURL, n, limit, mode)
+	}
+	switch mode {
+	case "ask":
+		t.offerLongArticleChoice(statusMsg, originalMsg, req, article)
 		return nil
+	case "reject":
+		return fmt.Errorf("%w: %d > %d", errArticleTooLong, len([]rune(article.TextContent)), t.ArticleLimit.MaxChars)
+	case "summarize":
+		preset.Summarize = true
+	case "split":
+		return t.voiceArticleParts(ctx, statusMsg, originalMsg, article, articleURL, textHash, archivePage, preset, warm)
+	}
+
+	// 3.5. Voice a summary instead of the full text
+	if preset.Summarize {
+		t.edit(statusMsg, fmt.Sprintf(t.tr("🧠 Делаю выжимку: %s..."), article.Title))
+		summary, err := t.summarizeForSpeech(ctx, article.TextContent)
+		if err != nil {
+			return err
+		}
+		article.TextContent = summary
 	}
 
-	// 4. Convert to speech
+	entry, created, err := t.voiceArticle(ctx, statusMsg, article, articleURL, textHash, archivePage, preset, warm)
+	if err != nil {
+		return err
+	}
+	if !created {
+		t.edit(statusMsg, fmt.Sprintf(t.tr("⚠️ Already exists: %s"), article.Title))
+		t.deleteMessageAfterDelay(originalMsg, 5*time.Second)
+		return req.inFeed(articleID)
+	}
+
+	dur := time.Duration(entry.Duration) * time.Second
+	t.edit(statusMsg, fmt.Sprintf("✅ 📖 %s (%s)", article.Title, t.formatDuration(dur)))
+
+	// Delete user's message after delay
+	t.deleteMessageAfterDelay(originalMsg, 5*time.Second)
+	return nil
+}
+
+// voiceArticle translates and voices the article text and adds it to the feed
+// under articleURL. created is false when the entry is in the feed already.
+func (t *TelegramBot) voiceArticle(ctx context.Context, statusMsg *tb.Message, article *Article, articleURL, textHash string,
+	archivePage []byte, preset config.Preset, warm <-chan struct{}) (entry ytfeed.Entry, created bool, err error) {
+	tts, translator := t.ttsFor(preset), t.translatorFor(preset)
+	articleID := t.makeArticleID(articleURL)
+
+	// 4. Translate (for non-Russian articles) and convert to speech, pipelined:
+	// audio of the first chunks is produced while the rest is being translated
 	const maxTextLen = 150000 // ~2.5 hours of audio
 	runes := []rune(article.TextContent)
 	if len(runes) > maxTextLen {
 		article.TextContent = string(runes[:maxTextLen])
 		log.Printf("[WARN] article text truncated from %d to %d characters", len(runes), maxTextLen)
 	}
-	charCount := len([]rune(article.TextContent))
-	_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("🔊 Озвучиваю: %s (%d символов)...", article.Title, charCount))
-
-	edgeTTS, ok := t.TTS.(*EdgeTTS)
-	if !ok {
-		return fmt.Errorf("TTS provider is not EdgeTTS")
+	chars := len([]rune(article.TextContent))
+	status := fmt.Sprintf(t.tr("🔊 Озвучиваю: %s (%d символов)"), article.Title, chars)
+	translated := translator != nil && translator.NeedsTranslation(article.TextContent)
+	etaStage := etaTTS
+	if translated {
+		status = fmt.Sprintf(t.tr("🌐 Перевожу с %s и озвучиваю: %s"), DetectLanguage(article.TextContent), article.Title)
+		etaStage = etaTranslatedTTS
+	}
+	t.edit(statusMsg, status+"..."+t.etaLine(ctx, etaStage, float64(chars)))
+	voice := tts // the preamble is Russian whatever the article
+	if !translated {
+		voice = t.voiceFor(ctx, tts, preset, article.TextContent)
 	}
 
-	audioData, err := edgeTTS.SynthesizeLongText(ctx, article.TextContent, 3000)
-	if err != nil {
-		return fmt.Errorf("failed to synthesize speech: %w", err)
+	select {
+	case <-warm:
+	case <-ctx.Done():
+		return ytfeed.Entry{}, false, ctx.Err()
 	}
 
-	// 5. Save audio file
 	fname := t.makeFileName(articleID)
 	filePath := t.FilesLocation + "/" + fname + ".mp3"
-	if err := os.WriteFile(filePath, audioData, 0644); err != nil {
-		return fmt.Errorf("failed to save audio file: %w", err)
+	out, err := createAtomic(filePath)
+	if err != nil {
+		return ytfeed.Entry{}, false, fmt.Errorf("failed to save audio file: %w", err)
 	}
-
-	// 6. Estimate duration (Edge TTS ~150 words/min, ~6 chars/word = ~900 chars/min)
-	duration := int(float64(charCount) / 900.0 * 60.0)
-	if t.DurationSvc != nil {
-		if fileDur := t.DurationSvc.File(filePath); fileDur > 0 {
-			duration = fileDur
+	clock := &mp3Clock{w: out} // chapter offsets
+	if err := t.writeJingle(clock, t.Intro.Jingle); err != nil {
+		out.Abort()
+		return ytfeed.Entry{}, false, err
+	}
+	speech := t.newSpeechCopy(clock.elapsed) // the read-along is aligned without the jingles
+	defer speech.remove()
+	preamble, err := t.writePreamble(ctx, speech.tee(clock), tts, article, articleURL)
+	if err != nil {
+		out.Abort()
+		return ytfeed.Entry{}, false, err
+	}
+	var lastEdit time.Time
+	var spoken strings.Builder
+	headings, chapterStarts := article.Headings(), map[int]time.Duration{}
+	pipe := speechPipeline{TTS: voice, Translator: translator, ChunkSize: 3000, Spoken: &spoken,
+		Marks: headings, OnMark: func(i int) { chapterStarts[i] = clock.elapsed }}
+	started := time.Now()
+	charCount, err := pipe.Run(ctx, article.TextContent, speech.tee(clock), func(done, total int) {
+		if done < total && time.Since(lastEdit) > 10*time.Second {
+			lastEdit = time.Now()
+			t.edit(statusMsg, fmt.Sprintf("%s... %d/%d%s", status, done, total, t.remainingETA(started, done, total)))
 		}
+	})
+	if err != nil {
+		out.Abort()
+		return ytfeed.Entry{}, false, fmt.Errorf("failed to synthesize speech: %w", err)
+	}
+	t.observeETA(etaStage, float64(chars), time.Since(started))
+	if err := t.writeJingle(clock, t.Intro.Outro); err != nil {
+		out.Abort()
+		return ytfeed.Entry{}, false, err
 	}
 
-	// 7. Create entry
-	entry := t.createArticleEntry(article, articleURL, filePath, duration)
+	// 5. Save audio file
+	if err := out.Commit(0o644); err != nil {
+		return ytfeed.Entry{}, false, fmt.Errorf("failed to save audio file: %w", err)
+	}
+	if err := t.finalizeAudio(ctx, filePath); err != nil {
+		return ytfeed.Entry{}, false, err
+	}
+
+	// 6. Get duration, chapters at the headings and the word timings for read-along
+	duration := t.ttsDuration(filePath, charCount)
+	var chaptersTranslator TranslationProvider
+	if translated {
+		chaptersTranslator = translator
+	}
+	chapters := t.saveChapters(ctx, filePath, articleChapters(article.Title, headings, chapterStarts),
+		time.Duration(duration)*time.Second, chaptersTranslator)
+	transcript := t.alignTranscript(ctx, filePath, speech, strings.TrimSpace(preamble+"\n"+spoken.String()))
+
+	// 7. Create entry, with the reader-mode copy saved next to the audio
+	entry = t.createArticleEntry(article, articleURL, filePath, duration)
+	entry.Media.Thumbnail.URL = t.articleCover(ctx, article, articleURL, filePath)
+	entry.ContentHash = textHash
+	entry.Transcript = transcript
+	entry.Chapters = chapters
+	entry.Script = saveScript(filePath, spoken.String())
+	var processedBy TranslationProvider
+	if translated {
+		processedBy = translator
+	}
+	entry.Processing = t.processing("tts", voice, processedBy, preset, t.Intro)
+	if translated {
+		t.markTranslated(ctx, &entry, article, articleURL, translator)
+	}
+	if archivePage != nil {
+		archivePath := archiveFile(filePath)
+		if err := writeAtomic(archivePath, archivePage, 0o644); err != nil {
+			log.Printf("[WARN] failed to save reader-mode copy %s: %v", archivePath, err)
+		} else {
+			entry.Media.Description += template.HTML(fmt.Sprintf("\nКопия статьи: %s/yt/media/%s", t.BaseURL, filepath.Base(archivePath)))
+		}
+	}
 
 	// 8. Store in BoltDB
-	created, err := t.Store.Save(entry)
-	if err != nil {
-		return fmt.Errorf("failed to save: %w", err)
+	if created, err = t.saveEntry(ctx, entry); err != nil {
+		return ytfeed.Entry{}, false, fmt.Errorf("failed to save: %w", err)
 	}
 	if !created {
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("⚠️ Already exists: %s", article.Title))
-		t.deleteMessageAfterDelay(originalMsg, 5*time.Second)
-		return nil
+		return entry, false, nil
 	}
 
 	// 9. Mark as processed
@@ -1654,14 +2323,8 @@ func (t *TelegramBot) processArticle(ctx context.Context, chat *tb.Chat, statusM
 	// 11. Remove old entries if exceeding MaxItems
 	t.removeOldEntries()
 
-	dur := time.Duration(duration) * time.Second
-	_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("✅ 📖 %s (%s)", article.Title, t.formatDuration(dur)))
-
-	log.Printf("[INFO] added article %s: %s (duration: %s, chars: %d)", articleID, article.Title, dur.String(), charCount)
-
-	// Delete user's message after delay
-	t.deleteMessageAfterDelay(originalMsg, 5*time.Second)
-	return nil
+	log.Printf("[INFO] added article %s: %s (duration: %s, chars: %d)", articleID, article.Title, articleDur.String(), charCount)
+	return entry, true, nil
 }
 
 // makeArticleID creates a unique ID for an article URL
@@ -1678,16 +2341,13 @@ func (t *TelegramBot) createArticleEntry(article *Article, url, file string, dur
 		title = "Article"
 	}
 
-	thumbnail := article.Image
-	if thumbnail == "" {
-		// Use a default article icon or leave empty
-		thumbnail = ""
-	}
+	thumbnail := article.Image // replaced by a local copy or a generated cover, see articleCover
 
 	return ytfeed.Entry{
 		ChannelID: t.FeedName,
 		VideoID:   t.makeArticleID(url),
-		Title:     "📖 " + title,
+		Title:     title,
+		Kind:      ytfeed.KindArticle,
 		Link: struct {
 			Href string `xml:"href,attr"`
 		}{Href: url},
@@ -1722,60 +2382,175 @@ func (t *TelegramBot) handleVoiceover(m *tb.Message) {
 		return
 	}
 
-	// Extract YouTube URL from command argument
-	args := regexp.MustCompile(`\s+`).Split(m.Text, 2)
-	if len(args) < 2 || args[1] == "" {
-		_, _ = t.Bot.Send(m.Chat, "Usage: /vo <youtube_url>\nExample: /vo https://youtube.com/watch?v=xxx")
+	// /vo [preset] <url> [again] [--lang=en] [--method=vot,subs]
+	usage := t.tr("Usage: /vo <video_url> [--lang=en] [--method=dub|vot|subs]\nExample: /vo https://youtube.com/watch?v=xxx")
+	args := commandArgs(m)
+	if args.len() == 0 || args.unknownFlag("lang", "method", "force") != "" {
+		_, _ = t.Bot.Send(m.Chat, usage)
+		return
+	}
+	var methods []string
+	if list, ok := args.flag("method"); ok {
+		var err error
+		if methods, err = parseVoMethods(list); err != nil {
+			_, _ = t.Bot.Send(m.Chat, "❌ "+err.Error()+"\n\n"+usage)
+			return
+		}
+	}
+	lang, _ := args.flag("lang")
+	lang = strings.ToLower(lang)
+	if lang != "" && !langCodeRe.MatchString(lang) {
+		_, _ = t.Bot.Send(m.Chat, usage)
 		return
 	}
 
-	videoURL := args[1]
+	force, arg := splitForceFlag(args.from(0)) // "/vo <url> again"
+	if _, ok := args.flag("force"); ok {
+		force = true
+	}
+	presetName, videoURL := t.splitPresetPrefix(arg) // "/vo tech <url>"
 	videoID := t.extractYouTubeVideoID(videoURL)
 	if videoID == "" {
-		_, _ = t.Bot.Send(m.Chat, "❌ Invalid YouTube URL")
+		// another site yt-dlp knows: Vimeo, conference talks...
+		if !isWebVideoURL(videoURL) {
+			_, _ = t.Bot.Send(m.Chat, t.tr("❌ Invalid video URL"))
+			return
+		}
+		statusMsg, err := t.Bot.Send(m.Chat, t.tr("⏳ Получаю озвучку..."))
+		if err != nil {
+			log.Printf("[WARN] failed to send voiceover status: %v", err)
+			return
+		}
+		t.runAction(m.Chat, statusMsg, &pendingAction{kind: "video", url: strings.TrimSpace(videoURL), preset: presetName,
+			voMethods: methods, voLang: lang, forceInflight: force, originalMsg: m}, "vo")
 		return
 	}
 
 	// Check if vot-cli is available
-	if !IsVotCliAvailable() {
-		_, _ = t.Bot.Send(m.Chat, "❌ vot-cli not installed")
+	if (len(methods) == 0 || slices.Contains(methods, voMethodVot)) && !IsVotCliAvailable() {
+		_, _ = t.Bot.Send(m.Chat, t.tr("❌ vot-cli not installed"))
 		return
 	}
 
-	statusMsg, _ := t.Bot.Send(m.Chat, "⏳ Получаю озвучку...")
-	go func() {
-		if err := t.processVoiceover(context.Background(), m.Chat, statusMsg, m, videoURL, videoID); err != nil {
-			log.Printf("[ERROR] failed to process voiceover %s: %v", videoID, err)
-			if ytfeed.IsCookieError(err.Error()) {
-				_, _ = t.Bot.Edit(statusMsg,
-					"❌ YouTube cookies expired. This video requires authentication.\nRun update-cookies.sh to fix.")
-			} else {
-				_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("❌ Error: %v", err))
-			}
+	statusMsg, err := t.Bot.Send(m.Chat, t.tr("⏳ Получаю озвучку..."))
+	if err != nil {
+		log.Printf("[WARN] failed to send voiceover status: %v", err)
+		return
+	}
+	t.runAction(m.Chat, statusMsg, &pendingAction{kind: "yt", videoIDs: []string{videoID}, preset: presetName,
+		voMethods: methods, voLang: lang, forceInflight: force, originalMsg: m}, "vo")
+}
+
+// reportVoiceoverError renders a single-video processVoiceover failure into
+// its status message and the admin chat. Music content is not a failure: the
+// status message gets the "add original audio" suggestion.
+func (t *TelegramBot) reportVoiceoverError(ctx context.Context, statusMsg, originalMsg *tb.Message, videoURL, videoID string,
+	preset config.Preset, err error) {
+	if me := (*musicContentError)(nil); errors.As(err, &me) {
+		t.offerOriginalAudio(statusMsg.Chat, statusMsg, originalMsg, me.videoID, me.title)
+		return
+	}
+	log.Printf("[ERROR] failed to process voiceover %s: %v", videoID, err)
+	t.edit(statusMsg, t.userErrorText(err))
+	t.reportFailure(failure{Log: tools.JobID(ctx), Job: "vo", URL: videoURL, Err: err,
+		retry: t.retryVoiceover(videoURL, videoID, preset)})
+}
+
+// userErrorText turns a pipeline error into a chat message. Recognized
+// yt-dlp failures (age, geo, members-only, removed, private, cookies) get an
+// actionable hint; anything else is cut to its first line, the full text
+// with the stderr dump stays in the log. Only network-like failures are
+// called temporary, the cause of an unknown one isn't guessed.
+func (t *TelegramBot) userErrorText(err error) string {
+	if ve, ok := ytfeed.AsVideoError(err); ok {
+		return t.tr(ve.Hint())
+	}
+	switch {
+	case errors.Is(err, ErrTooLong):
+		return t.tr("⏳ Слишком длинно для перевода: vot-cli такие видео не берёт, а субтитров нет. Добавь его как 🎵 Аудио.")
+	case errors.Is(err, ErrNoSubtitles):
+		return t.tr("📝 У видео нет субтитров, перевести через них не получится.")
+	case errors.Is(err, ErrFileTooLarge):
+		return t.tr("📦 Файл больше лимита размера (max_dub_size_mb), скачивание отменено.")
+	case errors.Is(err, ErrNoDub):
+		return t.tr("🎬 Официального русского дубляжа нет, а пресет запрещает машинный перевод.")
+	case errors.Is(err, errArticleTooLong):
+		return t.tr("📏 Статья длиннее лимита (telegram_bot.article_limit.max_chars), не озвучиваю.")
+	case errors.Is(err, errRobotsDisallowed):
+		return t.tr("🤖 robots.txt сайта запрещает загрузку этой страницы (telegram_bot.article_fetch.robots).")
+	}
+	if isCookieError(err) {
+		return t.tr("❌ YouTube cookies expired. This video requires authentication.\nRun update-cookies.sh to fix.")
+	}
+	msg := err.Error()
+	if idx := strings.Index(msg, "\n"); idx >= 0 {
+		msg = msg[:idx]
+	}
+	if runes := []rune(msg); len(runes) > 300 {
+		msg = string(runes[:300]) + "…"
+	}
+	switch {
+	case IsPermanent(err):
+	case isTransient(err):
+		msg += t.tr("\nПохоже на временный сбой, попробуй ещё раз позже.")
+	default:
+		msg += t.tr("\nПричина неясна, подробности в логе.")
+	}
+	return t.tr("❌ Error: ") + msg
+}
+
+// errMusicContent is returned by processVoiceover for songs and music clips:
+// translating lyrics is pointless, the user is offered the original audio
+var errMusicContent = errors.New("music content, translation skipped")
+
+// musicContentError is errMusicContent of a video, with its title for the offer
+type musicContentError struct {
+	videoID, title string
+}
+
+func (e *musicContentError) Error() string { return fmt.Sprintf("%v: %s", errMusicContent, e.title) }
+
+// Is makes errors.Is(err, errMusicContent) hold
+func (e *musicContentError) Is(target error) bool { return target == errMusicContent }
+
+// offerOriginalAudio offers a one-tap button adding the original audio of a
+// music video to the feed, in place of the voiceover status or, without
+// one (a batch shares its status), in a message of its own
+func (t *TelegramBot) offerOriginalAudio(chat *tb.Chat, statusMsg, originalMsg *tb.Message, videoID, title string) {
+	token := t.storePendingAction(&pendingAction{kind: "yt", videoIDs: []string{videoID}, originalMsg: originalMsg})
+	markup := &tb.ReplyMarkup{}
+	btnAudio := markup.Data(t.tr("🎵 Добавить оригинал"), "act", token+"|audio")
+	btnCancel := markup.Data(t.tr("🚫 Отмена"), "act", token+"|cancel")
+	markup.InlineKeyboard = [][]tb.InlineButton{{*btnAudio.Inline(), *btnCancel.Inline()}}
+	text := fmt.Sprintf(t.tr("🎵 «%s» похоже на музыку — переводить нечего.\nДобавить оригинальное аудио?"), title)
+	if statusMsg == nil {
+		if _, err := t.Bot.Send(chat, text, markup); err != nil {
+			log.Printf("[WARN] failed to offer the original audio of %s: %v", videoID, err)
 		}
-	}()
+		return
+	}
+	t.edit(statusMsg, text, markup)
 }
 
 // startAudioProcessing kicks off the existing audio download flow for one or
 // many videos (extracted from the "audio" menu action, behavior unchanged)
 func (t *TelegramBot) startAudioProcessing(chat *tb.Chat, statusMsg *tb.Message, pa *pendingAction) {
 	if len(pa.videoIDs) == 1 {
-		_, _ = t.Bot.Edit(statusMsg, "⏳ Processing...")
-		go func() {
-			if err := t.processVideo(context.Background(), chat, statusMsg, pa.originalMsg, pa.videoIDs[0]); err != nil {
+		t.edit(statusMsg, t.tr("⏳ Processing..."))
+		t.goActionJob(pa, audioJobTimeout, func(ctx context.Context) {
+			if err := t.processVideo(ctx, chat, statusMsg, pa.originalMsg, pa.videoIDs[0]); err != nil {
 				log.Printf("[ERROR] failed to process video %s: %v", pa.videoIDs[0], err)
-				if ytfeed.IsCookieError(err.Error()) {
-					_, _ = t.Bot.Edit(statusMsg,
-						"❌ YouTube cookies expired. This video requires authentication.\nRun update-cookies.sh to fix.")
-				} else {
-					_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("❌ Error: %v", err))
-				}
+				t.edit(statusMsg, t.userErrorText(err))
+				t.reportFailure(failure{Log: tools.JobID(ctx), Job: "audio", URL: "https://www.youtube.com/watch?v=" + pa.videoIDs[0], Err: err,
+					retry: t.retryVideo(pa.videoIDs[0])})
 			}
-		}()
+		})
 		return
 	}
-	_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("⏳ Processing %d videos...", len(pa.videoIDs)))
-	go t.processVideoBatch(context.Background(), chat, statusMsg, pa.originalMsg, pa.videoIDs)
+	t.edit(statusMsg, fmt.Sprintf(t.tr("⏳ Processing %d videos..."), len(pa.videoIDs)))
+	t.goActionJob(pa, time.Duration(len(pa.videoIDs))*audioJobTimeout, func(ctx context.Context) {
+		t.processVideoBatch(ctx, chat, statusMsg, pa.originalMsg, pa.videoIDs)
+	})
 }
 
 // handleMD handles /md <url> — transcript only (L1)
@@ -1789,29 +2564,29 @@ func (t *TelegramBot) handleNotesCommand(m *tb.Message, level string) {
 		return
 	}
 	if t.NotesSvc == nil {
-		_, _ = t.Bot.Send(m.Chat, "❌ Конспекты не настроены (notes.enabled в конфиге + GROQ_API_KEY)")
+		_, _ = t.Bot.Send(m.Chat, t.tr("❌ Конспекты не настроены (notes.enabled в конфиге + GROQ_API_KEY)"))
 		return
 	}
 
-	args := regexp.MustCompile(`\s+`).Split(m.Text, 2)
-	if len(args) < 2 || strings.TrimSpace(args[1]) == "" {
+	args := commandArgs(m)
+	if args.len() == 0 {
 		if level == "md" {
 			t.handleMDList(m) // bare /md shows the stored transcripts
 			return
 		}
-		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf("Usage: /%s <url> [url2 ...] [short|long]", level))
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Usage: /%s <url> [url2 ...] [short|long]"), level))
 		return
 	}
 
 	// pull an optional short|long token; the rest is the link text. length only
 	// affects the /notes summary (L2), /md has no summary so it's ignored there.
-	length, rest := parseSummaryLength(args[1])
+	length, rest := parseSummaryLength(args.from(0))
 
 	// batch: all YouTube links from the message, each with its own status
 	// message — results arrive as each job finishes
 	if videoIDs := t.extractAllYouTubeVideoIDs(rest); len(videoIDs) > 0 {
 		for i, videoID := range videoIDs {
-			statusMsg, _ := t.Bot.Send(m.Chat, "⏳ В очереди...")
+			statusMsg, _ := t.Bot.Send(m.Chat, t.tr("⏳ В очереди..."))
 			var origMsg *tb.Message
 			if i == 0 {
 				origMsg = m
@@ -1823,11 +2598,11 @@ func (t *TelegramBot) handleNotesCommand(m *tb.Message, level string) {
 
 	rawURL := t.extractURL(rest)
 	if rawURL == "" {
-		_, _ = t.Bot.Send(m.Chat, "❌ Не нашёл ссылку в сообщении")
+		_, _ = t.Bot.Send(m.Chat, t.tr("❌ Не нашёл ссылку в сообщении"))
 		return
 	}
 
-	statusMsg, _ := t.Bot.Send(m.Chat, "⏳ В очереди...")
+	statusMsg, _ := t.Bot.Send(m.Chat, t.tr("⏳ В очереди..."))
 	t.enqueueNotesJob(statusMsg, m, rawURL, level, length)
 }
 
@@ -1875,7 +2650,7 @@ func (t *TelegramBot) enqueueNotesJob(statusMsg, originalMsg *tb.Message, rawURL
 	}
 
 	if err := t.NotesSvc.Enqueue(rec); err != nil {
-		_, _ = t.Bot.Edit(statusMsg, "⚠️ "+err.Error())
+		t.edit(statusMsg, "⚠️ "+err.Error())
 	}
 }
 
@@ -1908,7 +2683,7 @@ func (t *TelegramBot) NotesJobProgress(job ytstore.NotesJobRecord, stage string)
 		return
 	}
 	_, statusMsg := t.notesChatMsg(job)
-	_, _ = t.Bot.Edit(statusMsg, stage+"...\n"+notesLabel(job.URL))
+	t.edit(statusMsg, stage+"...\n"+notesLabel(job.URL))
 }
 
 // NotesJobDone implements NotesNotifier: reports success, sends the MD
@@ -1921,11 +2696,11 @@ func (t *TelegramBot) NotesJobDone(job ytstore.NotesJobRecord, res NotesResult)
 		if job.StatusMsgID != 0 {
 			msg := "✅ " + res.Title
 			if res.Reused {
-				msg = fmt.Sprintf("⚠️ %s (already in feed)", res.Title)
+				msg = fmt.Sprintf(t.tr("⚠️ %s (already in feed)"), res.Title)
 			} else if res.DurationSec > 0 {
 				msg += fmt.Sprintf(" (%s)", t.formatDuration(time.Duration(res.DurationSec)*time.Second))
 			}
-			_, _ = t.Bot.Edit(statusMsg, msg)
+			t.edit(statusMsg, msg)
 		}
 		if job.OrigMsgID != 0 {
 			t.deleteMessageAfterDelay(&tb.Message{ID: job.OrigMsgID, Chat: chat}, 5*time.Second)
@@ -1938,7 +2713,7 @@ func (t *TelegramBot) NotesJobDone(job ytstore.NotesJobRecord, res NotesResult)
 		if res.NotionPageURL != "" {
 			msg += "\n📓 " + res.NotionPageURL
 		}
-		_, _ = t.Bot.Edit(statusMsg, msg)
+		t.edit(statusMsg, msg)
 	}
 	if job.Level == "md" {
 		t.sendNoteDocument(chat, res)
@@ -1990,18 +2765,21 @@ func (t *TelegramBot) RunQueuedVoiceover(ctx context.Context, job ytstore.NotesJ
 // without the queue (notes disabled) it falls back to the direct goroutine
 func (t *TelegramBot) enqueueVoiceoverJob(chat *tb.Chat, statusMsg, originalMsg *tb.Message, rawURL string) {
 	if t.NotesSvc == nil {
-		go func() {
-			if err := t.processPodcastVoiceover(context.Background(), chat, statusMsg, originalMsg, rawURL); err != nil {
+		t.goJob(voiceoverJobTimeout, func(ctx context.Context) {
+			if err := t.processPodcastVoiceover(ctx, chat, statusMsg, originalMsg, rawURL); err != nil {
 				log.Printf("[ERROR] failed to process podcast voiceover %s: %v", rawURL, err)
-				_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("❌ Error: %v", err))
+				t.edit(statusMsg, fmt.Sprintf(t.tr("❌ Error: %v"), err))
+				t.reportFailure(failure{Log: tools.JobID(ctx), Job: "podcast vo", URL: rawURL, Err: err, retry: func(chat *tb.Chat, statusMsg *tb.Message) {
+					t.enqueueVoiceoverJob(chat, statusMsg, nil, rawURL)
+				}})
 			}
-		}()
+		})
 		return
 	}
 
 	apID := appleEpisodeIDFromURL(rawURL)
 	if apID == "" {
-		_, _ = t.Bot.Edit(statusMsg, "❌ Не понял ссылку на эпизод")
+		t.edit(statusMsg, t.tr("❌ Не понял ссылку на эпизод"))
 		return
 	}
 	rec := ytstore.NotesJobRecord{
@@ -2016,10 +2794,10 @@ func (t *TelegramBot) enqueueVoiceoverJob(chat *tb.Chat, statusMsg, originalMsg
 		rec.OrigMsgID = originalMsg.ID
 	}
 	if err := t.NotesSvc.Enqueue(rec); err != nil {
-		_, _ = t.Bot.Edit(statusMsg, "⚠️ "+err.Error())
+		t.edit(statusMsg, "⚠️ "+err.Error())
 		return
 	}
-	_, _ = t.Bot.Edit(statusMsg, "⏳ Перевод в очереди...\n"+notesLabel(rawURL))
+	t.edit(statusMsg, t.tr("⏳ Перевод в очереди...\n")+notesLabel(rawURL))
 }
 
 // enqueueShowVoiceovers puts every not-yet-translated catalog episode of a
@@ -2027,7 +2805,7 @@ func (t *TelegramBot) enqueueVoiceoverJob(chat *tb.Chat, statusMsg, originalMsg
 func (t *TelegramBot) enqueueShowVoiceovers(ctx context.Context, chat *tb.Chat, statusMsg, originalMsg *tb.Message, rawURL string) {
 	show, eps, err := t.Apple.ResolveShow(ctx, rawURL)
 	if err != nil {
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("❌ Error: %v", err))
+		t.edit(statusMsg, fmt.Sprintf(t.tr("❌ Error: %v"), err))
 		return
 	}
 	if len(eps) > maxShowEpisodes {
@@ -2041,7 +2819,7 @@ func (t *TelegramBot) enqueueShowVoiceovers(ctx context.Context, chat *tb.Chat,
 			already++
 			continue
 		}
-		st, serr := t.Bot.Send(chat, "⏳ Перевод в очереди: "+ep.Title)
+		st, serr := t.Bot.Send(chat, t.tr("⏳ Перевод в очереди: ")+ep.Title)
 		if serr != nil {
 			continue
 		}
@@ -2054,17 +2832,17 @@ func (t *TelegramBot) enqueueShowVoiceovers(ctx context.Context, chat *tb.Chat,
 			StatusMsgID: st.ID,
 		}
 		if qerr := t.NotesSvc.Enqueue(rec); qerr != nil {
-			_, _ = t.Bot.Edit(st, "⚠️ "+qerr.Error()+"\n"+ep.Title)
+			t.edit(st, "⚠️ "+qerr.Error()+"\n"+ep.Title)
 			continue
 		}
 		queued++
 	}
 
-	summary := fmt.Sprintf("🎙 «%s»: поставил в очередь %d переводов", show, queued)
+	summary := fmt.Sprintf(t.tr("🎙 «%s»: поставил в очередь %d переводов"), show, queued)
 	if already > 0 {
-		summary += fmt.Sprintf(" (%d уже в ленте)", already)
+		summary += fmt.Sprintf(t.tr(" (%d уже в ленте)"), already)
 	}
-	_, _ = t.Bot.Edit(statusMsg, summary)
+	t.edit(statusMsg, summary)
 	t.deleteMessageAfterDelay(originalMsg, 5*time.Second)
 }
 
@@ -2073,10 +2851,12 @@ func (t *TelegramBot) enqueueShowVoiceovers(ctx context.Context, chat *tb.Chat,
 // of silently losing the work.
 func (t *TelegramBot) NotesJobFailed(job ytstore.NotesJobRecord, res NotesResult, err error) {
 	chat, statusMsg := t.notesChatMsg(job)
+	t.reportFailure(failure{Log: job.ID, Job: "notes " + job.Level, URL: job.URL, Err: err,
+		retry: func(_ *tb.Chat, statusMsg *tb.Message) { t.requeueNotesJob(job, statusMsg) }})
 
 	if res.MDPath != "" {
 		if job.StatusMsgID != 0 {
-			_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("⚠️ %s\n📄 транскрипт готов (файл ниже), но дальше не получилось:\n%v", res.Title, err))
+			t.edit(statusMsg, fmt.Sprintf(t.tr("⚠️ %s\n📄 транскрипт готов (файл ниже), но дальше не получилось:\n%v"), res.Title, err))
 		}
 		t.sendNoteDocument(chat, res)
 		return
@@ -2085,12 +2865,19 @@ func (t *TelegramBot) NotesJobFailed(job ytstore.NotesJobRecord, res NotesResult
 	if job.StatusMsgID == 0 {
 		return
 	}
-	if ytfeed.IsCookieError(err.Error()) {
-		_, _ = t.Bot.Edit(statusMsg,
-			"❌ YouTube cookies expired. This video requires authentication.\nRun update-cookies.sh to fix.")
+	t.edit(statusMsg, t.userErrorText(err)+"\n"+notesLabel(job.URL))
+}
+
+// requeueNotesJob puts a copy of a failed job back in the queue, reporting
+// in statusMsg
+func (t *TelegramBot) requeueNotesJob(job ytstore.NotesJobRecord, statusMsg *tb.Message) {
+	job.Error, job.OrigMsgID = "", 0
+	job.ChatID, job.StatusMsgID = statusMsg.Chat.ID, statusMsg.ID
+	if err := t.NotesSvc.Enqueue(job); err != nil {
+		t.edit(statusMsg, "⚠️ "+err.Error())
 		return
 	}
-	_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("❌ Error: %v\n%s", err, notesLabel(job.URL)))
+	t.edit(statusMsg, t.tr("⏳ Снова в очереди...\n")+notesLabel(job.URL))
 }
 
 // handleDigest handles /digest [тег]: bare form lists available tags with
@@ -2100,19 +2887,19 @@ func (t *TelegramBot) handleDigest(m *tb.Message) {
 		return
 	}
 	if t.NotesSvc == nil {
-		_, _ = t.Bot.Send(m.Chat, "❌ Конспекты не настроены (notes.enabled + GROQ_API_KEY)")
+		_, _ = t.Bot.Send(m.Chat, t.tr("❌ Конспекты не настроены (notes.enabled + GROQ_API_KEY)"))
 		return
 	}
 
-	args := regexp.MustCompile(`\s+`).Split(strings.TrimSpace(m.Text), 2)
-	if len(args) < 2 || strings.TrimSpace(args[1]) == "" {
+	args := commandArgs(m)
+	if args.len() == 0 {
 		stats, err := t.NotesSvc.TagStats()
 		if err != nil {
-			_, _ = t.Bot.Send(m.Chat, fmt.Sprintf("Error: %v", err))
+			_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Error: %v"), err))
 			return
 		}
 		if len(stats) == 0 {
-			_, _ = t.Bot.Send(m.Chat, "Пока нет транскриптов с тегами. Сначала /md или /notes.")
+			_, _ = t.Bot.Send(m.Chat, t.tr("Пока нет транскриптов с тегами. Сначала /md или /notes."))
 			return
 		}
 		type tagCount struct {
@@ -2130,23 +2917,23 @@ func (t *TelegramBot) handleDigest(m *tb.Message) {
 			return list[i].tag < list[j].tag
 		})
 		var b strings.Builder
-		b.WriteString("🏷 Теги в транскриптах:\n\n")
+		b.WriteString(t.tr("🏷 Теги в транскриптах:\n\n"))
 		for _, tc := range list {
 			fmt.Fprintf(&b, "• %s (%d)\n", tc.tag, tc.n)
 		}
-		b.WriteString("\nСобрать конспект по теме: /digest <тег>")
+		b.WriteString(t.tr("\nСобрать конспект по теме: /digest <тег>"))
 		_, _ = t.Bot.Send(m.Chat, b.String())
 		return
 	}
 
-	tag := normalizeTag(args[1])
+	tag := normalizeTag(args.from(0))
 	total, fresh, err := t.NotesSvc.DigestStatus(tag)
 	if err != nil {
-		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf("Error: %v", err))
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Error: %v"), err))
 		return
 	}
 	if total > 0 && fresh == 0 {
-		msg := fmt.Sprintf("Дайджест «%s» актуален: новых материалов нет (%d в составе).", tag, total)
+		msg := fmt.Sprintf(t.tr("Дайджест «%s» актуален: новых материалов нет (%d в составе)."), tag, total)
 		if url := t.NotesSvc.ExistingDigestURL(slugifyTopic(tag)); url != "" {
 			msg += "\n📓 " + url
 		}
@@ -2154,10 +2941,10 @@ func (t *TelegramBot) handleDigest(m *tb.Message) {
 		return
 	}
 
-	statusText := fmt.Sprintf("⏳ В очереди: дайджест «%s» (%d новых из %d)...", tag, fresh, total)
+	statusText := fmt.Sprintf(t.tr("⏳ В очереди: дайджест «%s» (%d новых из %d)..."), tag, fresh, total)
 	if total == 0 {
 		// no exact tag anywhere: the worker will pick sources by meaning
-		statusText = fmt.Sprintf("⏳ В очереди: дайджест «%s» — точного тега нет, подберу источники по смыслу...", tag)
+		statusText = fmt.Sprintf(t.tr("⏳ В очереди: дайджест «%s» — точного тега нет, подберу источники по смыслу..."), tag)
 	}
 	statusMsg, _ := t.Bot.Send(m.Chat, statusText)
 	rec := ytstore.NotesJobRecord{
@@ -2169,7 +2956,7 @@ func (t *TelegramBot) handleDigest(m *tb.Message) {
 		StatusMsgID: statusMsg.ID,
 	}
 	if err := t.NotesSvc.Enqueue(rec); err != nil {
-		_, _ = t.Bot.Edit(statusMsg, "⚠️ "+err.Error())
+		t.edit(statusMsg, "⚠️ "+err.Error())
 	}
 }
 
@@ -2178,44 +2965,92 @@ func (t *TelegramBot) handleStatus(m *tb.Message) {
 	if !t.isAuthorized(m.Sender) {
 		return
 	}
+	var b strings.Builder
 	if t.NotesSvc == nil {
-		_, _ = t.Bot.Send(m.Chat, "Конспекты выключены")
-		return
+		b.WriteString(t.tr("Конспекты выключены\n"))
+	} else {
+		queued, processing, recent, err := t.NotesSvc.QueueStatus()
+		if err != nil {
+			_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Error: %v"), err))
+			return
+		}
+		fmt.Fprintf(&b, t.tr("📋 Очередь конспектов\n⏳ в очереди: %d\n⚙️ в работе: %d\n"), queued, processing)
+		if line := t.r2UsageLine(); line != "" {
+			b.WriteString(line + "\n")
+		}
+		if line := t.llmRateLine(); line != "" {
+			b.WriteString(line + "\n")
+		}
+		t.writeRecentJobs(&b, recent)
 	}
-	queued, processing, recent, err := t.NotesSvc.QueueStatus()
-	if err != nil {
-		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf("Error: %v", err))
-		return
+	if len(t.Tools) > 0 {
+		b.WriteString(t.tr("\n🔧 Утилиты:\n"))
+		for _, st := range t.Tools {
+			b.WriteString(toolLine(st) + "\n")
+		}
 	}
+	t.writeLoad(&b, tools.Load())
+	_, _ = t.Bot.Send(m.Chat, strings.TrimSuffix(b.String(), "\n"))
+}
 
-	var b strings.Builder
-	fmt.Fprintf(&b, "📋 Очередь конспектов\n⏳ в очереди: %d\n⚙️ в работе: %d\n", queued, processing)
-	if line := t.r2UsageLine(); line != "" {
-		b.WriteString(line + "\n")
-	}
-	if line := llmRateLine(); line != "" {
-		b.WriteString(line + "\n")
-	}
-	if len(recent) > 0 {
-		b.WriteString("\nПоследние задачи:\n")
-		icons := map[string]string{
-			ytstore.NotesJobQueued:     "⏳",
-			ytstore.NotesJobProcessing: "⚙️",
-			ytstore.NotesJobDone:       "✅",
-			ytstore.NotesJobFailed:     "❌",
-		}
-		for _, j := range recent {
-			fmt.Fprintf(&b, "%s /%s %s\n", icons[j.Status], j.Level, notesLabel(j.URL))
-			if j.Error != "" {
-				errText := j.Error
-				if runes := []rune(errText); len(runes) > 80 {
-					errText = string(runes[:80]) + "…"
-				}
-				fmt.Fprintf(&b, "   └ %s\n", errText)
+// writeLoad renders the busy or limited resource classes of /status:
+// "download 2/2, в очереди 3"
+func (t *TelegramBot) writeLoad(b *strings.Builder, load []tools.Usage) {
+	header := false
+	for _, u := range load {
+		if u.Limit == 0 && u.Running == 0 {
+			continue
+		}
+		if !header {
+			b.WriteString(t.tr("\n🚦 Нагрузка:\n"))
+			header = true
+		}
+		limit := "∞"
+		if u.Limit > 0 {
+			limit = strconv.Itoa(u.Limit)
+		}
+		fmt.Fprintf(b, "%s %d/%s", u.Class, u.Running, limit)
+		if u.Waiting > 0 {
+			fmt.Fprintf(b, t.tr(", в очереди %d"), u.Waiting)
+		}
+		b.WriteString("\n")
+	}
+}
+
+// writeRecentJobs renders the latest notes jobs of /status
+func (t *TelegramBot) writeRecentJobs(b *strings.Builder, recent []ytstore.NotesJobRecord) {
+	if len(recent) == 0 {
+		return
+	}
+	b.WriteString(t.tr("\nПоследние задачи:\n"))
+	icons := map[string]string{
+		ytstore.NotesJobQueued:     "⏳",
+		ytstore.NotesJobProcessing: "⚙️",
+		ytstore.NotesJobDone:       "✅",
+		ytstore.NotesJobFailed:     "❌",
+	}
+	for _, j := range recent {
+		fmt.Fprintf(b, "%s /%s %s\n", icons[j.Status], j.Level, notesLabel(j.URL))
+		if j.Error != "" {
+			errText := j.Error
+			if runes := []rune(errText); len(runes) > 80 {
+				errText = string(runes[:80]) + "…"
 			}
+			fmt.Fprintf(b, "   └ %s\n", errText)
 		}
 	}
-	_, _ = t.Bot.Send(m.Chat, b.String())
+}
+
+// toolLine renders a tool check of /status: "✅ ffmpeg 7.1" or "❌ vot-cli: not found"
+func toolLine(st tools.Status) string {
+	if st.Err != nil {
+		return fmt.Sprintf("❌ %s: %v", st.Name, st.Err)
+	}
+	line := fmt.Sprintf("✅ %s %s", st.Name, st.Version)
+	if st.Configured {
+		line += " (" + st.Command + ")"
+	}
+	return line
 }
 
 // sendNoteDocument sends the L1 markdown file to the chat as a document with a
@@ -2225,7 +3060,7 @@ func (t *TelegramBot) sendNoteDocument(chat *tb.Chat, res NotesResult) {
 	doc := &tb.Document{
 		File:     tb.FromDisk(res.MDPath),
 		FileName: sanitizeFileName(res.Title) + ".md",
-		Caption:  noteCaption(res),
+		Caption:  t.noteCaption(res),
 	}
 	if _, err := t.Bot.Send(chat, doc); err != nil {
 		log.Printf("[WARN] failed to send note document %s: %v", res.MDPath, err)
@@ -2233,17 +3068,17 @@ func (t *TelegramBot) sendNoteDocument(chat *tb.Chat, res NotesResult) {
 }
 
 // noteCaption builds the stats line under the document: duration, words, tags
-func noteCaption(res NotesResult) string {
+func (t *TelegramBot) noteCaption(res NotesResult) string {
 	var parts []string
 	if res.Meta.DurationMin > 0 {
 		if h := res.Meta.DurationMin / 60; h > 0 {
-			parts = append(parts, fmt.Sprintf("⏱ %dч %02dм", h, res.Meta.DurationMin%60))
+			parts = append(parts, fmt.Sprintf(t.tr("⏱ %dч %02dм"), h, res.Meta.DurationMin%60))
 		} else {
-			parts = append(parts, fmt.Sprintf("⏱ %dм", res.Meta.DurationMin))
+			parts = append(parts, fmt.Sprintf(t.tr("⏱ %dм"), res.Meta.DurationMin))
 		}
 	}
 	if res.WordCount > 0 {
-		parts = append(parts, fmt.Sprintf("%d слов", res.WordCount))
+		parts = append(parts, fmt.Sprintf(t.tr("%d слов"), res.WordCount))
 	}
 	if len(res.Meta.Tags) > 0 {
 		parts = append(parts, "🏷 "+strings.Join(res.Meta.Tags, ", "))
@@ -2293,6 +3128,7 @@ func (t *TelegramBot) createPodcastEntry(ep *ApplePodcastEpisode, rawURL, file s
 		ChannelID: t.FeedName,
 		VideoID:   ep.SourceID(),
 		Title:     ep.Title,
+		Kind:      ytfeed.KindPodcast,
 		Link: struct {
 			Href string `xml:"href,attr"`
 		}{Href: rawURL},
@@ -2347,7 +3183,7 @@ func (t *TelegramBot) addPodcastEpisode(ctx context.Context, ep *ApplePodcastEpi
 
 	duration = t.DurationSvc.File(file)
 	entry := t.createPodcastEntry(ep, linkURL, file, duration)
-	if _, err := t.Store.Save(entry); err != nil {
+	if _, err := t.saveEntry(ctx, entry); err != nil {
 		return 0, false, fmt.Errorf("failed to save entry: %w", err)
 	}
 	if err := t.Store.SetProcessed(entry); err != nil {
@@ -2371,18 +3207,18 @@ func (t *TelegramBot) processPodcastAudio(ctx context.Context, chat *tb.Chat, st
 		return err
 	}
 
-	_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("⬇️ Скачиваю: %s...", ep.Title))
+	t.edit(statusMsg, fmt.Sprintf(t.tr("⬇️ Скачиваю: %s..."), ep.Title))
 	duration, skipped, err := t.addPodcastEpisode(ctx, ep, rawURL)
 	if err != nil {
 		return err
 	}
 	if skipped {
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("⚠️ %s (already in feed)", ep.Title))
+		t.edit(statusMsg, fmt.Sprintf(t.tr("⚠️ %s (already in feed)"), ep.Title))
 		return nil
 	}
 	t.removeOldEntries()
 
-	_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("✅ %s (%s)", ep.Title, t.formatDuration(time.Duration(duration)*time.Second)))
+	t.edit(statusMsg, fmt.Sprintf("✅ %s (%s)", ep.Title, t.formatDuration(time.Duration(duration)*time.Second)))
 	t.deleteMessageAfterDelay(originalMsg, 5*time.Second)
 	_ = chat
 	return nil
@@ -2394,7 +3230,7 @@ func (t *TelegramBot) processPodcastAudio(ctx context.Context, chat *tb.Chat, st
 func (t *TelegramBot) processPodcastShowBatch(ctx context.Context, chat *tb.Chat, statusMsg, originalMsg *tb.Message, rawURL string) {
 	show, eps, err := t.Apple.ResolveShow(ctx, rawURL)
 	if err != nil {
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("❌ Error: %v", err))
+		t.edit(statusMsg, fmt.Sprintf(t.tr("❌ Error: %v"), err))
 		return
 	}
 	if len(eps) > maxShowEpisodes {
@@ -2403,7 +3239,7 @@ func (t *TelegramBot) processPodcastShowBatch(ctx context.Context, chat *tb.Chat
 
 	added, skipped, failed := 0, 0, 0
 	for i, ep := range eps {
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("⬇️ %d/%d: %s...", i+1, len(eps), ep.Title))
+		t.edit(statusMsg, fmt.Sprintf("⬇️ %d/%d: %s...", i+1, len(eps), ep.Title))
 		duration, skip, aerr := t.addPodcastEpisode(ctx, ep, ep.EpisodeLink())
 		switch {
 		case aerr != nil:
@@ -2413,20 +3249,20 @@ func (t *TelegramBot) processPodcastShowBatch(ctx context.Context, chat *tb.Chat
 			skipped++
 		default:
 			added++
-			_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("✅ %d/%d: %s (%s)", i+1, len(eps), ep.Title,
+			t.edit(statusMsg, fmt.Sprintf("✅ %d/%d: %s (%s)", i+1, len(eps), ep.Title,
 				t.formatDuration(time.Duration(duration)*time.Second)))
 		}
 	}
 	t.removeOldEntries()
 
-	summary := fmt.Sprintf("✅ «%s»: добавлено %d/%d", show, added, len(eps))
+	summary := fmt.Sprintf(t.tr("✅ «%s»: добавлено %d/%d"), show, added, len(eps))
 	if skipped > 0 {
-		summary += fmt.Sprintf(" (%d уже в ленте)", skipped)
+		summary += fmt.Sprintf(t.tr(" (%d уже в ленте)"), skipped)
 	}
 	if failed > 0 {
-		summary += fmt.Sprintf(" (%d с ошибками)", failed)
+		summary += fmt.Sprintf(t.tr(" (%d с ошибками)"), failed)
 	}
-	_, _ = t.Bot.Edit(statusMsg, summary)
+	t.edit(statusMsg, summary)
 	t.deleteMessageAfterDelay(originalMsg, 5*time.Second)
 	_ = chat
 }
@@ -2445,7 +3281,7 @@ func (t *TelegramBot) translatePodcastEpisode(ctx context.Context, statusMsg *tb
 	var voFile string
 	titleEmoji = "🎙"
 	if IsVotCliAvailable() {
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("🎙 Пробую Яндекс-перевод: %s...", ep.Title))
+		t.edit(statusMsg, fmt.Sprintf(t.tr("🎙 Пробую Яндекс-перевод: %s..."), ep.Title))
 		if res, votErr := t.VoiceoverSvc.TranslateURL(ctx, ep.AudioURL, ep.SourceID()); votErr == nil {
 			voFile = res.FilePath
 		} else {
@@ -2461,11 +3297,15 @@ func (t *TelegramBot) translatePodcastEpisode(ctx context.Context, statusMsg *tb
 		titleEmoji = "📝"
 	}
 
-	duration = t.DurationSvc.File(voFile)
+	duration = t.ttsDuration(voFile, 0)
 	entry := t.createPodcastEntry(ep, linkURL, voFile, duration)
 	entry.VideoID = voID
-	entry.Title = titleEmoji + " " + ep.Title
-	if _, err := t.Store.Save(entry); err != nil {
+	entry.Title = ep.Title
+	entry.Kind = ytfeed.KindVoiceover
+	if titleEmoji == "📝" {
+		entry.Kind = ytfeed.KindSubtitleTTS
+	}
+	if _, err := t.saveEntry(ctx, entry); err != nil {
 		return 0, "", false, fmt.Errorf("failed to save entry: %w", err)
 	}
 	if err := t.Store.SetProcessed(entry); err != nil {
@@ -2494,12 +3334,12 @@ func (t *TelegramBot) processPodcastVoiceover(ctx context.Context, chat *tb.Chat
 		return err
 	}
 	if skipped {
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("⚠️ 🎙 %s (already in feed)", ep.Title))
+		t.edit(statusMsg, fmt.Sprintf(t.tr("⚠️ 🎙 %s (already in feed)"), ep.Title))
 		return nil
 	}
 	t.removeOldEntries()
 
-	_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("✅ %s %s (%s)", titleEmoji, ep.Title, t.formatDuration(time.Duration(duration)*time.Second)))
+	t.edit(statusMsg, fmt.Sprintf("✅ %s %s (%s)", titleEmoji, ep.Title, t.formatDuration(time.Duration(duration)*time.Second)))
 	t.deleteMessageAfterDelay(originalMsg, 5*time.Second)
 	_ = chat
 	return nil
@@ -2512,7 +3352,7 @@ func (t *TelegramBot) processPodcastVoiceover(ctx context.Context, chat *tb.Chat
 func (t *TelegramBot) processPodcastShowVoiceoverBatch(ctx context.Context, chat *tb.Chat, statusMsg, originalMsg *tb.Message, rawURL string) {
 	show, eps, err := t.Apple.ResolveShow(ctx, rawURL)
 	if err != nil {
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("❌ Error: %v", err))
+		t.edit(statusMsg, fmt.Sprintf(t.tr("❌ Error: %v"), err))
 		return
 	}
 	if len(eps) > maxShowEpisodes {
@@ -2521,7 +3361,7 @@ func (t *TelegramBot) processPodcastShowVoiceoverBatch(ctx context.Context, chat
 
 	added, skipped, failed := 0, 0, 0
 	for i, ep := range eps {
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("🎙 %d/%d: %s...", i+1, len(eps), ep.Title))
+		t.edit(statusMsg, fmt.Sprintf("🎙 %d/%d: %s...", i+1, len(eps), ep.Title))
 		duration, titleEmoji, skip, terr := t.translatePodcastEpisode(ctx, statusMsg, ep, ep.EpisodeLink())
 		switch {
 		case terr != nil:
@@ -2531,20 +3371,20 @@ func (t *TelegramBot) processPodcastShowVoiceoverBatch(ctx context.Context, chat
 			skipped++
 		default:
 			added++
-			_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("✅ %d/%d: %s %s (%s)", i+1, len(eps), titleEmoji, ep.Title,
+			t.edit(statusMsg, fmt.Sprintf("✅ %d/%d: %s %s (%s)", i+1, len(eps), titleEmoji, ep.Title,
 				t.formatDuration(time.Duration(duration)*time.Second)))
 		}
 	}
 	t.removeOldEntries()
 
-	summary := fmt.Sprintf("✅ «%s»: переведено %d/%d", show, added, len(eps))
+	summary := fmt.Sprintf(t.tr("✅ «%s»: переведено %d/%d"), show, added, len(eps))
 	if skipped > 0 {
-		summary += fmt.Sprintf(" (%d уже в ленте)", skipped)
+		summary += fmt.Sprintf(t.tr(" (%d уже в ленте)"), skipped)
 	}
 	if failed > 0 {
-		summary += fmt.Sprintf(" (%d с ошибками)", failed)
+		summary += fmt.Sprintf(t.tr(" (%d с ошибками)"), failed)
 	}
-	_, _ = t.Bot.Edit(statusMsg, summary)
+	t.edit(statusMsg, summary)
 	t.deleteMessageAfterDelay(originalMsg, 5*time.Second)
 	_ = chat
 }
@@ -2559,7 +3399,7 @@ func (t *TelegramBot) podcastVoiceoverViaWhisper(ctx context.Context, statusMsg
 
 	text := ""
 	if otr, plain := t.Apple.OfficialTranscript(ctx, ep); otr != nil || plain != "" {
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("📜 Официальный транскрипт: %s...", ep.Title))
+		t.edit(statusMsg, fmt.Sprintf(t.tr("📜 Официальный транскрипт: %s..."), ep.Title))
 		if otr != nil {
 			text = joinTranscriptText(otr)
 		} else {
@@ -2571,7 +3411,7 @@ func (t *TelegramBot) podcastVoiceoverViaWhisper(ctx context.Context, statusMsg
 		if t.NotesSvc == nil || t.NotesSvc.Transcriber == nil {
 			return "", fmt.Errorf("перевод недоступен: нужен GROQ_API_KEY (notes.enabled)")
 		}
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("⬇️ Скачиваю аудио: %s...", ep.Title))
+		t.edit(statusMsg, fmt.Sprintf(t.tr("⬇️ Скачиваю аудио: %s..."), ep.Title))
 		tempAudio := filepath.Join(os.TempDir(), "vo_src_"+ep.SourceID()+".mp3")
 		if err := t.Apple.DownloadEnclosure(ctx, ep.AudioURL, tempAudio); err != nil {
 			return "", err
@@ -2583,7 +3423,7 @@ func (t *TelegramBot) podcastVoiceoverViaWhisper(ctx context.Context, statusMsg
 		}()
 
 		tr, err := t.NotesSvc.Transcriber.Transcribe(ctx, tempAudio, func(done, total int) {
-			_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("🎧 Транскрибирую %d/%d: %s...", done, total, ep.Title))
+			t.edit(statusMsg, fmt.Sprintf(t.tr("🎧 Транскрибирую %d/%d: %s..."), done, total, ep.Title))
 		})
 		if err != nil {
 			return "", fmt.Errorf("failed to transcribe: %w", err)
@@ -2600,7 +3440,7 @@ func (t *TelegramBot) podcastVoiceoverViaWhisper(ctx context.Context, statusMsg
 	}
 
 	if t.Translator != nil && t.Translator.NeedsTranslation(text) {
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("🌐 Перевожу: %s...", ep.Title))
+		t.edit(statusMsg, fmt.Sprintf(t.tr("🌐 Перевожу: %s..."), ep.Title))
 		translated, trErr := t.Translator.Translate(ctx, text)
 		if trErr != nil {
 			return "", fmt.Errorf("failed to translate: %w", trErr)
@@ -2608,20 +3448,19 @@ func (t *TelegramBot) podcastVoiceoverViaWhisper(ctx context.Context, statusMsg
 		text = translated
 	}
 
-	_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("🗣 Озвучиваю: %s...", ep.Title))
-	edgeTTS, ok := t.TTS.(*EdgeTTS)
-	if !ok {
-		return "", fmt.Errorf("TTS provider is not EdgeTTS")
-	}
-	audioData, err := edgeTTS.SynthesizeLongText(ctx, text, 3000)
+	t.edit(statusMsg, fmt.Sprintf(t.tr("🗣 Озвучиваю: %s..."), ep.Title))
+	audioData, err := synthesizeLongText(ctx, t.TTS, text, 3000)
 	if err != nil {
 		return "", fmt.Errorf("failed to synthesize: %w", err)
 	}
 
 	voFile := filepath.Join(t.FilesLocation, fmt.Sprintf("vo_%s_%d.mp3", ep.SourceID(), time.Now().Unix()))
-	if err := os.WriteFile(voFile, audioData, 0o640); err != nil { //nolint:gosec
+	if err := writeAtomic(voFile, audioData, 0o640); err != nil {
 		return "", fmt.Errorf("failed to write voiceover file: %w", err)
 	}
+	if err := t.finalizeAudio(ctx, voFile); err != nil {
+		return "", err
+	}
 	return voFile, nil
 }
 
@@ -2649,79 +3488,73 @@ func (t *TelegramBot) appendNotionLinkToEntry(videoID, notionURL string) {
 }
 
 // processVoiceover downloads voice-over translated audio for a YouTube video
-func (t *TelegramBot) processVoiceover(ctx context.Context, chat *tb.Chat, statusMsg, originalMsg *tb.Message, videoURL, videoID string) error {
+func (t *TelegramBot) processVoiceover(ctx context.Context, chat *tb.Chat, statusMsg, originalMsg *tb.Message, videoURL, videoID string,
+	preset config.Preset) error {
 	// 1. Generate unique ID for this voiceover
 	voiceoverID := fmt.Sprintf("vo_%s", videoID)
 
 	// 2. Check if already processed
 	tempEntry := ytfeed.Entry{ChannelID: t.FeedName, VideoID: voiceoverID}
 	if found, _, _ := t.Store.CheckProcessed(tempEntry); found {
-		_, _ = t.Bot.Edit(statusMsg, "⚠️ Уже есть в ленте")
+		t.edit(statusMsg, t.tr("⚠️ Уже есть в ленте"))
 		t.deleteMessageAfterDelay(originalMsg, 5*time.Second)
 		return nil
 	}
 
 	// 3. Fetch video info first (for title and thumbnail)
-	_, _ = t.Bot.Edit(statusMsg, "⏳ Получаю информацию о видео...")
-	info, err := t.Downloader.GetInfo(ctx, videoURL)
+	t.edit(statusMsg, t.tr("⏳ Получаю информацию о видео..."))
+	info, err := t.videoInfo(ctx, videoURL)
 	if err != nil {
 		return fmt.Errorf("failed to get video info: %w", err)
 	}
 
-	// 4. Choose voiceover method (priority: YouTube Dubbed → vot-cli → subtitles)
-	var filePath string
-	var duration int
-	var method string
-
-	// 4a. Try YouTube Dubbed track first
-	_, _ = t.Bot.Edit(statusMsg, "🔍 Ищу русскую дорожку на YouTube...")
-	tracks, trackErr := t.VoiceoverSvc.GetDubbedAudioTracks(ctx, videoURL)
-	dubbedTrack := t.VoiceoverSvc.FindDubbedTrack(tracks)
-
-	if trackErr == nil && dubbedTrack != nil {
-		// Found dubbed track - download it
-		log.Printf("[INFO] found YouTube dubbed track (lang=%s) for %s", dubbedTrack.Language, videoID)
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("🎬 Скачиваю дубляж YouTube: %s...", info.Title))
-
-		result, err := t.VoiceoverSvc.DownloadDubbedTrack(ctx, videoURL, dubbedTrack)
-		if err != nil {
-			log.Printf("[WARN] failed to download dubbed track, falling back: %v", err)
-		} else {
-			filePath = result.FilePath
-			method = "youtube-dubbed"
-			log.Printf("[INFO] downloaded YouTube dubbed track: %s", filePath)
-		}
+	// 3.5. Songs have nothing to translate, suggest the original audio. Not for
+	// other sites: the audio menu downloads YouTube ids only.
+	if !isWebVideoID(videoID) && IsMusicContent(info) {
+		log.Printf("[INFO] %s looks like music content, voiceover skipped", videoID)
+		return &musicContentError{videoID: videoID, title: info.Title}
 	}
 
-	// 4b. Fallback to vot-cli or subtitles if no dubbed track
-	if filePath == "" {
-		maxDuration := 4 * 60 * 60 // 4 hours in seconds
-
-		if int(info.Duration) > maxDuration {
-			// Subtitles fallback for long videos
-			log.Printf("[INFO] video > 4 hours, using subtitle fallback for %s", videoID)
-			_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("📝 Видео > 4ч, скачиваю субтитры: %s...", info.Title))
-
-			fp, dur, err := t.processVoiceoverViaSubtitles(ctx, statusMsg, videoURL, videoID, info)
-			if err != nil {
-				return err
-			}
-			filePath = fp
-			duration = dur
-			method = "subtitles-tts"
-		} else {
-			// vot-cli for videos under 4 hours
-			_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("🎙 Скачиваю озвучку (vot-cli): %s...", info.Title))
-			result, err := t.VoiceoverSvc.TranslateVideo(ctx, videoURL)
-			if err != nil {
-				return fmt.Errorf("failed to get voiceover: %w", err)
+	// 4. Voiceover by the configured methods in order (default YouTube Dubbed → vot-cli → subtitles),
+	// the next one when a method fails. What each has to work with is checked at once up front.
+	methods := t.voMethods(time.Duration(info.Duration * float64(time.Second)))
+	if len(preset.Methods) > 0 { // asked for, tried whatever the duration
+		methods = preset.Methods
+	}
+	if preset.DubbedOnly {
+		methods = []string{voMethodDubbed}
+	}
+	t.edit(statusMsg, fmt.Sprintf(t.tr("🔍 Ищу, как озвучить: %s..."), info.Title))
+	probes := t.probeVoMethods(ctx, videoURL, info, methods, preset.Lang)
+	defer probes.close(func(p voProbe) { t.SubtitleSvc.Cleanup(p.subFile) })
+	var res voResult
+	var method string
+	var lastErr error
+	for _, m := range methods {
+		probe := probes.wait(m)
+		if probe.err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
 			}
-
-			log.Printf("[INFO] voiceover downloaded via vot-cli: %s (size: %d bytes)", result.FilePath, result.FileSize)
-			filePath = result.FilePath
-			method = "vot-cli"
+			log.Printf("[INFO] no voiceover of %s via %s: %v", videoID, m, probe.err)
+			lastErr = probe.err
+			continue
+		}
+		r, err := t.runVoMethod(ctx, m, probe, statusMsg, videoURL, videoID, info, preset)
+		if err == nil {
+			res, method = r, m
+			break
+		}
+		if ctx.Err() != nil {
+			return err
 		}
+		log.Printf("[WARN] voiceover of %s via %s failed: %v", videoID, m, err)
+		lastErr = err
+	}
+	if method == "" {
+		return t.voFailure(methods, info, lastErr)
 	}
+	filePath, duration, sources, processing := res.file, res.duration, res.sources, res.processing
 
 	// Get duration from file if not already set
 	if duration == 0 && t.DurationSvc != nil {
@@ -2734,23 +3567,28 @@ func (t *TelegramBot) processVoiceover(ctx context.Context, chat *tb.Chat, statu
 
 	// 7. Create entry using video info
 	thumbnail := info.Thumbnail
-	if thumbnail == "" {
+	if thumbnail == "" && !isWebVideoID(videoID) {
 		thumbnail = fmt.Sprintf("https://i.ytimg.com/vi/%s/hqdefault.jpg", videoID)
 	}
+	source := "YouTube видео"
+	if isWebVideoID(videoID) {
+		source = "видео"
+	}
 
-	// Choose emoji based on method
-	titleEmoji := "🎙" // default for vot-cli
+	// Choose emoji and kind based on method
+	titleEmoji, kind := "🎙", ytfeed.KindVoiceover // default for vot-cli
 	switch method {
-	case "youtube-dubbed":
+	case voMethodDubbed:
 		titleEmoji = "🎬" // official dub
-	case "subtitles-tts":
-		titleEmoji = "📝" // subtitles
+	case voMethodSubtitles:
+		titleEmoji, kind = "📝", ytfeed.KindSubtitleTTS // subtitles
 	}
 
 	entry := ytfeed.Entry{
 		ChannelID: t.FeedName,
 		VideoID:   voiceoverID,
-		Title:     titleEmoji + " " + info.Title,
+		Title:     info.Title,
+		Kind:      kind,
 		Link: struct {
 			Href string `xml:"href,attr"`
 		}{Href: videoURL},
@@ -2762,7 +3600,7 @@ func (t *TelegramBot) processVoiceover(ctx context.Context, chat *tb.Chat, statu
 				URL string `xml:"url,attr"`
 			} `xml:"thumbnail"`
 		}{
-			Description: template.HTML(fmt.Sprintf("Озвучка YouTube видео (%s): %s\n%s", method, info.Title, info.Description)),
+			Description: template.HTML(fmt.Sprintf("Озвучка %s (%s): %s\n%s", source, method, info.Title, info.Description)),
 			Thumbnail: struct {
 				URL string `xml:"url,attr"`
 			}{URL: thumbnail},
@@ -2774,20 +3612,24 @@ func (t *TelegramBot) processVoiceover(ctx context.Context, chat *tb.Chat, statu
 			Name: info.Uploader,
 			URI:  info.ChannelURL,
 		},
-		File:     filePath,
-		Duration: duration,
+		File:       filePath,
+		Duration:   duration,
+		Sources:    sources,
+		Processing: processing,
 	}
 
 	// 8. Store in BoltDB
-	created, err := t.Store.Save(entry)
+	created, err := t.saveEntry(ctx, entry)
 	if err != nil {
 		return fmt.Errorf("failed to save: %w", err)
 	}
 	if !created {
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("⚠️ Already exists: %s", info.Title))
+		removeVoiceoverSources(entry)
+		t.edit(statusMsg, fmt.Sprintf(t.tr("⚠️ Already exists: %s"), info.Title))
 		t.deleteMessageAfterDelay(originalMsg, 5*time.Second)
 		return nil
 	}
+	t.trimVoiceoverSources()
 
 	// 9. Mark as processed
 	if err := t.Store.SetProcessed(entry); err != nil {
@@ -2808,7 +3650,7 @@ func (t *TelegramBot) processVoiceover(ctx context.Context, chat *tb.Chat, statu
 	// 11. Remove old entries if exceeding MaxItems
 	t.removeOldEntries()
 
-	_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("✅ %s %s (%s)", titleEmoji, info.Title, t.formatDuration(dur)))
+	t.edit(statusMsg, fmt.Sprintf("✅ %s %s (%s)", titleEmoji, info.Title, t.formatDuration(dur)))
 
 	log.Printf("[INFO] added voiceover %s via %s: %s (duration: %s)", voiceoverID, method, info.Title, dur.String())
 
@@ -2819,24 +3661,40 @@ func (t *TelegramBot) processVoiceover(ctx context.Context, chat *tb.Chat, statu
 
 // processVoiceoverViaSubtitles handles long videos (>4h) by downloading subtitles,
 // translating them, and converting to speech via Edge TTS
-func (t *TelegramBot) processVoiceoverViaSubtitles(ctx context.Context, statusMsg *tb.Message, videoURL, videoID string, info *ytfeed.VideoInfo) (string, int, error) {
+func (t *TelegramBot) processVoiceoverViaSubtitles(ctx context.Context, statusMsg *tb.Message, videoURL, videoID string,
+	info *ytfeed.VideoInfo, tts TTSProvider) (string, int, ytfeed.Processing, error) {
 	// 1. Download subtitles
-	_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("📝 Скачиваю субтитры: %s...", info.Title))
+	t.edit(statusMsg, fmt.Sprintf(t.tr("📝 Скачиваю субтитры: %s..."), info.Title))
 	subFile, lang, err := t.SubtitleSvc.DownloadSubtitles(ctx, videoURL)
 	if err != nil {
-		return "", 0, fmt.Errorf("не удалось скачать субтитры: %w", err)
+		return "", 0, ytfeed.Processing{}, fmt.Errorf("не удалось скачать субтитры: %w", err)
 	}
 	defer t.SubtitleSvc.Cleanup(subFile)
+	return t.voiceSubtitles(ctx, statusMsg, videoURL, videoID, subFile, lang, info, tts)
+}
+
+// voiceSubtitles translates the downloaded subtitles of subFile in lang and
+// converts them to speech with tts
+func (t *TelegramBot) voiceSubtitles(ctx context.Context, statusMsg *tb.Message, videoURL, videoID, subFile, lang string,
+	info *ytfeed.VideoInfo, tts TTSProvider) (string, int, ytfeed.Processing, error) {
+	if tts == nil {
+		tts = NewEdgeTTS("ru-RU-DmitryNeural")
+	}
+
+	// voiced subtitles in place with the original audio where nothing is said, if on
+	if file, dur, p, ok := t.tryHybridDub(ctx, statusMsg, videoURL, videoID, subFile, lang, info, tts); ok {
+		return file, dur, p, nil
+	}
 
 	// 2. Parse subtitles to text
-	_, _ = t.Bot.Edit(statusMsg, "📄 Извлекаю текст из субтитров...")
+	t.edit(statusMsg, t.tr("📄 Извлекаю текст из субтитров..."))
 	text, err := t.SubtitleSvc.ParseSubtitles(subFile)
 	if err != nil {
-		return "", 0, fmt.Errorf("не удалось распарсить субтитры: %w", err)
+		return "", 0, ytfeed.Processing{}, fmt.Errorf("не удалось распарсить субтитры: %w", err)
 	}
 
 	if text == "" {
-		return "", 0, fmt.Errorf("субтитры пустые")
+		return "", 0, ytfeed.Processing{}, fmt.Errorf("субтитры пустые")
 	}
 
 	const maxSubtitleLen = 150000 // ~2.5 hours of audio
@@ -2849,56 +3707,62 @@ func (t *TelegramBot) processVoiceoverViaSubtitles(ctx context.Context, statusMs
 	charCount := len([]rune(text))
 	log.Printf("[INFO] extracted %d characters from subtitles (lang: %s)", charCount, lang)
 
-	// 3. Translate if not Russian
-	if lang != "ru" && t.Translator != nil && t.Translator.NeedsTranslation(text) {
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("🌐 Перевожу с %s на русский (%d символов)...", lang, charCount))
-		translated, err := t.Translator.Translate(ctx, text)
-		if err != nil {
-			return "", 0, fmt.Errorf("не удалось перевести: %w", err)
+	// 3. Translate if not Russian, unless a failed run of the same subtitles got that far already
+	partial := loadPartialVoice(t.FilesLocation, videoID, settingsHash(text, ttsName(tts), t.translatorName(t.Translator)))
+	var translator TranslationProvider
+	if partial.Text != "" {
+		log.Printf("[INFO] resuming subtitle voiceover of %s at chunk %d/%d", videoID, partial.Done+1, partial.Chunks)
+		text = partial.Text
+		if partial.Translated {
+			translator = t.Translator
+		}
+	} else {
+		if lang != "ru" && t.Translator != nil && t.Translator.NeedsTranslation(text) {
+			translator = t.Translator
+			t.edit(statusMsg, fmt.Sprintf(t.tr("🌐 Перевожу с %s на русский (%d символов)..."), lang, charCount))
+			translated, err := t.Translator.Translate(ctx, text)
+			if err != nil {
+				return "", 0, ytfeed.Processing{}, fmt.Errorf("не удалось перевести: %w", err)
+			}
+			text = translated
+		}
+		if err := partial.start(text, translator != nil); err != nil {
+			log.Printf("[WARN] subtitle voiceover of %s won't resume on a retry: %v", videoID, err)
 		}
-		text = translated
-		charCount = len([]rune(text))
-	}
-
-	// 4. Convert to speech via Edge TTS
-	_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("🔊 Озвучиваю (%d символов, это займёт время)...", charCount))
-
-	// Need TTS provider
-	if t.TTS == nil {
-		// Initialize TTS if not available
-		t.TTS = NewEdgeTTS("ru-RU-DmitryNeural")
 	}
+	charCount = len([]rune(text))
 
-	edgeTTS, ok := t.TTS.(*EdgeTTS)
-	if !ok {
-		return "", 0, fmt.Errorf("TTS провайдер недоступен")
+	// 4. Convert to speech via Edge TTS, chunk by chunk into the partial audio
+	t.edit(statusMsg, fmt.Sprintf(t.tr("🔊 Озвучиваю (%d символов, это займёт время)..."), charCount))
+	if partial.Done > 0 {
+		t.edit(statusMsg, fmt.Sprintf(t.tr("🔊 Продолжаю озвучку с фрагмента %d/%d..."), partial.Done+1, partial.Chunks))
 	}
-
-	audioData, err := edgeTTS.SynthesizeLongText(ctx, text, 3000)
+	err = partial.synthesize(ctx, tts, 3000, func(done, total int) {
+		t.edit(statusMsg, fmt.Sprintf(t.tr("🔊 Озвучиваю: %d/%d фрагментов..."), done, total))
+	})
 	if err != nil {
-		return "", 0, fmt.Errorf("не удалось озвучить: %w", err)
+		if partial.Done > 0 {
+			return "", 0, ytfeed.Processing{}, fmt.Errorf("не удалось озвучить (готово %d/%d фрагментов, повтор продолжит с места сбоя): %w",
+				partial.Done, partial.Chunks, err)
+		}
+		return "", 0, ytfeed.Processing{}, fmt.Errorf("не удалось озвучить: %w", err)
 	}
 
 	// 5. Save audio file
 	filePath := fmt.Sprintf("%s/vo_%s_%d.mp3", t.FilesLocation, videoID, time.Now().Unix())
-	if err := os.WriteFile(filePath, audioData, 0644); err != nil {
-		return "", 0, fmt.Errorf("не удалось сохранить файл: %w", err)
+	if err := os.Rename(partial.audioFile(), filePath); err != nil {
+		return "", 0, ytfeed.Processing{}, fmt.Errorf("не удалось сохранить файл: %w", err)
+	}
+	partial.remove()
+	if err := t.finalizeAudio(ctx, filePath); err != nil {
+		return "", 0, ytfeed.Processing{}, err
 	}
 
 	// 6. Get duration
-	duration := 0
-	if t.DurationSvc != nil {
-		if fileDur := t.DurationSvc.File(filePath); fileDur > 0 {
-			duration = fileDur
-		}
-	}
-	if duration == 0 {
-		// Estimate: ~900 chars/min for Russian TTS
-		duration = int(float64(charCount) / 900.0 * 60.0)
-	}
+	duration := t.ttsDuration(filePath, charCount)
 
 	log.Printf("[INFO] subtitle voiceover created: %s (chars: %d, duration: %ds)", filePath, charCount, duration)
-	return filePath, duration, nil
+	return filePath, duration, t.processing("subtitles-tts", tts, translator), nil
 }
 
 // splitTelegramMessage splits a message into chunks that fit within Telegram's message size limit.
@@ -0,0 +1,340 @@
+package proc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	"github.com/umputun/feed-master/app/feed"
+	procmocks "github.com/umputun/feed-master/app/proc/mocks"
+	"github.com/umputun/feed-master/app/tools"
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	"github.com/umputun/feed-master/app/youtube/feed/mocks"
+)
+
+const testBotUserID = 42
+
+// tgCall is a single Bot API request recorded by tgStub
+type tgCall struct {
+	Method string
+	Params map[string]any
+}
+
+// tgStub is a fake Telegram Bot API server. It answers send*/edit* with
+// a message and everything else with true, recording every call.
+type tgStub struct {
+	*httptest.Server
+	mu    sync.Mutex
+	calls []tgCall
+	msgID int
+}
+
+func newTgStub(t *testing.T) *tgStub {
+	t.Helper()
+	s := &tgStub{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		params := map[string]any{}
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &params)
+		}
+
+		s.mu.Lock()
+		s.calls = append(s.calls, tgCall{Method: method, Params: params})
+		s.msgID++
+		id := s.msgID
+		s.mu.Unlock()
+
+		if strings.HasPrefix(method, "send") || strings.HasPrefix(method, "edit") {
+			resp := map[string]any{"ok": true, "result": map[string]any{
+				"message_id": id, "text": params["text"], "chat": map[string]any{"id": testBotUserID, "type": "private"},
+			}}
+			if method == "sendDocument" { // telebot reads the stored file back
+				resp["result"].(map[string]any)["document"] = map[string]any{"file_id": "doc"}
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// texts returns "text" params of the recorded calls of the given method
+func (s *tgStub) texts(method string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res []string
+	for _, c := range s.calls {
+		if c.Method != method {
+			continue
+		}
+		if txt, ok := c.Params["text"].(string); ok {
+			res = append(res, txt)
+		}
+	}
+	return res
+}
+
+// count returns the number of calls of the method, multipart uploads included
+func (s *tgStub) count(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := 0
+	for _, c := range s.calls {
+		if c.Method == method {
+			res++
+		}
+	}
+	return res
+}
+
+// newTestBot makes a TelegramBot talking to the stub, with offline TTS and
+// translator and files in a temp dir. Tests override services as needed.
+func newTestBot(t *testing.T, stub *tgStub) *TelegramBot {
+	t.Helper()
+	bot, err := tb.NewBot(tb.Settings{URL: stub.URL, Token: "test", Offline: true})
+	require.NoError(t, err)
+	dir := t.TempDir()
+	return &TelegramBot{
+		Bot:            bot,
+		AllowedUserID:  testBotUserID,
+		FeedName:       "test",
+		FilesLocation:  dir,
+		TTSEnabled:     true,
+		TTS:            &FakeTTS{},
+		Translator:     &FakeTranslator{},
+		VoiceoverSvc:   NewVoiceoverService(dir, "ru", ""),
+		SubtitleSvc:    NewSubtitleService(dir, ""),
+		pendingActions: make(map[string]*pendingAction),
+		runCtx:         context.Background(),
+	}
+}
+
+// testMessage makes an incoming private message from the given user
+func testMessage(userID int64, text string) *tb.Message {
+	user := &tb.User{ID: userID}
+	return &tb.Message{ID: 1, Text: text, Sender: user, Chat: &tb.Chat{ID: userID, Type: tb.ChatPrivate}}
+}
+
+func TestTelegramBot_HandleStatusTools(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Tools = []tools.Status{
+		{Name: "yt-dlp", Version: "2025.09.26", Command: "yt-dlp"},
+		{Name: "ffmpeg", Version: "7.1", Command: "/opt/ffmpeg", Configured: true},
+		{Name: "vot-cli", Command: "vot-cli", Err: errors.New(`exec: "vot-cli": executable file not found in $PATH`)},
+	}
+
+	bot.handleStatus(testMessage(testBotUserID, "/status"))
+	sent := stub.texts("sendMessage")
+	require.Len(t, sent, 1)
+	assert.Contains(t, sent[0], "Конспекты выключены")
+	assert.Contains(t, sent[0], "✅ yt-dlp 2025.09.26\n")
+	assert.Contains(t, sent[0], "✅ ffmpeg 7.1 (/opt/ffmpeg)")
+	assert.Contains(t, sent[0], "❌ vot-cli: exec")
+}
+
+func TestWriteLoad(t *testing.T) {
+	bot := &TelegramBot{}
+	var b strings.Builder
+	bot.writeLoad(&b, []tools.Usage{{Class: tools.ClassDownload}, {Class: tools.ClassTranscode}})
+	assert.Empty(t, b.String(), "nothing limited or running")
+
+	bot.writeLoad(&b, []tools.Usage{
+		{Class: tools.ClassDownload, Running: 2, Waiting: 3, Limit: 2},
+		{Class: tools.ClassTranscode, Running: 1},
+		{Class: tools.ClassTTS, Limit: 4},
+	})
+	assert.Equal(t, "\n🚦 Нагрузка:\ndownload 2/2, в очереди 3\ntranscode 1/∞\ntts 0/4\n", b.String())
+}
+
+func TestTelegramBot_HandleHelp(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+
+	bot.handleHelp(testMessage(testBotUserID, "/help"))
+	sent := stub.texts("sendMessage")
+	require.Len(t, sent, 1)
+	assert.Contains(t, sent[0], "/vo <url>")
+
+	bot.handleHelp(testMessage(1, "/help"))
+	sent = stub.texts("sendMessage")
+	require.Len(t, sent, 2)
+	assert.Equal(t, "Unauthorized. This bot is private.", sent[1])
+}
+
+func TestTelegramBot_HandleVoiceoverUsage(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+
+	bot.handleVoiceover(testMessage(testBotUserID, "/vo"))
+	bot.handleVoiceover(testMessage(testBotUserID, "/vo example.com/page"))
+	bot.handleVoiceover(testMessage(testBotUserID, "/vo https://youtu.be/abc --speed=2"))
+	bot.handleVoiceover(testMessage(testBotUserID, "/vo https://youtu.be/abc --lang=english!"))
+	bot.handleVoiceover(testMessage(testBotUserID, "/vo https://youtu.be/abc --method=whisper"))
+	sent := stub.texts("sendMessage")
+	require.Len(t, sent, 5)
+	assert.True(t, strings.HasPrefix(sent[0], "Usage: /vo"))
+	assert.Equal(t, "❌ Invalid video URL", sent[1])
+	assert.True(t, strings.HasPrefix(sent[2], "Usage: /vo"), "unknown flag")
+	assert.True(t, strings.HasPrefix(sent[3], "Usage: /vo"), "bad language")
+	assert.True(t, strings.HasPrefix(sent[4], `❌ unknown voiceover method "whisper"`))
+}
+
+func TestTelegramBot_ProcessVoiceoverViaSubtitles(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.TTS = &FakeTTS{FramePerChars: 1} // ~26ms per char, long enough for a non-zero duration
+	bot.SubtitleSvc.Runner = &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			vtt := "WEBVTT\n\n00:00:00.000 --> 00:00:02.000\nHello there.\n\n00:00:02.000 --> 00:00:04.000\nGeneral Kenobi.\n"
+			return nil, nil, os.WriteFile(outputArg(args)+".en.vtt", []byte(vtt), 0o600)
+		},
+	}
+	statusMsg, err := bot.Bot.Send(&tb.Chat{ID: testBotUserID}, "⏳")
+	require.NoError(t, err)
+
+	file, dur, p, err := bot.processVoiceoverViaSubtitles(context.Background(), statusMsg,
+		"https://www.youtube.com/watch?v=abc123", "abc123", &ytfeed.VideoInfo{ID: "abc123", Title: "Long talk"}, bot.TTS)
+	require.NoError(t, err)
+	assert.Positive(t, dur)
+	assert.Equal(t, ytfeed.Processing{Method: "subtitles-tts", Voice: "*proc.FakeTTS", Translator: "*proc.FakeTranslator",
+		Settings: p.Settings}, p)
+	assert.Len(t, p.Settings, 8)
+
+	audio, err := os.ReadFile(file) //nolint:gosec // test temp file
+	require.NoError(t, err)
+	assert.NotEmpty(t, audio)
+	assert.Equal(t, silentMP3Frame[:4], audio[:4])
+
+	assert.Equal(t, []string{"Hello there. General Kenobi."}, bot.Translator.(*FakeTranslator).Texts())
+	assert.Equal(t, []string{"[ru] Hello there. General Kenobi."}, bot.TTS.(*FakeTTS).Texts())
+
+	edits := strings.Join(stub.texts("editMessageText"), "\n")
+	assert.Contains(t, edits, "Скачиваю субтитры: Long talk")
+	assert.Contains(t, edits, "Перевожу с en на русский")
+}
+
+func TestTelegramBot_ProcessVoiceoverViaSubtitlesTTSFailure(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.TTS = &FakeTTS{Err: errors.New("tts is down")}
+	bot.SubtitleSvc.Runner = &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			vtt := "WEBVTT\n\n00:00:00.000 --> 00:00:02.000\nПривет.\n"
+			return nil, nil, os.WriteFile(outputArg(args)+".ru.vtt", []byte(vtt), 0o600)
+		},
+	}
+
+	_, _, _, err := bot.processVoiceoverViaSubtitles(context.Background(), &tb.Message{ID: 1, Chat: &tb.Chat{ID: testBotUserID}},
+		"https://www.youtube.com/watch?v=abc123", "abc123", &ytfeed.VideoInfo{ID: "abc123", Title: "t"}, bot.TTS)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tts is down")
+	assert.Empty(t, bot.Translator.(*FakeTranslator).Texts(), "russian subtitles are not translated")
+}
+
+func TestTelegramBot_SaveEntryAnnouncesFeed(t *testing.T) {
+	topics := make(chan string, 2)
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		topics <- r.FormValue("hub.url")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer hub.Close()
+
+	bot := newTestBot(t, newTgStub(t))
+	bot.Store = newTestJobStore(t)
+	bot.BaseURL = "http://example.com/"
+	bot.WebSub = &feed.WebSub{Hubs: []string{hub.URL}}
+
+	entry := ytfeed.Entry{ChannelID: bot.FeedName, VideoID: "v1", Published: time.Now()}
+	created, err := bot.saveEntry(context.Background(), entry)
+	require.NoError(t, err)
+	require.True(t, created)
+	select {
+	case topic := <-topics:
+		assert.Equal(t, "http://example.com/yt/rss/"+bot.FeedName, topic)
+	case <-time.After(5 * time.Second):
+		t.Fatal("hub not pinged")
+	}
+
+	created, err = bot.saveEntry(context.Background(), entry)
+	require.NoError(t, err)
+	require.False(t, created)
+	select {
+	case topic := <-topics:
+		t.Fatalf("hub pinged for a duplicate: %s", topic)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestFakeTTS(t *testing.T) {
+	f := &FakeTTS{FramePerChars: 5}
+	audio, err := f.Synthesize(context.Background(), "0123456789")
+	require.NoError(t, err)
+	assert.Len(t, audio, 2*len(silentMP3Frame))
+
+	audio, err = synthesizeLongText(context.Background(), f, "Раз. Два. Три.", 6)
+	require.NoError(t, err)
+	assert.NotEmpty(t, audio)
+	assert.Equal(t, []string{"0123456789", "Раз.", "Два.", "Три."}, f.Texts(), "sentences over the size split at words")
+}
+
+func TestChunkParagraphs(t *testing.T) {
+	text := "First paragraph.\n\nSecond one, a bit longer.\n\nThird. Has two sentences.\n\n" +
+		"A single sentence far over the limit of a chunk"
+	assert.Equal(t, []string{text}, chunkParagraphs(text, 1000))
+	assert.Equal(t, []string{
+		"First paragraph.\n\nSecond one, a bit longer.",
+		"Third. Has two sentences.\n\nA single sentence",
+		"far over the limit of a chunk",
+	}, chunkParagraphs(text, 45), "the long sentence split at words")
+	assert.Equal(t, []string{"Third.", " Has two sentences."}, chunkParagraphs("Third. Has two sentences.", 20))
+}
+
+func TestWithPauses(t *testing.T) {
+	assert.Equal(t, "Heading.\n\nText ends: \n\n«Quoted.»\n\nItem one\nitem two.\n\nDone!  ",
+		withPauses("Heading\n\nText ends: \n\n«Quoted.»\n\nItem one\nitem two\n\nDone!  "))
+	assert.Equal(t, "", withPauses(""))
+}
+
+func TestFakeTranslator(t *testing.T) {
+	f := &FakeTranslator{}
+	assert.True(t, f.NeedsTranslation("hello world"))
+	assert.False(t, f.NeedsTranslation("привет мир"))
+	res, err := f.Translate(context.Background(), "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "[ru] hello", res)
+}
+
+func TestTelegramBot_TTSDuration(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	path := filepath.Join(bot.FilesLocation, "vo.mp3")
+	audio, err := (&FakeTTS{FramePerChars: 1}).Synthesize(context.Background(), strings.Repeat("a", 2000))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, audio, 0o600))
+
+	assert.Equal(t, 52, bot.ttsDuration(path, 100), "2000 frames * 1152 / 44100, no DurationSvc")
+
+	bot.DurationSvc = &procmocks.DurationServiceMock{FileFunc: func(string) int { return 77 }}
+	assert.Equal(t, 77, bot.ttsDuration(path, 100))
+
+	bot.DurationSvc = &procmocks.DurationServiceMock{FileFunc: func(string) int { return 0 }}
+	assert.Equal(t, 52, bot.ttsDuration(path, 100), "frame counting when DurationSvc fails")
+	assert.Equal(t, 60, bot.ttsDuration(filepath.Join(bot.FilesLocation, "nope.mp3"), 900), "estimate as last resort")
+}
@@ -0,0 +1,207 @@
+package proc
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// trashRetention is how long deleted entries and their files can be restored by /undo
+const trashRetention = 24 * time.Hour
+
+const delUsage = "Usage: /del [N | from-to | tag:name]\nExample: /del 1 (delete most recent), /del 3-7, /del tag:article"
+
+// selectEntries picks the entries to delete by a /del argument: a number or a
+// from-to range of /list positions, or tag:name matching the kind, channel or
+// site of the entries. The problem is a message for the user, "" on success.
+func selectEntries(entries []ytfeed.Entry, sel string) (res []ytfeed.Entry, problem string) {
+	if tag, ok := strings.CutPrefix(sel, "tag:"); ok {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			return nil, delUsage
+		}
+		for _, e := range entries {
+			for _, et := range entryTags(e) {
+				if et == tag {
+					res = append(res, e)
+					break
+				}
+			}
+		}
+		if len(res) == 0 {
+			return nil, fmt.Sprintf("No entries tagged %q in feed.", tag)
+		}
+		return res, ""
+	}
+
+	fromStr, toStr, isRange := strings.Cut(sel, "-")
+	from, err := strconv.Atoi(strings.TrimSpace(fromStr))
+	if err != nil || from < 1 {
+		return nil, delUsage
+	}
+	to := from
+	if isRange {
+		if to, err = strconv.Atoi(strings.TrimSpace(toStr)); err != nil || to < from {
+			return nil, delUsage
+		}
+	}
+	if to > len(entries) {
+		return nil, fmt.Sprintf("Only %d entries in feed.", len(entries))
+	}
+	return entries[from-1 : to], ""
+}
+
+// entryTags are the lowercase names an entry is matched by in /del tag:name:
+// its kind, channel, site and processing, e.g. "article", "veritasium",
+// "habr.com", "vot-cli" or "settings:1a2b3c4d"
+func entryTags(e ytfeed.Entry) []string {
+	res := []string{string(e.EntryKind())}
+	res = append(res, e.Processing.Categories()...)
+	if e.Author.Name != "" {
+		res = append(res, strings.ToLower(e.Author.Name))
+	}
+	if u, err := url.Parse(e.Link.Href); err == nil && u.Hostname() != "" {
+		res = append(res, strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."))
+	}
+	return res
+}
+
+// deletedSummary lists the deleted entries for the reply to /del
+func deletedSummary(entries []ytfeed.Entry) string {
+	if len(entries) == 1 {
+		return fmt.Sprintf("🗑 Deleted: %s (/undo to restore)\n\n", entries[0].Title)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "🗑 Deleted %d (/undo to restore):\n", len(entries))
+	for _, e := range entries {
+		fmt.Fprintf(&b, "• %s\n", e.Title)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// deleteEntries moves the entries to the trash in one transaction. Their
+// files stay for trashRetention, so /undo can bring the last deletion back;
+// purgeTrash removes them after that.
+func (t *TelegramBot) deleteEntries(entries []ytfeed.Entry) error {
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.VideoID)
+	}
+	if _, err := t.Store.Trash(t.FeedName, ids, time.Now()); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		_ = t.Store.ResetProcessed(e) // can be added again while in the trash
+	}
+	return nil
+}
+
+// purgeTrash deletes the files of the entries trashed over trashRetention ago
+func (t *TelegramBot) purgeTrash(now time.Time) {
+	purged, err := t.Store.PurgeTrash(t.FeedName, now.Add(-trashRetention))
+	if err != nil {
+		log.Printf("[WARN] failed to purge trash: %v", err)
+		return
+	}
+	for _, e := range purged {
+		t.removeEntryFiles(e)
+		t.forgetEntry(e)
+	}
+}
+
+// handleUndo restores the entries of the last /del or /delall (/undo)
+func (t *TelegramBot) handleUndo(m *tb.Message) {
+	if !t.isAuthorized(m.Sender) {
+		return
+	}
+	restored, err := t.Store.RestoreTrash(t.FeedName)
+	if err != nil {
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Error: %v"), err))
+		return
+	}
+	if len(restored) == 0 {
+		_, _ = t.Bot.Send(m.Chat, t.tr("Nothing to undo."))
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, t.tr("↩️ Restored %d:\n"), len(restored))
+	for _, e := range restored {
+		fmt.Fprintf(&b, "• %s\n", e.Title)
+		_ = t.Store.SetProcessed(e)
+	}
+	_, _ = t.Bot.Send(m.Chat, b.String())
+}
+
+// handleDeleteAll asks to confirm removing every entry of the feed (/delall)
+func (t *TelegramBot) handleDeleteAll(m *tb.Message) {
+	if !t.isAuthorized(m.Sender) {
+		return
+	}
+	entries, err := t.Store.Load(t.FeedName, 0)
+	if err != nil {
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Error: %v"), err))
+		return
+	}
+	if len(entries) == 0 {
+		_, _ = t.Bot.Send(m.Chat, t.tr("Feed is empty."))
+		return
+	}
+	rev, err := t.Store.Revision(t.FeedName)
+	if err != nil {
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Error: %v"), err))
+		return
+	}
+
+	// the revision in the button makes a confirmation stale once the feed changes
+	markup := &tb.ReplyMarkup{}
+	btnYes := markup.Data(fmt.Sprintf(t.tr("🗑 Удалить все (%d)"), len(entries)), "delall", strconv.FormatUint(rev.Rev, 10))
+	btnNo := markup.Data(t.tr("🚫 Отмена"), "delall", "cancel")
+	markup.InlineKeyboard = [][]tb.InlineButton{{*btnYes.Inline(), *btnNo.Inline()}}
+	_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Удалить все эпизоды ленты (%d)? Файлы хранятся сутки, /undo вернёт их."), len(entries)), markup)
+}
+
+// handleDeleteAllCallback removes all entries once /delall is confirmed.
+// Callback data is the feed revision the question was asked at, or "cancel".
+func (t *TelegramBot) handleDeleteAllCallback(c *tb.Callback) {
+	if c == nil || c.Message == nil || !t.isAuthorized(c.Sender) {
+		return
+	}
+	if c.Data == "cancel" {
+		t.edit(c.Message, t.tr("Отменено, лента не тронута."))
+		_ = t.Bot.Respond(c)
+		return
+	}
+
+	rev, err := t.Store.Revision(t.FeedName)
+	if err != nil {
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Error loading feed")})
+		return
+	}
+	if strconv.FormatUint(rev.Rev, 10) != c.Data {
+		t.edit(c.Message, t.tr("Лента изменилась с момента вопроса, повтори /delall."))
+		_ = t.Bot.Respond(c)
+		return
+	}
+
+	entries, err := t.Store.Load(t.FeedName, 0)
+	if err != nil {
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Error loading entries")})
+		return
+	}
+	if err := t.deleteEntries(entries); err != nil {
+		t.edit(c.Message, fmt.Sprintf(t.tr("❌ Error removing: %v"), err))
+		_ = t.Bot.Respond(c)
+		return
+	}
+	log.Printf("[INFO] deleted all %d entries of %s", len(entries), t.FeedName)
+	t.edit(c.Message, fmt.Sprintf(t.tr("🗑 Удалено эпизодов: %d. Лента пуста, /undo вернёт их в течение суток."), len(entries)))
+	_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Deleted")})
+}
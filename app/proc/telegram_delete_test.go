@@ -0,0 +1,179 @@
+package proc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+func TestSelectEntries(t *testing.T) {
+	entries := make([]ytfeed.Entry, 5)
+	for i := range entries {
+		entries[i].VideoID = fmt.Sprintf("v%d", i+1)
+	}
+	entries[1].VideoID, entries[1].Link.Href = "art_2", "https://www.habr.com/post/1"
+	entries[3].Author.Name = "Veritasium"
+
+	ids := func(res []ytfeed.Entry) (out []string) {
+		for _, e := range res {
+			out = append(out, e.VideoID)
+		}
+		return out
+	}
+
+	tbl := []struct {
+		sel     string
+		ids     []string
+		problem string
+	}{
+		{sel: "1", ids: []string{"v1"}},
+		{sel: "3-5", ids: []string{"v3", "v4", "v5"}},
+		{sel: "2 - 3", ids: []string{"art_2", "v3"}},
+		{sel: "tag:article", ids: []string{"art_2"}},
+		{sel: "tag:habr.com", ids: []string{"art_2"}},
+		{sel: "tag:veritasium", ids: []string{"v4"}},
+		{sel: "tag:video", ids: []string{"v1", "v3", "v4", "v5"}},
+		{sel: "tag:news", problem: `No entries tagged "news" in feed.`},
+		{sel: "tag:", problem: delUsage},
+		{sel: "0", problem: delUsage},
+		{sel: "5-3", problem: delUsage},
+		{sel: "abc", problem: delUsage},
+		{sel: "4-9", problem: "Only 5 entries in feed."},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.sel, func(t *testing.T) {
+			res, problem := selectEntries(entries, tt.sel)
+			assert.Equal(t, tt.problem, problem)
+			assert.Equal(t, tt.ids, ids(res))
+		})
+	}
+}
+
+// saveDeleteFixture saves n entries of the bot feed with files, newest first in /list
+func saveDeleteFixture(t *testing.T, bot *TelegramBot, n int) []string {
+	t.Helper()
+	var files []string
+	for i := 1; i <= n; i++ {
+		file := filepath.Join(bot.FilesLocation, fmt.Sprintf("v%d.mp3", i))
+		require.NoError(t, os.WriteFile(file, []byte("audio"), 0o600))
+		files = append(files, file)
+		_, err := bot.Store.Save(ytfeed.Entry{ChannelID: bot.FeedName, VideoID: fmt.Sprintf("v%d", i),
+			Title: fmt.Sprintf("title %d", i), File: file, Published: time.Date(2026, 1, 1, i, 0, 0, 0, time.UTC)})
+		require.NoError(t, err)
+	}
+	return files
+}
+
+func TestTelegramBot_HandleDeleteRange(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Store = newTestJobStore(t)
+	files := saveDeleteFixture(t, bot, 5)
+
+	bot.handleDelete(testMessage(testBotUserID, "/del 2-4")) // v4, v3, v2 in /list order
+	sent := stub.texts("sendMessage")
+	require.Len(t, sent, 1)
+	assert.Contains(t, sent[0], "🗑 Deleted 3 (/undo to restore):\n• title 4\n• title 3\n• title 2\n")
+	assert.Contains(t, sent[0], "Remaining (2):\n1. title 5")
+
+	entries, err := bot.Store.Load(bot.FeedName, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "v5", entries[0].VideoID)
+	assert.Equal(t, "v1", entries[1].VideoID)
+	for _, f := range files {
+		assert.FileExists(t, f, "files kept in the trash")
+	}
+
+	bot.handleDelete(testMessage(testBotUserID, "/del 3-4"))
+	sent = stub.texts("sendMessage")
+	require.Len(t, sent, 2)
+	assert.Equal(t, "Only 2 entries in feed.", sent[1])
+
+	bot.purgeTrash(time.Now())
+	assert.FileExists(t, files[1], "trashed less than a day ago")
+	bot.purgeTrash(time.Now().Add(trashRetention + time.Minute))
+	for i, f := range files {
+		_, err := os.Stat(f)
+		assert.Equal(t, i >= 1 && i <= 3, os.IsNotExist(err), f)
+	}
+	bot.handleUndo(testMessage(testBotUserID, "/undo"))
+	assert.Equal(t, "Nothing to undo.", stub.texts("sendMessage")[2], "purged entries are gone")
+}
+
+func TestTelegramBot_HandleUndo(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Store = newTestJobStore(t)
+	saveDeleteFixture(t, bot, 3)
+
+	bot.handleDelete(testMessage(testBotUserID, "/del 1"))
+	bot.handleDelete(testMessage(testBotUserID, "/del tag:video"))
+	entries, err := bot.Store.Load(bot.FeedName, 0)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	bot.handleUndo(testMessage(testBotUserID, "/undo"))
+	sent := stub.texts("sendMessage")
+	require.Len(t, sent, 3)
+	assert.Equal(t, "↩️ Restored 2:\n• title 1\n• title 2\n", sent[2], "the last deletion only")
+	entries, err = bot.Store.Load(bot.FeedName, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "v2", entries[0].VideoID)
+	found, _, err := bot.Store.CheckProcessed(entries[0])
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	bot.handleUndo(testMessage(testBotUserID, "/undo"))
+	entries, err = bot.Store.Load(bot.FeedName, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "v3", entries[0].VideoID)
+}
+
+func TestTelegramBot_HandleDeleteAll(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Store = newTestJobStore(t)
+	files := saveDeleteFixture(t, bot, 3)
+
+	bot.handleDeleteAll(testMessage(testBotUserID, "/delall"))
+	sent := stub.texts("sendMessage")
+	require.Len(t, sent, 1)
+	assert.Contains(t, sent[0], "(3)")
+	entries, err := bot.Store.Load(bot.FeedName, 0)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3, "nothing deleted before the confirmation")
+
+	rev, err := bot.Store.Revision(bot.FeedName)
+	require.NoError(t, err)
+	callback := func(data string) *tb.Callback {
+		return &tb.Callback{Sender: &tb.User{ID: testBotUserID}, Data: data,
+			Message: &tb.Message{ID: 7, Chat: &tb.Chat{ID: testBotUserID}}}
+	}
+
+	bot.handleDeleteAllCallback(callback(strconv.FormatUint(rev.Rev-1, 10)))
+	assert.Contains(t, stub.texts("editMessageText"), "Лента изменилась с момента вопроса, повтори /delall.")
+	entries, err = bot.Store.Load(bot.FeedName, 0)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3, "stale confirmation ignored")
+
+	bot.handleDeleteAllCallback(callback(strconv.FormatUint(rev.Rev, 10)))
+	entries, err = bot.Store.Load(bot.FeedName, 0)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	for _, f := range files {
+		assert.FileExists(t, f)
+	}
+	assert.Contains(t, stub.texts("editMessageText"), "🗑 Удалено эпизодов: 3. Лента пуста, /undo вернёт их в течение суток.")
+}
@@ -0,0 +1,112 @@
+package proc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	ytstore "github.com/umputun/feed-master/app/youtube/store"
+)
+
+const glossaryUsage = "Usage: /glossary add <термин> [= <перевод>] | /glossary list | /glossary del <термин или N>"
+
+// handleGlossary handles /glossary: "add Kubernetes" keeps the term untranslated,
+// "add pull request = пулл-реквест" sets its translation, "del" removes a term
+// by name or number in the list, "list" (or nothing) lists them
+func (t *TelegramBot) handleGlossary(m *tb.Message) {
+	if !t.isAuthorized(m.Sender) {
+		return
+	}
+	if t.Store == nil {
+		_, _ = t.Bot.Send(m.Chat, t.tr("❌ Глоссарий не настроен (нужно хранилище)."))
+		return
+	}
+	cmd, arg, _ := strings.Cut(strings.TrimSpace(strings.TrimPrefix(m.Text, "/glossary")), " ")
+	arg = strings.TrimSpace(arg)
+	switch strings.ToLower(cmd) {
+	case "", "list":
+		t.sendGlossary(m.Chat)
+	case "add":
+		term, translation, _ := strings.Cut(arg, "=")
+		gt := ytstore.GlossaryTerm{Term: strings.TrimSpace(term), Translation: strings.TrimSpace(translation),
+			CreatedAt: time.Now().UTC()}
+		if gt.Term == "" {
+			_, _ = t.Bot.Send(m.Chat, t.tr(glossaryUsage))
+			return
+		}
+		if err := t.Store.SaveGlossaryTerm(gt); err != nil {
+			_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("❌ Error: %v"), err))
+			return
+		}
+		log.Printf("[INFO] glossary term %q added", gt.Term)
+		_, _ = t.Bot.Send(m.Chat, t.tr("📖 В глоссарии: ")+t.glossaryLine(gt))
+	case "del", "rm":
+		t.deleteGlossaryTerm(m.Chat, arg)
+	default:
+		_, _ = t.Bot.Send(m.Chat, t.tr(glossaryUsage))
+	}
+}
+
+// deleteGlossaryTerm removes a term given by name or by its number in the list
+func (t *TelegramBot) deleteGlossaryTerm(chat *tb.Chat, arg string) {
+	if arg == "" {
+		_, _ = t.Bot.Send(chat, t.tr(glossaryUsage))
+		return
+	}
+	term := arg
+	if n, err := strconv.Atoi(arg); err == nil {
+		terms, lerr := t.Store.LoadGlossary()
+		if lerr != nil {
+			_, _ = t.Bot.Send(chat, fmt.Sprintf(t.tr("❌ Error: %v"), lerr))
+			return
+		}
+		if n < 1 || n > len(terms) {
+			_, _ = t.Bot.Send(chat, fmt.Sprintf(t.tr("❌ Нет термина %d, всего %d"), n, len(terms)))
+			return
+		}
+		term = terms[n-1].Term
+	}
+	found, err := t.Store.DeleteGlossaryTerm(term)
+	if err != nil {
+		_, _ = t.Bot.Send(chat, fmt.Sprintf(t.tr("❌ Error: %v"), err))
+		return
+	}
+	if !found {
+		_, _ = t.Bot.Send(chat, fmt.Sprintf(t.tr("❌ Нет термина %q в глоссарии"), term))
+		return
+	}
+	log.Printf("[INFO] glossary term %q removed", term)
+	_, _ = t.Bot.Send(chat, t.tr("🗑 Удалено из глоссария: ")+term)
+}
+
+// sendGlossary lists the glossary terms, numbered for /glossary del N
+func (t *TelegramBot) sendGlossary(chat *tb.Chat) {
+	terms, err := t.Store.LoadGlossary()
+	if err != nil {
+		_, _ = t.Bot.Send(chat, fmt.Sprintf(t.tr("❌ Error: %v"), err))
+		return
+	}
+	if len(terms) == 0 {
+		_, _ = t.Bot.Send(chat, t.tr("Глоссарий пуст. /glossary add <термин> [= <перевод>] — как переводить термин."))
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, t.tr("📖 Глоссарий (%d):\n\n"), len(terms))
+	for i, term := range terms {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, t.glossaryLine(term))
+	}
+	b.WriteString(t.tr("\nУдалить: /glossary del N"))
+	_, _ = t.Bot.Send(chat, b.String())
+}
+
+// glossaryLine renders a term as "term → translation", kept terms are marked
+func (t *TelegramBot) glossaryLine(term ytstore.GlossaryTerm) string {
+	if term.Translation == "" {
+		return term.Term + t.tr(" (без перевода)")
+	}
+	return term.Term + " → " + term.Translation
+}
@@ -0,0 +1,48 @@
+package proc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelegramBot_Glossary(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Store = newTestJobStore(t)
+	last := func() string {
+		sent := stub.texts("sendMessage")
+		require.NotEmpty(t, sent)
+		return sent[len(sent)-1]
+	}
+
+	bot.handleGlossary(testMessage(testBotUserID, "/glossary"))
+	assert.Contains(t, last(), "Глоссарий пуст")
+
+	bot.handleGlossary(testMessage(testBotUserID, "/glossary add Kubernetes"))
+	assert.Equal(t, "📖 В глоссарии: Kubernetes (без перевода)", last())
+	bot.handleGlossary(testMessage(testBotUserID, "/glossary add pull request = пулл-реквест"))
+	assert.Equal(t, "📖 В глоссарии: pull request → пулл-реквест", last())
+	bot.handleGlossary(testMessage(testBotUserID, "/glossary add = x"))
+	assert.Contains(t, last(), "Usage")
+
+	bot.handleGlossary(testMessage(testBotUserID, "/glossary list"))
+	assert.Equal(t, "📖 Глоссарий (2):\n\n1. Kubernetes (без перевода)\n2. pull request → пулл-реквест\n\nУдалить: /glossary del N", last())
+
+	bot.handleGlossary(testMessage(testBotUserID, "/glossary del 3"))
+	assert.Contains(t, last(), "Нет термина 3, всего 2")
+	bot.handleGlossary(testMessage(testBotUserID, "/glossary del Pull Request"))
+	assert.Equal(t, "🗑 Удалено из глоссария: Pull Request", last())
+	bot.handleGlossary(testMessage(testBotUserID, "/glossary del 1"))
+	assert.Equal(t, "🗑 Удалено из глоссария: Kubernetes", last())
+	bot.handleGlossary(testMessage(testBotUserID, "/glossary del Kubernetes"))
+	assert.Contains(t, last(), "Нет термина")
+
+	terms, err := bot.Store.LoadGlossary()
+	require.NoError(t, err)
+	assert.Empty(t, terms)
+
+	bot.handleGlossary(testMessage(testBotUserID, "/glossary rename x"))
+	assert.Contains(t, last(), "Usage")
+}
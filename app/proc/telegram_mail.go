@@ -0,0 +1,72 @@
+package proc
+
+import (
+	"context"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"net/url"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+)
+
+// HandleMail voices a mail from the gateway into the feed. It returns once
+// the job is queued, the SMTP client isn't kept waiting for the TTS.
+func (t *TelegramBot) HandleMail(_ context.Context, msg *MailMessage) error {
+	article, err := mailArticle(msg)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMailRejected, err)
+	}
+	chat := &tb.Chat{ID: t.AllowedUserID}
+	statusMsg, err := t.Bot.Send(chat, fmt.Sprintf("✉️ %s: %s", article.SiteName, article.Title))
+	if err != nil {
+		return fmt.Errorf("can't send status: %w", err)
+	}
+	req := articleRequest{URL: article.URL, Preset: t.MailPreset, Article: article, Background: true}
+	t.goJob(articleJobTimeout, func(ctx context.Context) {
+		err := t.processArticle(ctx, chat, statusMsg, nil, req)
+		switch {
+		case errors.Is(err, errInFeedAlready):
+			log.Printf("[INFO] mail %q from %s not voiced: %v", msg.Subject, msg.From, err)
+		case err != nil:
+			log.Printf("[ERROR] failed to process mail %q from %s: %v", msg.Subject, msg.From, err)
+			t.edit(statusMsg, t.userErrorText(err))
+		}
+	})
+	return nil
+}
+
+// mailArticle makes an article of a mail: readability over the HTML body,
+// the plain text one otherwise. The subject is the title, the Message-ID
+// makes the article URL (mid: scheme, RFC 2392), so a mail delivered twice
+// gets the same article ID.
+func mailArticle(msg *MailMessage) (*Article, error) {
+	id := msg.MessageID
+	if id == "" {
+		id = fmt.Sprintf("%x@turnip", sha1.Sum([]byte(msg.From+"\n"+msg.Subject+"\n"+msg.Text+msg.HTML)))
+	}
+	mid := &url.URL{Scheme: "mid", Opaque: url.PathEscape(id)}
+
+	var article *Article
+	if msg.HTML != "" {
+		if a, err := FromHTML([]byte(msg.HTML), mid); err == nil {
+			article = a
+		}
+	}
+	if article == nil {
+		text := cleanText(msg.Text)
+		if text == "" {
+			return nil, errors.New("no readable text")
+		}
+		article = &Article{TextContent: text, URL: mid.String()}
+	}
+	if msg.Subject != "" {
+		article.Title = msg.Subject
+	}
+	article.SiteName = msg.FromName
+	if article.SiteName == "" {
+		article.SiteName = msg.From
+	}
+	return article, nil
+}
@@ -0,0 +1,49 @@
+package proc
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMailArticle(t *testing.T) {
+	a, err := mailArticle(&MailMessage{MessageID: "x/1@host", From: "a@b.com", Subject: "Выпуск", Text: "Просто текст письма."})
+	require.NoError(t, err)
+	assert.Equal(t, "mid:x%2F1@host", a.URL)
+	assert.Equal(t, "Выпуск", a.Title)
+	assert.Equal(t, "a@b.com", a.SiteName)
+	assert.Equal(t, "Просто текст письма.", a.TextContent)
+
+	b, err := mailArticle(&MailMessage{From: "a@b.com", FromName: "Рассылка", Text: "Просто текст письма."})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(b.URL, "mid:"), "id made up without Message-ID")
+	assert.Equal(t, "Рассылка", b.SiteName)
+
+	_, err = mailArticle(&MailMessage{From: "a@b.com", Text: "  "})
+	require.Error(t, err)
+}
+
+func TestTelegramBot_HandleMail(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Store = newTestJobStore(t)
+	bot.MaxItems = 10
+
+	msg, err := ParseMail(strings.NewReader(testNewsletter))
+	require.NoError(t, err)
+	require.NoError(t, bot.HandleMail(context.Background(), msg))
+	assert.Contains(t, stub.texts("sendMessage")[0], "✉️ Рассылка: Выпуск 1")
+
+	require.Eventually(t, func() bool {
+		entries, err := bot.Store.Load(bot.FeedName, 10)
+		return err == nil && len(entries) == 1
+	}, 5*time.Second, 20*time.Millisecond)
+	entries, err := bot.Store.Load(bot.FeedName, 10)
+	require.NoError(t, err)
+	assert.Equal(t, "Выпуск 1", entries[0].Title)
+	assert.Equal(t, "mid:abc123@letters.example.com", entries[0].Link.Href)
+}
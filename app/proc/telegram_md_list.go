@@ -63,7 +63,7 @@ func (t *TelegramBot) handleMDList(m *tb.Message) {
 		return
 	}
 	if len(items) == 0 {
-		_, _ = t.Bot.Send(m.Chat, "Пока нет ни одного транскрипта. Пришли ссылку и выбери 📄 MD-файл.")
+		_, _ = t.Bot.Send(m.Chat, t.tr("Пока нет ни одного транскрипта. Пришли ссылку и выбери 📄 MD-файл."))
 		return
 	}
 	msg, markup := t.buildMDListMessage(items, 0)
@@ -91,7 +91,7 @@ func (t *TelegramBot) buildMDListMessage(items []noteListItem, page int) (string
 	}
 
 	var b strings.Builder
-	fmt.Fprintf(&b, "📄 Транскрипты (%d) — стр %d/%d:\n\n", total, page+1, pages)
+	fmt.Fprintf(&b, t.tr("📄 Транскрипты (%d) — стр %d/%d:\n\n"), total, page+1, pages)
 	for i := start; i < end; i++ {
 		item := items[i]
 		fmt.Fprintf(&b, "%d. %s\n", i+1, item.Meta.Title)
@@ -100,7 +100,7 @@ func (t *TelegramBot) buildMDListMessage(items []noteListItem, page int) (string
 			chips = append(chips, item.Meta.Date)
 		}
 		if item.Meta.DurationMin > 0 {
-			chips = append(chips, fmt.Sprintf("%dм", item.Meta.DurationMin))
+			chips = append(chips, fmt.Sprintf(t.tr("%dм"), item.Meta.DurationMin))
 		}
 		if item.Meta.hasProcessed("notes") {
 			chips = append(chips, "📓")
@@ -112,7 +112,7 @@ func (t *TelegramBot) buildMDListMessage(items []noteListItem, page int) (string
 			fmt.Fprintf(&b, "    %s\n", strings.Join(chips, " · "))
 		}
 	}
-	b.WriteString("\n⬇️ скачать · 📓 в Notion · 🗑 удалить")
+	b.WriteString(t.tr("\n⬇️ скачать · 📓 в Notion · 🗑 удалить"))
 
 	markup := &tb.ReplyMarkup{}
 	if pages > 1 {
@@ -145,11 +145,11 @@ func (t *TelegramBot) handleMDListPageCallback(c *tb.Callback) {
 	_, _ = fmt.Sscanf(strings.TrimPrefix(c.Data, "p="), "%d", &page)
 	items, err := t.loadNotesList()
 	if err != nil {
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Ошибка"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Ошибка")})
 		return
 	}
 	msg, markup := t.buildMDListMessage(items, page)
-	_, _ = t.Bot.Edit(c.Message, msg, markup)
+	t.edit(c.Message, msg, markup)
 	_ = t.Bot.Respond(c)
 }
 
@@ -171,14 +171,14 @@ func (t *TelegramBot) handleMDListActionCallback(c *tb.Callback) {
 		}
 	}
 	if t.NotesSvc == nil || sourceID == "" || strings.Contains(sourceID, string(os.PathSeparator)) || strings.Contains(sourceID, "..") {
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Bad data"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Bad data")})
 		return
 	}
 
 	path := filepath.Join(t.NotesSvc.MDLocation, sourceID+".md")
 	meta, body, err := readNoteFile(path)
 	if err != nil {
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Файл не найден"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Файл не найден")})
 		return
 	}
 
@@ -190,29 +190,29 @@ func (t *TelegramBot) handleMDListActionCallback(c *tb.Callback) {
 			Meta:      meta,
 			WordCount: len(strings.Fields(body)),
 		})
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Отправил файл"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Отправил файл")})
 	case "nt":
 		if meta.URL == "" {
-			_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "У файла нет URL источника"})
+			_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("У файла нет URL источника")})
 			return
 		}
-		statusMsg, _ := t.Bot.Send(c.Message.Chat, "⏳ В очереди...")
+		statusMsg, _ := t.Bot.Send(c.Message.Chat, t.tr("⏳ В очереди..."))
 		t.enqueueNotesJob(statusMsg, nil, meta.URL, "notes", "")
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Поставил в очередь"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Поставил в очередь")})
 	case "rm":
 		if err := os.Remove(path); err != nil {
-			_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: fmt.Sprintf("Ошибка: %v", err)})
+			_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: fmt.Sprintf(t.tr("Ошибка: %v"), err)})
 			return
 		}
 		items, lerr := t.loadNotesList()
 		if lerr != nil || len(items) == 0 {
-			_, _ = t.Bot.Edit(c.Message, "📄 Транскриптов больше нет")
+			t.edit(c.Message, t.tr("📄 Транскриптов больше нет"))
 		} else {
 			msg, markup := t.buildMDListMessage(items, page)
-			_, _ = t.Bot.Edit(c.Message, msg, markup)
+			t.edit(c.Message, msg, markup)
 		}
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Удалён"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Удалён")})
 	default:
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Bad action"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Bad action")})
 	}
 }
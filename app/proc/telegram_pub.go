@@ -1,7 +1,6 @@
 package proc
 
 import (
-	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -20,21 +19,21 @@ func (t *TelegramBot) handleFeeds(m *tb.Message) {
 		return
 	}
 	if t.Pub == nil {
-		_, _ = t.Bot.Send(m.Chat, "Издательская платформа не настроена (R2_* + FEED_SECRET)")
+		_, _ = t.Bot.Send(m.Chat, t.tr("Издательская платформа не настроена (R2_* + FEED_SECRET)"))
 		return
 	}
 	cats, err := t.Pub.Categories()
 	if err != nil {
-		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf("Error: %v", err))
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Error: %v"), err))
 		return
 	}
 	if len(cats) == 0 {
-		_, _ = t.Bot.Send(m.Chat, "Пока нет ни одной ленты. Закинь файл в Inbox — появится.")
+		_, _ = t.Bot.Send(m.Chat, t.tr("Пока нет ни одной ленты. Закинь файл в Inbox — появится."))
 		return
 	}
 
 	var b strings.Builder
-	b.WriteString("📻 Ленты платформы:\n\n")
+	b.WriteString(t.tr("📻 Ленты платформы:\n\n"))
 	for _, cat := range cats {
 		eps, lerr := t.Pub.EpisodeList(cat)
 		if lerr != nil {
@@ -44,10 +43,10 @@ func (t *TelegramBot) handleFeeds(m *tb.Message) {
 		for _, ep := range eps {
 			totalSec += ep.DurationSec
 		}
-		fmt.Fprintf(&b, "• %s — %d эп., %s\n%s\n\n", cat, len(eps),
+		fmt.Fprintf(&b, t.tr("• %s — %d эп., %s\n%s\n\n"), cat, len(eps),
 			t.formatDuration(time.Duration(totalSec)*time.Second), t.Pub.FeedURL(cat))
 	}
-	b.WriteString("Управление эпизодами: /archive <категория>")
+	b.WriteString(t.tr("Управление эпизодами: /archive <категория>"))
 	_, _ = t.Bot.Send(m.Chat, b.String())
 }
 
@@ -57,19 +56,18 @@ func (t *TelegramBot) handleArchive(m *tb.Message) {
 		return
 	}
 	if t.Pub == nil {
-		_, _ = t.Bot.Send(m.Chat, "Издательская платформа не настроена (R2_* + FEED_SECRET)")
+		_, _ = t.Bot.Send(m.Chat, t.tr("Издательская платформа не настроена (R2_* + FEED_SECRET)"))
 		return
 	}
-	parts := strings.Fields(m.Text)
-	if len(parts) < 2 {
+	category := commandArgs(m).arg(0)
+	if category == "" {
 		cats, _ := t.Pub.Categories()
-		_, _ = t.Bot.Send(m.Chat, "Usage: /archive <категория>\nЕсть: "+strings.Join(cats, ", "))
+		_, _ = t.Bot.Send(m.Chat, t.tr("Usage: /archive <категория>\nЕсть: ")+strings.Join(cats, ", "))
 		return
 	}
-	category := strings.TrimSpace(parts[1])
 	msg, markup, err := t.buildArchiveList(category, 0)
 	if err != nil {
-		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf("Error: %v", err))
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Error: %v"), err))
 		return
 	}
 	_, _ = t.Bot.Send(m.Chat, msg, markup)
@@ -83,7 +81,7 @@ func (t *TelegramBot) buildArchiveList(category string, page int) (string, *tb.R
 		return "", nil, err
 	}
 	if len(eps) == 0 {
-		return fmt.Sprintf("В «%s» нет опубликованных эпизодов", category), &tb.ReplyMarkup{}, nil
+		return fmt.Sprintf(t.tr("В «%s» нет опубликованных эпизодов"), category), &tb.ReplyMarkup{}, nil
 	}
 
 	total := len(eps)
@@ -100,13 +98,13 @@ func (t *TelegramBot) buildArchiveList(category string, page int) (string, *tb.R
 	}
 
 	var b strings.Builder
-	fmt.Fprintf(&b, "🗄 %s (%d) — стр %d/%d:\n\n", category, total, page+1, pages)
+	fmt.Fprintf(&b, t.tr("🗄 %s (%d) — стр %d/%d:\n\n"), category, total, page+1, pages)
 	for i := start; i < end; i++ {
 		ep := eps[i]
-		fmt.Fprintf(&b, "%d. %s (%s, %d МБ)\n", i+1, ep.Title,
+		fmt.Fprintf(&b, t.tr("%d. %s (%s, %d МБ)\n"), i+1, ep.Title,
 			t.formatDuration(time.Duration(ep.DurationSec)*time.Second), ep.SizeBytes/1024/1024)
 	}
-	b.WriteString("\n🗄 в архив (из ленты и R2) · 🔁 переобработать")
+	b.WriteString(t.tr("\n🗄 в архив (из ленты и R2) · 🔁 переобработать"))
 
 	markup := &tb.ReplyMarkup{}
 	if pages > 1 {
@@ -138,10 +136,10 @@ func (t *TelegramBot) handlePubPageCallback(c *tb.Callback) {
 	category, page, _ := parsePubCallback(c.Data)
 	msg, markup, err := t.buildArchiveList(category, page)
 	if err != nil {
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Ошибка"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Ошибка")})
 		return
 	}
-	_, _ = t.Bot.Edit(c.Message, msg, markup)
+	t.edit(c.Message, msg, markup)
 	_ = t.Bot.Respond(c)
 }
 
@@ -159,12 +157,12 @@ func (t *TelegramBot) handlePubActionCallback(c *tb.Callback) {
 
 	eps, err := t.Pub.EpisodeList(category)
 	if err != nil || idx < 0 || idx >= len(eps) {
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Список устарел, открой /archive заново"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Список устарел, открой /archive заново")})
 		return
 	}
 	file := eps[idx].File
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	ctx, cancel := t.jobContext(2 * time.Minute)
 	defer cancel()
 	switch action {
 	case "ar":
@@ -172,21 +170,21 @@ func (t *TelegramBot) handlePubActionCallback(c *tb.Callback) {
 	case "rq":
 		err = t.Pub.Requeue(ctx, category, file)
 	default:
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Bad action"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Bad action")})
 		return
 	}
 	if err != nil {
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: fmt.Sprintf("Ошибка: %v", err)})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: fmt.Sprintf(t.tr("Ошибка: %v"), err)})
 		return
 	}
 
-	toast := "В архиве"
+	toast := t.tr("В архиве")
 	if action == "rq" {
-		toast = "Забыт — watcher переобработает"
+		toast = t.tr("Забыт — watcher переобработает")
 	}
 	msg, markup, berr := t.buildArchiveList(category, page)
 	if berr == nil {
-		_, _ = t.Bot.Edit(c.Message, msg, markup)
+		t.edit(c.Message, msg, markup)
 	}
 	_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: toast})
 }
@@ -0,0 +1,126 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+)
+
+// maxReadLaterFailures is how many times an item is tried before it's given
+// up on: archived, so it doesn't hold up the queue, and reported to the owner
+const maxReadLaterFailures = 3
+
+// pollReadLater voices unread items of the read-later queue periodically
+// until ctx is done
+func (t *TelegramBot) pollReadLater(ctx context.Context) {
+	if t.ReadLater == nil || t.ArticleExtractor == nil {
+		return
+	}
+	interval := t.ReadLaterConf.Interval
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		t.checkReadLater(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkReadLater voices the unread items one at a time and archives each one
+// in the feed now. Failed attempts are counted in the store across checks and
+// restarts, an item failing for good or maxReadLaterFailures times is archived
+// too, the queue is taken oldest first and would be stuck behind it otherwise.
+func (t *TelegramBot) checkReadLater(ctx context.Context) {
+	limit := t.ReadLaterConf.Limit
+	if limit <= 0 {
+		limit = 5
+	}
+	name := t.ReadLater.Name()
+	items, err := t.ReadLater.Unread(ctx, limit)
+	if err != nil {
+		log.Printf("[WARN] can't read %s queue: %v", name, err)
+		return
+	}
+	for _, item := range items {
+		if ctx.Err() != nil {
+			return
+		}
+		if item.URL == "" {
+			log.Printf("[INFO] %s item %s has no URL, archived", name, item.ID)
+			t.archiveReadLater(ctx, item)
+			continue
+		}
+		err := t.voiceReadLaterItem(ctx, item)
+		if err == nil {
+			t.archiveReadLater(ctx, item)
+			continue
+		}
+		n, serr := t.Store.AddReadLaterFailure(name, item.ID)
+		if serr != nil {
+			log.Printf("[WARN] can't count the failure of %s item %s: %v", name, item.ID, serr)
+		}
+		if n < maxReadLaterFailures && !IsPermanent(err) {
+			continue
+		}
+		t.NotifyOwner(fmt.Sprintf(t.tr("⚠️ %s: не получилось озвучить «%s» (попыток: %d), убрал в архив\n%s\n%v"),
+			name, item.Title, n, item.URL, err))
+		t.archiveReadLater(ctx, item)
+	}
+}
+
+// archiveReadLater archives the item in the queue and forgets its failures
+func (t *TelegramBot) archiveReadLater(ctx context.Context, item ReadLaterItem) {
+	if err := t.ReadLater.Archive(ctx, item.ID); err != nil {
+		log.Printf("[WARN] can't archive %s in %s: %v", item.URL, t.ReadLater.Name(), err)
+		return
+	}
+	if err := t.Store.ClearReadLaterFailures(t.ReadLater.Name(), item.ID); err != nil {
+		log.Printf("[WARN] can't clear the failures of %s: %v", item.URL, err)
+	}
+}
+
+// voiceReadLaterItem runs the item through the article pipeline, a YouTube
+// video through the rule of its channel, with the progress in the owner chat.
+// nil means the item is in the feed now, voiced or found there already; an
+// item waiting for a choice is an error, it's not voiced.
+func (t *TelegramBot) voiceReadLaterItem(ctx context.Context, item ReadLaterItem) error {
+	chat := &tb.Chat{ID: t.AllowedUserID}
+	statusMsg, err := t.Bot.Send(chat, fmt.Sprintf("🔖 %s: %s", t.ReadLater.Name(), item.Title))
+	if err != nil {
+		log.Printf("[WARN] read-later item %s not voiced, can't send status: %v", item.URL, err)
+		return fmt.Errorf("can't send status: %w", err)
+	}
+	videoID := t.extractYouTubeVideoID(item.URL)
+	timeout := articleJobTimeout
+	if videoID != "" {
+		timeout = voiceoverJobTimeout
+	}
+	jobCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if videoID != "" {
+		err = t.processBackgroundVideo(jobCtx, chat, statusMsg, videoID, t.ReadLaterConf.Preset)
+	} else {
+		err = t.processArticle(jobCtx, chat, statusMsg, nil, articleRequest{URL: item.URL, Preset: t.ReadLaterConf.Preset,
+			Background: true})
+	}
+	switch {
+	case errors.Is(err, errInFeedAlready):
+		log.Printf("[INFO] read-later item %s not voiced: %v", item.URL, err)
+		return nil
+	case err != nil:
+		log.Printf("[WARN] failed to voice read-later item %s: %v", item.URL, err)
+		t.edit(statusMsg, t.userErrorText(err))
+		return err
+	}
+	return nil
+}
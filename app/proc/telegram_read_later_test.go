@@ -0,0 +1,116 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/feed-master/app/config"
+)
+
+// fakeReadLater is an in-memory read-later queue
+type fakeReadLater struct {
+	items    []ReadLaterItem
+	archived []string
+	err      error
+}
+
+func (f *fakeReadLater) Name() string { return "Fake" }
+
+func (f *fakeReadLater) Unread(_ context.Context, limit int) ([]ReadLaterItem, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	var res []ReadLaterItem
+	for _, it := range f.items {
+		if len(res) < limit && !strings.Contains(strings.Join(f.archived, ","), it.ID) {
+			res = append(res, it)
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeReadLater) Archive(_ context.Context, id string) error {
+	f.archived = append(f.archived, id)
+	return nil
+}
+
+func TestTelegramBot_CheckReadLater(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`<html><head><title>Статья</title></head><body><article><p>` +
+			strings.Repeat("Отложенная статья для прослушивания. ", 30) + `</p></article></body></html>`))
+	}))
+	defer ts.Close()
+
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Store = newTestJobStore(t)
+	bot.MaxItems = 10
+	bot.ArticleExtractor = NewArticleExtractor()
+	queue := &fakeReadLater{items: []ReadLaterItem{
+		{ID: "1", URL: ts.URL + "/good", Title: "Good"},
+		{ID: "2", URL: ts.URL + "/broken", Title: "Broken"},
+	}}
+	bot.ReadLater = queue
+	bot.ReadLaterConf.Limit = 5
+	ctx := context.Background()
+
+	bot.checkReadLater(ctx)
+	assert.Equal(t, []string{"1"}, queue.archived, "voiced item archived, failed one stays")
+	entries, err := bot.Store.Load(bot.FeedName, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	queue.items = append(queue.items, ReadLaterItem{ID: "3", URL: ts.URL + "/good?again", Title: "Same"})
+	for i := 1; i < maxReadLaterFailures; i++ {
+		bot.checkReadLater(ctx)
+	}
+	assert.Equal(t, []string{"1", "3", "2"}, queue.archived,
+		"the same text is in the feed already, the broken item given up on after the limit")
+	var notified int
+	for _, s := range stub.texts("sendMessage") {
+		if strings.Contains(s, "не получилось озвучить «Broken» (попыток: 3)") {
+			notified++
+		}
+	}
+	assert.Equal(t, 1, notified)
+	n, err := bot.Store.AddReadLaterFailure("Fake", "2")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n, "failures forgotten once archived")
+
+	queue.err = errors.New("down")
+	bot.checkReadLater(ctx) // logged, no panic
+}
+
+func TestTelegramBot_CheckReadLaterPermanent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Длинная</title></head><body><article><p>` +
+			strings.Repeat("Очень длинная отложенная статья. ", 100) + `</p></article></body></html>`))
+	}))
+	defer ts.Close()
+
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Store = newTestJobStore(t)
+	bot.MaxItems = 10
+	bot.ArticleExtractor = NewArticleExtractor()
+	bot.ArticleLimit = config.ArticleLimit{MaxChars: 500, Policy: "ask"}
+	queue := &fakeReadLater{items: []ReadLaterItem{{ID: "1", URL: ts.URL + "/long", Title: "Long"}}}
+	bot.ReadLater = queue
+
+	bot.checkReadLater(context.Background())
+	assert.Equal(t, []string{"1"}, queue.archived, "too long for good, no buttons to wait for")
+	assert.Empty(t, bot.pendingActions)
+	entries, _ := bot.Store.Load(bot.FeedName, 10)
+	assert.Empty(t, entries)
+}
@@ -18,11 +18,11 @@ const readListPageSize = 5
 // layer; bare /read shows the paginated list of saved articles.
 func (t *TelegramBot) handleRead(m *tb.Message) {
 	if !t.isAuthorized(m.Sender) {
-		_, _ = t.Bot.Send(m.Chat, "Unauthorized. This bot is private.")
+		_, _ = t.Bot.Send(m.Chat, t.tr("Unauthorized. This bot is private."))
 		return
 	}
 	if t.ReadSvc == nil {
-		_, _ = t.Bot.Send(m.Chat, "❌ Читалка не настроена (read.enabled).")
+		_, _ = t.Bot.Send(m.Chat, t.tr("❌ Читалка не настроена (read.enabled)."))
 		return
 	}
 
@@ -32,37 +32,37 @@ func (t *TelegramBot) handleRead(m *tb.Message) {
 		return
 	}
 	if !IsArticleURL(rawURL) {
-		_, _ = t.Bot.Send(m.Chat, "❌ Это не похоже на ссылку на статью.")
+		_, _ = t.Bot.Send(m.Chat, t.tr("❌ Это не похоже на ссылку на статью."))
 		return
 	}
 
-	statusMsg, _ := t.Bot.Send(m.Chat, "⏳ Добавляю в читалку...")
-	go t.processRead(context.Background(), m.Chat, statusMsg, m, rawURL)
+	statusMsg, _ := t.Bot.Send(m.Chat, t.tr("⏳ Добавляю в читалку..."))
+	t.goJob(articleJobTimeout, func(ctx context.Context) { t.processRead(ctx, m.Chat, statusMsg, m, rawURL) })
 }
 
 // processRead is the UI-bearing core: extract the article, save it, hand the
 // resulting .md back to the chat as a document
 func (t *TelegramBot) processRead(ctx context.Context, chat *tb.Chat, statusMsg, originalMsg *tb.Message, rawURL string) {
 	if t.ReadSvc == nil {
-		_, _ = t.Bot.Edit(statusMsg, "❌ Читалка не настроена.")
+		t.edit(statusMsg, t.tr("❌ Читалка не настроена."))
 		return
 	}
-	res, err := t.ReadSvc.Save(ctx, rawURL)
+	res, err := t.ReadSvc.Save(ctx, t.resolveURL(ctx, rawURL))
 	if err != nil {
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("❌ Error: %v", err))
+		t.edit(statusMsg, fmt.Sprintf(t.tr("❌ Error: %v"), err))
 		return
 	}
 
 	t.sendReadDocument(chat, res)
 
-	prefix := "📖 В читалке"
+	prefix := t.tr("📖 В читалке")
 	if res.Reused {
-		prefix = "♻️ Уже в читалке"
+		prefix = t.tr("♻️ Уже в читалке")
 	}
-	if caption := readCaption(res.Meta); caption != "" {
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("%s: %s\n%s", prefix, res.Title, caption))
+	if caption := t.readCaption(res.Meta); caption != "" {
+		t.edit(statusMsg, fmt.Sprintf("%s: %s\n%s", prefix, res.Title, caption))
 	} else {
-		_, _ = t.Bot.Edit(statusMsg, fmt.Sprintf("%s: %s", prefix, res.Title))
+		t.edit(statusMsg, fmt.Sprintf("%s: %s", prefix, res.Title))
 	}
 	t.deleteMessageAfterDelay(originalMsg, 5*time.Second)
 }
@@ -73,18 +73,18 @@ func (t *TelegramBot) sendReadDocument(chat *tb.Chat, res ReadResult) {
 	doc := &tb.Document{
 		File:     tb.FromDisk(res.MDPath),
 		FileName: sanitizeFileName(res.Title) + ".md",
-		Caption:  readCaption(res.Meta),
+		Caption:  t.readCaption(res.Meta),
 	}
 	if _, err := t.Bot.Send(chat, doc); err != nil {
-		_, _ = t.Bot.Send(chat, fmt.Sprintf("⚠️ Не смог отправить файл: %v", err))
+		_, _ = t.Bot.Send(chat, fmt.Sprintf(t.tr("⚠️ Не смог отправить файл: %v"), err))
 	}
 }
 
 // readCaption builds the stats line: reading time · tags
-func readCaption(meta ReadMeta) string {
+func (t *TelegramBot) readCaption(meta ReadMeta) string {
 	var parts []string
 	if meta.ReadingMin > 0 {
-		parts = append(parts, fmt.Sprintf("📖 %d мин", meta.ReadingMin))
+		parts = append(parts, fmt.Sprintf(t.tr("📖 %d мин"), meta.ReadingMin))
 	}
 	if len(meta.Tags) > 0 {
 		parts = append(parts, "🏷 "+strings.Join(meta.Tags, ", "))
@@ -100,7 +100,7 @@ func (t *TelegramBot) handleReadList(m *tb.Message) {
 		return
 	}
 	if len(items) == 0 {
-		_, _ = t.Bot.Send(m.Chat, "В читалке пусто. Пришли ссылку и выбери 📖 В читалку.")
+		_, _ = t.Bot.Send(m.Chat, t.tr("В читалке пусто. Пришли ссылку и выбери 📖 В читалку."))
 		return
 	}
 	msg, markup := t.buildReadListMessage(items, 0)
@@ -128,7 +128,7 @@ func (t *TelegramBot) buildReadListMessage(items []ReadItem, page int) (string,
 	}
 
 	var b strings.Builder
-	fmt.Fprintf(&b, "📖 Читалка (%d) — стр %d/%d:\n\n", total, page+1, pages)
+	fmt.Fprintf(&b, t.tr("📖 Читалка (%d) — стр %d/%d:\n\n"), total, page+1, pages)
 	for i := start; i < end; i++ {
 		item := items[i]
 		fmt.Fprintf(&b, "%d. %s\n", i+1, item.Meta.Title)
@@ -137,7 +137,7 @@ func (t *TelegramBot) buildReadListMessage(items []ReadItem, page int) (string,
 			chips = append(chips, item.Meta.DateAdded)
 		}
 		if item.Meta.ReadingMin > 0 {
-			chips = append(chips, fmt.Sprintf("%d мин", item.Meta.ReadingMin))
+			chips = append(chips, fmt.Sprintf(t.tr("%d мин"), item.Meta.ReadingMin))
 		}
 		if item.Meta.Site != "" {
 			chips = append(chips, item.Meta.Site)
@@ -149,7 +149,7 @@ func (t *TelegramBot) buildReadListMessage(items []ReadItem, page int) (string,
 			fmt.Fprintf(&b, "    %s\n", strings.Join(chips, " · "))
 		}
 	}
-	b.WriteString("\n⬇️ скачать · 🔗 источник · 🗑 удалить")
+	b.WriteString(t.tr("\n⬇️ скачать · 🔗 источник · 🗑 удалить"))
 
 	markup := &tb.ReplyMarkup{}
 	if pages > 1 {
@@ -185,11 +185,11 @@ func (t *TelegramBot) handleReadListPageCallback(c *tb.Callback) {
 	_, _ = fmt.Sscanf(strings.TrimPrefix(c.Data, "p="), "%d", &page)
 	items, err := t.ReadSvc.List()
 	if err != nil {
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Ошибка"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Ошибка")})
 		return
 	}
 	msg, markup := t.buildReadListMessage(items, page)
-	_, _ = t.Bot.Edit(c.Message, msg, markup)
+	t.edit(c.Message, msg, markup)
 	_ = t.Bot.Respond(c)
 }
 
@@ -214,14 +214,14 @@ func (t *TelegramBot) handleReadListActionCallback(c *tb.Callback) {
 		}
 	}
 	if sourceID == "" || strings.ContainsAny(sourceID, "/\\") || strings.Contains(sourceID, "..") {
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Bad data"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Bad data")})
 		return
 	}
 
 	path := filepath.Join(t.ReadSvc.Location, sourceID+".md")
 	meta, body, err := readReadFile(path)
 	if err != nil {
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Файл не найден"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Файл не найден")})
 		return
 	}
 
@@ -230,28 +230,28 @@ func (t *TelegramBot) handleReadListActionCallback(c *tb.Callback) {
 		t.sendReadDocument(c.Message.Chat, ReadResult{
 			MDPath: path, Title: meta.Title, Meta: meta, WordCount: len(strings.Fields(body)),
 		})
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Отправил файл"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Отправил файл")})
 	case "src":
 		if meta.SourceURL == "" {
-			_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "У статьи нет URL источника"})
+			_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("У статьи нет URL источника")})
 			return
 		}
 		_, _ = t.Bot.Send(c.Message.Chat, fmt.Sprintf("🔗 %s\n%s", meta.Title, meta.SourceURL))
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Прислал ссылку"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Прислал ссылку")})
 	case "rm":
 		if err := t.ReadSvc.Delete(sourceID); err != nil {
-			_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: fmt.Sprintf("Ошибка: %v", err)})
+			_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: fmt.Sprintf(t.tr("Ошибка: %v"), err)})
 			return
 		}
 		items, lerr := t.ReadSvc.List()
 		if lerr != nil || len(items) == 0 {
-			_, _ = t.Bot.Edit(c.Message, "📖 В читалке больше ничего нет")
+			t.edit(c.Message, t.tr("📖 В читалке больше ничего нет"))
 		} else {
 			msg, markup := t.buildReadListMessage(items, page)
-			_, _ = t.Bot.Edit(c.Message, msg, markup)
+			t.edit(c.Message, msg, markup)
 		}
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Удалён"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Удалён")})
 	default:
-		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: "Bad action"})
+		_ = t.Bot.Respond(c, &tb.CallbackResponse{Text: t.tr("Bad action")})
 	}
 }
@@ -0,0 +1,266 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	"github.com/umputun/feed-master/app/feed"
+	ytstore "github.com/umputun/feed-master/app/youtube/store"
+)
+
+const defaultRSSPollInterval = 30 * time.Minute
+
+// errTooShort is returned by processArticle for a text under the request's
+// minimum length, subscription posts like that are skipped quietly
+var errTooShort = errors.New("article is shorter than the minimum")
+
+// handleRSSSub handles /rsssub: with a feed URL subscribes to it, without
+// lists the subscriptions. "min=N" skips posts under N characters, a preset
+// name sets how the posts are processed.
+func (t *TelegramBot) handleRSSSub(m *tb.Message) {
+	if !t.isAuthorized(m.Sender) {
+		return
+	}
+	if t.Store == nil || !t.TTSEnabled {
+		_, _ = t.Bot.Send(m.Chat, t.tr("❌ Озвучка статей не настроена (tts_enabled)."))
+		return
+	}
+	feedURL := t.extractURL(m.Text)
+	if feedURL == "" {
+		t.sendRSSSubscriptions(m.Chat)
+		return
+	}
+
+	sub := ytstore.RSSSubscription{ID: ytstore.RSSSubscriptionID(feedURL), URL: feedURL, CreatedAt: time.Now().UTC()}
+	for _, arg := range commandArgs(m).pos {
+		switch {
+		case arg == feedURL:
+		case strings.HasPrefix(arg, "min="):
+			n, err := strconv.Atoi(strings.TrimPrefix(arg, "min="))
+			if err != nil || n < 0 {
+				_, _ = t.Bot.Send(m.Chat, t.tr("❌ min= ждёт число символов, например min=2000"))
+				return
+			}
+			sub.MinChars = n
+		default:
+			name := strings.ToLower(arg)
+			if _, ok := t.Presets[name]; !ok {
+				_, _ = t.Bot.Send(m.Chat, t.tr("Usage: /rsssub <url> [min=N] [пресет]"))
+				return
+			}
+			sub.Preset = name
+		}
+	}
+
+	statusMsg, err := t.Bot.Send(m.Chat, t.tr("📰 Читаю ленту..."))
+	if err != nil {
+		log.Printf("[WARN] can't send status for /rsssub: %v", err)
+		return
+	}
+	t.goJob(lookupJobTimeout, func(context.Context) { t.subscribeRSS(statusMsg, sub) })
+}
+
+// subscribeRSS fetches the feed of the subscription and saves it. Posts
+// already in the feed are the backlog, only the ones coming after are voiced.
+func (t *TelegramBot) subscribeRSS(statusMsg *tb.Message, sub ytstore.RSSSubscription) {
+	rss, err := feed.Parse(sub.URL)
+	if err != nil {
+		t.edit(statusMsg, fmt.Sprintf(t.tr("❌ Не читается как RSS/Atom: %v"), err))
+		return
+	}
+	sub.Title = rss.Title
+	for _, item := range rss.ItemList {
+		if key := rssItemKey(item); key != "" {
+			sub.MarkSeen(key)
+		}
+	}
+	if err := t.Store.SaveRSSSubscription(sub); err != nil {
+		t.edit(statusMsg, fmt.Sprintf(t.tr("❌ Error: %v"), err))
+		return
+	}
+	log.Printf("[INFO] subscribed to rss %s (%s)", sub.URL, sub.Title)
+	t.edit(statusMsg, fmt.Sprintf(t.tr("📰 Подписка: %s\nНовые посты будут озвучиваться в ленту, %d текущих пропущено."),
+		rssSubTitle(sub), len(sub.Seen)))
+}
+
+// handleRSSUnsub handles /rssunsub N, N as /rsssub lists them
+func (t *TelegramBot) handleRSSUnsub(m *tb.Message) {
+	if !t.isAuthorized(m.Sender) || t.Store == nil {
+		return
+	}
+	subs, err := t.Store.LoadRSSSubscriptions()
+	if err != nil {
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("❌ Error: %v"), err))
+		return
+	}
+	arg := commandArgs(m).arg(0)
+	if arg == "" {
+		_, _ = t.Bot.Send(m.Chat, t.tr("Usage: /rssunsub N (номер из /rsssub)"))
+		return
+	}
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > len(subs) {
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("❌ Нет подписки %s, всего %d"), arg, len(subs)))
+		return
+	}
+	sub := subs[n-1]
+	if err := t.Store.DeleteRSSSubscription(sub.ID); err != nil {
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("❌ Error: %v"), err))
+		return
+	}
+	log.Printf("[INFO] unsubscribed from rss %s", sub.URL)
+	_, _ = t.Bot.Send(m.Chat, t.tr("🗑 Отписался: ")+rssSubTitle(sub))
+}
+
+// sendRSSSubscriptions lists the subscriptions with their filters
+func (t *TelegramBot) sendRSSSubscriptions(chat *tb.Chat) {
+	subs, err := t.Store.LoadRSSSubscriptions()
+	if err != nil {
+		_, _ = t.Bot.Send(chat, fmt.Sprintf(t.tr("❌ Error: %v"), err))
+		return
+	}
+	if len(subs) == 0 {
+		_, _ = t.Bot.Send(chat, t.tr("Подписок нет. /rsssub <url> [min=N] [пресет] — озвучивать новые посты ленты."))
+		return
+	}
+	var b strings.Builder
+	b.WriteString(t.tr("📰 RSS-подписки:\n\n"))
+	for i, sub := range subs {
+		fmt.Fprintf(&b, "%d. %s\n%s\n", i+1, rssSubTitle(sub), sub.URL)
+		var opts []string
+		if sub.MinChars > 0 {
+			opts = append(opts, fmt.Sprintf(t.tr("от %d символов"), sub.MinChars))
+		}
+		if sub.Preset != "" {
+			opts = append(opts, t.tr("пресет ")+sub.Preset)
+		}
+		if len(opts) > 0 {
+			b.WriteString(strings.Join(opts, ", ") + "\n")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(t.tr("Отписаться: /rssunsub N"))
+	_, _ = t.Bot.Send(chat, b.String())
+}
+
+// pollRSSSubscriptions checks the subscribed feeds periodically until ctx is done
+func (t *TelegramBot) pollRSSSubscriptions(ctx context.Context) {
+	if t.Store == nil || t.ArticleExtractor == nil {
+		return
+	}
+	interval := t.RSSPollInterval
+	if interval <= 0 {
+		interval = defaultRSSPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.checkRSSSubscriptions(ctx)
+		}
+	}
+}
+
+// checkRSSSubscriptions voices new posts of every subscription, one post at
+// a time: a burst of posts shouldn't run a dozen TTS jobs at once
+func (t *TelegramBot) checkRSSSubscriptions(ctx context.Context) {
+	subs, err := t.Store.LoadRSSSubscriptions()
+	if err != nil {
+		log.Printf("[WARN] can't load rss subscriptions: %v", err)
+		return
+	}
+	for _, sub := range subs {
+		if ctx.Err() != nil {
+			return
+		}
+		t.checkRSSSubscription(ctx, sub)
+	}
+}
+
+// checkRSSSubscription voices the posts not seen before, oldest first. A post
+// is marked seen whatever the outcome, a failed one is reported, not retried.
+func (t *TelegramBot) checkRSSSubscription(ctx context.Context, sub ytstore.RSSSubscription) {
+	rss, err := feed.Parse(sub.URL)
+	if err != nil {
+		log.Printf("[WARN] rss subscription %s: %v", sub.URL, err)
+		return
+	}
+	for i := len(rss.ItemList) - 1; i >= 0; i-- {
+		item := rss.ItemList[i]
+		key := rssItemKey(item)
+		if key == "" || sub.IsSeen(key) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		t.voiceRSSItem(ctx, sub, item)
+		if err := t.Store.MarkRSSSeen(sub.ID, key); err != nil {
+			log.Printf("[WARN] can't mark rss post %s seen: %v", key, err)
+		}
+	}
+}
+
+// voiceRSSItem runs a post through the article pipeline, a YouTube video
+// through the rule of its channel; progress goes to the owner chat like for
+// a link sent by hand
+func (t *TelegramBot) voiceRSSItem(ctx context.Context, sub ytstore.RSSSubscription, item feed.Item) {
+	if item.Link == "" {
+		return
+	}
+	chat := &tb.Chat{ID: t.AllowedUserID}
+	statusMsg, err := t.Bot.Send(chat, fmt.Sprintf("📰 %s: %s", rssSubTitle(sub), item.Title))
+	if err != nil {
+		log.Printf("[WARN] rss post %s not voiced, can't send status: %v", item.Link, err)
+		return
+	}
+	videoID := t.extractYouTubeVideoID(item.Link)
+	timeout := articleJobTimeout
+	if videoID != "" {
+		timeout = voiceoverJobTimeout
+	}
+	jobCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if videoID != "" {
+		err = t.processBackgroundVideo(jobCtx, chat, statusMsg, videoID, sub.Preset)
+	} else {
+		err = t.processArticle(jobCtx, chat, statusMsg, nil, articleRequest{URL: item.Link, Preset: sub.Preset,
+			MinChars: sub.MinChars, Background: true})
+	}
+	switch {
+	case errors.Is(err, errTooShort):
+		log.Printf("[INFO] rss post %s skipped: %v", item.Link, err)
+		_ = t.Bot.Delete(statusMsg)
+	case errors.Is(err, errInFeedAlready):
+		log.Printf("[INFO] rss post %s not voiced: %v", item.Link, err)
+	case err != nil:
+		log.Printf("[WARN] failed to voice rss post %s: %v", item.Link, err)
+		t.edit(statusMsg, t.userErrorText(err))
+	}
+}
+
+// rssItemKey identifies a post across polls: the guid, the link without it
+func rssItemKey(item feed.Item) string {
+	if item.GUID != "" {
+		return item.GUID
+	}
+	return item.Link
+}
+
+// rssSubTitle is the feed title, its URL for untitled feeds
+func rssSubTitle(sub ytstore.RSSSubscription) string {
+	if sub.Title != "" {
+		return sub.Title
+	}
+	return sub.URL
+}
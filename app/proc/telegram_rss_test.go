@@ -0,0 +1,93 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelegramBot_RSSSubscription(t *testing.T) {
+	var mu sync.Mutex
+	posts := []string{"old"}
+	var srvURL string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/rss":
+			var items strings.Builder
+			for i := len(posts) - 1; i >= 0; i-- { // newest first
+				fmt.Fprintf(&items, "<item><title>%s</title><link>%s/post/%s</link><guid>%s</guid></item>", posts[i], srvURL, posts[i], posts[i])
+			}
+			_, _ = w.Write([]byte(`<rss version="2.0"><channel><title>Newsletter</title>` + items.String() + `</channel></rss>`))
+		case r.URL.Path == "/post/short":
+			_, _ = w.Write([]byte(`<html><body><article><p>Коротко о главном, без подробностей.</p></article></body></html>`))
+		default:
+			_, _ = w.Write([]byte(`<html><head><title>` + r.URL.Path + `</title></head><body><article><p>` +
+				strings.Repeat("Длинный пост рассылки про важные вещи "+r.URL.Path+". ", 30) + `</p></article></body></html>`))
+		}
+	}))
+	defer ts.Close()
+	srvURL = ts.URL
+
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Store = newTestJobStore(t)
+	bot.MaxItems = 10
+	bot.ArticleExtractor = NewArticleExtractor()
+
+	bot.handleRSSSub(testMessage(testBotUserID, "/rsssub "+ts.URL+"/rss min=200 nosuch"))
+	assert.Contains(t, stub.texts("sendMessage")[0], "Usage", "unknown preset rejected")
+
+	bot.handleRSSSub(testMessage(testBotUserID, "/rsssub "+ts.URL+"/rss min=200"))
+	sent := stub.texts("sendMessage")
+	assert.Contains(t, sent[len(sent)-1], "Читаю ленту", "feed fetched in a job")
+	require.Eventually(t, func() bool {
+		edits := stub.texts("editMessageText")
+		return len(edits) > 0 && strings.Contains(edits[len(edits)-1], "Подписка: Newsletter")
+	}, 5*time.Second, 10*time.Millisecond)
+	edits := stub.texts("editMessageText")
+	assert.Contains(t, edits[len(edits)-1], "1 текущих пропущено")
+
+	subs, err := bot.Store.LoadRSSSubscriptions()
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, 200, subs[0].MinChars)
+
+	mu.Lock()
+	posts = append(posts, "first", "short")
+	mu.Unlock()
+	bot.checkRSSSubscriptions(context.Background())
+
+	entries, err := bot.Store.Load(bot.FeedName, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "backlog and short post skipped")
+	assert.Equal(t, ts.URL+"/post/first", entries[0].Link.Href)
+
+	subs, err = bot.Store.LoadRSSSubscriptions()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old", "first", "short"}, subs[0].Seen)
+
+	bot.checkRSSSubscriptions(context.Background())
+	entries, err = bot.Store.Load(bot.FeedName, 10)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "seen posts not voiced again")
+
+	bot.handleRSSSub(testMessage(testBotUserID, "/rsssub"))
+	sent = stub.texts("sendMessage")
+	assert.Contains(t, sent[len(sent)-1], "1. Newsletter")
+	assert.Contains(t, sent[len(sent)-1], "от 200 символов")
+
+	bot.handleRSSUnsub(testMessage(testBotUserID, "/rssunsub 1"))
+	subs, err = bot.Store.LoadRSSSubscriptions()
+	require.NoError(t, err)
+	assert.Empty(t, subs)
+}
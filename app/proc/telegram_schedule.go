@@ -0,0 +1,175 @@
+package proc
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+const scheduleUsage = "Usage: /schedule N|from-to <when> [daily]\n" +
+	"when: tomorrow 7am, 19:00, 2026-10-20 07:30, +3h or now (publish right away)\n" +
+	"daily spreads a range over days, one episode a day, oldest first"
+
+// handleSchedule hides entries from the feed until a later time (/schedule 3
+// tomorrow 7am, /schedule 2-6 7am daily). The gc loop releases them.
+func (t *TelegramBot) handleSchedule(m *tb.Message) {
+	if !t.isAuthorized(m.Sender) {
+		return
+	}
+	args := commandArgs(m)
+	if args.len() < 2 {
+		_, _ = t.Bot.Send(m.Chat, scheduleUsage)
+		return
+	}
+	words := args.pos[1:]
+	daily := slices.Contains(words, "daily")
+	words = slices.DeleteFunc(words, func(w string) bool { return w == "daily" })
+	now := time.Now()
+	at, err := parseScheduleTime(now, words)
+	if err != nil {
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf("%v\n\n%s", err, scheduleUsage))
+		return
+	}
+
+	entries, err := t.Store.Load(t.FeedName, t.MaxItems)
+	if err != nil {
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Error: %v"), err))
+		return
+	}
+	if len(entries) == 0 {
+		_, _ = t.Bot.Send(m.Chat, t.tr("Feed is empty."))
+		return
+	}
+	selected, problem := selectEntries(entries, args.arg(0))
+	if problem != "" {
+		_, _ = t.Bot.Send(m.Chat, problem)
+		return
+	}
+	slices.Reverse(selected) // /list is newest first, a binge goes oldest first
+
+	var b strings.Builder
+	for i, e := range selected {
+		e.PublishAt = at
+		if daily {
+			e.PublishAt = at.AddDate(0, 0, i)
+		}
+		if !e.PublishAt.After(now) {
+			e.PublishAt = time.Time{}
+			e.Published = now
+		}
+		if err := t.Store.ReplaceEntry(e); err != nil {
+			_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Error scheduling %s: %v"), e.Title, err))
+			return
+		}
+		if e.PublishAt.IsZero() {
+			fmt.Fprintf(&b, t.tr("📢 %s — now\n"), e.Title)
+			continue
+		}
+		fmt.Fprintf(&b, "⏰ %s — %s\n", e.Title, e.PublishAt.Format("Mon 02.01 15:04"))
+	}
+	_, _ = t.Bot.Send(m.Chat, b.String())
+}
+
+// releaseScheduled publishes the entries whose time has come, dated by it,
+// so podcast apps see them as new
+func (t *TelegramBot) releaseScheduled(now time.Time) {
+	entries, err := t.Store.Load(t.FeedName, 0)
+	if err != nil {
+		log.Printf("[WARN] failed to load scheduled entries: %v", err)
+		return
+	}
+	for _, e := range entries {
+		if e.PublishAt.IsZero() || e.PublishAt.After(now) {
+			continue
+		}
+		e.Published, e.PublishAt = e.PublishAt, time.Time{}
+		if err := t.Store.ReplaceEntry(e); err != nil {
+			log.Printf("[WARN] failed to release scheduled %s: %v", e.VideoID, err)
+			continue
+		}
+		log.Printf("[INFO] released scheduled %s: %s", e.VideoID, e.Title)
+	}
+}
+
+// parseScheduleTime parses the time of /schedule: "now", a duration from now
+// ("+3h", "in 90m"), or a clock time ("7am", "19:00") optionally after a day
+// ("today", "tomorrow", "2026-10-20"). A clock time without a day is its next
+// occurrence. Times are local.
+func parseScheduleTime(now time.Time, words []string) (time.Time, error) {
+	if len(words) > 0 && words[0] == "in" {
+		words = words[1:]
+	}
+	switch {
+	case len(words) == 0:
+		return time.Time{}, fmt.Errorf("no time given")
+	case len(words) == 1 && words[0] == "now":
+		return now, nil
+	case len(words) == 1:
+		if d, err := time.ParseDuration(strings.TrimPrefix(words[0], "+")); err == nil {
+			if d <= 0 {
+				return time.Time{}, fmt.Errorf("bad duration %q", words[0])
+			}
+			return now.Add(d), nil
+		}
+	}
+
+	var day time.Time
+	clock := -1 // minutes since midnight
+	for _, w := range words {
+		switch w = strings.ToLower(w); w {
+		case "today":
+			day = now
+		case "tomorrow":
+			day = now.AddDate(0, 0, 1)
+		default:
+			if d, err := time.ParseInLocation(time.DateOnly, w, now.Location()); err == nil {
+				day = d
+				continue
+			}
+			mins, ok := parseClock(w)
+			if !ok {
+				return time.Time{}, fmt.Errorf("can't parse %q", w)
+			}
+			clock = mins
+		}
+	}
+	if clock < 0 {
+		return time.Time{}, fmt.Errorf("no time of day given")
+	}
+	if day.IsZero() {
+		at := time.Date(now.Year(), now.Month(), now.Day(), clock/60, clock%60, 0, 0, now.Location())
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+		return at, nil
+	}
+	at := time.Date(day.Year(), day.Month(), day.Day(), clock/60, clock%60, 0, 0, now.Location())
+	if !at.After(now) {
+		return time.Time{}, fmt.Errorf("%s is in the past", at.Format("2006-01-02 15:04"))
+	}
+	return at, nil
+}
+
+// parseClock parses "19:00", "7am", "7:30pm" into minutes since midnight
+func parseClock(s string) (int, bool) {
+	for _, layout := range []string{"15:04", "3pm", "3:04pm"} {
+		if ts, err := time.Parse(layout, s); err == nil {
+			return ts.Hour()*60 + ts.Minute(), true
+		}
+	}
+	return 0, false
+}
+
+// scheduledMark is the /list prefix of an entry waiting for its time
+func scheduledMark(e ytfeed.Entry) string {
+	if e.PublishAt.IsZero() {
+		return ""
+	}
+	return "⏰ " + e.PublishAt.Format("02.01 15:04") + " "
+}
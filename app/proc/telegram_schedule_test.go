@@ -0,0 +1,85 @@
+package proc
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScheduleTime(t *testing.T) {
+	now := time.Date(2026, 10, 16, 20, 15, 0, 0, time.Local)
+	tbl := []struct {
+		when string
+		want time.Time
+		err  string
+	}{
+		{when: "now", want: now},
+		{when: "+3h", want: now.Add(3 * time.Hour)},
+		{when: "in 90m", want: now.Add(90 * time.Minute)},
+		{when: "tomorrow 7am", want: time.Date(2026, 10, 17, 7, 0, 0, 0, time.Local)},
+		{when: "tomorrow 7:30pm", want: time.Date(2026, 10, 17, 19, 30, 0, 0, time.Local)},
+		{when: "21:00", want: time.Date(2026, 10, 16, 21, 0, 0, 0, time.Local)},
+		{when: "7am", want: time.Date(2026, 10, 17, 7, 0, 0, 0, time.Local), err: ""},
+		{when: "2026-10-20 07:30", want: time.Date(2026, 10, 20, 7, 30, 0, 0, time.Local)},
+		{when: "today 23:00", want: time.Date(2026, 10, 16, 23, 0, 0, 0, time.Local)},
+		{when: "today 7am", err: "2026-10-16 07:00 is in the past"},
+		{when: "tomorrow", err: "no time of day given"},
+		{when: "-1h", err: `bad duration "-1h"`},
+		{when: "soon", err: `can't parse "soon"`},
+		{when: "", err: "no time given"},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.when, func(t *testing.T) {
+			res, err := parseScheduleTime(now, strings.Fields(tt.when))
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, res)
+		})
+	}
+}
+
+func TestTelegramBot_HandleSchedule(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Store = newTestJobStore(t)
+	saveDeleteFixture(t, bot, 4)
+
+	bot.handleSchedule(testMessage(testBotUserID, "/schedule 2-3 tomorrow 7am daily"))
+	sent := stub.texts("sendMessage")
+	require.Len(t, sent, 1)
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	first := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 7, 0, 0, 0, time.Local)
+	assert.Equal(t, "⏰ title 2 — "+first.Format("Mon 02.01 15:04")+"\n⏰ title 3 — "+
+		first.AddDate(0, 0, 1).Format("Mon 02.01 15:04")+"\n", sent[0], "oldest first, a day apart")
+
+	entries, err := bot.Store.Load(bot.FeedName, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 4, "list order kept until released")
+	assert.True(t, first.AddDate(0, 0, 1).Equal(entries[1].PublishAt))
+	assert.True(t, first.Equal(entries[2].PublishAt))
+
+	bot.releaseScheduled(first.Add(time.Minute))
+	entries, err = bot.Store.Load(bot.FeedName, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	assert.Equal(t, "v2", entries[0].VideoID, "released entry dated by its time")
+	assert.True(t, entries[0].PublishAt.IsZero())
+	assert.True(t, entries[0].Published.Equal(first))
+	assert.Equal(t, "v3", entries[2].VideoID)
+	assert.False(t, entries[2].PublishAt.IsZero(), "next day still waits")
+
+	bot.handleSchedule(testMessage(testBotUserID, "/schedule 3 now"))
+	entries, err = bot.Store.Load(bot.FeedName, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "v3", entries[1].VideoID)
+	assert.True(t, entries[1].PublishAt.IsZero(), "published right away")
+
+	bot.handleSchedule(testMessage(testBotUserID, "/schedule 1"))
+	assert.Equal(t, scheduleUsage, stub.texts("sendMessage")[2])
+}
@@ -0,0 +1,78 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tb "gopkg.in/tucnak/telebot.v2"
+)
+
+// ErrLinkRejected is returned by AddLink for links and modes it can't take
+var ErrLinkRejected = errors.New("link rejected")
+
+// AddLink takes a link shared from outside of Telegram, e.g. by the share
+// sheet of a phone through the HTTP API. Without a mode the bot asks what to
+// do as for a link sent in the chat, a mode is the action of a menu button
+// (audio, vo, md, notes, audio_notes, tts, read) and starts it right away.
+func (t *TelegramBot) AddLink(_ context.Context, link, mode string) error {
+	if t.extractURL(link) != link {
+		return fmt.Errorf("%w: not a link: %q", ErrLinkRejected, link)
+	}
+	chat := &tb.Chat{ID: t.AllowedUserID}
+	if mode == "" {
+		t.handleText(&tb.Message{Sender: &tb.User{ID: t.AllowedUserID}, Chat: chat, Text: link})
+		return nil
+	}
+
+	pa := t.shareAction(link)
+	if pa == nil {
+		return fmt.Errorf("%w: no mode for %s, share it without one", ErrLinkRejected, link)
+	}
+	if !t.menuHasAction(pa.kind, mode) {
+		return fmt.Errorf("%w: mode %q isn't available for %s", ErrLinkRejected, mode, link)
+	}
+	statusMsg, err := t.Bot.Send(chat, "📲 "+link)
+	if err != nil {
+		return fmt.Errorf("can't send status: %w", err)
+	}
+	t.runAction(chat, statusMsg, pa, mode)
+	return nil
+}
+
+// shareAction makes the action of a shared link the way handleText sorts the
+// links, nil for links that need a question first (playlists, podcast shows)
+func (t *TelegramBot) shareAction(link string) *pendingAction {
+	if ids := t.extractAllYouTubeVideoIDs(link); len(ids) > 0 {
+		return &pendingAction{kind: "yt", videoIDs: ids}
+	}
+	if extractPlaylistURL(link) != "" {
+		return nil
+	}
+	if IsApplePodcastURL(link) {
+		if _, episodeID, err := parseAppleURL(link); err != nil || episodeID == "" {
+			return nil
+		}
+		return &pendingAction{kind: "podcast", url: link}
+	}
+	if (t.TTSEnabled || t.ReadSvc != nil) && IsArticleURL(link) {
+		return &pendingAction{kind: "article", url: link}
+	}
+	return nil
+}
+
+// menuHasAction checks the link menu of the kind has a button of the action
+func (t *TelegramBot) menuHasAction(kind, action string) bool {
+	if action == "cancel" {
+		return false
+	}
+	for _, row := range t.buildActionMenu("", kind).InlineKeyboard {
+		for _, btn := range row {
+			if _, act, _ := strings.Cut(btn.Data, "|"); act == action {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package proc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelegramBot_AddLink(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.TTSEnabled = false
+	ctx := context.Background()
+
+	require.NoError(t, bot.AddLink(ctx, "https://youtu.be/dQw4w9WgXcQ", ""))
+	assert.Equal(t, []string{"🤔 Что сделать со ссылкой?"}, stub.texts("sendMessage"), "menu as for a link sent in the chat")
+
+	for _, tc := range []struct{ link, mode string }{
+		{"youtu.be/dQw4w9WgXcQ", ""},
+		{"see https://youtu.be/dQw4w9WgXcQ", "audio"},
+		{"https://youtu.be/dQw4w9WgXcQ", "bogus"},
+		{"https://youtu.be/dQw4w9WgXcQ", "cancel"},
+		{"https://youtu.be/dQw4w9WgXcQ", "notes"}, // no notes service
+		{"https://www.youtube.com/playlist?list=PL590L5WQmH8fJ54F369BLDSqIwcs-TCfs", "audio"},
+		{"https://example.com/post", "tts"}, // no TTS, no reader
+	} {
+		err := bot.AddLink(ctx, tc.link, tc.mode)
+		assert.ErrorIs(t, err, ErrLinkRejected, "%s %s", tc.link, tc.mode)
+	}
+	assert.Len(t, stub.texts("sendMessage"), 1, "nothing sent for rejected links")
+}
+
+func TestTelegramBot_MenuHasAction(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	assert.True(t, bot.menuHasAction("yt", "audio"))
+	assert.True(t, bot.menuHasAction("yt", "vo"))
+	assert.False(t, bot.menuHasAction("yt", "md"))
+	assert.False(t, bot.menuHasAction("yt", "cancel"))
+	assert.True(t, bot.menuHasAction("article", "tts"))
+	assert.False(t, bot.menuHasAction("article", "vo"))
+	bot.TTSEnabled = false
+	assert.False(t, bot.menuHasAction("article", "tts"))
+}
@@ -0,0 +1,294 @@
+package proc
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	ytstore "github.com/umputun/feed-master/app/youtube/store"
+)
+
+// statsWeeks is how many recent weeks /stats shows
+const statsWeeks = 8
+
+// statsTopSources is how many sources /stats lists
+const statsTopSources = 5
+
+// statsTopEpisodes is how many most downloaded episodes /stats lists
+const statsTopEpisodes = 5
+
+// feedStats is the summary of the history log shown by /stats
+type feedStats struct {
+	total   int
+	seconds int         // audio generated, all time
+	weeks   []weekStat  // last statsWeeks weeks, oldest first
+	methods []countStat // download / tts / vo, most used first
+	sources []countStat // top domains and YouTube channels
+
+	downloads  int         // enclosure downloads, all time
+	dlMethods  []countStat // downloads by download / tts / vo, episodes still in the feed
+	dlEpisodes []countStat // most downloaded episodes still in the feed
+	dlClients  []countStat // podcast apps
+}
+
+type weekStat struct {
+	start   time.Time // monday
+	count   int
+	seconds int
+}
+
+type countStat struct {
+	name  string
+	count int
+}
+
+// handleStats summarizes what was added to the feed (/stats). "/stats chart"
+// sends the weekly hours as a PNG bar chart too.
+func (t *TelegramBot) handleStats(m *tb.Message) {
+	if !t.isAuthorized(m.Sender) {
+		return
+	}
+	history, _, err := t.Store.LoadHistory(t.FeedName, 0, 1<<30)
+	if err != nil {
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("❌ Error loading history: %v"), err))
+		return
+	}
+	if len(history) == 0 {
+		_, _ = t.Bot.Send(m.Chat, t.tr("История пуста, считать нечего"))
+		return
+	}
+	st := t.collectStats(history, time.Now())
+	_, _ = t.Bot.Send(m.Chat, "<pre>"+html.EscapeString(st.text(t.tr))+"</pre>", tb.ModeHTML)
+
+	if commandArgs(m).arg(0) != "chart" {
+		return
+	}
+	chart, err := st.weeklyChart()
+	if err != nil {
+		log.Printf("[WARN] failed to draw stats chart: %v", err)
+		return
+	}
+	photo := &tb.Photo{File: tb.FromReader(bytes.NewReader(chart)),
+		Caption: fmt.Sprintf(t.tr("Часы аудио по неделям с %s"), st.weeks[0].start.Format("02.01.2006"))}
+	if _, err := t.Bot.Send(m.Chat, photo); err != nil {
+		log.Printf("[WARN] failed to send stats chart: %v", err)
+	}
+}
+
+// collectStats counts the history log. Sources of YouTube items are their
+// channels when the episode is still in the feed, domains otherwise.
+func (t *TelegramBot) collectStats(history []ytstore.HistoryEntry, now time.Time) feedStats {
+	channels := map[string]string{}
+	files := map[string]ytfeed.Entry{} // episode by the base name of its file and sped-up copy
+	if entries, err := t.Store.Load(t.FeedName, 0); err == nil {
+		for _, e := range entries {
+			if e.Author.Name != "" {
+				channels[e.VideoID] = e.Author.Name
+			}
+			files[filepath.Base(e.File)] = e
+			if e.SpeedFile != "" {
+				files[filepath.Base(e.SpeedFile)] = e
+			}
+		}
+	}
+
+	st := feedStats{weeks: make([]weekStat, statsWeeks)}
+	thisWeek := weekStart(now)
+	for i := range st.weeks {
+		st.weeks[i].start = thisWeek.AddDate(0, 0, -7*(statsWeeks-1-i))
+	}
+	methods, sources := map[string]int{}, map[string]int{}
+	for _, h := range history {
+		st.total++
+		secs := parseClockDuration(h.Duration)
+		st.seconds += secs
+		week := weekStart(h.Timestamp.In(now.Location()))
+		if w := int(thisWeek.Sub(week).Hours()/24/7 + 0.5); w >= 0 && w < statsWeeks {
+			st.weeks[statsWeeks-1-w].count++
+			st.weeks[statsWeeks-1-w].seconds += secs
+		}
+		methods[statsMethod(h.Action)]++
+		if src := channels[h.VideoID]; src != "" {
+			sources[src]++
+		} else if src := urlDomain(h.URL); src != "" {
+			sources[src]++
+		}
+	}
+	st.methods = topCounts(methods, 0)
+	st.sources = topCounts(sources, statsTopSources)
+
+	downloads, err := t.Store.LoadDownloads()
+	if err != nil {
+		log.Printf("[WARN] failed to load download stats: %v", err)
+	}
+	dlMethods, dlEpisodes, dlClients := map[string]int{}, map[string]int{}, map[string]int{}
+	for _, d := range downloads {
+		st.downloads += d.Count
+		for client, n := range d.Clients {
+			dlClients[client] += n
+		}
+		if e, ok := files[d.File]; ok {
+			dlMethods[entryMethod(e)] += d.Count
+			dlEpisodes[e.Title] += d.Count
+		}
+	}
+	st.dlMethods = topCounts(dlMethods, 0)
+	st.dlEpisodes = topCounts(dlEpisodes, statsTopEpisodes)
+	st.dlClients = topCounts(dlClients, 0)
+	return st
+}
+
+// text renders the stats as a text table in the language of tr
+func (st feedStats) text(tr func(string) string) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, tr("📊 Всего: %d, аудио %.1f ч\n\n"), st.total, float64(st.seconds)/3600)
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, tr("Неделя\tШтук\tЧасов"))
+	for _, wk := range st.weeks {
+		fmt.Fprintf(w, "%s\t%d\t%.1f\n", wk.start.Format("02.01"), wk.count, float64(wk.seconds)/3600)
+	}
+	_ = w.Flush()
+	buf.WriteString(tr("\nСпособ:\n"))
+	for _, m := range st.methods {
+		fmt.Fprintf(&buf, "  %s — %d\n", m.name, m.count)
+	}
+	if len(st.sources) > 0 {
+		buf.WriteString(tr("\nИсточники:\n"))
+		for _, s := range st.sources {
+			fmt.Fprintf(&buf, "  %s — %d\n", s.name, s.count)
+		}
+	}
+	if st.downloads > 0 {
+		fmt.Fprintf(&buf, tr("\n🎧 Скачиваний: %d\n"), st.downloads)
+		for _, group := range []struct {
+			title  string
+			counts []countStat
+		}{{tr("По способу"), st.dlMethods}, {tr("Эпизоды"), st.dlEpisodes}, {tr("Приложения"), st.dlClients}} {
+			if len(group.counts) == 0 {
+				continue
+			}
+			buf.WriteString(group.title + ":\n")
+			for _, c := range group.counts {
+				fmt.Fprintf(&buf, "  %s — %d\n", c.name, c.count)
+			}
+		}
+	}
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+// weeklyChart draws the hours per week as bars, oldest on the left
+func (st feedStats) weeklyChart() ([]byte, error) {
+	const width, height, pad, gap = 640, 320, 20, 10
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	maxSecs := 1
+	for _, wk := range st.weeks {
+		maxSecs = max(maxSecs, wk.seconds)
+	}
+	barWidth := (width-2*pad)/len(st.weeks) - gap
+	bar := image.NewUniform(color.RGBA{R: 0x3b, G: 0x82, B: 0xf6, A: 0xff})
+	for i, wk := range st.weeks {
+		h := (height - 2*pad) * wk.seconds / maxSecs
+		x := pad + i*(barWidth+gap)
+		draw.Draw(img, image.Rect(x, height-pad-h, x+barWidth, height-pad), bar, image.Point{}, draw.Src)
+	}
+	draw.Draw(img, image.Rect(pad, height-pad, width-pad, height-pad+2), image.NewUniform(color.Gray{Y: 0x60}),
+		image.Point{}, draw.Src)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// statsMethod groups history actions into download / tts / vo
+func statsMethod(action string) string {
+	switch action {
+	case "audio":
+		return "download"
+	case "article", "digest":
+		return "tts"
+	case "voiceover":
+		return "vo"
+	}
+	return action
+}
+
+// entryMethod tells download / tts / vo of a feed episode by its kind
+func entryMethod(e ytfeed.Entry) string {
+	switch e.EntryKind() {
+	case ytfeed.KindArticle, ytfeed.KindDigest:
+		return "tts"
+	case ytfeed.KindVoiceover, ytfeed.KindSubtitleTTS:
+		return "vo"
+	}
+	return "download"
+}
+
+// topCounts sorts counts, most first (ties by name), limit 0 = all
+func topCounts(counts map[string]int, limit int) []countStat {
+	res := make([]countStat, 0, len(counts))
+	for name, n := range counts {
+		res = append(res, countStat{name: name, count: n})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].count != res[j].count {
+			return res[i].count > res[j].count
+		}
+		return res[i].name < res[j].name
+	})
+	if limit > 0 && len(res) > limit {
+		res = res[:limit]
+	}
+	return res
+}
+
+// weekStart is the monday 00:00 of the week of ts
+func weekStart(ts time.Time) time.Time {
+	y, m, d := ts.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, ts.Location())
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// parseClockDuration reads "m:ss" and "h:mm:ss" as written by formatDuration
+// into seconds, 0 if unparsable
+func parseClockDuration(s string) int {
+	if s == "" {
+		return 0
+	}
+	secs := 0
+	for _, p := range strings.Split(s, ":") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return 0
+		}
+		secs = secs*60 + n
+	}
+	return secs
+}
+
+// urlDomain is the host of the URL without "www.", "" if there is none
+func urlDomain(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
@@ -0,0 +1,91 @@
+package proc
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	ytstore "github.com/umputun/feed-master/app/youtube/store"
+)
+
+func TestTelegramBot_CollectStats(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	bot.Store = newTestJobStore(t)
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) // friday
+	vid := ytfeed.Entry{ChannelID: bot.FeedName, VideoID: "v1", Published: now}
+	vid.Author.Name = "Go Channel"
+	_, err := bot.Store.Save(vid)
+	require.NoError(t, err)
+
+	history := []ytstore.HistoryEntry{
+		{Timestamp: now.Add(-time.Hour), Action: "audio", VideoID: "v1", URL: "https://youtube.com/watch?v=v1", Duration: "1:00:00"},
+		{Timestamp: now.AddDate(0, 0, -4), Action: "article", URL: "https://www.example.com/a", Duration: "30:00"},
+		{Timestamp: now.AddDate(0, 0, -8), Action: "article", URL: "https://example.com/b", Duration: "15:00"},
+		{Timestamp: now.AddDate(0, 0, -9), Action: "voiceover", VideoID: "gone", URL: "https://youtube.com/watch?v=gone"},
+		{Timestamp: now.AddDate(-1, 0, 0), Action: "digest", Duration: "bad"},
+	}
+	st := bot.collectStats(history, now)
+
+	assert.Equal(t, 5, st.total)
+	assert.Equal(t, 6300, st.seconds)
+	require.Len(t, st.weeks, statsWeeks)
+	assert.Equal(t, time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), st.weeks[statsWeeks-1].start)
+	assert.Equal(t, weekStat{start: st.weeks[statsWeeks-1].start, count: 2, seconds: 5400}, st.weeks[statsWeeks-1])
+	assert.Equal(t, 2, st.weeks[statsWeeks-2].count)
+	assert.Equal(t, []countStat{{"tts", 3}, {"download", 1}, {"vo", 1}}, st.methods)
+	assert.Equal(t, []countStat{{"example.com", 2}, {"Go Channel", 1}, {"youtube.com", 1}}, st.sources)
+
+	text := st.text((&TelegramBot{}).tr)
+	assert.Contains(t, text, "Всего: 5, аудио 1.8 ч")
+	assert.Contains(t, text, "12.10   2     1.5")
+	assert.Contains(t, text, "tts — 3")
+
+	chart, err := st.weeklyChart()
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(chart))
+	require.NoError(t, err)
+	assert.Equal(t, 640, img.Bounds().Dx())
+}
+
+func TestTelegramBot_CollectStatsDownloads(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	bot.Store = newTestJobStore(t)
+	now := time.Now()
+	for i, e := range []ytfeed.Entry{
+		{ChannelID: bot.FeedName, VideoID: "v1", Title: "Video", File: "/srv/yt/v1.mp3", SpeedFile: "/srv/yt/v1.x1.5.mp3"},
+		{ChannelID: bot.FeedName, VideoID: "art_123", Title: "Article", File: "/srv/yt/art_123.mp3"},
+		{ChannelID: bot.FeedName, VideoID: "vo_v2", Title: "Voiceover", File: "/srv/yt/vo_v2.mp3"},
+	} {
+		e.Published = now.Add(time.Duration(i) * time.Minute)
+		_, err := bot.Store.Save(e)
+		require.NoError(t, err)
+	}
+	for _, d := range []struct{ file, client string }{
+		{"v1.mp3", "Overcast"}, {"v1.x1.5.mp3", "Overcast"}, {"art_123.mp3", "Apple Podcasts"},
+		{"art_123.mp3", "Overcast"}, {"art_123.mp3", "Overcast"}, {"gone.mp3", "other"},
+	} {
+		require.NoError(t, bot.Store.RecordDownload(d.file, d.client))
+	}
+
+	st := bot.collectStats([]ytstore.HistoryEntry{{Timestamp: now, Action: "audio"}}, now)
+	assert.Equal(t, 6, st.downloads, "deleted episodes counted in the total")
+	assert.Equal(t, []countStat{{"tts", 3}, {"download", 2}}, st.dlMethods)
+	assert.Equal(t, []countStat{{"Article", 3}, {"Video", 2}}, st.dlEpisodes, "sped-up copy counts for its episode")
+	assert.Equal(t, []countStat{{"Overcast", 4}, {"Apple Podcasts", 1}, {"other", 1}}, st.dlClients)
+	text := st.text((&TelegramBot{}).tr)
+	assert.Contains(t, text, "Скачиваний: 6")
+	assert.Contains(t, text, "Article — 3")
+	assert.NotContains(t, text, "Voiceover", "never downloaded")
+}
+
+func TestParseClockDuration(t *testing.T) {
+	assert.Equal(t, 125, parseClockDuration("2:05"))
+	assert.Equal(t, 3723, parseClockDuration("1:02:03"))
+	assert.Equal(t, 0, parseClockDuration(""))
+	assert.Equal(t, 0, parseClockDuration("1m"))
+}
@@ -0,0 +1,108 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// verifyReport is the outcome of checking the feed files
+type verifyReport struct {
+	total      int
+	ok         int
+	backfilled int      // entries without recorded checksum, recorded now
+	remote     int      // no local file, expected with R2 offload
+	problems   []string // human-readable lines for broken entries
+}
+
+// handleVerify checks every episode file of the bot feed against the size and
+// checksum recorded on save (/verify). Entries saved before checksums existed
+// get them recorded, so the next run can catch corruption.
+func (t *TelegramBot) handleVerify(m *tb.Message) {
+	if !t.isAuthorized(m.Sender) {
+		return
+	}
+	statusMsg, _ := t.Bot.Send(m.Chat, t.tr("🔍 Проверяю файлы ленты..."))
+	t.goJob(verifyJobTimeout, func(ctx context.Context) {
+		rep, err := t.verifyFeed(ctx)
+		if err != nil {
+			t.edit(statusMsg, fmt.Sprintf(t.tr("❌ Error: %v"), err))
+			return
+		}
+		t.edit(statusMsg, rep.text(t.tr))
+	})
+}
+
+// verifyFeed checks all entries of the bot feed
+func (t *TelegramBot) verifyFeed(ctx context.Context) (verifyReport, error) {
+	var rep verifyReport
+	entries, err := t.Store.Load(t.FeedName, 0)
+	if err != nil {
+		return rep, fmt.Errorf("failed to load feed: %w", err)
+	}
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return rep, err
+		}
+		rep.total++
+		status, verr := e.VerifyIntegrity()
+		if verr != nil {
+			rep.problems = append(rep.problems, fmt.Sprintf(t.tr("%s — ошибка проверки: %v"), e.Title, verr))
+			continue
+		}
+		switch status {
+		case ytfeed.IntegrityOK:
+			rep.ok++
+		case ytfeed.IntegrityUnknown:
+			if serr := e.SetIntegrity(); serr != nil {
+				rep.problems = append(rep.problems, fmt.Sprintf(t.tr("%s — не удалось посчитать сумму: %v"), e.Title, serr))
+				continue
+			}
+			if uerr := t.Store.UpdateEntry(e); uerr != nil {
+				log.Printf("[WARN] failed to save checksum of %s: %v", e.VideoID, uerr)
+			}
+			rep.backfilled++
+		case ytfeed.IntegrityMissing:
+			if t.Media != nil {
+				rep.remote++ // offloaded episodes have no local copy by design
+				continue
+			}
+			rep.problems = append(rep.problems, e.Title+t.tr(" — файл отсутствует"))
+		case ytfeed.IntegritySize:
+			rep.problems = append(rep.problems, e.Title+t.tr(" — размер не совпадает (файл обрезан?)"))
+		case ytfeed.IntegrityChecksum:
+			rep.problems = append(rep.problems, e.Title+t.tr(" — контрольная сумма не совпадает"))
+		}
+	}
+	return rep, nil
+}
+
+// text renders the report for the chat in the language of tr
+func (r verifyReport) text(tr func(string) string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, tr("🔍 Проверено эпизодов: %d\n✅ в порядке: %d\n"), r.total, r.ok)
+	if r.backfilled > 0 {
+		fmt.Fprintf(&b, tr("🆕 записаны контрольные суммы: %d\n"), r.backfilled)
+	}
+	if r.remote > 0 {
+		fmt.Fprintf(&b, tr("☁️ не на диске (в R2): %d\n"), r.remote)
+	}
+	if len(r.problems) == 0 {
+		return b.String()
+	}
+	fmt.Fprintf(&b, tr("\n❌ Проблемы (%d):\n"), len(r.problems))
+	for i, p := range r.problems {
+		if i == 20 {
+			fmt.Fprintf(&b, tr("…и ещё %d\n"), len(r.problems)-i)
+			break
+		}
+		b.WriteString("• " + p + "\n")
+	}
+	b.WriteString(tr("Удалить битые: /list → 🗑, затем отправить ссылки заново."))
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package proc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+func TestTelegramBot_VerifyFeed(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	bot.Store = newTestJobStore(t)
+
+	addEpisode := func(id, content string) string {
+		file := filepath.Join(bot.FilesLocation, id+".mp3")
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+		_, err := bot.saveEntry(context.Background(), ytfeed.Entry{ChannelID: bot.FeedName, VideoID: id, Title: "title " + id, File: file,
+			Published: time.Now()})
+		require.NoError(t, err)
+		return file
+	}
+	addEpisode("ok", "fine audio")
+	truncated := addEpisode("trunc", "full audio")
+	corrupted := addEpisode("corrupt", "good bytes")
+	missing := addEpisode("missing", "gone")
+
+	// legacy entry saved without checksum
+	legacy := filepath.Join(bot.FilesLocation, "legacy.mp3")
+	require.NoError(t, os.WriteFile(legacy, []byte("old"), 0o600))
+	_, err := bot.Store.Save(ytfeed.Entry{ChannelID: bot.FeedName, VideoID: "legacy", Title: "title legacy", File: legacy,
+		Published: time.Now()})
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(truncated, []byte("full"), 0o600))
+	require.NoError(t, os.WriteFile(corrupted, []byte("evil bytes"), 0o600))
+	require.NoError(t, os.Remove(missing))
+
+	rep, err := bot.verifyFeed(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5, rep.total)
+	assert.Equal(t, 1, rep.ok)
+	assert.Equal(t, 1, rep.backfilled)
+	assert.ElementsMatch(t, []string{
+		"title trunc — размер не совпадает (файл обрезан?)",
+		"title corrupt — контрольная сумма не совпадает",
+		"title missing — файл отсутствует",
+	}, rep.problems)
+	assert.Contains(t, rep.text((&TelegramBot{}).tr), "❌ Проблемы (3)")
+
+	rep, err = bot.verifyFeed(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, rep.ok, "legacy entry got its checksum recorded")
+	assert.Zero(t, rep.backfilled)
+
+	// with R2 offload a missing local file is expected
+	bot.Media = &mockOffloader{}
+	rep, err = bot.verifyFeed(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, rep.remote)
+	assert.Len(t, rep.problems, 2)
+}
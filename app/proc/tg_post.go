@@ -0,0 +1,114 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	log "github.com/go-pkgz/lgr"
+	"golang.org/x/net/html"
+)
+
+// telegramPostBase is where public channel posts are read from (var for tests)
+var telegramPostBase = "https://t.me"
+
+var tgPostPathRe = regexp.MustCompile(`^/(?:s/)?([A-Za-z][A-Za-z0-9_]{3,31})/(\d+)/?$`)
+
+// telegramPostURL tells whether the URL is a post of a public channel,
+// t.me/channel/123, and returns the channel and the post id
+func telegramPostURL(rawURL string) (channel, id string) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", ""
+	}
+	switch strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.") {
+	case "t.me", "telegram.me":
+		if m := tgPostPathRe.FindStringSubmatch(u.Path); m != nil {
+			return m[1], m[2]
+		}
+	}
+	return "", ""
+}
+
+// extractTelegramPost reads the post off its embed widget page, the regular
+// preview page has only a short og:description. For a link post the linked
+// article is voiced after the post text.
+func (e *ArticleExtractor) extractTelegramPost(ctx context.Context, rawURL, channel, id string) (*Article, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, telegramPostBase+"/"+channel+"/"+id+"?embed=1&mode=tme", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create telegram request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	resp, err := e.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("telegram request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("telegram HTTP error: %d", resp.StatusCode)
+	}
+	doc, err := html.Parse(io.LimitReader(resp.Body, 5*1024*1024))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse telegram page: %w", err)
+	}
+	if n := findClass(doc, "tgme_widget_message_error"); n != nil {
+		return nil, fmt.Errorf("telegram: %s", strings.TrimSpace(nodeText(n)))
+	}
+
+	var text string
+	if n := findClass(doc, "tgme_widget_message_text"); n != nil {
+		// bare links read aloud are noise, links with a caption stay as the caption
+		text = postText(n, func(a *html.Node) bool {
+			caption := strings.TrimSpace(nodeText(a))
+			return strings.HasPrefix(caption, "http") || strings.HasPrefix(attr(a, "href"), caption)
+		})
+	}
+	channelName := "@" + channel
+	if n := findClass(doc, "tgme_widget_message_owner_name"); n != nil {
+		channelName = strings.TrimSpace(nodeText(n))
+	}
+	res := &Article{Title: firstLine(text, 80), TextContent: text, SiteName: channelName, URL: rawURL}
+
+	if n := findClass(doc, "tgme_widget_message_link_preview"); n != nil {
+		if link := attr(n, "href"); link != "" {
+			if linked, err := e.extractLinked(ctx, link); err != nil {
+				log.Printf("[WARN] telegram post %s/%s: linked article %s not extracted: %v", channel, id, link, err)
+			} else {
+				res.Title = linked.Title
+				res.TextContent = strings.TrimSpace(text + "\n\n" + linked.BlockText())
+				res.Image = linked.Image
+			}
+		}
+	}
+
+	res.TextContent = cleanText(res.TextContent)
+	if res.TextContent == "" {
+		return nil, fmt.Errorf("telegram post %s/%s has no text", channel, id)
+	}
+	if res.Title == "" {
+		res.Title = channelName
+	}
+	return res, nil
+}
+
+// extractLinked extracts the article a post links to, a link to another
+// post isn't followed to avoid chains
+func (e *ArticleExtractor) extractLinked(ctx context.Context, link string) (*Article, error) {
+	if ch, _ := telegramPostURL(link); ch != "" {
+		return nil, fmt.Errorf("link to another post")
+	}
+	return e.Extract(ctx, link)
+}
+
+// firstLine returns the first line of text cut to maxLen runes
+func firstLine(text string, maxLen int) string {
+	line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(text), "\n", 2)[0])
+	if r := []rune(line); len(r) > maxLen {
+		line = strings.TrimSpace(string(r[:maxLen])) + "…"
+	}
+	return line
+}
@@ -0,0 +1,85 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelegramPostURL(t *testing.T) {
+	tbl := []struct {
+		url         string
+		channel, id string
+	}{
+		{"https://t.me/durov/123", "durov", "123"},
+		{"https://t.me/s/durov/123", "durov", "123"},
+		{"https://telegram.me/durov/123?single", "durov", "123"},
+		{"https://t.me/durov", "", ""},
+		{"https://t.me/c/1234567/89", "", ""},
+		{"https://t.me/joinchat/abcdef", "", ""},
+		{"https://example.com/durov/123", "", ""},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.url, func(t *testing.T) {
+			channel, id := telegramPostURL(tt.url)
+			assert.Equal(t, tt.channel, channel)
+			assert.Equal(t, tt.id, id)
+		})
+	}
+}
+
+func TestExtractTelegramPost(t *testing.T) {
+	article := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Linked article</title></head><body><article><h1>Linked article</h1>
+<p>The linked article has a long first paragraph that readability keeps as the main content of the page.</p>
+<p>And a second paragraph with more words so the page is not considered too short to be an article.</p>
+</article></body></html>`))
+	}))
+	defer article.Close()
+
+	posts := map[string]string{
+		"/news/1": `<div class="tgme_widget_message"><div class="tgme_widget_message_author"><a class="tgme_widget_message_owner_name" href="https://t.me/news"><span>Канал новостей</span></a></div>
+<div class="tgme_widget_message_text">Первая строка поста<br>Подробности <a href="https://example.com/x">здесь</a>: <a href="https://example.com/raw">https://example.com/raw</a></div></div>`,
+		"/news/2": fmt.Sprintf(`<div class="tgme_widget_message"><a class="tgme_widget_message_owner_name" href="https://t.me/news">Канал новостей</a>
+<div class="tgme_widget_message_text">Интересно, почитайте</div>
+<a class="tgme_widget_message_link_preview" href="%s/post"><div class="link_preview_title">Linked article</div></a></div>`, article.URL),
+		"/news/3": `<div class="tgme_widget_message_error">Post not found</div>`,
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1", r.URL.Query().Get("embed"))
+		_, _ = w.Write([]byte("<html><body>" + posts[r.URL.Path] + "</body></html>"))
+	}))
+	defer ts.Close()
+	oldBase := telegramPostBase
+	telegramPostBase = ts.URL
+	defer func() { telegramPostBase = oldBase }()
+
+	e := NewArticleExtractor()
+
+	t.Run("text post", func(t *testing.T) {
+		a, err := e.Extract(context.Background(), "https://t.me/news/1")
+		require.NoError(t, err)
+		assert.Equal(t, "Первая строка поста", a.Title)
+		assert.Equal(t, "Канал новостей", a.SiteName)
+		assert.Equal(t, "Первая строка поста\nПодробности здесь:", a.TextContent)
+	})
+
+	t.Run("link post", func(t *testing.T) {
+		a, err := e.Extract(context.Background(), "https://t.me/news/2")
+		require.NoError(t, err)
+		assert.Equal(t, "Linked article", a.Title)
+		assert.Equal(t, "https://t.me/news/2", a.URL)
+		assert.Contains(t, a.TextContent, "Интересно, почитайте\n")
+		assert.Contains(t, a.TextContent, "second paragraph with more words")
+	})
+
+	t.Run("missing post", func(t *testing.T) {
+		_, err := e.Extract(context.Background(), "https://t.me/news/3")
+		require.EqualError(t, err, "telegram: Post not found")
+	})
+}
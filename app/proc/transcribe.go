@@ -10,12 +10,13 @@ import (
 	"mime/multipart"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"sync"
 	"time"
+
+	"github.com/umputun/feed-master/app/tools"
 )
 
 // groqAPIBase is the default OpenAI-compatible Groq endpoint
@@ -29,6 +30,7 @@ type TranscribeService struct {
 	BaseURL      string
 	ChunkSeconds int
 	DurationSvc  DurationService
+	Diarizer     *Diarizer // labels the speakers of the segments, nil = no labels
 	client       *http.Client
 }
 
@@ -52,9 +54,10 @@ func NewTranscribeService(apiKey, model string, chunkSeconds int, dur DurationSe
 
 // TranscriptSegment is one Whisper segment with absolute timestamps
 type TranscriptSegment struct {
-	Start float64
-	End   float64
-	Text  string
+	Start   float64
+	End     float64
+	Text    string
+	Speaker string // "Speaker 1", "Speaker 2"... when diarized, "" = unknown
 }
 
 // Transcript is the assembled result over all chunks
@@ -121,22 +124,47 @@ func (s *TranscribeService) Transcribe(ctx context.Context, audioPath string, pr
 		}
 	}
 	res.DurationSec = offset
+	s.diarize(ctx, audioPath, res)
 	return res, nil
 }
 
+// diarize labels the speakers of the transcript, best-effort: a failed
+// diarization leaves the transcript without labels
+func (s *TranscribeService) diarize(ctx context.Context, audioPath string, tr *Transcript) {
+	if s.Diarizer == nil {
+		return
+	}
+	turns, err := s.Diarizer.Diarize(ctx, audioPath)
+	if err != nil {
+		log.Printf("[WARN] no speaker labels for %s: %v", filepath.Base(audioPath), err)
+		return
+	}
+	labelSpeakers(tr.Segments, turns)
+}
+
 // chunkAudio splits audio into ChunkSeconds-long mono 16kHz mp3 pieces.
 // 10 min @ 48kbps mono is ~3.6MB, well under Groq's 25MB request limit.
 func (s *TranscribeService) chunkAudio(ctx context.Context, audioPath, workDir string) ([]string, error) {
+	release, err := tools.Acquire(ctx, tools.ClassTranscode)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 	ffmpegCtx, cancel := context.WithTimeout(ctx, 30*time.Minute)
 	defer cancel()
 
 	outPattern := filepath.Join(workDir, "chunk-%04d.mp3")
-	cmd := exec.CommandContext(ffmpegCtx, "ffmpeg", "-nostdin", "-i", audioPath,
+	cmd := tools.Command(ffmpegCtx, "ffmpeg", "-nostdin", "-i", audioPath,
 		"-ar", "16000", "-ac", "1", "-b:a", "48k",
 		"-f", "segment", "-segment_time", strconv.Itoa(s.ChunkSeconds), outPattern)
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
+	cleanup, err := tools.Prepare(ffmpegCtx, cmd)
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
 	log.Printf("[DEBUG] chunking audio %s into %ds segments", audioPath, s.ChunkSeconds)
 	if err := cmd.Run(); err != nil {
 		return nil, fmt.Errorf("ffmpeg failed: %w, stderr: %s", err, lastLines(stderr.String(), 5))
@@ -218,17 +246,17 @@ func captureRateHeaders(h http.Header) {
 }
 
 // llmRateLine renders the /status line ("" until the first LLM call)
-func llmRateLine() string {
+func (t *TelegramBot) llmRateLine() string {
 	llmRate.mu.Lock()
 	defer llmRate.mu.Unlock()
 	if llmRate.remaining == "" {
 		return ""
 	}
-	age := "только что"
+	age := t.tr("только что")
 	if d := time.Since(llmRate.at); d > time.Minute {
-		age = fmt.Sprintf("%d мин назад", int(d.Minutes()))
+		age = fmt.Sprintf(t.tr("%d мин назад"), int(d.Minutes()))
 	}
-	return fmt.Sprintf("🧠 LLM: осталось %s из %s токенов (%s)", llmRate.remaining, llmRate.limit, age)
+	return fmt.Sprintf(t.tr("🧠 LLM: осталось %s из %s токенов (%s)"), llmRate.remaining, llmRate.limit, age)
 }
 
 // doWithRetry executes an HTTP request built by build, retrying on 429 and 5xx.
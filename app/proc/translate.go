@@ -5,19 +5,43 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"html"
 	"io"
 	"net/http"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 	"unicode"
+	"unicode/utf8"
+
+	log "github.com/go-pkgz/lgr"
+
+	"github.com/umputun/feed-master/app/tracing"
+	ytstore "github.com/umputun/feed-master/app/youtube/store"
 )
 
+// TranslationProvider translates text into the provider's target language
+type TranslationProvider interface {
+	NeedsTranslation(text string) bool
+	Translate(ctx context.Context, text string) (string, error)
+}
+
 // Translator handles text translation using Yandex Translate API
 type Translator struct {
+	Glossary           func() ([]ytstore.GlossaryTerm, error) // user glossary, terms found in a chunk go with its request
+	SourceLang         string                                 // language of the texts, "" = detected once per text
+	PreserveFormatting bool                                   // paragraphs and line breaks go as HTML markup
+	Speller            bool                                   // fix typos of the source first
+
 	apiKey     string
+	iam        *iamTokenSource // service account auth, used instead of apiKey when set
 	targetLang string
 	folderID   string
 	client     *http.Client
+	breaker    *translateBreaker // shared by the copies of the translator
+	backoff    time.Duration     // first retry delay, 0 = 2s
+	pause      time.Duration     // pause of an open breaker, 0 = translatePause
 }
 
 // NewTranslator creates a new translator instance
@@ -29,6 +53,7 @@ func NewTranslator(targetLang string) *Translator {
 	return &Translator{
 		apiKey:     "", // will be set via SetAPIKey
 		targetLang: targetLang,
+		breaker:    &translateBreaker{},
 		client: &http.Client{
 			Timeout: 60 * time.Second,
 		},
@@ -44,19 +69,79 @@ func NewTranslatorWithKey(apiKey, folderID, targetLang string) *Translator {
 		apiKey:     apiKey,
 		folderID:   folderID,
 		targetLang: targetLang,
+		breaker:    &translateBreaker{},
 		client: &http.Client{
 			Timeout: 60 * time.Second,
 		},
 	}
 }
 
+// SetServiceAccountKey authenticates requests with IAM tokens of a service
+// account instead of the API key, the tokens got by its authorized key file
+// (yc iam key create) and refreshed hourly
+func (t *Translator) SetServiceAccountKey(keyFile string) error {
+	iam, err := newIAMTokenSource(keyFile)
+	if err != nil {
+		return err
+	}
+	t.iam = iam
+	return nil
+}
+
+// authorize sets the Authorization header of a Yandex API request, a bearer
+// IAM token with a service account key, the API key otherwise
+func (t *Translator) authorize(ctx context.Context, req *http.Request) error {
+	if t.iam == nil {
+		req.Header.Set("Authorization", "Api-Key "+t.apiKey)
+		return nil
+	}
+	token, err := t.iam.Token(ctx, t.client)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
 // yandexRequest is the request body for Yandex Translate API
 type yandexRequest struct {
-	FolderID           string   `json:"folderId"`
-	TargetLanguageCode string   `json:"targetLanguageCode"`
-	Texts              []string `json:"texts"`
+	FolderID           string                `json:"folderId"`
+	SourceLanguageCode string                `json:"sourceLanguageCode,omitempty"` // required with a glossary
+	TargetLanguageCode string                `json:"targetLanguageCode"`
+	Format             string                `json:"format,omitempty"` // PLAIN_TEXT or HTML
+	Texts              []string              `json:"texts"`
+	Speller            bool                  `json:"speller,omitempty"`
+	GlossaryConfig     *yandexGlossaryConfig `json:"glossaryConfig,omitempty"`
+}
+
+// yandexDetectRequest is the request body of the language detection API
+type yandexDetectRequest struct {
+	FolderID          string   `json:"folderId"`
+	Text              string   `json:"text"`
+	LanguageCodeHints []string `json:"languageCodeHints,omitempty"`
+}
+
+// yandexDetectResponse is the response of the language detection API
+type yandexDetectResponse struct {
+	LanguageCode string `json:"languageCode"`
+}
+
+// yandexGlossaryConfig passes glossary pairs inline with the request
+type yandexGlossaryConfig struct {
+	GlossaryData struct {
+		GlossaryPairs []yandexGlossaryPair `json:"glossaryPairs"`
+	} `json:"glossaryData"`
+}
+
+// yandexGlossaryPair is a term and how to translate it
+type yandexGlossaryPair struct {
+	SourceText     string `json:"sourceText"`
+	TranslatedText string `json:"translatedText"`
 }
 
+// maxGlossaryPairs is the Yandex limit of inline glossary pairs per request
+const maxGlossaryPairs = 50
+
 // yandexResponse is the response from Yandex Translate API
 type yandexResponse struct {
 	Translations []struct {
@@ -99,51 +184,62 @@ func (t *Translator) NeedsTranslation(text string) bool {
 }
 
 // Translate translates text to target language
-func (t *Translator) Translate(ctx context.Context, text string) (string, error) {
-	sourceLang := DetectLanguage(text)
-	if sourceLang == t.targetLang {
+func (t *Translator) Translate(ctx context.Context, text string) (res string, err error) {
+	ctx, span := tracing.Start(ctx, "translate", "chars", len([]rune(text)))
+	defer func() { span.Finish(err) }()
+	if DetectLanguage(text) == t.targetLang {
 		return text, nil // already in target language
 	}
-
-	// Split into chunks if text is too long (Yandex limit is 10000 bytes, UTF-8 up to 4 bytes/char)
-	const maxChunkSize = 2000
-	if len(text) <= maxChunkSize {
-		return t.translateChunk(ctx, text, sourceLang)
+	sourceLang := t.sourceLanguage(ctx, text)
+	if sourceLang == t.targetLang {
+		return text, nil
 	}
 
-	// Split by paragraphs and translate
-	chunks := splitTextForTranslation(text, maxChunkSize)
-	var result strings.Builder
-
-	for i, chunk := range chunks {
+	// Sentences go as texts[] of a request, a translation per sentence, so
+	// none is dropped or merged at the joins. A request takes as many
+	// sentences as fit the Yandex limit of 10000 characters of all its texts
+	// (markup included), with a margin.
+	const maxSentenceSize, maxBatchChars = 2000, 9000
+	segs := sentenceSegments(text, maxSentenceSize)
+	batches := sentenceBatches(segs, maxBatchChars, func(s string) int {
+		return utf8.RuneCountInString(t.requestText(s))
+	})
+
+	for i, batch := range batches {
 		select {
 		case <-ctx.Done():
 			return "", ctx.Err()
 		default:
 		}
 
-		translated, err := t.translateChunk(ctx, chunk, sourceLang)
+		texts := make([]string, len(batch))
+		for j, idx := range batch {
+			texts[j] = segs[idx].text
+		}
+		translated, err := t.translateBatchRetry(ctx, texts, sourceLang)
 		if err != nil {
 			return "", fmt.Errorf("failed to translate chunk %d: %w", i, err)
 		}
-		result.WriteString(translated)
-
-		// Small delay between requests
-		if i < len(chunks)-1 {
-			time.Sleep(500 * time.Millisecond)
+		for j, idx := range batch {
+			segs[idx].text = translated[j]
 		}
 	}
 
+	var result strings.Builder
+	for _, seg := range segs {
+		result.WriteString(seg.text + seg.sep)
+	}
 	return result.String(), nil
 }
 
-// translateChunk translates a single chunk of text using Yandex Translate API
-func (t *Translator) translateChunk(ctx context.Context, text, sourceLang string) (string, error) {
-	if t.apiKey == "" {
-		return "", fmt.Errorf("Yandex Translate API key not configured (set YANDEX_TRANSLATE_KEY)")
+// translateBatch translates texts with a single Yandex Translate API request,
+// a translation per text in order
+func (t *Translator) translateBatch(ctx context.Context, texts []string, sourceLang string) ([]string, error) {
+	if t.apiKey == "" && t.iam == nil {
+		return nil, fmt.Errorf("Yandex Translate API key not configured (set YANDEX_TRANSLATE_KEY or translation.sa_key_file)")
 	}
 	if t.folderID == "" {
-		return "", fmt.Errorf("Yandex Folder ID not configured (set YANDEX_FOLDER_ID)")
+		return nil, fmt.Errorf("Yandex Folder ID not configured (set YANDEX_FOLDER_ID)")
 	}
 
 	// Yandex Translate API endpoint
@@ -151,74 +247,308 @@ func (t *Translator) translateChunk(ctx context.Context, text, sourceLang string
 
 	reqBody := yandexRequest{
 		FolderID:           t.folderID,
+		SourceLanguageCode: sourceLang,
 		TargetLanguageCode: t.targetLang,
-		Texts:              []string{text},
+		Format:             "PLAIN_TEXT",
+		Texts:              texts,
+		Speller:            t.Speller,
+	}
+	if t.PreserveFormatting {
+		reqBody.Format, reqBody.Texts = "HTML", make([]string, len(texts))
+		for i, text := range texts {
+			reqBody.Texts[i] = t.requestText(text)
+		}
+	}
+	joined := strings.Join(texts, " ")
+	if pairs := t.glossaryPairs(joined); len(pairs) > 0 {
+		if reqBody.SourceLanguageCode == "" {
+			reqBody.SourceLanguageCode = DetectLanguage(joined) // a glossary needs the source
+		}
+		reqBody.GlossaryConfig = &yandexGlossaryConfig{}
+		reqBody.GlossaryConfig.GlossaryData.GlossaryPairs = pairs
 	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Api-Key "+t.apiKey)
+	if err := t.authorize(ctx, req); err != nil {
+		return nil, err
+	}
 
 	resp, err := t.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		// Read raw body for debugging
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("Yandex API error (status %d): %s", resp.StatusCode, string(bodyBytes))
+		if resp.StatusCode == http.StatusBadRequest && reqBody.GlossaryConfig != nil {
+			// a language pair without glossary support or a wrongly detected source, the glossary is a nicety
+			log.Printf("[WARN] translation with glossary rejected, retrying without: %s", string(bodyBytes))
+			return t.withoutGlossary().translateBatch(ctx, texts, sourceLang)
+		}
+		return nil, &translateStatusError{status: resp.StatusCode, retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+			body: string(bodyBytes)}
 	}
 
 	var result yandexResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	if len(result.Translations) == 0 {
-		return "", fmt.Errorf("no translations returned")
+	if len(result.Translations) != len(texts) {
+		return nil, fmt.Errorf("%d translations returned for %d texts", len(result.Translations), len(texts))
 	}
 
-	return result.Translations[0].Text, nil
+	res := make([]string, len(texts))
+	for i, tr := range result.Translations {
+		res[i] = tr.Text
+		if t.PreserveFormatting {
+			res[i] = htmlParagraphs(tr.Text)
+		}
+	}
+	return res, nil
 }
 
-// splitTextForTranslation splits text into chunks, respecting maxSize limit
-func splitTextForTranslation(text string, maxSize int) []string {
-	if len(text) <= maxSize {
-		return []string{text}
+// requestText is the text as sent to the API, HTML markup of it with
+// PreserveFormatting
+func (t *Translator) requestText(text string) string {
+	if t.PreserveFormatting {
+		return paragraphsHTML(text)
 	}
+	return text
+}
 
-	var chunks []string
-	var current strings.Builder
+// textSegment is a sentence of a text and the whitespace following it
+type textSegment struct {
+	text, sep string
+}
 
-	// Split by sentences (. ! ? followed by space or newline)
-	words := strings.Fields(text)
+// sentenceSegments splits text into sentences, each with the whitespace (line
+// and paragraph breaks included) up to the next one, so the translations put
+// back together keep the layout. A sentence ends at . ! ? … followed by
+// whitespace (closing quotes and brackets go with it) or at a line break. A
+// sentence over maxSize is split at words. Leading whitespace is a segment
+// without text.
+func sentenceSegments(text string, maxSize int) []textSegment {
+	var segs []textSegment
+	rs := []rune(text)
+	i := 0
+	for i < len(rs) && unicode.IsSpace(rs[i]) {
+		i++
+	}
+	if i > 0 {
+		segs = append(segs, textSegment{sep: string(rs[:i])})
+	}
+	add := func(sentence, sep string) {
+		if len(sentence) <= maxSize {
+			segs = append(segs, textSegment{text: sentence, sep: sep})
+			return
+		}
+		parts := chunkParagraphs(sentence, maxSize)
+		for j, part := range parts {
+			segs = append(segs, textSegment{text: strings.TrimSpace(part), sep: " "})
+			if j == len(parts)-1 {
+				segs[len(segs)-1].sep = sep
+			}
+		}
+	}
+	for start := i; i < len(rs); {
+		end := -1
+		switch {
+		case rs[i] == '\n':
+			end = i
+		case strings.ContainsRune(".!?…", rs[i]):
+			j := i + 1
+			for j < len(rs) && strings.ContainsRune(".!?…\"'»”)]", rs[j]) {
+				j++
+			}
+			if j == len(rs) || unicode.IsSpace(rs[j]) {
+				end = j
+			}
+			i = j - 1
+		}
+		if end < 0 && i == len(rs)-1 {
+			end = len(rs)
+		}
+		if end < 0 {
+			i++
+			continue
+		}
+		k := end
+		for k < len(rs) && unicode.IsSpace(rs[k]) {
+			k++
+		}
+		sentence := strings.TrimRightFunc(string(rs[start:end]), unicode.IsSpace)
+		add(sentence, string(rs[start:end])[len(sentence):]+string(rs[end:k]))
+		start, i = k, k
+	}
+	return segs
+}
 
-	for _, word := range words {
-		// If adding this word would exceed limit, start new chunk
-		if current.Len()+len(word)+1 > maxSize && current.Len() > 0 {
-			chunks = append(chunks, strings.TrimSpace(current.String()))
-			current.Reset()
+// sentenceBatches groups the segments with text into batches of up to
+// maxSize in total, as measured by size, returning their indexes
+func sentenceBatches(segs []textSegment, maxSize int, size func(string) int) [][]int {
+	var batches [][]int
+	var batch []int
+	total := 0
+	for i, seg := range segs {
+		if seg.text == "" {
+			continue
 		}
-		if current.Len() > 0 {
-			current.WriteString(" ")
+		n := size(seg.text)
+		if len(batch) > 0 && total+n > maxSize {
+			batches = append(batches, batch)
+			batch, total = nil, 0
 		}
-		current.WriteString(word)
+		batch = append(batch, i)
+		total += n
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// sourceLangKey is the context key of the source language of a text
+// translated chunk by chunk
+type sourceLangKey struct{}
+
+// withSourceLang returns the context translating with the given source
+// language, detected once for all the chunks of a text
+func withSourceLang(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, sourceLangKey{}, lang)
+}
+
+// sourceDetector detects the source language of a text, "" when unknown
+type sourceDetector interface {
+	DetectSource(ctx context.Context, text string) string
+}
+
+// sourceLanguage returns the source language of the text: the one of the
+// context, the configured one or detected. "" leaves it to the API.
+func (t *Translator) sourceLanguage(ctx context.Context, text string) string {
+	if lang, ok := ctx.Value(sourceLangKey{}).(string); ok && lang != "" {
+		return lang
+	}
+	if t.SourceLang != "" {
+		return t.SourceLang
+	}
+	return t.DetectSource(ctx, text)
+}
+
+// DetectSource detects the language of the text with the Yandex detection
+// API by its beginning, the configured source language when set. "" when
+// detection fails, the translation API guesses then.
+func (t *Translator) DetectSource(ctx context.Context, text string) string {
+	if t.SourceLang != "" {
+		return t.SourceLang
+	}
+	if (t.apiKey == "" && t.iam == nil) || t.folderID == "" {
+		return ""
+	}
+	jsonData, err := json.Marshal(yandexDetectRequest{FolderID: t.folderID, Text: headChars(text, 1000),
+		LanguageCodeHints: []string{DetectLanguage(text)}})
+	if err != nil {
+		return ""
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://translate.api.cloud.yandex.net/translate/v2/detect",
+		bytes.NewBuffer(jsonData))
+	if err != nil {
+		return ""
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := t.authorize(ctx, req); err != nil {
+		log.Printf("[DEBUG] language detection failed: %v", err)
+		return ""
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		log.Printf("[DEBUG] language detection failed: %v", err)
+		return ""
+	}
+	defer resp.Body.Close()
+	var result yandexDetectResponse
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&result) != nil {
+		log.Printf("[DEBUG] language detection failed, status %d", resp.StatusCode)
+		return ""
 	}
+	return result.LanguageCode
+}
+
+// paragraphsHTML renders text as HTML paragraphs with line breaks, markup
+// the translation keeps
+func paragraphsHTML(text string) string {
+	var sb strings.Builder
+	for _, para := range paragraphs(text) {
+		sb.WriteString("<p>" + strings.ReplaceAll(html.EscapeString(strings.TrimSpace(para)), "\n", "<br>") + "</p>")
+	}
+	return sb.String()
+}
+
+var (
+	htmlBreakRe = regexp.MustCompile(`(?i)\s*<br\s*/?>\s*`)
+	htmlParaRe  = regexp.MustCompile(`(?i)\s*</p>\s*`)
+	htmlTagRe   = regexp.MustCompile(`<[^>]*>`)
+)
 
-	if current.Len() > 0 {
-		chunks = append(chunks, strings.TrimSpace(current.String()))
+// htmlParagraphs turns translated paragraphsHTML markup back into text
+func htmlParagraphs(s string) string {
+	s = htmlBreakRe.ReplaceAllString(s, "\n")
+	s = htmlParaRe.ReplaceAllString(s, "\n\n")
+	s = htmlTagRe.ReplaceAllString(s, "")
+	return strings.TrimSpace(html.UnescapeString(s))
+}
+
+// glossaryPairs returns the glossary terms found in the text (case-insensitive)
+// as pairs, a kept term translating to itself. Longer terms go first and
+// the Yandex limit is respected.
+func (t *Translator) glossaryPairs(text string) []yandexGlossaryPair {
+	if t.Glossary == nil {
+		return nil
+	}
+	terms, err := t.Glossary()
+	if err != nil {
+		log.Printf("[WARN] can't load translation glossary: %v", err)
+		return nil
+	}
+	sort.SliceStable(terms, func(i, j int) bool { return len(terms[i].Term) > len(terms[j].Term) })
+	lower := strings.ToLower(text)
+	var res []yandexGlossaryPair
+	for _, term := range terms {
+		if !strings.Contains(lower, strings.ToLower(term.Term)) {
+			continue
+		}
+		pair := yandexGlossaryPair{SourceText: term.Term, TranslatedText: term.Translation}
+		if pair.TranslatedText == "" {
+			pair.TranslatedText = term.Term
+		}
+		if res = append(res, pair); len(res) == maxGlossaryPairs {
+			break
+		}
 	}
+	return res
+}
 
-	return chunks
+// withoutGlossary returns a copy of the translator ignoring the glossary
+func (t *Translator) withoutGlossary() *Translator {
+	res := *t
+	res.Glossary = nil
+	return &res
+}
+
+// splitTextForTranslation splits text into chunks at paragraph boundaries,
+// respecting maxSize limit, see chunkParagraphs
+func splitTextForTranslation(text string, maxSize int) []string {
+	return chunkParagraphs(text, maxSize)
 }
@@ -0,0 +1,133 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/go-pkgz/lgr"
+
+	"github.com/umputun/feed-master/app/tracing"
+	ytstore "github.com/umputun/feed-master/app/youtube/store"
+)
+
+// llmTranslateChunkSize is the max chars of source text per LLM call, the
+// translation (Cyrillic costs more tokens) must fit the output limit too
+const llmTranslateChunkSize = 3000
+
+// llmContextChars is how much of the previous passage goes with a request
+const llmContextChars = 600
+
+// LLMTranslator translates into Russian with an OpenAI-compatible chat model.
+// Unlike sentence-level MT it sees whole paragraphs and the passage before
+// them, so names, terms and the register stay the same through an essay.
+type LLMTranslator struct {
+	Glossary   func() ([]ytstore.GlossaryTerm, error) // user glossary, terms found in a chunk go with its prompt
+	SourceLang string                                 // language of the texts, "" = left to the model
+
+	llm *EnrichService
+}
+
+// NewLLMTranslator creates a translator on the chat endpoint of the LLM service
+func NewLLMTranslator(llm *EnrichService) *LLMTranslator {
+	return &LLMTranslator{llm: llm}
+}
+
+// translateContextKey is the context key of the passage translated before
+// the text, see withTranslateContext
+type translateContextKey struct{}
+
+// translatedPassage is a source text and its translation
+type translatedPassage struct {
+	src, translated string
+}
+
+// withTranslateContext returns the context translating a text that follows
+// src translated as translated, so a translator seeing context continues it
+func withTranslateContext(ctx context.Context, src, translated string) context.Context {
+	return context.WithValue(ctx, translateContextKey{}, translatedPassage{src: src, translated: translated})
+}
+
+// NeedsTranslation checks if text needs translation into Russian
+func (l *LLMTranslator) NeedsTranslation(text string) bool {
+	return DetectLanguage(text) != "ru"
+}
+
+// Translate translates text into Russian paragraph-chunk by chunk, each
+// with the end of the previous one and its translation as context
+func (l *LLMTranslator) Translate(ctx context.Context, text string) (res string, err error) {
+	ctx, span := tracing.Start(ctx, "translate_llm", "chars", len([]rune(text)))
+	defer func() { span.Finish(err) }()
+	if DetectLanguage(text) == "ru" {
+		return text, nil
+	}
+	sourceLang, ok := ctx.Value(sourceLangKey{}).(string)
+	if !ok || sourceLang == "" {
+		sourceLang = l.SourceLang
+	}
+	prev, _ := ctx.Value(translateContextKey{}).(translatedPassage)
+
+	chunks := chunkParagraphs(text, llmTranslateChunkSize)
+	out := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		translated, err := l.llm.chat(ctx, llmTranslatePrompt(sourceLang, l.glossaryTerms(chunk), prev), chunk, false)
+		if err != nil {
+			return "", fmt.Errorf("failed to translate chunk %d/%d: %w", i+1, len(chunks), err)
+		}
+		translated = strings.TrimSpace(translated)
+		if translated == "" {
+			return "", fmt.Errorf("empty translation of chunk %d/%d", i+1, len(chunks))
+		}
+		out = append(out, translated)
+		prev = translatedPassage{src: chunk, translated: translated}
+	}
+	return strings.Join(out, "\n\n"), nil
+}
+
+// glossaryTerms returns the glossary terms found in the text (case-insensitive)
+func (l *LLMTranslator) glossaryTerms(text string) []ytstore.GlossaryTerm {
+	if l.Glossary == nil {
+		return nil
+	}
+	terms, err := l.Glossary()
+	if err != nil {
+		log.Printf("[WARN] can't load translation glossary: %v", err)
+		return nil
+	}
+	lower := strings.ToLower(text)
+	var res []ytstore.GlossaryTerm
+	for _, term := range terms {
+		if strings.Contains(lower, strings.ToLower(term.Term)) {
+			res = append(res, term)
+		}
+	}
+	return res
+}
+
+func llmTranslatePrompt(sourceLang string, terms []ytstore.GlossaryTerm, prev translatedPassage) string {
+	p := `Ты литературный переводчик. Переведи текст пользователя на русский язык.
+- Переводи полностью, ничего не сокращай, не пересказывай и не добавляй от себя.
+- Сохрани деление на абзацы и переносы строк.
+- Имена, названия и термины переводи единообразно по всему тексту; имена собственные, у которых нет устоявшегося русского варианта, транслитерируй.
+- Сохрани стиль и интонацию автора, пиши естественным русским языком, а не калькой.
+- Ответь только переводом, без пояснений и примечаний.`
+	if sourceLang != "" {
+		p += "\n\nЯзык оригинала: " + sourceLang + "."
+	}
+	if len(terms) > 0 {
+		p += "\n\nГлоссарий, переводи эти термины так:"
+		for _, term := range terms {
+			translation := term.Translation
+			if translation == "" {
+				translation = term.Term + " (не переводить)"
+			}
+			p += "\n- " + term.Term + " → " + translation
+		}
+	}
+	if prev.src != "" && prev.translated != "" {
+		p += "\n\nКонец предыдущего фрагмента оригинала (для связности, НЕ переводи его повторно):\n" +
+			tailChars(prev.src, llmContextChars) +
+			"\n\nЕго перевод (продолжай в том же стиле и с теми же терминами):\n" + tailChars(prev.translated, llmContextChars)
+	}
+	return p
+}
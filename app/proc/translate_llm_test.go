@@ -0,0 +1,90 @@
+package proc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ytstore "github.com/umputun/feed-master/app/youtube/store"
+)
+
+func TestLLMTranslator(t *testing.T) {
+	var mu sync.Mutex
+	var systems, users []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct{ Role, Content string }
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Len(t, req.Messages, 2)
+		mu.Lock()
+		systems = append(systems, req.Messages[0].Content)
+		users = append(users, req.Messages[1].Content)
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]any{"choices": []map[string]any{
+			{"message": map[string]string{"content": " Перевод: " + headChars(req.Messages[1].Content, 20) + "\n"}}}})
+	}))
+	defer ts.Close()
+	llm := NewEnrichService("key", "")
+	llm.BaseURL = ts.URL
+
+	tr := NewLLMTranslator(llm)
+	tr.Glossary = func() ([]ytstore.GlossaryTerm, error) {
+		return []ytstore.GlossaryTerm{{Term: "Kubernetes"}, {Term: "pull request", Translation: "пулреквест"}, {Term: "absent"}}, nil
+	}
+	assert.True(t, tr.NeedsTranslation("An essay."))
+	assert.False(t, tr.NeedsTranslation("Эссе."))
+
+	para := strings.Repeat("Kubernetes makes a pull request. ", 40)
+	res, err := tr.Translate(context.Background(), para+"\n\n"+para+"\n\n"+para)
+	require.NoError(t, err)
+	require.Len(t, systems, 2, "paragraphs packed into chunks")
+	assert.Equal(t, "Перевод: Kubernetes makes a p\n\nПеревод: Kubernetes makes a p", res)
+	assert.Contains(t, systems[0], "- Kubernetes → Kubernetes (не переводить)")
+	assert.Contains(t, systems[0], "- pull request → пулреквест")
+	assert.NotContains(t, systems[0], "absent")
+	assert.NotContains(t, systems[0], "Конец предыдущего фрагмента")
+	assert.Contains(t, systems[1], "Конец предыдущего фрагмента оригинала")
+	assert.Contains(t, systems[1], "Его перевод (продолжай в том же стиле и с теми же терминами):\nПеревод: Kubernetes makes a p")
+
+	systems = nil
+	ctx := withTranslateContext(withSourceLang(context.Background(), "en"), "Earlier text.", "Ранний текст.")
+	_, err = tr.Translate(ctx, "Next one.")
+	require.NoError(t, err)
+	require.Len(t, systems, 1)
+	assert.Contains(t, systems[0], "Язык оригинала: en.")
+	assert.Contains(t, systems[0], "Earlier text.")
+	assert.Contains(t, systems[0], "Ранний текст.")
+}
+
+// contextTranslator records the previous passage each call sees
+type contextTranslator struct {
+	FakeTranslator
+	prev []string
+}
+
+func (c *contextTranslator) Translate(ctx context.Context, text string) (string, error) {
+	p, _ := ctx.Value(translateContextKey{}).(translatedPassage)
+	c.prev = append(c.prev, p.translated)
+	return c.FakeTranslator.Translate(ctx, text)
+}
+
+func TestSpeechPipeline_TranslateContext(t *testing.T) {
+	tr := &contextTranslator{}
+	text := strings.Repeat("This is a sentence in English. ", 200)
+	_, err := speechPipeline{TTS: &FakeTTS{}, Translator: tr}.Run(context.Background(), text, &strings.Builder{}, nil)
+	require.NoError(t, err)
+	require.Greater(t, len(tr.prev), 2)
+	assert.Empty(t, tr.prev[0])
+	texts := tr.Texts()
+	for i := 1; i < len(tr.prev); i++ {
+		assert.Equal(t, "[ru] "+texts[i-1], tr.prev[i], "chunk %d continues the previous one", i)
+	}
+}
@@ -0,0 +1,173 @@
+package proc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+
+	"github.com/umputun/feed-master/app/tracing"
+)
+
+// LibreTranslator translates with a self-hosted LibreTranslate-compatible
+// server: LibreTranslate itself (Argos Translate models on CTranslate2) or
+// anything speaking its /translate API. Nothing leaves the host and there
+// are no per-character costs.
+type LibreTranslator struct {
+	SourceLang string // language of the texts, "" = detected once per text
+
+	url        string
+	apiKey     string
+	targetLang string
+	client     *http.Client
+}
+
+// NewLibreTranslator creates a translator of the server at baseURL, apiKey
+// is only needed by servers requiring one
+func NewLibreTranslator(baseURL, apiKey, targetLang string) *LibreTranslator {
+	if targetLang == "" {
+		targetLang = "ru"
+	}
+	return &LibreTranslator{
+		url:        strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		targetLang: targetLang,
+		client: &http.Client{
+			Timeout: 5 * time.Minute, // CPU models are slow on long batches
+		},
+	}
+}
+
+// libreRequest is the request body of LibreTranslate /translate
+type libreRequest struct {
+	Q      []string `json:"q"`
+	Source string   `json:"source"`
+	Target string   `json:"target"`
+	Format string   `json:"format"`
+	APIKey string   `json:"api_key,omitempty"`
+}
+
+// libreResponse is the response of LibreTranslate /translate for a list of texts
+type libreResponse struct {
+	TranslatedText []string `json:"translatedText"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// NeedsTranslation checks if text needs translation to target language
+func (l *LibreTranslator) NeedsTranslation(text string) bool {
+	return DetectLanguage(text) != l.targetLang
+}
+
+// Translate translates text to the target language sentence by sentence,
+// keeping the line and paragraph breaks
+func (l *LibreTranslator) Translate(ctx context.Context, text string) (res string, err error) {
+	ctx, span := tracing.Start(ctx, "translate_offline", "chars", len([]rune(text)))
+	defer func() { span.Finish(err) }()
+	if DetectLanguage(text) == l.targetLang {
+		return text, nil
+	}
+	sourceLang, ok := ctx.Value(sourceLangKey{}).(string)
+	if !ok || sourceLang == "" {
+		sourceLang = l.DetectSource(ctx, text)
+	}
+	if sourceLang == l.targetLang {
+		return text, nil
+	}
+	if sourceLang == "" {
+		sourceLang = "auto"
+	}
+
+	// smaller batches than Yandex takes, a local model on CPU translates
+	// a few sentences a second and the request must not time out
+	const maxSentenceSize, maxBatchSize = 2000, 4000
+	segs := sentenceSegments(text, maxSentenceSize)
+	for i, batch := range sentenceBatches(segs, maxBatchSize, func(s string) int { return len([]rune(s)) }) {
+		texts := make([]string, len(batch))
+		for j, idx := range batch {
+			texts[j] = segs[idx].text
+		}
+		translated, err := l.translateBatch(ctx, texts, sourceLang)
+		if err != nil {
+			return "", fmt.Errorf("failed to translate chunk %d: %w", i, err)
+		}
+		for j, idx := range batch {
+			segs[idx].text = translated[j]
+		}
+	}
+
+	var result strings.Builder
+	for _, seg := range segs {
+		result.WriteString(seg.text + seg.sep)
+	}
+	return result.String(), nil
+}
+
+// translateBatch translates texts with a single request, a translation per text in order
+func (l *LibreTranslator) translateBatch(ctx context.Context, texts []string, sourceLang string) ([]string, error) {
+	jsonData, err := json.Marshal(libreRequest{Q: texts, Source: sourceLang, Target: l.targetLang, Format: "text",
+		APIKey: l.apiKey})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", l.url+"/translate", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("offline translation error (status %d): %s", resp.StatusCode, string(bodyBytes))
+	}
+	var result libreResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(result.TranslatedText) != len(texts) {
+		return nil, fmt.Errorf("%d translations returned for %d texts", len(result.TranslatedText), len(texts))
+	}
+	return result.TranslatedText, nil
+}
+
+// DetectSource detects the language of the text with the server's /detect
+// by its beginning, the configured source language when set. "" when
+// detection fails, the server detects per request then.
+func (l *LibreTranslator) DetectSource(ctx context.Context, text string) string {
+	if l.SourceLang != "" {
+		return l.SourceLang
+	}
+	jsonData, err := json.Marshal(map[string]string{"q": headChars(text, 1000), "api_key": l.apiKey})
+	if err != nil {
+		return ""
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", l.url+"/detect", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return ""
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := l.client.Do(req)
+	if err != nil {
+		log.Printf("[DEBUG] offline language detection failed: %v", err)
+		return ""
+	}
+	defer resp.Body.Close()
+	var result []struct {
+		Language string `json:"language"`
+	}
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&result) != nil || len(result) == 0 {
+		log.Printf("[DEBUG] offline language detection failed, status %d", resp.StatusCode)
+		return ""
+	}
+	return result[0].Language
+}
@@ -0,0 +1,70 @@
+package proc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLibreTranslator(t *testing.T) {
+	var mu sync.Mutex
+	var reqs []libreRequest
+	var detects int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/detect" {
+			detects++
+			_, _ = w.Write([]byte(`[{"confidence":90,"language":"de"}]`))
+			return
+		}
+		require.Equal(t, "/translate", r.URL.Path)
+		var req libreRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		reqs = append(reqs, req)
+		if req.Q[0] == "fail" {
+			http.Error(w, `{"error":"model not installed"}`, http.StatusBadRequest)
+			return
+		}
+		res := libreResponse{}
+		for _, q := range req.Q {
+			res.TranslatedText = append(res.TranslatedText, strings.ToUpper(q))
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	defer ts.Close()
+
+	tr := NewLibreTranslator(ts.URL+"/", "secret", "ru")
+	assert.True(t, tr.NeedsTranslation("Ein Satz."))
+	assert.False(t, tr.NeedsTranslation("Привет, мир."))
+
+	text := "Titel\n\n" + strings.Repeat("Ein Satz auf Deutsch. ", 300)
+	res, err := tr.Translate(context.Background(), text)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(res, "TITEL\n\nEIN SATZ AUF DEUTSCH. EIN SATZ"))
+	assert.Equal(t, 300, strings.Count(res, "EIN SATZ AUF DEUTSCH."))
+	assert.Equal(t, 1, detects, "detected once for all the batches")
+	require.Greater(t, len(reqs), 1)
+	for _, req := range reqs {
+		assert.Equal(t, "de", req.Source)
+		assert.Equal(t, "ru", req.Target)
+		assert.Equal(t, "secret", req.APIKey)
+	}
+
+	reqs = nil
+	_, err = tr.Translate(withSourceLang(context.Background(), "fr"), "Une phrase.")
+	require.NoError(t, err)
+	assert.Equal(t, "fr", reqs[0].Source, "source of the context")
+	assert.Equal(t, 1, detects)
+
+	_, err = tr.Translate(context.Background(), "fail")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "model not installed")
+}
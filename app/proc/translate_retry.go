@@ -0,0 +1,138 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+)
+
+const (
+	translateAttempts  = 5               // tries of a request, backing off 2s..32s, before the breaker opens
+	translatePause     = 5 * time.Minute // how long an open breaker holds translation back
+	translateMaxPauses = 12              // pauses of a request before it fails, an hour in all
+)
+
+// translateStatusError is a non-200 response of the translation API
+type translateStatusError struct {
+	status     int
+	retryAfter time.Duration // Retry-After of the response, 0 = none
+	body       string
+}
+
+func (e *translateStatusError) Error() string {
+	return fmt.Sprintf("Yandex API error (status %d): %s", e.status, e.body)
+}
+
+// retryableTranslateErr reports whether the request may succeed later: the
+// API throttles (429) or fails (5xx), or the connection does
+func retryableTranslateErr(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var se *translateStatusError
+	if errors.As(err, &se) {
+		return se.status == http.StatusTooManyRequests || se.status >= 500
+	}
+	var ue *url.Error
+	return errors.As(err, &ue)
+}
+
+// translateBreaker holds all the translations back for a while once the API
+// keeps failing, so jobs pause instead of failing halfway through the text
+// and piling more requests on a throttled service
+type translateBreaker struct {
+	mu        sync.Mutex
+	openUntil time.Time
+}
+
+// wait blocks while the breaker is open
+func (b *translateBreaker) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		d := time.Until(b.openUntil)
+		b.mu.Unlock()
+		if d <= 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+		}
+	}
+}
+
+// open holds the translations back for d, or longer if already open so
+func (b *translateBreaker) open(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if until := time.Now().Add(d); until.After(b.openUntil) {
+		b.openUntil = until
+	}
+}
+
+// translateBatchRetry is translateBatch retrying throttled and failed
+// requests: with exponential backoff honoring Retry-After, then, with the
+// attempts used up, pausing all the translations on the breaker and trying
+// again, up to translateMaxPauses times
+func (t *Translator) translateBatchRetry(ctx context.Context, texts []string, sourceLang string) ([]string, error) {
+	pause := t.pause
+	if pause <= 0 {
+		pause = translatePause
+	}
+	for pauses := 0; ; pauses++ {
+		if err := t.breaker.wait(ctx); err != nil {
+			return nil, err
+		}
+		res, err := t.translateBatchBackoff(ctx, texts, sourceLang)
+		if err == nil || !retryableTranslateErr(err) {
+			return res, err
+		}
+		if pauses == translateMaxPauses {
+			return nil, fmt.Errorf("translation still failing after %v of pauses: %w", pause*translateMaxPauses, err)
+		}
+		log.Printf("[WARN] translation keeps failing, pausing for %v (%d/%d): %v", pause, pauses+1, translateMaxPauses, err)
+		t.breaker.open(pause)
+	}
+}
+
+// translateBatchBackoff makes up to translateAttempts tries of the request,
+// backing off 2s..32s or as long as Retry-After asks
+func (t *Translator) translateBatchBackoff(ctx context.Context, texts []string, sourceLang string) ([]string, error) {
+	backoff := t.backoff
+	if backoff <= 0 {
+		backoff = 2 * time.Second
+	}
+	var lastErr error
+	for attempt := 1; attempt <= translateAttempts; attempt++ {
+		res, err := t.translateBatch(ctx, texts, sourceLang)
+		if err == nil || !retryableTranslateErr(err) {
+			return res, err
+		}
+		lastErr = err
+		if attempt == translateAttempts {
+			break
+		}
+		wait := backoff
+		var se *translateStatusError
+		if errors.As(err, &se) && se.retryAfter > wait {
+			wait = se.retryAfter
+		}
+		log.Printf("[WARN] translation retrying in %v (attempt %d/%d): %v", wait, attempt, translateAttempts, err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		if backoff < 32*time.Second {
+			backoff *= 2
+		}
+	}
+	return nil, lastErr
+}
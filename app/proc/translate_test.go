@@ -0,0 +1,304 @@
+package proc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ytstore "github.com/umputun/feed-master/app/youtube/store"
+)
+
+// redirectTransport sends every request to the test server
+type redirectTransport struct{ target *url.URL }
+
+func (r redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme, req.URL.Host = r.target.Scheme, r.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestTranslator_Glossary(t *testing.T) {
+	var mu sync.Mutex
+	var reqs []yandexRequest
+	reject := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/translate/v2/detect" {
+			_, _ = w.Write([]byte(`{"languageCode":"en"}`))
+			return
+		}
+		var req yandexRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		reqs = append(reqs, req)
+		mu.Unlock()
+		if reject && req.GlossaryConfig != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"glossary is not supported"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"translations":[{"text":"перевод"}]}`))
+	}))
+	defer ts.Close()
+	target, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	tr := NewTranslatorWithKey("key", "folder", "ru")
+	tr.client = &http.Client{Transport: redirectTransport{target: target}}
+	tr.Glossary = func() ([]ytstore.GlossaryTerm, error) {
+		return []ytstore.GlossaryTerm{{Term: "Go"}, {Term: "pull request", Translation: "пулл-реквест"},
+			{Term: "Go modules"}, {Term: "Rust"}}, nil
+	}
+
+	res, err := tr.Translate(context.Background(), "Open a Pull Request to update Go modules.")
+	require.NoError(t, err)
+	assert.Equal(t, "перевод", res)
+	require.Len(t, reqs, 1)
+	assert.Equal(t, "en", reqs[0].SourceLanguageCode)
+	require.NotNil(t, reqs[0].GlossaryConfig)
+	assert.Equal(t, []yandexGlossaryPair{{SourceText: "pull request", TranslatedText: "пулл-реквест"},
+		{SourceText: "Go modules", TranslatedText: "Go modules"}, {SourceText: "Go", TranslatedText: "Go"}},
+		reqs[0].GlossaryConfig.GlossaryData.GlossaryPairs, "terms found in the text, longest first")
+
+	reqs, reject = nil, true
+	res, err = tr.Translate(context.Background(), "Written in Rust.")
+	require.NoError(t, err)
+	assert.Equal(t, "перевод", res)
+	require.Len(t, reqs, 2, "retried without the glossary")
+	assert.Nil(t, reqs[1].GlossaryConfig)
+	assert.Equal(t, "en", reqs[1].SourceLanguageCode)
+
+	reqs = nil
+	tr.Glossary = func() ([]ytstore.GlossaryTerm, error) { return nil, errors.New("db closed") }
+	_, err = tr.Translate(context.Background(), "Written in Rust.")
+	require.NoError(t, err, "glossary failure doesn't stop the translation")
+	require.Len(t, reqs, 1)
+	assert.Nil(t, reqs[0].GlossaryConfig)
+}
+
+func TestTranslator_Options(t *testing.T) {
+	var mu sync.Mutex
+	var reqs []yandexRequest
+	var detects int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/translate/v2/detect" {
+			detects++
+			var req yandexDetectRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, []string{"en"}, req.LanguageCodeHints)
+			_, _ = w.Write([]byte(`{"languageCode":"de"}`))
+			return
+		}
+		var req yandexRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		reqs = append(reqs, req)
+		var tr []map[string]string
+		for _, text := range req.Texts {
+			tr = append(tr, map[string]string{"text": strings.ToUpper(text)})
+		}
+		resp, err := json.Marshal(map[string]any{"translations": tr})
+		require.NoError(t, err)
+		_, _ = w.Write(resp)
+	}))
+	defer ts.Close()
+	target, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	newTranslator := func() *Translator {
+		tr := NewTranslatorWithKey("key", "folder", "ru")
+		tr.client = &http.Client{Transport: redirectTransport{target: target}}
+		return tr
+	}
+	reset := func() ([]yandexRequest, int) {
+		mu.Lock()
+		defer mu.Unlock()
+		r, d := reqs, detects
+		reqs, detects = nil, 0
+		return r, d
+	}
+
+	t.Run("detected source, plain text", func(t *testing.T) {
+		tr := newTranslator()
+		text := strings.Repeat("Ein Satz auf Deutsch.\n\n", 500)
+		res, err := tr.Translate(context.Background(), text)
+		require.NoError(t, err)
+		assert.Contains(t, res, "EIN SATZ AUF DEUTSCH.\n\nEIN SATZ")
+		sent, detected := reset()
+		assert.Equal(t, 1, detected, "detected once for all the chunks")
+		require.Greater(t, len(sent), 1)
+		for _, req := range sent {
+			assert.Equal(t, "de", req.SourceLanguageCode)
+			assert.Equal(t, "PLAIN_TEXT", req.Format)
+			assert.False(t, req.Speller)
+		}
+	})
+
+	t.Run("configured source, formatting, speller", func(t *testing.T) {
+		tr := newTranslator()
+		tr.SourceLang, tr.PreserveFormatting, tr.Speller = "fr", true, true
+		res, err := tr.Translate(context.Background(), "Titre\n\nUne ligne\net l'autre < 5")
+		require.NoError(t, err)
+		assert.Equal(t, "TITRE\n\nUNE LIGNE\nET L'AUTRE < 5", res)
+		sent, detected := reset()
+		assert.Zero(t, detected)
+		require.Len(t, sent, 1)
+		assert.Equal(t, "fr", sent[0].SourceLanguageCode)
+		assert.Equal(t, "HTML", sent[0].Format)
+		assert.Equal(t, []string{"<p>Titre</p>", "<p>Une ligne</p>", "<p>et l&#39;autre &lt; 5</p>"}, sent[0].Texts)
+		assert.True(t, sent[0].Speller)
+	})
+
+	t.Run("source of the context", func(t *testing.T) {
+		tr := newTranslator()
+		_, err := tr.Translate(withSourceLang(context.Background(), "es"), "Una frase.")
+		require.NoError(t, err)
+		sent, detected := reset()
+		assert.Zero(t, detected)
+		require.Len(t, sent, 1)
+		assert.Equal(t, "es", sent[0].SourceLanguageCode)
+	})
+}
+
+func TestSentenceSegments(t *testing.T) {
+	text := "  First one. Second one!\nA line\n\n«Quoted?» Said he... Pi is 3.14 here.\nЧетвёртое (в скобках.) Конец"
+	segs := sentenceSegments(text, 1000)
+	var texts []string
+	var joined strings.Builder
+	for _, seg := range segs {
+		texts = append(texts, seg.text)
+		joined.WriteString(seg.text + seg.sep)
+	}
+	assert.Equal(t, text, joined.String(), "layout kept")
+	assert.Equal(t, []string{"", "First one.", "Second one!", "A line", "«Quoted?»", "Said he...", "Pi is 3.14 here.",
+		"Четвёртое (в скобках.)", "Конец"}, texts)
+	assert.Equal(t, "\n\n", segs[3].sep)
+
+	long := sentenceSegments("word word word word word. End", 12)
+	require.Len(t, long, 4)
+	assert.Equal(t, textSegment{text: "word word", sep: " "}, long[0])
+	assert.Equal(t, textSegment{text: "word.", sep: " "}, long[2])
+
+	assert.Equal(t, [][]int{{1, 2}, {3}, {4}}, sentenceBatches([]textSegment{{sep: " "}, {text: "aaaa"}, {text: "bbbb"},
+		{text: "cccccccccc"}, {text: "d"}}, 10, func(s string) int { return len(s) }))
+	assert.Equal(t, [][]int{{0, 1}, {2}}, sentenceBatches([]textSegment{{text: "ёёёё"}, {text: "жжжж"}, {text: "a"}}, 8,
+		utf8.RuneCountInString), "characters counted, not bytes")
+}
+
+func TestTranslator_SentenceAligned(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]string
+	short := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req yandexRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		batches = append(batches, req.Texts)
+		mu.Unlock()
+		var tr []map[string]string
+		for _, text := range req.Texts {
+			tr = append(tr, map[string]string{"text": "[" + text + "]"})
+		}
+		if short {
+			tr = tr[1:]
+		}
+		resp, err := json.Marshal(map[string]any{"translations": tr})
+		require.NoError(t, err)
+		_, _ = w.Write(resp)
+	}))
+	defer ts.Close()
+	target, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	tr := NewTranslatorWithKey("key", "folder", "ru")
+	tr.SourceLang = "en"
+	tr.client = &http.Client{Transport: redirectTransport{target: target}}
+
+	text := "Heading\n\n" + strings.Repeat("This is a sentence of the article. ", 300)
+	res, err := tr.Translate(context.Background(), text)
+	require.NoError(t, err)
+	require.Len(t, batches, 2, "sentences batched up to the request limit")
+	n := 0
+	for _, b := range batches {
+		n += len(b)
+	}
+	assert.Equal(t, 301, n, "a text per sentence")
+	assert.True(t, strings.HasPrefix(res, "[Heading]\n\n[This is a sentence of the article.] [This is"))
+	assert.Equal(t, 300, strings.Count(res, "[This is a sentence of the article.]"), "no sentence dropped or merged")
+
+	short = true
+	_, err = tr.Translate(context.Background(), "One. Two.")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 translations returned for 2 texts")
+}
+
+func TestTranslator_RateLimited(t *testing.T) {
+	var mu sync.Mutex
+	calls, failures := 0, 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		fail := calls <= failures
+		mu.Unlock()
+		if fail {
+			w.Header().Set("Retry-After", "0.01")
+			http.Error(w, "throttled", http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"translations":[{"text":"Привет."}]}`))
+	}))
+	defer ts.Close()
+	target, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	tr := NewTranslatorWithKey("key", "folder", "ru")
+	tr.SourceLang = "en"
+	tr.client = &http.Client{Transport: redirectTransport{target: target}}
+	tr.backoff, tr.pause = time.Millisecond, 20*time.Millisecond
+
+	t.Run("retried with backoff", func(t *testing.T) {
+		mu.Lock()
+		calls, failures = 0, 2
+		mu.Unlock()
+		res, err := tr.Translate(context.Background(), "Hello.")
+		require.NoError(t, err)
+		assert.Equal(t, "Привет.", res)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("paused on the breaker", func(t *testing.T) {
+		mu.Lock()
+		calls, failures = 0, translateAttempts+1
+		mu.Unlock()
+		st := time.Now()
+		res, err := tr.Translate(context.Background(), "Hello.")
+		require.NoError(t, err)
+		assert.Equal(t, "Привет.", res)
+		assert.Equal(t, translateAttempts+2, calls)
+		assert.GreaterOrEqual(t, time.Since(st), 20*time.Millisecond)
+	})
+
+	t.Run("cancelled while paused", func(t *testing.T) {
+		mu.Lock()
+		calls, failures = 0, 1000
+		mu.Unlock()
+		tr.pause = time.Hour
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		_, err := tr.Translate(ctx, "Hello.")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("not retried on client errors", func(t *testing.T) {
+		assert.False(t, retryableTranslateErr(&translateStatusError{status: http.StatusUnauthorized}))
+		assert.True(t, retryableTranslateErr(&translateStatusError{status: http.StatusBadGateway}))
+	})
+}
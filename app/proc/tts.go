@@ -7,8 +7,14 @@ import (
 	"io"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/wujunwei928/edge-tts-go/edge_tts"
+
+	"github.com/umputun/feed-master/app/duration"
+	"github.com/umputun/feed-master/app/tools"
+	"github.com/umputun/feed-master/app/tracing"
 )
 
 // escapeXML escapes characters that are invalid in XML/SSML content.
@@ -27,6 +33,7 @@ type TTSProvider interface {
 // EdgeTTS implements TTSProvider using Microsoft Edge TTS
 type EdgeTTS struct {
 	Voice string
+	Rate  string // speech rate like "+20%" or "-15%", empty = default
 }
 
 // NewEdgeTTS creates a new Edge TTS provider
@@ -37,10 +44,20 @@ func NewEdgeTTS(voice string) *EdgeTTS {
 	return &EdgeTTS{Voice: voice}
 }
 
-// Synthesize converts text to speech using Edge TTS
+// Synthesize converts text to speech using Edge TTS, a session at a time
+// within the TTS limit of tools
 func (e *EdgeTTS) Synthesize(ctx context.Context, text string) ([]byte, error) {
-	text = escapeXML(text)
-	comm, err := edge_tts.NewCommunicate(text, edge_tts.SetVoice(e.Voice))
+	release, err := tools.Acquire(ctx, tools.ClassTTS)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	text = escapeXML(verbalize(text, voiceLang(e.Voice)))
+	opts := []edge_tts.CommunicateOption{edge_tts.SetVoice(e.Voice)}
+	if e.Rate != "" {
+		opts = append(opts, edge_tts.SetRate(e.Rate))
+	}
+	comm, err := edge_tts.NewCommunicate(text, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create TTS communicator: %w", err)
 	}
@@ -69,7 +86,7 @@ func (e *EdgeTTS) SynthesizeLongText(ctx context.Context, text string, maxChunkS
 		maxChunkSize = 3000 // Edge TTS has ~3000 char limit per request
 	}
 
-	chunks := splitTextIntoChunks(text, maxChunkSize)
+	chunks := splitTextIntoChunks(withPauses(text), maxChunkSize)
 	var result bytes.Buffer
 
 	for i, chunk := range chunks {
@@ -79,60 +96,172 @@ func (e *EdgeTTS) SynthesizeLongText(ctx context.Context, text string, maxChunkS
 		default:
 		}
 
-		// Retry logic for transient errors
-		var audio []byte
-		var err error
-		for attempt := 0; attempt < 3; attempt++ {
-			if attempt > 0 {
-				// Exponential backoff: 5s, 10s, 20s
-				backoff := time.Duration(5<<attempt) * time.Second
-				time.Sleep(backoff)
-			}
-
-			audio, err = e.Synthesize(ctx, chunk)
-			if err == nil {
-				break
-			}
-		}
+		audio, err := e.synthesizeWithRetry(ctx, chunk)
 		if err != nil {
 			return nil, fmt.Errorf("failed to synthesize chunk %d: %w", i, err)
 		}
 		result.Write(audio)
 
-		// Delay between chunks to avoid rate limiting (2 seconds)
+		// Delay between chunks to avoid rate limiting
 		if i < len(chunks)-1 {
-			time.Sleep(2 * time.Second)
+			time.Sleep(edgeChunkPause)
 		}
 	}
 
 	return result.Bytes(), nil
 }
 
-// splitTextIntoChunks splits text into chunks at sentence boundaries
+// edgeChunkPause is the delay between Edge TTS requests to avoid rate limiting
+const edgeChunkPause = 2 * time.Second
+
+// synthesizeWithRetry synthesizes a chunk, retrying transient errors
+func (e *EdgeTTS) synthesizeWithRetry(ctx context.Context, chunk string) (audio []byte, err error) {
+	for attempt := 0; attempt < 3; attempt++ {
+		if attempt > 0 {
+			// Exponential backoff: 10s, 20s
+			backoff := time.Duration(5<<attempt) * time.Second
+			time.Sleep(backoff)
+		}
+
+		audio, err = e.Synthesize(ctx, chunk)
+		if err == nil {
+			return audio, nil
+		}
+	}
+	return nil, err
+}
+
+// Warmup makes a tiny synthesis request. The library dials a new websocket
+// per request, so there is no connection to keep open, but the first request
+// pays for DNS, TLS and the Sec-MS-GEC clock-skew correction, and a dead
+// service or a wrong voice shows up before the real chunks are queued.
+func (e *EdgeTTS) Warmup(ctx context.Context) error {
+	_, err := e.Synthesize(ctx, ".")
+	return err
+}
+
+// synthesizeLongText voices text of any length with the given provider.
+// EdgeTTS goes through SynthesizeLongText (retries, rate-limit pauses),
+// other providers get the same sentence chunks without pauses.
+func synthesizeLongText(ctx context.Context, p TTSProvider, text string, maxChunkSize int) (audio []byte, err error) {
+	ctx, span := tracing.Start(ctx, "tts", "chars", len([]rune(text)))
+	defer func() { span.Finish(err) }()
+	if p == nil {
+		return nil, fmt.Errorf("TTS provider is not configured")
+	}
+	if e, ok := p.(*EdgeTTS); ok {
+		return e.SynthesizeLongText(ctx, text, maxChunkSize)
+	}
+	if maxChunkSize <= 0 {
+		maxChunkSize = 3000
+	}
+	var result bytes.Buffer
+	for i, chunk := range splitTextIntoChunks(withPauses(text), maxChunkSize) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		audio, err := p.Synthesize(ctx, chunk)
+		if err != nil {
+			return nil, fmt.Errorf("failed to synthesize chunk %d: %w", i, err)
+		}
+		result.Write(audio)
+	}
+	return result.Bytes(), nil
+}
+
+// ttsDuration returns the playing time of produced TTS audio in seconds:
+// DurationSvc when it's configured and succeeds, counting MP3 frames
+// otherwise, and the speech rate estimate (Edge TTS ~150 words/min,
+// ~6 chars/word = ~900 chars/min) as the last resort
+func (t *TelegramBot) ttsDuration(path string, charCount int) int {
+	if t.DurationSvc != nil {
+		if d := t.DurationSvc.File(path); d > 0 {
+			return d
+		}
+	}
+	if d, err := duration.MP3FileLength(path); err == nil {
+		return int(d.Round(time.Second).Seconds())
+	}
+	return int(float64(charCount) / 900.0 * 60.0)
+}
+
+// splitTextIntoChunks splits text into chunks at paragraph boundaries, see chunkParagraphs
 func splitTextIntoChunks(text string, maxSize int) []string {
+	return chunkParagraphs(text, maxSize)
+}
+
+// chunkParagraphs packs the paragraphs of text into chunks of up to maxSize
+// bytes, paragraphs of a chunk kept apart by a blank line. A longer paragraph
+// is split at sentences, a longer sentence at words.
+func chunkParagraphs(text string, maxSize int) []string {
 	if len(text) <= maxSize {
 		return []string{text}
 	}
 
 	var chunks []string
-	sentences := splitIntoSentences(text)
 	var current strings.Builder
-
-	for _, sentence := range sentences {
-		if current.Len()+len(sentence) > maxSize && current.Len() > 0 {
+	flush := func() {
+		if strings.TrimSpace(current.String()) != "" {
 			chunks = append(chunks, current.String())
-			current.Reset()
 		}
-		current.WriteString(sentence)
+		current.Reset()
+	}
+	add := func(piece, sep string) {
+		if current.Len() > 0 && current.Len()+len(sep)+len(piece) > maxSize {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString(sep)
+		}
+		current.WriteString(piece)
 	}
 
-	if current.Len() > 0 {
-		chunks = append(chunks, current.String())
+	for _, para := range paragraphs(text) {
+		if len(para) <= maxSize {
+			add(para, "\n\n")
+			continue
+		}
+		sep := "\n\n" // sentences carry their own spacing
+		for _, sentence := range splitIntoSentences(para) {
+			if len(sentence) <= maxSize {
+				add(sentence, sep)
+				sep = ""
+				continue
+			}
+			for _, word := range strings.Fields(sentence) {
+				if sep == "" {
+					sep = " "
+				}
+				add(word, sep)
+				sep = " "
+			}
+			sep = ""
+		}
 	}
+	flush()
 
 	return chunks
 }
 
+// withPauses ends paragraphs without a stop (headings, list items) with a
+// period, so TTS pauses after them instead of running into the next one.
+// The rest of the text is kept as is.
+func withPauses(text string) string {
+	var sb strings.Builder
+	start := 0
+	for _, sep := range append(blankLineRe.FindAllStringIndex(text, -1), []int{len(text), len(text)}) {
+		para := text[start:sep[0]]
+		body := strings.TrimRightFunc(para, unicode.IsSpace)
+		sb.WriteString(body)
+		if last, _ := utf8.DecodeLastRuneInString(strings.TrimRight(body, `"')]»”`)); body != "" && !strings.ContainsRune(".!?…:;", last) {
+			sb.WriteString(".")
+		}
+		sb.WriteString(para[len(body):] + text[sep[0]:sep[1]])
+		start = sep[1]
+	}
+	return sb.String()
+}
+
 // splitIntoSentences splits text into sentences
 func splitIntoSentences(text string) []string {
 	var sentences []string
@@ -0,0 +1,217 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// defaultNitterURL is the nitter instance threads are read through when
+// ArticleExtractor.NitterURL isn't set
+const defaultNitterURL = "https://nitter.net"
+
+var (
+	tweetPathRe     = regexp.MustCompile(`^/([A-Za-z0-9_]{1,15})/status(?:es)?/(\d+)`)
+	threadCounterRe = regexp.MustCompile(`(^|\s)(?:(?:\(?\d{1,3}\s?/\s?\d{0,3}\)?|🧵)\s*)+$`)
+)
+
+// tweetURL tells whether the URL is a tweet on twitter.com or x.com and
+// returns its author and id
+func tweetURL(rawURL string) (user, id string) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", ""
+	}
+	switch strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.") {
+	case "twitter.com", "mobile.twitter.com", "x.com", "mobile.x.com", "fxtwitter.com", "vxtwitter.com", "fixupx.com":
+		if m := tweetPathRe.FindStringSubmatch(u.Path); m != nil && m[1] != "i" {
+			return m[1], m[2]
+		}
+	}
+	return "", ""
+}
+
+// tweet is a post of a nitter conversation page
+type tweet struct {
+	user string // handle without @, lowercase
+	name string
+	text string
+}
+
+// extractTweetThread unrolls the thread through a nitter instance: the author's
+// posts before and after the linked one, joined into one text. Replies of
+// other people are left out. twitter.com itself serves no readable HTML.
+func (e *ArticleExtractor) extractTweetThread(ctx context.Context, rawURL, user, id string) (*Article, error) {
+	base := strings.TrimRight(e.NitterURL, "/")
+	if base == "" {
+		base = defaultNitterURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/"+user+"/status/"+id, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create nitter request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("Accept", "text/html")
+	resp, err := e.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("nitter request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("nitter HTTP error: %d", resp.StatusCode)
+	}
+	doc, err := html.Parse(io.LimitReader(resp.Body, 5*1024*1024))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse nitter page: %w", err)
+	}
+
+	posts := nitterThread(doc)
+	if len(posts) == 0 {
+		return nil, fmt.Errorf("no tweets on the nitter page")
+	}
+	author := posts[0]
+	for _, p := range posts {
+		if p.user == strings.ToLower(user) {
+			author = p
+			break
+		}
+	}
+	var parts []string
+	for _, p := range posts {
+		if p.user != author.user {
+			continue
+		}
+		// "2/7" and 🧵 ending a post only number the thread
+		if text := strings.TrimSpace(threadCounterRe.ReplaceAllString(p.text, "")); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	text := cleanText(strings.Join(parts, "\n"))
+	if text == "" {
+		return nil, fmt.Errorf("thread has no text")
+	}
+
+	title := firstLine(text, 80)
+	name := author.name
+	if name == "" {
+		name = "@" + author.user
+	}
+	return &Article{
+		Title:       name + ": " + title,
+		TextContent: text,
+		SiteName:    "X",
+		URL:         rawURL,
+	}, nil
+}
+
+// nitterThread collects the posts of the main thread of a nitter status page
+// in order: the ones before the linked post, the post itself and the
+// continuation. The replies section is skipped.
+func nitterThread(doc *html.Node) []tweet {
+	var res []tweet
+	var walk func(n *html.Node, inThread bool)
+	walk = func(n *html.Node, inThread bool) {
+		if n.Type == html.ElementNode {
+			switch {
+			case hasClass(n, "replies"):
+				return
+			case hasClass(n, "main-thread"):
+				inThread = true
+			case inThread && hasClass(n, "timeline-item"):
+				if t, ok := nitterTweet(n); ok {
+					res = append(res, t)
+				}
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, inThread)
+		}
+	}
+	walk(doc, false)
+	return res
+}
+
+// nitterTweet reads the author and the text of a timeline item, quoted
+// tweets inside it are not part of the text
+func nitterTweet(item *html.Node) (tweet, bool) {
+	content := findClass(item, "tweet-content")
+	if content == nil {
+		return tweet{}, false
+	}
+	var res tweet
+	if n := findClass(item, "username"); n != nil {
+		res.user = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(nodeText(n)), "@"))
+	}
+	if n := findClass(item, "fullname"); n != nil {
+		res.name = strings.TrimSpace(nodeText(n))
+	}
+
+	// external links are noise read aloud, mentions and hashtags stay
+	res.text = postText(content, func(a *html.Node) bool { return !strings.HasPrefix(attr(a, "href"), "/") })
+	return res, res.user != ""
+}
+
+// postText renders the text of a social post body, <br> to a newline.
+// Links skipLink reports true for are left out.
+func postText(content *html.Node, skipLink func(a *html.Node) bool) string {
+	var sb strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			sb.WriteString(n.Data)
+			return
+		case n.Type == html.ElementNode && n.DataAtom == atom.Br:
+			sb.WriteString("\n")
+			return
+		case n.Type == html.ElementNode && n.DataAtom == atom.A && skipLink(n):
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(content)
+	return strings.TrimSpace(sb.String())
+}
+
+// findClass returns the first element in n's subtree having the class
+func findClass(n *html.Node, class string) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && hasClass(c, class) {
+			return c
+		}
+		if found := findClass(c, class); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// hasClass reports whether the element's class list has the class
+func hasClass(n *html.Node, class string) bool {
+	for _, f := range strings.Fields(attr(n, "class")) {
+		if f == class {
+			return true
+		}
+	}
+	return false
+}
+
+// attr returns the attribute value of the element, "" if missing
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
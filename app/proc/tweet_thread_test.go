@@ -0,0 +1,79 @@
+package proc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTweetURL(t *testing.T) {
+	tbl := []struct {
+		url      string
+		user, id string
+	}{
+		{"https://x.com/umputun/status/1790000000000000001", "umputun", "1790000000000000001"},
+		{"https://twitter.com/umputun/status/1790000000000000001?s=20", "umputun", "1790000000000000001"},
+		{"https://mobile.twitter.com/umputun/status/17", "umputun", "17"},
+		{"https://x.com/umputun", "", ""},
+		{"https://x.com/i/web/status/17", "", ""},
+		{"https://example.com/umputun/status/17", "", ""},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.url, func(t *testing.T) {
+			user, id := tweetURL(tt.url)
+			assert.Equal(t, tt.user, user)
+			assert.Equal(t, tt.id, id)
+		})
+	}
+}
+
+const testNitterPage = `<html><body><div class="conversation">
+<div class="main-thread">
+ <div class="before-tweet thread-line"><div class="timeline-item">
+  <div class="tweet-header"><a class="fullname" href="/umputun">Umputun</a><a class="username" href="/umputun">@umputun</a></div>
+  <div class="tweet-content media-body">Thread about feeds 🧵 1/3</div>
+ </div></div>
+ <div class="main-tweet"><div class="timeline-item">
+  <div class="tweet-header"><a class="fullname" href="/umputun">Umputun</a><a class="username" href="/umputun">@umputun</a></div>
+  <div class="tweet-content media-body">Second part, see <a href="https://example.com/long/path">example.com/long/…</a> and <a href="/search?q=%23rss">#rss</a><br>new line 2/3</div>
+  <div class="quote"><div class="quote-text">quoted tweet text</div></div>
+ </div></div>
+ <div class="after-tweet thread-line">
+  <div class="timeline-item"><div class="tweet-header"><a class="fullname" href="/bob">Bob</a><a class="username" href="/bob">@bob</a></div>
+   <div class="tweet-content media-body">Someone else in the chain</div></div>
+  <div class="timeline-item"><div class="tweet-header"><a class="fullname" href="/umputun">Umputun</a><a class="username" href="/umputun">@umputun</a></div>
+   <div class="tweet-content media-body">The end. 3/3</div></div>
+ </div>
+</div>
+<div class="replies"><div class="timeline-item">
+ <div class="tweet-header"><a class="username" href="/umputun">@umputun</a></div>
+ <div class="tweet-content media-body">a reply further down</div>
+</div></div>
+</div></body></html>`
+
+func TestExtractTweetThread(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/umputun/status/17" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(testNitterPage))
+	}))
+	defer ts.Close()
+
+	e := NewArticleExtractor()
+	e.NitterURL = ts.URL + "/"
+	article, err := e.Extract(context.Background(), "https://x.com/umputun/status/17")
+	require.NoError(t, err)
+	assert.Equal(t, "Umputun: Thread about feeds", article.Title)
+	assert.Equal(t, "X", article.SiteName)
+	assert.Equal(t, "https://x.com/umputun/status/17", article.URL)
+	assert.Equal(t, "Thread about feeds\nSecond part, see and #rss\nnew line\nThe end.", article.TextContent)
+
+	_, err = e.Extract(context.Background(), "https://x.com/umputun/status/18")
+	require.Error(t, err)
+}
@@ -0,0 +1,103 @@
+package proc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+)
+
+// trackingParams are query parameters that only identify the campaign or the
+// click, never the content. Any "utm_*" parameter is stripped as well.
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "yclid": true, "msclkid": true, "twclid": true, "igshid": true,
+	"mc_cid": true, "mc_eid": true, "_hsenc": true, "_hsmi": true, "mkt_tok": true, "vero_id": true, "vero_conv": true,
+	"oly_anon_id": true, "oly_enc_id": true, "rb_clickid": true, "s_cid": true, "wt_mc": true, "cmpid": true,
+	"ncid": true, "ocid": true, "smid": true, "sr_share": true, "ref_src": true, "ref_url": true, "ref": true,
+	"spm": true, "_ga": true, "_gl": true, "triedRedirect": true,
+}
+
+// StripTracking drops tracking query parameters and the fragment from a URL,
+// the remaining parameters are sorted so the same page always gets the same
+// URL. Returns the input unchanged if it doesn't parse.
+func StripTracking(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	u.Fragment, u.RawFragment = "", ""
+	q := u.Query()
+	for k := range q {
+		if trackingParams[k] || strings.HasPrefix(strings.ToLower(k), "utm_") {
+			q.Del(k)
+		}
+	}
+	u.RawQuery = q.Encode() // sorted by key
+	return u.String()
+}
+
+// URLResolver expands shortened and click-tracking links (t.co, bit.ly,
+// newsletter redirectors) to the URL they point at and strips tracking
+// parameters, so the same article shared via different links gets one ID.
+type URLResolver struct {
+	HTTPClient *http.Client
+}
+
+// resolverUserAgent is deliberately not a browser UA: t.co answers browsers
+// with a JS/meta-refresh page instead of a plain redirect
+const resolverUserAgent = "turnip-link-resolver/1.0"
+
+// NewURLResolver makes a resolver with a short timeout, a slow shortener
+// shouldn't hold the job back
+func NewURLResolver() *URLResolver {
+	return &URLResolver{HTTPClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Resolve follows redirects of rawURL and returns the final URL without
+// tracking parameters. Never fails: on network errors the input is used,
+// extraction will report the real problem.
+func (r *URLResolver) Resolve(ctx context.Context, rawURL string) string {
+	final, err := r.follow(ctx, http.MethodHead, rawURL)
+	if err != nil {
+		// some servers reject HEAD outright, GET is the fallback
+		if final, err = r.follow(ctx, http.MethodGet, rawURL); err != nil {
+			log.Printf("[DEBUG] can't resolve %s: %v", rawURL, err)
+			return StripTracking(rawURL)
+		}
+	}
+	res := StripTracking(final)
+	if res != rawURL {
+		log.Printf("[INFO] resolved %s -> %s", rawURL, res)
+	}
+	return res
+}
+
+// follow makes the request with redirects followed and returns the URL of the last hop
+func (r *URLResolver) follow(ctx context.Context, method, rawURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", resolverUserAgent)
+	resp, err := r.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
+		return "", &url.Error{Op: method, URL: rawURL, Err: http.ErrNotSupported}
+	}
+	return resp.Request.URL.String(), nil
+}
+
+// resolveURL is the canonical form of a link sent to the bot, the offline
+// tracking-parameters cleanup when the resolver isn't set
+func (t *TelegramBot) resolveURL(ctx context.Context, rawURL string) string {
+	if t.URLResolver == nil {
+		return StripTracking(rawURL)
+	}
+	return t.URLResolver.Resolve(ctx, rawURL)
+}
@@ -0,0 +1,70 @@
+package proc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripTracking(t *testing.T) {
+	tbl := []struct {
+		in, want string
+	}{
+		{"https://example.com/post?utm_source=tw&utm_medium=social&id=5", "https://example.com/post?id=5"},
+		{"https://example.com/post?UTM_Campaign=x", "https://example.com/post"},
+		{"https://example.com/post?fbclid=abc#section", "https://example.com/post"},
+		{"https://example.com/post?b=2&a=1&gclid=z", "https://example.com/post?a=1&b=2"},
+		{"https://example.com/post", "https://example.com/post"},
+		{"not a url", "not a url"},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.want, StripTracking(tt.in))
+		})
+	}
+}
+
+func TestURLResolver_Resolve(t *testing.T) {
+	var userAgent string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/s/abc", func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+		http.Redirect(w, r, "/click?to=post", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/click", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/post?id=5&utm_source=newsletter&mc_eid=x", http.StatusFound)
+	})
+	mux.HandleFunc("/post", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("article"))
+	})
+	mux.HandleFunc("/nohead", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		http.Redirect(w, r, "/post?utm_campaign=x", http.StatusFound)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	r := NewURLResolver()
+	ctx := context.Background()
+	assert.Equal(t, ts.URL+"/post?id=5", r.Resolve(ctx, ts.URL+"/s/abc"), "redirect chain followed, tracking stripped")
+	assert.Equal(t, resolverUserAgent, userAgent)
+	assert.Equal(t, ts.URL+"/post", r.Resolve(ctx, ts.URL+"/nohead"), "GET fallback when HEAD isn't allowed")
+	assert.Equal(t, ts.URL+"/post", r.Resolve(ctx, ts.URL+"/post?utm_source=x"), "no redirect, params still cleaned")
+
+	ts.Close()
+	assert.Equal(t, ts.URL+"/s/abc", r.Resolve(ctx, ts.URL+"/s/abc?fbclid=1"), "unreachable, offline cleanup only")
+}
+
+func TestTelegramBot_MakeArticleIDAfterResolve(t *testing.T) {
+	bot := &TelegramBot{}
+	ctx := context.Background()
+	a := bot.makeArticleID(bot.resolveURL(ctx, "https://example.com/post?utm_source=twitter"))
+	b := bot.makeArticleID(bot.resolveURL(ctx, "https://example.com/post?utm_source=newsletter#top"))
+	assert.Equal(t, a, b, "same content, same article ID")
+}
@@ -0,0 +1,221 @@
+package proc
+
+import (
+	"regexp"
+	"strings"
+)
+
+// wordForms are the forms of a word after a number: ru one/few/many
+// (1 доллар, 2 доллара, 5 долларов), en singular/plural/plural
+type wordForms [3]string
+
+// verbalizer rewrites what TTS misreads in a language: currency signs,
+// magnitude suffixes, percents, units and the like become words agreeing
+// with their number. The digits themselves are left to TTS, it reads them
+// well once they stand alone.
+type verbalizer struct {
+	currencies map[string]wordForms // by sign
+	magnitudes map[string]wordForms // by suffix: 3.5B, 20 млн
+	units      map[string]wordForms // by abbreviation, after a number
+	percent    wordForms
+	decimal    string            // decimal separator read by TTS
+	words      map[string]string // terms read letter by letter otherwise
+	letters    map[rune]string   // Latin letters glued to a digit (5G, Wi-Fi 6E)
+	decades    bool              // 1990s → 1990-е
+	slavic     bool              // one/few/many agreement of Russian, singular/plural otherwise
+}
+
+var verbalizers = map[string]*verbalizer{
+	"ru": {
+		currencies: map[string]wordForms{
+			"$": {"доллар", "доллара", "долларов"}, "€": {"евро", "евро", "евро"}, "£": {"фунт", "фунта", "фунтов"},
+			"₽": {"рубль", "рубля", "рублей"}, "¥": {"иена", "иены", "иен"},
+		},
+		magnitudes: map[string]wordForms{
+			"K": {"тысяча", "тысячи", "тысяч"}, "k": {"тысяча", "тысячи", "тысяч"}, "тыс.": {"тысяча", "тысячи", "тысяч"},
+			"M": {"миллион", "миллиона", "миллионов"}, "mn": {"миллион", "миллиона", "миллионов"},
+			"млн": {"миллион", "миллиона", "миллионов"},
+			"B":   {"миллиард", "миллиарда", "миллиардов"}, "bn": {"миллиард", "миллиарда", "миллиардов"},
+			"млрд": {"миллиард", "миллиарда", "миллиардов"},
+			"T":    {"триллион", "триллиона", "триллионов"}, "трлн": {"триллион", "триллиона", "триллионов"},
+		},
+		units: map[string]wordForms{
+			"km/h": {"километр в час", "километра в час", "километров в час"},
+			"км/ч": {"километр в час", "километра в час", "километров в час"},
+			"km":   {"километр", "километра", "километров"}, "км": {"километр", "километра", "километров"},
+			"kg": {"килограмм", "килограмма", "килограммов"}, "кг": {"килограмм", "килограмма", "килограммов"},
+			"cm": {"сантиметр", "сантиметра", "сантиметров"}, "см": {"сантиметр", "сантиметра", "сантиметров"},
+			"mm": {"миллиметр", "миллиметра", "миллиметров"}, "мм": {"миллиметр", "миллиметра", "миллиметров"},
+			"MB": {"мегабайт", "мегабайта", "мегабайт"}, "МБ": {"мегабайт", "мегабайта", "мегабайт"},
+			"GB": {"гигабайт", "гигабайта", "гигабайт"}, "ГБ": {"гигабайт", "гигабайта", "гигабайт"},
+			"TB": {"терабайт", "терабайта", "терабайт"}, "ТБ": {"терабайт", "терабайта", "терабайт"},
+			"MHz": {"мегагерц", "мегагерца", "мегагерц"}, "МГц": {"мегагерц", "мегагерца", "мегагерц"},
+			"GHz": {"гигагерц", "гигагерца", "гигагерц"}, "ГГц": {"гигагерц", "гигагерца", "гигагерц"},
+			"°C": {"градус Цельсия", "градуса Цельсия", "градусов Цельсия"},
+		},
+		percent: wordForms{"процент", "процента", "процентов"},
+		decimal: ",",
+		words:   map[string]string{"Wi-Fi": "вай-фай", "WiFi": "вай-фай", "Bluetooth": "блютус"},
+		letters: map[rune]string{'A': "эй", 'B': "би", 'C': "си", 'D': "ди", 'E': "и", 'F': "эф", 'G': "джи", 'H': "эйч",
+			'I': "ай", 'J': "джей", 'K': "кей", 'L': "эл", 'M': "эм", 'N': "эн", 'O': "оу", 'P': "пи", 'Q': "кью", 'R': "ар",
+			'S': "эс", 'T': "ти", 'U': "ю", 'V': "ви", 'W': "дабл-ю", 'X': "икс", 'Y': "уай", 'Z': "зед"},
+		decades: true,
+		slavic:  true,
+	},
+	"en": {
+		currencies: map[string]wordForms{
+			"$": {"dollar", "dollars", "dollars"}, "€": {"euro", "euros", "euros"}, "£": {"pound", "pounds", "pounds"},
+			"₽": {"ruble", "rubles", "rubles"}, "¥": {"yen", "yen", "yen"},
+		},
+		magnitudes: map[string]wordForms{
+			"K": {"thousand", "thousand", "thousand"}, "k": {"thousand", "thousand", "thousand"},
+			"M": {"million", "million", "million"}, "mn": {"million", "million", "million"},
+			"B": {"billion", "billion", "billion"}, "bn": {"billion", "billion", "billion"},
+			"T": {"trillion", "trillion", "trillion"},
+		},
+		units: map[string]wordForms{
+			"km/h": {"kilometer per hour", "kilometers per hour", "kilometers per hour"},
+			"km":   {"kilometer", "kilometers", "kilometers"}, "kg": {"kilogram", "kilograms", "kilograms"},
+			"cm": {"centimeter", "centimeters", "centimeters"}, "mm": {"millimeter", "millimeters", "millimeters"},
+			"MB": {"megabyte", "megabytes", "megabytes"}, "GB": {"gigabyte", "gigabytes", "gigabytes"},
+			"TB": {"terabyte", "terabytes", "terabytes"}, "MHz": {"megahertz", "megahertz", "megahertz"},
+			"GHz": {"gigahertz", "gigahertz", "gigahertz"}, "°C": {"degree Celsius", "degrees Celsius", "degrees Celsius"},
+		},
+		percent: wordForms{"percent", "percent", "percent"},
+		decimal: ".",
+	},
+}
+
+const (
+	numPattern = `(\d+(?:[.,]\d+)?)`
+	// a number doesn't continue a word or another number before it
+	numStart = `(^|[^\p{L}\p{N}.,])`
+	// nor a word after it
+	wordEnd = `([^\p{L}\p{N}]|$)`
+)
+
+var (
+	currencyBeforeRe = regexp.MustCompile(numStart + `([$€£₽¥])\s?` + numPattern + `(?:\s?(K|k|M|B|T|bn|mn|тыс\.|млн|млрд|трлн))?` + wordEnd)
+	currencyAfterRe  = regexp.MustCompile(numStart + numPattern + `(?:\s?(K|k|M|B|T|bn|mn|тыс\.|млн|млрд|трлн))?\s?([$€£₽¥])`)
+	percentRe        = regexp.MustCompile(numStart + numPattern + `\s?%`)
+	unitRe           = regexp.MustCompile(numStart + numPattern + `\s?(km/h|км/ч|km|км|kg|кг|cm|см|mm|мм|MB|МБ|GB|ГБ|TB|ТБ|MHz|МГц|GHz|ГГц|°C)` + wordEnd)
+	magnitudeRe      = regexp.MustCompile(numStart + numPattern + `\s?(млн|млрд|трлн|тыс\.)` + wordEnd)
+	decadeRe         = regexp.MustCompile(numStart + `(?:'(\d0)|(1\d\d0|20\d0))s` + wordEnd)
+	thousandsRe      = regexp.MustCompile(`\b\d{1,3}(?:,\d{3})+\b`)
+	digitLetterRe    = regexp.MustCompile(numStart + `(\d)([A-Z])` + wordEnd)
+)
+
+// voiceLang returns the language of an Edge TTS voice, "ru" of "ru-RU-DmitryNeural"
+func voiceLang(voice string) string {
+	lang, _, _ := strings.Cut(voice, "-")
+	return strings.ToLower(lang)
+}
+
+// verbalize rewrites the numbers with signs, suffixes and units of the text
+// into words of the language, the text is returned as is for languages
+// without a verbalizer
+func verbalize(text, lang string) string {
+	v, ok := verbalizers[lang]
+	if !ok {
+		return text
+	}
+	for term, word := range v.words {
+		text = strings.ReplaceAll(text, term, word)
+	}
+	if !strings.ContainsAny(text, "0123456789") {
+		return text
+	}
+	if v.decimal == "." {
+		text = thousandsRe.ReplaceAllStringFunc(text, func(s string) string { return strings.ReplaceAll(s, ",", "") })
+	}
+	text = replaceSubmatches(currencyBeforeRe, text, func(m []string) (string, bool) {
+		res, ok := v.amount(m[3], m[4], m[2])
+		return m[1] + res + m[5], ok
+	})
+	text = replaceSubmatches(currencyAfterRe, text, func(m []string) (string, bool) {
+		res, ok := v.amount(m[2], m[3], m[4])
+		return m[1] + res, ok
+	})
+	text = replaceSubmatches(percentRe, text, func(m []string) (string, bool) {
+		return m[1] + v.number(m[2]) + " " + v.form(v.percent, m[2]), true
+	})
+	text = replaceSubmatches(unitRe, text, func(m []string) (string, bool) {
+		unit, ok := v.units[m[3]]
+		return m[1] + v.number(m[2]) + " " + v.form(unit, m[2]) + m[4], ok
+	})
+	text = replaceSubmatches(magnitudeRe, text, func(m []string) (string, bool) {
+		magnitude, ok := v.magnitudes[m[3]]
+		return m[1] + v.number(m[2]) + " " + v.form(magnitude, m[2]) + m[4], ok
+	})
+	if v.decades {
+		text = replaceSubmatches(decadeRe, text, func(m []string) (string, bool) {
+			return m[1] + m[2] + m[3] + "-е" + m[4], true
+		})
+	}
+	if v.letters != nil {
+		text = replaceSubmatches(digitLetterRe, text, func(m []string) (string, bool) {
+			return m[1] + m[2] + " " + v.letters[rune(m[3][0])] + m[4], true
+		})
+	}
+	return text
+}
+
+// amount renders a sum of money, the magnitude (suffix) optional.
+// false when the currency or the magnitude is unknown to the language.
+func (v *verbalizer) amount(num, suffix, sign string) (string, bool) {
+	currency, ok := v.currencies[sign]
+	if !ok {
+		return "", false
+	}
+	if suffix == "" {
+		return v.number(num) + " " + v.form(currency, num), true
+	}
+	magnitude, ok := v.magnitudes[suffix]
+	if !ok {
+		return "", false
+	}
+	return v.number(num) + " " + v.form(magnitude, num) + " " + currency[2], true // 3 миллиарда долларов
+}
+
+// number returns the number with the decimal separator of the language
+func (v *verbalizer) number(num string) string {
+	return strings.NewReplacer(".", v.decimal, ",", v.decimal).Replace(num)
+}
+
+// form returns the form of the word agreeing with the number. A fraction
+// takes the few form (3,5 доллара, 3.5 dollars). In Russian an integer is
+// one, few or many by its last digits, in English one only as 1.
+func (v *verbalizer) form(f wordForms, num string) string {
+	if strings.ContainsAny(num, ".,") {
+		return f[1]
+	}
+	if !v.slavic {
+		if num == "1" {
+			return f[0]
+		}
+		return f[2]
+	}
+	n := 0
+	for _, d := range num[max(len(num)-2, 0):] {
+		n = n*10 + int(d-'0')
+	}
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return f[0]
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return f[1]
+	default:
+		return f[2]
+	}
+}
+
+// replaceSubmatches is ReplaceAllStringFunc getting the submatches, a match
+// fn returns false for is kept as is
+func replaceSubmatches(re *regexp.Regexp, text string, fn func(m []string) (string, bool)) string {
+	return re.ReplaceAllStringFunc(text, func(s string) string {
+		if res, ok := fn(re.FindStringSubmatch(s)); ok {
+			return res
+		}
+		return s
+	})
+}
@@ -0,0 +1,34 @@
+package proc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerbalize(t *testing.T) {
+	tests := []struct {
+		lang, in, want string
+	}{
+		{"ru", "Сделка на $3.5B закрыта", "Сделка на 3,5 миллиарда долларов закрыта"},
+		{"ru", "выручка $1 млрд и $21", "выручка 1 миллиард долларов и 21 доллар"},
+		{"ru", "цена 100 ₽, скидка 25%", "цена 100 рублей, скидка 25 процентов"},
+		{"ru", "стоит 3 €.", "стоит 3 евро."},
+		{"ru", "рост на 1,5% и 12%", "рост на 1,5 процента и 12 процентов"},
+		{"ru", "в 1990s и в '80s", "в 1990-е и в 80-е"},
+		{"ru", "поддержка Wi-Fi 6E и 5G", "поддержка вай-фай 6 и и 5 джи"},
+		{"ru", "до 120 км/ч за 5 км, 2 GB", "до 120 километров в час за 5 километров, 2 гигабайта"},
+		{"ru", "бюджет 20 млн и 3 тыс. человек", "бюджет 20 миллионов и 3 тысячи человек"},
+		{"ru", "таймаут 10s и версия 2.0", "таймаут 10s и версия 2.0"},
+		{"ru", "без чисел", "без чисел"},
+		{"en", "a $3.5B deal, $1 and $1,000", "a 3.5 billion dollars deal, 1 dollar and 1000 dollars"},
+		{"en", "grew 12% to 5 km", "grew 12 percent to 5 kilometers"},
+		{"en", "the 1990s and 5G", "the 1990s and 5G"},
+		{"de", "für $5", "für $5"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, verbalize(tt.in, tt.lang), tt.in)
+	}
+	assert.Equal(t, "ru", voiceLang("ru-RU-DmitryNeural"))
+	assert.Equal(t, "en", voiceLang("en-US-AriaNeural"))
+}
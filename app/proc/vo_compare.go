@@ -0,0 +1,117 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// defaultCompareMethods are the voiceover methods /compare puts side by side
+var defaultCompareMethods = []string{voMethodVot, voMethodSubtitles}
+
+// handleCompare voices a video by two methods and posts both files to the
+// chat, nothing is added to the feed (/compare <url> [method method])
+func (t *TelegramBot) handleCompare(m *tb.Message) {
+	if !t.isAuthorized(m.Sender) {
+		return
+	}
+	usage := fmt.Sprintf(t.tr("Usage: /compare <video_url> [способ способ]\nСпособы: %s\nExample: /compare https://youtu.be/xxx"),
+		strings.Join(defaultVoMethods, ", "))
+	args := commandArgs(m)
+	if (args.len() != 1 && args.len() != 3) || args.unknownFlag() != "" {
+		_, _ = t.Bot.Send(m.Chat, usage)
+		return
+	}
+	videoURL := args.arg(0)
+	if t.extractYouTubeVideoID(videoURL) == "" && !isWebVideoURL(videoURL) {
+		_, _ = t.Bot.Send(m.Chat, t.tr("❌ Invalid video URL"))
+		return
+	}
+	methods := defaultCompareMethods
+	if args.len() == 3 {
+		var err error
+		methods, err = parseVoMethods(args.arg(1) + "," + args.arg(2))
+		if err != nil || len(methods) != 2 {
+			_, _ = t.Bot.Send(m.Chat, usage)
+			return
+		}
+	}
+
+	statusMsg, err := t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("⏳ Сравниваю %s и %s..."), methods[0], methods[1]))
+	if err != nil {
+		log.Printf("[WARN] failed to send compare status: %v", err)
+		return
+	}
+	t.goNamedJob("🆚 "+videoURL, 2*voiceoverJobTimeout, func(ctx context.Context) {
+		if err := t.compareVoiceovers(ctx, m.Chat, statusMsg, videoURL, methods); err != nil {
+			log.Printf("[WARN] compare of %s failed: %v", videoURL, err)
+			t.edit(statusMsg, "❌ "+t.userErrorText(err))
+		}
+	})
+}
+
+// compareVoiceovers makes the voiceover of the video by each of the methods
+// in turn and sends the files to the chat, dropping them afterwards. A
+// method failing is reported and the next one still runs.
+func (t *TelegramBot) compareVoiceovers(ctx context.Context, chat *tb.Chat, statusMsg *tb.Message, videoURL string,
+	methods []string) error {
+	videoID := sourceID(videoURL)
+	t.edit(statusMsg, t.tr("⏳ Получаю информацию о видео..."))
+	info, err := t.videoInfo(ctx, videoURL)
+	if err != nil {
+		return fmt.Errorf("failed to get video info: %w", err)
+	}
+
+	probes := t.probeVoMethods(ctx, videoURL, info, methods, "")
+	defer probes.close(func(p voProbe) { t.SubtitleSvc.Cleanup(p.subFile) })
+	var summary []string
+	for i, method := range methods {
+		line := fmt.Sprintf("%c %s: ", 'A'+i, method)
+		started := time.Now()
+		probe := probes.wait(method)
+		res, err := voResult{}, probe.err
+		if err == nil {
+			res, err = t.runVoMethod(ctx, method, probe, statusMsg, videoURL, videoID, info, t.preset(""))
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			log.Printf("[WARN] compare: %s of %s failed: %v", method, videoID, err)
+			summary = append(summary, line+"❌ "+t.userErrorText(err))
+			continue
+		}
+		line += fmt.Sprintf(t.tr("готово за %s"), t.formatDuration(time.Since(started).Round(time.Second)))
+		if err := t.sendCompared(chat, res.file, fmt.Sprintf("%c · %s · %s", 'A'+i, method, info.Title)); err != nil {
+			line += ", " + err.Error()
+		}
+		summary = append(summary, line)
+		removeVoiceoverSources(ytfeed.Entry{Sources: append(res.sources, res.file)})
+	}
+	t.edit(statusMsg, fmt.Sprintf("🆚 %s\n%s", info.Title, strings.Join(summary, "\n")))
+	return nil
+}
+
+// sendCompared sends a compared voiceover to the chat as audio
+func (t *TelegramBot) sendCompared(chat *tb.Chat, file, title string) error {
+	fi, err := os.Stat(file)
+	if err != nil {
+		return fmt.Errorf("файл не найден")
+	}
+	if fi.Size() > telegramBotFileLimit {
+		return fmt.Errorf("файл %d МБ больше лимита Telegram", fi.Size()/1024/1024)
+	}
+	audio := &tb.Audio{File: tb.FromDisk(file), FileName: sanitizeFileName(title) + ".mp3", Title: title}
+	if _, err := t.Bot.Send(chat, audio); err != nil {
+		log.Printf("[WARN] failed to send compared voiceover %s: %v", file, err)
+		return fmt.Errorf("не удалось отправить файл")
+	}
+	return nil
+}
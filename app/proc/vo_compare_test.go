@@ -0,0 +1,70 @@
+package proc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	"github.com/umputun/feed-master/app/youtube/feed/mocks"
+)
+
+func TestTelegramBot_HandleCompareUsage(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+
+	bot.handleCompare(testMessage(testBotUserID, "/compare"))
+	bot.handleCompare(testMessage(testBotUserID, "/compare https://youtu.be/abc vot-cli vot-cli"))
+	bot.handleCompare(testMessage(testBotUserID, "/compare https://youtu.be/abc vot-cli whisper"))
+	bot.handleCompare(testMessage(testBotUserID, "/compare youtu"))
+	sent := stub.texts("sendMessage")
+	require.Len(t, sent, 4)
+	for _, s := range sent[:3] {
+		assert.True(t, strings.HasPrefix(s, "Usage: /compare"), s)
+	}
+	assert.Equal(t, "❌ Invalid video URL", sent[3])
+}
+
+func TestTelegramBot_CompareVoiceovers(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Downloader = &ytfeed.Downloader{Runner: &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			return []byte(`{"id":"abc123","title":"Talk","duration":60}`), nil, nil
+		},
+	}}
+	bot.VoiceoverSvc.Runner = &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			return []byte(`{"formats":[]}`), nil, nil // no dub
+		},
+	}
+	bot.SubtitleSvc.Runner = &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			vtt := "WEBVTT\n\n00:00:00.000 --> 00:00:02.000\nHello there.\n"
+			return nil, nil, os.WriteFile(outputArg(args)+".en.vtt", []byte(vtt), 0o600)
+		},
+	}
+	statusMsg, err := bot.Bot.Send(&tb.Chat{ID: testBotUserID}, "⏳")
+	require.NoError(t, err)
+
+	err = bot.compareVoiceovers(context.Background(), &tb.Chat{ID: testBotUserID}, statusMsg, "https://youtu.be/abc123",
+		[]string{voMethodDubbed, voMethodSubtitles})
+	require.NoError(t, err)
+	assert.Equal(t, 1, stub.count("sendAudio"), "the voiceover made is posted")
+	edits := stub.texts("editMessageText")
+	require.NotEmpty(t, edits)
+	summary := edits[len(edits)-1]
+	assert.Contains(t, summary, "🆚 Talk\n")
+	assert.Contains(t, summary, "A youtube-dubbed: ❌ 🎬 Официального русского дубляжа нет")
+	assert.Contains(t, summary, "B subtitles-tts: готово за")
+
+	left, err := filepath.Glob(filepath.Join(bot.FilesLocation, "vo_*"))
+	require.NoError(t, err)
+	assert.Empty(t, left, "nothing kept, not in the feed")
+}
@@ -0,0 +1,313 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	"github.com/umputun/feed-master/app/config"
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// voiceover methods, as recorded in ytfeed.Processing and named in the config
+const (
+	voMethodDubbed    = "youtube-dubbed" // official YouTube dub track
+	voMethodVot       = "vot-cli"        // Yandex machine voiceover
+	voMethodSubtitles = "subtitles-tts"  // translated subtitles read by TTS
+	// subtitles-tts of a video without subtitles, made by transcription; not a
+	// method of its own in the config
+	voMethodTranscript = "transcript-tts"
+)
+
+// defaultVoMethods is the order methods are tried in unless configured
+var defaultVoMethods = []string{voMethodDubbed, voMethodVot, voMethodSubtitles}
+
+// defaultVotMaxDuration is the longest video vot-cli takes, it refuses longer ones
+const defaultVotMaxDuration = 4 * time.Hour
+
+// voResult is a voiceover made by one of the methods
+type voResult struct {
+	file       string
+	duration   int      // seconds, 0 = not known yet
+	sources    []string // kept inputs of a vot-cli voiceover for /remix
+	processing ytfeed.Processing
+}
+
+// langCodeRe matches a language code of a command, "en" or "pt-br"
+var langCodeRe = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+// voMethodAliases are the short names of the methods in commands (--method=subs)
+var voMethodAliases = map[string]string{"dub": voMethodDubbed, "dubbed": voMethodDubbed, "vot": voMethodVot,
+	"subs": voMethodSubtitles, "subtitles": voMethodSubtitles}
+
+// parseVoMethods parses the comma separated methods of a command, by their
+// names or aliases
+func parseVoMethods(list string) ([]string, error) {
+	var res []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if alias, ok := voMethodAliases[name]; ok {
+			name = alias
+		}
+		if !slices.Contains(defaultVoMethods, name) {
+			return nil, fmt.Errorf("unknown voiceover method %q", name)
+		}
+		if !slices.Contains(res, name) {
+			res = append(res, name)
+		}
+	}
+	return res, nil
+}
+
+// checkVoMethods validates the configured method names
+func checkVoMethods(conf config.VoiceoverMethods) error {
+	for _, m := range conf.Methods {
+		if !slices.Contains(defaultVoMethods, m) {
+			return fmt.Errorf("unknown voiceover method %q, expected one of %v", m, defaultVoMethods)
+		}
+	}
+	return nil
+}
+
+// voMethods returns the methods taking a video of the duration, in the order
+// they are tried
+func (t *TelegramBot) voMethods(duration time.Duration) []string {
+	methods := t.VoMethods.Methods
+	if len(methods) == 0 {
+		methods = defaultVoMethods
+	}
+	maxDuration := func(m string) time.Duration {
+		if d, ok := t.VoMethods.MaxDuration[m]; ok {
+			return d
+		}
+		if m == voMethodVot {
+			return defaultVotMaxDuration
+		}
+		return 0
+	}
+	minDuration := func(m string) time.Duration {
+		if d, ok := t.VoMethods.MinDuration[m]; ok {
+			return d
+		}
+		if m == voMethodSubtitles && slices.Contains(methods, voMethodVot) {
+			return maxDuration(voMethodVot) // subtitles for what vot-cli doesn't take
+		}
+		return 0
+	}
+	var res []string
+	for _, m := range methods {
+		if maxDur := maxDuration(m); maxDur > 0 && duration > maxDur {
+			continue
+		}
+		if duration < minDuration(m) {
+			continue
+		}
+		res = append(res, m)
+	}
+	return res
+}
+
+// voFailure is the error of a voiceover no method made: ErrNoDub for the
+// official dub only, ErrTooLong when vot-cli was left out for the duration
+// and subtitles failed too, the error of the last method tried otherwise
+func (t *TelegramBot) voFailure(methods []string, info *ytfeed.VideoInfo, lastErr error) error {
+	configured := t.VoMethods.Methods
+	if len(configured) == 0 {
+		configured = defaultVoMethods
+	}
+	votSkipped := slices.Contains(configured, voMethodVot) && !slices.Contains(methods, voMethodVot)
+	switch {
+	case lastErr == nil:
+		return fmt.Errorf("no voiceover method takes %s, a %s video", info.Title,
+			time.Duration(info.Duration)*time.Second)
+	case votSkipped && errors.Is(lastErr, ErrNoSubtitles):
+		return fmt.Errorf("%w: %w", ErrTooLong, lastErr)
+	}
+	return lastErr
+}
+
+// voProbe is what a method has to work with, checked before it runs
+type voProbe struct {
+	track   *AudioTrack // official dub track
+	subFile string      // downloaded subtitles
+	subLang string      // language of the subtitles
+	// no subtitles, they're made by transcribing the audio
+	transcribe bool
+	err        error // the method can't make the voiceover
+}
+
+// voProbes are the checks of the methods, running at once: the dub track
+// listing, the subtitles download and the vot-cli lookup
+type voProbes struct {
+	cancel  context.CancelFunc
+	results map[string]chan voProbe
+	done    map[string]voProbe // checks already waited for
+}
+
+// probeVoMethods starts the checks of the methods, lang is the language
+// spoken in the video, "" when not known
+func (t *TelegramBot) probeVoMethods(ctx context.Context, videoURL string, info *ytfeed.VideoInfo,
+	methods []string, lang string) *voProbes {
+	ctx, cancel := context.WithCancel(ctx)
+	res := &voProbes{cancel: cancel, results: make(map[string]chan voProbe, len(methods)), done: map[string]voProbe{}}
+	for _, m := range methods {
+		ch := make(chan voProbe, 1)
+		res.results[m] = ch
+		go func() { ch <- t.probeVoMethod(ctx, m, videoURL, info, lang) }()
+	}
+	return res
+}
+
+// wait returns the check of the method once it's done, the checks of the
+// methods after it keep running
+func (p *voProbes) wait(method string) voProbe {
+	if r, ok := p.done[method]; ok {
+		return r
+	}
+	ch, ok := p.results[method]
+	if !ok {
+		return voProbe{err: fmt.Errorf("voiceover method %q not checked", method)}
+	}
+	r := <-ch
+	p.done[method] = r
+	return r
+}
+
+// close stops the checks still running and passes all the results to cleanup,
+// the ones not waited for in the background as they finish
+func (p *voProbes) close(cleanup func(voProbe)) {
+	p.cancel()
+	for m, ch := range p.results {
+		if r, ok := p.done[m]; ok {
+			cleanup(r)
+			continue
+		}
+		go func() { cleanup(<-ch) }()
+	}
+}
+
+// probeVoMethod checks the method can voice the video
+func (t *TelegramBot) probeVoMethod(ctx context.Context, method, videoURL string, info *ytfeed.VideoInfo,
+	lang string) voProbe {
+	switch method {
+	case voMethodDubbed:
+		tracks, err := t.VoiceoverSvc.GetDubbedAudioTracks(ctx, videoURL)
+		if err != nil {
+			return voProbe{err: fmt.Errorf("failed to list audio tracks: %w", err)}
+		}
+		track := t.VoiceoverSvc.FindDubbedTrack(tracks)
+		if track == nil {
+			return voProbe{err: fmt.Errorf("%s: %w", info.Title, ErrNoDub)}
+		}
+		return voProbe{track: track}
+	case voMethodVot:
+		if !IsVotCliAvailable() {
+			return voProbe{err: errors.New("vot-cli not installed")}
+		}
+		return voProbe{}
+	case voMethodSubtitles:
+		file, subLang, err := t.SubtitleSvc.DownloadSubtitlesLang(ctx, videoURL, lang)
+		if errors.Is(err, ErrNoSubtitles) && t.canTranscribe() {
+			return voProbe{transcribe: true}
+		}
+		if err != nil {
+			return voProbe{err: fmt.Errorf("не удалось скачать субтитры: %w", err)}
+		}
+		return voProbe{subFile: file, subLang: subLang}
+	}
+	return voProbe{err: fmt.Errorf("unknown voiceover method %q", method)}
+}
+
+// runVoMethod makes the voiceover of the video with the method, from what its
+// check found
+func (t *TelegramBot) runVoMethod(ctx context.Context, method string, probe voProbe, statusMsg *tb.Message,
+	videoURL, videoID string, info *ytfeed.VideoInfo, preset config.Preset) (voResult, error) {
+	switch method {
+	case voMethodDubbed:
+		return t.voViaDub(ctx, statusMsg, videoURL, videoID, info, probe.track)
+	case voMethodVot:
+		return t.voViaVot(ctx, statusMsg, videoURL, info, preset.Lang)
+	case voMethodSubtitles:
+		if probe.transcribe {
+			return t.voViaTranscript(ctx, statusMsg, videoURL, videoID, info, preset)
+		}
+		file, dur, p, err := t.voiceSubtitles(ctx, statusMsg, videoURL, videoID, probe.subFile, probe.subLang, info,
+			t.ttsFor(preset))
+		return voResult{file: file, duration: dur, processing: p}, err
+	}
+	return voResult{}, fmt.Errorf("unknown voiceover method %q", method)
+}
+
+// voViaTranscript voices the subtitles made by transcribing the video, for
+// the videos without any
+func (t *TelegramBot) voViaTranscript(ctx context.Context, statusMsg *tb.Message, videoURL, videoID string,
+	info *ytfeed.VideoInfo, preset config.Preset) (voResult, error) {
+	subFile, lang, err := t.transcribeVideo(ctx, statusMsg, videoURL, videoID, info)
+	if err != nil {
+		return voResult{}, err
+	}
+	defer t.SubtitleSvc.Cleanup(subFile)
+	file, dur, p, err := t.voiceSubtitles(ctx, statusMsg, videoURL, videoID, subFile, lang, info, t.ttsFor(preset))
+	if err != nil {
+		return voResult{}, err
+	}
+	if p.Method == voMethodSubtitles { // a hybrid dub stays one
+		p.Method = voMethodTranscript
+		p.Settings = settingsHash(p)
+	}
+	return voResult{file: file, duration: dur, processing: p}, nil
+}
+
+// voViaDub downloads the official Russian dub track of the video
+func (t *TelegramBot) voViaDub(ctx context.Context, statusMsg *tb.Message, videoURL, videoID string,
+	info *ytfeed.VideoInfo, track *AudioTrack) (voResult, error) {
+	log.Printf("[INFO] found YouTube dubbed track (lang=%s) for %s", track.Language, videoID)
+	t.edit(statusMsg, fmt.Sprintf(t.tr("🎬 Скачиваю дубляж YouTube: %s...%s"), info.Title, t.etaLine(ctx, etaDownload, info.Duration)))
+
+	started := time.Now()
+	limit := t.dubSizeLimit(info.ChannelID)
+	result, err := t.VoiceoverSvc.DownloadDubbedTrack(ctx, videoURL, track, limit)
+	if err != nil {
+		if errors.Is(err, ErrFileTooLarge) {
+			t.edit(statusMsg, fmt.Sprintf(t.tr("⚠️ Дубляж больше лимита (%d МБ), перевожу сам: %s..."),
+				limit/(1024*1024), info.Title))
+		}
+		return voResult{}, fmt.Errorf("failed to download dubbed track: %w", err)
+	}
+	t.observeETA(etaDownload, info.Duration, time.Since(started))
+	log.Printf("[INFO] downloaded YouTube dubbed track: %s", result.FilePath)
+	return voResult{file: result.FilePath, processing: t.processing(voMethodDubbed, nil, nil)}, nil
+}
+
+// voViaVot gets the vot-cli voiceover of the video in the language ("" is
+// detected), keeping its sources for /remix when configured
+func (t *TelegramBot) voViaVot(ctx context.Context, statusMsg *tb.Message, videoURL string,
+	info *ytfeed.VideoInfo, lang string) (voResult, error) {
+	t.edit(statusMsg, fmt.Sprintf(t.tr("🎙 Скачиваю озвучку (vot-cli): %s...%s"), info.Title, t.etaLine(ctx, etaVoiceover, info.Duration)))
+	started := time.Now()
+	result, err := t.VoiceoverSvc.TranslateVideoFrom(ctx, videoURL, lang)
+	if err != nil {
+		return voResult{}, fmt.Errorf("failed to get voiceover: %w", err)
+	}
+	t.observeETA(etaVoiceover, info.Duration, time.Since(started))
+	log.Printf("[INFO] voiceover downloaded via vot-cli: %s (size: %d bytes)", result.FilePath, result.FileSize)
+
+	res := voResult{file: result.FilePath}
+	if t.VoSources.Keep {
+		t.edit(statusMsg, fmt.Sprintf(t.tr("🎚 Сохраняю оригинальную дорожку: %s..."), info.Title))
+		res.sources = t.keepVoiceoverSources(ctx, videoURL, result.FilePath)
+	}
+	mix := 0.0 // level of the original mixed in
+	if len(res.sources) > 0 {
+		mix = t.VoSources.OriginalVolume
+	}
+	res.processing = t.processing(voMethodVot, nil, nil, mix)
+	return res, nil
+}
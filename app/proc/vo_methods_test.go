@@ -0,0 +1,155 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/feed-master/app/config"
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	"github.com/umputun/feed-master/app/youtube/feed/mocks"
+)
+
+func TestTelegramBot_VoMethods(t *testing.T) {
+	tests := []struct {
+		name     string
+		conf     config.VoiceoverMethods
+		duration time.Duration
+		want     []string
+	}{
+		{name: "default, short", duration: time.Hour, want: []string{voMethodDubbed, voMethodVot}},
+		{name: "default, long", duration: 5 * time.Hour, want: []string{voMethodDubbed, voMethodSubtitles}},
+		{name: "subtitles always", duration: time.Hour,
+			conf: config.VoiceoverMethods{Methods: []string{voMethodSubtitles, voMethodDubbed}},
+			want: []string{voMethodSubtitles, voMethodDubbed}},
+		{name: "vot only", duration: 5 * time.Hour, conf: config.VoiceoverMethods{Methods: []string{voMethodVot}}},
+		{name: "lower vot limit, subtitles take over", duration: 3 * time.Hour,
+			conf: config.VoiceoverMethods{MaxDuration: map[string]time.Duration{voMethodVot: 2 * time.Hour}},
+			want: []string{voMethodDubbed, voMethodSubtitles}},
+		{name: "subtitles as a fallback of vot", duration: time.Hour,
+			conf: config.VoiceoverMethods{MinDuration: map[string]time.Duration{voMethodSubtitles: 0}},
+			want: []string{voMethodDubbed, voMethodVot, voMethodSubtitles}},
+		{name: "no limit of vot", duration: 5 * time.Hour,
+			conf: config.VoiceoverMethods{Methods: []string{voMethodVot}, MaxDuration: map[string]time.Duration{voMethodVot: 0}},
+			want: []string{voMethodVot}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &TelegramBot{VoMethods: tt.conf}
+			assert.Equal(t, tt.want, bot.voMethods(tt.duration))
+		})
+	}
+}
+
+func TestTelegramBot_VoFailure(t *testing.T) {
+	bot := &TelegramBot{}
+	info := &ytfeed.VideoInfo{Title: "Talk", Duration: 5 * 3600}
+	noSubs := fmt.Errorf("не удалось скачать субтитры: %w", ErrNoSubtitles)
+
+	err := bot.voFailure([]string{voMethodDubbed, voMethodSubtitles}, info, noSubs)
+	assert.ErrorIs(t, err, ErrTooLong, "vot-cli left out for the duration")
+	assert.ErrorIs(t, err, ErrNoSubtitles)
+
+	bot.VoMethods.Methods = []string{voMethodDubbed, voMethodSubtitles}
+	err = bot.voFailure([]string{voMethodDubbed, voMethodSubtitles}, info, noSubs)
+	assert.NotErrorIs(t, err, ErrTooLong, "vot-cli not configured")
+
+	votErr := errors.New("vot failed")
+	assert.Equal(t, votErr, bot.voFailure([]string{voMethodVot}, info, votErr))
+	assert.ErrorContains(t, bot.voFailure(nil, info, nil), "no voiceover method takes Talk, a 5h0m0s video")
+}
+
+func TestCheckVoMethods(t *testing.T) {
+	require.NoError(t, checkVoMethods(config.VoiceoverMethods{}))
+	require.NoError(t, checkVoMethods(config.VoiceoverMethods{Methods: []string{voMethodSubtitles}}))
+	assert.ErrorContains(t, checkVoMethods(config.VoiceoverMethods{Methods: []string{"subtitles"}}), `unknown voiceover method "subtitles"`)
+}
+
+func TestParseVoMethods(t *testing.T) {
+	methods, err := parseVoMethods("subs, VOT,subtitles-tts")
+	require.NoError(t, err)
+	assert.Equal(t, []string{voMethodSubtitles, voMethodVot}, methods)
+	methods, err = parseVoMethods("dub")
+	require.NoError(t, err)
+	assert.Equal(t, []string{voMethodDubbed}, methods)
+	_, err = parseVoMethods("whisper")
+	assert.ErrorContains(t, err, `unknown voiceover method "whisper"`)
+}
+
+func TestTelegramBot_ProbeVoMethods(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	subsStarted := make(chan struct{})
+	var once sync.Once
+	bot.VoiceoverSvc.Runner = &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			select {
+			case <-subsStarted: // the subtitles are fetched at the same time
+			case <-time.After(5 * time.Second):
+				return nil, nil, errors.New("subtitles not fetched concurrently")
+			}
+			return []byte(dumpJSONWithDubs), nil, nil
+		},
+	}
+	bot.SubtitleSvc.Runner = &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			once.Do(func() { close(subsStarted) })
+			vtt := "WEBVTT\n\n00:00:00.000 --> 00:00:02.000\nHello there.\n"
+			return nil, nil, os.WriteFile(outputArg(args)+".en.vtt", []byte(vtt), 0o600)
+		},
+	}
+	info := &ytfeed.VideoInfo{ID: "abc123", Title: "Talk"}
+
+	probes := bot.probeVoMethods(context.Background(), "https://www.youtube.com/watch?v=abc123", info,
+		[]string{voMethodDubbed, voMethodSubtitles}, "")
+	dub := probes.wait(voMethodDubbed)
+	require.NoError(t, dub.err)
+	assert.Equal(t, "ru", dub.track.Language)
+	subs := probes.wait(voMethodSubtitles)
+	require.NoError(t, subs.err)
+	assert.Equal(t, "en", subs.subLang)
+	assert.FileExists(t, subs.subFile)
+	assert.Equal(t, subs, probes.wait(voMethodSubtitles), "a check is waited for once")
+	assert.ErrorContains(t, probes.wait(voMethodVot).err, "not checked")
+
+	probes.close(func(p voProbe) { bot.SubtitleSvc.Cleanup(p.subFile) })
+	assert.NoFileExists(t, subs.subFile, "cleaned up on close")
+
+	cleaned := make(chan voProbe, 1)
+	probes = bot.probeVoMethods(context.Background(), "https://www.youtube.com/watch?v=abc123", info,
+		[]string{voMethodSubtitles}, "")
+	probes.close(func(p voProbe) { cleaned <- p })
+	select {
+	case p := <-cleaned:
+		bot.SubtitleSvc.Cleanup(p.subFile)
+	case <-time.After(5 * time.Second):
+		t.Fatal("a check not waited for isn't cleaned up")
+	}
+}
+
+func TestTelegramBot_ProbeVoMethodsTranscribes(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	bot.SubtitleSvc.Runner = &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			return nil, nil, nil // no subtitles written
+		},
+	}
+	info := &ytfeed.VideoInfo{Title: "Talk"}
+
+	probes := bot.probeVoMethods(context.Background(), "https://vimeo.com/123", info, []string{voMethodSubtitles}, "")
+	assert.ErrorIs(t, probes.wait(voMethodSubtitles).err, ErrNoSubtitles, "no transcriber")
+	probes.close(func(voProbe) {})
+
+	bot.NotesSvc = &NotesService{Transcriber: &TranscribeService{}}
+	probes = bot.probeVoMethods(context.Background(), "https://vimeo.com/123", info, []string{voMethodSubtitles}, "")
+	p := probes.wait(voMethodSubtitles)
+	require.NoError(t, p.err)
+	assert.True(t, p.transcribe)
+	probes.close(func(voProbe) {})
+}
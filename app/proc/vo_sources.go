@@ -0,0 +1,214 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// voSourceFiles names the kept inputs of a voiceover episode:
+// vo_x.mp3 → vo_x.voice.mp3 (translated voice) and vo_x.orig.mp3 (original audio)
+func voSourceFiles(file string) (voice, original string) {
+	base := strings.TrimSuffix(file, filepath.Ext(file))
+	return base + ".voice.mp3", base + ".orig.mp3"
+}
+
+// Mix writes the voice over the original audio, turned down to volume, into
+// dst. The mix lasts as long as the voice; volume 0 re-encodes the voice alone.
+func (f *AudioFinalizer) Mix(ctx context.Context, voice, original, dst string, volume float64) error {
+	if volume < 0 || volume > 1 {
+		return fmt.Errorf("original volume %g out of the 0-1 range", volume)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+
+	tmp := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".mix.tmp")
+	defer os.Remove(tmp) //nolint:errcheck // gone after a successful rename
+
+	args := []string{"-nostdin", "-y", "-v", "error", "-i", voice}
+	if volume > 0 {
+		args = append(args, "-i", original, "-filter_complex",
+			"[1:a]volume="+strconv.FormatFloat(volume, 'f', -1, 64)+"[bg];[0:a][bg]amix=inputs=2:duration=first:normalize=0[out]",
+			"-map", "[out]")
+	} else {
+		args = append(args, "-map", "0:a")
+	}
+	args = append(args, "-c:a", "libmp3lame", "-q:a", "5", "-id3v2_version", "3", "-write_xing", "1", "-f", "mp3", tmp)
+	if _, stderr, err := f.runner().Run(ctx, "ffmpeg", args...); err != nil {
+		return fmt.Errorf("ffmpeg mix failed: %w, stderr: %s", err, lastLines(string(stderr), 5))
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return fmt.Errorf("rename mixed file: %w", err)
+	}
+	return nil
+}
+
+// keepVoiceoverSources saves the voice track of a fresh vot-cli voiceover and
+// downloads the original audio next to it, then mixes the two into file when
+// an original volume is set. Best-effort: on failure the episode stays the
+// plain voice track. Returns the kept files, nil if none.
+func (t *TelegramBot) keepVoiceoverSources(ctx context.Context, videoURL, file string) []string {
+	if !t.VoSources.Keep || t.Finalizer == nil {
+		return nil
+	}
+	voice, original := voSourceFiles(file)
+	if err := copyFile(file, voice); err != nil {
+		log.Printf("[WARN] can't keep voice track of %s: %v", filepath.Base(file), err)
+		return nil
+	}
+	if err := t.VoiceoverSvc.DownloadOriginalAudio(ctx, videoURL, original); err != nil {
+		log.Printf("[WARN] can't keep original audio of %s: %v", filepath.Base(file), err)
+		_ = os.Remove(voice)
+		_ = os.Remove(original)
+		return nil
+	}
+	if t.VoSources.OriginalVolume > 0 {
+		if err := t.Finalizer.Mix(ctx, voice, original, file, t.VoSources.OriginalVolume); err != nil {
+			log.Printf("[WARN] can't mix %s, keeping the voice only: %v", filepath.Base(file), err)
+		}
+	}
+	return []string{voice, original}
+}
+
+// removeVoiceoverSources deletes the kept inputs of a removed episode, they
+// never go to R2
+func removeVoiceoverSources(entry ytfeed.Entry) {
+	for _, f := range entry.Sources {
+		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+			log.Printf("[WARN] failed to delete voiceover source %s: %v", f, err)
+		}
+	}
+}
+
+// trimVoiceoverSources keeps the kept inputs under the MaxSizeMB quota,
+// dropping those of the oldest episodes first. The episodes stay, only the
+// remix goes.
+func (t *TelegramBot) trimVoiceoverSources() {
+	if t.VoSources.MaxSizeMB <= 0 {
+		return
+	}
+	entries, err := t.Store.Load(t.FeedName, 0)
+	if err != nil {
+		log.Printf("[WARN] failed to load entries for the sources quota: %v", err)
+		return
+	}
+	limit := int64(t.VoSources.MaxSizeMB) * 1024 * 1024
+	var total int64
+	for _, e := range entries { // newest first
+		if len(e.Sources) == 0 {
+			continue
+		}
+		var size int64
+		for _, f := range e.Sources {
+			if st, err := os.Stat(f); err == nil {
+				size += st.Size()
+			}
+		}
+		if total+size <= limit {
+			total += size
+			continue
+		}
+		removeVoiceoverSources(e)
+		e.Sources = nil
+		if err := t.Store.UpdateEntry(e); err != nil {
+			log.Printf("[WARN] failed to drop sources of %s: %v", e.VideoID, err)
+			continue
+		}
+		log.Printf("[INFO] dropped voiceover sources of %s, over the %d MB quota", e.VideoID, t.VoSources.MaxSizeMB)
+	}
+}
+
+// handleRemix rebuilds a voiceover episode from its kept sources with another
+// level of the original (/remix N [volume])
+func (t *TelegramBot) handleRemix(m *tb.Message) {
+	if !t.isAuthorized(m.Sender) {
+		return
+	}
+	usage := t.tr("Usage: /remix N [громкость оригинала 0-1]\nExample: /remix 1 0.3")
+	args := commandArgs(m)
+	idx, volume := 1, t.VoSources.OriginalVolume
+	if args.len() > 0 {
+		if n, err := strconv.Atoi(args.arg(0)); err == nil && n >= 1 {
+			idx = n
+		} else {
+			_, _ = t.Bot.Send(m.Chat, usage)
+			return
+		}
+	}
+	if args.len() > 1 {
+		v, err := strconv.ParseFloat(strings.Replace(args.arg(1), ",", ".", 1), 64)
+		if err != nil || v < 0 || v > 1 {
+			_, _ = t.Bot.Send(m.Chat, usage)
+			return
+		}
+		volume = v
+	}
+	if t.Finalizer == nil {
+		_, _ = t.Bot.Send(m.Chat, t.tr("❌ Нет ffmpeg, пересвести нечем"))
+		return
+	}
+
+	entries, err := t.Store.Load(t.FeedName, t.MaxItems)
+	if err != nil {
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Error: %v"), err))
+		return
+	}
+	if idx > len(entries) {
+		_, _ = t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("Only %d entries in feed."), len(entries)))
+		return
+	}
+	entry := entries[idx-1]
+	if len(entry.Sources) != 2 {
+		_, _ = t.Bot.Send(m.Chat, t.tr("❌ Для этого эпизода исходники не сохранены (vo_sources.keep)"))
+		return
+	}
+
+	statusMsg, _ := t.Bot.Send(m.Chat, fmt.Sprintf(t.tr("🎚 Пересвожу %s..."), entry.Title))
+	t.goJob(30*time.Minute, func(ctx context.Context) {
+		text := fmt.Sprintf(t.tr("✅ Пересведено: %s (оригинал %.0f%%)"), entry.Title, volume*100)
+		if err := t.remixEpisode(ctx, entry, volume); err != nil {
+			log.Printf("[WARN] remix of %s failed: %v", entry.VideoID, err)
+			text = "❌ " + t.userErrorText(err)
+		}
+		if statusMsg != nil {
+			t.edit(statusMsg, text)
+		}
+	})
+}
+
+// remixEpisode mixes the kept sources of the entry into its file again and
+// re-publishes it (sped-up copy and R2 upload included)
+func (t *TelegramBot) remixEpisode(ctx context.Context, entry ytfeed.Entry, volume float64) error {
+	voice, original := entry.Sources[0], entry.Sources[1]
+	for _, f := range entry.Sources {
+		if _, err := os.Stat(f); err != nil {
+			return fmt.Errorf("voiceover source %s is gone: %w", filepath.Base(f), err)
+		}
+	}
+	if err := t.Finalizer.Mix(ctx, voice, original, entry.File, volume); err != nil {
+		return err
+	}
+	if err := entry.SetIntegrity(); err != nil {
+		log.Printf("[WARN] failed to checksum %s: %v", entry.File, err)
+	}
+	if t.DurationSvc != nil {
+		if dur := t.DurationSvc.File(entry.File); dur > 0 {
+			entry.Duration = dur
+		}
+	}
+	if err := t.Store.UpdateEntry(entry); err != nil {
+		return fmt.Errorf("failed to update %s: %w", entry.VideoID, err)
+	}
+	log.Printf("[INFO] remixed %s with the original at %g", entry.VideoID, volume)
+	t.offloadMedia(entry)
+	return nil
+}
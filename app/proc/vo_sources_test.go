@@ -0,0 +1,156 @@
+package proc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/feed-master/app/config"
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	"github.com/umputun/feed-master/app/youtube/feed/mocks"
+)
+
+func TestVoSourceFiles(t *testing.T) {
+	voice, original := voSourceFiles("/srv/yt/vo_abc_1.mp3")
+	assert.Equal(t, "/srv/yt/vo_abc_1.voice.mp3", voice)
+	assert.Equal(t, "/srv/yt/vo_abc_1.orig.mp3", original)
+}
+
+// sourcesRunner fakes yt-dlp writing the original audio and ffmpeg writing
+// the mix, remembering the ffmpeg arguments
+func sourcesRunner(ffmpegArgs *[]string) *mocks.CommandRunnerMock {
+	return &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			switch name {
+			case "yt-dlp":
+				for i, a := range args {
+					if a == "-o" {
+						return nil, nil, os.WriteFile(args[i+1], []byte("ORIG"), 0o600)
+					}
+				}
+			case "ffmpeg":
+				*ffmpegArgs = args
+				return nil, nil, os.WriteFile(args[len(args)-1], []byte("MIX"), 0o600)
+			}
+			return nil, nil, nil
+		},
+	}
+}
+
+func TestTelegramBot_KeepVoiceoverSources(t *testing.T) {
+	var ffmpegArgs []string
+	bot := newTestBot(t, newTgStub(t))
+	bot.Finalizer = &AudioFinalizer{Runner: sourcesRunner(&ffmpegArgs)}
+	bot.VoiceoverSvc.Runner = sourcesRunner(&ffmpegArgs)
+	file := filepath.Join(t.TempDir(), "vo_abc_1.mp3")
+	require.NoError(t, os.WriteFile(file, []byte("VOICE"), 0o600))
+
+	assert.Nil(t, bot.keepVoiceoverSources(context.Background(), "https://youtu.be/abc", file), "off by default")
+
+	bot.VoSources = config.VoiceoverSources{Keep: true, OriginalVolume: 0.2}
+	sources := bot.keepVoiceoverSources(context.Background(), "https://youtu.be/abc", file)
+	voice, original := voSourceFiles(file)
+	require.Equal(t, []string{voice, original}, sources)
+
+	data, err := os.ReadFile(voice)
+	require.NoError(t, err)
+	assert.Equal(t, "VOICE", string(data), "voice track kept as downloaded")
+	data, err = os.ReadFile(original)
+	require.NoError(t, err)
+	assert.Equal(t, "ORIG", string(data))
+	data, err = os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "MIX", string(data), "episode is the mix")
+	assert.Contains(t, strings.Join(ffmpegArgs, " "), "volume=0.2")
+}
+
+func TestTelegramBot_TrimVoiceoverSources(t *testing.T) {
+	bot := newTestBot(t, newTgStub(t))
+	bot.Store = newTestJobStore(t)
+	bot.VoSources = config.VoiceoverSources{Keep: true, MaxSizeMB: 1}
+	dir := t.TempDir()
+
+	var saved []ytfeed.Entry
+	for i, id := range []string{"old", "mid", "new"} {
+		file := filepath.Join(dir, id+".mp3")
+		voice, original := voSourceFiles(file)
+		for _, f := range []string{voice, original} {
+			require.NoError(t, os.WriteFile(f, make([]byte, 200*1024), 0o600))
+		}
+		e := ytfeed.Entry{ChannelID: bot.FeedName, VideoID: id, File: file, Sources: []string{voice, original}}
+		e.Published = time.Now().Add(time.Duration(i-3) * time.Hour)
+		_, err := bot.Store.Save(e)
+		require.NoError(t, err)
+		saved = append(saved, e)
+	}
+
+	bot.trimVoiceoverSources()
+	entries, err := bot.Store.Load(bot.FeedName, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 3, "episodes stay")
+	kept := map[string]int{}
+	for _, e := range entries {
+		kept[e.VideoID] = len(e.Sources)
+	}
+	assert.Equal(t, map[string]int{"new": 2, "mid": 2, "old": 0}, kept)
+	_, err = os.Stat(saved[0].Sources[0])
+	assert.True(t, os.IsNotExist(err), "oldest sources removed")
+	_, err = os.Stat(saved[2].Sources[0])
+	assert.NoError(t, err)
+}
+
+func TestTelegramBot_RemixEpisode(t *testing.T) {
+	var ffmpegArgs []string
+	bot := newTestBot(t, newTgStub(t))
+	bot.Store = newTestJobStore(t)
+	bot.Finalizer = &AudioFinalizer{Runner: sourcesRunner(&ffmpegArgs)}
+	dir := t.TempDir()
+
+	file := filepath.Join(dir, "vo_abc_1.mp3")
+	voice, original := voSourceFiles(file)
+	require.NoError(t, os.WriteFile(voice, []byte("VOICE"), 0o600))
+	require.NoError(t, os.WriteFile(original, []byte("ORIG"), 0o600))
+	entry := ytfeed.Entry{ChannelID: bot.FeedName, VideoID: "vo_abc", File: file, Sources: []string{voice, original}}
+	_, err := bot.Store.Save(entry)
+	require.NoError(t, err)
+
+	require.NoError(t, bot.remixEpisode(context.Background(), entry, 0.5))
+	assert.Contains(t, strings.Join(ffmpegArgs, " "), "volume=0.5")
+	entries, err := bot.Store.Load(bot.FeedName, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, int64(3), entries[0].FileSize, "size of the new mix")
+
+	require.NoError(t, bot.remixEpisode(context.Background(), entry, 0))
+	assert.NotContains(t, ffmpegArgs, "-filter_complex", "voice only")
+
+	require.NoError(t, bot.deleteEntry(entries[0]))
+	_, err = os.Stat(original)
+	assert.True(t, os.IsNotExist(err), "sources deleted with the episode")
+
+	err = bot.remixEpisode(context.Background(), entry, 0.5)
+	assert.ErrorContains(t, err, "is gone")
+}
+
+func TestTelegramBot_HandleRemixNoSources(t *testing.T) {
+	stub := newTgStub(t)
+	bot := newTestBot(t, stub)
+	bot.Store = newTestJobStore(t)
+	bot.MaxItems = 10
+	bot.Finalizer = &AudioFinalizer{Runner: &mocks.CommandRunnerMock{}}
+	_, err := bot.Store.Save(ytfeed.Entry{ChannelID: bot.FeedName, VideoID: "v1", File: "v1.mp3"})
+	require.NoError(t, err)
+
+	bot.handleRemix(testMessage(testBotUserID, "/remix 1 2"))
+	bot.handleRemix(testMessage(testBotUserID, "/remix 1"))
+	sent := stub.texts("sendMessage")
+	require.Len(t, sent, 2)
+	assert.Contains(t, sent[0], "Usage: /remix", "volume over 1")
+	assert.Contains(t, sent[1], "исходники не сохранены")
+}
@@ -0,0 +1,97 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/go-pkgz/lgr"
+	tb "gopkg.in/tucnak/telebot.v2"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// isWebVideoURL tells if s is a link /vo can try on a site other than
+// YouTube, yt-dlp decides if there is a video
+func isWebVideoURL(s string) bool {
+	u, err := url.Parse(strings.TrimSpace(s))
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// isWebVideoID tells if the id is of a video from a site other than YouTube, see sourceID
+func isWebVideoID(id string) bool {
+	return strings.HasPrefix(id, "web_")
+}
+
+// canTranscribe tells if videos without subtitles can get them by transcription
+func (t *TelegramBot) canTranscribe() bool {
+	return t.NotesSvc != nil && t.NotesSvc.Transcriber != nil && t.VoiceoverSvc != nil
+}
+
+// transcribeVideo makes subtitles of a video without them by transcribing its
+// audio. Returns the WebVTT file, removed with SubtitleSvc.Cleanup, and the
+// language spoken.
+func (t *TelegramBot) transcribeVideo(ctx context.Context, statusMsg *tb.Message, videoURL, videoID string,
+	info *ytfeed.VideoInfo) (file, lang string, err error) {
+	jobDir, err := ytfeed.MakeJobDir(t.FilesLocation)
+	if err != nil {
+		return "", "", err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(jobDir) // Cleanup removes it on success
+		}
+	}()
+
+	t.edit(statusMsg, fmt.Sprintf(t.tr("🎵 Субтитров нет, скачиваю звук для расшифровки: %s..."), info.Title))
+	audio := filepath.Join(jobDir, "original.mp3")
+	if err := t.VoiceoverSvc.DownloadOriginalAudio(ctx, videoURL, audio); err != nil {
+		return "", "", fmt.Errorf("не удалось скачать звук: %w", err)
+	}
+	tr, err := t.NotesSvc.Transcriber.Transcribe(ctx, audio, func(done, total int) {
+		t.edit(statusMsg, fmt.Sprintf(t.tr("🎧 Расшифровываю %d/%d: %s..."), done, total, info.Title))
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to transcribe: %w", err)
+	}
+	if joinTranscriptText(tr) == "" {
+		return "", "", fmt.Errorf("речь не распознана: %w", ErrNoSubtitles)
+	}
+
+	file = filepath.Join(jobDir, "transcript_"+videoID+".vtt")
+	if err := writeAtomic(file, []byte(segmentsVTT(tr.Segments)), 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to save transcript: %w", err)
+	}
+	lang = transcriptLang(tr.Language)
+	log.Printf("[INFO] transcribed %s into %d cues (lang: %s)", videoID, len(tr.Segments), lang)
+	return file, lang, nil
+}
+
+// segmentsVTT renders transcript segments as WebVTT subtitles
+func segmentsVTT(segs []TranscriptSegment) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+	for _, seg := range segs {
+		text := strings.TrimSpace(seg.Text)
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s --> %s\n%s\n", vttTime(seg.Start), vttTime(seg.End), text)
+	}
+	return b.String()
+}
+
+// transcriptLang is the language code of the one the transcriber detected,
+// Whisper names it in full ("russian")
+func transcriptLang(lang string) string {
+	switch lang = strings.ToLower(strings.TrimSpace(lang)); lang {
+	case "russian":
+		return "ru"
+	case "english", "":
+		return "en"
+	}
+	return lang
+}
@@ -0,0 +1,41 @@
+package proc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSourceID(t *testing.T) {
+	assert.Equal(t, "abc123", sourceID("https://m.youtube.com/watch?v=abc123&t=10"))
+	assert.Equal(t, "abc123", sourceID("https://youtu.be/abc123"))
+	id := sourceID("https://vimeo.com/76979871")
+	assert.Regexp(t, `^web_[0-9a-f]{12}$`, id)
+	assert.True(t, isWebVideoID(id))
+	assert.Equal(t, id, sourceID("https://vimeo.com/76979871"), "stable")
+	assert.NotEqual(t, id, sourceID("https://vimeo.com/76979872"))
+	assert.False(t, isWebVideoID("abc123"))
+}
+
+func TestIsWebVideoURL(t *testing.T) {
+	assert.True(t, isWebVideoURL("https://vimeo.com/76979871"))
+	assert.True(t, isWebVideoURL(" http://media.ccc.de/v/talk "))
+	assert.False(t, isWebVideoURL("vimeo.com/76979871"))
+	assert.False(t, isWebVideoURL("ftp://example.com/video.mp4"))
+	assert.False(t, isWebVideoURL("not a url"))
+}
+
+func TestSegmentsVTT(t *testing.T) {
+	vtt := segmentsVTT([]TranscriptSegment{{Start: 0, End: 2.5, Text: " Hello there. "}, {Start: 3, End: 4, Text: " "},
+		{Start: 3661.2, End: 3662, Text: "Bye."}})
+	assert.Equal(t, "WEBVTT\n\n00:00:00.000 --> 00:00:02.500\nHello there.\n\n01:01:01.200 --> 01:01:02.000\nBye.\n", vtt)
+	segs := ParseSubtitleSegments(vtt)
+	assert.Len(t, segs, 2, "parsed back as subtitles")
+}
+
+func TestTranscriptLang(t *testing.T) {
+	assert.Equal(t, "ru", transcriptLang("Russian"))
+	assert.Equal(t, "en", transcriptLang("english"))
+	assert.Equal(t, "en", transcriptLang(""))
+	assert.Equal(t, "de", transcriptLang("de"))
+}
@@ -3,16 +3,19 @@ package proc
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	log "github.com/go-pkgz/lgr"
 
+	"github.com/umputun/feed-master/app/tools"
 	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
 )
 
@@ -22,6 +25,41 @@ type AudioTrack struct {
 	Language string
 	Quality  string
 	Bitrate  int
+	Size     int64  // bytes, exact or approximate, 0 if yt-dlp doesn't know
+	Codec    string // normalized by trackCodec: aac, opus, mp3, vorbis
+}
+
+// audioFormat is a container of dubbed episodes with the codec it takes
+// without a transcode
+type audioFormat struct {
+	codec string
+	ext   string
+}
+
+// audioFormats are the formats dubbed tracks are saved in, by the yt-dlp
+// --audio-format name
+var audioFormats = map[string]audioFormat{
+	"mp3":  {codec: "mp3", ext: "mp3"},
+	"m4a":  {codec: "aac", ext: "m4a"},
+	"opus": {codec: "opus", ext: "opus"},
+}
+
+// CheckAudioFormat returns an error for a format dubbed tracks can't be saved in, "" is mp3
+func CheckAudioFormat(name string) error {
+	if _, ok := audioFormats[name]; !ok && name != "" {
+		return fmt.Errorf("unknown audio format %q, use mp3, m4a or opus", name)
+	}
+	return nil
+}
+
+// trackCodec normalizes a yt-dlp acodec, "mp4a.40.2" is "aac"
+func trackCodec(acodec string) string {
+	c := strings.ToLower(acodec)
+	if strings.HasPrefix(c, "mp4a") {
+		return "aac"
+	}
+	c, _, _ = strings.Cut(c, ".")
+	return c
 }
 
 // VoiceoverService handles YouTube video voice-over translation using vot-cli
@@ -29,6 +67,8 @@ type VoiceoverService struct {
 	OutputDir   string
 	TargetLang  string
 	CookiesFile string
+	AudioFormat string               // format of dubbed tracks (mp3, m4a, opus), "" = mp3
+	Runner      ytfeed.CommandRunner // yt-dlp and vot-cli calls, nil = ytfeed.ExecRunner
 }
 
 // NewVoiceoverService creates a new voiceover service
@@ -43,6 +83,14 @@ func NewVoiceoverService(outputDir, targetLang, cookiesFile string) *VoiceoverSe
 	}
 }
 
+// runner returns the injected CommandRunner or the exec-backed default
+func (v *VoiceoverService) runner() ytfeed.CommandRunner {
+	if v.Runner != nil {
+		return v.Runner
+	}
+	return ytfeed.ExecRunner{}
+}
+
 // ytdlpArgs returns common yt-dlp arguments including cookies if configured.
 // If useCookies is false, cookies are omitted even if CookiesFile is set.
 func (v *VoiceoverService) ytdlpArgs(useCookies bool, args ...string) []string {
@@ -63,34 +111,46 @@ type VoiceoverResult struct {
 	FileSize int64
 }
 
-// TranslateVideo downloads voice-over translated audio for a YouTube video
+// TranslateVideo downloads voice-over translated audio for a video, YouTube
+// or another site vot-cli takes
 func (v *VoiceoverService) TranslateVideo(ctx context.Context, videoURL string) (*VoiceoverResult, error) {
+	return v.TranslateVideoFrom(ctx, videoURL, "")
+}
+
+// TranslateVideoFrom is TranslateVideo of a video in the language, "" lets
+// vot-cli detect it
+func (v *VoiceoverService) TranslateVideoFrom(ctx context.Context, videoURL, lang string) (*VoiceoverResult, error) {
 	// Normalize URL: replace m.youtube.com with www.youtube.com
 	videoURL = normalizeYouTubeURL(videoURL)
-
-	// Create unique filename based on video ID and timestamp
-	videoID := extractVideoID(videoURL)
-	if videoID == "" {
-		return nil, fmt.Errorf("could not extract video ID from URL")
-	}
-	return v.TranslateURL(ctx, videoURL, videoID)
+	return v.translate(ctx, videoURL, sourceID(videoURL), lang)
 }
 
 // TranslateURL runs vot-cli on an arbitrary media URL (e.g. a direct podcast
 // enclosure). Yandex VOT supports some direct media links but not all —
 // callers should be ready to fall back when it fails.
 func (v *VoiceoverService) TranslateURL(ctx context.Context, mediaURL, outID string) (*VoiceoverResult, error) {
+	return v.translate(ctx, mediaURL, outID, "")
+}
+
+// translate runs vot-cli on the media URL, lang is the language spoken in it
+func (v *VoiceoverService) translate(ctx context.Context, mediaURL, outID, lang string) (*VoiceoverResult, error) {
 	videoURL := mediaURL
 	outputFile := filepath.Join(v.OutputDir, fmt.Sprintf("vo_%s_%d.mp3", outID, time.Now().Unix()))
+	// vot-cli writes in place, a crash mid-way must not leave a truncated file
+	// under the final name, so it writes the partial name and we rename at the end.
+	// ".tmp" keeps it out of the served *.mp3 files, the startup sweep removes it.
+	partFile := filepath.Join(v.OutputDir, "."+filepath.Base(outputFile)+".vot.tmp")
 
 	// Build vot-cli command
-	// vot-cli --output /path/to --output-file name.mp3 --reslang ru "URL"
+	// vot-cli --output /path/to --output-file name.mp3 [--lang en] --reslang ru "URL"
 	args := []string{
 		"--output", v.OutputDir,
-		"--output-file", filepath.Base(outputFile),
-		"--reslang", v.TargetLang,
-		videoURL,
+		"--output-file", filepath.Base(partFile),
 	}
+	if lang != "" {
+		args = append(args, "--lang", lang)
+	}
+	args = append(args, "--reslang", v.TargetLang, videoURL)
 
 	log.Printf("[INFO] running vot-cli with args: %v", args)
 
@@ -98,32 +158,32 @@ func (v *VoiceoverService) TranslateURL(ctx context.Context, mediaURL, outID str
 	cmdCtx, cancel := context.WithTimeout(ctx, 30*time.Minute)
 	defer cancel()
 
-	cmd := exec.CommandContext(cmdCtx, "vot-cli", args...)
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	stdout, stderr, err := v.runner().Run(cmdCtx, "vot-cli", args...)
 
-	err := cmd.Run()
-
-	log.Printf("[DEBUG] vot-cli stdout: %s", stdout.String())
-	log.Printf("[DEBUG] vot-cli stderr: %s", stderr.String())
+	log.Printf("[DEBUG] vot-cli stdout: %s", stdout)
+	log.Printf("[DEBUG] vot-cli stderr: %s", stderr)
 
 	if err != nil {
-		return nil, fmt.Errorf("vot-cli failed: %w\nstdout: %s\nstderr: %s", err, stdout.String(), stderr.String())
+		_ = os.Remove(partFile)
+		return nil, fmt.Errorf("vot-cli failed: %w\nstdout: %s\nstderr: %s", err, stdout, stderr)
 	}
 
 	// Check if file was created and has content
-	fileInfo, statErr := os.Stat(outputFile)
+	fileInfo, statErr := os.Stat(partFile)
 	if os.IsNotExist(statErr) {
-		return nil, fmt.Errorf("vot-cli did not create output file at %s", outputFile)
+		return nil, fmt.Errorf("vot-cli did not create output file at %s", partFile)
 	}
 	if statErr != nil {
 		return nil, fmt.Errorf("failed to stat output file: %w", statErr)
 	}
 	if fileInfo.Size() == 0 {
+		_ = os.Remove(partFile)
 		return nil, fmt.Errorf("vot-cli created empty file")
 	}
+	if err := os.Rename(partFile, outputFile); err != nil {
+		_ = os.Remove(partFile)
+		return nil, fmt.Errorf("failed to finalize output file: %w", err)
+	}
 
 	log.Printf("[INFO] vot-cli created file %s (size: %d bytes)", outputFile, fileInfo.Size())
 
@@ -175,6 +235,16 @@ func extractVideoID(url string) string {
 	return ""
 }
 
+// sourceID is the id of the video at the URL in file and entry names: the
+// YouTube video id, "web_" and a hash of the URL for the other sites
+func sourceID(videoURL string) string {
+	if id := extractVideoID(normalizeYouTubeURL(videoURL)); id != "" {
+		return id
+	}
+	sum := sha256.Sum256([]byte(videoURL))
+	return "web_" + hex.EncodeToString(sum[:6])
+}
+
 // extractTitleFromOutput tries to extract video title from vot-cli output
 func extractTitleFromOutput(output string) string {
 	// vot-cli may output the title, try to parse it
@@ -189,9 +259,17 @@ func extractTitleFromOutput(output string) string {
 	return ""
 }
 
+// audioFormat returns the format dubbed tracks are saved in
+func (v *VoiceoverService) audioFormat() (name string, format audioFormat) {
+	if f, ok := audioFormats[v.AudioFormat]; ok {
+		return v.AudioFormat, f
+	}
+	return "mp3", audioFormats["mp3"]
+}
+
 // IsVotCliAvailable checks if vot-cli is installed and accessible
 func IsVotCliAvailable() bool {
-	_, err := exec.LookPath("vot-cli")
+	_, err := tools.LookPath("vot-cli")
 	return err == nil
 }
 
@@ -204,6 +282,8 @@ type ytdlpFormat struct {
 	Abr        float64 `json:"abr"`
 	Vcodec     string  `json:"vcodec"`
 	Acodec     string  `json:"acodec"`
+	Filesize   int64   `json:"filesize"`
+	FilesizeAp int64   `json:"filesize_approx"`
 }
 
 // ytdlpInfo represents video info from yt-dlp --dump-json
@@ -228,22 +308,36 @@ func (v *VoiceoverService) getDubbedAudioTracks(ctx context.Context, videoURL st
 	cmdCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
 	defer cancel()
 
-	cmd := exec.CommandContext(cmdCtx, "yt-dlp", v.ytdlpArgs(useCookies, "--dump-json", "--no-download", videoURL)...)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	stdout, stderr, err := v.runner().Run(cmdCtx, "yt-dlp", v.ytdlpArgs(useCookies, "--dump-json", "--no-download", videoURL)...)
+	if err != nil {
+		return nil, ytfeed.WrapFailure(fmt.Errorf("yt-dlp dump-json failed: %w\nstderr: %s", err, stderr), string(stderr))
+	}
+
+	_, format := v.audioFormat()
+	tracks, err := parseAudioTracks(stdout, format.codec)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("yt-dlp dump-json failed: %w\nstderr: %s", err, stderr.String())
+	log.Printf("[INFO] found %d audio tracks for %s", len(tracks), videoURL)
+	for _, t := range tracks {
+		log.Printf("[DEBUG] audio track: lang=%s format=%s codec=%s bitrate=%d", t.Language, t.FormatID, t.Codec, t.Bitrate)
 	}
 
+	return tracks, nil
+}
+
+// parseAudioTracks picks language-tagged audio-only formats (one per
+// language, first wins unless a later one has the preferred codec) out of
+// "yt-dlp --dump-json" output
+func parseAudioTracks(data []byte, preferCodec string) ([]AudioTrack, error) {
 	var info ytdlpInfo
-	if err := json.Unmarshal(stdout.Bytes(), &info); err != nil {
+	if err := json.Unmarshal(data, &info); err != nil {
 		return nil, fmt.Errorf("failed to parse yt-dlp output: %w", err)
 	}
 
 	var tracks []AudioTrack
-	seen := make(map[string]bool)
+	seen := make(map[string]int) // language -> index in tracks
 
 	for _, f := range info.Formats {
 		// Audio-only formats have vcodec=none and acodec != none
@@ -256,26 +350,25 @@ func (v *VoiceoverService) getDubbedAudioTracks(ctx context.Context, videoURL st
 			continue
 		}
 
-		// Avoid duplicates (same language, keep best quality)
-		key := f.Language
-		if seen[key] {
-			continue
-		}
-		seen[key] = true
-
-		tracks = append(tracks, AudioTrack{
+		track := AudioTrack{
 			FormatID: f.FormatID,
 			Language: f.Language,
 			Quality:  f.Ext,
 			Bitrate:  int(f.Abr),
-		})
-	}
+			Size:     max(f.Filesize, f.FilesizeAp),
+			Codec:    trackCodec(f.Acodec),
+		}
 
-	log.Printf("[INFO] found %d audio tracks for %s", len(tracks), videoURL)
-	for _, t := range tracks {
-		log.Printf("[DEBUG] audio track: lang=%s format=%s bitrate=%d", t.Language, t.FormatID, t.Bitrate)
+		// Avoid duplicates (same language), a track in the preferred codec is saved without a transcode
+		if i, ok := seen[f.Language]; ok {
+			if preferCodec != "" && track.Codec == preferCodec && tracks[i].Codec != preferCodec {
+				tracks[i] = track
+			}
+			continue
+		}
+		seen[f.Language] = len(tracks)
+		tracks = append(tracks, track)
 	}
-
 	return tracks, nil
 }
 
@@ -291,61 +384,61 @@ func (v *VoiceoverService) FindDubbedTrack(tracks []AudioTrack) *AudioTrack {
 	return nil
 }
 
-// DownloadDubbedTrack downloads a specific audio track using yt-dlp.
+// DownloadDubbedTrack downloads a specific audio track using yt-dlp, a track
+// over maxSize bytes (<= 0 = no limit) fails with ErrFileTooLarge.
 // On cookie errors, retries without cookies as a fallback.
-func (v *VoiceoverService) DownloadDubbedTrack(ctx context.Context, videoURL string, track *AudioTrack) (*VoiceoverResult, error) {
-	result, err := v.downloadDubbedTrack(ctx, videoURL, track, true)
+func (v *VoiceoverService) DownloadDubbedTrack(ctx context.Context, videoURL string, track *AudioTrack,
+	maxSize int64) (*VoiceoverResult, error) {
+	result, err := v.downloadDubbedTrack(ctx, videoURL, track, maxSize, true)
 	if err != nil && v.CookiesFile != "" && ytfeed.IsCookieError(err.Error()) {
 		log.Printf("[WARN] cookies expired, retrying DownloadDubbedTrack without cookies")
-		return v.downloadDubbedTrack(ctx, videoURL, track, false)
+		return v.downloadDubbedTrack(ctx, videoURL, track, maxSize, false)
 	}
 	return result, err
 }
 
-func (v *VoiceoverService) downloadDubbedTrack(ctx context.Context, videoURL string, track *AudioTrack, useCookies bool) (*VoiceoverResult, error) {
+func (v *VoiceoverService) downloadDubbedTrack(ctx context.Context, videoURL string, track *AudioTrack, maxSize int64,
+	useCookies bool) (*VoiceoverResult, error) {
 	videoURL = normalizeYouTubeURL(videoURL)
+	videoID := sourceID(videoURL)
 
-	videoID := extractVideoID(videoURL)
-	if videoID == "" {
-		return nil, fmt.Errorf("could not extract video ID")
+	if maxSize > 0 && track.Size > maxSize {
+		return nil, fmt.Errorf("dubbed track %s is %d bytes, limit %d: %w", track.FormatID, track.Size, maxSize, ErrFileTooLarge)
 	}
 
-	outputFile := filepath.Join(v.OutputDir, fmt.Sprintf("vo_%s_%d.mp3", videoID, time.Now().Unix()))
-
-	// Download specific audio track and convert to mp3
-	args := v.ytdlpArgs(useCookies,
-		"-f", track.FormatID,
-		"--extract-audio",
-		"--audio-format", "mp3",
-		"--audio-quality", "128K",
-		"-o", outputFile,
-		videoURL,
-	)
+	formatName, format := v.audioFormat()
+	outputFile := filepath.Join(v.OutputDir, fmt.Sprintf("vo_%s_%d.%s", videoID, time.Now().Unix(), format.ext))
+
+	// Download specific audio track in the feed's format. yt-dlp copies the
+	// stream of a track already in the format's codec, others are transcoded.
+	// --max-filesize guards formats yt-dlp knows the size of upfront, the
+	// post-check covers the rest.
+	args := []string{"-f", track.FormatID, "--extract-audio", "--audio-format", formatName}
+	transcode := track.Codec != format.codec
+	if transcode {
+		args = append(args, "--audio-quality", "128K")
+	}
+	if maxSize > 0 {
+		args = append(args, "--max-filesize", strconv.FormatInt(maxSize, 10))
+	}
+	args = v.ytdlpArgs(useCookies, append(args, "-o", outputFile, videoURL)...)
 
-	log.Printf("[INFO] downloading dubbed track (lang=%s, format=%s) for %s", track.Language, track.FormatID, videoURL)
+	log.Printf("[INFO] downloading dubbed track (lang=%s, format=%s, codec=%s, transcode to %s: %v) for %s",
+		track.Language, track.FormatID, track.Codec, formatName, transcode, videoURL)
 
 	cmdCtx, cancel := context.WithTimeout(ctx, 30*time.Minute)
 	defer cancel()
 
-	cmd := exec.CommandContext(cmdCtx, "yt-dlp", args...)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("yt-dlp download failed: %w\nstderr: %s", err, stderr.String())
+	stdout, stderr, err := v.runner().Run(cmdCtx, "yt-dlp", args...)
+	if err != nil {
+		return nil, ytfeed.WrapFailure(fmt.Errorf("yt-dlp download failed: %w\nstderr: %s", err, stderr), string(stderr))
 	}
-
-	// yt-dlp might add extension, find the actual file
-	actualFile := outputFile
-	if _, err := os.Stat(outputFile); os.IsNotExist(err) {
-		// Try with .mp3 extension if not already
-		if !strings.HasSuffix(outputFile, ".mp3") {
-			actualFile = outputFile + ".mp3"
-		}
+	// yt-dlp skips oversized files with a message and exit code 0
+	if bytes.Contains(stdout, []byte("larger than max-filesize")) || bytes.Contains(stderr, []byte("larger than max-filesize")) {
+		return nil, fmt.Errorf("dubbed track %s is over %d bytes: %w", track.FormatID, maxSize, ErrFileTooLarge)
 	}
 
-	fileInfo, err := os.Stat(actualFile)
+	fileInfo, err := os.Stat(outputFile)
 	if err != nil {
 		return nil, fmt.Errorf("downloaded file not found: %w", err)
 	}
@@ -353,11 +446,54 @@ func (v *VoiceoverService) downloadDubbedTrack(ctx context.Context, videoURL str
 	if fileInfo.Size() == 0 {
 		return nil, fmt.Errorf("downloaded file is empty")
 	}
+	if maxSize > 0 && fileInfo.Size() > maxSize {
+		_ = os.Remove(outputFile)
+		return nil, fmt.Errorf("downloaded dubbed track is %d bytes, limit %d: %w", fileInfo.Size(), maxSize, ErrFileTooLarge)
+	}
 
-	log.Printf("[INFO] downloaded dubbed track: %s (size: %d bytes)", actualFile, fileInfo.Size())
+	log.Printf("[INFO] downloaded dubbed track: %s (size: %d bytes)", outputFile, fileInfo.Size())
 
 	return &VoiceoverResult{
-		FilePath: actualFile,
+		FilePath: outputFile,
 		FileSize: fileInfo.Size(),
 	}, nil
 }
+
+// DownloadOriginalAudio downloads the original audio of the video as MP3 into
+// dst, the background of a remixed voiceover
+func (v *VoiceoverService) DownloadOriginalAudio(ctx context.Context, videoURL, dst string) error {
+	err := v.downloadOriginalAudio(ctx, videoURL, dst, true)
+	if err != nil && v.CookiesFile != "" && ytfeed.IsCookieError(err.Error()) {
+		log.Printf("[WARN] cookies expired, retrying DownloadOriginalAudio without cookies")
+		return v.downloadOriginalAudio(ctx, videoURL, dst, false)
+	}
+	return err
+}
+
+func (v *VoiceoverService) downloadOriginalAudio(ctx context.Context, videoURL, dst string, useCookies bool) error {
+	args := v.ytdlpArgs(useCookies,
+		"-f", "bestaudio",
+		"--extract-audio",
+		"--audio-format", "mp3",
+		"--audio-quality", "128K",
+		"-o", dst,
+		normalizeYouTubeURL(videoURL),
+	)
+
+	cmdCtx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+
+	_, stderr, err := v.runner().Run(cmdCtx, "yt-dlp", args...)
+	if err != nil {
+		return ytfeed.WrapFailure(fmt.Errorf("yt-dlp download failed: %w\nstderr: %s", err, stderr), string(stderr))
+	}
+	fileInfo, err := os.Stat(dst)
+	if err != nil {
+		return fmt.Errorf("downloaded file not found: %w", err)
+	}
+	if fileInfo.Size() == 0 {
+		return fmt.Errorf("downloaded file is empty")
+	}
+	log.Printf("[INFO] downloaded original audio: %s (size: %d bytes)", dst, fileInfo.Size())
+	return nil
+}
@@ -0,0 +1,250 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	"github.com/umputun/feed-master/app/youtube/feed/mocks"
+)
+
+const dumpJSONWithDubs = `{"formats":[
+	{"format_id":"sb0","vcodec":"none","acodec":"none"},
+	{"format_id":"251-0","language":"en","ext":"webm","abr":128.5,"vcodec":"none","acodec":"opus"},
+	{"format_id":"251-1","language":"ru","ext":"webm","abr":130,"vcodec":"none","acodec":"opus","filesize_approx":1048576},
+	{"format_id":"140-1","language":"ru","ext":"m4a","abr":129,"vcodec":"none","acodec":"mp4a.40.2"},
+	{"format_id":"140","ext":"m4a","abr":129,"vcodec":"none","acodec":"mp4a.40.2"},
+	{"format_id":"137","language":"en","vcodec":"avc1","acodec":"none"}
+]}`
+
+func TestParseAudioTracks(t *testing.T) {
+	tracks, err := parseAudioTracks([]byte(dumpJSONWithDubs), "mp3")
+	require.NoError(t, err)
+	assert.Equal(t, []AudioTrack{
+		{FormatID: "251-0", Language: "en", Quality: "webm", Bitrate: 128, Codec: "opus"},
+		{FormatID: "251-1", Language: "ru", Quality: "webm", Bitrate: 130, Size: 1048576, Codec: "opus"},
+	}, tracks)
+
+	tracks, err = parseAudioTracks([]byte(dumpJSONWithDubs), "aac")
+	require.NoError(t, err)
+	assert.Equal(t, []AudioTrack{
+		{FormatID: "251-0", Language: "en", Quality: "webm", Bitrate: 128, Codec: "opus"},
+		{FormatID: "140-1", Language: "ru", Quality: "m4a", Bitrate: 129, Codec: "aac"},
+	}, tracks, "a track of the preferred codec wins")
+
+	tracks, err = parseAudioTracks([]byte(`{"formats":[]}`), "")
+	require.NoError(t, err)
+	assert.Empty(t, tracks)
+
+	_, err = parseAudioTracks([]byte(`not json`), "")
+	require.Error(t, err)
+}
+
+func TestVoiceoverService_GetDubbedAudioTracks(t *testing.T) {
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			return []byte(dumpJSONWithDubs), nil, nil
+		},
+	}
+	svc := NewVoiceoverService(t.TempDir(), "ru", "")
+	svc.Runner = runner
+
+	tracks, err := svc.GetDubbedAudioTracks(context.Background(), "https://m.youtube.com/watch?v=abc123")
+	require.NoError(t, err)
+	require.Len(t, tracks, 2)
+	dub := svc.FindDubbedTrack(tracks)
+	require.NotNil(t, dub)
+	assert.Equal(t, "251-1", dub.FormatID)
+
+	require.Len(t, runner.RunCalls(), 1)
+	assert.Equal(t, "yt-dlp", runner.RunCalls()[0].Name)
+	assert.Contains(t, runner.RunCalls()[0].Args, "https://www.youtube.com/watch?v=abc123")
+}
+
+func TestVoiceoverService_GetDubbedAudioTracksCookieRetry(t *testing.T) {
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			for _, a := range args {
+				if a == "--cookies" {
+					return nil, []byte("ERROR: cookies are no longer valid"), errors.New("exit status 1")
+				}
+			}
+			return []byte(dumpJSONWithDubs), nil, nil
+		},
+	}
+	svc := NewVoiceoverService(t.TempDir(), "ru", "/tmp/cookies.txt")
+	svc.Runner = runner
+
+	tracks, err := svc.GetDubbedAudioTracks(context.Background(), "https://www.youtube.com/watch?v=abc123")
+	require.NoError(t, err)
+	assert.Len(t, tracks, 2)
+	require.Len(t, runner.RunCalls(), 2)
+	assert.Contains(t, runner.RunCalls()[0].Args, "--cookies")
+	assert.NotContains(t, runner.RunCalls()[1].Args, "--cookies")
+}
+
+func TestVoiceoverService_GetDubbedAudioTracksFailure(t *testing.T) {
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			return nil, []byte("ERROR: [youtube] abc123: Video unavailable"), errors.New("exit status 1")
+		},
+	}
+	svc := NewVoiceoverService(t.TempDir(), "ru", "")
+	svc.Runner = runner
+
+	_, err := svc.GetDubbedAudioTracks(context.Background(), "https://www.youtube.com/watch?v=abc123")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ytfeed.ErrRemoved)
+	assert.Len(t, runner.RunCalls(), 1)
+}
+
+func TestVoiceoverService_TranslateURLRenamesPartial(t *testing.T) {
+	dir := t.TempDir()
+	var partName string
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			for i, a := range args {
+				if a == "--output-file" {
+					partName = args[i+1]
+				}
+			}
+			return nil, nil, os.WriteFile(filepath.Join(dir, partName), []byte("audio"), 0o600)
+		},
+	}
+	svc := NewVoiceoverService(dir, "ru", "")
+	svc.Runner = runner
+
+	res, err := svc.TranslateURL(context.Background(), "https://www.youtube.com/watch?v=abc123", "abc123")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(partName, ".vo_abc123_"))
+	assert.True(t, strings.HasSuffix(partName, ".mp3.vot.tmp"), "not an *.mp3 while written")
+	assert.NotEqual(t, partName, filepath.Base(res.FilePath))
+	assert.FileExists(t, res.FilePath)
+	assert.NoFileExists(t, filepath.Join(dir, partName))
+	assert.Equal(t, int64(5), res.FileSize)
+}
+
+func TestVoiceoverService_TranslateVideoFrom(t *testing.T) {
+	dir := t.TempDir()
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			part := args[slices.Index(args, "--output-file")+1]
+			return nil, nil, os.WriteFile(filepath.Join(dir, part), []byte("audio"), 0o600)
+		},
+	}
+	svc := NewVoiceoverService(dir, "ru", "")
+	svc.Runner = runner
+
+	_, err := svc.TranslateVideoFrom(context.Background(), "https://m.youtube.com/watch?v=abc123", "en")
+	require.NoError(t, err)
+	_, err = svc.TranslateVideo(context.Background(), "https://www.youtube.com/watch?v=abc123")
+	require.NoError(t, err)
+	calls := runner.RunCalls()
+	require.Len(t, calls, 2)
+	assert.Equal(t, []string{"--lang", "en", "--reslang", "ru", "https://www.youtube.com/watch?v=abc123"}, calls[0].Args[4:])
+	assert.NotContains(t, calls[1].Args, "--lang", "detected by vot-cli")
+}
+
+func TestVoiceoverService_DownloadDubbedTrackSizeLimit(t *testing.T) {
+	const limit = 1000
+	tbl := []struct {
+		name     string
+		track    AudioTrack
+		stdout   string
+		size     int
+		wantErr  error
+		wantRuns int
+	}{
+		{"fits", AudioTrack{FormatID: "251-1", Size: 900}, "", 500, nil, 1},
+		{"known size over limit", AudioTrack{FormatID: "251-1", Size: 5000}, "", 0, ErrFileTooLarge, 0},
+		{"skipped by yt-dlp", AudioTrack{FormatID: "251-1"},
+			"[download] File is larger than max-filesize (5000 bytes > 1000 bytes). Aborting.", 0, ErrFileTooLarge, 1},
+		{"converted file over limit", AudioTrack{FormatID: "251-1", Size: 900}, "", 1500, ErrFileTooLarge, 1},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			runner := &mocks.CommandRunnerMock{
+				RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+					if tt.size > 0 {
+						for i, a := range args {
+							if a == "-o" {
+								if err := os.WriteFile(args[i+1], make([]byte, tt.size), 0o600); err != nil {
+									return nil, nil, err
+								}
+							}
+						}
+					}
+					return []byte(tt.stdout), nil, nil
+				},
+			}
+			svc := NewVoiceoverService(dir, "ru", "")
+			svc.Runner = runner
+
+			res, err := svc.DownloadDubbedTrack(context.Background(), "https://www.youtube.com/watch?v=abc123", &tt.track, limit)
+			require.Len(t, runner.RunCalls(), tt.wantRuns)
+			if tt.wantRuns > 0 {
+				assert.Contains(t, strings.Join(runner.RunCalls()[0].Args, " "), "--max-filesize 1000")
+			}
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.True(t, IsPermanent(err))
+				files, _ := os.ReadDir(dir)
+				assert.Empty(t, files, "oversized file removed")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(tt.size), res.FileSize)
+		})
+	}
+}
+
+func TestVoiceoverService_DownloadDubbedTrackFormat(t *testing.T) {
+	tbl := []struct {
+		format    string
+		codec     string
+		ext       string
+		transcode bool
+	}{
+		{format: "", codec: "opus", ext: ".mp3", transcode: true},
+		{format: "opus", codec: "opus", ext: ".opus", transcode: false},
+		{format: "m4a", codec: "aac", ext: ".m4a", transcode: false},
+		{format: "m4a", codec: "opus", ext: ".m4a", transcode: true},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.format+"-"+tt.codec, func(t *testing.T) {
+			runner := &mocks.CommandRunnerMock{
+				RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+					for i, a := range args {
+						if a == "-o" {
+							return nil, nil, os.WriteFile(args[i+1], []byte("audio"), 0o600)
+						}
+					}
+					return nil, nil, errors.New("no output")
+				},
+			}
+			svc := NewVoiceoverService(t.TempDir(), "ru", "")
+			svc.Runner = runner
+			svc.AudioFormat = tt.format
+
+			res, err := svc.DownloadDubbedTrack(context.Background(), "https://www.youtube.com/watch?v=abc123",
+				&AudioTrack{FormatID: "251-1", Codec: tt.codec}, 0)
+			require.NoError(t, err)
+			assert.Equal(t, tt.ext, filepath.Ext(res.FilePath))
+			args := strings.Join(runner.RunCalls()[0].Args, " ")
+			assert.Contains(t, args, "--audio-format "+strings.TrimPrefix(tt.ext, "."))
+			assert.Equal(t, tt.transcode, strings.Contains(args, "--audio-quality"))
+		})
+	}
+	assert.NoError(t, CheckAudioFormat("opus"))
+	assert.NoError(t, CheckAudioFormat(""))
+	assert.Error(t, CheckAudioFormat("flac"))
+}
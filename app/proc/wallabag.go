@@ -0,0 +1,166 @@
+package proc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReadLaterItem is an unread item of a read-later queue
+type ReadLaterItem struct {
+	ID    string
+	URL   string
+	Title string
+}
+
+// ReadLaterQueue is a read-later service (Wallabag, Pocket, Instapaper) whose
+// unread items are voiced into the feed and archived afterwards
+type ReadLaterQueue interface {
+	Name() string
+	Unread(ctx context.Context, limit int) ([]ReadLaterItem, error)
+	Archive(ctx context.Context, id string) error
+}
+
+// WallabagClient talks to the Wallabag v2 API with the OAuth password grant
+type WallabagClient struct {
+	BaseURL      string // instance root, e.g. https://app.wallabag.it
+	ClientID     string
+	ClientSecret string
+	Username     string
+	Password     string
+	client       *http.Client
+
+	mu      sync.Mutex // guards the token
+	token   string
+	expires time.Time
+}
+
+// NewWallabagClient makes a client for the instance at baseURL
+func NewWallabagClient(baseURL, clientID, clientSecret, username, password string) *WallabagClient {
+	return &WallabagClient{
+		BaseURL:      strings.TrimRight(baseURL, "/"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Username:     username,
+		Password:     password,
+		client:       &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name of the service for messages
+func (w *WallabagClient) Name() string { return "Wallabag" }
+
+// Unread returns up to limit unarchived entries, oldest first
+func (w *WallabagClient) Unread(ctx context.Context, limit int) ([]ReadLaterItem, error) {
+	q := url.Values{"archive": {"0"}, "sort": {"created"}, "order": {"asc"}, "perPage": {strconv.Itoa(limit)}, "detail": {"metadata"}}
+	var resp struct {
+		Embedded struct {
+			Items []struct {
+				ID    int    `json:"id"`
+				URL   string `json:"url"`
+				Title string `json:"title"`
+			} `json:"items"`
+		} `json:"_embedded"`
+	}
+	if err := w.call(ctx, http.MethodGet, "/api/entries.json?"+q.Encode(), nil, &resp); err != nil {
+		return nil, err
+	}
+	res := make([]ReadLaterItem, 0, len(resp.Embedded.Items))
+	for _, it := range resp.Embedded.Items {
+		res = append(res, ReadLaterItem{ID: strconv.Itoa(it.ID), URL: it.URL, Title: it.Title})
+	}
+	return res, nil
+}
+
+// Archive marks the entry read
+func (w *WallabagClient) Archive(ctx context.Context, id string) error {
+	return w.call(ctx, http.MethodPatch, "/api/entries/"+url.PathEscape(id)+".json", url.Values{"archive": {"1"}}, nil)
+}
+
+// call makes an authorized API request, form is sent url-encoded, the JSON
+// answer is decoded into out when it's not nil
+func (w *WallabagClient) call(ctx context.Context, method, path string, form url.Values, out any) error {
+	token, err := w.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	var body io.Reader = http.NoBody
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, w.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("wallabag %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized {
+		w.mu.Lock()
+		w.token = "" // revoked or expired early, next call logs in again
+		w.mu.Unlock()
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("wallabag %s %s: status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("wallabag %s %s: bad response: %w", method, path, err)
+	}
+	return nil
+}
+
+// accessToken returns the cached token, logging in when it's missing or about to expire
+func (w *WallabagClient) accessToken(ctx context.Context) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.token != "" && time.Until(w.expires) > time.Minute {
+		return w.token, nil
+	}
+	form := url.Values{
+		"grant_type":    {"password"},
+		"client_id":     {w.ClientID},
+		"client_secret": {w.ClientSecret},
+		"username":      {w.Username},
+		"password":      {w.Password},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.BaseURL+"/oauth/v2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("wallabag login: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("wallabag login: status %d", resp.StatusCode)
+	}
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil || tok.AccessToken == "" {
+		return "", fmt.Errorf("wallabag login: bad token response: %v", err)
+	}
+	w.token = tok.AccessToken
+	w.expires = time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second)
+	return w.token, nil
+}
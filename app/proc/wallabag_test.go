@@ -0,0 +1,59 @@
+package proc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWallabagClient(t *testing.T) {
+	var logins int
+	var archived []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /oauth/v2/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "password", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "cid", r.PostForm.Get("client_id"))
+		assert.Equal(t, "me", r.PostForm.Get("username"))
+		logins++
+		_, _ = w.Write([]byte(`{"access_token":"tok","expires_in":3600}`))
+	})
+	mux.HandleFunc("GET /api/entries.json", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer tok", r.Header.Get("Authorization"))
+		assert.Equal(t, "0", r.URL.Query().Get("archive"))
+		assert.Equal(t, "2", r.URL.Query().Get("perPage"))
+		_, _ = w.Write([]byte(`{"_embedded":{"items":[{"id":7,"url":"https://example.com/a","title":"A"},
+			{"id":9,"url":"https://example.com/b","title":"B"}]}}`))
+	})
+	mux.HandleFunc("PATCH /api/entries/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "404.json" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "1", r.PostForm.Get("archive"))
+		archived = append(archived, r.PathValue("id"))
+		_, _ = w.Write([]byte(`{}`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	wb := NewWallabagClient(ts.URL+"/", "cid", "secret", "me", "pass")
+	ctx := context.Background()
+	items, err := wb.Unread(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []ReadLaterItem{{ID: "7", URL: "https://example.com/a", Title: "A"},
+		{ID: "9", URL: "https://example.com/b", Title: "B"}}, items)
+
+	require.NoError(t, wb.Archive(ctx, "7"))
+	assert.Equal(t, []string{"7.json"}, archived)
+	assert.Equal(t, 1, logins, "token reused")
+
+	err = wb.Archive(ctx, "404")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 404")
+}
@@ -0,0 +1,32 @@
+package proc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	log "github.com/go-pkgz/lgr"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// makePeaks writes the waveform of a new episode for the web player and
+// records it in the entry. Best-effort like the sped-up copy, the player goes
+// without it on failure. Runs before the offload, it needs the local file.
+func (t *TelegramBot) makePeaks(ctx context.Context, entry ytfeed.Entry) {
+	if !t.Waveform || t.Finalizer == nil || entry.File == "" {
+		return
+	}
+	dst := ytfeed.PeaksFile(entry.File)
+	if err := ytfeed.WritePeaks(ctx, t.Finalizer.runner(), entry.File, dst); err != nil {
+		log.Printf("[WARN] no waveform of %s: %v", filepath.Base(entry.File), err)
+		return
+	}
+	if err := t.updateStoredEntry(entry, func(e *ytfeed.Entry) { e.Peaks = dst }); err != nil {
+		// the episode is gone already (deleted while decoding), so is its waveform
+		log.Printf("[WARN] can't record waveform of %s: %v", entry.VideoID, err)
+		_ = os.Remove(dst)
+		return
+	}
+	log.Printf("[INFO] made waveform %s", filepath.Base(dst))
+}
@@ -0,0 +1,82 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	"github.com/umputun/feed-master/app/youtube/feed/mocks"
+)
+
+func TestTelegramBot_MakePeaks(t *testing.T) {
+	var ffmpegErr error
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			if ffmpegErr != nil {
+				return nil, []byte("boom"), ffmpegErr
+			}
+			return []byte{128, 160, 96, 128}, nil, nil
+		},
+	}
+	newBot := func() (*TelegramBot, ytfeed.Entry) {
+		bot := newTestBot(t, newTgStub(t))
+		bot.Store = newTestJobStore(t)
+		bot.Finalizer = &AudioFinalizer{Runner: runner}
+		bot.Waveform = true
+		file := filepath.Join(t.TempDir(), "ep.mp3")
+		require.NoError(t, os.WriteFile(file, []byte("audio"), 0o600))
+		entry := ytfeed.Entry{ChannelID: bot.FeedName, VideoID: "v1", File: file, FileSize: 5}
+		_, err := bot.Store.Save(entry)
+		require.NoError(t, err)
+		return bot, entry
+	}
+
+	t.Run("made and recorded", func(t *testing.T) {
+		bot, entry := newBot()
+		bot.makePeaks(context.Background(), entry)
+		entries, err := bot.Store.Load(bot.FeedName, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		peaks := ytfeed.PeaksFile(entry.File)
+		assert.Equal(t, peaks, entries[0].Peaks)
+		assert.Equal(t, int64(5), entries[0].FileSize, "stored fields kept")
+		_, err = os.Stat(peaks)
+		require.NoError(t, err)
+
+		require.NoError(t, bot.deleteEntry(entries[0]))
+		_, err = os.Stat(peaks)
+		assert.True(t, os.IsNotExist(err), "waveform deleted with the episode")
+	})
+
+	t.Run("ffmpeg fails", func(t *testing.T) {
+		bot, entry := newBot()
+		ffmpegErr = errors.New("exit status 1")
+		defer func() { ffmpegErr = nil }()
+		bot.makePeaks(context.Background(), entry)
+		entries, err := bot.Store.Load(bot.FeedName, 10)
+		require.NoError(t, err)
+		assert.Empty(t, entries[0].Peaks)
+	})
+
+	t.Run("entry deleted meanwhile", func(t *testing.T) {
+		bot, entry := newBot()
+		require.NoError(t, bot.Store.Remove(entry))
+		bot.makePeaks(context.Background(), entry)
+		_, err := os.Stat(ytfeed.PeaksFile(entry.File))
+		assert.True(t, os.IsNotExist(err), "orphan waveform removed")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		bot, entry := newBot()
+		bot.Waveform = false
+		bot.makePeaks(context.Background(), entry)
+		_, err := os.Stat(ytfeed.PeaksFile(entry.File))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
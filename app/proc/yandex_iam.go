@@ -0,0 +1,150 @@
+package proc
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// yandexIAMURL exchanges a service account JWT for an IAM token
+const yandexIAMURL = "https://iam.api.cloud.yandex.net/iam/v1/tokens"
+
+// iamRefresh is how long an IAM token is used, Yandex issues them for 12h
+// and recommends getting a new one every hour
+const iamRefresh = time.Hour
+
+// yandexSAKey is the authorized key of a service account, the JSON file
+// "yc iam key create" writes
+type yandexSAKey struct {
+	ID               string `json:"id"`
+	ServiceAccountID string `json:"service_account_id"`
+	PrivateKey       string `json:"private_key"`
+}
+
+// iamTokenSource gets IAM tokens of a service account by its authorized key
+// and keeps them until due for a refresh
+type iamTokenSource struct {
+	keyID, accountID string
+	key              *rsa.PrivateKey
+
+	mu      sync.Mutex
+	token   string
+	refresh time.Time // when to get a new token
+}
+
+// newIAMTokenSource reads the authorized key file of a service account
+func newIAMTokenSource(keyFile string) (*iamTokenSource, error) {
+	data, err := os.ReadFile(keyFile) //nolint:gosec // path from the config
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account key: %w", err)
+	}
+	var saKey yandexSAKey
+	if err := json.Unmarshal(data, &saKey); err != nil {
+		return nil, fmt.Errorf("failed to parse service account key: %w", err)
+	}
+	if saKey.ID == "" || saKey.ServiceAccountID == "" {
+		return nil, fmt.Errorf("service account key %s has no id or service_account_id", keyFile)
+	}
+	block, _ := pem.Decode([]byte(saKey.PrivateKey)) // skips the "PLEASE DO NOT REMOVE THIS LINE!" header
+	if block == nil {
+		return nil, fmt.Errorf("no PEM private key in %s", keyFile)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key of %s is not RSA", keyFile)
+	}
+	return &iamTokenSource{keyID: saKey.ID, accountID: saKey.ServiceAccountID, key: key}, nil
+}
+
+// Token returns the IAM token, getting a new one when the current is due
+func (s *iamTokenSource) Token(ctx context.Context, client *http.Client) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Now().Before(s.refresh) {
+		return s.token, nil
+	}
+	token, expires, err := s.exchange(ctx, client)
+	if err != nil {
+		return "", fmt.Errorf("failed to get IAM token: %w", err)
+	}
+	s.token, s.refresh = token, time.Now().Add(iamRefresh)
+	if !expires.IsZero() && expires.Add(-5*time.Minute).Before(s.refresh) {
+		s.refresh = expires.Add(-5 * time.Minute)
+	}
+	return s.token, nil
+}
+
+// exchange makes a signed JWT and exchanges it for an IAM token
+func (s *iamTokenSource) exchange(ctx context.Context, client *http.Client) (token string, expires time.Time, err error) {
+	jwt, err := s.jwt(time.Now())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	body, err := json.Marshal(map[string]string{"jwt": jwt})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", yandexIAMURL, bytes.NewBuffer(body))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", time.Time{}, fmt.Errorf("IAM API error (status %d): %s", resp.StatusCode, string(bodyBytes))
+	}
+	var result struct {
+		IAMToken  string    `json:"iamToken"`
+		ExpiresAt time.Time `json:"expiresAt"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to decode IAM response: %w", err)
+	}
+	if result.IAMToken == "" {
+		return "", time.Time{}, fmt.Errorf("no token in IAM response")
+	}
+	return result.IAMToken, result.ExpiresAt, nil
+}
+
+// jwt makes the PS256-signed JWT of the service account for the token exchange
+func (s *iamTokenSource) jwt(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"typ": "JWT", "alg": "PS256", "kid": s.keyID})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{"iss": s.accountID, "aud": yandexIAMURL,
+		"iat": now.Unix(), "exp": now.Add(time.Hour).Unix()})
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	signed := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPSS(rand.Reader, s.key, crypto.SHA256, hash[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+	return signed + "." + enc.EncodeToString(sig), nil
+}
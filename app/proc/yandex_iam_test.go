@@ -0,0 +1,94 @@
+package proc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslator_ServiceAccount(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	keyJSON, err := json.Marshal(map[string]string{"id": "kid1", "service_account_id": "sa1",
+		"private_key": "PLEASE DO NOT REMOVE THIS LINE! Yandex.Cloud SA Key ID <kid1>\n" +
+			string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))})
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "key.json")
+	require.NoError(t, os.WriteFile(keyFile, keyJSON, 0o600))
+
+	var mu sync.Mutex
+	var exchanges int
+	var auths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/iam/v1/tokens" {
+			var req struct{ JWT string }
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			parts := strings.Split(req.JWT, ".")
+			require.Len(t, parts, 3)
+			hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+			sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+			require.NoError(t, err)
+			require.NoError(t, rsa.VerifyPSS(&key.PublicKey, crypto.SHA256, hash[:], sig,
+				&rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}))
+			header, err := base64.RawURLEncoding.DecodeString(parts[0])
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"typ":"JWT","alg":"PS256","kid":"kid1"}`, string(header))
+			claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+			require.NoError(t, err)
+			assert.Contains(t, string(claims), `"iss":"sa1"`)
+			assert.Contains(t, string(claims), `"aud":"https://iam.api.cloud.yandex.net/iam/v1/tokens"`)
+			exchanges++
+			_ = json.NewEncoder(w).Encode(map[string]any{"iamToken": "t1",
+				"expiresAt": time.Now().Add(12 * time.Hour).Format(time.RFC3339Nano)})
+			return
+		}
+		auths = append(auths, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"translations":[{"text":"Привет."}]}`))
+	}))
+	defer ts.Close()
+	target, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	tr := NewTranslatorWithKey("", "folder", "ru")
+	tr.SourceLang = "en"
+	tr.client = &http.Client{Transport: redirectTransport{target: target}}
+	require.NoError(t, tr.SetServiceAccountKey(keyFile))
+
+	for range 2 {
+		res, err := tr.Translate(context.Background(), "Hello.")
+		require.NoError(t, err)
+		assert.Equal(t, "Привет.", res)
+	}
+	assert.Equal(t, 1, exchanges, "token reused until due")
+	assert.Equal(t, []string{"Bearer t1", "Bearer t1"}, auths)
+
+	tr.iam.refresh = time.Now().Add(-time.Second)
+	_, err = tr.Translate(context.Background(), "Hello.")
+	require.NoError(t, err)
+	assert.Equal(t, 2, exchanges, "refreshed when due")
+
+	require.NoError(t, os.WriteFile(keyFile, []byte(`{"id":"kid1"}`), 0o600))
+	assert.Error(t, tr.SetServiceAccountKey(keyFile))
+}
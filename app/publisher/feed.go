@@ -65,11 +65,12 @@ type Episode struct {
 
 // audioMimeByExt maps supported extensions to enclosure MIME types
 var audioMimeByExt = map[string]string{
-	".mp3": "audio/mpeg",
-	".m4a": "audio/mp4",
-	".m4b": "audio/mp4",
-	".aac": "audio/aac",
-	".ogg": "audio/ogg",
+	".mp3":  "audio/mpeg",
+	".m4a":  "audio/mp4",
+	".m4b":  "audio/mp4",
+	".aac":  "audio/aac",
+	".ogg":  "audio/ogg",
+	".opus": "audio/ogg",
 }
 
 // mimeForFile returns the enclosure type for a filename ("" = not audio)
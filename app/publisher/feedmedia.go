@@ -23,7 +23,7 @@ func (m *FeedMedia) key(basename string) string {
 
 // UploadMedia uploads one episode file, returns its public URL
 func (m *FeedMedia) UploadMedia(ctx context.Context, localPath, basename string) (string, error) {
-	return m.Store.Upload(ctx, localPath, m.key(basename), "audio/mpeg")
+	return m.Store.Upload(ctx, localPath, m.key(basename), mimeForFile(basename))
 }
 
 // DeleteMedia removes an episode object (best-effort companion to /del)
@@ -6,10 +6,11 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/umputun/feed-master/app/tools"
 )
 
 // watcher notes: a polling scanner instead of fsnotify — inotify is flaky on
@@ -170,14 +171,24 @@ func (p *Service) prepare(ctx context.Context, path, category string) (string, e
 		return "", fmt.Errorf("failed to create processed dir: %w", err)
 	}
 
+	release, err := tools.Acquire(ctx, tools.ClassTranscode)
+	if err != nil {
+		return "", err
+	}
+	defer release()
 	ffCtx, cancel := context.WithTimeout(ctx, 60*time.Minute)
 	defer cancel()
 	tmp := out + ".part.mp3" // ffmpeg needs a recognizable extension
-	cmd := exec.CommandContext(ffCtx, "ffmpeg", "-nostdin", "-y", "-i", path,
+	cmd := tools.Command(ffCtx, "ffmpeg", "-nostdin", "-y", "-i", path,
 		"-af", "loudnorm=I=-16:TP=-1.5:LRA=11",
 		"-ar", "44100", "-b:a", "128k", tmp)
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
+	cleanup, err := tools.Prepare(ffCtx, cmd)
+	defer cleanup()
+	if err != nil {
+		return "", err
+	}
 	log.Printf("[INFO] normalizing loudness: %s", filepath.Base(path))
 	if err := cmd.Run(); err != nil {
 		_ = os.Remove(tmp)
@@ -0,0 +1,91 @@
+// Package systemd implements the parts of the systemd service protocol feed-master
+// uses: listening sockets passed by socket activation and sd_notify readiness,
+// stopping and watchdog messages. Outside of systemd everything here is a no-op.
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// listenFDsStart is the first descriptor passed by systemd, SD_LISTEN_FDS_START
+const listenFDsStart = 3
+
+// Listeners returns the sockets passed by socket activation, nil when the
+// process wasn't activated by a socket. The environment is unset, so the
+// sockets aren't inherited by children.
+func Listeners() ([]net.Listener, error) {
+	defer func() {
+		_ = os.Unsetenv("LISTEN_PID")
+		_ = os.Unsetenv("LISTEN_FDS")
+		_ = os.Unsetenv("LISTEN_FDNAMES")
+	}()
+	return listeners(os.Getenv, os.Getpid(), listenFDsStart)
+}
+
+func listeners(getenv func(string) string, pid, start int) ([]net.Listener, error) {
+	if getenv("LISTEN_PID") != strconv.Itoa(pid) {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, nil
+	}
+	names := strings.Split(getenv("LISTEN_FDNAMES"), ":")
+	res := make([]net.Listener, 0, n)
+	for fd := start; fd < start+n; fd++ {
+		name := "LISTEN_FD_" + strconv.Itoa(fd)
+		if i := fd - start; i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(fd), name)
+		l, lerr := net.FileListener(f) // dups the descriptor
+		_ = f.Close()
+		if lerr != nil {
+			for _, prev := range res {
+				_ = prev.Close()
+			}
+			return nil, fmt.Errorf("socket %s: %w", name, lerr)
+		}
+		res = append(res, l)
+	}
+	return res, nil
+}
+
+// Notify sends a state, like "READY=1", to the service manager. sent is false
+// without NOTIFY_SOCKET, i.e. when not running as a Type=notify service.
+func Notify(state string) (sent bool, err error) {
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" {
+		return false, nil
+	}
+	if strings.HasPrefix(addr, "@") { // abstract namespace
+		addr = "\x00" + addr[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("dial notify socket: %w", err)
+	}
+	defer conn.Close()
+	if _, err = conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("write %q to notify socket: %w", state, err)
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns how often the service manager expects "WATCHDOG=1",
+// zero when the watchdog is off
+func WatchdogInterval() time.Duration {
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
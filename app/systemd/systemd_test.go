@@ -0,0 +1,80 @@
+package systemd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListeners(t *testing.T) {
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer tcp.Close()
+	f, err := tcp.(*net.TCPListener).File()
+	require.NoError(t, err)
+	defer f.Close()
+
+	env := map[string]string{"LISTEN_PID": "42", "LISTEN_FDS": "1", "LISTEN_FDNAMES": "http"}
+	getenv := func(k string) string { return env[k] }
+
+	res, err := listeners(getenv, 7, int(f.Fd()))
+	require.NoError(t, err)
+	assert.Empty(t, res, "sockets for another process")
+
+	res, err = listeners(getenv, 42, int(f.Fd()))
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	defer res[0].Close()
+	assert.Equal(t, tcp.Addr().String(), res[0].Addr().String())
+
+	env["LISTEN_FDS"] = ""
+	res, err = listeners(getenv, 42, int(f.Fd()))
+	require.NoError(t, err)
+	assert.Empty(t, res)
+}
+
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	sent, err := Notify("READY=1")
+	require.NoError(t, err)
+	assert.False(t, sent, "not under systemd")
+
+	sock := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: sock, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", sock)
+
+	sent, err = Notify("READY=1")
+	require.NoError(t, err)
+	assert.True(t, sent)
+	buf := make([]byte, 64)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "READY=1", string(buf[:n]))
+
+	t.Setenv("NOTIFY_SOCKET", filepath.Join(t.TempDir(), "missing.sock"))
+	_, err = Notify("READY=1")
+	assert.ErrorIs(t, err, syscall.ENOENT)
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "")
+	t.Setenv("WATCHDOG_PID", "")
+	assert.Zero(t, WatchdogInterval())
+
+	t.Setenv("WATCHDOG_USEC", "30000000")
+	assert.Equal(t, 30*time.Second, WatchdogInterval())
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	assert.Equal(t, 30*time.Second, WatchdogInterval())
+	t.Setenv("WATCHDOG_PID", "1")
+	assert.Zero(t, WatchdogInterval(), "watchdog of another process")
+}
@@ -0,0 +1,220 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+)
+
+// JobLogs keeps the stderr of the external commands of each background job
+// in a file of its own, for troubleshooting without access to the process log.
+// Only the newest files are kept.
+type JobLogs struct {
+	Dir       string `yaml:"dir"`         // "" = no job logs
+	Keep      int    `yaml:"keep"`        // newest files kept, 0 = 200
+	MaxSizeKB int    `yaml:"max_size_kb"` // a log stops growing here, 0 = 1024
+}
+
+// JobLogInfo describes a stored job log
+type JobLogInfo struct {
+	ID      string
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+const (
+	defaultJobLogKeep    = 200
+	defaultJobLogMaxSize = 1024 * 1024
+)
+
+var (
+	jobLogsMu sync.RWMutex
+	jobLogs   JobLogs
+
+	unsafeJobIDRe = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+)
+
+type jobLogKey struct{}
+
+// ConfigureJobLogs sets where the jobs started from now on keep their logs
+func ConfigureJobLogs(l JobLogs) {
+	jobLogsMu.Lock()
+	defer jobLogsMu.Unlock()
+	jobLogs = l
+}
+
+// jobLog is the log file of one job, created on the first write so jobs
+// running no commands leave nothing behind
+type jobLog struct {
+	id      string
+	conf    JobLogs
+	mu      sync.Mutex
+	f       *os.File
+	size    int64
+	stopped bool // over the size limit or failed to open
+}
+
+// StartJob returns a context of the job with the id, the commands run with it
+// write their command line, stderr and exit status to the log of the job. The
+// returned done closes the log, call it once the job is over.
+func StartJob(ctx context.Context, id string) (jobCtx context.Context, done func()) {
+	jobLogsMu.RLock()
+	conf := jobLogs
+	jobLogsMu.RUnlock()
+	jl := &jobLog{id: id, conf: conf, stopped: conf.Dir == ""}
+	return context.WithValue(ctx, jobLogKey{}, jl), jl.close
+}
+
+// JobID returns the id of the job of ctx, "" outside of a job
+func JobID(ctx context.Context) string {
+	if jl := jobLogOf(ctx); jl != nil {
+		return jl.id
+	}
+	return ""
+}
+
+// JobLog returns the stored log of the job with the id
+func JobLog(id string) (JobLogInfo, error) {
+	jobLogsMu.RLock()
+	dir := jobLogs.Dir
+	jobLogsMu.RUnlock()
+	if dir == "" {
+		return JobLogInfo{}, fmt.Errorf("job logs are off")
+	}
+	path := filepath.Join(dir, jobLogName(id))
+	st, err := os.Stat(path)
+	if err != nil {
+		return JobLogInfo{}, fmt.Errorf("no log of job %s: %w", id, err)
+	}
+	return JobLogInfo{ID: id, Path: path, Size: st.Size(), ModTime: st.ModTime()}, nil
+}
+
+// RecentJobLogs returns up to n stored job logs, newest first
+func RecentJobLogs(n int) ([]JobLogInfo, error) {
+	jobLogsMu.RLock()
+	dir := jobLogs.Dir
+	jobLogsMu.RUnlock()
+	if dir == "" {
+		return nil, nil
+	}
+	res, err := listJobLogs(dir)
+	if err != nil {
+		return nil, err
+	}
+	return res[:min(n, len(res))], nil
+}
+
+// Write appends to the log, past the size limit the rest is dropped
+func (l *jobLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stopped {
+		return len(p), nil
+	}
+	if l.f == nil && !l.open() {
+		return len(p), nil
+	}
+	maxSize := int64(l.conf.MaxSizeKB) * 1024
+	if maxSize <= 0 {
+		maxSize = defaultJobLogMaxSize
+	}
+	if l.size+int64(len(p)) > maxSize {
+		_, _ = l.f.Write(p[:max(0, maxSize-l.size)])
+		_, _ = l.f.WriteString("\n… log truncated\n")
+		l.stopped = true
+		return len(p), nil
+	}
+	n, err := l.f.Write(p)
+	l.size += int64(n)
+	if err != nil {
+		log.Printf("[WARN] failed to write log of job %s: %v", l.id, err)
+		l.stopped = true
+	}
+	return len(p), nil // a broken log never fails the command
+}
+
+// printf writes a line of the job itself, not of a command
+func (l *jobLog) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(l, "%s "+format+"\n", append([]any{time.Now().Format("15:04:05")}, args...)...)
+}
+
+// open creates the log file, dropping the oldest logs over the limit, with l.mu held
+func (l *jobLog) open() bool {
+	if err := os.MkdirAll(l.conf.Dir, 0o750); err != nil {
+		log.Printf("[WARN] can't make job log dir %s: %v", l.conf.Dir, err)
+		l.stopped = true
+		return false
+	}
+	keep := l.conf.Keep
+	if keep <= 0 {
+		keep = defaultJobLogKeep
+	}
+	if logs, err := listJobLogs(l.conf.Dir); err == nil && len(logs) >= keep {
+		for _, old := range logs[keep-1:] {
+			_ = os.Remove(old.Path)
+		}
+	}
+	f, err := os.OpenFile(filepath.Join(l.conf.Dir, jobLogName(l.id)), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) //nolint:gosec // name sanitized
+	if err != nil {
+		log.Printf("[WARN] can't open log of job %s: %v", l.id, err)
+		l.stopped = true
+		return false
+	}
+	l.f = f
+	return true
+}
+
+func (l *jobLog) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f != nil {
+		_ = l.f.Close()
+		l.f = nil
+	}
+	l.stopped = true
+}
+
+// jobLogOf returns the log of the job of ctx, nil outside of a job
+func jobLogOf(ctx context.Context) *jobLog {
+	if ctx == nil {
+		return nil
+	}
+	jl, _ := ctx.Value(jobLogKey{}).(*jobLog)
+	return jl
+}
+
+// jobLogName is the file name of the log of a job, ids are made file name safe
+func jobLogName(id string) string {
+	return unsafeJobIDRe.ReplaceAllString(id, "_") + ".log"
+}
+
+// listJobLogs returns the logs in dir, newest first
+func listJobLogs(dir string) ([]JobLogInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var res []JobLogInfo
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".log")
+		if !ok || e.IsDir() {
+			continue
+		}
+		st, serr := e.Info()
+		if serr != nil {
+			continue
+		}
+		res = append(res, JobLogInfo{ID: id, Path: filepath.Join(dir, e.Name()), Size: st.Size(), ModTime: st.ModTime()})
+	}
+	slices.SortFunc(res, func(a, b JobLogInfo) int { return b.ModTime.Compare(a.ModTime) })
+	return res, nil
+}
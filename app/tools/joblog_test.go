@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartJob(t *testing.T) {
+	t.Cleanup(func() { ConfigureJobLogs(JobLogs{}) })
+	dir := filepath.Join(t.TempDir(), "jobs")
+	ConfigureJobLogs(JobLogs{Dir: dir})
+
+	ctx, done := StartJob(context.Background(), "job/1")
+	assert.Equal(t, "job/1", JobID(ctx))
+	assert.Empty(t, JobID(context.Background()))
+	assert.NoDirExists(t, dir, "created on the first write only")
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", "echo out; echo oops >&2; exit 3")
+	cleanup, err := Prepare(ctx, cmd)
+	require.NoError(t, err)
+	out, err := cmd.Output()
+	require.Error(t, err)
+	assert.Equal(t, "out\n", string(out))
+	cleanup()
+	done()
+
+	info, err := JobLog("job/1")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "job_1.log"), info.Path)
+	data, err := os.ReadFile(info.Path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "$ sh -c echo out; echo oops >&2; exit 3\n")
+	assert.Contains(t, string(data), "oops\n")
+	assert.Contains(t, string(data), "exit status 3\n")
+	assert.NotContains(t, string(data), "out\n", "stdout is the result, not logged")
+
+	_, err = JobLog("nope")
+	require.Error(t, err)
+}
+
+func TestStartJob_off(t *testing.T) {
+	ConfigureJobLogs(JobLogs{})
+	ctx, done := StartJob(context.Background(), "job1")
+	defer done()
+	cmd := exec.CommandContext(ctx, "sh", "-c", "echo oops >&2")
+	cleanup, err := Prepare(ctx, cmd)
+	require.NoError(t, err)
+	defer cleanup()
+	require.NoError(t, cmd.Run())
+	_, err = JobLog("job1")
+	require.Error(t, err)
+	logs, err := RecentJobLogs(10)
+	require.NoError(t, err)
+	assert.Empty(t, logs)
+}
+
+func TestJobLog_rotation(t *testing.T) {
+	t.Cleanup(func() { ConfigureJobLogs(JobLogs{}) })
+	dir := t.TempDir()
+	ConfigureJobLogs(JobLogs{Dir: dir, Keep: 3})
+
+	now := time.Now()
+	for i := range 5 {
+		ctx, done := StartJob(context.Background(), fmt.Sprintf("job%d", i))
+		jobLogOf(ctx).printf("line %d", i)
+		done()
+		ts := now.Add(time.Duration(i-5) * time.Minute)
+		require.NoError(t, os.Chtimes(filepath.Join(dir, fmt.Sprintf("job%d.log", i)), ts, ts))
+	}
+	logs, err := RecentJobLogs(10)
+	require.NoError(t, err)
+	ids := make([]string, 0, len(logs))
+	for _, l := range logs {
+		ids = append(ids, l.ID)
+	}
+	assert.Equal(t, []string{"job4", "job3", "job2"}, ids, "newest first, the oldest removed")
+
+	logs, err = RecentJobLogs(1)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, "job4", logs[0].ID)
+}
+
+func TestJobLog_truncate(t *testing.T) {
+	t.Cleanup(func() { ConfigureJobLogs(JobLogs{}) })
+	dir := t.TempDir()
+	ConfigureJobLogs(JobLogs{Dir: dir, MaxSizeKB: 1})
+
+	ctx, done := StartJob(context.Background(), "big")
+	jl := jobLogOf(ctx)
+	for range 3 {
+		n, err := jl.Write([]byte(strings.Repeat("x", 600)))
+		require.NoError(t, err)
+		assert.Equal(t, 600, n)
+	}
+	done()
+	data, err := os.ReadFile(filepath.Join(dir, "big.log"))
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("x", 1024)+"\n… log truncated\n", string(data))
+}
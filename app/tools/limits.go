@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	log "github.com/go-pkgz/lgr"
+
+	"github.com/umputun/feed-master/app/tracing"
+)
+
+// Class is a resource the external work competes for
+type Class string
+
+// enum of resource classes
+const (
+	ClassDownload  = Class("download")  // network: yt-dlp, vot-cli
+	ClassTranscode = Class("transcode") // CPU: ffmpeg
+	ClassTTS       = Class("tts")       // TTS sessions
+)
+
+// Classes lists the resource classes in the order /status shows them
+var Classes = []Class{ClassDownload, ClassTranscode, ClassTTS}
+
+// Limits caps how many jobs of each class run at once, the rest wait in a
+// queue. Zero = unlimited.
+type Limits struct {
+	Downloads  int `yaml:"downloads"`
+	Transcodes int `yaml:"transcodes"`
+	TTS        int `yaml:"tts"`
+}
+
+// toolClasses are the classes of the known tools, ffprobe is too cheap to queue
+var toolClasses = map[string]Class{"yt-dlp": ClassDownload, "vot-cli": ClassDownload, "ffmpeg": ClassTranscode}
+
+// semaphore is a counting semaphore of one class
+type semaphore struct {
+	slots   chan struct{} // nil = unlimited
+	running atomic.Int32
+	waiting atomic.Int32
+}
+
+var (
+	limitsMu   sync.RWMutex
+	semaphores = newSemaphores(Limits{})
+)
+
+// ConfigureLimits sets the limits of the jobs started from now on, the
+// running ones finish under the old limits
+func ConfigureLimits(l Limits) {
+	sems := newSemaphores(l)
+	limitsMu.Lock()
+	semaphores = sems
+	limitsMu.Unlock()
+}
+
+func newSemaphores(l Limits) map[Class]*semaphore {
+	res := map[Class]*semaphore{}
+	for class, n := range map[Class]int{ClassDownload: l.Downloads, ClassTranscode: l.Transcodes, ClassTTS: l.TTS} {
+		sem := &semaphore{}
+		if n > 0 {
+			sem.slots = make(chan struct{}, n)
+		}
+		res[class] = sem
+	}
+	return res
+}
+
+// ClassOf returns the class of a tool, "" for tools run without a limit
+func ClassOf(name string) Class {
+	return toolClasses[name]
+}
+
+// Acquire waits for a free slot of the class, in the order of arrival as
+// far as the runtime goes. The returned release gives the slot back, call it
+// once the job is done. An empty class or one without a limit never waits.
+func Acquire(ctx context.Context, class Class) (release func(), err error) {
+	limitsMu.RLock()
+	sem := semaphores[class]
+	limitsMu.RUnlock()
+	if sem == nil {
+		return func() {}, nil
+	}
+	if sem.slots != nil {
+		select {
+		case sem.slots <- struct{}{}:
+		default:
+			sem.waiting.Add(1)
+			log.Printf("[DEBUG] waiting for a %s slot, %d running", class, sem.running.Load())
+			_, span := tracing.Child(ctx, "queue "+string(class))
+			select {
+			case sem.slots <- struct{}{}:
+				sem.waiting.Add(-1)
+				span.End()
+			case <-ctx.Done():
+				sem.waiting.Add(-1)
+				span.Finish(ctx.Err())
+				return func() {}, ctx.Err()
+			}
+		}
+	}
+	sem.running.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			sem.running.Add(-1)
+			if sem.slots != nil {
+				<-sem.slots
+			}
+		})
+	}, nil
+}
+
+// Usage is the load of a resource class
+type Usage struct {
+	Class   Class
+	Running int
+	Waiting int
+	Limit   int // 0 = unlimited
+}
+
+// Load returns the usage of every class
+func Load() []Usage {
+	limitsMu.RLock()
+	defer limitsMu.RUnlock()
+	res := make([]Usage, 0, len(Classes))
+	for _, class := range Classes {
+		u := Usage{Class: class}
+		if sem := semaphores[class]; sem != nil {
+			u.Running, u.Waiting, u.Limit = int(sem.running.Load()), int(sem.waiting.Load()), cap(sem.slots)
+		}
+		res = append(res, u)
+	}
+	return res
+}
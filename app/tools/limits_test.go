@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquire(t *testing.T) {
+	ConfigureLimits(Limits{Transcodes: 2})
+	defer ConfigureLimits(Limits{})
+
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := Acquire(context.Background(), ClassTranscode)
+			require.NoError(t, err)
+			defer release()
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			running.Add(-1)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), peak.Load())
+}
+
+func TestAcquireCanceled(t *testing.T) {
+	ConfigureLimits(Limits{Downloads: 1})
+	defer ConfigureLimits(Limits{})
+
+	release, err := Acquire(context.Background(), ClassDownload)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := Acquire(ctx, ClassDownload)
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return Load()[0].Waiting == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, Usage{Class: ClassDownload, Running: 1, Waiting: 1, Limit: 1}, Load()[0])
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	release()
+	release() // second call is a no-op
+	assert.Equal(t, Usage{Class: ClassDownload, Limit: 1}, Load()[0])
+
+	// unlimited classes and tools without a class never wait
+	for _, class := range []Class{ClassTTS, ClassOf("ffprobe")} {
+		rel, err := Acquire(ctx, class)
+		require.NoError(t, err)
+		rel()
+	}
+}
+
+func TestClassOf(t *testing.T) {
+	assert.Equal(t, ClassDownload, ClassOf("yt-dlp"))
+	assert.Equal(t, ClassDownload, ClassOf("vot-cli"))
+	assert.Equal(t, ClassTranscode, ClassOf("ffmpeg"))
+	assert.Equal(t, Class(""), ClassOf("ffprobe"))
+}
@@ -0,0 +1,236 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	log "github.com/go-pkgz/lgr"
+
+	"github.com/umputun/feed-master/app/tracing"
+)
+
+// Sandbox confines the external commands: no stdin, a scrubbed environment
+// (secrets of feed-master aren't passed on), an own temporary directory per
+// command and, with prlimit on PATH, resource limits. Zero limits = unlimited.
+// Nice and IOClass lower the priority of the commands with nice and ionice,
+// so the HTTP server and the bot stay responsive while ffmpeg runs. Tools run
+// in a docker image get the limits as docker run flags instead, prlimit and
+// the priorities would only apply to the docker client.
+type Sandbox struct {
+	WorkDir    string   `yaml:"work_dir"`     // parent of the per-command temp dirs, default os.TempDir()
+	Env        []string `yaml:"env"`          // more variables passed through, e.g. HF_TOKEN
+	MemoryMB   int      `yaml:"memory_mb"`    // address space, node based tools (vot-cli) reserve a lot of it
+	CPUSeconds int      `yaml:"cpu_seconds"`  // CPU time
+	FileSizeMB int      `yaml:"file_size_mb"` // largest file written
+	OpenFiles  int      `yaml:"open_files"`   // open descriptors
+	Nice       int      `yaml:"nice"`         // CPU niceness 1..19 added to the commands, 0 = as the service
+	IOClass    string   `yaml:"io_class"`     // ionice class: idle or best-effort, "" = as the service
+	IOLevel    int      `yaml:"io_level"`     // best-effort priority, 0 (high) .. 7 (low)
+}
+
+// ioClasses are the ionice classes by name, realtime needs root and isn't offered
+var ioClasses = map[string]string{"best-effort": "2", "idle": "3"}
+
+// passedEnv are the variables every command gets, the rest of the environment is dropped
+var passedEnv = []string{
+	"PATH", "HOME", "USER", "LANG", "LC_ALL", "LC_CTYPE", "TZ",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy",
+	"XDG_CACHE_HOME", "XDG_CONFIG_HOME", "SSL_CERT_FILE", "SSL_CERT_DIR",
+	"DOCKER_HOST", "DOCKER_CONFIG", "DOCKER_CONTEXT", "DOCKER_TLS_VERIFY", "DOCKER_CERT_PATH", "DOCKER_API_VERSION",
+}
+
+const maxSpanArgs = 300 // runes of a command line kept in its trace span
+
+var (
+	sandbox       Sandbox
+	missingLogged sync.Map // wrapper binaries reported missing, by name
+)
+
+// Validate checks the priority settings
+func (sb Sandbox) Validate() error {
+	if sb.Nice < 0 || sb.Nice > 19 {
+		return fmt.Errorf("sandbox nice %d out of 0..19", sb.Nice)
+	}
+	if _, ok := ioClasses[sb.IOClass]; sb.IOClass != "" && !ok {
+		return fmt.Errorf("unknown sandbox io_class %q, should be idle or best-effort", sb.IOClass)
+	}
+	if sb.IOLevel < 0 || sb.IOLevel > 7 {
+		return fmt.Errorf("sandbox io_level %d out of 0..7", sb.IOLevel)
+	}
+	return nil
+}
+
+// ConfigureSandbox sets the sandbox of the commands prepared from now on
+func ConfigureSandbox(sb Sandbox) {
+	mu.Lock()
+	defer mu.Unlock()
+	sandbox = sb
+}
+
+// Prepare applies the sandbox to a command not started yet. The returned
+// cleanup removes the temporary directory of the command, call it once the
+// command is done. A command with its own Dir keeps it. Within a job (see
+// StartJob) the command line, stderr and exit status go to the job log too.
+func Prepare(ctx context.Context, cmd *exec.Cmd) (cleanup func(), err error) {
+	mu.RLock()
+	sb := sandbox
+	mu.RUnlock()
+
+	cmd.Stdin = nil // reads from /dev/null
+	tmp, err := os.MkdirTemp(sb.WorkDir, "cmd-*")
+	if err != nil {
+		return func() {}, fmt.Errorf("sandbox dir: %w", err)
+	}
+	cleanup = func() { _ = os.RemoveAll(tmp) }
+	if _, span := tracing.Child(ctx, "exec "+filepath.Base(cmd.Args[0]), "cmd.args", argsExcerpt(cmd.Args)); span != nil {
+		removeTmp := cleanup
+		cleanup = func() {
+			removeTmp()
+			if cmd.ProcessState != nil && !cmd.ProcessState.Success() {
+				span.SetError(errors.New(cmd.ProcessState.String()))
+			}
+			span.End()
+		}
+	}
+	if jl := jobLogOf(ctx); jl != nil {
+		jl.printf("$ %s", strings.Join(cmd.Args, " "))
+		if cmd.Stderr != nil {
+			cmd.Stderr = io.MultiWriter(cmd.Stderr, jl)
+		} else {
+			cmd.Stderr = jl
+		}
+		removeTmp := cleanup
+		cleanup = func() {
+			removeTmp()
+			if cmd.ProcessState != nil {
+				jl.printf("[exit] %s", cmd.ProcessState)
+			}
+		}
+	}
+
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	cmd.Env = scrubEnv(env, sb.Env, tmp)
+
+	if cmd.Err != nil {
+		return cleanup, nil // fails on start anyway
+	}
+	if isDockerRun(cmd.Args) {
+		// limits must reach the container, the client isn't what uses the resources
+		cmd.Args = slices.Concat(cmd.Args[:2], sb.dockerRunArgs(), cmd.Args[2:])
+		return cleanup, nil
+	}
+	var prefix []string
+	for _, w := range sb.wrappers() {
+		bin, lerr := exec.LookPath(w[0])
+		if lerr != nil {
+			if _, logged := missingLogged.LoadOrStore(w[0], true); !logged {
+				log.Printf("[WARN] sandbox %s ignored: %v", w[0], lerr)
+			}
+			continue
+		}
+		prefix = append(append(prefix, bin), w[1:]...)
+	}
+	if len(prefix) > 0 {
+		cmd.Args = append(append(prefix, cmd.Path), cmd.Args[1:]...)
+		cmd.Path = prefix[0]
+	}
+	return cleanup, nil
+}
+
+// argsExcerpt is the command line of a span, long ones (prompts, URL lists) cut
+func argsExcerpt(args []string) string {
+	line := strings.Join(args, " ")
+	if runes := []rune(line); len(runes) > maxSpanArgs {
+		return string(runes[:maxSpanArgs]) + "…"
+	}
+	return line
+}
+
+// scrubEnv keeps the passed variables of env, with TMPDIR pointed to tmp
+func scrubEnv(env, extra []string, tmp string) []string {
+	res := make([]string, 0, len(passedEnv)+len(extra)+1)
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		if name == "TMPDIR" {
+			continue
+		}
+		for _, allowed := range [][]string{passedEnv, extra} {
+			if slices.Contains(allowed, name) {
+				res = append(res, kv)
+				break
+			}
+		}
+	}
+	return append(res, "TMPDIR="+tmp)
+}
+
+// wrappers are the commands the sandboxed one runs under, outermost first:
+// prlimit sets the limits, then ionice and nice the priorities. Each ends with
+// the separator its command takes before the wrapped one.
+func (sb Sandbox) wrappers() [][]string {
+	var res [][]string
+	if limits := sb.prlimitArgs(); len(limits) > 0 {
+		res = append(res, append(append([]string{"prlimit"}, limits...), "--"))
+	}
+	if class, ok := ioClasses[sb.IOClass]; ok {
+		w := []string{"ionice", "-t", "-c", class}
+		if sb.IOClass == "best-effort" {
+			w = append(w, "-n", strconv.Itoa(sb.IOLevel))
+		}
+		res = append(res, append(w, "--"))
+	}
+	if sb.Nice > 0 {
+		res = append(res, []string{"nice", "-n", strconv.Itoa(sb.Nice), "--"})
+	}
+	return res
+}
+
+// isDockerRun tells a tool run in a docker image (see Resolve) from a local binary
+func isDockerRun(args []string) bool {
+	return len(args) > 1 && filepath.Base(args[0]) == "docker" && args[1] == "run"
+}
+
+// dockerRunArgs are the docker run options of the configured limits: memory
+// as the cgroup limit of the container, the rest as its rlimits, soft=hard
+func (sb Sandbox) dockerRunArgs() []string {
+	var res []string
+	if sb.MemoryMB > 0 {
+		res = append(res, "--memory="+strconv.Itoa(sb.MemoryMB)+"m")
+	}
+	add := func(name string, v int64) {
+		if v > 0 {
+			res = append(res, "--ulimit="+name+"="+strconv.FormatInt(v, 10))
+		}
+	}
+	add("cpu", int64(sb.CPUSeconds))
+	add("fsize", int64(sb.FileSizeMB)*1024*1024)
+	add("nofile", int64(sb.OpenFiles))
+	return res
+}
+
+// prlimitArgs are the prlimit options of the configured limits, soft=hard
+func (sb Sandbox) prlimitArgs() []string {
+	var res []string
+	add := func(opt string, v int64) {
+		if v > 0 {
+			res = append(res, "--"+opt+"="+strconv.FormatInt(v, 10))
+		}
+	}
+	add("as", int64(sb.MemoryMB)*1024*1024)
+	add("cpu", int64(sb.CPUSeconds))
+	add("fsize", int64(sb.FileSizeMB)*1024*1024)
+	add("nofile", int64(sb.OpenFiles))
+	return res
+}
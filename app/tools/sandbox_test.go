@@ -0,0 +1,127 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepare(t *testing.T) {
+	t.Cleanup(func() { ConfigureSandbox(Sandbox{}) })
+	work := t.TempDir()
+	ConfigureSandbox(Sandbox{WorkDir: work, Env: []string{"HF_TOKEN"}})
+	t.Setenv("TELEGRAM_TOKEN", "secret")
+	t.Setenv("HF_TOKEN", "hf")
+	t.Setenv("LANG", "C.UTF-8")
+
+	cmd := exec.CommandContext(context.Background(), "sh", "-c", `env; echo "dir=$TMPDIR"; cat`)
+	cmd.Stdin = os.Stdin
+	cleanup, err := Prepare(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Nil(t, cmd.Stdin)
+	out, err := cmd.Output()
+	require.NoError(t, err)
+
+	env := string(out)
+	assert.NotContains(t, env, "TELEGRAM_TOKEN", "secrets dropped")
+	assert.Contains(t, env, "HF_TOKEN=hf\n", "passed by config")
+	assert.Contains(t, env, "LANG=C.UTF-8\n")
+	assert.Contains(t, env, "PATH=")
+	_, tmp, ok := strings.Cut(env, "dir=")
+	require.True(t, ok)
+	tmp = strings.TrimSpace(tmp)
+	assert.True(t, strings.HasPrefix(tmp, work), tmp)
+	assert.DirExists(t, tmp)
+	cleanup()
+	assert.NoDirExists(t, tmp)
+}
+
+func TestPrepareLimits(t *testing.T) {
+	if _, err := exec.LookPath("prlimit"); err != nil {
+		t.Skip("no prlimit")
+	}
+	t.Cleanup(func() { ConfigureSandbox(Sandbox{}) })
+	ConfigureSandbox(Sandbox{CPUSeconds: 120, OpenFiles: 64})
+
+	cmd := exec.CommandContext(context.Background(), "sh", "-c", "ulimit -t; ulimit -n")
+	cleanup, err := Prepare(context.Background(), cmd)
+	require.NoError(t, err)
+	defer cleanup()
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "120\n64\n", string(out))
+}
+
+func TestPrepareDocker(t *testing.T) {
+	bin := t.TempDir()
+	docker := "#!/bin/sh\necho \"$@\"\necho \"host=$DOCKER_HOST config=$DOCKER_CONFIG\"\n"
+	require.NoError(t, os.WriteFile(bin+"/docker", []byte(docker), 0o700)) //nolint:gosec // test script
+	t.Setenv("PATH", bin+":"+os.Getenv("PATH"))
+	t.Setenv("DOCKER_HOST", "tcp://docker:2375")
+	t.Setenv("DOCKER_CONFIG", "/srv/docker")
+	t.Cleanup(func() { ConfigureSandbox(Sandbox{}) })
+	ConfigureSandbox(Sandbox{MemoryMB: 512, OpenFiles: 64, Nice: 10})
+
+	cmd := exec.CommandContext(context.Background(), "docker", "run", "--rm", "-i", "img", "ffmpeg", "-version")
+	cleanup, err := Prepare(context.Background(), cmd)
+	require.NoError(t, err)
+	defer cleanup()
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "run --memory=512m --ulimit=nofile=64 --rm -i img ffmpeg -version\n"+
+		"host=tcp://docker:2375 config=/srv/docker\n", string(out), "limits as flags, no host wrappers")
+}
+
+func TestPreparePriority(t *testing.T) {
+	for _, bin := range []string{"nice", "ionice"} {
+		if _, err := exec.LookPath(bin); err != nil {
+			t.Skip("no " + bin)
+		}
+	}
+	t.Cleanup(func() { ConfigureSandbox(Sandbox{}) })
+	ConfigureSandbox(Sandbox{Nice: 7, IOClass: "best-effort", IOLevel: 6})
+
+	cmd := exec.CommandContext(context.Background(), "sh", "-c", "nice; ionice")
+	cleanup, err := Prepare(context.Background(), cmd)
+	require.NoError(t, err)
+	defer cleanup()
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "7\nbest-effort: prio 6\n", string(out))
+}
+
+func TestSandbox_wrappers(t *testing.T) {
+	assert.Empty(t, Sandbox{}.wrappers())
+	assert.Equal(t, [][]string{
+		{"prlimit", "--cpu=60", "--"},
+		{"ionice", "-t", "-c", "3", "--"},
+		{"nice", "-n", "10", "--"},
+	}, Sandbox{CPUSeconds: 60, IOClass: "idle", Nice: 10}.wrappers())
+	assert.Equal(t, [][]string{{"ionice", "-t", "-c", "2", "-n", "4", "--"}}, Sandbox{IOClass: "best-effort", IOLevel: 4}.wrappers())
+}
+
+func TestSandbox_Validate(t *testing.T) {
+	require.NoError(t, Sandbox{}.Validate())
+	require.NoError(t, Sandbox{Nice: 19, IOClass: "idle"}.Validate())
+	require.EqualError(t, Sandbox{Nice: 20}.Validate(), "sandbox nice 20 out of 0..19")
+	require.EqualError(t, Sandbox{IOClass: "realtime"}.Validate(),
+		`unknown sandbox io_class "realtime", should be idle or best-effort`)
+	require.EqualError(t, Sandbox{IOClass: "best-effort", IOLevel: 8}.Validate(), "sandbox io_level 8 out of 0..7")
+}
+
+func TestSandbox_prlimitArgs(t *testing.T) {
+	assert.Empty(t, Sandbox{}.prlimitArgs())
+	assert.Equal(t, []string{"--as=2147483648", "--cpu=600", "--fsize=1048576", "--nofile=256"},
+		Sandbox{MemoryMB: 2048, CPUSeconds: 600, FileSizeMB: 1, OpenFiles: 256}.prlimitArgs())
+}
+
+func TestSandbox_dockerRunArgs(t *testing.T) {
+	assert.Empty(t, Sandbox{Nice: 10}.dockerRunArgs())
+	assert.Equal(t, []string{"--memory=2048m", "--ulimit=cpu=600", "--ulimit=fsize=1048576", "--ulimit=nofile=256"},
+		Sandbox{MemoryMB: 2048, CPUSeconds: 600, FileSizeMB: 1, OpenFiles: 256}.dockerRunArgs())
+}
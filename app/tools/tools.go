@@ -0,0 +1,169 @@
+// Package tools starts the external binaries feed-master depends on: yt-dlp,
+// ffmpeg, ffprobe and vot-cli. By default they are looked up on PATH; the
+// config may point a tool to an explicit binary or run it in a docker image.
+// Commands are confined and deprioritized by the Sandbox before they start,
+// and wait for a slot of their resource class under Limits.
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tool says how to start an external binary, zero value = its name on PATH
+type Tool struct {
+	Path       string   `yaml:"path"`        // binary, inside the image when Image is set
+	Image      string   `yaml:"image"`       // run as "docker run --rm -i [docker_args] image path args"
+	DockerArgs []string `yaml:"docker_args"` // e.g. volumes, so the container sees the files passed as args
+}
+
+// Known are the tools checked on startup and reported in /status
+var Known = []string{"yt-dlp", "ffmpeg", "ffprobe", "vot-cli"}
+
+// versionArgs print the version of a known tool
+var versionArgs = map[string][]string{
+	"yt-dlp":  {"--version"},
+	"ffmpeg":  {"-version"},
+	"ffprobe": {"-version"},
+	"vot-cli": {"--version"},
+}
+
+var (
+	mu         sync.RWMutex
+	configured = map[string]Tool{}
+)
+
+// Configure sets the tools started otherwise than by name from PATH
+func Configure(tools map[string]Tool) {
+	mu.Lock()
+	defer mu.Unlock()
+	configured = map[string]Tool{}
+	for name, t := range tools {
+		configured[name] = t
+	}
+}
+
+// Configured returns how the tool is set up and whether it is in the config at all
+func Configured(name string) (Tool, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	t, ok := configured[name]
+	return t, ok
+}
+
+// Resolve returns the binary and arguments to start the tool with.
+// Names not in the config are returned as is.
+func Resolve(name string, args ...string) (bin string, resArgs []string) {
+	t, _ := Configured(name)
+	bin = name
+	if t.Path != "" {
+		bin = t.Path
+	}
+	if t.Image == "" {
+		return bin, args
+	}
+	resArgs = append([]string{"run", "--rm", "-i"}, t.DockerArgs...)
+	resArgs = append(resArgs, t.Image, bin)
+	return "docker", append(resArgs, args...)
+}
+
+// Command is exec.CommandContext for the resolved tool
+func Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	bin, resArgs := Resolve(name, args...)
+	return exec.CommandContext(ctx, bin, resArgs...) //nolint:gosec // binaries come from the config
+}
+
+// LookPath checks the resolved binary of the tool (docker for images) can be found
+func LookPath(name string) (string, error) {
+	bin, _ := Resolve(name)
+	return exec.LookPath(bin)
+}
+
+// ShellCommand returns the resolved tool as a quoted shell command prefix,
+// for the user-written command templates run with sh -c
+func ShellCommand(name string) string {
+	bin, args := Resolve(name)
+	words := make([]string, 0, len(args)+1)
+	for _, w := range append([]string{bin}, args...) {
+		words = append(words, shellQuote(w))
+	}
+	return strings.Join(words, " ")
+}
+
+// shellQuote single-quotes a word unless it is made of safe characters only
+func shellQuote(s string) string {
+	safe := s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@,+", r))
+	}) < 0
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Status is the result of a tool check
+type Status struct {
+	Name       string
+	Command    string // how the tool is started, e.g. "/usr/local/bin/yt-dlp"
+	Version    string // first line of its version output
+	Configured bool   // set in the config rather than taken from PATH
+	Err        error
+}
+
+// Check runs each known tool for its version
+func Check(ctx context.Context) []Status {
+	res := make([]Status, 0, len(Known))
+	for _, name := range Known {
+		res = append(res, check(ctx, name))
+	}
+	return res
+}
+
+func check(ctx context.Context, name string) Status {
+	_, isConfigured := Configured(name)
+	st := Status{Name: name, Command: ShellCommand(name), Configured: isConfigured}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second) // docker may pull the image
+	defer cancel()
+	cmd := Command(ctx, name, versionArgs[name]...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	cleanup, err := Prepare(ctx, cmd)
+	defer cleanup()
+	if err != nil {
+		st.Err = err
+		return st
+	}
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, firstLine(msg))
+		}
+		st.Err = err
+		return st
+	}
+	st.Version = versionOf(firstLine(stdout.String()))
+	return st
+}
+
+// versionOf trims the banner around the version, "ffmpeg version 7.1 Copyright..." -> "7.1"
+func versionOf(line string) string {
+	if _, rest, ok := strings.Cut(line, " version "); ok {
+		line = rest
+	}
+	if i := strings.Index(line, " Copyright"); i > 0 {
+		line = line[:i]
+	}
+	return strings.TrimSpace(line)
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return s
+}
@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	t.Cleanup(func() { Configure(nil) })
+	Configure(map[string]Tool{
+		"ffmpeg":  {Path: "/opt/ffmpeg/bin/ffmpeg"},
+		"vot-cli": {Image: "ghcr.io/example/vot:1", DockerArgs: []string{"-v", "/srv/var:/srv/var"}},
+	})
+
+	bin, args := Resolve("yt-dlp", "--version")
+	assert.Equal(t, "yt-dlp", bin, "not configured, from PATH")
+	assert.Equal(t, []string{"--version"}, args)
+
+	bin, args = Resolve("ffmpeg", "-i", "a.mp3")
+	assert.Equal(t, "/opt/ffmpeg/bin/ffmpeg", bin)
+	assert.Equal(t, []string{"-i", "a.mp3"}, args)
+
+	bin, args = Resolve("vot-cli", "--output", "/srv/var/x")
+	assert.Equal(t, "docker", bin)
+	assert.Equal(t, []string{"run", "--rm", "-i", "-v", "/srv/var:/srv/var", "ghcr.io/example/vot:1", "vot-cli",
+		"--output", "/srv/var/x"}, args)
+
+	assert.Equal(t, "docker run --rm -i -v /srv/var:/srv/var ghcr.io/example/vot:1 vot-cli", ShellCommand("vot-cli"))
+	Configure(map[string]Tool{"yt-dlp": {Path: "/home/me/My Tools/yt-dlp"}})
+	assert.Equal(t, `'/home/me/My Tools/yt-dlp'`, ShellCommand("yt-dlp"))
+	assert.Equal(t, "ffmpeg", ShellCommand("ffmpeg"), "reconfigured, ffmpeg back to PATH")
+}
+
+func TestCheck(t *testing.T) {
+	t.Cleanup(func() { Configure(nil) })
+	dir := t.TempDir()
+	script := filepath.Join(dir, "fake-ffmpeg")
+	require.NoError(t, os.WriteFile(script,
+		[]byte("#!/bin/sh\necho 'ffmpeg version 7.1.1 Copyright (c) 2000-2025 the FFmpeg developers'\necho 'built with gcc'\n"), 0o700)) //nolint:gosec // test script
+	Configure(map[string]Tool{"ffmpeg": {Path: script}, "vot-cli": {Path: filepath.Join(dir, "missing")}})
+
+	res := Check(context.Background())
+	require.Len(t, res, len(Known))
+	byName := map[string]Status{}
+	for _, st := range res {
+		byName[st.Name] = st
+	}
+	assert.NoError(t, byName["ffmpeg"].Err)
+	assert.Equal(t, "7.1.1", byName["ffmpeg"].Version)
+	assert.True(t, byName["ffmpeg"].Configured)
+	assert.Equal(t, script, byName["ffmpeg"].Command)
+	assert.Error(t, byName["vot-cli"].Err)
+	assert.True(t, byName["vot-cli"].Configured)
+	assert.False(t, byName["yt-dlp"].Configured)
+}
+
+func TestVersionOf(t *testing.T) {
+	assert.Equal(t, "7.1", versionOf("ffmpeg version 7.1 Copyright (c) 2000-2024"))
+	assert.Equal(t, "n6.1-3", versionOf("ffprobe version n6.1-3 Copyright"))
+	assert.Equal(t, "2025.09.26", versionOf("2025.09.26"))
+	assert.Equal(t, "1.4.2", versionOf("1.4.2"))
+}
@@ -0,0 +1,306 @@
+// Package tracing records the spans of a job (metadata, download, translation,
+// TTS, store) and exports them to an OpenTelemetry collector over OTLP/HTTP
+// with the JSON encoding, so a slow job shows where its time went
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+)
+
+// Config is the exporter of the traces, no endpoint = tracing off
+type Config struct {
+	Endpoint string            `yaml:"endpoint"` // OTLP/HTTP traces URL, e.g. http://localhost:4318/v1/traces
+	Service  string            `yaml:"service"`  // service.name of the spans, feed-master by default
+	Headers  map[string]string `yaml:"headers"`  // sent with every export, e.g. the auth of a hosted collector
+	Timeout  time.Duration     `yaml:"timeout"`  // of an export, 10s by default
+}
+
+const (
+	defaultService = "feed-master"
+	defaultTimeout = 10 * time.Second
+	scopeName      = "github.com/umputun/feed-master"
+)
+
+var (
+	mu       sync.RWMutex
+	exporter *otlpExporter // nil = tracing off
+)
+
+// Configure sets the exporter of the spans started from now on
+func Configure(c Config) {
+	mu.Lock()
+	defer mu.Unlock()
+	if c.Endpoint == "" {
+		exporter = nil
+		return
+	}
+	if c.Service == "" {
+		c.Service = defaultService
+	}
+	if c.Timeout == 0 {
+		c.Timeout = defaultTimeout
+	}
+	exporter = &otlpExporter{conf: c, client: &http.Client{Timeout: c.Timeout}}
+}
+
+// Span is a timed step of a trace. A nil span, returned with tracing off,
+// records nothing, so callers never check.
+type Span struct {
+	trace    *trace
+	id       string
+	parentID string
+	name     string
+	start    time.Time
+
+	mu     sync.Mutex
+	attrs  map[string]any
+	errMsg string
+	ended  bool
+}
+
+// trace keeps the spans of one trace until its root span ends
+type trace struct {
+	id       string
+	exporter *otlpExporter
+
+	mu      sync.Mutex
+	spans   []spanData
+	flushed bool
+}
+
+type spanKey struct{}
+
+// Start starts a span, a child of the span of ctx or the root of a new trace.
+// End the span once the step is done. Attrs are key, value pairs.
+func Start(ctx context.Context, name string, attrs ...any) (context.Context, *Span) {
+	parent, _ := ctx.Value(spanKey{}).(*Span)
+	var tr *trace
+	parentID := ""
+	if parent != nil {
+		tr, parentID = parent.trace, parent.id
+	} else {
+		mu.RLock()
+		exp := exporter
+		mu.RUnlock()
+		if exp == nil {
+			return ctx, nil
+		}
+		tr = &trace{id: randomHex(16), exporter: exp}
+	}
+	s := &Span{trace: tr, id: randomHex(8), parentID: parentID, name: name, start: time.Now(), attrs: map[string]any{}}
+	s.SetAttrs(attrs...)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// Child starts a span only inside a trace, for the steps too frequent to be
+// traces of their own, like commands and queue waits. Nil span outside.
+func Child(ctx context.Context, name string, attrs ...any) (context.Context, *Span) {
+	if _, ok := ctx.Value(spanKey{}).(*Span); !ok {
+		return ctx, nil
+	}
+	return Start(ctx, name, attrs...)
+}
+
+// SetAttrs adds key, value pairs to the span
+func (s *Span) SetAttrs(attrs ...any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return // exported already
+	}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		s.attrs[fmt.Sprint(attrs[i])] = attrs[i+1]
+	}
+}
+
+// SetError marks the span failed, nil err does nothing
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	if !s.ended {
+		s.errMsg = err.Error()
+	}
+	s.mu.Unlock()
+}
+
+// Finish marks the span failed on err and ends it, for a deferred call with a named error
+func (s *Span) Finish(err error) {
+	s.SetError(err)
+	s.End()
+}
+
+// End finishes the span. The end of the root span exports the trace, spans
+// ending later are exported on their own.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	data := spanData{span: s, end: time.Now(), attrs: s.attrs, errMsg: s.errMsg}
+	s.mu.Unlock()
+
+	tr := s.trace
+	tr.mu.Lock()
+	tr.spans = append(tr.spans, data)
+	if s.parentID != "" && !tr.flushed {
+		tr.mu.Unlock()
+		return
+	}
+	tr.flushed = true
+	batch := tr.spans
+	tr.spans = nil
+	tr.mu.Unlock()
+	go tr.exporter.export(tr.id, batch)
+}
+
+// spanData is a finished span
+type spanData struct {
+	span   *Span
+	end    time.Time
+	attrs  map[string]any
+	errMsg string
+}
+
+// otlpExporter posts spans to an OTLP/HTTP collector
+type otlpExporter struct {
+	conf   Config
+	client *http.Client
+}
+
+func (e *otlpExporter) export(traceID string, batch []spanData) {
+	body, err := json.Marshal(e.request(traceID, batch))
+	if err != nil {
+		log.Printf("[WARN] failed to encode trace %s: %v", traceID, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), e.conf.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.conf.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		log.Printf("[WARN] bad trace endpoint %s: %v", e.conf.Endpoint, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.conf.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		log.Printf("[WARN] failed to export trace %s: %v", traceID, err)
+		return
+	}
+	defer resp.Body.Close() // nolint
+	if resp.StatusCode >= 300 {
+		log.Printf("[WARN] failed to export trace %s: collector responded %s", traceID, resp.Status)
+		return
+	}
+	log.Printf("[DEBUG] exported trace %s, %d spans", traceID, len(batch))
+}
+
+// OTLP JSON encoding, see opentelemetry-proto trace/v1. Ids are hex, times
+// are nanoseconds as strings.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttr `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope struct {
+			Name string `json:"name"`
+		} `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpSpan struct {
+		TraceID      string     `json:"traceId"`
+		SpanID       string     `json:"spanId"`
+		ParentSpanID string     `json:"parentSpanId,omitempty"`
+		Name         string     `json:"name"`
+		Kind         int        `json:"kind"`
+		Start        string     `json:"startTimeUnixNano"`
+		End          string     `json:"endTimeUnixNano"`
+		Attributes   []otlpAttr `json:"attributes,omitempty"`
+		Status       otlpStatus `json:"status"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"` // 0 unset, 2 error
+		Message string `json:"message,omitempty"`
+	}
+	otlpAttr struct {
+		Key   string         `json:"key"`
+		Value map[string]any `json:"value"`
+	}
+)
+
+func (e *otlpExporter) request(traceID string, batch []spanData) otlpRequest {
+	scope := otlpScopeSpans{}
+	scope.Scope.Name = scopeName
+	for _, d := range batch {
+		sp := otlpSpan{
+			TraceID: traceID, SpanID: d.span.id, ParentSpanID: d.span.parentID, Name: d.span.name,
+			Kind:  1, // internal
+			Start: strconv.FormatInt(d.span.start.UnixNano(), 10), End: strconv.FormatInt(d.end.UnixNano(), 10),
+		}
+		for k, v := range d.attrs {
+			sp.Attributes = append(sp.Attributes, attr(k, v))
+		}
+		if d.errMsg != "" {
+			sp.Status = otlpStatus{Code: 2, Message: d.errMsg}
+		}
+		scope.Spans = append(scope.Spans, sp)
+	}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: []otlpAttr{attr("service.name", e.conf.Service)}},
+		ScopeSpans: []otlpScopeSpans{scope},
+	}}}
+}
+
+// attr encodes an attribute value of the types spans carry, others as strings
+func attr(key string, v any) otlpAttr {
+	switch val := v.(type) {
+	case string:
+		return otlpAttr{Key: key, Value: map[string]any{"stringValue": val}}
+	case bool:
+		return otlpAttr{Key: key, Value: map[string]any{"boolValue": val}}
+	case int:
+		return otlpAttr{Key: key, Value: map[string]any{"intValue": strconv.Itoa(val)}}
+	case int64:
+		return otlpAttr{Key: key, Value: map[string]any{"intValue": strconv.FormatInt(val, 10)}}
+	case float64:
+		return otlpAttr{Key: key, Value: map[string]any{"doubleValue": val}}
+	default:
+		return otlpAttr{Key: key, Value: map[string]any{"stringValue": fmt.Sprint(val)}}
+	}
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStart_export(t *testing.T) {
+	requests := make(chan otlpRequest, 2)
+	var auth string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		auth = r.Header.Get("Authorization")
+		var req otlpRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests <- req
+	}))
+	defer ts.Close()
+	t.Cleanup(func() { Configure(Config{}) })
+	Configure(Config{Endpoint: ts.URL + "/v1/traces", Headers: map[string]string{"Authorization": "Bearer x"}})
+
+	ctx, root := Start(context.Background(), "job", "job.id", "abc")
+	_, info := Start(ctx, "youtube.info", "url", "https://youtu.be/1")
+	info.End()
+	_, tts := Child(ctx, "tts", "chars", 42)
+	tts.Finish(errors.New("edge tts failed"))
+	root.End()
+
+	var req otlpRequest
+	select {
+	case req = <-requests:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no export")
+	}
+	assert.Equal(t, "Bearer x", auth)
+	require.Len(t, req.ResourceSpans, 1)
+	assert.Equal(t, []otlpAttr{{Key: "service.name", Value: map[string]any{"stringValue": "feed-master"}}},
+		req.ResourceSpans[0].Resource.Attributes)
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 3)
+	assert.Equal(t, []string{"youtube.info", "tts", "job"}, []string{spans[0].Name, spans[1].Name, spans[2].Name})
+	rootSpan := spans[2]
+	assert.Len(t, rootSpan.TraceID, 32)
+	assert.Len(t, rootSpan.SpanID, 16)
+	assert.Empty(t, rootSpan.ParentSpanID)
+	for _, sp := range spans[:2] {
+		assert.Equal(t, rootSpan.TraceID, sp.TraceID)
+		assert.Equal(t, rootSpan.SpanID, sp.ParentSpanID)
+	}
+	assert.Equal(t, []otlpAttr{{Key: "chars", Value: map[string]any{"intValue": "42"}}}, spans[1].Attributes)
+	assert.Equal(t, otlpStatus{Code: 2, Message: "edge tts failed"}, spans[1].Status)
+	assert.Equal(t, otlpStatus{}, rootSpan.Status)
+	assert.GreaterOrEqual(t, rootSpan.End, rootSpan.Start)
+
+	// a span ending after its trace went out is exported on its own
+	_, late := Start(ctx, "store")
+	late.End()
+	select {
+	case req = <-requests:
+		require.Len(t, req.ResourceSpans[0].ScopeSpans[0].Spans, 1)
+		assert.Equal(t, "store", req.ResourceSpans[0].ScopeSpans[0].Spans[0].Name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no export of the late span")
+	}
+}
+
+func TestStart_off(t *testing.T) {
+	Configure(Config{})
+	ctx, span := Start(context.Background(), "job")
+	assert.Nil(t, span)
+	span.SetAttrs("k", "v")
+	span.Finish(errors.New("boom"))
+	_, child := Child(ctx, "exec yt-dlp")
+	assert.Nil(t, child)
+}
+
+func TestChild_outsideTrace(t *testing.T) {
+	t.Cleanup(func() { Configure(Config{}) })
+	Configure(Config{Endpoint: "http://127.0.0.1:1/v1/traces"})
+	_, span := Child(context.Background(), "exec ffprobe")
+	assert.Nil(t, span, "commands outside a job are not traced")
+}
@@ -0,0 +1,56 @@
+// waveform players of the episodes page: draws the peaks (audiowaveform JSON)
+// of an episode on its canvas, shows the played part and seeks on click
+(function () {
+    'use strict';
+
+    function draw(canvas, peaks, progress) {
+        var ctx = canvas.getContext('2d');
+        var w = canvas.width, h = canvas.height, mid = h / 2;
+        var data = peaks.data, pairs = data.length / 2;
+        var scale = peaks.bits === 16 ? 32768 : 128;
+        ctx.clearRect(0, 0, w, h);
+        for (var x = 0; x < w; x++) {
+            var i = Math.floor(x * pairs / w) * 2;
+            var lo = data[i] / scale, hi = data[i + 1] / scale;
+            ctx.fillStyle = x / w < progress ? 'rgba(10, 107, 165, 0.87)' : 'rgba(0, 0, 0, 0.25)';
+            ctx.fillRect(x, mid - hi * mid, 1, Math.max((hi - lo) * mid, 1));
+        }
+    }
+
+    function setup(player) {
+        var canvas = player.querySelector('canvas.ump-waveform');
+        var audio = player.querySelector('audio');
+        var url = player.getAttribute('data-peaks');
+        if (!canvas || !audio || !url) {
+            return;
+        }
+        fetch(url).then(function (resp) {
+            if (!resp.ok) {
+                throw new Error(resp.status);
+            }
+            return resp.json();
+        }).then(function (peaks) {
+            // duration of the waveform, the audio doesn't know its own until loaded
+            var total = peaks.length * peaks.samples_per_pixel / peaks.sample_rate;
+            var redraw = function () {
+                canvas.width = canvas.clientWidth;
+                var d = audio.duration || total;
+                draw(canvas, peaks, d ? audio.currentTime / d : 0);
+            };
+            canvas.addEventListener('click', function (ev) {
+                var rect = canvas.getBoundingClientRect();
+                audio.currentTime = (ev.clientX - rect.left) / rect.width * (audio.duration || total);
+                audio.play();
+            });
+            audio.addEventListener('timeupdate', redraw);
+            window.addEventListener('resize', redraw);
+            redraw();
+        }).catch(function () {
+            canvas.remove(); // no waveform, the plain player stays
+        });
+    }
+
+    document.addEventListener('DOMContentLoaded', function () {
+        document.querySelectorAll('.ump-waveform-player').forEach(setup);
+    });
+})();
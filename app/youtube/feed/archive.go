@@ -0,0 +1,103 @@
+package feed
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Archive is a yt-dlp --download-archive file, a "youtube <id>" line for each
+// downloaded video. The file is shared with other yt-dlp runs (cron jobs), so
+// it is read again when it changes and appended to, never rewritten.
+// A nil Archive is empty and ignores additions.
+type Archive struct {
+	Path string
+
+	mu      sync.Mutex
+	ids     map[string]bool
+	modTime time.Time
+	size    int64
+}
+
+// archiveExtractor is the yt-dlp extractor key of YouTube videos in the archive
+const archiveExtractor = "youtube"
+
+// Has checks the video is in the archive
+func (a *Archive) Has(videoID string) (bool, error) {
+	if a == nil {
+		return false, nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.load(); err != nil {
+		return false, err
+	}
+	return a.ids[videoID], nil
+}
+
+// Add appends the video to the archive, unless it is there already
+func (a *Archive) Add(videoID string) error {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.load(); err != nil {
+		return err
+	}
+	if a.ids[videoID] {
+		return nil
+	}
+	fh, err := os.OpenFile(a.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644) // nolint
+	if err != nil {
+		return fmt.Errorf("can't open archive: %w", err)
+	}
+	// one write of a whole line, appends of other yt-dlp runs don't interleave with it
+	if _, err = fmt.Fprintf(fh, "%s %s\n", archiveExtractor, videoID); err != nil {
+		_ = fh.Close()
+		return fmt.Errorf("can't add %s to archive: %w", videoID, err)
+	}
+	if err = fh.Close(); err != nil {
+		return fmt.Errorf("can't close archive: %w", err)
+	}
+	a.ids[videoID] = true
+	return nil
+}
+
+// load reads the archive again if it changed since the last read, a missing
+// file is an empty archive
+func (a *Archive) load() error {
+	fi, err := os.Stat(a.Path)
+	if os.IsNotExist(err) {
+		a.ids, a.modTime, a.size = map[string]bool{}, time.Time{}, 0
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("can't stat archive: %w", err)
+	}
+	if a.ids != nil && fi.ModTime().Equal(a.modTime) && fi.Size() == a.size {
+		return nil
+	}
+
+	fh, err := os.Open(a.Path) // nolint
+	if err != nil {
+		return fmt.Errorf("can't open archive: %w", err)
+	}
+	defer fh.Close() // nolint
+	ids := map[string]bool{}
+	scanner := bufio.NewScanner(fh)
+	for scanner.Scan() {
+		extractor, id, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if ok && strings.EqualFold(extractor, archiveExtractor) && id != "" {
+			ids[strings.TrimSpace(id)] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("can't read archive: %w", err)
+	}
+	a.ids, a.modTime, a.size = ids, fi.ModTime(), fi.Size()
+	return nil
+}
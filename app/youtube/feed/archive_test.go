@@ -0,0 +1,50 @@
+package feed
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchive(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "archive.txt")
+	a := &Archive{Path: fname}
+
+	found, err := a.Has("dQw4w9WgXcQ")
+	require.NoError(t, err)
+	assert.False(t, found, "no file yet")
+
+	require.NoError(t, a.Add("dQw4w9WgXcQ"))
+	require.NoError(t, a.Add("dQw4w9WgXcQ"), "added once")
+	found, err = a.Has("dQw4w9WgXcQ")
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	// a cron job of yt-dlp appends to the same file
+	fh, err := os.OpenFile(fname, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = fh.WriteString("youtube 9bZkp7q19f0\r\nvimeo 76979871\n\nbroken\n")
+	require.NoError(t, err)
+	require.NoError(t, fh.Close())
+	require.NoError(t, os.Chtimes(fname, time.Now().Add(time.Minute), time.Now().Add(time.Minute)))
+
+	for id, want := range map[string]bool{"dQw4w9WgXcQ": true, "9bZkp7q19f0": true, "76979871": false, "broken": false} {
+		found, err = a.Has(id)
+		require.NoError(t, err)
+		assert.Equal(t, want, found, id)
+	}
+	require.NoError(t, a.Add("kJQP7kiw5Fk"))
+	data, err := os.ReadFile(fname)
+	require.NoError(t, err)
+	assert.Equal(t, "youtube dQw4w9WgXcQ\nyoutube 9bZkp7q19f0\r\nvimeo 76979871\n\nbroken\nyoutube kJQP7kiw5Fk\n", string(data))
+
+	var none *Archive
+	found, err = none.Has("dQw4w9WgXcQ")
+	require.NoError(t, err)
+	assert.False(t, found)
+	require.NoError(t, none.Add("dQw4w9WgXcQ"))
+}
@@ -11,10 +11,12 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
-	"text/template"
 	"time"
 
 	log "github.com/go-pkgz/lgr"
+
+	"github.com/umputun/feed-master/app/tools"
+	"github.com/umputun/feed-master/app/tracing"
 )
 
 // IsCookieError checks if yt-dlp error is related to expired/invalid cookies
@@ -46,6 +48,11 @@ type VideoInfo struct {
 	Thumbnail   string  `json:"thumbnail"`
 	UploadDate  string  `json:"upload_date"` // YYYYMMDD format
 	WebpageURL  string  `json:"webpage_url"`
+
+	Categories []string `json:"categories"`
+	Tags       []string `json:"tags"`
+	Track      string   `json:"track"`  // set by yt-dlp for music content
+	Artist     string   `json:"artist"` // set by yt-dlp for music content
 }
 
 // ErrSkip is returned when the file is not downloaded
@@ -53,6 +60,8 @@ var ErrSkip = errors.New("skip")
 
 // Downloader executes an external command to download a video and extract its audio.
 type Downloader struct {
+	Runner CommandRunner // yt-dlp metadata calls, nil = ExecRunner
+
 	ytTemplate   string
 	logOutWriter io.Writer
 	logErrWriter io.Writer
@@ -60,7 +69,8 @@ type Downloader struct {
 	cookiesFile  string
 }
 
-// NewDownloader creates a new Downloader with the given template (full command with placeholders for {{.ID}} and {{.Filename}}.
+// NewDownloader creates a new Downloader with the given template (full command with placeholders for {{.ID}} and {{.FileName}},
+// run without a shell, see RenderTemplate).
 // Destination is the directory where the audio files will be stored.
 func NewDownloader(tmpl string, logOutWriter, logErrWriter io.Writer, destination, cookiesFile string) *Downloader {
 	return &Downloader{
@@ -72,6 +82,14 @@ func NewDownloader(tmpl string, logOutWriter, logErrWriter io.Writer, destinatio
 	}
 }
 
+// runner returns the injected CommandRunner or the exec-backed default
+func (d *Downloader) runner() CommandRunner {
+	if d.Runner != nil {
+		return d.Runner
+	}
+	return ExecRunner{Stderr: d.logErrWriter}
+}
+
 // ytdlpArgs returns common yt-dlp arguments including cookies if configured
 func (d *Downloader) ytdlpArgs(args ...string) []string {
 	var result []string
@@ -83,56 +101,97 @@ func (d *Downloader) ytdlpArgs(args ...string) []string {
 	return result
 }
 
+// GetOption tunes a single download
+type GetOption func(*getOptions)
+
+type getOptions struct {
+	audioQuality string
+}
+
+// AudioQuality makes the download pass --audio-quality to yt-dlp (0 best to 10
+// worst, or a bitrate like 128K), over the one of the template
+func AudioQuality(quality string) GetOption {
+	return func(o *getOptions) { o.audioQuality = quality }
+}
+
 // Get downloads a video from youtube and extracts audio.
 // yt-dlp --extract-audio --audio-format=mp3 --audio-quality=0 -f m4a/bestaudio "https://www.youtube.com/watch?v={{.ID}}" --no-progress -o {{.Filename}}
 // On cookie errors, retries without cookies as a fallback.
-func (d *Downloader) Get(ctx context.Context, id, fname string) (file string, err error) {
-	file, err = d.get(ctx, id, fname, true)
+func (d *Downloader) Get(ctx context.Context, id, fname string, opts ...GetOption) (file string, err error) {
+	ctx, span := tracing.Start(ctx, "youtube.download", "video.id", id)
+	defer func() { span.Finish(err) }()
+	var o getOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	file, err = d.get(ctx, id, fname, o, true)
 	if err != nil && d.cookiesFile != "" && IsCookieError(err.Error()) {
 		log.Printf("[WARN] cookies expired, retrying Get without cookies")
-		return d.get(ctx, id, fname, false)
+		return d.get(ctx, id, fname, o, false)
 	}
 	return file, err
 }
 
-func (d *Downloader) get(ctx context.Context, id, fname string, useCookies bool) (file string, err error) {
+func (d *Downloader) get(ctx context.Context, id, fname string, o getOptions, useCookies bool) (file string, err error) {
 	if err := os.MkdirAll(d.destination, 0o750); err != nil {
 		return "", fmt.Errorf("failed to create directory %s: %w", d.destination, err)
 	}
 
-	tmplParams := struct {
-		ID       string
-		FileName string
-	}{
-		ID:       id,
-		FileName: fname,
+	argv, err := RenderTemplate(d.ytTemplate, TemplateParams{ID: id, FileName: fname})
+	if err != nil {
+		return "", fmt.Errorf("failed to render download template: %w", err)
 	}
-	b1 := bytes.Buffer{}
-	if err := template.Must(template.New("youtube-dl").Parse(d.ytTemplate)).Execute(&b1, tmplParams); err != nil { // nolint
-		return "", fmt.Errorf("failed to parse template: %v", err)
+	if useCookies && d.cookiesFile != "" && isYtDlp(argv[0]) {
+		argv = append([]string{argv[0], "--cookies", d.cookiesFile}, argv[1:]...)
+	}
+	if o.audioQuality != "" && isYtDlp(argv[0]) {
+		argv = append(argv, "--audio-quality", o.audioQuality) // the last one wins
 	}
 
-	cmdStr := b1.String()
-	if useCookies && d.cookiesFile != "" {
-		cmdStr = strings.Replace(cmdStr, "yt-dlp ", "yt-dlp --cookies "+d.cookiesFile+" ", 1)
+	release, err := tools.Acquire(ctx, tools.ClassDownload)
+	if err != nil {
+		return "", err
 	}
+	defer release()
 
-	cmd := exec.CommandContext(ctx, "sh", "-c", cmdStr) // nolint
-	cmd.Stdin = os.Stdin
+	// partials, thumbnails and other intermediates stay in the job dir and go with it
+	jobDir, err := MakeJobDir(d.destination)
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(jobDir)
+
+	var cmd *exec.Cmd
+	if argv[0] == "yt-dlp" {
+		cmd = tools.Command(ctx, "yt-dlp", argv[1:]...) // configured binary or image
+	} else {
+		cmd = exec.CommandContext(ctx, argv[0], argv[1:]...) //nolint:gosec // command of the config
+	}
 	cmd.Stdout = d.logOutWriter
 	var stderrBuf bytes.Buffer
 	cmd.Stderr = io.MultiWriter(d.logErrWriter, &stderrBuf)
-	cmd.Dir = d.destination
-	log.Printf("[DEBUG] executing command: %s", cmdStr)
+	cmd.Dir = jobDir
+	killGroupOnCancel(cmd)
+	cmd.WaitDelay = 10 * time.Second // don't hang on pipes held by a killed grandchild
+	cleanup, err := tools.Prepare(ctx, cmd)
+	defer cleanup()
+	if err != nil {
+		return "", err
+	}
+	log.Printf("[DEBUG] executing command: %s", strings.Join(cmd.Args, " "))
 	if err := cmd.Run(); err != nil {
 		stderrStr := stderrBuf.String()
 		if stderrStr != "" {
-			return "", fmt.Errorf("failed to execute command: %v\n%s", err, stderrStr)
+			return "", WrapFailure(fmt.Errorf("failed to execute command: %v\n%s", err, stderrStr), stderrStr)
 		}
 		return "", fmt.Errorf("failed to execute command: %v", err)
 	}
 
 	file = filepath.Join(d.destination, fname+".mp3")
+	// a template with an absolute output path writes the file in place
+	if err := os.Rename(filepath.Join(jobDir, fname+".mp3"), file); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to move %s: %w", fname, err)
+	}
 	if _, err := os.Stat(file); os.IsNotExist(err) {
 		return file, ErrSkip
 	}
@@ -141,8 +200,10 @@ func (d *Downloader) get(ctx context.Context, id, fname string, useCookies bool)
 
 // GetInfo fetches video metadata without downloading using yt-dlp --dump-json.
 // On cookie errors, retries without cookies as a fallback.
-func (d *Downloader) GetInfo(ctx context.Context, videoURL string) (*VideoInfo, error) {
-	info, err := d.getInfo(ctx, videoURL, true)
+func (d *Downloader) GetInfo(ctx context.Context, videoURL string) (info *VideoInfo, err error) {
+	ctx, span := tracing.Start(ctx, "youtube.info", "url", videoURL)
+	defer func() { span.Finish(err) }()
+	info, err = d.getInfo(ctx, videoURL, true)
 	if err != nil && d.cookiesFile != "" && IsCookieError(err.Error()) {
 		log.Printf("[WARN] cookies expired, retrying GetInfo without cookies")
 		return d.getInfo(ctx, videoURL, false)
@@ -159,25 +220,31 @@ func (d *Downloader) getInfo(ctx context.Context, videoURL string, useCookies bo
 		args = ytdlpArgsWithoutCookies(args)
 	}
 
-	cmd := exec.CommandContext(ctx, "yt-dlp", args...)
-	var stderrBuf bytes.Buffer
-	cmd.Stderr = io.MultiWriter(d.logErrWriter, &stderrBuf)
-
-	output, err := cmd.Output()
+	output, stderr, err := d.runner().Run(ctx, "yt-dlp", args...)
 	if err != nil {
-		stderrStr := stderrBuf.String()
-		if stderrStr != "" {
-			return nil, fmt.Errorf("failed to get video info: %w\n%s", err, stderrStr)
+		if stderrStr := string(stderr); stderrStr != "" {
+			return nil, WrapFailure(fmt.Errorf("failed to get video info: %w\n%s", err, stderrStr), stderrStr)
 		}
 		return nil, fmt.Errorf("failed to get video info: %w", err)
 	}
 
+	info, err := parseVideoInfo(output)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] got video info: id=%s, title=%s, duration=%.0fs", info.ID, info.Title, info.Duration)
+	return info, nil
+}
+
+// parseVideoInfo decodes "yt-dlp --dump-json" output
+func parseVideoInfo(output []byte) (*VideoInfo, error) {
 	var info VideoInfo
 	if err := json.Unmarshal(output, &info); err != nil {
 		return nil, fmt.Errorf("failed to parse video info: %w", err)
 	}
-
-	log.Printf("[DEBUG] got video info: id=%s, title=%s, duration=%.0fs", info.ID, info.Title, info.Duration)
+	if info.ID == "" {
+		return nil, fmt.Errorf("failed to parse video info: no id in yt-dlp output")
+	}
 	return &info, nil
 }
 
@@ -205,14 +272,10 @@ func (d *Downloader) expandPlaylist(ctx context.Context, playlistURL string, use
 	}
 	args = append(args, "--flat-playlist", "--no-download", "--print", "id", playlistURL)
 
-	cmd := exec.CommandContext(ctx, "yt-dlp", args...)
-	var stderrBuf bytes.Buffer
-	cmd.Stderr = io.MultiWriter(d.logErrWriter, &stderrBuf)
-
-	output, err := cmd.Output()
+	output, stderr, err := d.runner().Run(ctx, "yt-dlp", args...)
 	if err != nil {
-		if stderrStr := stderrBuf.String(); stderrStr != "" {
-			return nil, fmt.Errorf("failed to expand playlist: %w\n%s", err, stderrStr)
+		if stderrStr := string(stderr); stderrStr != "" {
+			return nil, WrapFailure(fmt.Errorf("failed to expand playlist: %w\n%s", err, stderrStr), stderrStr)
 		}
 		return nil, fmt.Errorf("failed to expand playlist: %w", err)
 	}
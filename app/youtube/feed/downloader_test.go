@@ -3,15 +3,20 @@ package feed
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/feed-master/app/tools"
+	"github.com/umputun/feed-master/app/youtube/feed/mocks"
 )
 
 func TestDownloader_Get(t *testing.T) {
@@ -32,6 +37,37 @@ func TestDownloader_Get(t *testing.T) {
 	t.Log(l)
 }
 
+func TestDownloader_GetConfiguredYtDlp(t *testing.T) {
+	t.Cleanup(func() { tools.Configure(nil) })
+	tools.Configure(map[string]tools.Tool{"yt-dlp": {Path: "echo"}})
+	lw := bytes.NewBuffer(nil)
+	loc := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(loc, "f1.mp3"), []byte("x"), 0o600))
+
+	d := NewDownloader("yt-dlp -x {{.ID}} -o {{.FileName}}.mp3", lw, lw, loc, "")
+	_, err := d.Get(context.Background(), "id1", "f1")
+	require.NoError(t, err)
+	assert.Equal(t, "-x id1 -o f1.mp3\n", lw.String(), "yt-dlp of the template replaced by the configured binary")
+}
+
+func TestDownloader_GetAudioQuality(t *testing.T) {
+	t.Cleanup(func() { tools.Configure(nil) })
+	tools.Configure(map[string]tools.Tool{"yt-dlp": {Path: "echo"}})
+	lw := bytes.NewBuffer(nil)
+	loc := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(loc, "f1.mp3"), []byte("x"), 0o600))
+
+	d := NewDownloader("yt-dlp -x --audio-quality=0 {{.ID}} -o {{.FileName}}.mp3", lw, lw, loc, "")
+	_, err := d.Get(context.Background(), "id1", "f1", AudioQuality("5"))
+	require.NoError(t, err)
+	assert.Equal(t, "-x --audio-quality=0 id1 -o f1.mp3 --audio-quality 5\n", lw.String())
+
+	lw.Reset()
+	_, err = d.Get(context.Background(), "id1", "f1")
+	require.NoError(t, err)
+	assert.Equal(t, "-x --audio-quality=0 id1 -o f1.mp3\n", lw.String(), "template's quality without one")
+}
+
 func TestDownloader_GetSkip(t *testing.T) {
 	lw := bytes.NewBuffer(nil)
 	loc := os.TempDir()
@@ -61,6 +97,18 @@ func TestDownloader_GetFailed(t *testing.T) {
 	assert.Equal(t, fh.Name(), res)
 }
 
+func TestDownloader_GetCanceled(t *testing.T) {
+	lw := bytes.NewBuffer(nil)
+	d := NewDownloader(`sh -c "sleep 30 && echo {{.ID}} {{.FileName}}"`, lw, lw, t.TempDir(), "")
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	st := time.Now()
+	_, err := d.Get(ctx, "id1", "fname")
+	require.Error(t, err)
+	assert.Less(t, time.Since(st), 5*time.Second, "shell and its children killed on cancel")
+}
+
 func TestIsCookieError(t *testing.T) {
 	tests := []struct {
 		name string
@@ -103,3 +151,99 @@ func TestParseFlatPlaylistIDs(t *testing.T) {
 		})
 	}
 }
+
+func TestDownloader_GetInfoRunner(t *testing.T) {
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			return []byte(`{"id":"abc","title":"Song","duration":212,"categories":["Music"],"track":"Song","artist":"Band"}`), nil, nil
+		},
+	}
+	d := NewDownloader("", nil, nil, os.TempDir(), "")
+	d.Runner = runner
+
+	info, err := d.GetInfo(context.Background(), "https://www.youtube.com/watch?v=abc")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", info.ID)
+	assert.Equal(t, "Song", info.Title)
+	assert.Equal(t, []string{"Music"}, info.Categories)
+	assert.Equal(t, "Band", info.Artist)
+
+	require.Len(t, runner.RunCalls(), 1)
+	assert.Equal(t, "yt-dlp", runner.RunCalls()[0].Name)
+	assert.Contains(t, runner.RunCalls()[0].Args, "--dump-json")
+}
+
+func TestDownloader_GetInfoCookieRetry(t *testing.T) {
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			for _, a := range args {
+				if a == "--cookies" {
+					return nil, []byte("ERROR: The provided YouTube account cookies are no longer valid"), errors.New("exit status 1")
+				}
+			}
+			return []byte(`{"id":"abc","title":"t"}`), nil, nil
+		},
+	}
+	d := NewDownloader("", nil, nil, os.TempDir(), "/tmp/cookies.txt")
+	d.Runner = runner
+
+	info, err := d.GetInfo(context.Background(), "https://www.youtube.com/watch?v=abc")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", info.ID)
+	require.Len(t, runner.RunCalls(), 2)
+	assert.NotContains(t, runner.RunCalls()[1].Args, "--cookies")
+}
+
+func TestDownloader_GetInfoFailure(t *testing.T) {
+	runner := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			return nil, []byte("ERROR: [youtube] abc: Private video. Sign in if you've been granted access"), errors.New("exit status 1")
+		},
+	}
+	d := NewDownloader("", nil, nil, os.TempDir(), "")
+	d.Runner = runner
+
+	_, err := d.GetInfo(context.Background(), "https://www.youtube.com/watch?v=abc")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrPrivate)
+}
+
+func TestParseVideoInfo(t *testing.T) {
+	tbl := []struct {
+		name    string
+		out     string
+		wantErr bool
+	}{
+		{"valid", `{"id":"abc","title":"t","duration":10}`, false},
+		{"no id", `{"title":"t"}`, true},
+		{"broken json", `{"id":`, true},
+		{"empty", ``, true},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := parseVideoInfo([]byte(tt.out))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "abc", info.ID)
+		})
+	}
+}
+
+func TestDownloader_GetJobDir(t *testing.T) {
+	loc := t.TempDir()
+	lw := bytes.NewBuffer(nil)
+	// writes the audio and a thumbnail leftover in the working directory, as yt-dlp does
+	d := NewDownloader("sh -c 'touch {{.FileName}}.mp3 {{.FileName}}.webp {{.FileName}}.m4a.part'", lw, lw, loc, "")
+	res, err := d.Get(context.Background(), "id1", "f1")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(loc, "f1.mp3"), res)
+	assert.FileExists(t, res)
+
+	left, err := os.ReadDir(loc)
+	require.NoError(t, err)
+	require.Len(t, left, 1, "intermediates removed with the job dir")
+	assert.Equal(t, "f1.mp3", left[0].Name())
+}
@@ -0,0 +1,126 @@
+package feed
+
+import (
+	"errors"
+	"strings"
+)
+
+// FailureKind is a class of yt-dlp failure recognized from its stderr
+type FailureKind string
+
+// enum of recognized yt-dlp failure kinds
+const (
+	FailureUnknown       = FailureKind("")
+	FailureAgeRestricted = FailureKind("age_restricted")
+	FailureGeoBlocked    = FailureKind("geo_blocked")
+	FailureMembersOnly   = FailureKind("members_only")
+	FailurePrivate       = FailureKind("private")
+	FailureRemoved       = FailureKind("removed")
+	FailureCookies       = FailureKind("cookies")
+)
+
+// failureMarkers maps stderr fragments to failure kinds. Order matters:
+// "Sign in to confirm your age" is also a cookie marker, so age goes first.
+var failureMarkers = []struct {
+	kind    FailureKind
+	markers []string
+}{
+	{FailureAgeRestricted, []string{"Sign in to confirm your age", "age-restricted", "inappropriate for some users"}},
+	{FailureMembersOnly, []string{"members-only", "Join this channel to get access", "available to this channel's members"}},
+	{FailureGeoBlocked, []string{"not available in your country", "not made this video available in your country",
+		"geo restriction", "geo-restricted", "blocked it in your country"}},
+	{FailurePrivate, []string{"Private video", "This video is private"}},
+	{FailureRemoved, []string{"Video unavailable", "has been removed", "account associated with this video has been terminated",
+		"copyright claim", "This video does not exist", "HTTP Error 404"}},
+	{FailureCookies, []string{"cookies are no longer valid", "cookies have expired", "Please sign in", "Sign in to confirm you"}},
+}
+
+// ClassifyFailure recognizes the failure kind in yt-dlp output
+func ClassifyFailure(stderr string) FailureKind {
+	for _, fm := range failureMarkers {
+		for _, m := range fm.markers {
+			if strings.Contains(stderr, m) {
+				return fm.kind
+			}
+		}
+	}
+	return FailureUnknown
+}
+
+// sentinel errors for the failure kinds, a *VideoError matches its kind's
+// sentinel with errors.Is so callers can branch without inspecting Kind
+var (
+	ErrAgeRestricted = errors.New("age-restricted video")
+	ErrMembersOnly   = errors.New("members-only video")
+	ErrGeoBlocked    = errors.New("video is not available in this country")
+	ErrPrivate       = errors.New("private video")
+	ErrRemoved       = errors.New("video removed or unavailable")
+	ErrCookieExpired = errors.New("youtube cookies expired")
+)
+
+var kindErrors = map[FailureKind]error{
+	FailureAgeRestricted: ErrAgeRestricted,
+	FailureMembersOnly:   ErrMembersOnly,
+	FailureGeoBlocked:    ErrGeoBlocked,
+	FailurePrivate:       ErrPrivate,
+	FailureRemoved:       ErrRemoved,
+	FailureCookies:       ErrCookieExpired,
+}
+
+// VideoError is a yt-dlp failure with a recognized kind. Error() keeps the
+// full text (stderr included) for logs, Hint() is the short user-facing form.
+type VideoError struct {
+	Kind FailureKind
+	Err  error
+}
+
+func (e *VideoError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error
+func (e *VideoError) Unwrap() error { return e.Err }
+
+// Is matches the sentinel error of the failure kind
+func (e *VideoError) Is(target error) bool {
+	sentinel, ok := kindErrors[e.Kind]
+	return ok && sentinel == target
+}
+
+// Hint returns an actionable message for the failure kind
+func (e *VideoError) Hint() string {
+	switch e.Kind {
+	case FailureAgeRestricted:
+		return "🔞 Видео с возрастным ограничением. Нужны cookies аккаунта, подтвердившего возраст: пришли свежий cookies.txt файлом."
+	case FailureMembersOnly:
+		return "💎 Видео только для спонсоров канала. Нужны cookies аккаунта с подпиской: пришли cookies.txt файлом."
+	case FailureGeoBlocked:
+		return "🌍 Видео недоступно в стране сервера. Помогут прокси (--proxy в dl_template) или cookies из другой страны."
+	case FailurePrivate:
+		return "🔒 Приватное видео. Скачать можно только с cookies аккаунта, у которого есть доступ."
+	case FailureRemoved:
+		return "🗑 Видео удалено или недоступно (удалено автором, заблокировано, канал закрыт)."
+	case FailureCookies:
+		return "❌ YouTube cookies expired. This video requires authentication.\nRun update-cookies.sh to fix."
+	}
+	return e.Err.Error()
+}
+
+// WrapFailure wraps err into *VideoError when yt-dlp stderr has a recognized
+// failure, otherwise returns err unchanged
+func WrapFailure(err error, stderr string) error {
+	if err == nil {
+		return nil
+	}
+	if kind := ClassifyFailure(stderr); kind != FailureUnknown {
+		return &VideoError{Kind: kind, Err: err}
+	}
+	return err
+}
+
+// AsVideoError extracts *VideoError from the error chain
+func AsVideoError(err error) (*VideoError, bool) {
+	var ve *VideoError
+	if errors.As(err, &ve) {
+		return ve, true
+	}
+	return nil, false
+}
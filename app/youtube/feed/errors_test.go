@@ -0,0 +1,46 @@
+package feed
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		want   FailureKind
+	}{
+		{"age", "ERROR: [youtube] abc: Sign in to confirm your age. This video may be inappropriate for some users.", FailureAgeRestricted},
+		{"members", "ERROR: [youtube] abc: Join this channel to get access to members-only content like this video", FailureMembersOnly},
+		{"geo", "ERROR: [youtube] abc: The uploader has not made this video available in your country", FailureGeoBlocked},
+		{"private", "ERROR: [youtube] abc: Private video. Sign in if you've been granted access to this video", FailurePrivate},
+		{"removed", "ERROR: [youtube] abc: Video unavailable. This video has been removed by the uploader", FailureRemoved},
+		{"cookies", "ERROR: [youtube] abc: Sign in to confirm you're not a bot", FailureCookies},
+		{"unknown", "ERROR: unable to download webpage: timed out", FailureUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClassifyFailure(tt.stderr))
+		})
+	}
+}
+
+func TestWrapFailure(t *testing.T) {
+	base := errors.New("exit status 1")
+	assert.Nil(t, WrapFailure(nil, "Private video"))
+	assert.Equal(t, base, WrapFailure(base, "network timeout"), "unknown failures pass through")
+
+	err := fmt.Errorf("failed to get video info: %w", WrapFailure(base, "Private video"))
+	ve, ok := AsVideoError(err)
+	require.True(t, ok)
+	assert.Equal(t, FailurePrivate, ve.Kind)
+	assert.True(t, errors.Is(err, base), "original error stays in the chain")
+	assert.True(t, errors.Is(err, ErrPrivate), "kind sentinel matches")
+	assert.False(t, errors.Is(err, ErrGeoBlocked))
+	assert.Contains(t, ve.Hint(), "Приватное")
+}
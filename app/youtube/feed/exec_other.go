@@ -0,0 +1,9 @@
+//go:build windows
+
+package feed
+
+import "os/exec"
+
+// killGroupOnCancel is a no-op without unix process groups, the default
+// CommandContext kill of the direct child applies
+func killGroupOnCancel(_ *exec.Cmd) {}
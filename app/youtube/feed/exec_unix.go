@@ -0,0 +1,18 @@
+//go:build !windows
+
+package feed
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killGroupOnCancel puts cmd into its own process group and kills the whole
+// group when the context is canceled: yt-dlp runs ffmpeg as its children to
+// extract and convert the audio, and killing only yt-dlp would orphan them
+func killGroupOnCancel(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
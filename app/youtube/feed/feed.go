@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"html/template"
 	"net/http"
+	"slices"
 	"sort"
 	"time"
 )
@@ -105,6 +106,50 @@ type Entry struct {
 	File        string
 	Duration    int    // seconds
 	DurationFmt string // used for ui only
+
+	FileSize int64  `xml:"-"` // bytes of File when it was saved, kept after the file moves to R2
+	SHA256   string `xml:"-"` // hex checksum of File when it was saved
+
+	ContentHash string `xml:"-"` // hash of the normalized source text (articles), finds syndicated copies
+
+	SpeedFile     string `xml:"-"` // time-stretched copy of File for the sped-up feed, "" = none
+	SpeedFileSize int64  `xml:"-"` // bytes of SpeedFile
+
+	Pinned bool `xml:"-"` // kept by the age-based expiry
+
+	PublishAt time.Time `xml:"-"` // scheduled, hidden from the feeds until released at this time; zero = published
+
+	Kind Kind `xml:"-"` // what the entry was made from, see EntryKind
+
+	Processing Processing `xml:"-"` // how the entry was made, zero for downloads and entries saved before it was recorded
+
+	Added time.Time `xml:"-"` // when the entry was saved to the store, zero for entries saved before it was recorded
+
+	Sources []string `xml:"-"` // voiceover inputs kept for a remix (voice track, original audio), local only
+
+	Transcript string `xml:"-"` // word-aligned WebVTT of the voiced text next to File, "" = none
+	Chapters   string `xml:"-"` // JSON chapters (podcast namespace) next to File, "" = none
+	Script     string `xml:"-"` // voiced text (the translation, if translated) next to File for /revoice, "" = none
+	Peaks      string `xml:"-"` // waveform (audiowaveform JSON) next to File for the web player, "" = none
+
+	GUID string `xml:"-"` // item guid kept from an imported feed, "" = UID
+
+	SchemaVersion int `xml:"-"` // stored record layout version, set by the store
+}
+
+// AddedAt returns when the entry was added to the feed, its publication
+// time for entries saved before that was recorded
+func (e *Entry) AddedAt() time.Time {
+	if e.Added.IsZero() {
+		return e.Published
+	}
+	return e.Added
+}
+
+// Released drops the scheduled entries (PublishAt set), the ones every public
+// view of a feed hides until the scheduler releases them
+func Released(entries []Entry) []Entry {
+	return slices.DeleteFunc(entries, func(e Entry) bool { return !e.PublishAt.IsZero() })
 }
 
 // UID returns the unique identifier of the entry.
@@ -112,6 +157,15 @@ func (e *Entry) UID() string {
 	return e.ChannelID + "::" + e.VideoID
 }
 
+// ItemGUID returns the guid of the entry in the RSS feeds: the one of the feed
+// it was imported from, so podcast apps don't fetch it again, or the UID
+func (e *Entry) ItemGUID() string {
+	if e.GUID != "" {
+		return e.GUID
+	}
+	return e.UID()
+}
+
 func (e *Entry) String() string {
 	tz, _ := time.LoadLocation("Local")
 
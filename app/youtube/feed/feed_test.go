@@ -84,3 +84,11 @@ func TestFeed_url(t *testing.T) {
 		})
 	}
 }
+
+func TestReleased(t *testing.T) {
+	entries := []Entry{{VideoID: "now"}, {VideoID: "later", PublishAt: time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)}, {VideoID: "old"}}
+	res := Released(entries)
+	require.Len(t, res, 2)
+	assert.Equal(t, "now", res[0].VideoID)
+	assert.Equal(t, "old", res[1].VideoID)
+}
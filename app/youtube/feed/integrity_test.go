@@ -0,0 +1,59 @@
+package feed
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntry_Integrity(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "a.mp3")
+	require.NoError(t, os.WriteFile(file, []byte("hello"), 0o600))
+
+	e := Entry{File: file}
+	status, err := e.VerifyIntegrity()
+	require.NoError(t, err)
+	assert.Equal(t, IntegrityUnknown, status, "nothing recorded yet")
+
+	require.NoError(t, e.SetIntegrity())
+	assert.Equal(t, int64(5), e.FileSize)
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", e.SHA256)
+
+	status, err = e.VerifyIntegrity()
+	require.NoError(t, err)
+	assert.Equal(t, IntegrityOK, status)
+
+	require.NoError(t, os.WriteFile(file, []byte("hellO"), 0o600))
+	status, err = e.VerifyIntegrity()
+	require.NoError(t, err)
+	assert.Equal(t, IntegrityChecksum, status)
+
+	require.NoError(t, os.WriteFile(file, []byte("hel"), 0o600))
+	status, err = e.VerifyIntegrity()
+	require.NoError(t, err)
+	assert.Equal(t, IntegritySize, status)
+
+	require.NoError(t, os.Remove(file))
+	status, err = e.VerifyIntegrity()
+	require.NoError(t, err)
+	assert.Equal(t, IntegrityMissing, status)
+
+	assert.Error(t, e.SetIntegrity())
+}
+
+func TestEntry_MimeType(t *testing.T) {
+	tbl := []struct{ file, want string }{
+		{"/a/b.mp3", "audio/mpeg"},
+		{"/a/b.M4A", "audio/mp4"},
+		{"/a/b.opus", "audio/ogg"},
+		{"/a/b.webm", "audio/webm"},
+		{"", "audio/mpeg"},
+	}
+	for _, tt := range tbl {
+		e := Entry{File: tt.file}
+		assert.Equal(t, tt.want, e.MimeType(), tt.file)
+	}
+}
@@ -0,0 +1,113 @@
+package feed
+
+import (
+	"slices"
+	"strings"
+)
+
+// Kind is what an entry was made from
+type Kind string
+
+// enum of entry kinds, used as RSS categories and for ?kind= filtering
+const (
+	KindVideo       = Kind("video")        // audio downloaded from a video
+	KindPodcast     = Kind("podcast")      // episode of a podcast, downloaded as is
+	KindArticle     = Kind("article")      // article read by TTS
+	KindDigest      = Kind("digest")       // daily digest of articles
+	KindVoiceover   = Kind("voiceover")    // translated voice track, machine (vot-cli) or official dub
+	KindSubtitleTTS = Kind("subtitle-tts") // translated subtitles or transcript read by TTS
+)
+
+// Kinds lists all entry kinds
+var Kinds = []Kind{KindVideo, KindPodcast, KindArticle, KindDigest, KindVoiceover, KindSubtitleTTS}
+
+// kindEmojis are put in front of the titles of the bot feed, podcasts have none
+var kindEmojis = map[Kind]string{
+	KindVideo:       "📼",
+	KindArticle:     "📖",
+	KindDigest:      "🗞",
+	KindVoiceover:   "🎙",
+	KindSubtitleTTS: "📝",
+}
+
+// titleEmojis are the prefixes the pipelines put in stored titles before the
+// titles were kept plain, by kind
+var titleEmojis = map[string]Kind{
+	"📼": KindVideo,
+	"📖": KindArticle,
+	"🗞": KindDigest,
+	"🎙": KindVoiceover,
+	"🎬": KindVoiceover,
+	"📝": KindSubtitleTTS,
+}
+
+// EntryKind returns the kind of the entry, derived from its id for entries
+// saved before the kind was recorded. The title isn't looked at: entries of
+// the bot feed got their kind on migration, the rest are YouTube videos whose
+// titles may start with any emoji.
+func (e *Entry) EntryKind() Kind {
+	if e.Kind != "" {
+		return e.Kind
+	}
+	return LegacyKind(e.VideoID, "")
+}
+
+// LegacyKind tells the kind by the id prefix and title emoji the pipelines used
+// before Entry.Kind: art_ articles, dig_ digests, vo_ voiceovers, ap_ podcasts.
+// Only entries of the bot feed had the emoji, pass "" as the title for others.
+func LegacyKind(videoID, title string) Kind {
+	emoji, _, _ := strings.Cut(title, " ")
+	switch {
+	case strings.HasPrefix(videoID, "art_"):
+		return KindArticle
+	case strings.HasPrefix(videoID, "dig_"):
+		return KindDigest
+	case strings.HasPrefix(videoID, "vo_") && titleEmojis[emoji] == KindSubtitleTTS:
+		return KindSubtitleTTS
+	case strings.HasPrefix(videoID, "vo_"):
+		return KindVoiceover
+	case strings.HasPrefix(videoID, "ap_"):
+		return KindPodcast
+	case titleEmojis[emoji] == KindArticle:
+		return KindArticle
+	}
+	return KindVideo
+}
+
+// EmojiTitle is the title with the emoji of the entry kind in front, 🎬 for
+// an official dub
+func (e *Entry) EmojiTitle() string {
+	emoji := kindEmojis[e.EntryKind()]
+	if e.EntryKind() == KindVoiceover && e.Processing.Method == "youtube-dubbed" {
+		emoji = "🎬"
+	}
+	if emoji == "" {
+		return e.PlainTitle()
+	}
+	return emoji + " " + e.PlainTitle()
+}
+
+// PlainTitle is the title without the kind emoji in front, as titles saved
+// before they were kept plain had
+func (e *Entry) PlainTitle() string {
+	emoji, rest, ok := strings.Cut(e.Title, " ")
+	if _, known := titleEmojis[emoji]; ok && known {
+		return strings.TrimSpace(rest)
+	}
+	return e.Title
+}
+
+// ParseKinds parses a comma separated list of kinds, ok is false on an unknown one
+func ParseKinds(s string) (res []Kind, ok bool) {
+	for _, part := range strings.Split(s, ",") {
+		k := Kind(strings.TrimSpace(part))
+		if k == "" {
+			continue
+		}
+		if !slices.Contains(Kinds, k) {
+			return nil, false
+		}
+		res = append(res, k)
+	}
+	return res, true
+}
@@ -0,0 +1,77 @@
+package feed
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntry_EntryKind(t *testing.T) {
+	tbl := []struct {
+		entry Entry
+		want  Kind
+	}{
+		{Entry{VideoID: "abc123", Title: "some video"}, KindVideo},
+		{Entry{VideoID: "abc123", Title: "📖 Книга недели"}, KindVideo},
+		{Entry{VideoID: "art_1", Title: "📖 article"}, KindArticle},
+		{Entry{VideoID: "dig_1", Title: "🗞 Digest"}, KindDigest},
+		{Entry{VideoID: "vo_abc", Title: "🎙 translated"}, KindVoiceover},
+		{Entry{VideoID: "ap_123", Title: "episode"}, KindPodcast},
+		{Entry{VideoID: "art_1", Title: "digest", Kind: KindDigest}, KindDigest},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.entry.VideoID+" "+tt.entry.Title, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.entry.EntryKind())
+		})
+	}
+}
+
+func TestLegacyKind(t *testing.T) {
+	assert.Equal(t, KindVideo, LegacyKind("abc123", "some video"))
+	assert.Equal(t, KindArticle, LegacyKind("art_1", "article"))
+	assert.Equal(t, KindVoiceover, LegacyKind("vo_abc", "🎬 dubbed"))
+	assert.Equal(t, KindSubtitleTTS, LegacyKind("vo_abc", "📝 subtitles"))
+	assert.Equal(t, KindArticle, LegacyKind("x1", "📖 uploaded"))
+}
+
+func TestEntry_PlainTitle(t *testing.T) {
+	assert.Equal(t, "article", (&Entry{Title: "📖 article"}).PlainTitle())
+	assert.Equal(t, "subtitles", (&Entry{Title: "📝  subtitles"}).PlainTitle())
+	assert.Equal(t, "no emoji", (&Entry{Title: "no emoji"}).PlainTitle())
+	assert.Equal(t, "🎉 party", (&Entry{Title: "🎉 party"}).PlainTitle(), "not a kind emoji")
+	assert.Equal(t, "📖", (&Entry{Title: "📖"}).PlainTitle())
+}
+
+func TestEntry_EmojiTitle(t *testing.T) {
+	tbl := []struct {
+		entry Entry
+		want  string
+	}{
+		{Entry{Title: "video", Kind: KindVideo}, "📼 video"},
+		{Entry{Title: "article", Kind: KindArticle}, "📖 article"},
+		{Entry{Title: "📖 legacy", Kind: KindArticle}, "📖 legacy"},
+		{Entry{Title: "translated", Kind: KindVoiceover}, "🎙 translated"},
+		{Entry{Title: "dub", Kind: KindVoiceover, Processing: Processing{Method: "youtube-dubbed"}}, "🎬 dub"},
+		{Entry{Title: "subtitles", Kind: KindSubtitleTTS}, "📝 subtitles"},
+		{Entry{Title: "episode", Kind: KindPodcast}, "episode"},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.entry.EmojiTitle())
+		})
+	}
+}
+
+func TestParseKinds(t *testing.T) {
+	res, ok := ParseKinds("article, digest,,video")
+	assert.True(t, ok)
+	assert.Equal(t, []Kind{KindArticle, KindDigest, KindVideo}, res)
+
+	res, ok = ParseKinds("article,document")
+	assert.False(t, ok)
+	assert.Nil(t, res)
+
+	res, ok = ParseKinds("")
+	assert.True(t, ok)
+	assert.Empty(t, res)
+}
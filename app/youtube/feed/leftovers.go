@@ -0,0 +1,66 @@
+package feed
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/go-pkgz/lgr"
+)
+
+// JobDirPrefix is the name prefix of per-job temporary directories, see MakeJobDir
+const JobDirPrefix = ".job-"
+
+// leftoverPatterns match what a killed or failed job leaves in the files
+// location: yt-dlp partial downloads and thumbnails, subtitles, vot-cli and
+// apple podcasts partials, unfinished atomic writes and per-job directories
+var leftoverPatterns = []string{
+	"*.part", "*.part-Frag*", "*.ytdl", "*.webp", "*.part.mp3",
+	"sub_*.vtt", "sub_*.srt", "msub_*.vtt", "msub_*.srt",
+	".*.tmp", JobDirPrefix + "*",
+}
+
+// MakeJobDir creates a temporary directory for the intermediate files of one
+// job inside parent, so they stay on the same filesystem as the result and
+// can be removed together. The caller removes it once the job is done.
+func MakeJobDir(parent string) (string, error) {
+	if err := os.MkdirAll(parent, 0o750); err != nil {
+		return "", fmt.Errorf("failed to create directory %s: %w", parent, err)
+	}
+	dir, err := os.MkdirTemp(parent, JobDirPrefix+"*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create job directory: %w", err)
+	}
+	return dir, nil
+}
+
+// InJobDir tells if the file is inside a directory made by MakeJobDir
+func InJobDir(file string) bool {
+	return strings.HasPrefix(filepath.Base(filepath.Dir(file)), JobDirPrefix)
+}
+
+// SweepLeftovers removes the leftovers of jobs interrupted by a crash or a
+// restart from dir. Call it on startup, before any job runs there.
+func SweepLeftovers(dir string) (removed int, err error) {
+	seen := map[string]bool{}
+	for _, pattern := range leftoverPatterns {
+		matches, gerr := filepath.Glob(filepath.Join(dir, pattern))
+		if gerr != nil {
+			return removed, fmt.Errorf("bad leftover pattern %q: %w", pattern, gerr)
+		}
+		for _, m := range matches {
+			if seen[m] {
+				continue
+			}
+			seen[m] = true
+			if rerr := os.RemoveAll(m); rerr != nil {
+				log.Printf("[WARN] failed to remove leftover %s: %v", m, rerr)
+				continue
+			}
+			log.Printf("[DEBUG] removed leftover %s", m)
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,40 @@
+package feed
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSweepLeftovers(t *testing.T) {
+	dir := t.TempDir()
+	leftovers := []string{"abc.m4a.part", "abc.webp", "abc.m4a.ytdl", "vo_x_1.part.mp3", "sub_x_1.en.vtt",
+		"msub_x_1.ru.srt", ".feed.xml.123.tmp", "abc.m4a.part-Frag12"}
+	kept := []string{"abc.mp3", "vo_x_1.mp3", "notes.md"}
+	for _, f := range append(append([]string{}, leftovers...), kept...) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, f), []byte("x"), 0o600))
+	}
+	jobDir, err := MakeJobDir(dir)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(jobDir, "abc.webm"), []byte("x"), 0o600))
+	assert.True(t, InJobDir(filepath.Join(jobDir, "abc.webm")))
+	assert.False(t, InJobDir(filepath.Join(dir, "abc.mp3")))
+
+	removed, err := SweepLeftovers(dir)
+	require.NoError(t, err)
+	assert.Equal(t, len(leftovers)+1, removed)
+	for _, f := range leftovers {
+		assert.NoFileExists(t, filepath.Join(dir, f))
+	}
+	assert.NoDirExists(t, jobDir)
+	for _, f := range kept {
+		assert.FileExists(t, filepath.Join(dir, f))
+	}
+
+	removed, err = SweepLeftovers(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+}
@@ -0,0 +1,80 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Peaks is the waveform of an episode in the audiowaveform JSON format
+// (version 2, one channel, 8 bit), the one peaks.js and wavesurfer load:
+// min and max of each pixel, interleaved
+type Peaks struct {
+	Version         int    `json:"version"`
+	Channels        int    `json:"channels"`
+	SampleRate      int    `json:"sample_rate"`
+	SamplesPerPixel int    `json:"samples_per_pixel"`
+	Bits            int    `json:"bits"`
+	Length          int    `json:"length"`
+	Data            []int8 `json:"data"`
+}
+
+const (
+	peaksRate   = 4000 // Hz of the decoded audio, enough for the envelope of a waveform
+	peaksPixels = 1200 // min/max pairs of a waveform, whatever the episode length
+)
+
+// PeaksFile names the waveform of an episode file: ep.mp3 → ep.peaks.json
+func PeaksFile(file string) string {
+	return strings.TrimSuffix(file, filepath.Ext(file)) + ".peaks.json"
+}
+
+// MakePeaks reduces unsigned 8 bit mono samples (ffmpeg -f u8) at rate Hz
+// to at most pixels min/max pairs
+func MakePeaks(samples []byte, rate, pixels int) Peaks {
+	spp := max((len(samples)+pixels-1)/max(pixels, 1), 1)
+	res := Peaks{Version: 2, Channels: 1, SampleRate: rate, SamplesPerPixel: spp, Bits: 8, Data: []int8{}}
+	for start := 0; start < len(samples); start += spp {
+		lo, hi := int8(127), int8(-128)
+		for _, b := range samples[start:min(start+spp, len(samples))] {
+			v := int8(int(b) - 128)
+			lo, hi = min(lo, v), max(hi, v)
+		}
+		res.Data = append(res.Data, lo, hi)
+		res.Length++
+	}
+	return res
+}
+
+// WritePeaks decodes the audio at src with ffmpeg and writes its waveform to
+// dst, through a temp file so the web player never gets a partial one
+func WritePeaks(ctx context.Context, runner CommandRunner, src, dst string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+	out, stderr, err := runner.Run(ctx, "ffmpeg", "-nostdin", "-v", "error", "-i", src,
+		"-map", "0:a:0", "-ac", "1", "-ar", strconv.Itoa(peaksRate), "-f", "u8", "-")
+	if err != nil {
+		return fmt.Errorf("ffmpeg decode failed: %w, stderr: %s", err, strings.TrimSpace(string(stderr)))
+	}
+	if len(out) == 0 {
+		return fmt.Errorf("no audio in %s", src)
+	}
+	data, err := json.Marshal(MakePeaks(out, peaksRate, peaksPixels))
+	if err != nil {
+		return fmt.Errorf("can't marshal peaks: %w", err)
+	}
+	tmp := dst + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil { // nolint
+		return fmt.Errorf("can't write peaks: %w", err)
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("can't rename peaks: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/feed-master/app/youtube/feed/mocks"
+)
+
+func TestPeaksFile(t *testing.T) {
+	assert.Equal(t, "/srv/yt/ep.peaks.json", PeaksFile("/srv/yt/ep.mp3"))
+	assert.Equal(t, "/srv/yt/ep.x1.5.peaks.json", PeaksFile("/srv/yt/ep.x1.5.m4a"))
+}
+
+func TestMakePeaks(t *testing.T) {
+	// u8 samples: 128 is silence
+	samples := []byte{128, 138, 118, 128, 255, 0, 130}
+	p := MakePeaks(samples, 4000, 3)
+	assert.Equal(t, 2, p.Version)
+	assert.Equal(t, 1, p.Channels)
+	assert.Equal(t, 8, p.Bits)
+	assert.Equal(t, 4000, p.SampleRate)
+	assert.Equal(t, 3, p.SamplesPerPixel)
+	assert.Equal(t, 3, p.Length)
+	assert.Equal(t, []int8{-10, 10, -128, 127, 2, 2}, p.Data)
+
+	p = MakePeaks([]byte{128, 129}, 4000, 10)
+	assert.Equal(t, 1, p.SamplesPerPixel, "short audio, a pixel per sample")
+	assert.Equal(t, 2, p.Length)
+
+	data, err := json.Marshal(MakePeaks(nil, 4000, 10))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"data":[]`)
+}
+
+func TestWritePeaks(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "ep.mp3"), filepath.Join(dir, "ep.peaks.json")
+
+	t.Run("written", func(t *testing.T) {
+		runner := &mocks.CommandRunnerMock{
+			RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+				assert.Equal(t, "ffmpeg", name)
+				assert.Contains(t, args, src)
+				assert.Equal(t, []string{"-f", "u8", "-"}, args[len(args)-3:])
+				return []byte{128, 200, 56, 128}, nil, nil
+			},
+		}
+		require.NoError(t, WritePeaks(context.Background(), runner, src, dst))
+		data, err := os.ReadFile(dst) // nolint
+		require.NoError(t, err)
+		var p Peaks
+		require.NoError(t, json.Unmarshal(data, &p))
+		assert.Equal(t, 4, p.Length)
+		assert.Equal(t, []int8{0, 0, 72, 72, -72, -72, 0, 0}, p.Data)
+		_, err = os.Stat(dst + ".tmp")
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("ffmpeg fails", func(t *testing.T) {
+		runner := &mocks.CommandRunnerMock{
+			RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+				return nil, []byte("no such file"), errors.New("exit status 1")
+			},
+		}
+		err := WritePeaks(context.Background(), runner, src, filepath.Join(dir, "bad.peaks.json"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no such file")
+	})
+
+	t.Run("no audio", func(t *testing.T) {
+		runner := &mocks.CommandRunnerMock{
+			RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+				return nil, nil, nil
+			},
+		}
+		require.Error(t, WritePeaks(context.Background(), runner, src, filepath.Join(dir, "empty.peaks.json")))
+	})
+}
@@ -0,0 +1,23 @@
+package feed
+
+// Processing records how an entry was made, so the entries made with old
+// settings can be found and made again
+type Processing struct {
+	Method     string // vot-cli, youtube-dubbed, subtitles-tts, hybrid-dub or tts, "" = not recorded
+	Voice      string // TTS voice and rate, "" = no TTS
+	Translator string // translation backend, "" = not translated by the bot
+	Settings   string // short hash of the settings the result depends on
+}
+
+// Categories are the RSS item categories of the processing: the method and
+// the settings hash as "settings:1a2b3c4d", none when not recorded
+func (p Processing) Categories() []string {
+	var res []string
+	if p.Method != "" {
+		res = append(res, p.Method)
+	}
+	if p.Settings != "" {
+		res = append(res, "settings:"+p.Settings)
+	}
+	return res
+}
@@ -0,0 +1,46 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/umputun/feed-master/app/tools"
+)
+
+//go:generate moq -out mocks/command_runner.go -pkg mocks -skip-ensure -fmt goimports . CommandRunner
+
+// CommandRunner runs an external binary (yt-dlp, vot-cli) and returns its
+// stdout. stderr comes back separately: failures are classified from it.
+type CommandRunner interface {
+	Run(ctx context.Context, name string, args ...string) (stdout, stderr []byte, err error)
+}
+
+// ExecRunner is the CommandRunner backed by os/exec, binaries resolved by tools. Stderr, if set, gets a
+// copy of the child's stderr (used for log mirroring).
+type ExecRunner struct {
+	Stderr io.Writer
+}
+
+// Run executes the command and waits for it, after a free slot of its resource class
+func (r ExecRunner) Run(ctx context.Context, name string, args ...string) (stdout, stderr []byte, err error) {
+	release, err := tools.Acquire(ctx, tools.ClassOf(name))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+	cmd := tools.Command(ctx, name, args...)
+	var outBuf, errBuf bytes.Buffer
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+	if r.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(r.Stderr, &errBuf)
+	}
+	cleanup, err := tools.Prepare(ctx, cmd)
+	defer cleanup()
+	if err != nil {
+		return nil, nil, err
+	}
+	err = cmd.Run()
+	return outBuf.Bytes(), errBuf.Bytes(), err
+}
@@ -0,0 +1,139 @@
+package feed
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// the download template used to run with sh -c, now it is split into argv
+// words first and each word is rendered on its own. A value can't add
+// arguments or reach a shell, and shell syntax in the template is an error
+// instead of a silent surprise.
+
+// templateValueRe is what ID and FileName may consist of: YouTube ids
+// (which may start with "-") and the generated file names
+var templateValueRe = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// TemplateParams are the values of the download template
+type TemplateParams struct {
+	ID       string
+	FileName string
+}
+
+// RenderTemplate checks the download template and renders its command line
+func RenderTemplate(tmpl string, params TemplateParams) ([]string, error) {
+	if !templateValueRe.MatchString(params.ID) {
+		return nil, fmt.Errorf("invalid video id %q", params.ID)
+	}
+	if !templateValueRe.MatchString(params.FileName) || strings.HasPrefix(params.FileName, "-") {
+		return nil, fmt.Errorf("invalid file name %q", params.FileName)
+	}
+	words, err := splitTemplate(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("empty download template")
+	}
+	// {{.Filename}} is the name of FileName in templates of older configs
+	values := map[string]string{"ID": params.ID, "FileName": params.FileName, "Filename": params.FileName}
+	res := make([]string, 0, len(words))
+	for i, w := range words {
+		t, perr := template.New("youtube-dl").Option("missingkey=error").Parse(w)
+		if perr != nil {
+			return nil, fmt.Errorf("failed to parse template word %d %q: %w", i+1, w, perr)
+		}
+		var b bytes.Buffer
+		if eerr := t.Execute(&b, values); eerr != nil {
+			return nil, fmt.Errorf("failed to render template word %d %q: %w", i+1, w, eerr)
+		}
+		res = append(res, b.String())
+	}
+	return res, nil
+}
+
+// isYtDlp reports whether the command is yt-dlp, by name or path
+func isYtDlp(bin string) bool {
+	return filepath.Base(bin) == "yt-dlp"
+}
+
+// splitTemplate splits the template into words the way sh would, with '...',
+// "..." and backslash escapes, keeping {{ actions }} whole. Pipes, redirects,
+// command lists, substitutions and variables are refused, nothing expands them.
+func splitTemplate(s string) ([]string, error) {
+	var words []string
+	var cur strings.Builder
+	inWord := false
+	flush := func() {
+		if inWord {
+			words = append(words, cur.String())
+			cur.Reset()
+			inWord = false
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case strings.HasPrefix(s[i:], "{{"):
+			end := strings.Index(s[i:], "}}")
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed {{ at %d", i)
+			}
+			cur.WriteString(s[i : i+end+2])
+			inWord = true
+			i += end + 1
+		case c == ' ' || c == '\t' || c == '\n':
+			flush()
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed ' at %d", i)
+			}
+			cur.WriteString(s[i+1 : i+1+end])
+			inWord = true
+			i += end + 1
+		case c == '"':
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				switch {
+				case s[j] == '\\' && j+1 < len(s) && strings.IndexByte("\"\\$`", s[j+1]) >= 0:
+					j++
+					cur.WriteByte(s[j])
+				case strings.HasPrefix(s[j:], "{{"):
+					end := strings.Index(s[j:], "}}")
+					if end < 0 {
+						return nil, fmt.Errorf("unclosed {{ at %d", j)
+					}
+					cur.WriteString(s[j : j+end+2])
+					j += end + 1
+				case s[j] == '$' || s[j] == '`':
+					return nil, fmt.Errorf("shell expansion %q at %d isn't supported, the template runs without a shell", s[j], j)
+				default:
+					cur.WriteByte(s[j])
+				}
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unclosed \" at %d", i)
+			}
+			inWord = true
+			i = j
+		case c == '\\':
+			if i+1 < len(s) {
+				i++
+				cur.WriteByte(s[i])
+				inWord = true
+			}
+		case strings.IndexByte("|&;<>()$`", c) >= 0:
+			return nil, fmt.Errorf("shell syntax %q at %d isn't supported, the template runs without a shell", c, i)
+		default:
+			cur.WriteByte(c)
+			inWord = true
+		}
+	}
+	flush()
+	return words, nil
+}
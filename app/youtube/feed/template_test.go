@@ -0,0 +1,63 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	def := `yt-dlp --extract-audio --audio-format=mp3 --audio-quality=0 -f m4a/bestaudio --no-playlist ` +
+		`"https://www.youtube.com/watch?v={{.ID}}" --no-progress -o {{.FileName}} --match-filter "!is_live & availability=public"`
+	res, err := RenderTemplate(def, TemplateParams{ID: "-abc_123XYZ", FileName: "f00d"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"yt-dlp", "--extract-audio", "--audio-format=mp3", "--audio-quality=0", "-f", "m4a/bestaudio",
+		"--no-playlist", "https://www.youtube.com/watch?v=-abc_123XYZ", "--no-progress", "-o", "f00d",
+		"--match-filter", "!is_live & availability=public"}, res)
+
+	res, err = RenderTemplate(`yt-dlp -o '{{ .FileName }}.%(ext)s' a\ b "say \"{{ .ID }}\""`, TemplateParams{ID: "x", FileName: "f"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"yt-dlp", "-o", "f.%(ext)s", "a b", `say "x"`}, res)
+
+	res, err = RenderTemplate(`yt-dlp "https://www.youtube.com/watch?v={{.ID}}" -o {{.Filename}}`, TemplateParams{ID: "x", FileName: "f"})
+	require.NoError(t, err, "older configs name it Filename")
+	assert.Equal(t, []string{"yt-dlp", "https://www.youtube.com/watch?v=x", "-o", "f"}, res)
+
+	tbl := []struct {
+		tmpl   string
+		params TemplateParams
+		err    string
+	}{
+		{"yt-dlp {{.ID}}; rm -rf /", TemplateParams{ID: "x", FileName: "f"}, `shell syntax ';'`},
+		{"yt-dlp {{.ID}} | tee log", TemplateParams{ID: "x", FileName: "f"}, `shell syntax '|'`},
+		{"yt-dlp {{.ID}} > log", TemplateParams{ID: "x", FileName: "f"}, `shell syntax '>'`},
+		{"yt-dlp -o $HOME/{{.FileName}}", TemplateParams{ID: "x", FileName: "f"}, `shell syntax '$'`},
+		{`yt-dlp "$(id)"`, TemplateParams{ID: "x", FileName: "f"}, `shell expansion '$'`},
+		{`yt-dlp "{{.ID}}`, TemplateParams{ID: "x", FileName: "f"}, `unclosed "`},
+		{"yt-dlp {{.Nope}}", TemplateParams{ID: "x", FileName: "f"}, `map has no entry for key "Nope"`},
+		{"  ", TemplateParams{ID: "x", FileName: "f"}, "empty download template"},
+		{"yt-dlp {{.ID}}", TemplateParams{ID: "x; reboot", FileName: "f"}, `invalid video id`},
+		{"yt-dlp -o {{.FileName}}", TemplateParams{ID: "x", FileName: "--exec=sh"}, `invalid file name`},
+	}
+	for _, tt := range tbl {
+		_, err := RenderTemplate(tt.tmpl, tt.params)
+		require.Error(t, err, tt.tmpl)
+		assert.Contains(t, err.Error(), tt.err, tt.tmpl)
+	}
+}
+
+func TestDownloader_GetCookiesArg(t *testing.T) {
+	dir := t.TempDir()
+	fake := filepath.Join(dir, "yt-dlp")
+	require.NoError(t, os.WriteFile(fake, []byte("#!/bin/sh\necho \"$@\"\n"), 0o700)) //nolint:gosec // test script
+	lw := bytes.NewBuffer(nil)
+	d := NewDownloader(fake+" -x {{.ID}} -o {{.FileName}}.mp3", lw, lw, dir, "/etc/cookies.txt")
+	_, err := d.Get(context.Background(), "id1", "f1")
+	require.ErrorIs(t, err, ErrSkip)
+	assert.Equal(t, "--cookies /etc/cookies.txt -x id1 -o f1.mp3\n", lw.String(), "cookies as own arguments")
+}
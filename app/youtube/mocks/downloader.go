@@ -5,6 +5,7 @@ package mocks
 
 import (
 	"context"
+	"github.com/umputun/feed-master/app/youtube/feed"
 	"sync"
 )
 
@@ -14,7 +15,7 @@ import (
 //
 // 		// make and configure a mocked youtube.DownloaderService
 // 		mockedDownloaderService := &DownloaderServiceMock{
-// 			GetFunc: func(ctx context.Context, id string, fname string) (string, error) {
+// 			GetFunc: func(ctx context.Context, id string, fname string, opts ...feed.GetOption) (string, error) {
 // 				panic("mock out the Get method")
 // 			},
 // 		}
@@ -25,7 +26,7 @@ import (
 // 	}
 type DownloaderServiceMock struct {
 	// GetFunc mocks the Get method.
-	GetFunc func(ctx context.Context, id string, fname string) (string, error)
+	GetFunc func(ctx context.Context, id string, fname string, opts ...feed.GetOption) (string, error)
 
 	// calls tracks calls to the methods.
 	calls struct {
@@ -37,13 +38,15 @@ type DownloaderServiceMock struct {
 			ID string
 			// Fname is the fname argument value.
 			Fname string
+			// Opts is the opts argument value.
+			Opts []feed.GetOption
 		}
 	}
 	lockGet sync.RWMutex
 }
 
 // Get calls GetFunc.
-func (mock *DownloaderServiceMock) Get(ctx context.Context, id string, fname string) (string, error) {
+func (mock *DownloaderServiceMock) Get(ctx context.Context, id string, fname string, opts ...feed.GetOption) (string, error) {
 	if mock.GetFunc == nil {
 		panic("DownloaderServiceMock.GetFunc: method is nil but DownloaderService.Get was just called")
 	}
@@ -51,15 +54,17 @@ func (mock *DownloaderServiceMock) Get(ctx context.Context, id string, fname str
 		Ctx   context.Context
 		ID    string
 		Fname string
+		Opts  []feed.GetOption
 	}{
 		Ctx:   ctx,
 		ID:    id,
 		Fname: fname,
+		Opts:  opts,
 	}
 	mock.lockGet.Lock()
 	mock.calls.Get = append(mock.calls.Get, callInfo)
 	mock.lockGet.Unlock()
-	return mock.GetFunc(ctx, id, fname)
+	return mock.GetFunc(ctx, id, fname, opts...)
 }
 
 // GetCalls gets all the calls that were made to Get.
@@ -69,11 +74,13 @@ func (mock *DownloaderServiceMock) GetCalls() []struct {
 	Ctx   context.Context
 	ID    string
 	Fname string
+	Opts  []feed.GetOption
 } {
 	var calls []struct {
 		Ctx   context.Context
 		ID    string
 		Fname string
+		Opts  []feed.GetOption
 	}
 	mock.lockGet.RLock()
 	calls = mock.calls.Get
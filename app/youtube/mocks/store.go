@@ -31,6 +31,9 @@ import (
 // 			RemoveFunc: func(entry ytfeed.Entry) error {
 // 				panic("mock out the Remove method")
 // 			},
+// 			RemoveExpiredFunc: func(channelID string, before time.Time) ([]ytfeed.Entry, error) {
+// 				panic("mock out the RemoveExpired method")
+// 			},
 // 			RemoveOldFunc: func(channelID string, keep int) ([]string, error) {
 // 				panic("mock out the RemoveOld method")
 // 			},
@@ -65,6 +68,9 @@ type StoreServiceMock struct {
 	// RemoveFunc mocks the Remove method.
 	RemoveFunc func(entry ytfeed.Entry) error
 
+	// RemoveExpiredFunc mocks the RemoveExpired method.
+	RemoveExpiredFunc func(channelID string, before time.Time) ([]ytfeed.Entry, error)
+
 	// RemoveOldFunc mocks the RemoveOld method.
 	RemoveOldFunc func(channelID string, keep int) ([]string, error)
 
@@ -104,6 +110,13 @@ type StoreServiceMock struct {
 			// Entry is the entry argument value.
 			Entry ytfeed.Entry
 		}
+		// RemoveExpired holds details about calls to the RemoveExpired method.
+		RemoveExpired []struct {
+			// ChannelID is the channelID argument value.
+			ChannelID string
+			// Before is the before argument value.
+			Before time.Time
+		}
 		// RemoveOld holds details about calls to the RemoveOld method.
 		RemoveOld []struct {
 			// ChannelID is the channelID argument value.
@@ -132,6 +145,7 @@ type StoreServiceMock struct {
 	lockExist          sync.RWMutex
 	lockLoad           sync.RWMutex
 	lockRemove         sync.RWMutex
+	lockRemoveExpired  sync.RWMutex
 	lockRemoveOld      sync.RWMutex
 	lockResetProcessed sync.RWMutex
 	lockSave           sync.RWMutex
@@ -292,6 +306,42 @@ func (mock *StoreServiceMock) RemoveCalls() []struct {
 	return calls
 }
 
+// RemoveExpired calls RemoveExpiredFunc.
+func (mock *StoreServiceMock) RemoveExpired(channelID string, before time.Time) ([]ytfeed.Entry, error) {
+	if mock.RemoveExpiredFunc == nil {
+		panic("StoreServiceMock.RemoveExpiredFunc: method is nil but StoreService.RemoveExpired was just called")
+	}
+	callInfo := struct {
+		ChannelID string
+		Before    time.Time
+	}{
+		ChannelID: channelID,
+		Before:    before,
+	}
+	mock.lockRemoveExpired.Lock()
+	mock.calls.RemoveExpired = append(mock.calls.RemoveExpired, callInfo)
+	mock.lockRemoveExpired.Unlock()
+	return mock.RemoveExpiredFunc(channelID, before)
+}
+
+// RemoveExpiredCalls gets all the calls that were made to RemoveExpired.
+// Check the length with:
+//
+//	len(mockedStoreService.RemoveExpiredCalls())
+func (mock *StoreServiceMock) RemoveExpiredCalls() []struct {
+	ChannelID string
+	Before    time.Time
+} {
+	var calls []struct {
+		ChannelID string
+		Before    time.Time
+	}
+	mock.lockRemoveExpired.RLock()
+	calls = mock.calls.RemoveExpired
+	mock.lockRemoveExpired.RUnlock()
+	return calls
+}
+
 // RemoveOld calls RemoveOldFunc.
 func (mock *StoreServiceMock) RemoveOld(channelID string, keep int) ([]string, error) {
 	if mock.RemoveOldFunc == nil {
@@ -0,0 +1,150 @@
+package youtube
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// SkipRule cuts the preroll of the videos of a channel with ffmpeg: the first
+// seconds of every video and the chapters with the given titles. Chapters are
+// the "0:00 Intro" lines of the video description, YouTube takes them from there.
+type SkipRule struct {
+	Intro    time.Duration `yaml:"intro"`    // cut from the start of every video, e.g. 45s
+	Chapters []string      `yaml:"chapters"` // titles of the chapters to cut wherever they are, case-insensitive
+}
+
+// enabled checks the rule cuts anything
+func (r SkipRule) enabled() bool {
+	return r.Intro > 0 || len(r.Chapters) > 0
+}
+
+// chapter is a chapter of the video description
+type chapter struct {
+	start float64 // seconds
+	title string
+}
+
+// cutRange is a part of the audio to cut, seconds
+type cutRange struct {
+	start, end float64
+}
+
+// openEnd ends the last chapter of an audio of unknown length
+const openEnd = 1e9
+
+// chapterLineRe matches "0:00 Intro", "1:02:03 - Topic", "(12:30) Ad" lines
+var chapterLineRe = regexp.MustCompile(`^\s*\(?((?:\d{1,2}:)?\d{1,2}:\d{2})\)?\s*[-–—:|]?\s*(.+?)\s*$`)
+
+// descChapters returns the chapters of the video description, nil unless it
+// has two or more of them starting at 0:00 like YouTube wants
+func descChapters(description string) []chapter {
+	var res []chapter
+	for _, line := range strings.Split(html.UnescapeString(description), "\n") {
+		m := chapterLineRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		start := 0
+		for _, part := range strings.Split(m[1], ":") {
+			n, _ := strconv.Atoi(part)
+			start = start*60 + n
+		}
+		if len(res) > 0 && float64(start) <= res[len(res)-1].start {
+			continue // timestamps mentioned out of order aren't chapters
+		}
+		res = append(res, chapter{start: float64(start), title: m[2]})
+	}
+	if len(res) < 2 || res[0].start != 0 {
+		return nil
+	}
+	return res
+}
+
+// cutRanges returns the merged parts of the audio of length seconds (0 =
+// unknown) the rule cuts, in order
+func (r SkipRule) cutRanges(chapters []chapter, length float64) []cutRange {
+	end := length
+	if end <= 0 {
+		end = openEnd
+	}
+	var ranges []cutRange
+	if r.Intro > 0 {
+		ranges = append(ranges, cutRange{start: 0, end: min(r.Intro.Seconds(), end)})
+	}
+	for i, ch := range chapters {
+		if !slices.ContainsFunc(r.Chapters, func(t string) bool { return strings.EqualFold(strings.TrimSpace(t), ch.title) }) {
+			continue
+		}
+		chEnd := end
+		if i+1 < len(chapters) {
+			chEnd = min(chapters[i+1].start, end)
+		}
+		if ch.start < chEnd {
+			ranges = append(ranges, cutRange{start: ch.start, end: chEnd})
+		}
+	}
+	slices.SortFunc(ranges, func(a, b cutRange) int { return cmp.Compare(a.start, b.start) })
+	var res []cutRange
+	for _, rng := range ranges {
+		if n := len(res); n > 0 && rng.start <= res[n-1].end {
+			res[n-1].end = max(res[n-1].end, rng.end)
+			continue
+		}
+		res = append(res, rng)
+	}
+	return res
+}
+
+// skipPreroll cuts the parts of the downloaded file the rule of its channel
+// skips, in place. The file is left whole on failure and when nothing would be left.
+func (s *Service) skipPreroll(ctx context.Context, entry ytfeed.Entry, file string, rule SkipRule) error {
+	if !rule.enabled() {
+		return nil
+	}
+	length := float64(s.DurationService.File(file))
+	ranges := rule.cutRanges(descChapters(string(entry.Media.Description)), length)
+	if len(ranges) == 0 {
+		return nil
+	}
+	cut, terms := 0.0, make([]string, 0, len(ranges))
+	for _, rng := range ranges {
+		cut += rng.end - rng.start
+		terms = append(terms, fmt.Sprintf("between(t,%g,%g)", rng.start, rng.end))
+	}
+	if length > 0 && cut >= length {
+		return fmt.Errorf("the cut of %gs leaves nothing of %gs", cut, length)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+	ext := filepath.Ext(file)
+	// ffmpeg takes the format from the extension
+	tmp := strings.TrimSuffix(file, ext) + ".cut" + ext
+	defer os.Remove(tmp) //nolint:errcheck // gone after a successful rename
+	args := []string{"-nostdin", "-y", "-v", "error", "-i", file, "-map", "0:a",
+		"-af", "aselect='not(" + strings.Join(terms, "+") + ")',asetpts=N/SR/TB", "-map_metadata", "0"}
+	if strings.EqualFold(ext, ".mp3") {
+		args = append(args, "-c:a", "libmp3lame", "-q:a", "2", "-id3v2_version", "3")
+	}
+	if _, stderr, err := s.runner().Run(ctx, "ffmpeg", append(args, tmp)...); err != nil {
+		return fmt.Errorf("ffmpeg cut failed: %w, stderr: %s", err, strings.TrimSpace(string(stderr)))
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		return fmt.Errorf("rename cut file: %w", err)
+	}
+	log.Printf("[INFO] cut %gs of preroll from %s (%s)", cut, entry.VideoID, filepath.Base(file))
+	return nil
+}
@@ -0,0 +1,118 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	feedmocks "github.com/umputun/feed-master/app/youtube/feed/mocks"
+	"github.com/umputun/feed-master/app/youtube/mocks"
+)
+
+func TestDescChapters(t *testing.T) {
+	desc := "About this episode.\n\n0:00 Intro\n0:45 - Sponsor: Acme\n(2:10) Main topic\n1:02:03 | Q&amp;A\nsee 0:30 for the joke\n"
+	assert.Equal(t, []chapter{
+		{start: 0, title: "Intro"},
+		{start: 45, title: "Sponsor: Acme"},
+		{start: 130, title: "Main topic"},
+		{start: 3723, title: "Q&A"},
+	}, descChapters(desc))
+
+	assert.Nil(t, descChapters("no chapters here"))
+	assert.Nil(t, descChapters("0:00 Only one"))
+	assert.Nil(t, descChapters("1:00 Not from the start\n2:00 Later"), "YouTube chapters start at 0:00")
+}
+
+func TestSkipRule_cutRanges(t *testing.T) {
+	chapters := []chapter{{0, "Intro"}, {40, "Sponsor"}, {90, "Topic"}, {600, "Outro"}}
+	tbl := []struct {
+		name   string
+		rule   SkipRule
+		length float64
+		want   []cutRange
+	}{
+		{"intro only", SkipRule{Intro: 30 * time.Second}, 700, []cutRange{{0, 30}}},
+		{"chapters merged", SkipRule{Chapters: []string{"intro", " SPONSOR "}}, 700, []cutRange{{0, 90}}},
+		{"intro and chapters", SkipRule{Intro: 60 * time.Second, Chapters: []string{"Sponsor", "Outro"}}, 700,
+			[]cutRange{{0, 90}, {600, 700}}},
+		{"last chapter, unknown length", SkipRule{Chapters: []string{"Outro"}}, 0, []cutRange{{600, openEnd}}},
+		{"no match", SkipRule{Chapters: []string{"Ad"}}, 700, nil},
+		{"intro over the length", SkipRule{Intro: time.Hour}, 700, []cutRange{{0, 700}}},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.rule.cutRanges(chapters, tt.length))
+		})
+	}
+}
+
+func TestService_skipPreroll(t *testing.T) {
+	var ffmpegErr error
+	runner := &feedmocks.CommandRunnerMock{
+		RunFunc: func(_ context.Context, name string, args ...string) ([]byte, []byte, error) {
+			if ffmpegErr != nil {
+				return nil, []byte("boom"), ffmpegErr
+			}
+			return nil, nil, os.WriteFile(args[len(args)-1], []byte("cut"), 0o600)
+		},
+	}
+	svc := Service{
+		DurationService: &mocks.DurationServiceMock{FileFunc: func(string) int { return 700 }},
+		Runner:          runner,
+	}
+	entry := ytfeed.Entry{VideoID: "vid1"}
+	entry.Media.Description = template.HTML("0:00 Intro\n0:40 Sponsor\n1:30 Topic")
+	newFile := func() string {
+		file := filepath.Join(t.TempDir(), "ep.mp3")
+		require.NoError(t, os.WriteFile(file, []byte("whole"), 0o600))
+		return file
+	}
+	content := func(file string) string {
+		data, err := os.ReadFile(file) // nolint
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("cut", func(t *testing.T) {
+		file := newFile()
+		require.NoError(t, svc.skipPreroll(context.Background(), entry, file, SkipRule{Intro: 10 * time.Second, Chapters: []string{"sponsor"}}))
+		assert.Equal(t, "cut", content(file))
+		args := runner.RunCalls()[len(runner.RunCalls())-1].Args
+		assert.Contains(t, args, "aselect='not(between(t,0,10)+between(t,40,90))',asetpts=N/SR/TB")
+		assert.Contains(t, args, "libmp3lame")
+		_, err := os.Stat(filepath.Join(filepath.Dir(file), "ep.cut.mp3"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("ffmpeg fails", func(t *testing.T) {
+		file := newFile()
+		ffmpegErr = errors.New("exit status 1")
+		defer func() { ffmpegErr = nil }()
+		require.Error(t, svc.skipPreroll(context.Background(), entry, file, SkipRule{Intro: 10 * time.Second}))
+		assert.Equal(t, "whole", content(file))
+	})
+
+	t.Run("nothing left", func(t *testing.T) {
+		file := newFile()
+		calls := len(runner.RunCalls())
+		require.Error(t, svc.skipPreroll(context.Background(), entry, file, SkipRule{Intro: time.Hour}))
+		assert.Len(t, runner.RunCalls(), calls)
+		assert.Equal(t, "whole", content(file))
+	})
+
+	t.Run("no rule, no matching chapter", func(t *testing.T) {
+		file := newFile()
+		calls := len(runner.RunCalls())
+		require.NoError(t, svc.skipPreroll(context.Background(), entry, file, SkipRule{}))
+		require.NoError(t, svc.skipPreroll(context.Background(), entry, file, SkipRule{Chapters: []string{"Ad"}}))
+		assert.Len(t, runner.RunCalls(), calls)
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"os/exec"
 	"path"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -19,6 +20,7 @@ import (
 	"github.com/google/uuid"
 
 	rssfeed "github.com/umputun/feed-master/app/feed"
+	"github.com/umputun/feed-master/app/tools"
 	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
 )
 
@@ -40,20 +42,52 @@ type Service struct {
 	RootURL         string
 	SkipShorts      time.Duration
 
+	WebSub   *rssfeed.WebSub // hubs advertised in the feeds and pinged on new entries, nil = none
+	FeedsURL string          // public url of the /yt/rss feeds, base of the WebSub topics
+
+	Archive *ytfeed.Archive // yt-dlp download archive shared with other yt-dlp runs, nil = none
+
+	Waveform bool                 // new entries get the waveform of the web player
+	Runner   ytfeed.CommandRunner // ffmpeg of the waveforms and preroll cuts, nil = ytfeed.ExecRunner
+
 	YtDlpUpdDuration time.Duration
 	YtDlpUpdCommand  string
 }
 
 // FeedInfo contains channel or feed ID, readable name and other per-feed info
 type FeedInfo struct {
-	Name        string      `yaml:"name"`
-	ID          string      `yaml:"id"`
-	Type        ytfeed.Type `yaml:"type"`
-	Keep        int         `yaml:"keep"`
-	Language    string      `yaml:"lang"`
-	Filter      FeedFilter  `yaml:"filter"`
-	Description string      `yaml:"description"`
-	Image       string      `yaml:"image"`
+	Name        string        `yaml:"name"`
+	ID          string        `yaml:"id"`
+	Type        ytfeed.Type   `yaml:"type"`
+	Keep        int           `yaml:"keep"`
+	MaxAge      time.Duration `yaml:"max_age"` // entries published earlier are removed (pinned kept), 0 = no limit
+	Language    string        `yaml:"lang"`
+	Filter      FeedFilter    `yaml:"filter"`
+	Description string        `yaml:"description"`
+	Image       string        `yaml:"image"`
+	Podcast     PodcastMeta   `yaml:"podcast"`
+	Speed       float64       `yaml:"-"`            // sped-up feed: enclosures point to the entries' SpeedFile, 0 = original
+	Sort        string        `yaml:"-"`            // item order: SortPublished, SortAdded, "" = store order (published)
+	Limit       int           `yaml:"-"`            // at most that many items, on top of keep, 0 = no limit
+	Kinds       []ytfeed.Kind `yaml:"-"`            // only entries of these kinds, empty = all
+	KindEmoji   bool          `yaml:"-"`            // titles with the emoji of the entry kind in front, the bot feed's
+	Skip        SkipRule      `yaml:"skip"`         // preroll cut from the downloaded videos
+}
+
+// feed item orders for FeedInfo.Sort
+const (
+	SortPublished = "published" // newest publication date first
+	SortAdded     = "added"     // most recently added to the feed first
+)
+
+// PodcastMeta is the podcast directory metadata of a feed (Apple Podcasts,
+// Spotify), rendered into the RSS channel
+type PodcastMeta struct {
+	Author     string `yaml:"author"`   // itunes:author, default the author of the newest entry
+	Category   string `yaml:"category"` // Apple category, "Technology" or "Society & Culture > Documentary"
+	Explicit   bool   `yaml:"explicit"`
+	OwnerName  string `yaml:"owner_name"`
+	OwnerEmail string `yaml:"owner_email"` // directories send the ownership verification here
 }
 
 // FeedFilter contains filter criteria for the feed
@@ -64,7 +98,7 @@ type FeedFilter struct {
 
 // DownloaderService is an interface for downloading audio from youtube
 type DownloaderService interface {
-	Get(ctx context.Context, id string, fname string) (file string, err error)
+	Get(ctx context.Context, id string, fname string, opts ...ytfeed.GetOption) (file string, err error)
 }
 
 // ChannelService is an interface for getting channel entries, i.e. the list of videos
@@ -78,6 +112,7 @@ type StoreService interface {
 	Load(channelID string, maX int) ([]ytfeed.Entry, error)
 	Exist(entry ytfeed.Entry) (bool, error)
 	RemoveOld(channelID string, keep int) ([]string, error)
+	RemoveExpired(channelID string, before time.Time) ([]ytfeed.Entry, error)
 	Remove(entry ytfeed.Entry) error
 	SetProcessed(entry ytfeed.Entry) error
 	ResetProcessed(entry ytfeed.Entry) error
@@ -134,6 +169,20 @@ func (s *Service) RSSFeed(fi FeedInfo) (string, error) {
 	}
 	log.Printf("[DEBUG] RSSFeed got %d entries for channel=%s", len(entries), fi.ID)
 
+	switch fi.Sort {
+	case SortPublished:
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].Published.After(entries[j].Published) })
+	case SortAdded:
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].AddedAt().After(entries[j].AddedAt()) })
+	}
+	entries = ytfeed.Released(entries)
+	if len(fi.Kinds) > 0 {
+		entries = slices.DeleteFunc(entries, func(e ytfeed.Entry) bool { return !slices.Contains(fi.Kinds, e.EntryKind()) })
+	}
+	if fi.Limit > 0 && len(entries) > fi.Limit {
+		entries = entries[:fi.Limit]
+	}
+
 	if len(entries) == 0 {
 		return "", nil
 	}
@@ -141,20 +190,31 @@ func (s *Service) RSSFeed(fi FeedInfo) (string, error) {
 	items := []rssfeed.Item{}
 	for _, entry := range entries {
 
-		fileURL := s.RootURL + "/" + path.Base(entry.File)
+		file, size, dur, guid := entry.File, entry.FileSize, entry.Duration, entry.ItemGUID()
+		if fi.Speed > 0 {
+			guid += fmt.Sprintf("::x%g", fi.Speed)
+			// entries without a copy (made before it was enabled, ffmpeg failed) stay at normal speed
+			if entry.SpeedFile != "" {
+				file, size, dur = entry.SpeedFile, entry.SpeedFileSize, int(float64(dur)/fi.Speed)
+			}
+		}
+		fileURL := s.RootURL + "/" + path.Base(file)
 
-		var fileSize int
-		if fileInfo, fiErr := os.Stat(entry.File); fiErr != nil {
-			log.Printf("[WARN] failed to get file size for %s (%s %s): %v", entry.File, entry.VideoID, entry.Title, fiErr)
-		} else {
-			fileSize = int(fileInfo.Size())
+		// the recorded size survives the file moving to R2, stat covers older entries
+		fileSize := int(size)
+		if fileSize == 0 {
+			if fileInfo, fiErr := os.Stat(file); fiErr != nil {
+				log.Printf("[WARN] failed to get file size for %s (%s %s): %v", file, entry.VideoID, entry.Title, fiErr)
+			} else {
+				fileSize = int(fileInfo.Size())
+			}
 		}
 
 		duration := ""
-		if entry.Duration > 0 {
-			h := entry.Duration / 3600
-			m := (entry.Duration % 3600) / 60
-			sec := entry.Duration % 60
+		if dur > 0 {
+			h := dur / 3600
+			m := (dur % 3600) / 60
+			sec := dur % 60
 			if h > 0 {
 				duration = fmt.Sprintf("%d:%02d:%02d", h, m, sec)
 			} else {
@@ -167,20 +227,39 @@ func (s *Service) RSSFeed(fi FeedInfo) (string, error) {
 			itunesImage = &rssfeed.ItunesImg{URL: entry.Media.Thumbnail.URL}
 		}
 
+		// word timings and chapters match the original speed only
+		var transcript *rssfeed.PodcastTranscript
+		var chapters *rssfeed.PodcastChapters
+		if fi.Speed == 0 {
+			if entry.Transcript != "" {
+				transcript = &rssfeed.PodcastTranscript{URL: s.RootURL + "/" + path.Base(entry.Transcript), Type: "text/vtt"}
+			}
+			if entry.Chapters != "" {
+				chapters = &rssfeed.PodcastChapters{URL: s.RootURL + "/" + path.Base(entry.Chapters), Type: "application/json+chapters"}
+			}
+		}
+
+		title := entry.Title
+		if fi.KindEmoji {
+			title = entry.EmojiTitle()
+		}
 		items = append(items, rssfeed.Item{
-			Title:       entry.Title,
+			Title:       title,
+			Categories:  append([]string{string(entry.EntryKind())}, entry.Processing.Categories()...),
 			Description: entry.Media.Description,
 			Link:        entry.Link.Href,
 			PubDate:     entry.Published.In(time.UTC).Format(time.RFC1123Z),
-			GUID:        entry.ChannelID + "::" + entry.VideoID,
+			GUID:        guid,
 			Author:      entry.Author.Name,
 			Enclosure: rssfeed.Enclosure{
 				URL:    fileURL,
-				Type:   "audio/mpeg",
+				Type:   entry.MimeType(),
 				Length: fileSize,
 			},
 			Duration:    duration,
 			ItunesImage: itunesImage,
+			Transcript:  transcript,
+			Chapters:    chapters,
 			DT:          time.Now(),
 		})
 	}
@@ -204,6 +283,29 @@ func (s *Service) RSSFeed(fi FeedInfo) (string, error) {
 		Language:       fi.Language,
 		ItunesAuthor:   entries[0].Author.Name,
 		ItunesExplicit: "no",
+		ItunesCategory: rssfeed.NewItunesCategory(fi.Podcast.Category),
+	}
+	if fi.Podcast.Author != "" {
+		rss.ItunesAuthor = fi.Podcast.Author
+	}
+	if fi.Podcast.Explicit {
+		rss.ItunesExplicit = "yes"
+	}
+	if fi.Podcast.OwnerName != "" || fi.Podcast.OwnerEmail != "" {
+		rss.ItunesOwner = &rssfeed.ItunesOwner{Name: fi.Podcast.OwnerName, Email: fi.Podcast.OwnerEmail}
+	}
+
+	if s.FeedsURL != "" {
+		if rss.AtomLinks = s.WebSub.Links(s.FeedURL(fi)); rss.AtomLinks != nil {
+			rss.NsAtom = rssfeed.NsAtom
+		}
+	}
+
+	for _, item := range items {
+		if item.Transcript != nil || item.Chapters != nil {
+			rss.NsPodcast = "https://podcastindex.org/namespace/1.0"
+			break
+		}
 	}
 
 	// set image from config or from channel as rss thumbnail
@@ -232,6 +334,15 @@ func (s *Service) RSSFeed(fi FeedInfo) (string, error) {
 	return res, nil
 }
 
+// FeedURL returns the public url of the feed, its WebSub topic
+func (s *Service) FeedURL(fi FeedInfo) string {
+	res := strings.TrimSuffix(s.FeedsURL, "/") + "/" + fi.ID
+	if fi.Speed > 0 {
+		res += "?speed=1"
+	}
+	return res
+}
+
 // procChannels processes all channels, downloads audio, updates metadata and stores RSS
 func (s *Service) procChannels(ctx context.Context) error {
 
@@ -244,7 +355,7 @@ func (s *Service) procChannels(ctx context.Context) error {
 			continue
 		}
 		log.Printf("[INFO] got %d entries for %s, limit to %d", len(entries), feedInfo.Name, s.keep(feedInfo))
-		changed, processed := false, 0
+		changed, added, processed := false, false, 0
 		for i, entry := range entries {
 
 			// exit right away if context is done
@@ -317,6 +428,10 @@ func (s *Service) procChannels(ctx context.Context) error {
 				continue
 			}
 
+			if cutErr := s.skipPreroll(ctx, entry, file, feedInfo.Skip); cutErr != nil {
+				log.Printf("[WARN] failed to skip preroll of %s, keep it whole: %v", entry.VideoID, cutErr)
+			}
+
 			// update metadata
 			if tagsErr := s.updateMp3Tags(file, entry, feedInfo); tagsErr != nil {
 				log.Printf("[WARN] failed to update metadata for %s: %s", entry.VideoID, tagsErr)
@@ -334,6 +449,11 @@ func (s *Service) procChannels(ctx context.Context) error {
 			log.Printf("[INFO] downloaded %s (%s) to %s, size: %d, channel: %+v", entry.VideoID, entry.Title, file, fsize, feedInfo)
 
 			entry = s.update(entry, file, feedInfo)
+			entry.Kind = ytfeed.KindVideo
+			if err := entry.SetIntegrity(); err != nil {
+				log.Printf("[WARN] failed to checksum %s: %v", file, err)
+			}
+			entry.Peaks = s.makePeaks(ctx, file)
 
 			ok, saveErr := s.Store.Save(entry)
 			if saveErr != nil {
@@ -342,15 +462,23 @@ func (s *Service) procChannels(ctx context.Context) error {
 			if !ok {
 				log.Printf("[WARN] attempt to save dup entry %+v", entry)
 			}
-			changed = true
+			changed, added = true, true
 			if procErr := s.Store.SetProcessed(entry); procErr != nil {
 				log.Printf("[WARN] failed to set processed status for %s: %v", entry.VideoID, procErr)
 			}
+			if arcErr := s.Archive.Add(entry.VideoID); arcErr != nil {
+				log.Printf("[WARN] failed to add %s to download archive: %v", entry.VideoID, arcErr)
+			}
 			allStats.added++
 			log.Printf("[INFO] saved %s (%s) to %s, channel: %+v", entry.VideoID, entry.Title, file, feedInfo)
 		}
 		allStats.processed += processed
 
+		if expired := s.removeExpired(feedInfo, time.Now()); expired > 0 {
+			allStats.removed += expired
+			changed = true
+		}
+
 		if changed {
 			removed := s.removeOld(feedInfo)
 			allStats.removed += removed
@@ -365,6 +493,11 @@ func (s *Service) procChannels(ctx context.Context) error {
 				}
 			}
 		}
+		if added && s.FeedsURL != "" {
+			if err := s.WebSub.Publish(ctx, s.FeedURL(feedInfo)); err != nil {
+				log.Printf("[WARN] %v", err)
+			}
+		}
 	}
 
 	log.Printf("[INFO] all channels processed - channels: %d, %s, lifetime: %d, feed size: %d",
@@ -417,6 +550,16 @@ func (s *Service) isNew(entry ytfeed.Entry, fi FeedInfo) (ok bool, err error) {
 		return false, nil
 	}
 
+	// downloaded by another yt-dlp run sharing the archive
+	archived, arcErr := s.Archive.Has(entry.VideoID)
+	if arcErr != nil {
+		log.Printf("[WARN] can't check download archive for %s, %v", entry.VideoID, arcErr)
+	}
+	if archived {
+		log.Printf("[INFO] skip %s, in the download archive", entry.String())
+		return false, nil
+	}
+
 	return true, nil
 }
 
@@ -497,6 +640,52 @@ func (s *Service) removeOld(fi FeedInfo) int {
 	return removed
 }
 
+// removeExpired deletes entries older than the feed's MaxAge and their files
+func (s *Service) removeExpired(fi FeedInfo, now time.Time) int {
+	if fi.MaxAge <= 0 {
+		return 0
+	}
+	entries, err := s.Store.RemoveExpired(fi.ID, now.Add(-fi.MaxAge))
+	if err != nil {
+		log.Printf("[WARN] failed to remove expired entries for %s, %v", fi.ID, err)
+		return 0
+	}
+	for _, e := range entries {
+		for _, f := range []string{e.File, e.SpeedFile, e.Peaks} {
+			if f == "" {
+				continue
+			}
+			if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+				log.Printf("[WARN] failed to remove file %s: %v", f, err)
+			}
+		}
+		log.Printf("[INFO] expired %s (%s) for %s, published %s", e.VideoID, e.Title, fi.ID, e.Published.Format(time.DateOnly))
+	}
+	return len(entries)
+}
+
+// makePeaks writes the waveform of the file, "" if disabled or failed, the
+// web player goes without it
+func (s *Service) makePeaks(ctx context.Context, file string) string {
+	if !s.Waveform {
+		return ""
+	}
+	dst := ytfeed.PeaksFile(file)
+	if err := ytfeed.WritePeaks(ctx, s.runner(), file, dst); err != nil {
+		log.Printf("[WARN] no waveform of %s: %v", file, err)
+		return ""
+	}
+	return dst
+}
+
+// runner returns the injected CommandRunner or the exec-backed default
+func (s *Service) runner() ytfeed.CommandRunner {
+	if s.Runner != nil {
+		return s.Runner
+	}
+	return ytfeed.ExecRunner{}
+}
+
 func (s *Service) keep(fi FeedInfo) int {
 	keep := s.KeepPerChannel
 	if fi.Keep > 0 {
@@ -589,9 +778,14 @@ func (s *Service) updateMp3Tags(file string, entry ytfeed.Entry, fi FeedInfo) er
 func (s *Service) execYtdlpUpdate(ctx context.Context, updCmd string) {
 	log.Printf("[INFO] executing yt-dlp update command %s", s.YtDlpUpdCommand)
 	cmd := exec.CommandContext(ctx, "sh", "-c", updCmd) // nolint
-	cmd.Stdin = os.Stdin
 	cmd.Stdout = log.ToWriter(log.Default(), "DEBUG")
 	cmd.Stderr = log.ToWriter(log.Default(), "INFO")
+	cleanup, err := tools.Prepare(ctx, cmd)
+	defer cleanup()
+	if err != nil {
+		log.Printf("[WARN] can't run yt-dlp update command %s: %v", s.YtDlpUpdCommand, err)
+		return
+	}
 	if err := cmd.Run(); err != nil {
 		log.Printf("[WARN] failed to execute yt-dlp update command %s: %v", s.YtDlpUpdCommand, err)
 	}
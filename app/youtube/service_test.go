@@ -4,7 +4,9 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,7 +14,9 @@ import (
 	"github.com/stretchr/testify/require"
 	bolt "go.etcd.io/bbolt"
 
+	rssfeed "github.com/umputun/feed-master/app/feed"
 	ytfeed "github.com/umputun/feed-master/app/youtube/feed"
+	feedmocks "github.com/umputun/feed-master/app/youtube/feed/mocks"
 	"github.com/umputun/feed-master/app/youtube/store"
 
 	"github.com/umputun/feed-master/app/youtube/mocks"
@@ -33,7 +37,7 @@ func TestService_Do(t *testing.T) {
 		},
 	}
 	downloader := &mocks.DownloaderServiceMock{
-		GetFunc: func(_ context.Context, _ string, fname string) (string, error) {
+		GetFunc: func(_ context.Context, _ string, fname string, _ ...ytfeed.GetOption) (string, error) {
 			fpath := filepath.Join(tempDir, fname+".mp3")
 			_, err := os.Create(fpath) // nolint
 			require.NoError(t, err)
@@ -140,7 +144,7 @@ func TestService_DoIsAllowedFilter(t *testing.T) {
 		},
 	}
 	downloader := &mocks.DownloaderServiceMock{
-		GetFunc: func(_ context.Context, _ string, fname string) (string, error) {
+		GetFunc: func(_ context.Context, _ string, fname string, _ ...ytfeed.GetOption) (string, error) {
 			return "/tmp/" + fname + ".mp3", nil
 		},
 	}
@@ -222,12 +226,106 @@ func TestService_DoIsAllowedFilter(t *testing.T) {
 }
 
 // nolint:dupl // test if very similar to TestService_RSSFeed
+func TestService_DoDownloadArchive(t *testing.T) {
+	tempDir := t.TempDir()
+	archive := filepath.Join(tempDir, "archive.txt")
+	require.NoError(t, os.WriteFile(archive, []byte("youtube vid1\nvimeo vid2\n"), 0o600))
+
+	chans := &mocks.ChannelServiceMock{
+		GetFunc: func(_ context.Context, chanID string, _ ytfeed.Type) ([]ytfeed.Entry, error) {
+			return []ytfeed.Entry{
+				{ChannelID: chanID, VideoID: "vid1", Title: "title1", Published: time.Now()},
+				{ChannelID: chanID, VideoID: "vid2", Title: "title2", Published: time.Now()},
+			}, nil
+		},
+	}
+	downloader := &mocks.DownloaderServiceMock{
+		GetFunc: func(_ context.Context, _ string, fname string, _ ...ytfeed.GetOption) (string, error) {
+			fpath := filepath.Join(tempDir, fname+".mp3")
+			require.NoError(t, os.WriteFile(fpath, []byte("audio"), 0o600))
+			return fpath, nil
+		},
+	}
+	db, err := bolt.Open(filepath.Join(tempDir, "test.db"), 0o600, &bolt.Options{Timeout: time.Second})
+	require.NoError(t, err)
+	defer db.Close()
+	svc := Service{
+		Feeds:           []FeedInfo{{ID: "channel1", Name: "name1", Type: ytfeed.FTChannel}},
+		Downloader:      downloader,
+		ChannelService:  chans,
+		Store:           &store.BoltDB{DB: db},
+		CheckDuration:   time.Hour,
+		KeepPerChannel:  10,
+		DurationService: &mocks.DurationServiceMock{FileFunc: func(string) int { return 1234 }},
+		Archive:         &ytfeed.Archive{Path: archive},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, svc.Do(ctx), context.DeadlineExceeded)
+
+	require.Len(t, downloader.GetCalls(), 1, "vid1 is in the archive")
+	assert.Equal(t, "vid2", downloader.GetCalls()[0].ID)
+	data, err := os.ReadFile(archive) // nolint
+	require.NoError(t, err)
+	assert.Equal(t, "youtube vid1\nvimeo vid2\nyoutube vid2\n", string(data))
+}
+
+func TestService_DoWaveform(t *testing.T) {
+	tempDir := t.TempDir()
+	chans := &mocks.ChannelServiceMock{
+		GetFunc: func(_ context.Context, chanID string, _ ytfeed.Type) ([]ytfeed.Entry, error) {
+			return []ytfeed.Entry{{ChannelID: chanID, VideoID: "vid1", Title: "title1", Published: time.Now()}}, nil
+		},
+	}
+	downloader := &mocks.DownloaderServiceMock{
+		GetFunc: func(_ context.Context, _ string, fname string, _ ...ytfeed.GetOption) (string, error) {
+			fpath := filepath.Join(tempDir, fname+".mp3")
+			require.NoError(t, os.WriteFile(fpath, []byte("audio"), 0o600))
+			return fpath, nil
+		},
+	}
+	runner := &feedmocks.CommandRunnerMock{
+		RunFunc: func(_ context.Context, name string, _ ...string) ([]byte, []byte, error) {
+			assert.Equal(t, "ffmpeg", name)
+			return []byte{128, 160, 96, 128}, nil, nil
+		},
+	}
+	db, err := bolt.Open(filepath.Join(tempDir, "test.db"), 0o600, &bolt.Options{Timeout: time.Second})
+	require.NoError(t, err)
+	defer db.Close()
+	boltStore := &store.BoltDB{DB: db}
+	svc := Service{
+		Feeds:           []FeedInfo{{ID: "channel1", Name: "name1", Type: ytfeed.FTChannel}},
+		Downloader:      downloader,
+		ChannelService:  chans,
+		Store:           boltStore,
+		CheckDuration:   time.Hour,
+		KeepPerChannel:  10,
+		DurationService: &mocks.DurationServiceMock{FileFunc: func(string) int { return 1234 }},
+		Waveform:        true,
+		Runner:          runner,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, svc.Do(ctx), context.DeadlineExceeded)
+
+	entries, err := boltStore.Load("channel1", 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, ytfeed.PeaksFile(entries[0].File), entries[0].Peaks)
+	_, err = os.Stat(entries[0].Peaks)
+	require.NoError(t, err)
+	require.Len(t, runner.RunCalls(), 1)
+}
+
 func TestService_RSSFeed(t *testing.T) {
 	storeSvc := &mocks.StoreServiceMock{
 		LoadFunc: func(string, int) ([]ytfeed.Entry, error) {
 			res := []ytfeed.Entry{
 				{ChannelID: "channel1", VideoID: "vid1", Title: "title1", File: "/tmp/file1.mp3"},
-				{ChannelID: "channel1", VideoID: "vid2", Title: "title2", File: "/tmp/file2.mp3"},
+				{ChannelID: "channel1", VideoID: "vid2", Title: "title2", File: "/tmp/file2.m4a", FileSize: 12345, GUID: "old::vid2"},
 			}
 			res[0].Link.Href = "http://example.com/v1"
 			res[1].Link.Href = "http://example.com/v2"
@@ -255,8 +353,10 @@ func TestService_RSSFeed(t *testing.T) {
 	assert.Contains(t, res, `<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd" xmlns:media="http://search.yahoo.com/mrss/">`)
 	assert.Contains(t, res, `<enclosure url="http://localhost:8080/yt/file1.mp3"`)
 	assert.Contains(t, res, `<enclosure url="http://localhost:8080/yt/file1.mp3"`)
+	assert.Contains(t, res, `<enclosure url="http://localhost:8080/yt/file2.m4a" length="12345" type="audio/mp4">`,
+		"recorded size and type, file not on disk")
 	assert.Contains(t, res, `<guid>channel1::vid1</guid>`)
-	assert.Contains(t, res, `<guid>channel1::vid2</guid>`)
+	assert.Contains(t, res, `<guid>old::vid2</guid>`, "guid kept from the imported feed")
 	assert.NotContains(t, res, `<guid>channel1::vid3</guid>`, "skipped short video")
 	assert.Contains(t, res, `<link>http://example.com/v1</link>`)
 	assert.Contains(t, res, `<link>http://example.com/v2</link>`)
@@ -266,6 +366,192 @@ func TestService_RSSFeed(t *testing.T) {
 
 }
 
+func TestService_RSSFeedSortLimit(t *testing.T) {
+	now := time.Now()
+	storeSvc := &mocks.StoreServiceMock{
+		LoadFunc: func(string, int) ([]ytfeed.Entry, error) {
+			return []ytfeed.Entry{ // store order, by published
+				{ChannelID: "c", VideoID: "vid1", File: "/tmp/file1.mp3", Published: now, Added: now.Add(-2 * time.Hour)},
+				{ChannelID: "c", VideoID: "vid2", File: "/tmp/file2.mp3", Published: now.Add(-time.Hour), Added: now},
+				{ChannelID: "c", VideoID: "vid3", File: "/tmp/file3.mp3", Published: now.Add(-3 * time.Hour)},
+			}, nil
+		},
+	}
+	svc := Service{Store: storeSvc, RootURL: "http://localhost:8080/yt", KeepPerChannel: 10}
+
+	guids := func(res string) []string {
+		return regexp.MustCompile(`<guid>c::(vid\d)</guid>`).FindAllString(res, -1)
+	}
+
+	res, err := svc.RSSFeed(FeedInfo{ID: "c", Sort: SortPublished})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"<guid>c::vid1</guid>", "<guid>c::vid2</guid>", "<guid>c::vid3</guid>"}, guids(res))
+
+	res, err = svc.RSSFeed(FeedInfo{ID: "c", Sort: SortAdded})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"<guid>c::vid2</guid>", "<guid>c::vid1</guid>", "<guid>c::vid3</guid>"}, guids(res),
+		"entry without added time sorted by published")
+
+	res, err = svc.RSSFeed(FeedInfo{ID: "c", Sort: SortAdded, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"<guid>c::vid2</guid>", "<guid>c::vid1</guid>"}, guids(res))
+}
+
+func TestService_RSSFeedScheduled(t *testing.T) {
+	now := time.Now()
+	storeSvc := &mocks.StoreServiceMock{
+		LoadFunc: func(string, int) ([]ytfeed.Entry, error) {
+			return []ytfeed.Entry{
+				{ChannelID: "c", VideoID: "vid1", File: "/tmp/file1.mp3", Published: now, PublishAt: now.Add(time.Hour)},
+				{ChannelID: "c", VideoID: "vid2", File: "/tmp/file2.mp3", Published: now.Add(-time.Hour)},
+			}, nil
+		},
+	}
+	svc := Service{Store: storeSvc, RootURL: "http://localhost:8080/yt", KeepPerChannel: 10}
+
+	res, err := svc.RSSFeed(FeedInfo{ID: "c", Limit: 1})
+	require.NoError(t, err)
+	assert.NotContains(t, res, "<guid>c::vid1</guid>", "waits to be released")
+	assert.Contains(t, res, "<guid>c::vid2</guid>")
+}
+
+func TestService_RSSFeedKind(t *testing.T) {
+	now := time.Now()
+	storeSvc := &mocks.StoreServiceMock{
+		LoadFunc: func(string, int) ([]ytfeed.Entry, error) {
+			return []ytfeed.Entry{
+				{ChannelID: "c", VideoID: "art_1", Title: "📖 Some article", File: "/tmp/file1.mp3", Published: now},
+				{ChannelID: "c", VideoID: "vid2", Title: "A video", Kind: ytfeed.KindVideo, File: "/tmp/file2.mp3",
+					Published: now.Add(-time.Hour)},
+				{ChannelID: "c", VideoID: "vo_3", Title: "Subtitles", Kind: ytfeed.KindSubtitleTTS, File: "/tmp/file3.mp3",
+					Published: now.Add(-2 * time.Hour), Processing: ytfeed.Processing{Method: "subtitles-tts", Settings: "1a2b3c4d"}},
+			}, nil
+		},
+	}
+	svc := Service{Store: storeSvc, RootURL: "http://localhost:8080/yt", KeepPerChannel: 10}
+
+	res, err := svc.RSSFeed(FeedInfo{ID: "c"})
+	require.NoError(t, err)
+	assert.Contains(t, res, "<category>article</category>", "kind of a legacy entry from its id")
+	assert.Contains(t, res, "<category>video</category>")
+	assert.Contains(t, res, "<category>subtitle-tts</category>")
+	assert.Contains(t, res, "<category>subtitles-tts</category>", "processing method")
+	assert.Contains(t, res, "<category>settings:1a2b3c4d</category>")
+	assert.Contains(t, res, "<title>📖 Some article</title>")
+	assert.Contains(t, res, "<title>Subtitles</title>", "plain titles stored, no kind emoji by default")
+
+	res, err = svc.RSSFeed(FeedInfo{ID: "c", Kinds: []ytfeed.Kind{ytfeed.KindArticle, ytfeed.KindSubtitleTTS}, Limit: 1})
+	require.NoError(t, err)
+	assert.Contains(t, res, "<guid>c::art_1</guid>")
+	assert.NotContains(t, res, "<guid>c::vid2</guid>")
+	assert.NotContains(t, res, "<guid>c::vo_3</guid>", "limit applied after the kind filter")
+
+	res, err = svc.RSSFeed(FeedInfo{ID: "c", Kinds: []ytfeed.Kind{ytfeed.KindSubtitleTTS, ytfeed.KindVideo}, KindEmoji: true})
+	require.NoError(t, err)
+	assert.Contains(t, res, "<title>📝 Subtitles</title>", "emoji from the kind")
+	assert.Contains(t, res, "<title>📼 A video</title>")
+	assert.NotContains(t, res, "<guid>c::art_1</guid>")
+}
+
+func TestService_RSSFeedPodcastMeta(t *testing.T) {
+	storeSvc := &mocks.StoreServiceMock{
+		LoadFunc: func(string, int) ([]ytfeed.Entry, error) {
+			res := []ytfeed.Entry{{ChannelID: "bot", VideoID: "vid1", File: "/tmp/file1.mp3"}}
+			res[0].Author.Name = "some channel"
+			return res, nil
+		},
+	}
+	svc := Service{Store: storeSvc, RootURL: "http://localhost:8080/yt", KeepPerChannel: 10}
+
+	res, err := svc.RSSFeed(FeedInfo{ID: "bot", Name: "bot"})
+	require.NoError(t, err)
+	assert.Contains(t, res, `<itunes:author>some channel</itunes:author>`)
+	assert.Contains(t, res, `<itunes:explicit>no</itunes:explicit>`)
+	assert.NotContains(t, res, `itunes:category`)
+	assert.NotContains(t, res, `itunes:owner`)
+
+	res, err = svc.RSSFeed(FeedInfo{ID: "bot", Name: "bot", Language: "ru", Podcast: PodcastMeta{Author: "Me",
+		Category: "Technology > Tech News", Explicit: true, OwnerName: "Me", OwnerEmail: "me@example.com"}})
+	require.NoError(t, err)
+	assert.Contains(t, res, `<language>ru</language>`)
+	assert.Contains(t, res, `<itunes:author>Me</itunes:author>`)
+	assert.Contains(t, res, `<itunes:explicit>yes</itunes:explicit>`)
+	assert.Contains(t, res, `<itunes:category text="Technology">`+"\n"+`      <itunes:category text="Tech News"></itunes:category>`)
+	assert.Contains(t, res, `<itunes:email>me@example.com</itunes:email>`)
+}
+
+func TestService_RSSFeedWebSub(t *testing.T) {
+	storeSvc := &mocks.StoreServiceMock{
+		LoadFunc: func(string, int) ([]ytfeed.Entry, error) {
+			return []ytfeed.Entry{{ChannelID: "bot", VideoID: "vid1", File: "/tmp/file1.mp3"}}, nil
+		},
+	}
+	svc := Service{Store: storeSvc, RootURL: "http://localhost:8080/yt", KeepPerChannel: 10,
+		FeedsURL: "http://localhost:8080/yt/rss"}
+
+	res, err := svc.RSSFeed(FeedInfo{ID: "bot", Name: "bot"})
+	require.NoError(t, err)
+	assert.NotContains(t, res, "atom:link", "no hubs")
+
+	svc.WebSub = &rssfeed.WebSub{Hubs: []string{"https://hub.example.com/"}}
+	res, err = svc.RSSFeed(FeedInfo{ID: "bot", Name: "bot", Speed: 1.5})
+	require.NoError(t, err)
+	assert.Contains(t, res, `xmlns:atom="http://www.w3.org/2005/Atom"`)
+	assert.Contains(t, res, `<atom:link rel="hub" href="https://hub.example.com/"></atom:link>`)
+	assert.Contains(t, res, `<atom:link rel="self" href="http://localhost:8080/yt/rss/bot?speed=1" type="application/rss+xml"></atom:link>`)
+}
+
+func TestService_RSSFeedSpeed(t *testing.T) {
+	storeSvc := &mocks.StoreServiceMock{
+		LoadFunc: func(string, int) ([]ytfeed.Entry, error) {
+			return []ytfeed.Entry{
+				{ChannelID: "bot", VideoID: "vid1", File: "/tmp/file1.mp3", FileSize: 900, Duration: 90,
+					SpeedFile: "/tmp/file1.x1.5.mp3", SpeedFileSize: 600},
+				{ChannelID: "bot", VideoID: "vid2", File: "/tmp/file2.mp3", FileSize: 300, Duration: 30},
+			}, nil
+		},
+	}
+	svc := Service{Store: storeSvc, RootURL: "http://localhost:8080/yt", KeepPerChannel: 10}
+
+	res, err := svc.RSSFeed(FeedInfo{ID: "bot", Name: "bot (x1.5)", Speed: 1.5})
+	require.NoError(t, err)
+	assert.Contains(t, res, `<enclosure url="http://localhost:8080/yt/file1.x1.5.mp3" length="600" type="audio/mpeg">`)
+	assert.Contains(t, res, `<itunes:duration>1:00</itunes:duration>`, "duration of the sped-up copy")
+	assert.Contains(t, res, `<enclosure url="http://localhost:8080/yt/file2.mp3" length="300" type="audio/mpeg">`,
+		"no copy, original served")
+	assert.Contains(t, res, `<guid>bot::vid1::x1.5</guid>`, "items differ from the normal feed")
+
+	res, err = svc.RSSFeed(FeedInfo{ID: "bot", Name: "bot"})
+	require.NoError(t, err)
+	assert.Contains(t, res, `<enclosure url="http://localhost:8080/yt/file1.mp3" length="900" type="audio/mpeg">`)
+	assert.Contains(t, res, `<guid>bot::vid1</guid>`)
+}
+
+func TestService_RSSFeedTranscript(t *testing.T) {
+	storeSvc := &mocks.StoreServiceMock{
+		LoadFunc: func(string, int) ([]ytfeed.Entry, error) {
+			return []ytfeed.Entry{
+				{ChannelID: "bot", VideoID: "art1", File: "/tmp/art1.mp3", Transcript: "/tmp/art1.vtt",
+					Chapters: "/tmp/art1.chapters.json"},
+				{ChannelID: "bot", VideoID: "vid2", File: "/tmp/file2.mp3"},
+			}, nil
+		},
+	}
+	svc := Service{Store: storeSvc, RootURL: "http://localhost:8080/yt", KeepPerChannel: 10}
+
+	res, err := svc.RSSFeed(FeedInfo{ID: "bot", Name: "bot"})
+	require.NoError(t, err)
+	assert.Contains(t, res, `xmlns:podcast="https://podcastindex.org/namespace/1.0"`)
+	assert.Contains(t, res, `<podcast:transcript url="http://localhost:8080/yt/art1.vtt" type="text/vtt"></podcast:transcript>`)
+	assert.Equal(t, 1, strings.Count(res, "<podcast:transcript"))
+	assert.Contains(t, res,
+		`<podcast:chapters url="http://localhost:8080/yt/art1.chapters.json" type="application/json+chapters"></podcast:chapters>`)
+
+	res, err = svc.RSSFeed(FeedInfo{ID: "bot", Name: "bot (x1.5)", Speed: 1.5})
+	require.NoError(t, err)
+	assert.NotContains(t, res, "podcast:", "timings and chapters don't match the sped-up copy")
+}
+
 // nolint:dupl // test if very similar to TestService_RSSFeed
 func TestService_RSSFeedPlayList(t *testing.T) {
 	storeSvc := &mocks.StoreServiceMock{
@@ -397,6 +683,28 @@ func TestService_totalEntriesToKeep(t *testing.T) {
 	assert.Equal(t, 15, svc.totalEntriesToKeep())
 }
 
+func TestService_removeExpired(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "old.mp3")
+	require.NoError(t, os.WriteFile(file, []byte("audio"), 0o600))
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	storeSvc := &mocks.StoreServiceMock{
+		RemoveExpiredFunc: func(channelID string, before time.Time) ([]ytfeed.Entry, error) {
+			return []ytfeed.Entry{{ChannelID: channelID, VideoID: "old", File: file}}, nil
+		},
+	}
+	svc := Service{Store: storeSvc}
+
+	assert.Equal(t, 0, svc.removeExpired(FeedInfo{ID: "chan1"}, now), "no max_age")
+	assert.Empty(t, storeSvc.RemoveExpiredCalls())
+
+	assert.Equal(t, 1, svc.removeExpired(FeedInfo{ID: "chan1", MaxAge: 24 * time.Hour}, now))
+	require.Len(t, storeSvc.RemoveExpiredCalls(), 1)
+	assert.Equal(t, now.Add(-24*time.Hour), storeSvc.RemoveExpiredCalls()[0].Before)
+	_, err := os.Stat(file)
+	assert.True(t, os.IsNotExist(err))
+}
+
 func TestService_countAllEntries(t *testing.T) {
 
 	storeSvc := &mocks.StoreServiceMock{
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	bolt "go.etcd.io/bbolt"
+)
+
+var downloadsBkt = []byte("downloads")
+
+// DownloadStat counts the downloads of an episode file, by podcast client
+type DownloadStat struct {
+	File    string         `json:"file"` // base name of the enclosure
+	Count   int            `json:"count"`
+	Clients map[string]int `json:"clients,omitempty"` // e.g. "Apple Podcasts": 3
+	Last    time.Time      `json:"last"`
+}
+
+// RecordDownload counts a download of the file by the client
+func (s *BoltDB) RecordDownload(file, client string) error {
+	return s.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(downloadsBkt)
+		if err != nil {
+			return fmt.Errorf("create bucket %s: %w", downloadsBkt, err)
+		}
+		st := DownloadStat{File: file}
+		if v := bucket.Get([]byte(file)); v != nil {
+			if jerr := json.Unmarshal(v, &st); jerr != nil {
+				log.Printf("[WARN] download stats of %s reset, %v", file, jerr)
+				st = DownloadStat{File: file}
+			}
+		}
+		st.Count++
+		if client != "" {
+			if st.Clients == nil {
+				st.Clients = map[string]int{}
+			}
+			st.Clients[client]++
+		}
+		st.Last = time.Now()
+		data, err := json.Marshal(st)
+		if err != nil {
+			return fmt.Errorf("marshal download stats of %s: %w", file, err)
+		}
+		return bucket.Put([]byte(file), data)
+	})
+}
+
+// LoadDownloads returns the download counts of all files ever downloaded
+func (s *BoltDB) LoadDownloads() (res []DownloadStat, err error) {
+	err = s.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(downloadsBkt)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var st DownloadStat
+			if jerr := json.Unmarshal(v, &st); jerr != nil {
+				log.Printf("[WARN] failed to unmarshal download stats of %s: %v", string(k), jerr)
+				return nil
+			}
+			res = append(res, st)
+			return nil
+		})
+	})
+	return res, err
+}
@@ -0,0 +1,37 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestStore_Downloads(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "dl.db"), 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	defer db.Close()
+	s := BoltDB{DB: db}
+
+	res, err := s.LoadDownloads()
+	require.NoError(t, err)
+	assert.Empty(t, res)
+
+	require.NoError(t, s.RecordDownload("a.mp3", "Overcast"))
+	require.NoError(t, s.RecordDownload("a.mp3", "Overcast"))
+	require.NoError(t, s.RecordDownload("a.mp3", ""))
+	require.NoError(t, s.RecordDownload("b.mp3", "Apple Podcasts"))
+
+	res, err = s.LoadDownloads()
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	assert.Equal(t, "a.mp3", res[0].File)
+	assert.Equal(t, 3, res[0].Count)
+	assert.Equal(t, map[string]int{"Overcast": 2}, res[0].Clients)
+	assert.WithinDuration(t, time.Now(), res[0].Last, time.Minute)
+	assert.Equal(t, 1, res[1].Count)
+	assert.Equal(t, map[string]int{"Apple Podcasts": 1}, res[1].Clients)
+}
@@ -0,0 +1,82 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	bolt "go.etcd.io/bbolt"
+)
+
+var glossaryBkt = []byte("glossary")
+
+// GlossaryTerm is a term the translation keeps as is or renders the given way
+type GlossaryTerm struct {
+	Term        string    `json:"term"`
+	Translation string    `json:"translation,omitempty"` // empty = keep the term untranslated
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// glossaryKey makes the key of a term, case-insensitive
+func glossaryKey(term string) []byte {
+	return []byte(strings.ToLower(strings.TrimSpace(term)))
+}
+
+// SaveGlossaryTerm creates or replaces a glossary term, terms differing in case only are the same term
+func (s *BoltDB) SaveGlossaryTerm(term GlossaryTerm) error {
+	if strings.TrimSpace(term.Term) == "" {
+		return errors.New("glossary term is empty")
+	}
+	return s.Update(func(tx *bolt.Tx) error {
+		bucket, e := tx.CreateBucketIfNotExists(glossaryBkt)
+		if e != nil {
+			return fmt.Errorf("create bucket %s: %w", glossaryBkt, e)
+		}
+		jdata, jerr := json.Marshal(&term)
+		if jerr != nil {
+			return fmt.Errorf("marshal glossary term %s: %w", term.Term, jerr)
+		}
+		return bucket.Put(glossaryKey(term.Term), jdata)
+	})
+}
+
+// LoadGlossary returns all glossary terms in alphabetical order
+func (s *BoltDB) LoadGlossary() (terms []GlossaryTerm, err error) {
+	err = s.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(glossaryBkt)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var item GlossaryTerm
+			if jerr := json.Unmarshal(v, &item); jerr != nil {
+				log.Printf("[WARN] glossary term unmarshal %s: %v", string(k), jerr)
+				return nil
+			}
+			terms = append(terms, item)
+			return nil
+		})
+	})
+	sort.Slice(terms, func(i, j int) bool { return strings.ToLower(terms[i].Term) < strings.ToLower(terms[j].Term) })
+	return terms, err
+}
+
+// DeleteGlossaryTerm removes the term (any case), reports whether it was there
+func (s *BoltDB) DeleteGlossaryTerm(term string) (found bool, err error) {
+	err = s.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(glossaryBkt)
+		if bucket == nil {
+			return nil
+		}
+		key := glossaryKey(term)
+		if found = bucket.Get(key) != nil; !found {
+			return nil
+		}
+		return bucket.Delete(key)
+	})
+	return found, err
+}
@@ -0,0 +1,45 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestStore_Glossary(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	defer db.Close()
+	s := BoltDB{DB: db}
+
+	terms, err := s.LoadGlossary()
+	require.NoError(t, err)
+	assert.Empty(t, terms)
+	found, err := s.DeleteGlossaryTerm("nope")
+	require.NoError(t, err, "no bucket yet")
+	assert.False(t, found)
+
+	require.NoError(t, s.SaveGlossaryTerm(GlossaryTerm{Term: "pull request", Translation: "пулл-реквест"}))
+	require.NoError(t, s.SaveGlossaryTerm(GlossaryTerm{Term: "Kubernetes"}))
+	require.NoError(t, s.SaveGlossaryTerm(GlossaryTerm{Term: "Pull Request", Translation: "PR"}), "same term, replaced")
+	require.Error(t, s.SaveGlossaryTerm(GlossaryTerm{Term: " "}), "term required")
+
+	terms, err = s.LoadGlossary()
+	require.NoError(t, err)
+	require.Len(t, terms, 2)
+	assert.Equal(t, "Kubernetes", terms[0].Term, "alphabetical, case-insensitive")
+	assert.Empty(t, terms[0].Translation)
+	assert.Equal(t, GlossaryTerm{Term: "Pull Request", Translation: "PR"}, terms[1])
+
+	found, err = s.DeleteGlossaryTerm("KUBERNETES")
+	require.NoError(t, err)
+	assert.True(t, found)
+	terms, err = s.LoadGlossary()
+	require.NoError(t, err)
+	require.Len(t, terms, 1)
+	assert.Equal(t, "Pull Request", terms[0].Term)
+}
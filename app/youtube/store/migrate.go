@@ -0,0 +1,200 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	log "github.com/go-pkgz/lgr"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/umputun/feed-master/app/youtube/feed"
+)
+
+// EntrySchemaVersion is the layout version of feed.Entry records written by
+// this build. Bump it together with a new entryMigrations step whenever a
+// change to feed.Entry needs existing records rewritten.
+const EntrySchemaVersion = 3
+
+// entryMigration upgrades a stored entry from Version-1 to Version. It works on
+// raw JSON fields, not on feed.Entry, so it can still see fields the current
+// struct has renamed or dropped.
+type entryMigration struct {
+	Version int
+	Name    string
+	Apply   func(rec map[string]json.RawMessage) error
+	BotFeed bool // only entries of the bot feed are changed, the rest just get the version
+}
+
+// entryMigrations must be sorted by Version, one step per version. The kind and
+// title steps read the ids and title emoji of the bot pipelines, on the YouTube
+// channels they would take a video titled "📖 ..." for an article.
+var entryMigrations = []entryMigration{
+	// records written before versioning have no SchemaVersion, the layout is unchanged
+	{Version: 1, Name: "initial versioned schema", Apply: func(map[string]json.RawMessage) error { return nil }},
+	{Version: 2, Name: "entry kind", Apply: migrateEntryKind, BotFeed: true},
+	{Version: 3, Name: "plain titles", Apply: migratePlainTitle, BotFeed: true},
+}
+
+// migrateEntryKind records the kind the id prefix and title emoji used to tell
+func migrateEntryKind(rec map[string]json.RawMessage) error {
+	if _, ok := rec["Kind"]; ok {
+		return nil
+	}
+	var videoID, title string
+	if raw, ok := rec["VideoID"]; ok {
+		if err := json.Unmarshal(raw, &videoID); err != nil {
+			return fmt.Errorf("bad video id %s: %w", string(raw), err)
+		}
+	}
+	if raw, ok := rec["Title"]; ok {
+		if err := json.Unmarshal(raw, &title); err != nil {
+			return fmt.Errorf("bad title %s: %w", string(raw), err)
+		}
+	}
+	kind, err := json.Marshal(feed.LegacyKind(videoID, title))
+	if err != nil {
+		return fmt.Errorf("marshal kind: %w", err)
+	}
+	rec["Kind"] = kind
+	return nil
+}
+
+// migratePlainTitle drops the kind emoji from the title, the feed renders it
+// from the kind. An official dub keeps its 🎬 as the processing method.
+func migratePlainTitle(rec map[string]json.RawMessage) error {
+	var entry feed.Entry
+	for name, dst := range map[string]any{"VideoID": &entry.VideoID, "Title": &entry.Title, "Kind": &entry.Kind,
+		"Processing": &entry.Processing} {
+		if raw, ok := rec[name]; ok {
+			if err := json.Unmarshal(raw, dst); err != nil {
+				return fmt.Errorf("bad %s %s: %w", strings.ToLower(name), string(raw), err)
+			}
+		}
+	}
+	plain := entry.PlainTitle()
+	if plain == entry.Title {
+		return nil
+	}
+	if strings.HasPrefix(entry.Title, "🎬") && entry.Processing.Method == "" {
+		entry.Processing.Method = "youtube-dubbed"
+		processing, err := json.Marshal(entry.Processing)
+		if err != nil {
+			return fmt.Errorf("marshal processing: %w", err)
+		}
+		rec["Processing"] = processing
+	}
+	title, err := json.Marshal(plain)
+	if err != nil {
+		return fmt.Errorf("marshal title: %w", err)
+	}
+	rec["Title"] = title
+	return nil
+}
+
+// Migrate upgrades entries of all configured channels to EntrySchemaVersion.
+// Called once on open, before the store is used. Records written by a newer
+// build are left untouched. Returns the number of upgraded records.
+func (s *BoltDB) Migrate() (int, error) {
+	return s.migrateEntries(entryMigrations, EntrySchemaVersion)
+}
+
+func (s *BoltDB) migrateEntries(migrations []entryMigration, target int) (count int, err error) {
+	for _, channel := range s.Channels {
+		n, merr := s.migrateBucket(channel, migrations, target, channel == s.BotFeed)
+		count += n
+		if merr != nil {
+			return count, fmt.Errorf("migrate %s: %w", channel, merr)
+		}
+		if n > 0 {
+			log.Printf("[INFO] migrated %d entries of %s to schema v%d", n, channel, target)
+		}
+	}
+	return count, nil
+}
+
+// migrateBucket upgrades one channel bucket in a single transaction,
+// so a failed step leaves the bucket as it was
+func (s *BoltDB) migrateBucket(channel string, migrations []entryMigration, target int, botFeed bool) (count int, err error) {
+	err = s.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(channel))
+		if bucket == nil {
+			return nil
+		}
+		// collect first: mutating a bucket while iterating its cursor is unsafe
+		updates := map[string][]byte{}
+		if ferr := bucket.ForEach(func(k, v []byte) error {
+			if v == nil {
+				return nil // nested bucket
+			}
+			upgraded, changed, uerr := upgradeEntry(v, migrations, target, botFeed)
+			if uerr != nil {
+				return fmt.Errorf("entry %s: %w", string(k), uerr)
+			}
+			if changed {
+				updates[string(k)] = upgraded
+			}
+			return nil
+		}); ferr != nil {
+			return ferr
+		}
+		for k, v := range updates {
+			if perr := bucket.Put([]byte(k), v); perr != nil {
+				return fmt.Errorf("save migrated entry %s: %w", k, perr)
+			}
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// upgradeEntry applies migrations newer than the record version, up to target,
+// the bot feed ones only to an entry of the bot feed. Undecodable records are
+// skipped (Load skips them too), not failed.
+func upgradeEntry(data []byte, migrations []entryMigration, target int, botFeed bool) (res []byte, changed bool, err error) {
+	rec := map[string]json.RawMessage{}
+	if jerr := json.Unmarshal(data, &rec); jerr != nil {
+		log.Printf("[WARN] skip undecodable entry %q: %v", string(data), jerr)
+		return nil, false, nil
+	}
+
+	version := 0
+	if raw, ok := rec["SchemaVersion"]; ok {
+		if jerr := json.Unmarshal(raw, &version); jerr != nil {
+			return nil, false, fmt.Errorf("bad schema version %s: %w", string(raw), jerr)
+		}
+	}
+	if version >= target {
+		return nil, false, nil
+	}
+
+	for _, m := range migrations {
+		if m.Version <= version || m.Version > target {
+			continue
+		}
+		if m.Version != version+1 {
+			return nil, false, fmt.Errorf("no migration from v%d to v%d", version, version+1)
+		}
+		if m.BotFeed && !botFeed {
+			version = m.Version
+			continue
+		}
+		if aerr := m.Apply(rec); aerr != nil {
+			return nil, false, fmt.Errorf("migration v%d %q: %w", m.Version, m.Name, aerr)
+		}
+		version = m.Version
+	}
+	if version != target {
+		return nil, false, fmt.Errorf("no migration from v%d to v%d", version, target)
+	}
+
+	rec["SchemaVersion"] = json.RawMessage(fmt.Sprintf("%d", version))
+	if res, err = json.Marshal(rec); err != nil {
+		return nil, false, fmt.Errorf("marshal migrated entry: %w", err)
+	}
+	return res, true, nil
+}
@@ -0,0 +1,178 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/umputun/feed-master/app/youtube/feed"
+)
+
+func prepMigrateStore(t *testing.T) *BoltDB {
+	t.Helper()
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "migrate.db"), 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return &BoltDB{DB: db, Channels: []string{"chan1", "chan2"}, BotFeed: "chan1"}
+}
+
+// putRaw writes a record bypassing Save, as an older build would have
+func putRaw(t *testing.T, s *BoltDB, bucket, key, data string) {
+	t.Helper()
+	require.NoError(t, s.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), []byte(data))
+	}))
+}
+
+func getRaw(t *testing.T, s *BoltDB, bucket, key string) map[string]any {
+	t.Helper()
+	res := map[string]any{}
+	require.NoError(t, s.View(func(tx *bolt.Tx) error {
+		return json.Unmarshal(tx.Bucket([]byte(bucket)).Get([]byte(key)), &res)
+	}))
+	return res
+}
+
+func TestStore_SaveSetsSchemaVersion(t *testing.T) {
+	s := prepMigrateStore(t)
+	entry := feed.Entry{ChannelID: "chan1", VideoID: "vid1", Title: "title1", Duration: 42,
+		Published: time.Date(2022, time.March, 21, 16, 45, 22, 0, time.UTC),
+		Added:     time.Date(2022, time.March, 21, 17, 0, 0, 0, time.UTC)}
+	entry.Media.Description = "desc"
+
+	_, err := s.Save(entry)
+	require.NoError(t, err)
+	res, err := s.Load("chan1", 0)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, EntrySchemaVersion, res[0].SchemaVersion)
+
+	// round trip keeps everything else intact
+	res[0].SchemaVersion = 0
+	assert.Equal(t, entry, res[0])
+
+	n, err := s.Migrate()
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "fresh records need no migration")
+}
+
+func TestStore_MigrateLegacyEntries(t *testing.T) {
+	s := prepMigrateStore(t)
+	putRaw(t, s, "chan1", "1-a", `{"ChannelID":"chan1","VideoID":"a","Title":"legacy","Duration":10,"File":"/f/a.mp3"}`)
+	putRaw(t, s, "chan1", "2-b", `{"ChannelID":"chan1","VideoID":"art_b","Title":"📖 v1","SchemaVersion":1}`)
+	putRaw(t, s, "chan1", "3-e", `{"ChannelID":"chan1","VideoID":"vo_e","Title":"🎬 dubbed","Kind":"voiceover","SchemaVersion":2}`)
+	putRaw(t, s, "chan2", "3-c", `not json`)
+	putRaw(t, s, "other", "4-d", `{"VideoID":"d"}`) // not a configured channel, e.g. feed-master buckets
+
+	n, err := s.Migrate()
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	res, err := s.Load("chan1", 0)
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+	assert.Equal(t, "legacy", res[2].Title)
+	assert.Equal(t, 10, res[2].Duration)
+	assert.Equal(t, "/f/a.mp3", res[2].File)
+	assert.Equal(t, EntrySchemaVersion, res[2].SchemaVersion)
+	assert.Equal(t, feed.KindVideo, res[2].Kind)
+	assert.Equal(t, feed.KindArticle, res[1].Kind, "v1 record upgraded, kind from the id")
+	assert.Equal(t, EntrySchemaVersion, res[1].SchemaVersion)
+	assert.Equal(t, "v1", res[1].Title, "kind emoji dropped")
+	assert.Equal(t, feed.KindVoiceover, res[0].Kind)
+	assert.Equal(t, "dubbed", res[0].Title)
+	assert.Equal(t, "🎬 dubbed", res[0].EmojiTitle(), "official dub kept as the method")
+	assert.NotContains(t, getRaw(t, s, "other", "4-d"), "SchemaVersion")
+
+	n, err = s.Migrate()
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "second run is a no-op")
+}
+
+func TestStore_MigrateKeepsChannelEntries(t *testing.T) {
+	s := prepMigrateStore(t)
+	putRaw(t, s, "chan2", "1-a", `{"ChannelID":"chan2","VideoID":"a","Title":"🎬 Трейлер"}`)
+	putRaw(t, s, "chan2", "2-b", `{"ChannelID":"chan2","VideoID":"b","Title":"📖 Книга недели","SchemaVersion":2,"Kind":"video"}`)
+
+	n, err := s.Migrate()
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	res, err := s.Load("chan2", 0)
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	assert.Equal(t, "📖 Книга недели", res[0].Title)
+	assert.Equal(t, feed.KindVideo, res[0].EntryKind())
+	assert.Equal(t, "🎬 Трейлер", res[1].Title, "title of a channel video kept")
+	assert.Empty(t, res[1].Kind, "no kind guessed from the title")
+	assert.Equal(t, feed.KindVideo, res[1].EntryKind())
+	assert.Empty(t, res[1].Processing.Method)
+	assert.Equal(t, EntrySchemaVersion, res[1].SchemaVersion)
+}
+
+func TestStore_MigrateSteps(t *testing.T) {
+	s := prepMigrateStore(t)
+	putRaw(t, s, "chan1", "1-a", `{"VideoID":"a","Title":"v0","Length":"90"}`)
+	putRaw(t, s, "chan1", "2-b", `{"VideoID":"b","Title":"v1","Length":"30","SchemaVersion":1}`)
+	putRaw(t, s, "chan2", "3-c", `{"VideoID":"c","Title":"v3","SchemaVersion":3}`)
+
+	var applied []string
+	migrations := []entryMigration{
+		{Version: 1, Name: "noop", Apply: func(map[string]json.RawMessage) error {
+			applied = append(applied, "v1")
+			return nil
+		}},
+		{Version: 2, Name: "rename Length to Duration", Apply: func(rec map[string]json.RawMessage) error {
+			applied = append(applied, "v2")
+			var l string
+			if err := json.Unmarshal(rec["Length"], &l); err != nil {
+				return err
+			}
+			rec["Duration"] = json.RawMessage(l)
+			delete(rec, "Length")
+			return nil
+		}},
+	}
+
+	n, err := s.migrateEntries(migrations, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.ElementsMatch(t, []string{"v1", "v2", "v2"}, applied)
+
+	a := getRaw(t, s, "chan1", "1-a")
+	assert.Equal(t, 90.0, a["Duration"])
+	assert.NotContains(t, a, "Length")
+	assert.Equal(t, 2.0, a["SchemaVersion"])
+	assert.Equal(t, 30.0, getRaw(t, s, "chan1", "2-b")["Duration"])
+	assert.Equal(t, 3.0, getRaw(t, s, "chan2", "3-c")["SchemaVersion"], "newer records untouched")
+}
+
+func TestStore_MigrateFailureKeepsBucket(t *testing.T) {
+	s := prepMigrateStore(t)
+	putRaw(t, s, "chan1", "1-a", `{"VideoID":"a","Title":"ok"}`)
+	putRaw(t, s, "chan1", "2-b", `{"VideoID":"b","Title":"bad"}`)
+
+	migrations := []entryMigration{{Version: 1, Name: "picky", Apply: func(rec map[string]json.RawMessage) error {
+		if string(rec["Title"]) == `"bad"` {
+			return errors.New("can't upgrade")
+		}
+		return nil
+	}}}
+	_, err := s.migrateEntries(migrations, 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't upgrade")
+	assert.NotContains(t, getRaw(t, s, "chan1", "1-a"), "SchemaVersion", "transaction rolled back")
+
+	_, err = s.migrateEntries(migrations[:0], 1)
+	require.Error(t, err, "gap in migrations is an error")
+}
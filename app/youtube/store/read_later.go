@@ -0,0 +1,39 @@
+package store
+
+import (
+	"fmt"
+	"strconv"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var readLaterBkt = []byte("read_later_failures")
+
+// AddReadLaterFailure counts a failed attempt to voice the item of the
+// read-later service and returns the attempts so far
+func (s *BoltDB) AddReadLaterFailure(service, itemID string) (n int, err error) {
+	err = s.Update(func(tx *bolt.Tx) error {
+		bucket, e := tx.CreateBucketIfNotExists(readLaterBkt)
+		if e != nil {
+			return fmt.Errorf("create bucket %s: %w", readLaterBkt, e)
+		}
+		key := []byte(service + "/" + itemID)
+		if v := bucket.Get(key); v != nil {
+			n, _ = strconv.Atoi(string(v))
+		}
+		n++
+		return bucket.Put(key, []byte(strconv.Itoa(n)))
+	})
+	return n, err
+}
+
+// ClearReadLaterFailures forgets the failed attempts of the item, once it's voiced or given up on
+func (s *BoltDB) ClearReadLaterFailures(service, itemID string) error {
+	return s.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(readLaterBkt)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.Delete([]byte(service + "/" + itemID))
+	})
+}
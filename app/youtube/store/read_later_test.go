@@ -0,0 +1,34 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestStore_ReadLaterFailures(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	defer db.Close()
+	s := BoltDB{DB: db}
+
+	require.NoError(t, s.ClearReadLaterFailures("Wallabag", "1"), "nothing to clear")
+	n, err := s.AddReadLaterFailure("Wallabag", "1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	n, err = s.AddReadLaterFailure("Wallabag", "1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	n, err = s.AddReadLaterFailure("Pocket", "1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n, "counted by service")
+
+	require.NoError(t, s.ClearReadLaterFailures("Wallabag", "1"))
+	n, err = s.AddReadLaterFailure("Wallabag", "1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var revisionsBkt = []byte("feed_revisions")
+
+// Revision identifies the state of a channel's entries: Rev grows with every
+// change to them, Modified is the time of the last one. Feed endpoints make
+// ETag and Last-Modified of it.
+type Revision struct {
+	Rev      uint64    `json:"rev"`
+	Modified time.Time `json:"modified"`
+}
+
+// Revision returns the current revision of the channel, zero for a channel
+// not changed since revisions were recorded
+func (s *BoltDB) Revision(channelID string) (res Revision, err error) {
+	err = s.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(revisionsBkt)
+		if bucket == nil {
+			return nil
+		}
+		v := bucket.Get([]byte(channelID))
+		if v == nil {
+			return nil
+		}
+		if jerr := json.Unmarshal(v, &res); jerr != nil {
+			return fmt.Errorf("unmarshal revision of %s: %w", channelID, jerr)
+		}
+		return nil
+	})
+	return res, err
+}
+
+// bumpRevision records a change to the channel's entries, inside the
+// transaction making it
+func bumpRevision(tx *bolt.Tx, channelID string) error {
+	bucket, err := tx.CreateBucketIfNotExists(revisionsBkt)
+	if err != nil {
+		return fmt.Errorf("create bucket %s: %w", revisionsBkt, err)
+	}
+	var rev Revision
+	if v := bucket.Get([]byte(channelID)); v != nil {
+		_ = json.Unmarshal(v, &rev) // a broken record restarts the count
+	}
+	rev.Rev++
+	rev.Modified = time.Now()
+	data, err := json.Marshal(rev)
+	if err != nil {
+		return fmt.Errorf("marshal revision of %s: %w", channelID, err)
+	}
+	return bucket.Put([]byte(channelID), data)
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/umputun/feed-master/app/youtube/feed"
+)
+
+func TestStore_Revision(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "rev.db"), 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	defer db.Close()
+	s := BoltDB{DB: db}
+
+	rev, err := s.Revision("chan1")
+	require.NoError(t, err)
+	assert.Equal(t, Revision{}, rev, "nothing written yet")
+
+	now := time.Now()
+	e1 := feed.Entry{ChannelID: "chan1", VideoID: "vid1", Published: now.Add(-48 * time.Hour)}
+	e2 := feed.Entry{ChannelID: "chan1", VideoID: "vid2", Published: now}
+	expect := func(want uint64, msg string) {
+		t.Helper()
+		rev, err := s.Revision("chan1")
+		require.NoError(t, err)
+		assert.Equal(t, want, rev.Rev, msg)
+		assert.WithinDuration(t, time.Now(), rev.Modified, time.Minute)
+	}
+
+	_, err = s.Save(e1)
+	require.NoError(t, err)
+	expect(1, "saved")
+	_, err = s.Save(e1)
+	require.NoError(t, err)
+	expect(1, "duplicate save changes nothing")
+	_, err = s.Save(e2)
+	require.NoError(t, err)
+	expect(2, "saved")
+
+	e2.Title = "new title"
+	require.NoError(t, s.UpdateEntry(e2))
+	expect(3, "updated")
+
+	_, err = s.RemoveExpired("chan1", now.Add(-72*time.Hour))
+	require.NoError(t, err)
+	expect(3, "nothing expired")
+	_, err = s.RemoveExpired("chan1", now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	expect(4, "vid1 expired")
+
+	_, err = s.RemoveOld("chan1", 1)
+	require.NoError(t, err)
+	expect(4, "nothing over keep")
+
+	require.NoError(t, s.Remove(e2))
+	expect(5, "removed")
+
+	rev, err = s.Revision("chan2")
+	require.NoError(t, err)
+	assert.Zero(t, rev.Rev, "per channel")
+}
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	bolt "go.etcd.io/bbolt"
+)
+
+var rssSubsBkt = []byte("rss_subs")
+
+// maxRSSSeen caps the remembered items of a subscription, well above what a
+// feed shows at once, so an item dropping out of the window isn't re-voiced
+const maxRSSSeen = 500
+
+// RSSSubscription is a news feed whose new posts get voiced into the bot feed
+type RSSSubscription struct {
+	ID        string    `json:"id"` // sha1 of the feed URL
+	URL       string    `json:"url"`
+	Title     string    `json:"title,omitempty"`
+	MinChars  int       `json:"min_chars,omitempty"` // posts with shorter text are skipped
+	Preset    string    `json:"preset,omitempty"`    // processing preset of the posts
+	Seen      []string  `json:"seen,omitempty"`      // handled item GUIDs/links, oldest first
+	CreatedAt time.Time `json:"created_at"`
+	CheckedAt time.Time `json:"checked_at,omitempty"`
+}
+
+// RSSSubscriptionID makes the subscription key of a feed URL
+func RSSSubscriptionID(feedURL string) string {
+	return fmt.Sprintf("%x", sha1.Sum([]byte(feedURL)))
+}
+
+// IsSeen reports whether the item with the given key was handled already
+func (s *RSSSubscription) IsSeen(key string) bool {
+	for _, k := range s.Seen {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkSeen remembers the item key, dropping the oldest keys over the cap
+func (s *RSSSubscription) MarkSeen(key string) {
+	if s.IsSeen(key) {
+		return
+	}
+	s.Seen = append(s.Seen, key)
+	if len(s.Seen) > maxRSSSeen {
+		s.Seen = s.Seen[len(s.Seen)-maxRSSSeen:]
+	}
+}
+
+// SaveRSSSubscription creates or updates a subscription keyed by its ID
+func (s *BoltDB) SaveRSSSubscription(sub RSSSubscription) error {
+	if sub.ID == "" {
+		return errors.New("rss subscription id is empty")
+	}
+	return s.Update(func(tx *bolt.Tx) error {
+		bucket, e := tx.CreateBucketIfNotExists(rssSubsBkt)
+		if e != nil {
+			return fmt.Errorf("create bucket %s: %w", rssSubsBkt, e)
+		}
+		jdata, jerr := json.Marshal(&sub)
+		if jerr != nil {
+			return fmt.Errorf("marshal rss subscription %s: %w", sub.URL, jerr)
+		}
+		return bucket.Put([]byte(sub.ID), jdata)
+	})
+}
+
+// LoadRSSSubscriptions returns all subscriptions, oldest first
+func (s *BoltDB) LoadRSSSubscriptions() (subs []RSSSubscription, err error) {
+	err = s.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(rssSubsBkt)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var item RSSSubscription
+			if jerr := json.Unmarshal(v, &item); jerr != nil {
+				log.Printf("[WARN] rss subscription unmarshal %s: %v", string(k), jerr)
+				return nil
+			}
+			subs = append(subs, item)
+			return nil
+		})
+	})
+	sort.Slice(subs, func(i, j int) bool { return subs[i].CreatedAt.Before(subs[j].CreatedAt) })
+	return subs, err
+}
+
+// DeleteRSSSubscription removes the subscription, no error if it's not there
+func (s *BoltDB) DeleteRSSSubscription(id string) error {
+	return s.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(rssSubsBkt)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.Delete([]byte(id))
+	})
+}
+
+// MarkRSSSeen records handled items of a subscription and the check time.
+// Read-modify-write in one transaction: a subscription removed while its
+// posts were being voiced stays removed.
+func (s *BoltDB) MarkRSSSeen(id string, keys ...string) error {
+	return s.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(rssSubsBkt)
+		if bucket == nil {
+			return nil
+		}
+		v := bucket.Get([]byte(id))
+		if v == nil {
+			return nil
+		}
+		var sub RSSSubscription
+		if err := json.Unmarshal(v, &sub); err != nil {
+			return fmt.Errorf("unmarshal rss subscription %s: %w", id, err)
+		}
+		for _, k := range keys {
+			sub.MarkSeen(k)
+		}
+		sub.CheckedAt = time.Now().UTC()
+		jdata, err := json.Marshal(&sub)
+		if err != nil {
+			return fmt.Errorf("marshal rss subscription %s: %w", sub.URL, err)
+		}
+		return bucket.Put([]byte(id), jdata)
+	})
+}
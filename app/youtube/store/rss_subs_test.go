@@ -0,0 +1,60 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestStore_RSSSubscriptions(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	defer db.Close()
+	s := BoltDB{DB: db}
+
+	subs, err := s.LoadRSSSubscriptions()
+	require.NoError(t, err)
+	assert.Empty(t, subs)
+	require.NoError(t, s.MarkRSSSeen("nope", "x"), "no bucket yet")
+
+	now := time.Now().UTC()
+	a := RSSSubscription{ID: RSSSubscriptionID("https://a.com/rss"), URL: "https://a.com/rss", MinChars: 500,
+		Seen: []string{"a1"}, CreatedAt: now}
+	b := RSSSubscription{ID: RSSSubscriptionID("https://b.com/rss"), URL: "https://b.com/rss", CreatedAt: now.Add(-time.Hour)}
+	require.NoError(t, s.SaveRSSSubscription(a))
+	require.NoError(t, s.SaveRSSSubscription(b))
+	require.Error(t, s.SaveRSSSubscription(RSSSubscription{URL: "x"}), "id required")
+
+	subs, err = s.LoadRSSSubscriptions()
+	require.NoError(t, err)
+	require.Len(t, subs, 2)
+	assert.Equal(t, b.URL, subs[0].URL, "oldest first")
+	assert.Equal(t, 500, subs[1].MinChars)
+
+	require.NoError(t, s.MarkRSSSeen(a.ID, "a2", "a1"))
+	subs, err = s.LoadRSSSubscriptions()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a1", "a2"}, subs[1].Seen)
+	assert.False(t, subs[1].CheckedAt.IsZero())
+
+	require.NoError(t, s.DeleteRSSSubscription(a.ID))
+	require.NoError(t, s.MarkRSSSeen(a.ID, "a3"), "removed subscription not resurrected")
+	subs, err = s.LoadRSSSubscriptions()
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, b.URL, subs[0].URL)
+}
+
+func TestRSSSubscription_MarkSeenCap(t *testing.T) {
+	var sub RSSSubscription
+	for i := 0; i < maxRSSSeen+10; i++ {
+		sub.MarkSeen(time.Unix(int64(i), 0).String())
+	}
+	assert.Len(t, sub.Seen, maxRSSSeen)
+	assert.True(t, sub.IsSeen(time.Unix(int64(maxRSSSeen+9), 0).String()))
+	assert.False(t, sub.IsSeen(time.Unix(0, 0).String()), "oldest dropped")
+}
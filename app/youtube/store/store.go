@@ -68,6 +68,7 @@ type HistoryEntry struct {
 type BoltDB struct {
 	*bolt.DB
 	Channels []string // the list of configured channels ids
+	BotFeed  string   // bucket of the telegram bot feed, "" = no bot
 }
 
 // Save to bolt, skip if found
@@ -88,6 +89,10 @@ func (s *BoltDB) Save(entry feed.Entry) (bool, error) {
 			return nil
 		}
 
+		entry.SchemaVersion = EntrySchemaVersion
+		if entry.Added.IsZero() {
+			entry.Added = time.Now()
+		}
 		jdata, jerr := json.Marshal(&entry)
 		if jerr != nil {
 			return fmt.Errorf("marshal entry %s: %w", entry.VideoID, jerr)
@@ -100,8 +105,12 @@ func (s *BoltDB) Save(entry feed.Entry) (bool, error) {
 			return fmt.Errorf("save entry %s: %w", entry.VideoID, e)
 		}
 
+		// a trashed copy of the entry shares its files, purging it would delete the new ones
+		if e = dropTrashed(tx, entry); e != nil {
+			return e
+		}
 		created = true
-		return e
+		return bumpRevision(tx, entry.ChannelID)
 	})
 
 	return created, err
@@ -180,7 +189,7 @@ func (s *BoltDB) Last() (feed.Entry, error) {
 }
 
 // RemoveOld removes old entries from bolt and returns the list of removed entry.File
-// the caller should delete the files
+// (and entry.SpeedFile, entry.Peaks and entry.Sources, if any), the caller should delete the files
 // important: this method returns the list of removed keys even if there was an error
 func (s *BoltDB) RemoveOld(channelID string, keep int) ([]string, error) {
 	deleted := 0
@@ -207,15 +216,67 @@ func (s *BoltDB) RemoveOld(channelID string, keep int) ([]string, error) {
 					continue
 				}
 				res = append(res, item.File)
+				if item.SpeedFile != "" {
+					res = append(res, item.SpeedFile)
+				}
+				if item.Peaks != "" {
+					res = append(res, item.Peaks)
+				}
+				res = append(res, item.Sources...)
 				deleted++
 			}
 		}
+		if deleted > 0 {
+			if err := bumpRevision(tx, channelID); err != nil {
+				errs = multierror.Append(errs, err)
+			}
+		}
 		return errs.ErrorOrNil()
 	})
 
 	return res, err
 }
 
+// RemoveExpired removes entries published before the cutoff, except pinned
+// and scheduled ones, and returns them, the caller should delete their files. A channel
+// without a bucket has nothing to expire.
+func (s *BoltDB) RemoveExpired(channelID string, before time.Time) ([]feed.Entry, error) {
+	var res []feed.Entry
+	err := s.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(channelID))
+		if bucket == nil {
+			return nil
+		}
+		var keys [][]byte
+		c := bucket.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var item feed.Entry
+			if err := json.Unmarshal(v, &item); err != nil {
+				log.Printf("[WARN] failed to unmarshal, %v", err)
+				continue
+			}
+			if item.Pinned || !item.PublishAt.IsZero() || !item.Published.Before(before) {
+				continue
+			}
+			keys = append(keys, append([]byte(nil), k...))
+			res = append(res, item)
+		}
+		for _, k := range keys {
+			if err := bucket.Delete(k); err != nil {
+				return fmt.Errorf("failed to delete %s: %w", string(k), err)
+			}
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		return bumpRevision(tx, channelID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 // Remove entry matched by vidoID and channelID
 func (s *BoltDB) Remove(entry feed.Entry) error {
 
@@ -236,7 +297,7 @@ func (s *BoltDB) Remove(entry feed.Entry) error {
 					return fmt.Errorf("failed to delete %s (%s): %w", string(k), item.VideoID, err)
 				}
 				log.Printf("[INFO] delete %s - %s", string(k), item.String())
-				return nil
+				return bumpRevision(tx, entry.ChannelID)
 			}
 		}
 		return nil
@@ -261,6 +322,7 @@ func (s *BoltDB) UpdateEntry(entry feed.Entry) error {
 				continue
 			}
 			if item.VideoID == entry.VideoID {
+				entry.SchemaVersion = EntrySchemaVersion
 				jdata, jerr := json.Marshal(&entry)
 				if jerr != nil {
 					return fmt.Errorf("marshal entry %s: %w", entry.VideoID, jerr)
@@ -269,8 +331,49 @@ func (s *BoltDB) UpdateEntry(entry feed.Entry) error {
 					return fmt.Errorf("failed to update %s (%s): %w", string(k), item.VideoID, err)
 				}
 				log.Printf("[INFO] update %s - %s", string(k), entry.String())
-				return nil
+				return bumpRevision(tx, entry.ChannelID)
+			}
+		}
+		return fmt.Errorf("entry %s not found in %s", entry.VideoID, entry.ChannelID)
+	})
+}
+
+// ReplaceEntry replaces the stored entry matched by VideoID and ChannelID
+// like UpdateEntry, but moves it to the key of its publication time, so a
+// changed Published reorders the feed. Returns an error if the entry is not found.
+func (s *BoltDB) ReplaceEntry(entry feed.Entry) error {
+	key, keyErr := s.key(entry)
+	if keyErr != nil {
+		return fmt.Errorf("failed to generate key for %s: %w", entry.VideoID, keyErr)
+	}
+	return s.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(entry.ChannelID))
+		if bucket == nil {
+			return fmt.Errorf("no bucket for %s", entry.ChannelID)
+		}
+		c := bucket.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var item feed.Entry
+			if err := json.Unmarshal(v, &item); err != nil {
+				log.Printf("[WARN] failed to unmarshal, %v", err)
+				continue
+			}
+			if item.VideoID != entry.VideoID {
+				continue
+			}
+			if err := bucket.Delete(k); err != nil {
+				return fmt.Errorf("failed to delete %s (%s): %w", string(k), item.VideoID, err)
+			}
+			entry.SchemaVersion = EntrySchemaVersion
+			jdata, jerr := json.Marshal(&entry)
+			if jerr != nil {
+				return fmt.Errorf("marshal entry %s: %w", entry.VideoID, jerr)
+			}
+			if err := bucket.Put(key, jdata); err != nil {
+				return fmt.Errorf("failed to save %s (%s): %w", string(key), entry.VideoID, err)
 			}
+			log.Printf("[INFO] replace %s -> %s - %s", string(k), string(key), entry.String())
+			return bumpRevision(tx, entry.ChannelID)
 		}
 		return fmt.Errorf("entry %s not found in %s", entry.VideoID, entry.ChannelID)
 	})
@@ -42,6 +42,7 @@ func TestStore_SaveAndLoad(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 1, len(res))
 	assert.Equal(t, "vid1", res[0].VideoID)
+	assert.WithinDuration(t, time.Now(), res[0].Added, time.Minute, "added time stamped on save")
 
 	entry2 := feed.Entry{
 		ChannelID: "chan1",
@@ -152,6 +153,8 @@ func TestBoldDB_RemoveOld(t *testing.T) {
 			Title:     "title1",
 			Published: time.Date(2022, time.March, 21, 16, 45, 22, 0, time.UTC),
 			File:      "f1",
+			SpeedFile: "f1.x1.5",
+			Peaks:     "f1.peaks.json",
 		}
 		created, e := s.Save(entry)
 		require.NoError(t, e)
@@ -184,7 +187,41 @@ func TestBoldDB_RemoveOld(t *testing.T) {
 
 	res, err := s.RemoveOld("chan1", 1)
 	require.NoError(t, err)
-	assert.Equal(t, []string{"f2", "f1"}, res)
+	assert.Equal(t, []string{"f2", "f1", "f1.x1.5", "f1.peaks.json"}, res)
+}
+
+func TestStore_RemoveExpired(t *testing.T) {
+	tmpfile := filepath.Join(os.TempDir(), "test-expired.db")
+	defer os.Remove(tmpfile)
+
+	db, err := bolt.Open(tmpfile, 0o600, &bolt.Options{Timeout: 1 * time.Second})
+	require.NoError(t, err)
+
+	s := BoltDB{DB: db}
+	for i, e := range []feed.Entry{
+		{VideoID: "old", Published: time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC), File: "f1"},
+		{VideoID: "pinned", Published: time.Date(2022, time.January, 2, 0, 0, 0, 0, time.UTC), File: "f2", Pinned: true},
+		{VideoID: "fresh", Published: time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC), File: "f3"},
+	} {
+		e.ChannelID, e.Title = "chan1", fmt.Sprintf("title%d", i)
+		_, err = s.Save(e)
+		require.NoError(t, err)
+	}
+
+	res, err := s.RemoveExpired("chan1", time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, "f1", res[0].File)
+
+	left, err := s.Load("chan1", 10)
+	require.NoError(t, err)
+	require.Len(t, left, 2)
+	assert.Equal(t, "fresh", left[0].VideoID)
+	assert.Equal(t, "pinned", left[1].VideoID)
+
+	res, err = s.RemoveExpired("nochan", time.Now())
+	require.NoError(t, err, "no bucket, nothing to expire")
+	assert.Empty(t, res)
 }
 
 func TestBoltDB_SetProcessed(t *testing.T) {
@@ -499,6 +536,29 @@ func TestStore_UpdateEntry(t *testing.T) {
 	assert.Error(t, s.UpdateEntry(noBucket))
 }
 
+func TestStore_ReplaceEntry(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	defer db.Close()
+	s := BoltDB{DB: db}
+
+	for i, id := range []string{"vid1", "vid2"} {
+		_, err = s.Save(feed.Entry{ChannelID: "chan1", VideoID: id, Published: time.Date(2022, time.March, 21, i, 0, 0, 0, time.UTC)})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, s.ReplaceEntry(feed.Entry{ChannelID: "chan1", VideoID: "vid1", Title: "moved",
+		Published: time.Date(2022, time.March, 22, 0, 0, 0, 0, time.UTC)}))
+	res, err := s.Load("chan1", 100)
+	require.NoError(t, err)
+	require.Len(t, res, 2, "replace must not leave the old record")
+	assert.Equal(t, "vid1", res[0].VideoID, "newest after the change of date")
+	assert.Equal(t, "moved", res[0].Title)
+
+	assert.Error(t, s.ReplaceEntry(feed.Entry{ChannelID: "chan1", VideoID: "nope"}))
+	assert.Error(t, s.ReplaceEntry(feed.Entry{ChannelID: "nochan", VideoID: "vid1"}))
+}
+
 func TestStore_NotionMeta(t *testing.T) {
 	tmpfile := filepath.Join(os.TempDir(), "test-notion.db")
 	defer os.Remove(tmpfile)
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	bolt "go.etcd.io/bbolt"
+)
+
+var throughputBkt = []byte("throughput")
+
+// throughputWeight is the weight of a new sample in the learned rate, recent
+// runs count more: a server upgrade or a throttled network shows up quickly
+const throughputWeight = 0.3
+
+// Throughput is the learned speed of a pipeline stage, in units of its work
+// (media seconds, characters) per second of wall time
+type Throughput struct {
+	Stage     string    `json:"stage"`
+	Rate      float64   `json:"rate"` // units per second, moving average
+	Samples   int       `json:"samples"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Add mixes a run of units done in took into the rate
+func (t *Throughput) Add(units float64, took time.Duration, now time.Time) {
+	rate := units / took.Seconds()
+	if t.Samples == 0 {
+		t.Rate = rate
+	} else {
+		t.Rate += throughputWeight * (rate - t.Rate)
+	}
+	t.Samples++
+	t.UpdatedAt = now
+}
+
+// AddThroughput records a run of a stage and returns the updated rate. Runs
+// without work or time are ignored.
+func (s *BoltDB) AddThroughput(stage string, units float64, took time.Duration) (res Throughput, err error) {
+	if units <= 0 || took <= 0 {
+		return Throughput{}, fmt.Errorf("empty run of %s", stage)
+	}
+	err = s.Update(func(tx *bolt.Tx) error {
+		bucket, e := tx.CreateBucketIfNotExists(throughputBkt)
+		if e != nil {
+			return fmt.Errorf("create bucket %s: %w", throughputBkt, e)
+		}
+		res = Throughput{Stage: stage}
+		if v := bucket.Get([]byte(stage)); v != nil {
+			if jerr := json.Unmarshal(v, &res); jerr != nil {
+				log.Printf("[WARN] throughput unmarshal %s: %v", stage, jerr)
+				res = Throughput{Stage: stage}
+			}
+		}
+		res.Add(units, took, time.Now().UTC())
+		jdata, jerr := json.Marshal(&res)
+		if jerr != nil {
+			return fmt.Errorf("marshal throughput %s: %w", stage, jerr)
+		}
+		return bucket.Put([]byte(stage), jdata)
+	})
+	return res, err
+}
+
+// LoadThroughputs returns the learned rates by stage
+func (s *BoltDB) LoadThroughputs() (res map[string]Throughput, err error) {
+	res = map[string]Throughput{}
+	err = s.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(throughputBkt)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var item Throughput
+			if jerr := json.Unmarshal(v, &item); jerr != nil {
+				log.Printf("[WARN] throughput unmarshal %s: %v", string(k), jerr)
+				return nil
+			}
+			res[string(k)] = item
+			return nil
+		})
+	})
+	return res, err
+}
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestStore_Throughput(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	defer db.Close()
+	s := BoltDB{DB: db}
+
+	rates, err := s.LoadThroughputs()
+	require.NoError(t, err)
+	assert.Empty(t, rates)
+
+	res, err := s.AddThroughput("tts", 1000, 10*time.Second)
+	require.NoError(t, err)
+	assert.InDelta(t, 100, res.Rate, 0.001, "the first run sets the rate")
+	res, err = s.AddThroughput("tts", 2000, 10*time.Second)
+	require.NoError(t, err)
+	assert.InDelta(t, 130, res.Rate, 0.001, "later runs move it by their weight")
+	assert.Equal(t, 2, res.Samples)
+	_, err = s.AddThroughput("download", 600, time.Minute)
+	require.NoError(t, err)
+	_, err = s.AddThroughput("download", 0, time.Minute)
+	require.Error(t, err)
+
+	rates, err = s.LoadThroughputs()
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	assert.InDelta(t, 130, rates["tts"].Rate, 0.001)
+	assert.InDelta(t, 10, rates["download"].Rate, 0.001)
+	assert.Equal(t, 1, rates["download"].Samples)
+}
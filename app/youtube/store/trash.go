@@ -0,0 +1,214 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/umputun/feed-master/app/youtube/feed"
+)
+
+var trashBkt = []byte("trash")
+
+// trashed is an entry moved to the trash, with the key it had in its channel
+type trashed struct {
+	Key     string     `json:"key"`
+	Entry   feed.Entry `json:"entry"`
+	Deleted time.Time  `json:"deleted"`
+}
+
+// Trash moves the entries of the channel with the given video ids to the
+// trash in one transaction, as one batch for RestoreTrash. Their files are
+// kept until PurgeTrash, an entry saved again drops its trashed copy. Returns
+// how many were found.
+func (s *BoltDB) Trash(channelID string, videoIDs []string, now time.Time) (moved int, err error) {
+	ids := make(map[string]bool, len(videoIDs))
+	for _, id := range videoIDs {
+		ids[id] = true
+	}
+	err = s.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(channelID))
+		if bucket == nil {
+			return fmt.Errorf("no bucket for %s", channelID)
+		}
+		trash, e := trashBucket(tx, channelID)
+		if e != nil {
+			return e
+		}
+		var keys [][]byte
+		c := bucket.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var item feed.Entry
+			if jerr := json.Unmarshal(v, &item); jerr != nil {
+				log.Printf("[WARN] failed to unmarshal, %v", jerr)
+				continue
+			}
+			if !ids[item.VideoID] {
+				continue
+			}
+			data, jerr := json.Marshal(trashed{Key: string(k), Entry: item, Deleted: now})
+			if jerr != nil {
+				return fmt.Errorf("marshal trashed %s: %w", item.VideoID, jerr)
+			}
+			// batch first, so the last batch is at the end of the bucket
+			if perr := trash.Put([]byte(fmt.Sprintf("%020d/%s", now.UnixNano(), k)), data); perr != nil {
+				return fmt.Errorf("trash %s: %w", item.VideoID, perr)
+			}
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		for _, k := range keys {
+			if derr := bucket.Delete(k); derr != nil {
+				return fmt.Errorf("failed to delete %s: %w", string(k), derr)
+			}
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		moved = len(keys)
+		return bumpRevision(tx, channelID)
+	})
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("[INFO] trashed %d entries of %s", moved, channelID)
+	return moved, nil
+}
+
+// RestoreTrash moves the last trashed batch of the channel back, entries
+// saved again in the meantime are kept as they are. Returns the restored
+// entries, none with an empty trash.
+func (s *BoltDB) RestoreTrash(channelID string) ([]feed.Entry, error) {
+	var res []feed.Entry
+	err := s.Update(func(tx *bolt.Tx) error {
+		trash, e := trashBucket(tx, channelID)
+		if e != nil {
+			return e
+		}
+		lastKey, _ := trash.Cursor().Last()
+		if lastKey == nil {
+			return nil
+		}
+		batch, _, _ := strings.Cut(string(lastKey), "/")
+		bucket, e := tx.CreateBucketIfNotExists([]byte(channelID))
+		if e != nil {
+			return fmt.Errorf("create bucket %s: %w", channelID, e)
+		}
+		var keys [][]byte
+		c := trash.Cursor()
+		prefix := []byte(batch + "/")
+		for k, v := c.Seek(prefix); k != nil && strings.HasPrefix(string(k), string(prefix)); k, v = c.Next() {
+			keys = append(keys, append([]byte(nil), k...))
+			var item trashed
+			if jerr := json.Unmarshal(v, &item); jerr != nil {
+				log.Printf("[WARN] failed to unmarshal trashed %s, %v", string(k), jerr)
+				continue
+			}
+			if bucket.Get([]byte(item.Key)) != nil {
+				continue
+			}
+			data, jerr := json.Marshal(item.Entry)
+			if jerr != nil {
+				return fmt.Errorf("marshal entry %s: %w", item.Entry.VideoID, jerr)
+			}
+			if perr := bucket.Put([]byte(item.Key), data); perr != nil {
+				return fmt.Errorf("restore entry %s: %w", item.Entry.VideoID, perr)
+			}
+			res = append(res, item.Entry)
+		}
+		for _, k := range keys {
+			if derr := trash.Delete(k); derr != nil {
+				return fmt.Errorf("failed to delete trashed %s: %w", string(k), derr)
+			}
+		}
+		if len(res) == 0 {
+			return nil
+		}
+		return bumpRevision(tx, channelID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// PurgeTrash drops the entries of the channel trashed before the cutoff and
+// returns them, the caller should delete their files
+func (s *BoltDB) PurgeTrash(channelID string, before time.Time) ([]feed.Entry, error) {
+	var res []feed.Entry
+	err := s.Update(func(tx *bolt.Tx) error {
+		root := tx.Bucket(trashBkt)
+		if root == nil || root.Bucket([]byte(channelID)) == nil {
+			return nil
+		}
+		trash := root.Bucket([]byte(channelID))
+		var keys [][]byte
+		c := trash.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var item trashed
+			if jerr := json.Unmarshal(v, &item); jerr != nil {
+				log.Printf("[WARN] failed to unmarshal trashed %s, %v", string(k), jerr)
+				keys = append(keys, append([]byte(nil), k...))
+				continue
+			}
+			if !item.Deleted.Before(before) {
+				break // keys are in deletion order
+			}
+			keys = append(keys, append([]byte(nil), k...))
+			res = append(res, item.Entry)
+		}
+		for _, k := range keys {
+			if derr := trash.Delete(k); derr != nil {
+				return fmt.Errorf("failed to delete trashed %s: %w", string(k), derr)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// dropTrashed removes the trashed entries with the video id or the file of the
+// saved entry, their files are the saved one's now
+func dropTrashed(tx *bolt.Tx, entry feed.Entry) error {
+	root := tx.Bucket(trashBkt)
+	if root == nil || root.Bucket([]byte(entry.ChannelID)) == nil {
+		return nil
+	}
+	trash := root.Bucket([]byte(entry.ChannelID))
+	var keys [][]byte
+	c := trash.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		var item trashed
+		if jerr := json.Unmarshal(v, &item); jerr != nil {
+			continue
+		}
+		if item.Entry.VideoID == entry.VideoID || (entry.File != "" && item.Entry.File == entry.File) {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+	}
+	for _, k := range keys {
+		if err := trash.Delete(k); err != nil {
+			return fmt.Errorf("failed to delete trashed %s: %w", string(k), err)
+		}
+	}
+	return nil
+}
+
+// trashBucket returns the trash of the channel, a bucket nested in trashBkt
+func trashBucket(tx *bolt.Tx, channelID string) (*bolt.Bucket, error) {
+	root, err := tx.CreateBucketIfNotExists(trashBkt)
+	if err != nil {
+		return nil, fmt.Errorf("create bucket %s: %w", trashBkt, err)
+	}
+	bucket, err := root.CreateBucketIfNotExists([]byte(channelID))
+	if err != nil {
+		return nil, fmt.Errorf("create trash bucket %s: %w", channelID, err)
+	}
+	return bucket, nil
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/umputun/feed-master/app/youtube/feed"
+)
+
+func TestStore_Trash(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0o600, &bolt.Options{Timeout: 1 * time.Second})
+	require.NoError(t, err)
+	defer db.Close()
+	s := BoltDB{DB: db}
+
+	_, err = s.Trash("chan1", []string{"vid1"}, time.Now())
+	require.Error(t, err, "no bucket")
+
+	for i := 1; i <= 4; i++ {
+		_, err = s.Save(feed.Entry{ChannelID: "chan1", VideoID: fmt.Sprintf("vid%d", i), Title: fmt.Sprintf("title%d", i),
+			Published: time.Date(2022, time.March, 21, i, 0, 0, 0, time.UTC)})
+		require.NoError(t, err)
+	}
+	ids := func() (res []string) {
+		entries, lerr := s.Load("chan1", 10)
+		require.NoError(t, lerr)
+		for _, e := range entries {
+			res = append(res, e.VideoID)
+		}
+		return res
+	}
+	rev, err := s.Revision("chan1")
+	require.NoError(t, err)
+
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	n, err := s.Trash("chan1", []string{"vid1", "vid3", "missing"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"vid4", "vid2"}, ids())
+	after, err := s.Revision("chan1")
+	require.NoError(t, err)
+	assert.Equal(t, rev.Rev+1, after.Rev, "one revision for the batch")
+
+	n, err = s.Trash("chan1", []string{"vid4"}, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"vid2"}, ids())
+
+	restored, err := s.RestoreTrash("chan1")
+	require.NoError(t, err)
+	require.Len(t, restored, 1, "the last batch only")
+	assert.Equal(t, "title4", restored[0].Title)
+	assert.Equal(t, []string{"vid4", "vid2"}, ids())
+
+	purged, err := s.PurgeTrash("chan1", now)
+	require.NoError(t, err)
+	assert.Empty(t, purged, "not old enough")
+	purged, err = s.PurgeTrash("chan1", now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, purged, 2)
+
+	restored, err = s.RestoreTrash("chan1")
+	require.NoError(t, err)
+	assert.Empty(t, restored, "trash is empty")
+
+	purged, err = s.PurgeTrash("chan2", now)
+	require.NoError(t, err)
+	assert.Empty(t, purged, "no trash of the channel")
+}
+
+func TestStore_TrashSavedAgain(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0o600, &bolt.Options{Timeout: 1 * time.Second})
+	require.NoError(t, err)
+	defer db.Close()
+	s := BoltDB{DB: db}
+
+	entry := feed.Entry{ChannelID: "chan1", VideoID: "vid1", File: "/srv/abc.mp3", Title: "title1",
+		Published: time.Date(2022, time.March, 21, 1, 0, 0, 0, time.UTC)}
+	_, err = s.Save(entry)
+	require.NoError(t, err)
+	_, err = s.Save(feed.Entry{ChannelID: "chan1", VideoID: "vid2", File: "/srv/def.mp3",
+		Published: time.Date(2022, time.March, 21, 2, 0, 0, 0, time.UTC)})
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	_, err = s.Trash("chan1", []string{"vid1", "vid2"}, now)
+	require.NoError(t, err)
+
+	// added again, with a later date, while the first copy is in the trash
+	entry.Published = entry.Published.Add(time.Hour * 24)
+	created, err := s.Save(entry)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	purged, err := s.PurgeTrash("chan1", now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, purged, 1, "the copy of the saved entry is gone, its files are live")
+	assert.Equal(t, "vid2", purged[0].VideoID)
+}
@@ -0,0 +1,45 @@
+package store
+
+import (
+	"fmt"
+	"strconv"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var userLangBkt = []byte("user_lang")
+
+// SaveUserLang keeps the bot language the user picked, "" drops the choice
+func (s *BoltDB) SaveUserLang(userID int64, lang string) error {
+	return s.Update(func(tx *bolt.Tx) error {
+		bucket, e := tx.CreateBucketIfNotExists(userLangBkt)
+		if e != nil {
+			return fmt.Errorf("create bucket %s: %w", userLangBkt, e)
+		}
+		key := []byte(strconv.FormatInt(userID, 10))
+		if lang == "" {
+			return bucket.Delete(key)
+		}
+		return bucket.Put(key, []byte(lang))
+	})
+}
+
+// LoadUserLangs returns the bot languages the users picked, by user id
+func (s *BoltDB) LoadUserLangs() (res map[int64]string, err error) {
+	res = map[int64]string{}
+	err = s.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(userLangBkt)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			id, perr := strconv.ParseInt(string(k), 10, 64)
+			if perr != nil {
+				return nil // not a user id, skipped
+			}
+			res[id] = string(v)
+			return nil
+		})
+	})
+	return res, err
+}
@@ -0,0 +1,29 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestStore_UserLang(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	defer db.Close()
+	s := BoltDB{DB: db}
+
+	langs, err := s.LoadUserLangs()
+	require.NoError(t, err)
+	assert.Empty(t, langs)
+
+	require.NoError(t, s.SaveUserLang(1, "en"))
+	require.NoError(t, s.SaveUserLang(2, "ru"))
+	require.NoError(t, s.SaveUserLang(2, ""), "choice dropped")
+	langs, err = s.LoadUserLangs()
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]string{1: "en"}, langs)
+}
@@ -25,6 +25,7 @@ require (
 	github.com/wujunwei928/edge-tts-go v0.0.0-20250315123430-d4675babeb96
 	go.etcd.io/bbolt v1.4.3
 	golang.org/x/net v0.53.0
+	golang.org/x/text v0.37.0
 	gopkg.in/tucnak/telebot.v2 v2.5.0
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -65,7 +66,6 @@ require (
 	go.yaml.in/yaml/v3 v3.0.4 // indirect
 	golang.org/x/crypto v0.51.0 // indirect
 	golang.org/x/sys v0.44.0 // indirect
-	golang.org/x/text v0.37.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
 	gopkg.in/ini.v1 v1.67.2 // indirect
 )